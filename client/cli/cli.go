@@ -15,6 +15,7 @@ import (
 	_ "github.com/micro/micro/v3/client/cli/auth"
 	_ "github.com/micro/micro/v3/client/cli/config"
 	_ "github.com/micro/micro/v3/client/cli/debug"
+	_ "github.com/micro/micro/v3/client/cli/events"
 	_ "github.com/micro/micro/v3/client/cli/gen"
 	_ "github.com/micro/micro/v3/client/cli/init"
 	_ "github.com/micro/micro/v3/client/cli/network"
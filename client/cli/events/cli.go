@@ -0,0 +1,120 @@
+// Package cli implements the `micro events` subcommands
+// for example:
+//
+//	micro events replay
+//	micro events status
+//	micro events dlq list
+//	micro events dlq requeue
+package cli
+
+import (
+	"time"
+
+	"github.com/micro/micro/v3/cmd"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/util/helper"
+	"github.com/urfave/cli/v2"
+)
+
+func init() {
+	cmd.Register(&cli.Command{
+		Name:   "events",
+		Usage:  "Commands for managing events",
+		Action: helper.UnexpectedSubcommand,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "replay",
+				Usage:     "replay the history of a topic",
+				UsageText: `micro events replay [options] topic`,
+				Description: "Publishes the events of the topic from the time or offset to the target topic, or to the " +
+					"topic itself, until it catches up with the events published after the replay started.",
+				Action: replay,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "replay the events from the time e.g. 2021-01-02T15:04:05Z, or the duration before now e.g. 24h",
+					},
+					&cli.Uint64Flag{
+						Name:  "offset",
+						Usage: "replay the events from the offset, for streams which number their events",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "topic the events are published to, defaults to the topic being replayed",
+					},
+					&cli.UintFlag{
+						Name:    "limit",
+						Aliases: []string{"l"},
+						Usage:   "the maximum number of events to replay",
+					},
+					&cli.DurationFlag{
+						Name:  "idle",
+						Usage: "stop replaying if no event is received for the duration",
+						Value: 5 * time.Second,
+					},
+				},
+			},
+			{
+				Name:      "status",
+				Usage:     "show the consumer groups of a topic and their lag",
+				UsageText: `micro events status [options] [topic]`,
+				Description: "Lists the consumer groups of the topic, or of every topic if none is given, with the number " +
+					"of consumers, the events not yet delivered to the group and the events delivered but not acknowledged. " +
+					"Consumers joining and leaving groups are published to the " + events.GroupTopic + " topic.",
+				Action: status,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "output format (json, table)",
+						Value: "table",
+					},
+				},
+			},
+			{
+				Name:   "dlq",
+				Usage:  "Inspect and requeue the events of a dead-letter topic",
+				Action: helper.UnexpectedSubcommand,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "list",
+						Usage:     "list the events of a dead-letter topic",
+						UsageText: `micro events dlq list [options] topic`,
+						Action:    listDeadLetters,
+						Flags: []cli.Flag{
+							&cli.UintFlag{
+								Name:    "limit",
+								Aliases: []string{"l"},
+								Usage:   "list limit",
+							},
+							&cli.UintFlag{
+								Name:    "offset",
+								Aliases: []string{"o"},
+								Usage:   "list offset",
+							},
+							&cli.StringFlag{
+								Name:  "output",
+								Usage: "output format (json, table)",
+								Value: "table",
+							},
+						},
+					},
+					{
+						Name:      "requeue",
+						Usage:     "publish events of a dead-letter topic to the topic they were consumed from",
+						UsageText: `micro events dlq requeue [options] topic [id...]`,
+						Description: "Requeues the events with the ids, or all the events of the topic if no ids are given. " +
+							"Requeued events are deleted from the dead-letter topic.",
+						Action: requeueDeadLetters,
+						Flags: []cli.Flag{
+							&cli.UintFlag{
+								Name:    "limit",
+								Aliases: []string{"l"},
+								Usage:   "the number of events read from the topic",
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// listDeadLetters prints the events of a dead-letter topic
+func listDeadLetters(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("Topic arg is required")
+	}
+
+	var opts []events.ReadOption
+	if ctx.Uint("limit") != 0 {
+		opts = append(opts, events.ReadLimit(ctx.Uint("limit")))
+	}
+	if ctx.Uint("offset") != 0 {
+		opts = append(opts, events.ReadOffset(ctx.Uint("offset")))
+	}
+
+	evs, err := events.Read(ctx.Args().First(), opts...)
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't read the events of %s", ctx.Args().First())
+	}
+
+	switch ctx.String("output") {
+	case "json":
+		b, err := json.MarshalIndent(evs, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed marshalling JSON")
+		}
+		fmt.Printf("%s\n", string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintf(w, "%v \t %v \t %v \t %v\n", "ID", "TOPIC", "DEAD-LETTERED", "PAYLOAD")
+		for _, ev := range evs {
+			payload := string(ev.Payload)
+			if !utf8.Valid(ev.Payload) {
+				payload = fmt.Sprintf("%#x", ev.Payload)
+			}
+			fmt.Fprintf(w, "%v \t %v \t %v \t %v\n", ev.ID, ev.Metadata[events.DeadLetterTopicKey], humanize.Time(ev.Timestamp), payload)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+// requeueDeadLetters publishes the events of a dead-letter topic to the topics they were
+// consumed from and deletes them from the dead-letter topic
+func requeueDeadLetters(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("Topic arg is required")
+	}
+	topic := ctx.Args().First()
+
+	var opts []events.ReadOption
+	if ctx.Uint("limit") != 0 {
+		opts = append(opts, events.ReadLimit(ctx.Uint("limit")))
+	}
+	evs, err := events.Read(topic, opts...)
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't read the events of %s", topic)
+	}
+
+	// requeue the events with the ids, or all of them if there are none
+	ids := map[string]bool{}
+	for _, id := range ctx.Args().Tail() {
+		ids[id] = true
+	}
+
+	var count int
+	for _, ev := range evs {
+		if len(ids) > 0 && !ids[ev.ID] {
+			continue
+		}
+		delete(ids, ev.ID)
+
+		original := ev.Metadata[events.DeadLetterTopicKey]
+		if len(original) == 0 {
+			return fmt.Errorf("Event %s wasn't dead-lettered, it has no %s", ev.ID, events.DeadLetterTopicKey)
+		}
+		md := make(map[string]string, len(ev.Metadata))
+		for k, v := range ev.Metadata {
+			if k != events.DeadLetterTopicKey {
+				md[k] = v
+			}
+		}
+
+		if err := events.Publish(original, ev.Payload, events.WithMetadata(md), events.WithOrderingKey(ev.OrderingKey)); err != nil {
+			return errors.Wrapf(err, "Couldn't requeue event %s", ev.ID)
+		}
+		if err := events.Delete(topic, ev.ID); err != nil {
+			return errors.Wrapf(err, "Event %s was requeued but couldn't be deleted", ev.ID)
+		}
+		count++
+	}
+
+	for id := range ids {
+		fmt.Fprintf(os.Stderr, "Event %s not found\n", id)
+	}
+	fmt.Printf("Requeued %d events\n", count)
+	return nil
+}
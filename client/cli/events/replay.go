@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// replay consumes the history of a topic and publishes the events to the target topic, until it
+// catches up with the events published after the replay started
+func replay(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("Topic arg is required")
+	}
+	topic := ctx.Args().First()
+	target := ctx.String("to")
+	if len(target) == 0 {
+		target = topic
+	}
+
+	// events are consumed with a new group so the replay doesn't affect other consumers
+	opts := []events.ConsumeOption{events.WithGroup("replay-" + uuid.New().String())}
+	switch {
+	case ctx.Uint64("offset") > 0:
+		opts = append(opts, events.ReadFromOffset(ctx.Uint64("offset")))
+	case len(ctx.String("from")) > 0:
+		from, err := parseFrom(ctx.String("from"))
+		if err != nil {
+			return err
+		}
+		opts = append(opts, events.ReadFrom(from))
+	default:
+		return errors.New("One of --from or --offset is required")
+	}
+
+	cctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := events.Consume(topic, append(opts, events.WithContext(cctx))...)
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't consume %s", topic)
+	}
+
+	// events published from the second the replay started are new, which includes the ones it
+	// publishes itself if the target is the topic being replayed
+	cutoff := time.Now().Truncate(time.Second)
+	limit := ctx.Uint("limit")
+	idle := ctx.Duration("idle")
+
+	var count uint
+	for limit == 0 || count < limit {
+		var ev events.Event
+		var ok bool
+		select {
+		case ev, ok = <-ch:
+		case <-time.After(idle):
+			ok = false
+		}
+		if !ok || !ev.Timestamp.Before(cutoff) {
+			break
+		}
+
+		if err := events.Publish(target, ev.Payload, events.WithMetadata(ev.Metadata), events.WithOrderingKey(ev.OrderingKey)); err != nil {
+			return errors.Wrapf(err, "Couldn't replay event %s", ev.ID)
+		}
+		count++
+	}
+
+	fmt.Printf("Replayed %d events\n", count)
+	return nil
+}
+
+// parseFrom parses a time in RFC3339 format, or a duration before now
+func parseFrom(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid --from %s, expected a time e.g. 2021-01-02T15:04:05Z or a duration e.g. 24h", v)
+	}
+	return time.Now().Add(-d), nil
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// status prints the consumers and lag of the consumer groups of a topic, or of every topic
+func status(ctx *cli.Context) error {
+	groups, err := events.Status(ctx.Args().First())
+	if err == events.ErrNotSupported {
+		return errors.New("The events stream doesn't support reporting the status of consumer groups")
+	} else if err != nil {
+		return errors.Wrap(err, "Couldn't get the status of the consumer groups")
+	}
+
+	switch ctx.String("output") {
+	case "json":
+		b, err := json.MarshalIndent(groups, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed marshalling JSON")
+		}
+		fmt.Printf("%s\n", string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintf(w, "%v \t %v \t %v \t %v \t %v\n", "TOPIC", "GROUP", "CONSUMERS", "PENDING", "UNACKED")
+		for _, g := range groups {
+			fmt.Fprintf(w, "%v \t %v \t %v \t %v \t %v\n", g.Topic, g.Group, g.Consumers, g.Pending, g.Unacked)
+		}
+		w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/micro/micro/v3/client/cli/namespace"
+	"github.com/micro/micro/v3/client/cli/util"
+	pb "github.com/micro/micro/v3/proto/store"
+	"github.com/micro/micro/v3/service/client"
+	"github.com/micro/micro/v3/service/context"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// backup is the entrypoint for micro store backup
+func backup(ctx *cli.Context) error {
+	env, err := util.GetEnv(ctx)
+	if err != nil {
+		return err
+	}
+	ns, err := namespace.Get(env.Name)
+	if err != nil {
+		return err
+	}
+
+	// back up the table if one was set, otherwise all the tables of the namespace
+	tables := []string{ctx.String("table")}
+	if len(tables[0]) == 0 {
+		tReq := client.NewRequest(ctx.String("store"), "Store.Tables", &pb.TablesRequest{
+			Database: ns,
+		})
+		tRsp := &pb.TablesResponse{}
+		if err := client.DefaultClient.Call(context.DefaultContext, tReq, tRsp, client.WithAuthToken()); err != nil {
+			return errors.Wrap(err, "couldn't list tables")
+		}
+		tables = tRsp.Tables
+	}
+
+	opts := make([]store.BackupOption, len(tables))
+	for i, t := range tables {
+		opts[i] = store.BackupTable(ns, t)
+	}
+
+	var w io.Writer = os.Stdout
+	if out := ctx.String("out"); out != "-" {
+		f, err := os.OpenFile(out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't open %s", out)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := store.Backup(context.DefaultContext, w, opts...); err != nil {
+		return errors.Wrap(err, "couldn't back up the store")
+	}
+	return nil
+}
+
+// restoreBackup is the entrypoint for micro store restore with an archive written by micro store backup
+func restoreBackup(ctx *cli.Context) error {
+	var opts []store.RestoreOption
+	if t := ctx.String("table"); len(t) > 0 {
+		env, err := util.GetEnv(ctx)
+		if err != nil {
+			return err
+		}
+		ns, err := namespace.Get(env.Name)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, store.RestoreTable(ns, t))
+	}
+
+	var r io.Reader = os.Stdin
+	if in := ctx.String("in"); in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't open %s", in)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if err := store.Restore(context.DefaultContext, r, opts...); err != nil {
+		return errors.Wrap(err, "couldn't restore the store")
+	}
+	return nil
+}
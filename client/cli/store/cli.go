@@ -1,5 +1,6 @@
 // Package cli implements the `micro store` subcommands
 // for example:
+//   micro store backup
 //   micro store snapshot
 //   micro store restore
 //   micro store sync
@@ -56,11 +57,20 @@ func init() {
 						Name:  "order",
 						Usage: "Set the order of records e.g asc or desc",
 					},
+					&cli.StringFlag{
+						Name:  "order-by",
+						Usage: "Set the field records are sorted by e.g key or expiry",
+					},
 					&cli.UintFlag{
 						Name:    "offset",
 						Aliases: []string{"o"},
 						Usage:   "list offset",
 					},
+					&cli.StringSliceFlag{
+						Name:    "where",
+						Aliases: []string{"w"},
+						Usage:   "only read records with the metadata e.g status=active",
+					},
 					&cli.BoolFlag{
 						Name:    "verbose",
 						Aliases: []string{"v"},
@@ -100,12 +110,20 @@ func init() {
 						Name:  "order",
 						Usage: "Set the order of records e.g asc or desc",
 					},
+					&cli.StringFlag{
+						Name:  "order-by",
+						Usage: "Set the field records are sorted by e.g key or expiry",
+					},
 					&cli.BoolFlag{
 						Name:    "prefix",
 						Aliases: []string{"p"},
 						Usage:   "list prefix",
 						Value:   false,
 					},
+					&cli.StringFlag{
+						Name:  "delimiter",
+						Usage: "group the keys containing the delimiter after the prefix e.g /",
+					},
 					&cli.UintFlag{
 						Name:    "limit",
 						Aliases: []string{"l"},
@@ -144,10 +162,36 @@ func init() {
 					},
 				},
 			},
+			{
+				Name:      "touch",
+				Usage:     "set the expiry of a record without rewriting it",
+				UsageText: `micro store touch [options] key`,
+				Action:    touch,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "expiry",
+						Aliases: []string{"e"},
+						Usage:   "expiry in time.ParseDuration format, the record never expires if not set",
+						Value:   "",
+					},
+					&cli.StringFlag{
+						Name:    "database",
+						Aliases: []string{"d"},
+						Usage:   "database of the record",
+						Value:   "micro",
+					},
+					&cli.StringFlag{
+						Name:    "table",
+						Aliases: []string{"t"},
+						Usage:   "table of the record",
+						Value:   "micro",
+					},
+				},
+			},
 			{
 				Name:      "delete",
-				Usage:     "delete a key from the store",
-				UsageText: `micro store delete [options] key`,
+				Usage:     "delete keys from the store",
+				UsageText: `micro store delete [options] key [key...]`,
 				Action:    delete,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
@@ -160,6 +204,32 @@ func init() {
 						Usage: "table to delete from",
 						Value: "micro",
 					},
+					&cli.BoolFlag{
+						Name:    "prefix",
+						Aliases: []string{"p"},
+						Usage:   "delete all keys with the prefix",
+						Value:   false,
+					},
+				},
+			},
+			{
+				Name:      "compact",
+				Usage:     "rewrite the files of a table to reclaim disk space",
+				UsageText: `micro store compact [options]`,
+				Action:    compact,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "database",
+						Aliases: []string{"d"},
+						Usage:   "database to compact",
+						Value:   "micro",
+					},
+					&cli.StringFlag{
+						Name:    "table",
+						Aliases: []string{"t"},
+						Usage:   "table to compact",
+						Value:   "micro",
+					},
 				},
 			},
 			{
@@ -192,6 +262,63 @@ func init() {
 					},
 				},
 			},
+			{
+				Name:   "usage",
+				Usage:  "Show the number and size of the records in the namespace, and its quota",
+				Action: usage,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "store",
+						Usage: "store service to call",
+						Value: "store",
+					},
+				},
+			},
+			{
+				Name:   "stats",
+				Usage:  "Show the number and size of the records in each table of the namespace, and when they expire",
+				Action: stats,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "store",
+						Usage: "store service to call",
+						Value: "store",
+					},
+					&cli.StringFlag{
+						Name:    "table",
+						Aliases: []string{"t"},
+						Usage:   "table to show, all the tables if not set",
+					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "show the tables of all the databases known to the store service",
+					},
+				},
+			},
+			{
+				Name:      "backup",
+				Usage:     "Back up the records of the tables in a namespace to an archive",
+				UsageText: `micro store backup [options]`,
+				Action:    backup,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "store",
+						Usage: "store service to call",
+						Value: "store",
+					},
+					&cli.StringFlag{
+						Name:    "table",
+						Aliases: []string{"t"},
+						Usage:   "table to back up, all the tables if not set",
+					},
+					&cli.StringFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "file to write the archive to, or - for stdout",
+						Value:   "store-backup.tar.gz",
+					},
+				},
+			},
 			{
 				Name:   "snapshot",
 				Usage:  "Back up a store",
@@ -213,14 +340,20 @@ func init() {
 			},
 			{
 				Name:   "restore",
-				Usage:  "restore a store snapshot",
+				Usage:  "restore a store backup, or a snapshot if the source is set",
 				Action: restore,
 				Flags: append(CommonFlags,
 					&cli.StringFlag{
 						Name:  "source",
-						Usage: "Backup source",
+						Usage: "Snapshot source",
 						Value: "file:///tmp/store-snapshot",
 					},
+					&cli.StringFlag{
+						Name:    "in",
+						Aliases: []string{"i"},
+						Usage:   "archive written by micro store backup, or - for stdin",
+						Value:   "store-backup.tar.gz",
+					},
 				),
 			},
 		},
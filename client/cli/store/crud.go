@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -58,6 +59,16 @@ func read(ctx *cli.Context) error {
 		}
 		opts = append(opts, store.ReadOrder(order))
 	}
+	if v := ctx.String("order-by"); v == string(store.OrderByExpiry) {
+		opts = append(opts, store.ReadOrderBy(store.OrderByExpiry))
+	}
+	for _, w := range ctx.StringSlice("where") {
+		parts := strings.SplitN(w, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("Invalid where %s, expected field=value", w)
+		}
+		opts = append(opts, store.ReadWhere(parts[0], parts[1]))
+	}
 
 	records, err := store.DefaultStore.Read(ctx.Args().First(), opts...)
 	if err != nil {
@@ -138,6 +149,39 @@ func write(ctx *cli.Context) error {
 	return nil
 }
 
+// touch sets the expiry of a record
+func touch(ctx *cli.Context) error {
+	if err := initStore(ctx); err != nil {
+		return err
+	}
+	if ctx.Args().Len() < 1 {
+		return errors.New("Key arg is required")
+	}
+	var expiry time.Duration
+	if len(ctx.String("expiry")) > 0 {
+		d, err := time.ParseDuration(ctx.String("expiry"))
+		if err != nil {
+			return errors.Wrap(err, "expiry flag is invalid")
+		}
+		expiry = d
+	}
+
+	env, err := util.GetEnv(ctx)
+	if err != nil {
+		return err
+	}
+	// get the namespace
+	ns, err := namespace.Get(env.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Touch(context.Background(), ctx.Args().First(), expiry, store.TouchFrom(ns, ctx.String("table"))); err != nil {
+		return errors.Wrap(err, "couldn't touch")
+	}
+	return nil
+}
+
 // list retrieves keys
 func list(ctx *cli.Context) error {
 	if err := initStore(ctx); err != nil {
@@ -176,6 +220,12 @@ func list(ctx *cli.Context) error {
 		}
 		opts = append(opts, store.ListOrder(order))
 	}
+	if v := ctx.String("order-by"); v == string(store.OrderByExpiry) {
+		opts = append(opts, store.ListOrderBy(store.OrderByExpiry))
+	}
+	if v := ctx.String("delimiter"); len(v) > 0 {
+		opts = append(opts, store.ListDelimiter(v))
+	}
 
 	keys, err := store.DefaultStore.List(opts...)
 	if err != nil {
@@ -215,8 +265,45 @@ func delete(ctx *cli.Context) error {
 		return err
 	}
 
-	if err := store.DefaultStore.Delete(ctx.Args().First(), store.DeleteFrom(ns, ctx.String("table"))); err != nil {
-		return errors.Wrapf(err, "couldn't delete key %s", ctx.Args().First())
+	opts := []store.DeleteOption{store.DeleteFrom(ns, ctx.String("table"))}
+	if ctx.Bool("prefix") {
+		opts = append(opts, store.DeletePrefix())
+	}
+
+	keys := ctx.Args().Slice()
+	if len(keys) == 1 || ctx.Bool("prefix") {
+		for _, k := range keys {
+			if err := store.DefaultStore.Delete(k, opts...); err != nil {
+				return errors.Wrapf(err, "couldn't delete key %s", k)
+			}
+		}
+		return nil
+	}
+
+	if err := store.DeleteMany(keys, opts...); err != nil {
+		return errors.Wrapf(err, "couldn't delete keys %s", strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+// compact rewrites the files of a table to reclaim disk space
+func compact(ctx *cli.Context) error {
+	if err := initStore(ctx); err != nil {
+		return err
+	}
+
+	env, err := util.GetEnv(ctx)
+	if err != nil {
+		return err
+	}
+	// get the namespace
+	ns, err := namespace.Get(env.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Compact(context.Background(), store.CompactFrom(ns, ctx.String("table"))); err != nil {
+		return errors.Wrap(err, "couldn't compact")
 	}
 	return nil
 }
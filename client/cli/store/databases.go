@@ -2,6 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/micro/micro/v3/client/cli/namespace"
 	"github.com/micro/micro/v3/client/cli/util"
@@ -47,3 +51,89 @@ func tables(ctx *cli.Context) error {
 	}
 	return nil
 }
+
+// usage is the entrypoint for micro store usage
+func usage(ctx *cli.Context) error {
+	env, err := util.GetEnv(ctx)
+	if err != nil {
+		return err
+	}
+	ns, err := namespace.Get(env.Name)
+	if err != nil {
+		return err
+	}
+
+	uReq := client.NewRequest(ctx.String("store"), "Store.Usage", &pb.UsageRequest{
+		Database: ns,
+	})
+	uRsp := &pb.UsageResponse{}
+	if err := client.DefaultClient.Call(context.DefaultContext, uReq, uRsp, client.WithAuthToken()); err != nil {
+		return err
+	}
+	fmt.Printf("keys\t%s\n", usageLimit(uRsp.Keys, uRsp.MaxKeys))
+	fmt.Printf("bytes\t%s\n", usageLimit(uRsp.Bytes, uRsp.MaxBytes))
+	return nil
+}
+
+func usageLimit(n, max int64) string {
+	if max == 0 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%d / %d", n, max)
+}
+
+// stats is the entrypoint for micro store stats
+func stats(ctx *cli.Context) error {
+	var dbs []string
+	if ctx.Bool("all") {
+		dbReq := client.NewRequest(ctx.String("store"), "Store.Databases", &pb.DatabasesRequest{})
+		dbRsp := &pb.DatabasesResponse{}
+		if err := client.DefaultClient.Call(context.DefaultContext, dbReq, dbRsp, client.WithAuthToken()); err != nil {
+			return err
+		}
+		dbs = dbRsp.Databases
+	} else {
+		env, err := util.GetEnv(ctx)
+		if err != nil {
+			return err
+		}
+		ns, err := namespace.Get(env.Name)
+		if err != nil {
+			return err
+		}
+		dbs = []string{ns}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, strings.Join([]string{"Database", "Table", "Keys", "Bytes", "Oldest Expiry", "Newest Expiry"}, "\t\t"))
+	for _, db := range dbs {
+		sReq := client.NewRequest(ctx.String("store"), "Store.Stats", &pb.StatsRequest{
+			Database: db,
+			Table:    ctx.String("table"),
+		})
+		sRsp := &pb.StatsResponse{}
+		if err := client.DefaultClient.Call(context.DefaultContext, sReq, sRsp, client.WithAuthToken()); err != nil {
+			return err
+		}
+		for _, t := range sRsp.Tables {
+			fmt.Fprintln(w, strings.Join([]string{
+				t.Database,
+				t.Table,
+				fmt.Sprintf("%d", t.Keys),
+				fmt.Sprintf("%d", t.Bytes),
+				formatExpiry(t.OldestExpiry),
+				formatExpiry(t.NewestExpiry),
+			}, "\t\t"))
+		}
+	}
+	return nil
+}
+
+func formatExpiry(unix int64) string {
+	if unix == 0 {
+		return "n/a"
+	}
+	return time.Unix(unix, 0).Format(time.RFC3339)
+}
@@ -11,6 +11,9 @@ import (
 
 // restore is the entrypoint for micro store restore
 func restore(ctx *cli.Context) error {
+	if !ctx.IsSet("source") {
+		return restoreBackup(ctx)
+	}
 	s, err := makeStore(ctx)
 	if err != nil {
 		return errors.Wrap(err, "couldn't construct a store")
@@ -3,6 +3,7 @@ package cmd
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -40,7 +41,15 @@ import (
 	"github.com/micro/micro/v3/service/store"
 	uconf "github.com/micro/micro/v3/util/config"
 	"github.com/micro/micro/v3/util/helper"
+	"github.com/micro/micro/v3/util/limiter"
 	"github.com/micro/micro/v3/util/report"
+	"github.com/micro/micro/v3/util/selector"
+	"github.com/micro/micro/v3/util/selector/consistent"
+	"github.com/micro/micro/v3/util/selector/leastload"
+	"github.com/micro/micro/v3/util/selector/outlier"
+	"github.com/micro/micro/v3/util/selector/random"
+	"github.com/micro/micro/v3/util/selector/roundrobin"
+	"github.com/micro/micro/v3/util/selector/weighted"
 	"github.com/micro/micro/v3/util/user"
 	"github.com/micro/micro/v3/util/wrapper"
 	"github.com/urfave/cli/v2"
@@ -91,6 +100,15 @@ var (
 
 	onceBefore sync.Once
 
+	// selectors which can be used to balance calls, keyed by name
+	selectors = map[string]func(...selector.Option) selector.Selector{
+		"consistent": consistent.NewSelector,
+		"leastload":  leastload.NewSelector,
+		"random":     random.NewSelector,
+		"roundrobin": roundrobin.NewSelector,
+		"weighted":   weighted.NewSelector,
+	}
+
 	// name of the binary
 	name = "micro"
 	// description of the binary
@@ -199,6 +217,11 @@ var (
 			Usage:   "Client key for TLS with broker",
 			EnvVars: []string{"MICRO_BROKER_TLS_KEY"},
 		},
+		&cli.StringFlag{
+			Name:    "store",
+			Usage:   "Set the store used by the local profile: file or badger",
+			EnvVars: []string{"MICRO_STORE"},
+		},
 		&cli.StringFlag{
 			Name:    "store_address",
 			EnvVars: []string{"MICRO_STORE_ADDRESS"},
@@ -227,9 +250,79 @@ var (
 		},
 		&cli.StringFlag{
 			Name:    "service_address",
-			Usage:   "Address to run the service on",
+			Usage:   "Address to run the service on, such as :8080 or unix:///var/run/micro.sock",
 			EnvVars: []string{"MICRO_SERVICE_ADDRESS"},
 		},
+		&cli.StringFlag{
+			Name:    "zone",
+			Usage:   "Zone the service runs in, calls are routed to services in the same zone when there are any",
+			EnvVars: []string{"MICRO_ZONE"},
+		},
+		&cli.StringFlag{
+			Name:    "region",
+			Usage:   "Region the service runs in, calls are routed to services in the same region when there are none in the zone",
+			EnvVars: []string{"MICRO_REGION"},
+		},
+		&cli.StringFlag{
+			Name:    "selector",
+			Usage:   "Selector used to balance calls across the nodes of a service: roundrobin, random, weighted, leastload, which prefers the nodes with the fewest calls in flight and lowest latency, or consistent, which sends the calls with the same Micro-Select-Key header to the same node",
+			EnvVars: []string{"MICRO_SELECTOR"},
+		},
+		&cli.StringFlag{
+			Name:    "static_routes",
+			Usage:   "Path to a JSON file of the addresses of services which don't register, e.g. {\"billing\": [\"10.0.0.1:8080\"]}",
+			EnvVars: []string{"MICRO_STATIC_ROUTES"},
+		},
+		&cli.BoolFlag{
+			Name:    "outlier_detection",
+			Usage:   "Temporarily stop calling the nodes of a service which fail too many calls",
+			EnvVars: []string{"MICRO_OUTLIER_DETECTION"},
+		},
+		&cli.IntFlag{
+			Name:    "client_pool_size",
+			Usage:   "Number of connections to each node kept by the client",
+			EnvVars: []string{"MICRO_CLIENT_POOL_SIZE"},
+		},
+		&cli.DurationFlag{
+			Name:    "client_pool_ttl",
+			Usage:   "How long the client keeps a connection before closing it",
+			EnvVars: []string{"MICRO_CLIENT_POOL_TTL"},
+		},
+		&cli.DurationFlag{
+			Name:    "client_pool_idle_timeout",
+			Usage:   "How long the client keeps an idle connection before closing it",
+			EnvVars: []string{"MICRO_CLIENT_POOL_IDLE_TIMEOUT"},
+		},
+		&cli.IntFlag{
+			Name:    "client_pool_max_idle",
+			Usage:   "Number of idle connections to each node kept by the client",
+			EnvVars: []string{"MICRO_CLIENT_POOL_MAX_IDLE"},
+		},
+		&cli.IntFlag{
+			Name:    "client_pool_max_streams",
+			Usage:   "Number of concurrent calls made on a connection by the client",
+			EnvVars: []string{"MICRO_CLIENT_POOL_MAX_STREAMS"},
+		},
+		&cli.IntFlag{
+			Name:    "server_max_concurrent_requests",
+			Usage:   "Number of requests served concurrently, the rest are queued or rejected",
+			EnvVars: []string{"MICRO_SERVER_MAX_CONCURRENT_REQUESTS"},
+		},
+		&cli.IntFlag{
+			Name:    "server_request_queue",
+			Usage:   "Number of requests which wait for the concurrency limit before they're rejected",
+			EnvVars: []string{"MICRO_SERVER_REQUEST_QUEUE"},
+		},
+		&cli.BoolFlag{
+			Name:    "server_adaptive_limit",
+			Usage:   "Adapt the concurrency limit to the latency of the requests, up to 10 times the max concurrent requests",
+			EnvVars: []string{"MICRO_SERVER_ADAPTIVE_LIMIT"},
+		},
+		&cli.BoolFlag{
+			Name:    "circuit_breaker",
+			Usage:   "Fail the calls to a service endpoint which fails or is slow too often until it recovers",
+			EnvVars: []string{"MICRO_CIRCUIT_BREAKER"},
+		},
 		&cli.StringFlag{
 			Name:    "config_secret_key",
 			Usage:   "Key to use when encoding/decoding secret config values. Will be generated and saved to file if not provided.",
@@ -271,6 +364,27 @@ func setupDefaults() {
 	runtime.DefaultRuntime = runtimeSrv.NewRuntime()
 }
 
+// loadStaticRoutes loads the routes from a JSON file of the addresses of each service
+func loadStaticRoutes(path string) ([]router.Route, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var services map[string][]string
+	if err := json.Unmarshal(b, &services); err != nil {
+		return nil, fmt.Errorf("invalid static routes in %v: %v", path, err)
+	}
+
+	var routes []router.Route
+	for service, addrs := range services {
+		for _, addr := range addrs {
+			routes = append(routes, router.Route{Service: service, Address: addr})
+		}
+	}
+	return routes, nil
+}
+
 func formatErr(err error) string {
 	switch v := err.(type) {
 	case *errors.Error:
@@ -549,6 +663,15 @@ func (c *command) Before(ctx *cli.Context) error {
 		profile.Setup(ctx)
 	}
 
+	// merge the static routes with the routes looked up by the router
+	if path := ctx.String("static_routes"); len(path) > 0 {
+		routes, err := loadStaticRoutes(path)
+		if err != nil {
+			logger.Fatalf("Error loading the static routes: %v", err)
+		}
+		router.DefaultRouter.Init(router.StaticRoutes(routes...))
+	}
+
 	// set the proxy address
 	var proxy string
 	if c.service || ctx.IsSet("proxy_address") {
@@ -575,6 +698,9 @@ func (c *command) Before(ctx *cli.Context) error {
 
 	onceBefore.Do(func() {
 		// wrap the client
+		if ctx.Bool("circuit_breaker") {
+			client.DefaultClient = wrapper.BreakerClient(client.DefaultClient)
+		}
 		client.DefaultClient = wrapper.AuthClient(client.DefaultClient)
 		client.DefaultClient = wrapper.TraceCall(client.DefaultClient)
 		client.DefaultClient = wrapper.LogClient(client.DefaultClient)
@@ -589,6 +715,18 @@ func (c *command) Before(ctx *cli.Context) error {
 			server.WrapHandler(wrapper.MetricsHandler()),
 			server.WrapHandler(wrapper.OpenTraceHandler()),
 		)
+
+		// limit the requests served concurrently
+		if n := ctx.Int("server_max_concurrent_requests"); n > 0 {
+			opts := []limiter.Option{
+				limiter.Limit(n),
+				limiter.QueueSize(ctx.Int("server_request_queue")),
+			}
+			if ctx.Bool("server_adaptive_limit") {
+				opts = append(opts, limiter.Adaptive(1, n*10))
+			}
+			server.DefaultServer.Init(server.WrapHandler(wrapper.LimitHandler(limiter.New(opts...))))
+		}
 	})
 
 	// setup auth
@@ -637,6 +775,58 @@ func (c *command) Before(ctx *cli.Context) error {
 	// initialize the server with the namespace so it knows which domain to register in
 	server.DefaultServer.Init(server.Namespace(ctx.String("namespace")))
 
+	// register the service with its zone and region and prefer the routes in them
+	if zone, region := ctx.String("zone"), ctx.String("region"); len(zone) > 0 || len(region) > 0 {
+		md := map[string]string{}
+		for k, v := range server.DefaultServer.Options().Metadata {
+			md[k] = v
+		}
+		if len(zone) > 0 {
+			md[registry.ZoneKey] = zone
+		}
+		if len(region) > 0 {
+			md[registry.RegionKey] = region
+		}
+		server.DefaultServer.Init(server.Metadata(md))
+		client.DefaultClient.Init(client.Zone(zone), client.Region(region))
+	}
+
+	// balance calls with the selector requested
+	if name := ctx.String("selector"); len(name) > 0 {
+		sel, ok := selectors[name]
+		if !ok {
+			logger.Fatalf("Unknown selector %v", name)
+		}
+		client.DefaultClient.Init(client.Selector(sel()))
+	}
+
+	// tune the connection pool of the client
+	var poolOpts []client.Option
+	if ctx.IsSet("client_pool_size") {
+		poolOpts = append(poolOpts, client.PoolSize(ctx.Int("client_pool_size")))
+	}
+	if ctx.IsSet("client_pool_ttl") {
+		poolOpts = append(poolOpts, client.PoolTTL(ctx.Duration("client_pool_ttl")))
+	}
+	if ctx.IsSet("client_pool_idle_timeout") {
+		poolOpts = append(poolOpts, grpcCli.PoolIdleTimeout(ctx.Duration("client_pool_idle_timeout")))
+	}
+	if ctx.IsSet("client_pool_max_idle") {
+		poolOpts = append(poolOpts, grpcCli.PoolMaxIdle(ctx.Int("client_pool_max_idle")))
+	}
+	if ctx.IsSet("client_pool_max_streams") {
+		poolOpts = append(poolOpts, grpcCli.PoolMaxStreams(ctx.Int("client_pool_max_streams")))
+	}
+	if len(poolOpts) > 0 {
+		client.DefaultClient.Init(poolOpts...)
+	}
+
+	// stop calling the nodes which fail too many calls until they recover
+	if ctx.Bool("outlier_detection") {
+		sel := outlier.NewSelector(client.DefaultClient.Options().Selector)
+		client.DefaultClient.Init(client.Selector(sel))
+	}
+
 	// setup registry
 	registryOpts := []registry.Option{}
 
@@ -694,6 +884,8 @@ func (c *command) Before(ctx *cli.Context) error {
 		cfg := &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: caCertPool}
 		brokerOpts = append(brokerOpts, broker.TLSConfig(cfg))
 	}
+	// wrap the broker so the publish and subscribe wrappers are applied whichever broker is used
+	broker.DefaultBroker = broker.Wrap(broker.DefaultBroker)
 	if err := broker.DefaultBroker.Init(brokerOpts...); err != nil {
 		logger.Fatalf("Error configuring broker: %v", err)
 	}
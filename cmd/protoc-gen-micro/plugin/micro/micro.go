@@ -388,6 +388,10 @@ func (g *micro) generateClientMethod(reqServ, servName, serviceDescVar string, m
 		g.P("Close() error")
 	}
 
+	if genSend && genRecv {
+		// bidirectional streaming, the client can stop sending while still receiving
+		g.P("CloseSend() error")
+	}
 	if genSend {
 		g.P("Send(*", inType, ") error")
 	}
@@ -420,6 +424,13 @@ func (g *micro) generateClientMethod(reqServ, servName, serviceDescVar string, m
 		g.P()
 	}
 
+	if genSend && genRecv {
+		g.P("func (x *", streamType, ") CloseSend() error {")
+		g.P("return x.stream.CloseSend()")
+		g.P("}")
+		g.P()
+	}
+
 	g.P("func (x *", streamType, ") Context() context.Context {")
 	g.P("return x.stream.Context()")
 	g.P("}")
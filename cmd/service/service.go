@@ -176,6 +176,7 @@ var srvCommands = []srvCommand{
 	{
 		Name:    "registry",
 		Command: registry.Run,
+		Flags:   registry.Flags,
 	},
 	{
 		Name:    "runtime",
@@ -185,6 +186,7 @@ var srvCommands = []srvCommand{
 	{
 		Name:    "store",
 		Command: store.Run,
+		Flags:   store.Flags,
 	},
 	{
 		Name:    "web",
@@ -248,7 +250,7 @@ func init() {
 			},
 			&ccli.StringFlag{
 				Name:    "address",
-				Usage:   "Address of the service",
+				Usage:   "Address of the service e.g :8080 or unix:///var/run/micro.sock",
 				EnvVars: []string{"MICRO_SERVICE_ADDRESS"},
 			},
 			&ccli.StringFlag{
@@ -0,0 +1,341 @@
+// Package azure implements the micro blob store with Azure Blob Storage
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+)
+
+var (
+	doubleSlash = regexp.MustCompile("/+")
+	removeCol   = regexp.MustCompile(":")
+	// container names may only contain lowercase letters, numbers and hyphens
+	invalidContainer = regexp.MustCompile("[^a-z0-9-]+")
+)
+
+func cleanKey(s string) string {
+	return doubleSlash.ReplaceAllLiteralString(removeCol.ReplaceAllLiteralString(s, "/"), "/")
+}
+
+// containerName returns the name of the container of the namespace
+func containerName(ns string) string {
+	return strings.Trim(invalidContainer.ReplaceAllLiteralString(strings.ToLower(ns), "-"), "-")
+}
+
+// NewBlobStore returns an initialized azure blob store. Blobs are written to the container set
+// with the Container option, under a directory for their namespace, or if it isn't set to a
+// container for each namespace.
+func NewBlobStore(opts ...Option) (store.BlobStore, error) {
+	// parse the options
+	options := Options{
+		Account: os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		Key:     os.Getenv("AZURE_STORAGE_KEY"),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Account) == 0 {
+		return nil, fmt.Errorf("missing storage account")
+	}
+	if len(options.Endpoint) == 0 {
+		options.Endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", options.Account)
+	}
+
+	u, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// without a key the blobs must be public, or the endpoint have a SAS token
+	var cred azblob.Credential = azblob.NewAnonymousCredential()
+	var key *azblob.SharedKeyCredential
+	if len(options.Key) > 0 {
+		if key, err = azblob.NewSharedKeyCredential(options.Account, options.Key); err != nil {
+			return nil, err
+		}
+		cred = key
+	}
+
+	// return the blob store
+	return &azure{
+		service: azblob.NewServiceURL(*u, azblob.NewPipeline(cred, azblob.PipelineOptions{})),
+		key:     key,
+		options: &options,
+	}, nil
+}
+
+type azure struct {
+	service azblob.ServiceURL
+	// key signs urls, it's nil if the store has no storage key
+	key     *azblob.SharedKeyCredential
+	options *Options
+}
+
+// object returns the container and name of the blob the key of the namespace is written to
+func (a *azure) object(namespace, key string) (string, string) {
+	if len(a.options.Container) > 0 {
+		return a.options.Container, path.Join(namespace, key)
+	}
+	return containerName(namespace), key
+}
+
+// hasCode returns true if the error is a storage error with one of the codes
+func hasCode(err error, codes ...azblob.ServiceCodeType) bool {
+	serr, ok := err.(azblob.StorageError)
+	if !ok {
+		return false
+	}
+	for _, c := range codes {
+		if serr.ServiceCode() == c {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotFound returns true if the error is for a missing blob or container
+func isNotFound(err error) bool {
+	return hasCode(err, azblob.ServiceCodeBlobNotFound, azblob.ServiceCodeContainerNotFound)
+}
+
+func (a *azure) Read(key string, opts ...store.BlobOption) (io.Reader, error) {
+	// validate the key
+	if len(key) == 0 {
+		return nil, store.ErrMissingKey
+	}
+
+	// make the key safe for use as a blob name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	container, name := a.object(options.Namespace, key)
+	blob := a.service.NewContainerURL(container).NewBlobURL(name)
+	res, err := blob.Download(context.TODO(), 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+	if isNotFound(err) {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// the blob is streamed from the response, which is closed by the caller
+	return res.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3}), nil
+}
+
+func (a *azure) Write(key string, blob io.Reader, opts ...store.BlobOption) error {
+	// validate the key
+	if len(key) == 0 {
+		return store.ErrMissingKey
+	}
+
+	// make the key safe for use as a blob name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	container, name := a.object(options.Namespace, key)
+	cu := a.service.NewContainerURL(container)
+	logger.Infof("Saving file %v into container %v", name, container)
+
+	// without a container each namespace has its own, which may not exist yet. Access to
+	// blobs is set per container so public blobs are only readable anonymously in them.
+	if len(a.options.Container) == 0 {
+		access := azblob.PublicAccessNone
+		if options.Public {
+			access = azblob.PublicAccessBlob
+		}
+		_, err := cu.Create(context.TODO(), azblob.Metadata{}, access)
+		if err != nil && !hasCode(err, azblob.ServiceCodeContainerAlreadyExists) {
+			return err
+		}
+	}
+
+	partSize := a.options.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	_, err := azblob.UploadStreamToBlockBlob(context.TODO(), blob, cu.NewBlockBlobURL(name), azblob.UploadStreamToBlockBlobOptions{
+		BufferSize:      partSize,
+		MaxBuffers:      1,
+		BlobHTTPHeaders: azblob.BlobHTTPHeaders{ContentType: options.ContentType},
+		Metadata:        options.Metadata,
+	})
+	return err
+}
+
+func (a *azure) Delete(key string, opts ...store.BlobOption) error {
+	// validate the key
+	if len(key) == 0 {
+		return store.ErrMissingKey
+	}
+
+	// make the key safe for use as a blob name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	container, name := a.object(options.Namespace, key)
+	blob := a.service.NewContainerURL(container).NewBlobURL(name)
+	_, err := blob.Delete(context.TODO(), azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{})
+	// deleting a missing blob is a no-op
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (a *azure) List(opts ...store.BlobListOption) ([]string, error) {
+	items, keyPrefix, err := a.list(azblob.BlobListingDetails{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(items))
+	for i, b := range items {
+		// return the key without the prefix
+		keys[i] = strings.TrimPrefix(b.Name, keyPrefix)
+	}
+	// return the result
+	return keys, nil
+}
+
+func (a *azure) ListInfo(opts ...store.BlobListOption) ([]*store.BlobInfo, error) {
+	items, keyPrefix, err := a.list(azblob.BlobListingDetails{Metadata: true}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make([]*store.BlobInfo, len(items))
+	for i, b := range items {
+		blobs[i] = &store.BlobInfo{
+			Key:      strings.TrimPrefix(b.Name, keyPrefix),
+			Metadata: b.Metadata,
+			Modified: b.Properties.LastModified,
+		}
+		if b.Properties.ContentLength != nil {
+			blobs[i].Size = *b.Properties.ContentLength
+		}
+		if b.Properties.ContentType != nil {
+			blobs[i].ContentType = *b.Properties.ContentType
+		}
+	}
+	return blobs, nil
+}
+
+// list returns the blobs of the namespace which match, and the prefix of their names which isn't
+// part of the key of the blob
+func (a *azure) list(details azblob.BlobListingDetails, opts ...store.BlobListOption) ([]azblob.BlobItemInternal, string, error) {
+	// parse the options
+	var options store.BlobListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	// with a container the keys of the namespace are in its directory
+	container, prefix := containerName(options.Namespace), options.Prefix
+	keyPrefix := ""
+	if len(a.options.Container) > 0 {
+		keyPrefix = options.Namespace + "/"
+		container, prefix = a.options.Container, keyPrefix+options.Prefix
+	}
+
+	cu := a.service.NewContainerURL(container)
+	items := []azblob.BlobItemInternal{}
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		res, err := cu.ListBlobsFlatSegment(context.TODO(), marker, azblob.ListBlobsSegmentOptions{Prefix: prefix, Details: details})
+		if isNotFound(err) {
+			return items, keyPrefix, nil
+		} else if err != nil {
+			return nil, "", err
+		}
+		items = append(items, res.Segment.BlobItems...)
+		marker = res.NextMarker
+	}
+	return items, keyPrefix, nil
+}
+
+func (a *azure) PresignGet(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return a.presign(key, ttl, azblob.BlobSASPermissions{Read: true}, opts...)
+}
+
+// PresignPut returns a url the blob can be uploaded to. Azure requires uploads to the url to set
+// the x-ms-blob-type header to BlockBlob.
+func (a *azure) PresignPut(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return a.presign(key, ttl, azblob.BlobSASPermissions{Create: true, Write: true}, opts...)
+}
+
+// presign returns the url of the blob with a SAS token granting the permissions until the ttl expires
+func (a *azure) presign(key string, ttl time.Duration, perms azblob.BlobSASPermissions, opts ...store.BlobOption) (string, error) {
+	// validate the key
+	if len(key) == 0 {
+		return "", store.ErrMissingKey
+	}
+
+	// SAS tokens are signed with the storage key
+	if a.key == nil {
+		return "", store.ErrNotSupported
+	}
+
+	// make the key safe for use as a blob name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	container, name := a.object(options.Namespace, key)
+	sas, err := azblob.BlobSASSignatureValues{
+		ExpiryTime:    time.Now().UTC().Add(ttl),
+		Permissions:   perms.String(),
+		ContainerName: container,
+		BlobName:      name,
+	}.NewSASQueryParameters(a.key)
+	if err != nil {
+		return "", err
+	}
+
+	parts := azblob.NewBlobURLParts(a.service.NewContainerURL(container).NewBlobURL(name).URL())
+	parts.SAS = sas
+	u := parts.URL()
+	return u.String(), nil
+}
@@ -0,0 +1,120 @@
+package azure
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerName(t *testing.T) {
+	assert.Equal(t, "micro", containerName("micro"))
+	assert.Equal(t, "foo-bar", containerName("Foo_Bar"))
+	assert.Equal(t, "foo", containerName("-foo."))
+}
+
+func TestObject(t *testing.T) {
+	a := &azure{options: &Options{}}
+	c, n := a.object("Foo", "build/name/version")
+	assert.Equal(t, "foo", c)
+	assert.Equal(t, "build/name/version", n)
+
+	a = &azure{options: &Options{Container: "blobs"}}
+	c, n = a.object("Foo", "build/name/version")
+	assert.Equal(t, "blobs", c)
+	assert.Equal(t, "Foo/build/name/version", n)
+}
+
+func TestPresign(t *testing.T) {
+	// without a storage key urls can't be signed
+	blob, err := NewBlobStore(Credentials("micro", ""))
+	assert.NoError(t, err)
+	_, err = blob.(store.Presigner).PresignGet("foo", time.Minute)
+	assert.Equal(t, store.ErrNotSupported, err)
+
+	blob, err = NewBlobStore(Credentials("micro", "c2VjcmV0"), Container("blobs"))
+	assert.NoError(t, err)
+	p := blob.(store.Presigner)
+
+	_, err = p.PresignGet("", time.Minute)
+	assert.Equal(t, store.ErrMissingKey, err)
+
+	// signing doesn't call azure, so the urls can be checked offline
+	get, err := p.PresignGet("build://name:version", time.Minute, store.BlobNamespace("foo"))
+	assert.NoError(t, err)
+	u, err := url.Parse(get)
+	assert.NoError(t, err)
+	assert.Equal(t, "micro.blob.core.windows.net", u.Host)
+	assert.Equal(t, "/blobs/foo/build/name/version", u.Path)
+	assert.Equal(t, "r", u.Query().Get("sp"))
+	assert.NotEmpty(t, u.Query().Get("sig"))
+
+	put, err := p.PresignPut("blob", time.Hour, store.BlobNamespace("foo"))
+	assert.NoError(t, err)
+	u, err = url.Parse(put)
+	assert.NoError(t, err)
+	assert.Equal(t, "/blobs/foo/blob", u.Path)
+	assert.Equal(t, "cw", u.Query().Get("sp"))
+}
+
+func TestBlobStore(t *testing.T) {
+	account := os.Getenv("AZURE_BLOB_STORE_ACCOUNT")
+	if len(account) == 0 {
+		t.Skipf("Missing required config AZURE_BLOB_STORE_ACCOUNT")
+	}
+
+	key := os.Getenv("AZURE_BLOB_STORE_KEY")
+	if len(key) == 0 {
+		t.Skipf("Missing required config AZURE_BLOB_STORE_KEY")
+	}
+
+	opts := []Option{Credentials(account, key)}
+	if e := os.Getenv("AZURE_BLOB_STORE_ENDPOINT"); len(e) > 0 {
+		opts = append(opts, Endpoint(e))
+	}
+	blob, err := NewBlobStore(opts...)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	_, err = blob.Read("")
+	assert.Equal(t, store.ErrMissingKey, err)
+
+	_, err = blob.Read("foo")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	assert.NoError(t, blob.Write("hello", bytes.NewBufferString("world")))
+
+	val, err := blob.Read("hello")
+	assert.NoError(t, err)
+	if val != nil {
+		b, _ := ioutil.ReadAll(val)
+		assert.Equal(t, "world", string(b))
+	}
+
+	_, err = blob.Read("hello", store.BlobNamespace("bar"))
+	assert.Equal(t, store.ErrNotFound, err)
+
+	keys, err := blob.List()
+	assert.NoError(t, err)
+	assert.Contains(t, keys, "hello")
+
+	blobs, err := blob.(store.BlobInfoLister).ListInfo(store.BlobListPrefix("hello"))
+	assert.NoError(t, err)
+	if assert.Len(t, blobs, 1) {
+		assert.Equal(t, int64(len("world")), blobs[0].Size)
+		assert.False(t, blobs[0].Modified.IsZero())
+	}
+
+	assert.NoError(t, blob.Delete("hello", store.BlobNamespace("bar")))
+	assert.NoError(t, blob.Delete("hello"))
+
+	_, err = blob.Read("hello")
+	assert.Equal(t, store.ErrNotFound, err)
+}
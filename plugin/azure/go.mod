@@ -0,0 +1,11 @@
+module github.com/micro/micro/plugin/azure/v3
+
+go 1.15
+
+require (
+	github.com/Azure/azure-storage-blob-go v0.15.0
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/stretchr/testify v1.7.0
+)
+
+replace github.com/micro/micro/v3 => ../..
@@ -0,0 +1,49 @@
+package azure
+
+// DefaultPartSize is the size of the blocks blobs are uploaded in if it isn't set
+const DefaultPartSize = 4 * 1024 * 1024
+
+// Options used to configure the azure blob store
+type Options struct {
+	Account   string
+	Key       string
+	Container string
+	Endpoint  string
+	PartSize  int
+}
+
+// Option configures one or more options
+type Option func(o *Options)
+
+// Credentials sets the storage account and its shared key. If they aren't set they're read
+// from the AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY env vars.
+func Credentials(account, key string) Option {
+	return func(o *Options) {
+		o.Account = account
+		o.Key = key
+	}
+}
+
+// Container sets the container all the blobs are written to. If it isn't set each namespace
+// has its own container.
+func Container(name string) Option {
+	return func(o *Options) {
+		o.Container = name
+	}
+}
+
+// Endpoint sets the URL of the blob service, which is the account's endpoint on Azure by
+// default. Azurite serves the account in the path e.g. http://127.0.0.1:10000/devstoreaccount1
+func Endpoint(e string) Option {
+	return func(o *Options) {
+		o.Endpoint = e
+	}
+}
+
+// PartSize sets the size of the blocks blobs are uploaded in, so only one block of a blob is
+// held in memory at a time
+func PartSize(n int) Option {
+	return func(o *Options) {
+		o.PartSize = n
+	}
+}
@@ -0,0 +1,671 @@
+// Package cassandra implements a Cassandra / ScyllaDB backed store
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gocql/gocql"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultDatabase is the keyspace that the cassandra store
+	// will use if no database is provided.
+	DefaultDatabase = "micro"
+	// DefaultTable when none is specified
+	DefaultTable = "micro"
+	// DefaultTimeout for queries
+	DefaultTimeout = 5 * time.Second
+
+	// ErrNoConnection is returned when the store has not connected to cassandra
+	ErrNoConnection = errors.New("cassandra session not initialised")
+
+	// keyspace and table names may only contain letters, numbers and underscores
+	re = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+	// all the records of a table are kept in a single partition so that
+	// keys are sorted by the clustering column and prefixes can be ranged over
+	partition = "micro"
+)
+
+// NewStore returns a new micro Store backed by cassandra
+func NewStore(opts ...store.Option) store.Store {
+	s := &cassandraStore{
+		options: store.Options{
+			Database: DefaultDatabase,
+			Table:    DefaultTable,
+		},
+		tables: make(map[string]bool),
+	}
+
+	// best-effort configure the store
+	if err := s.Init(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return s
+}
+
+type cassandraStore struct {
+	options store.Options
+	session *gocql.Session
+
+	sync.RWMutex
+	// tables which have been created, keyed by keyspace.table
+	tables map[string]bool
+}
+
+func (c *cassandraStore) configure() error {
+	nodes := c.options.Nodes
+	if len(nodes) == 0 {
+		nodes = []string{"127.0.0.1"}
+	}
+
+	cluster := gocql.NewCluster(nodes...)
+	cluster.Timeout = DefaultTimeout
+	cluster.Consistency = gocql.Quorum
+
+	if ctx := c.options.Context; ctx != nil {
+		if cl, ok := ctx.Value(consistencyKey{}).(gocql.Consistency); ok {
+			cluster.Consistency = cl
+		}
+		if a, ok := ctx.Value(authKey{}).(*authCreds); ok {
+			cluster.Authenticator = gocql.PasswordAuthenticator{
+				Username: a.Username,
+				Password: a.Password,
+			}
+		}
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	if c.session != nil {
+		c.session.Close()
+	}
+	c.session = session
+	c.tables = make(map[string]bool)
+	c.Unlock()
+
+	// create the default table up front
+	_, err = c.table(c.options.Database, c.options.Table)
+	return err
+}
+
+// table returns the keyspace.table name for the database and table,
+// creating them if they don't yet exist
+func (c *cassandraStore) table(database, table string) (string, error) {
+	if len(database) == 0 {
+		database = c.options.Database
+	}
+	if len(table) == 0 {
+		table = c.options.Table
+	}
+
+	keyspace := strings.ToLower(re.ReplaceAllString(database, "_"))
+	name := keyspace + "." + strings.ToLower(re.ReplaceAllString(table, "_"))
+
+	c.RLock()
+	session, ok := c.session, c.tables[name]
+	c.RUnlock()
+
+	if session == nil {
+		return "", ErrNoConnection
+	}
+	if ok {
+		return name, nil
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.tables[name] {
+		return name, nil
+	}
+
+	replication := 1
+	if c.options.Context != nil {
+		if n, ok := c.options.Context.Value(replicationFactorKey{}).(int); ok && n > 0 {
+			replication = n
+		}
+	}
+
+	q := fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': %d}", keyspace, replication)
+	if err := session.Query(q).Exec(); err != nil {
+		return "", errors.Wrap(err, "Couldn't create keyspace")
+	}
+
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		partition text,
+		key text,
+		value blob,
+		metadata text,
+		PRIMARY KEY (partition, key)
+	) WITH CLUSTERING ORDER BY (key ASC)`, name)
+	if err := session.Query(q).Exec(); err != nil {
+		return "", errors.Wrap(err, "Couldn't create table")
+	}
+
+	c.tables[name] = true
+	return name, nil
+}
+
+func (c *cassandraStore) conn() (*gocql.Session, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.session == nil {
+		return nil, ErrNoConnection
+	}
+	return c.session, nil
+}
+
+// scan returns the records which start with prefix and end with suffix
+func (c *cassandraStore) scan(session *gocql.Session, table string, prefix, suffix string, order store.Order, limit, offset uint, keysOnly bool) ([]*store.Record, error) {
+	cols := "key, value, metadata, TTL(value)"
+	if keysOnly {
+		cols = "key"
+	}
+
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE partition = ?", cols, table)
+	args := []interface{}{partition}
+
+	// range over the clustering key rather than filtering every row
+	if len(prefix) > 0 {
+		q += " AND key >= ? AND key < ?"
+		args = append(args, prefix, prefix+string(utf8.MaxRune))
+	}
+
+	if order == store.OrderDesc {
+		q += " ORDER BY key DESC"
+	} else {
+		q += " ORDER BY key ASC"
+	}
+
+	// cassandra has no OFFSET so the skipped rows have to be fetched too,
+	// and the suffix is filtered client side
+	if limit > 0 && len(suffix) == 0 {
+		q += fmt.Sprintf(" LIMIT %d", limit+offset)
+	}
+
+	iter := session.Query(q, args...).Iter()
+
+	var records []*store.Record
+	var skipped uint
+
+	for {
+		var key, metadata string
+		var value []byte
+		var ttl int
+
+		var ok bool
+		if keysOnly {
+			ok = iter.Scan(&key)
+		} else {
+			ok = iter.Scan(&key, &value, &metadata, &ttl)
+		}
+		if !ok {
+			break
+		}
+
+		if len(suffix) > 0 && !strings.HasSuffix(key, suffix) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+
+		rec := &store.Record{
+			Key:      key,
+			Value:    value,
+			Metadata: make(map[string]interface{}),
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal([]byte(metadata), &rec.Metadata); err != nil {
+				iter.Close()
+				return nil, err
+			}
+		}
+		if ttl > 0 {
+			rec.Expiry = time.Duration(ttl) * time.Second
+		}
+
+		records = append(records, rec)
+
+		if limit > 0 && uint(len(records)) >= limit {
+			break
+		}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (c *cassandraStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&c.options)
+	}
+	return c.configure()
+}
+
+func (c *cassandraStore) Options() store.Options {
+	return c.options
+}
+
+func (c *cassandraStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	options := store.ReadOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// earlier revisions of records aren't kept
+	if options.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys aren't paged through with a cursor
+	if options.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys matched against a pattern are read like a prefix
+	if options.Match != nil && !options.Suffix {
+		options.Prefix = true
+	}
+
+	if len(options.Where) > 0 || options.OrderBy == store.OrderByExpiry || options.Match != nil {
+		return c.where(key, options)
+	}
+
+	table, err := c.table(options.Database, options.Table)
+	if err != nil {
+		return nil, err
+	}
+	session, err := c.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.Prefix && !options.Suffix {
+		var metadata string
+		var ttl int
+		rec := &store.Record{Key: key, Metadata: make(map[string]interface{})}
+
+		q := fmt.Sprintf("SELECT value, metadata, TTL(value) FROM %s WHERE partition = ? AND key = ?", table)
+		if err := session.Query(q, partition, key).Scan(&rec.Value, &metadata, &ttl); err == gocql.ErrNotFound {
+			return nil, store.ErrNotFound
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(metadata) > 0 {
+			if err := json.Unmarshal([]byte(metadata), &rec.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		if ttl > 0 {
+			rec.Expiry = time.Duration(ttl) * time.Second
+		}
+		return []*store.Record{rec}, nil
+	}
+
+	var prefix, suffix string
+	if options.Prefix {
+		prefix = key
+	}
+	if options.Suffix {
+		suffix = key
+	}
+
+	records, err := c.scan(session, table, prefix, suffix, options.Order, options.Limit, options.Offset, false)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*store.Record{}
+	}
+	return records, nil
+}
+
+// where reads the records without the metadata values and then filters them, as
+// cassandra can't query by metadata. The limit and offset are applied once filtered.
+func (c *cassandraStore) where(key string, options store.ReadOptions) ([]*store.Record, error) {
+	opts := []store.ReadOption{
+		store.ReadFrom(options.Database, options.Table),
+		store.ReadOrder(options.Order),
+	}
+	if options.Prefix {
+		opts = append(opts, store.ReadPrefix())
+	}
+	if options.Suffix {
+		opts = append(opts, store.ReadSuffix())
+	}
+
+	var match *regexp.Regexp
+	if options.Match != nil {
+		var err error
+		if match, err = options.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	recs, err := c.Read(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*store.Record{}
+	for _, r := range recs {
+		if r.Match(options.Where) && (match == nil || match.MatchString(r.Key)) {
+			results = append(results, r)
+		}
+	}
+	if options.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, options.Order)
+	}
+
+	if !options.Prefix && !options.Suffix {
+		if len(results) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return results, nil
+	}
+
+	if options.Offset > 0 {
+		// offset is greater than the records we have
+		if int(options.Offset) >= len(results) {
+			return []*store.Record{}, nil
+		}
+		results = results[options.Offset:]
+	}
+
+	if options.Limit > 0 && int(options.Limit) < len(results) {
+		results = results[:options.Limit]
+	}
+
+	return results, nil
+}
+
+func (c *cassandraStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if c.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// records aren't versioned
+	if options.IfVersion > 0 || options.IfNotExists {
+		return store.ErrNotSupported
+	}
+
+	table, err := c.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	session, err := c.conn()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(r.Metadata)
+	if err != nil {
+		return err
+	}
+
+	// a TTL of 0 means the record never expires
+	var ttl int
+	if r.Expiry > 0 {
+		ttl = int(math.Ceil(r.Expiry.Seconds()))
+	}
+
+	q := fmt.Sprintf("INSERT INTO %s (partition, key, value, metadata) VALUES (?, ?, ?, ?) USING TTL ?", table)
+	if err := session.Query(q, partition, r.Key, r.Value, string(metadata), ttl).Exec(); err != nil {
+		return errors.Wrap(err, "Couldn't write record "+r.Key)
+	}
+
+	return nil
+}
+
+// Touch rewrites the record with the new TTL, cassandra expires cells rather than rows so
+// the value has to be written again. The update is conditional on the value which was read
+// so it's retried rather than overwriting a concurrent write.
+func (c *cassandraStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if c.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := c.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	session, err := c.conn()
+	if err != nil {
+		return err
+	}
+
+	// a TTL of 0 means the record never expires
+	var ttl int
+	if expiry > 0 {
+		ttl = int(math.Ceil(expiry.Seconds()))
+	}
+
+	read := fmt.Sprintf("SELECT value, metadata FROM %s WHERE partition = ? AND key = ?", table)
+	update := fmt.Sprintf("UPDATE %s USING TTL ? SET value = ?, metadata = ? WHERE partition = ? AND key = ? IF value = ? AND metadata = ?", table)
+
+	for {
+		var value []byte
+		var metadata string
+		if err := session.Query(read, partition, key).WithContext(ctx).Scan(&value, &metadata); err == gocql.ErrNotFound {
+			return store.ErrNotFound
+		} else if err != nil {
+			return err
+		}
+
+		applied, err := session.Query(update, ttl, value, metadata, partition, key, value, metadata).
+			WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+		if err != nil {
+			return errors.Wrap(err, "Couldn't touch record "+key)
+		}
+		if applied {
+			return nil
+		}
+	}
+}
+
+func (c *cassandraStore) Delete(key string, opts ...store.DeleteOption) error {
+	if c.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := c.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	session, err := c.conn()
+	if err != nil {
+		return err
+	}
+
+	if !options.Prefix && !options.Suffix {
+		q := fmt.Sprintf("DELETE FROM %s WHERE partition = ? AND key = ?", table)
+		return session.Query(q, partition, key).Exec()
+	}
+
+	var prefix, suffix string
+	if options.Prefix {
+		prefix = key
+	}
+	if options.Suffix {
+		suffix = key
+	}
+
+	records, err := c.scan(session, table, prefix, suffix, store.OrderAsc, 0, 0, true)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(records))
+	for i, r := range records {
+		keys[i] = r.Key
+	}
+	return c.deleteMany(session, table, keys)
+}
+
+// DeleteMany deletes the records with a single statement
+func (c *cassandraStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if c.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := c.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	session, err := c.conn()
+	if err != nil {
+		return err
+	}
+
+	return c.deleteMany(session, table, keys)
+}
+
+func (c *cassandraStore) deleteMany(session *gocql.Session, table string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	// all the keys are in one partition so they can be removed with a single IN query
+	q := fmt.Sprintf("DELETE FROM %s WHERE partition = ? AND key IN ?", table)
+	if err := session.Query(q, partition, keys).Exec(); err != nil {
+		return errors.Wrap(err, "Couldn't delete records")
+	}
+	return nil
+}
+
+func (c *cassandraStore) List(opts ...store.ListOption) ([]string, error) {
+	options := store.ListOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(options.Delimiter) > 0 {
+		return store.ListDelimited(c, options)
+	}
+	if options.Match != nil {
+		return store.ListMatched(c, options)
+	}
+
+	if options.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	if options.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := c.where(options.Prefix, store.ReadOptions{
+			Database: options.Database,
+			Table:    options.Table,
+			Prefix:   true,
+			Order:    options.Order,
+			OrderBy:  options.OrderBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys := []string{}
+		for _, r := range recs {
+			if strings.HasSuffix(r.Key, options.Suffix) {
+				keys = append(keys, r.Key)
+			}
+		}
+		if options.Offset > 0 {
+			// offset is greater than the keys we have
+			if int(options.Offset) >= len(keys) {
+				return []string{}, nil
+			}
+			keys = keys[options.Offset:]
+		}
+		if options.Limit > 0 && int(options.Limit) < len(keys) {
+			keys = keys[:options.Limit]
+		}
+		return keys, nil
+	}
+
+	table, err := c.table(options.Database, options.Table)
+	if err != nil {
+		return nil, err
+	}
+	session, err := c.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := c.scan(session, table, options.Prefix, options.Suffix, options.Order, options.Limit, options.Offset, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, r := range records {
+		keys = append(keys, r.Key)
+	}
+	return keys, nil
+}
+
+// Healthy queries the cassandra cluster
+func (c *cassandraStore) Healthy(ctx context.Context) error {
+	return c.session.Query("SELECT now() FROM system.local").WithContext(ctx).Exec()
+}
+
+func (c *cassandraStore) Close() error {
+	c.Lock()
+	defer c.Unlock()
+	if c.session != nil {
+		c.session.Close()
+		c.session = nil
+	}
+	return nil
+}
+
+func (c *cassandraStore) String() string {
+	return "cassandra"
+}
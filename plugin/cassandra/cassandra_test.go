@@ -0,0 +1,24 @@
+package cassandra
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/storetest"
+)
+
+const addr = "127.0.0.1"
+
+func TestConformance(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(addr, "9042"), time.Second)
+	if err != nil {
+		t.Skipf("Cassandra isn't reachable at %s: %v", addr, err)
+	}
+	conn.Close()
+
+	storetest.Conformance(t, func() store.Store {
+		return NewStore(store.Nodes(addr))
+	})
+}
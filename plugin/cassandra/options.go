@@ -0,0 +1,43 @@
+package cassandra
+
+import (
+	"context"
+
+	"github.com/gocql/gocql"
+	"github.com/micro/micro/v3/service/store"
+)
+
+type consistencyKey struct{}
+
+type replicationFactorKey struct{}
+
+type authKey struct{}
+
+type authCreds struct {
+	Username string
+	Password string
+}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// Consistency sets the consistency level used for reads and writes, defaults to Quorum
+func Consistency(c gocql.Consistency) store.Option {
+	return setOption(consistencyKey{}, c)
+}
+
+// ReplicationFactor sets the replication factor used when creating a keyspace, defaults to 1
+func ReplicationFactor(n int) store.Option {
+	return setOption(replicationFactorKey{}, n)
+}
+
+// Auth allows you to specify username/password
+func Auth(username, password string) store.Option {
+	return setOption(authKey{}, &authCreds{Username: username, Password: password})
+}
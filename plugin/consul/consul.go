@@ -0,0 +1,442 @@
+// Package consul provides a consul service registry
+package consul
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+	hash "github.com/mitchellh/hashstructure"
+)
+
+const (
+	// the tags a micro service is registered with, the values are base64 encoded JSON
+	domainTag   = "micro-domain="
+	versionTag  = "micro-version="
+	metadataTag = "micro-metadata="
+	endpointTag = "micro-endpoint="
+
+	// defaultDeregisterAfter is the minimum consul allows
+	defaultDeregisterAfter = time.Minute
+)
+
+// metaKey matches the keys consul allows in the metadata of a service
+var metaKey = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,128}$`)
+
+type consulRegistry struct {
+	options registry.Options
+
+	sync.RWMutex
+	client *consul.Client
+	// the hashes of the registered nodes by service id, so unchanged nodes aren't registered
+	// again, their TTL check is passed instead
+	register map[string]uint64
+}
+
+// NewRegistry returns a registry which registers each node of a service as a consul service
+// instance with the local agent, with a TTL check if it's registered with a TTL. The services in
+// the consul catalog which weren't registered by micro are returned in the default domain, so
+// micro services can discover them.
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	c := &consulRegistry{
+		register: make(map[string]uint64),
+	}
+	if err := c.configure(opts...); err != nil {
+		logger.Errorf("Error configuring consul registry: %v", err)
+	}
+	return c
+}
+
+// configure sets up the client with the options
+func (c *consulRegistry) configure(opts ...registry.Option) error {
+	for _, o := range opts {
+		o(&c.options)
+	}
+	if c.options.Timeout == 0 {
+		c.options.Timeout = 5 * time.Second
+	}
+
+	config := consul.DefaultConfig()
+	for _, addr := range c.options.Addrs {
+		if len(addr) == 0 {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "8500")
+		}
+		config.Address = addr
+		break
+	}
+	if c.options.Secure || c.options.TLSConfig != nil {
+		config.Scheme = "https"
+		if c.options.TLSConfig != nil {
+			config.Transport.TLSClientConfig = c.options.TLSConfig
+		}
+	}
+	if ctx := c.options.Context; ctx != nil {
+		if token, ok := ctx.Value(tokenKey{}).(string); ok && len(token) > 0 {
+			config.Token = token
+		}
+		if dc, ok := ctx.Value(datacenterKey{}).(string); ok && len(dc) > 0 {
+			config.Datacenter = dc
+		}
+	}
+
+	client, err := consul.NewClient(config)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	c.client = client
+	c.Unlock()
+	return nil
+}
+
+func (c *consulRegistry) getClient() *consul.Client {
+	c.RLock()
+	defer c.RUnlock()
+	return c.client
+}
+
+func (c *consulRegistry) deregisterAfter() time.Duration {
+	if c.options.Context != nil {
+		if d, ok := c.options.Context.Value(deregisterAfterKey{}).(time.Duration); ok && d > defaultDeregisterAfter {
+			return d
+		}
+	}
+	return defaultDeregisterAfter
+}
+
+func (c *consulRegistry) Init(opts ...registry.Option) error {
+	return c.configure(opts...)
+}
+
+func (c *consulRegistry) Options() registry.Options {
+	return c.options
+}
+
+func (c *consulRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	// parse the options
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	var gerr error
+	for _, node := range s.Nodes {
+		if err := c.registerNode(s, node, options); err != nil {
+			gerr = err
+		}
+	}
+	return gerr
+}
+
+// registerNode registers the node as an instance of the service with the local agent
+func (c *consulRegistry) registerNode(s *registry.Service, node *registry.Node, options registry.RegisterOptions) error {
+	reg := encode(s, node, options.Domain)
+	checkID := "service:" + reg.ID
+	if options.TTL > 0 {
+		reg.Check = &consul.AgentServiceCheck{
+			TTL:                            options.TTL.String(),
+			DeregisterCriticalServiceAfter: c.deregisterAfter().String(),
+		}
+	}
+
+	h, err := hash.Hash(reg, nil)
+	if err != nil {
+		return err
+	}
+	c.RLock()
+	v, ok := c.register[reg.ID]
+	c.RUnlock()
+
+	client := c.getClient()
+	// the node is unchanged so only its TTL check is passed, it's registered again if the check
+	// doesn't exist, e.g. if the agent has restarted
+	if ok && v == h {
+		if options.TTL == 0 {
+			return nil
+		}
+		if err := client.Agent().PassTTL(checkID, ""); err == nil {
+			return nil
+		}
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("TTL check of %s id %s not found, registering", s.Name, node.Id)
+		}
+	}
+
+	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+		logger.Tracef("Registering %s id %s with ttl %v", s.Name, node.Id, options.TTL)
+	}
+	if err := client.Agent().ServiceRegister(reg); err != nil {
+		return err
+	}
+	if options.TTL > 0 {
+		if err := client.Agent().PassTTL(checkID, ""); err != nil {
+			return err
+		}
+	}
+
+	c.Lock()
+	c.register[reg.ID] = h
+	c.Unlock()
+	return nil
+}
+
+func (c *consulRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	for _, node := range s.Nodes {
+		c.Lock()
+		delete(c.register, node.Id)
+		c.Unlock()
+
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("Deregistering %s id %s", s.Name, node.Id)
+		}
+		if err := c.getClient().Agent().ServiceDeregister(node.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetService returns the versions of the service with the nodes which are passing their checks
+func (c *consulRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	// parse the options and fallback to the default domain
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	entries, _, err := c.getClient().Health().Service(name, "", true, c.queryOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]*registry.Service)
+	var keys []string
+	for _, e := range entries {
+		sn, domain := decode(e)
+		if options.Domain != registry.WildcardDomain && domain != options.Domain {
+			continue
+		}
+
+		// compose a key of name/version/domain
+		key := sn.Name + sn.Version + domain
+		s, ok := versions[key]
+		if !ok {
+			versions[key] = sn
+			keys = append(keys, key)
+			continue
+		}
+		s.Nodes = append(s.Nodes, sn.Nodes...)
+	}
+	services := make([]*registry.Service, 0, len(versions))
+	for _, key := range keys {
+		services = append(services, versions[key])
+	}
+	services = registry.FilterServices(services, options.Filters)
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	return services, nil
+}
+
+// ListServices returns a service for each version of the services in the catalog, without their
+// nodes. The version and domain are read from the tags of the instances of the service.
+func (c *consulRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	// parse the options
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	rsp, _, err := c.getClient().Catalog().Services(c.queryOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var services []*registry.Service
+	for name, tags := range rsp {
+		domains := map[string]bool{}
+		versions := map[string]bool{}
+		for _, t := range tags {
+			if strings.HasPrefix(t, domainTag) {
+				domains[decodeTag(t, domainTag)] = true
+			} else if strings.HasPrefix(t, versionTag) {
+				versions[decodeTag(t, versionTag)] = true
+			}
+		}
+		if len(domains) == 0 {
+			domains[registry.DefaultDomain] = true
+		}
+		if len(versions) == 0 {
+			versions[""] = true
+		}
+
+		for domain := range domains {
+			if options.Domain != registry.WildcardDomain && domain != options.Domain {
+				continue
+			}
+			for version := range versions {
+				services = append(services, &registry.Service{
+					Name:     name,
+					Version:  version,
+					Metadata: map[string]string{"domain": domain},
+				})
+			}
+		}
+	}
+
+	// sort the services
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Name == services[j].Name {
+			return services[i].Version < services[j].Version
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// Watch the services with blocking queries
+func (c *consulRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return newConsulWatcher(c, opts...)
+}
+
+func (c *consulRegistry) String() string {
+	return "consul"
+}
+
+// queryOptions returns the options of the queries, reading the services from any server
+func (c *consulRegistry) queryOptions() *consul.QueryOptions {
+	return &consul.QueryOptions{AllowStale: true}
+}
+
+// encode the node of the service as a consul service instance. The version, domain, metadata and
+// endpoints of the service are encoded in the tags, and the metadata of the node is set as the
+// metadata of the instance, skipping the keys consul doesn't allow.
+func encode(s *registry.Service, node *registry.Node, domain string) *consul.AgentServiceRegistration {
+	tags := []string{
+		encodeTag(domainTag, domain),
+		encodeTag(versionTag, s.Version),
+	}
+	if len(s.Metadata) > 0 {
+		b, _ := json.Marshal(s.Metadata)
+		tags = append(tags, encodeTag(metadataTag, string(b)))
+	}
+	for _, e := range s.Endpoints {
+		b, _ := json.Marshal(e)
+		tags = append(tags, encodeTag(endpointTag, string(b)))
+	}
+
+	meta := make(map[string]string, len(node.Metadata))
+	for k, v := range node.Metadata {
+		if !metaKey.MatchString(k) || strings.HasPrefix(k, "consul-") || len(v) > 512 {
+			if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+				logger.Tracef("Skipping metadata %s of %s id %s not supported by consul", k, s.Name, node.Id)
+			}
+			continue
+		}
+		meta[k] = v
+	}
+
+	host, port := node.Address, 0
+	if h, p, err := net.SplitHostPort(node.Address); err == nil {
+		host = h
+		port, _ = strconv.Atoi(p)
+	}
+
+	return &consul.AgentServiceRegistration{
+		ID:      node.Id,
+		Name:    s.Name,
+		Tags:    tags,
+		Meta:    meta,
+		Address: host,
+		Port:    port,
+	}
+}
+
+// decode the consul service instance as a service with a single node, returning its domain.
+// Instances which weren't registered by micro are in the default domain.
+func decode(e *consul.ServiceEntry) (*registry.Service, string) {
+	s := &registry.Service{
+		Name:     e.Service.Service,
+		Metadata: map[string]string{},
+	}
+	domain := registry.DefaultDomain
+	for _, t := range e.Service.Tags {
+		switch {
+		case strings.HasPrefix(t, domainTag):
+			domain = decodeTag(t, domainTag)
+		case strings.HasPrefix(t, versionTag):
+			s.Version = decodeTag(t, versionTag)
+		case strings.HasPrefix(t, metadataTag):
+			json.Unmarshal([]byte(decodeTag(t, metadataTag)), &s.Metadata)
+		case strings.HasPrefix(t, endpointTag):
+			var ep *registry.Endpoint
+			if err := json.Unmarshal([]byte(decodeTag(t, endpointTag)), &ep); err == nil && ep != nil {
+				s.Endpoints = append(s.Endpoints, ep)
+			}
+		}
+	}
+	if s.Metadata == nil {
+		s.Metadata = map[string]string{}
+	}
+	s.Metadata["domain"] = domain
+
+	// the address of the instance defaults to the address of its node
+	host := e.Service.Address
+	if len(host) == 0 && e.Node != nil {
+		host = e.Node.Address
+	}
+	addr := host
+	if e.Service.Port > 0 {
+		addr = net.JoinHostPort(host, strconv.Itoa(e.Service.Port))
+	}
+	node := &registry.Node{
+		Id:       e.Service.ID,
+		Address:  addr,
+		Metadata: map[string]string{},
+	}
+	for k, v := range e.Service.Meta {
+		node.Metadata[k] = v
+	}
+	s.Nodes = []*registry.Node{node}
+	return s, domain
+}
+
+func encodeTag(prefix, value string) string {
+	return prefix + base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func decodeTag(tag, prefix string) string {
+	b, _ := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(tag, prefix))
+	return string(b)
+}
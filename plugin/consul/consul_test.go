@@ -0,0 +1,200 @@
+package consul
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/micro/micro/v3/service/registry"
+)
+
+func testService(name, version, id string) *registry.Service {
+	return &registry.Service{
+		Name:     name,
+		Version:  version,
+		Metadata: map[string]string{"foo": "bar"},
+		Endpoints: []*registry.Endpoint{{
+			Name:     "Foo.Bar",
+			Request:  &registry.Value{Name: "Request", Type: "Request"},
+			Response: &registry.Value{Name: "Response", Type: "Response"},
+			Metadata: map[string]string{"stream": "false"},
+		}},
+		Nodes: []*registry.Node{{
+			Id:       id,
+			Address:  "10.0.0.1:8080",
+			Metadata: map[string]string{"protocol": "grpc", "invalid.key": "x"},
+		}},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	s := testService("foo", "1.0.0", "foo-1")
+	reg := encode(s, s.Nodes[0], "bar")
+	if reg.Address != "10.0.0.1" || reg.Port != 8080 {
+		t.Fatalf("Expected address 10.0.0.1:8080, got %v:%v", reg.Address, reg.Port)
+	}
+	if _, ok := reg.Meta["invalid.key"]; ok {
+		t.Fatal("Expected invalid metadata key to be skipped")
+	}
+
+	rsp, domain := decode(&consul.ServiceEntry{Service: &consul.AgentService{
+		ID:      reg.ID,
+		Service: reg.Name,
+		Tags:    reg.Tags,
+		Meta:    reg.Meta,
+		Address: reg.Address,
+		Port:    reg.Port,
+	}})
+	if domain != "bar" {
+		t.Fatalf("Expected domain bar, got %v", domain)
+	}
+	if rsp.Name != s.Name || rsp.Version != s.Version {
+		t.Fatalf("Expected %v %v, got %v %v", s.Name, s.Version, rsp.Name, rsp.Version)
+	}
+	if rsp.Metadata["foo"] != "bar" || rsp.Metadata["domain"] != "bar" {
+		t.Fatalf("Unexpected metadata %v", rsp.Metadata)
+	}
+	if !reflect.DeepEqual(rsp.Endpoints, s.Endpoints) {
+		t.Fatalf("Expected endpoints %v, got %v", s.Endpoints, rsp.Endpoints)
+	}
+	node := rsp.Nodes[0]
+	if node.Id != "foo-1" || node.Address != "10.0.0.1:8080" || node.Metadata["protocol"] != "grpc" {
+		t.Fatalf("Unexpected node %+v", node)
+	}
+}
+
+func TestDecodeExternal(t *testing.T) {
+	rsp, domain := decode(&consul.ServiceEntry{
+		Node: &consul.Node{Address: "10.0.0.2"},
+		Service: &consul.AgentService{
+			ID:      "postgres-1",
+			Service: "postgres",
+			Tags:    []string{"primary"},
+			Port:    5432,
+		},
+	})
+	if domain != registry.DefaultDomain {
+		t.Fatalf("Expected the default domain, got %v", domain)
+	}
+	if rsp.Name != "postgres" || rsp.Nodes[0].Address != "10.0.0.2:5432" {
+		t.Fatalf("Unexpected service %+v %+v", rsp, rsp.Nodes[0])
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := testService("foo", "1.0.0", "foo-1")
+	b := testService("foo", "1.0.0", "foo-2")
+	c := testService("foo", "1.0.0", "foo-1")
+	c.Nodes[0].Address = "10.0.0.3:8080"
+
+	testCases := []struct {
+		name   string
+		prev   map[string]*registry.Service
+		cur    map[string]*registry.Service
+		expect map[string]string
+	}{
+		{"Create", map[string]*registry.Service{"foo-1": a}, map[string]*registry.Service{"foo-1": a, "foo-2": b}, map[string]string{"foo-2": "create"}},
+		{"Update", map[string]*registry.Service{"foo-1": a}, map[string]*registry.Service{"foo-1": c}, map[string]string{"foo-1": "update"}},
+		{"Delete", map[string]*registry.Service{"foo-1": a, "foo-2": b}, map[string]*registry.Service{"foo-2": b}, map[string]string{"foo-1": "delete"}},
+		{"Unchanged", map[string]*registry.Service{"foo-1": a}, map[string]*registry.Service{"foo-1": a}, map[string]string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actions := map[string]string{}
+			for _, r := range diff(tc.prev, tc.cur) {
+				actions[r.Service.Nodes[0].Id] = r.Action
+			}
+			if !reflect.DeepEqual(actions, tc.expect) {
+				t.Fatalf("Expected %v, got %v", tc.expect, actions)
+			}
+		})
+	}
+}
+
+// TestConsul runs against the consul agent at CONSUL_ADDRESS, e.g. one started with
+// `consul agent -dev`
+func TestConsul(t *testing.T) {
+	addr := os.Getenv("CONSUL_ADDRESS")
+	if len(addr) == 0 {
+		t.Skip("CONSUL_ADDRESS not set")
+	}
+
+	r := NewRegistry(registry.Addrs(addr))
+	name := fmt.Sprintf("test-%d", time.Now().UnixNano())
+
+	w, err := r.Watch(registry.WatchService(name))
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer w.Stop()
+	results := make(chan *registry.Result, 10)
+	go func() {
+		for {
+			res, err := w.Next()
+			if err != nil {
+				close(results)
+				return
+			}
+			results <- res
+		}
+	}()
+
+	s := testService(name, "1.0.0", name+"-1")
+	if err := r.Register(s, registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	// registering an unchanged service passes its TTL check
+	if err := r.Register(s, registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error registering again: %v", err)
+	}
+
+	expectResult(t, results, "create", name+"-1")
+
+	rsp, err := r.GetService(name)
+	if err != nil {
+		t.Fatalf("Error getting service: %v", err)
+	}
+	if len(rsp) != 1 || len(rsp[0].Nodes) != 1 || rsp[0].Version != "1.0.0" {
+		t.Fatalf("Unexpected services %+v", rsp)
+	}
+	if _, err := r.GetService(name, registry.GetDomain("other")); err != registry.ErrNotFound {
+		t.Fatalf("Expected not found in another domain, got %v", err)
+	}
+
+	list, err := r.ListServices()
+	if err != nil {
+		t.Fatalf("Error listing services: %v", err)
+	}
+	var found bool
+	for _, l := range list {
+		if l.Name == name && l.Version == "1.0.0" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected %v in %v", name, list)
+	}
+
+	if err := r.Deregister(s); err != nil {
+		t.Fatalf("Error deregistering: %v", err)
+	}
+	expectResult(t, results, "delete", name+"-1")
+	if _, err := r.GetService(name); err != registry.ErrNotFound {
+		t.Fatalf("Expected not found after deregistering, got %v", err)
+	}
+}
+
+func expectResult(t *testing.T, results chan *registry.Result, action, id string) {
+	select {
+	case res := <-results:
+		if res.Action != action || res.Service.Nodes[0].Id != id {
+			t.Fatalf("Expected %v of %v, got %v of %v", action, id, res.Action, res.Service.Nodes[0].Id)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Timed out waiting for %v of %v", action, id)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/micro/micro/plugin/consul/v3
+
+go 1.15
+
+require (
+	github.com/hashicorp/consul/api v1.9.1
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/mitchellh/hashstructure v1.0.0
+)
+
+replace github.com/micro/micro/v3 => ../..
@@ -0,0 +1,38 @@
+package consul
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+)
+
+type tokenKey struct{}
+type datacenterKey struct{}
+type deregisterAfterKey struct{}
+
+// Token sets the ACL token used to authenticate with consul
+func Token(token string) registry.Option {
+	return setRegistryOption(tokenKey{}, token)
+}
+
+// Datacenter sets the datacenter of the services, by default the datacenter of the agent is used
+func Datacenter(dc string) registry.Option {
+	return setRegistryOption(datacenterKey{}, dc)
+}
+
+// DeregisterCriticalServiceAfter sets how long the TTL check of a service registered with a TTL
+// can be critical before consul deregisters it, the minimum is one minute
+func DeregisterCriticalServiceAfter(d time.Duration) registry.Option {
+	return setRegistryOption(deregisterAfterKey{}, d)
+}
+
+// setRegistryOption returns a function to setup a context with given value
+func setRegistryOption(k, v interface{}) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
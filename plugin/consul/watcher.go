@@ -0,0 +1,200 @@
+package consul
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+)
+
+var (
+	// waitTime is how long a blocking query waits for a change before it's made again
+	waitTime = 5 * time.Minute
+	// retryDelay is the delay before retrying a blocking query which failed
+	retryDelay = time.Second
+)
+
+// consulWatcher watches the services with blocking queries. The health of a service is watched
+// to emit the changes of its nodes, and if no service is watched the catalog is watched to start
+// watching the services registered after the watcher.
+type consulWatcher struct {
+	reg     *consulRegistry
+	options registry.WatchOptions
+
+	next   chan *registry.Result
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sync.Mutex
+	services map[string]bool
+}
+
+func newConsulWatcher(r *consulRegistry, opts ...registry.WatchOption) (registry.Watcher, error) {
+	var options registry.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	// the health of the services is diffed so the watch can't be resumed
+	if len(options.Resume) > 0 {
+		return nil, registry.ErrWatchExpired
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &consulWatcher{
+		reg:      r,
+		options:  options,
+		next:     make(chan *registry.Result),
+		ctx:      ctx,
+		cancel:   cancel,
+		services: make(map[string]bool),
+	}
+
+	if len(options.Service) > 0 {
+		w.watch(options.Service, false)
+	} else {
+		go w.watchCatalog()
+	}
+	return w, nil
+}
+
+// watch the nodes of the service unless it's already watched, if initial is true the nodes of
+// the first query are emitted as created, otherwise they're the baseline changes are emitted from
+func (w *consulWatcher) watch(service string, initial bool) {
+	w.Lock()
+	defer w.Unlock()
+	if w.services[service] {
+		return
+	}
+	w.services[service] = true
+	go w.watchService(service, initial)
+}
+
+func (w *consulWatcher) watchCatalog() {
+	var index uint64
+	first := true
+
+	for {
+		q := (&consul.QueryOptions{WaitIndex: index, WaitTime: waitTime, AllowStale: true}).WithContext(w.ctx)
+		services, meta, err := w.reg.getClient().Catalog().Services(q)
+		if err != nil {
+			if !w.retry(err) {
+				return
+			}
+			continue
+		}
+		index = nextIndex(index, meta.LastIndex)
+
+		// the services removed from the catalog aren't stopped being watched, the deletion of
+		// their nodes is emitted by their watch
+		for name := range services {
+			w.watch(name, !first)
+		}
+		first = false
+	}
+}
+
+func (w *consulWatcher) watchService(service string, initial bool) {
+	var index uint64
+	var prev map[string]*registry.Service
+	first := !initial
+
+	for {
+		q := (&consul.QueryOptions{WaitIndex: index, WaitTime: waitTime, AllowStale: true}).WithContext(w.ctx)
+		entries, meta, err := w.reg.getClient().Health().Service(service, "", true, q)
+		if err != nil {
+			if !w.retry(err) {
+				return
+			}
+			continue
+		}
+		index = nextIndex(index, meta.LastIndex)
+
+		cur := make(map[string]*registry.Service, len(entries))
+		for _, e := range entries {
+			s, domain := decode(e)
+			if w.options.Domain != registry.WildcardDomain && domain != w.options.Domain {
+				continue
+			}
+			cur[s.Nodes[0].Id] = s
+		}
+		if first {
+			prev = cur
+			first = false
+			continue
+		}
+
+		for _, res := range diff(prev, cur) {
+			select {
+			case w.next <- res:
+			case <-w.ctx.Done():
+				return
+			}
+		}
+		prev = cur
+	}
+}
+
+// retry waits before retrying a query which failed, returning false if the watcher was stopped
+func (w *consulWatcher) retry(err error) bool {
+	select {
+	case <-w.ctx.Done():
+		return false
+	default:
+	}
+	logger.Errorf("Error watching consul: %v", err)
+
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-time.After(retryDelay):
+		return true
+	}
+}
+
+func (w *consulWatcher) Next() (*registry.Result, error) {
+	select {
+	case r := <-w.next:
+		return r, nil
+	case <-w.ctx.Done():
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+func (w *consulWatcher) Stop() {
+	w.cancel()
+}
+
+// nextIndex returns the index of the next blocking query, which is reset if the index went
+// backwards, e.g. when the servers were restored from a snapshot
+func nextIndex(prev, last uint64) uint64 {
+	if last < prev {
+		return 0
+	}
+	return last
+}
+
+// diff returns the results of the changes between the nodes of a service, which are keyed by id
+func diff(prev, cur map[string]*registry.Service) []*registry.Result {
+	var results []*registry.Result
+	for id, s := range cur {
+		p, ok := prev[id]
+		if !ok {
+			results = append(results, &registry.Result{Action: "create", Service: s})
+		} else if !reflect.DeepEqual(p, s) {
+			results = append(results, &registry.Result{Action: "update", Service: s})
+		}
+	}
+	for id, s := range prev {
+		if _, ok := cur[id]; !ok {
+			results = append(results, &registry.Result{Action: "delete", Service: s})
+		}
+	}
+	return results
+}
@@ -540,6 +540,14 @@ func (e *etcdRegistry) GetService(name string, opts ...registry.GetOption) ([]*r
 		services = append(services, service)
 	}
 
+	// filter the nodes by their metadata
+	if len(options.Filters) > 0 {
+		services = registry.FilterServices(services, options.Filters)
+		if len(services) == 0 {
+			return nil, registry.ErrNotFound
+		}
+	}
+
 	return services, nil
 }
 
@@ -9,7 +9,7 @@ require (
 	go.uber.org/zap v1.16.0
 )
 
-replace google.golang.org/grpc v1.30.0 => google.golang.org/grpc v1.26.0
+replace google.golang.org/grpc => google.golang.org/grpc v1.26.0
 
 replace github.com/micro/micro/v3 => ../..
 
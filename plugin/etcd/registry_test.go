@@ -0,0 +1,253 @@
+package etcd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/embed"
+)
+
+// startEtcd starts an etcd server in a temporary directory, returning its client address
+func startEtcd(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	cfg.Logger = "zap"
+	cfg.LogLevel = "error"
+	clientURL, _ := url.Parse("http://127.0.0.1:0")
+	peerURL, _ := url.Parse("http://127.0.0.1:0")
+	cfg.LCUrls, cfg.ACUrls = []url.URL{*clientURL}, []url.URL{*clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{*peerURL}, []url.URL{*peerURL}
+	cfg.InitialCluster = cfg.Name + "=" + peerURL.String()
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatalf("Error starting etcd: %v", err)
+	}
+	t.Cleanup(e.Close)
+
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out starting etcd")
+	}
+	return e.Clients[0].Addr().String()
+}
+
+func testService(name, id string) *registry.Service {
+	return &registry.Service{
+		Name:    name,
+		Version: "1.0.0",
+		Nodes: []*registry.Node{{
+			Id:      id,
+			Address: "10.0.0.1:8080",
+		}},
+	}
+}
+
+func expectResult(t *testing.T, w registry.Watcher, action, id string) *registry.Result {
+	t.Helper()
+	results := make(chan *registry.Result, 1)
+	errs := make(chan error, 1)
+	go func() {
+		res, err := w.Next()
+		if err != nil {
+			errs <- err
+			return
+		}
+		results <- res
+	}()
+
+	select {
+	case res := <-results:
+		if res.Action != action || res.Service.Nodes[0].Id != id {
+			t.Fatalf("Expected %v of %v, got %v of %v", action, id, res.Action, res.Service.Nodes[0].Id)
+		}
+		return res
+	case err := <-errs:
+		t.Fatalf("Error watching: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Timed out waiting for %v of %v", action, id)
+	}
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(registry.Addrs(startEtcd(t)))
+
+	w, err := r.Watch(registry.WatchService("foo"))
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer w.Stop()
+
+	foo1 := testService("foo", "foo-1")
+	if err := r.Register(foo1, registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	expectResult(t, w, "create", "foo-1")
+
+	// registering an unchanged node renews its lease without updating it
+	if err := r.Register(foo1, registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error registering again: %v", err)
+	}
+	foo1.Nodes[0].Address = "10.0.0.2:8080"
+	if err := r.Register(foo1, registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error updating: %v", err)
+	}
+	expectResult(t, w, "update", "foo-1")
+
+	if err := r.Register(testService("foo", "foo-2"), registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	expectResult(t, w, "create", "foo-2")
+	if err := r.Register(testService("bar", "bar-1"), registry.RegisterDomain("other")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+
+	srvs, err := r.GetService("foo")
+	if err != nil {
+		t.Fatalf("Error getting service: %v", err)
+	}
+	if len(srvs) != 1 || len(srvs[0].Nodes) != 2 {
+		t.Fatalf("Expected one version with two nodes, got %+v", srvs)
+	}
+	if _, err := r.GetService("bar"); err != registry.ErrNotFound {
+		t.Fatalf("Expected bar not to be found in the default domain, got %v", err)
+	}
+	if srvs, err := r.GetService("bar", registry.GetDomain(registry.WildcardDomain)); err != nil || len(srvs) != 1 {
+		t.Fatalf("Expected bar to be found in any domain, got %+v %v", srvs, err)
+	}
+
+	list, err := r.ListServices()
+	if err != nil || len(list) != 1 || list[0].Name != "foo" {
+		t.Fatalf("Expected foo to be listed, got %+v %v", list, err)
+	}
+
+	if err := r.Deregister(testService("foo", "foo-2")); err != nil {
+		t.Fatalf("Error deregistering: %v", err)
+	}
+	expectResult(t, w, "delete", "foo-2")
+}
+
+func TestRegistryLeaseExpiry(t *testing.T) {
+	r := NewRegistry(registry.Addrs(startEtcd(t)))
+
+	w, err := r.Watch()
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer w.Stop()
+
+	if err := r.Register(testService("foo", "foo-1"), registry.RegisterTTL(time.Second)); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	expectResult(t, w, "create", "foo-1")
+
+	// the node is deleted once its lease expires without being renewed
+	expectResult(t, w, "delete", "foo-1")
+	if _, err := r.GetService("foo"); err != registry.ErrNotFound {
+		t.Fatalf("Expected foo not to be found once its lease expired, got %v", err)
+	}
+
+	// registering the node again once its lease expired creates it again
+	if err := r.Register(testService("foo", "foo-1"), registry.RegisterTTL(time.Minute)); err != nil {
+		t.Fatalf("Error registering again: %v", err)
+	}
+	expectResult(t, w, "create", "foo-1")
+}
+
+func TestRegistryResume(t *testing.T) {
+	addr := startEtcd(t)
+	r := NewRegistry(registry.Addrs(addr))
+
+	w, err := r.Watch(registry.WatchService("foo"))
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	if err := r.Register(testService("foo", "foo-1")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	res := expectResult(t, w, "create", "foo-1")
+	w.Stop()
+
+	// the events after the token are returned when resuming
+	if err := r.Register(testService("foo", "foo-2")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	if err := r.Register(testService("bar", "bar-1")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	if err := r.Deregister(testService("foo", "foo-1")); err != nil {
+		t.Fatalf("Error deregistering: %v", err)
+	}
+	w, err = r.Watch(registry.WatchService("foo"), registry.WatchResume(res.Token))
+	if err != nil {
+		t.Fatalf("Error resuming: %v", err)
+	}
+	expectResult(t, w, "create", "foo-2")
+	last := expectResult(t, w, "delete", "foo-1")
+	w.Stop()
+
+	// the watch expires once the revisions after the token are compacted
+	c, err := clientv3.New(clientv3.Config{Endpoints: []string{addr}})
+	if err != nil {
+		t.Fatalf("Error connecting: %v", err)
+	}
+	defer c.Close()
+	rev, _ := strconv.ParseInt(last.Token, 10, 64)
+	if _, err := c.Compact(context.Background(), rev); err != nil {
+		t.Fatalf("Error compacting: %v", err)
+	}
+	w, err = r.Watch(registry.WatchResume(res.Token))
+	if err != nil {
+		t.Fatalf("Error resuming: %v", err)
+	}
+	defer w.Stop()
+	if _, err := w.Next(); err != registry.ErrWatchExpired {
+		t.Fatalf("Expected the watch to expire, got %v", err)
+	}
+}
+
+func TestRegistryWatchFilters(t *testing.T) {
+	r := NewRegistry(registry.Addrs(startEtcd(t)))
+
+	// a service watched across domains
+	all, err := r.Watch(registry.WatchService("foo"), registry.WatchDomain(registry.WildcardDomain))
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer all.Stop()
+	// the services of a domain
+	other, err := r.Watch(registry.WatchDomain("other"))
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer other.Stop()
+
+	if err := r.Register(testService("bar", "bar-1")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	if err := r.Register(testService("foo", "foo-1")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	if err := r.Register(testService("foo", "foo-2"), registry.RegisterDomain("other")); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+
+	expectResult(t, all, "create", "foo-1")
+	expectResult(t, all, "create", "foo-2")
+	expectResult(t, other, "create", "foo-2")
+}
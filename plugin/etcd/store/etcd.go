@@ -0,0 +1,640 @@
+// Package store implements an etcd backed store
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"math"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var (
+	// DefaultDatabase is the namespace that the etcd store
+	// will use if no namespace is provided.
+	DefaultDatabase = "micro"
+	// DefaultTable when none is specified
+	DefaultTable = "micro"
+	// DefaultTimeout for requests to etcd
+	DefaultTimeout = 5 * time.Second
+
+	// all keys are stored under this prefix
+	prefix = "/micro/store/"
+
+	// the default limit on the number of operations in an etcd transaction
+	maxTxnOps = 128
+)
+
+type etcdStore struct {
+	options store.Options
+	client  *clientv3.Client
+}
+
+// record stored by us
+type record struct {
+	Key      string                 `json:"key"`
+	Value    []byte                 `json:"value"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// NewStore returns a new micro Store backed by etcd
+func NewStore(opts ...store.Option) store.Store {
+	s := &etcdStore{
+		options: store.Options{
+			Database: DefaultDatabase,
+			Table:    DefaultTable,
+		},
+	}
+
+	// best-effort configure the store
+	if err := s.Init(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return s
+}
+
+func (e *etcdStore) configure() error {
+	config := clientv3.Config{
+		Endpoints:   []string{"127.0.0.1:2379"},
+		DialTimeout: DefaultTimeout,
+	}
+
+	if e.options.Context != nil {
+		if u, ok := e.options.Context.Value(authKey{}).(*authCreds); ok {
+			config.Username = u.Username
+			config.Password = u.Password
+		}
+		if c, ok := e.options.Context.Value(tlsConfigKey{}).(*tls.Config); ok {
+			config.TLS = c
+		}
+	}
+
+	var addrs []string
+
+	for _, address := range e.options.Nodes {
+		if len(address) == 0 {
+			continue
+		}
+		addr, port, err := net.SplitHostPort(address)
+		if ae, ok := err.(*net.AddrError); ok && ae.Err == "missing port in address" {
+			addrs = append(addrs, net.JoinHostPort(address, "2379"))
+		} else if err == nil {
+			addrs = append(addrs, net.JoinHostPort(addr, port))
+		}
+	}
+
+	// if we got addrs then we'll update
+	if len(addrs) > 0 {
+		config.Endpoints = addrs
+	}
+
+	// check if the endpoints have https://
+	if config.TLS != nil {
+		for i, ep := range config.Endpoints {
+			if !strings.HasPrefix(ep, "https://") {
+				config.Endpoints[i] = "https://" + ep
+			}
+		}
+	}
+
+	cli, err := clientv3.New(config)
+	if err != nil {
+		return err
+	}
+
+	if e.client != nil {
+		e.client.Close()
+	}
+	e.client = cli
+
+	return nil
+}
+
+// keyPrefix returns the etcd key prefix for the given database and table
+func (e *etcdStore) keyPrefix(database, table string) string {
+	if len(database) == 0 {
+		database = e.options.Database
+	}
+	if len(table) == 0 {
+		table = e.options.Table
+	}
+	return path.Join(prefix, database, table) + "/"
+}
+
+// get fetches the records with the given key, or all the keys with that prefix
+func (e *etcdStore) get(kp, key string, order store.Order, limit, offset uint, suffix string, opts ...clientv3.OpOption) ([]*store.Record, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	rsp, err := e.client.Get(ctx, kp+key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*store.Record
+
+	for _, kv := range rsp.Kvs {
+		k := strings.TrimPrefix(string(kv.Key), kp)
+		if suffix != "" && !strings.HasSuffix(k, suffix) {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(kv.Value, &rec); err != nil {
+			return nil, err
+		}
+
+		newRecord := &store.Record{
+			Key:      rec.Key,
+			Value:    rec.Value,
+			Metadata: make(map[string]interface{}),
+			// the revision the key was last modified at
+			Version: uint64(kv.ModRevision),
+		}
+		for k, v := range rec.Metadata {
+			newRecord.Metadata[k] = v
+		}
+
+		// expiry is managed with a lease so lookup how long it has left
+		if kv.Lease != 0 {
+			ttl, err := e.client.TimeToLive(ctx, clientv3.LeaseID(kv.Lease))
+			if err != nil {
+				return nil, err
+			}
+			if ttl.TTL <= 0 {
+				continue
+			}
+			newRecord.Expiry = time.Duration(ttl.TTL) * time.Second
+		}
+
+		records = append(records, newRecord)
+	}
+
+	if order == store.OrderDesc {
+		for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+			records[i], records[j] = records[j], records[i]
+		}
+	}
+
+	if offset > 0 {
+		// offset is greater than the records we have
+		if int(offset) >= len(records) {
+			return nil, nil
+		}
+
+		records = records[offset:]
+	}
+
+	if limit > 0 && int(limit) < len(records) {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
+
+// rangeOpts returns the options used to fetch a sorted range of keys
+func rangeOpts(order store.Order, limit, offset uint, suffix string) []clientv3.OpOption {
+	opts := []clientv3.OpOption{
+		clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+	}
+	// we can only push the limit down to etcd if we don't filter client side
+	if order != store.OrderDesc && limit > 0 && suffix == "" {
+		opts = append(opts, clientv3.WithLimit(int64(offset+limit)))
+	}
+	return opts
+}
+
+func (e *etcdStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&e.options)
+	}
+	return e.configure()
+}
+
+func (e *etcdStore) Options() store.Options {
+	return e.options
+}
+
+func (e *etcdStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	readOpts := store.ReadOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&readOpts)
+	}
+
+	// earlier revisions of records aren't kept
+	if readOpts.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys aren't paged through with a cursor
+	if readOpts.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys matched against a pattern are read like a prefix
+	if readOpts.Match != nil && !readOpts.Suffix {
+		readOpts.Prefix = true
+	}
+
+	if len(readOpts.Where) > 0 || readOpts.OrderBy == store.OrderByExpiry || readOpts.Match != nil {
+		return e.where(key, readOpts)
+	}
+
+	kp := e.keyPrefix(readOpts.Database, readOpts.Table)
+
+	if !readOpts.Prefix && !readOpts.Suffix {
+		recs, err := e.get(kp, key, store.OrderAsc, 0, 0, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(recs) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return recs, nil
+	}
+
+	pfx := ""
+	if readOpts.Prefix {
+		pfx = key
+	}
+	sfx := ""
+	if readOpts.Suffix {
+		sfx = key
+	}
+
+	recs, err := e.get(kp, pfx, readOpts.Order, readOpts.Limit, readOpts.Offset, sfx,
+		rangeOpts(readOpts.Order, readOpts.Limit, readOpts.Offset, sfx)...)
+	if err != nil {
+		return nil, err
+	}
+	if recs == nil {
+		recs = []*store.Record{}
+	}
+	return recs, nil
+}
+
+// where reads the records without the metadata values and then filters them, as
+// etcd can't query by metadata. The limit and offset are applied once filtered.
+func (e *etcdStore) where(key string, options store.ReadOptions) ([]*store.Record, error) {
+	opts := []store.ReadOption{
+		store.ReadFrom(options.Database, options.Table),
+		store.ReadOrder(options.Order),
+	}
+	if options.Prefix {
+		opts = append(opts, store.ReadPrefix())
+	}
+	if options.Suffix {
+		opts = append(opts, store.ReadSuffix())
+	}
+
+	var match *regexp.Regexp
+	if options.Match != nil {
+		var err error
+		if match, err = options.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	recs, err := e.Read(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*store.Record{}
+	for _, r := range recs {
+		if r.Match(options.Where) && (match == nil || match.MatchString(r.Key)) {
+			results = append(results, r)
+		}
+	}
+	if options.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, options.Order)
+	}
+
+	if !options.Prefix && !options.Suffix {
+		if len(results) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return results, nil
+	}
+
+	if options.Offset > 0 {
+		// offset is greater than the records we have
+		if int(options.Offset) >= len(results) {
+			return []*store.Record{}, nil
+		}
+		results = results[options.Offset:]
+	}
+
+	if options.Limit > 0 && int(options.Limit) < len(results) {
+		results = results[:options.Limit]
+	}
+
+	return results, nil
+}
+
+func (e *etcdStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if e.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	b, err := json.Marshal(&record{
+		Key:      r.Key,
+		Value:    r.Value,
+		Metadata: r.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	var putOpts []clientv3.OpOption
+
+	// etcd expires keys by attaching them to a lease
+	if r.Expiry > 0 {
+		ttl := int64(math.Ceil(r.Expiry.Seconds()))
+		lease, err := e.client.Grant(ctx, ttl)
+		if err != nil {
+			return errors.Wrap(err, "Couldn't create lease for record "+r.Key)
+		}
+		putOpts = append(putOpts, clientv3.WithLease(lease.ID))
+	}
+
+	kp := e.keyPrefix(writeOpts.Database, writeOpts.Table)
+
+	// the conditions of the write are compared against the revisions of the key
+	var cmps []clientv3.Cmp
+	if writeOpts.IfNotExists {
+		cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(kp+r.Key), "=", 0))
+	}
+	if writeOpts.IfVersion > 0 {
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(kp+r.Key), "=", int64(writeOpts.IfVersion)))
+	}
+
+	rsp, err := e.client.Txn(ctx).If(cmps...).Then(clientv3.OpPut(kp+r.Key, string(b), putOpts...)).Commit()
+	if err != nil {
+		return errors.Wrap(err, "Couldn't write record "+r.Key)
+	}
+	if !rsp.Succeeded {
+		return store.ErrConflict
+	}
+
+	r.Version = uint64(rsp.Header.Revision)
+	return nil
+}
+
+// Touch attaches the key to a new lease, or to none if it shouldn't expire. The value
+// is kept by the put so only the lease is changed.
+func (e *etcdStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if e.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	putOpts := []clientv3.OpOption{clientv3.WithIgnoreValue()}
+	var lease clientv3.LeaseID
+	if expiry > 0 {
+		ttl := int64(math.Ceil(expiry.Seconds()))
+		rsp, err := e.client.Grant(ctx, ttl)
+		if err != nil {
+			return errors.Wrap(err, "Couldn't create lease for record "+key)
+		}
+		lease = rsp.ID
+		putOpts = append(putOpts, clientv3.WithLease(lease))
+	}
+
+	k := e.keyPrefix(options.Database, options.Table) + key
+	rsp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(k), ">", 0)).
+		Then(clientv3.OpPut(k, "", putOpts...)).
+		Commit()
+	if err != nil {
+		return errors.Wrap(err, "Couldn't touch record "+key)
+	}
+	if !rsp.Succeeded {
+		if lease != 0 {
+			e.client.Revoke(ctx, lease)
+		}
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (e *etcdStore) Delete(key string, opts ...store.DeleteOption) error {
+	if e.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOpts store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOpts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	kp := e.keyPrefix(deleteOpts.Database, deleteOpts.Table)
+
+	if deleteOpts.Suffix {
+		// etcd can't match suffixes so the keys have to be listed first
+		pfx := ""
+		if deleteOpts.Prefix {
+			pfx = key
+		}
+		rsp, err := e.client.Get(ctx, kp+pfx, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+		if err != nil {
+			return err
+		}
+		var keys []string
+		for _, kv := range rsp.Kvs {
+			if k := strings.TrimPrefix(string(kv.Key), kp); strings.HasSuffix(k, key) {
+				keys = append(keys, k)
+			}
+		}
+		return e.deleteMany(ctx, kp, keys)
+	}
+
+	var delOpts []clientv3.OpOption
+	if deleteOpts.Prefix {
+		delOpts = append(delOpts, clientv3.WithPrefix())
+	}
+
+	_, err := e.client.Delete(ctx, kp+key, delOpts...)
+	return err
+}
+
+// DeleteMany deletes the records in a single transaction
+func (e *etcdStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if e.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOpts store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOpts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	return e.deleteMany(ctx, e.keyPrefix(deleteOpts.Database, deleteOpts.Table), keys)
+}
+
+func (e *etcdStore) deleteMany(ctx context.Context, kp string, keys []string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxTxnOps {
+			n = maxTxnOps
+		}
+
+		ops := make([]clientv3.Op, n)
+		for i, k := range keys[:n] {
+			ops[i] = clientv3.OpDelete(kp + k)
+		}
+		if _, err := e.client.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return errors.Wrap(err, "Couldn't delete records")
+		}
+
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func (e *etcdStore) List(opts ...store.ListOption) ([]string, error) {
+	listOpts := store.ListOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&listOpts)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(listOpts.Delimiter) > 0 {
+		return store.ListDelimited(e, listOpts)
+	}
+	if listOpts.Match != nil {
+		return store.ListMatched(e, listOpts)
+	}
+
+	if listOpts.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	if listOpts.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := e.where(listOpts.Prefix, store.ReadOptions{
+			Database: listOpts.Database,
+			Table:    listOpts.Table,
+			Prefix:   true,
+			Order:    listOpts.Order,
+			OrderBy:  listOpts.OrderBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys := []string{}
+		for _, r := range recs {
+			if strings.HasSuffix(r.Key, listOpts.Suffix) {
+				keys = append(keys, r.Key)
+			}
+		}
+		if listOpts.Offset > 0 {
+			// offset is greater than the keys we have
+			if int(listOpts.Offset) >= len(keys) {
+				return []string{}, nil
+			}
+			keys = keys[listOpts.Offset:]
+		}
+		if listOpts.Limit > 0 && int(listOpts.Limit) < len(keys) {
+			keys = keys[:listOpts.Limit]
+		}
+		return keys, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	kp := e.keyPrefix(listOpts.Database, listOpts.Table)
+	getOpts := append(rangeOpts(listOpts.Order, listOpts.Limit, listOpts.Offset, listOpts.Suffix), clientv3.WithKeysOnly())
+
+	rsp, err := e.client.Get(ctx, kp+listOpts.Prefix, getOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, kv := range rsp.Kvs {
+		k := strings.TrimPrefix(string(kv.Key), kp)
+		if listOpts.Suffix != "" && !strings.HasSuffix(k, listOpts.Suffix) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+
+	if listOpts.Order == store.OrderDesc {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	if listOpts.Offset > 0 {
+		// offset is greater than the keys we have
+		if int(listOpts.Offset) >= len(keys) {
+			return nil, nil
+		}
+
+		keys = keys[listOpts.Offset:]
+	}
+
+	if listOpts.Limit > 0 && int(listOpts.Limit) < len(keys) {
+		keys = keys[:listOpts.Limit]
+	}
+
+	return keys, nil
+}
+
+// Healthy reads a key from the etcd cluster, which fails if it doesn't have a leader
+func (e *etcdStore) Healthy(ctx context.Context) error {
+	_, err := e.client.Get(ctx, "health")
+	return err
+}
+
+func (e *etcdStore) Close() error {
+	if e.client != nil {
+		return e.client.Close()
+	}
+	return nil
+}
+
+func (e *etcdStore) String() string {
+	return "etcd"
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/storetest"
+)
+
+const addr = "127.0.0.1:2379"
+
+func TestConformance(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		t.Skipf("Etcd isn't reachable at %s: %v", addr, err)
+	}
+	conn.Close()
+
+	storetest.Conformance(t, func() store.Store {
+		return NewStore(store.Nodes(addr))
+	})
+}
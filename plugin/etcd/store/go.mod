@@ -0,0 +1,12 @@
+module github.com/micro/micro/plugin/etcd/store/v3
+
+go 1.15
+
+require (
+	github.com/micro/micro/v3 v3.2.2-0.20210607125937-d152c4ec7749
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.7.0
+	go.etcd.io/etcd/client/v3 v3.5.0
+)
+
+replace github.com/micro/micro/v3 => ../../..
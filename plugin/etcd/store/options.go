@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type authKey struct{}
+
+type tlsConfigKey struct{}
+
+type authCreds struct {
+	Username string
+	Password string
+}
+
+// Auth allows you to specify username/password
+func Auth(username, password string) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, authKey{}, &authCreds{Username: username, Password: password})
+	}
+}
+
+// TLSConfig sets the TLS config used to connect to etcd
+func TLSConfig(c *tls.Config) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, tlsConfigKey{}, c)
+	}
+}
@@ -19,6 +19,7 @@ package etcd
 import (
 	"context"
 	"errors"
+	"strconv"
 	"sync"
 	"time"
 
@@ -30,10 +31,14 @@ type etcdWatcher struct {
 	w       clientv3.WatchChan
 	client  *clientv3.Client
 	timeout time.Duration
+	service string
 
 	mtx    sync.Mutex
 	stop   chan bool
 	cancel func()
+
+	// results of the last response which haven't been returned
+	results []*registry.Result
 }
 
 func newEtcdWatcher(c *clientv3.Client, timeout time.Duration, opts ...registry.WatchOption) (registry.Watcher, error) {
@@ -45,21 +50,30 @@ func newEtcdWatcher(c *clientv3.Client, timeout time.Duration, opts ...registry.
 		wo.Domain = defaultDomain
 	}
 
+	// a service watched across domains is filtered from the events of all the services
 	watchPath := prefix
-	if wo.Domain == registry.WildcardDomain {
-		if len(wo.Service) > 0 {
-			return nil, errors.New("Cannot watch a service across domains")
-		}
-		watchPath = prefix
-	} else if len(wo.Service) > 0 {
+	if wo.Domain != registry.WildcardDomain && len(wo.Service) > 0 {
 		watchPath = servicePath(wo.Domain, wo.Service) + "/"
+	} else if wo.Domain != registry.WildcardDomain {
+		watchPath = prefixWithDomain(wo.Domain) + "/"
+	}
+
+	// resume the watch after the revision of the token
+	watchOpts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithPrevKV()}
+	if len(wo.Resume) > 0 {
+		rev, err := strconv.ParseInt(wo.Resume, 10, 64)
+		if err != nil {
+			return nil, registry.ErrWatchExpired
+		}
+		watchOpts = append(watchOpts, clientv3.WithRev(rev+1))
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	w := c.Watch(ctx, watchPath, clientv3.WithPrefix(), clientv3.WithPrevKV())
+	w := c.Watch(ctx, watchPath, watchOpts...)
 	stop := make(chan bool, 1)
 
 	return &etcdWatcher{
+		service: wo.Service,
 		cancel:  cancel,
 		stop:    stop,
 		w:       w,
@@ -69,7 +83,15 @@ func newEtcdWatcher(c *clientv3.Client, timeout time.Duration, opts ...registry.
 }
 
 func (ew *etcdWatcher) Next() (*registry.Result, error) {
-	for wresp := range ew.w {
+	for len(ew.results) == 0 {
+		wresp, ok := <-ew.w
+		if !ok {
+			return nil, errors.New("could not get next")
+		}
+		// the revision resumed from has been compacted
+		if wresp.CompactRevision != 0 {
+			return nil, registry.ErrWatchExpired
+		}
 		if wresp.Err() != nil {
 			return nil, wresp.Err()
 		}
@@ -77,6 +99,10 @@ func (ew *etcdWatcher) Next() (*registry.Result, error) {
 			return nil, errors.New("could not get next")
 		}
 		for _, ev := range wresp.Events {
+			if _, name, ok := getName(string(ev.Kv.Key), prefix); len(ew.service) > 0 && (!ok || name != serializeServiceName(ew.service)) {
+				continue
+			}
+
 			service := decode(ev.Kv.Value)
 			var action string
 
@@ -97,13 +123,17 @@ func (ew *etcdWatcher) Next() (*registry.Result, error) {
 			if service == nil {
 				continue
 			}
-			return &registry.Result{
+			ew.results = append(ew.results, &registry.Result{
 				Action:  action,
 				Service: service,
-			}, nil
+				Token:   strconv.FormatInt(ev.Kv.ModRevision, 10),
+			})
 		}
 	}
-	return nil, errors.New("could not get next")
+
+	r := ew.results[0]
+	ew.results = ew.results[1:]
+	return r, nil
 }
 
 func (ew *etcdWatcher) Stop() {
@@ -0,0 +1,284 @@
+// Package gcs implements the micro blob store with Google Cloud Storage
+package gcs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+var (
+	doubleSlash = regexp.MustCompile("/+")
+	removeCol   = regexp.MustCompile(":")
+)
+
+func cleanKey(s string) string {
+	return doubleSlash.ReplaceAllLiteralString(removeCol.ReplaceAllLiteralString(s, "/"), "/")
+}
+
+// NewBlobStore returns an initialized gcs blob store. Blobs are written to the bucket set with
+// the Bucket option, under a directory for their namespace, or if it isn't set to a bucket
+// for each namespace.
+func NewBlobStore(opts ...Option) (store.BlobStore, error) {
+	// parse the options
+	options := Options{Project: os.Getenv("GOOGLE_CLOUD_PROJECT")}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var copts []option.ClientOption
+	if len(options.CredentialsFile) > 0 {
+		copts = append(copts, option.WithCredentialsFile(options.CredentialsFile))
+	}
+	if len(options.Endpoint) > 0 {
+		copts = append(copts, option.WithEndpoint(options.Endpoint), option.WithoutAuthentication())
+	}
+
+	client, err := storage.NewClient(context.Background(), copts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// return the blob store
+	return &gcs{client, &options}, nil
+}
+
+type gcs struct {
+	client  *storage.Client
+	options *Options
+}
+
+// object returns the bucket and name of the object the key of the namespace is written to
+func (g *gcs) object(namespace, key string) (string, string) {
+	if len(g.options.Bucket) > 0 {
+		return g.options.Bucket, path.Join(namespace, key)
+	}
+	return namespace, key
+}
+
+func isNotFound(err error) bool {
+	return err == storage.ErrObjectNotExist || err == storage.ErrBucketNotExist
+}
+
+func (g *gcs) Read(key string, opts ...store.BlobOption) (io.Reader, error) {
+	// validate the key
+	if len(key) == 0 {
+		return nil, store.ErrMissingKey
+	}
+
+	// make the key safe for use as an object name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	bucket, name := g.object(options.Namespace, key)
+	r, err := g.client.Bucket(bucket).Object(name).NewReader(context.TODO())
+	if isNotFound(err) {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// the blob is streamed from the response, which is closed by the caller
+	return r, nil
+}
+
+func (g *gcs) Write(key string, blob io.Reader, opts ...store.BlobOption) error {
+	// validate the key
+	if len(key) == 0 {
+		return store.ErrMissingKey
+	}
+
+	// make the key safe for use as an object name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	bucket, name := g.object(options.Namespace, key)
+	logger.Infof("Saving file %v into bucket %v", name, bucket)
+
+	// without a bucket each namespace has its own, which may not exist yet
+	if len(g.options.Bucket) == 0 {
+		err := g.client.Bucket(bucket).Create(context.TODO(), g.options.Project, nil)
+		if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusConflict {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	w := g.client.Bucket(bucket).Object(name).NewWriter(context.TODO())
+	w.ContentType = options.ContentType
+	w.Metadata = options.Metadata
+	if g.options.PartSize > 0 {
+		w.ChunkSize = g.options.PartSize
+	}
+	if options.Public {
+		w.PredefinedACL = "publicRead"
+	}
+	if _, err := io.Copy(w, blob); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (g *gcs) Delete(key string, opts ...store.BlobOption) error {
+	// validate the key
+	if len(key) == 0 {
+		return store.ErrMissingKey
+	}
+
+	// make the key safe for use as an object name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	bucket, name := g.object(options.Namespace, key)
+	err := g.client.Bucket(bucket).Object(name).Delete(context.TODO())
+	// deleting a missing object is a no-op
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (g *gcs) List(opts ...store.BlobListOption) ([]string, error) {
+	objs, keyPrefix, err := g.list(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(objs))
+	for i, attrs := range objs {
+		// return the key without the prefix
+		keys[i] = strings.TrimPrefix(attrs.Name, keyPrefix)
+	}
+	// return the result
+	return keys, nil
+}
+
+func (g *gcs) ListInfo(opts ...store.BlobListOption) ([]*store.BlobInfo, error) {
+	objs, keyPrefix, err := g.list(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make([]*store.BlobInfo, len(objs))
+	for i, attrs := range objs {
+		blobs[i] = &store.BlobInfo{
+			Key:         strings.TrimPrefix(attrs.Name, keyPrefix),
+			Size:        attrs.Size,
+			ContentType: attrs.ContentType,
+			Metadata:    attrs.Metadata,
+			Modified:    attrs.Updated,
+		}
+	}
+	return blobs, nil
+}
+
+// list returns the objects of the namespace which match, and the prefix of their names which isn't
+// part of the key of the blob
+func (g *gcs) list(opts ...store.BlobListOption) ([]*storage.ObjectAttrs, string, error) {
+	// parse the options
+	var options store.BlobListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	// with a bucket the keys of the namespace are in its directory
+	bucket, prefix := options.Namespace, options.Prefix
+	keyPrefix := ""
+	if len(g.options.Bucket) > 0 {
+		keyPrefix = options.Namespace + "/"
+		bucket, prefix = g.options.Bucket, keyPrefix+options.Prefix
+	}
+
+	objs := []*storage.ObjectAttrs{}
+	it := g.client.Bucket(bucket).Objects(context.TODO(), &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		} else if isNotFound(err) {
+			return objs, keyPrefix, nil
+		} else if err != nil {
+			return nil, "", err
+		}
+		objs = append(objs, attrs)
+	}
+	return objs, keyPrefix, nil
+}
+
+func (g *gcs) PresignGet(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return g.presign(http.MethodGet, key, ttl, opts...)
+}
+
+func (g *gcs) PresignPut(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return g.presign(http.MethodPut, key, ttl, opts...)
+}
+
+// presign returns a V4 signed url of the object for the method. The url is signed with the service
+// account of the credentials, or on GCE the default service account using the IAM credentials API.
+func (g *gcs) presign(method, key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	// validate the key
+	if len(key) == 0 {
+		return "", store.ErrMissingKey
+	}
+
+	// make the key safe for use as an object name
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	bucket, name := g.object(options.Namespace, key)
+	return g.client.Bucket(bucket).SignedURL(name, &storage.SignedURLOptions{
+		Method:  method,
+		Expires: time.Now().Add(ttl),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
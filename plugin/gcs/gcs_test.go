@@ -0,0 +1,88 @@
+package gcs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObject(t *testing.T) {
+	g := &gcs{options: &Options{}}
+	b, n := g.object("foo", cleanKey("build://name:version"))
+	assert.Equal(t, "foo", b)
+	assert.Equal(t, "build/name/version", n)
+
+	g = &gcs{options: &Options{Bucket: "blobs"}}
+	b, n = g.object("foo", "build/name/version")
+	assert.Equal(t, "blobs", b)
+	assert.Equal(t, "foo/build/name/version", n)
+}
+
+func TestPresign(t *testing.T) {
+	blob, err := NewBlobStore(Endpoint("http://localhost:4443/storage/v1/"))
+	assert.NoError(t, err)
+	p, ok := blob.(store.Presigner)
+	assert.True(t, ok, "Expected the blob store to presign urls")
+
+	_, err = p.PresignGet("", time.Minute)
+	assert.Equal(t, store.ErrMissingKey, err)
+	_, err = p.PresignPut("", time.Minute)
+	assert.Equal(t, store.ErrMissingKey, err)
+}
+
+func TestBlobStore(t *testing.T) {
+	bucket := os.Getenv("GCS_BLOB_STORE_BUCKET")
+	if len(bucket) == 0 {
+		t.Skipf("Missing required config GCS_BLOB_STORE_BUCKET")
+	}
+
+	opts := []Option{Bucket(bucket)}
+	if e := os.Getenv("GCS_BLOB_STORE_ENDPOINT"); len(e) > 0 {
+		opts = append(opts, Endpoint(e))
+	}
+	blob, err := NewBlobStore(opts...)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	_, err = blob.Read("")
+	assert.Equal(t, store.ErrMissingKey, err)
+
+	_, err = blob.Read("foo")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	assert.NoError(t, blob.Write("hello", bytes.NewBufferString("world")))
+
+	val, err := blob.Read("hello")
+	assert.NoError(t, err)
+	if val != nil {
+		b, _ := ioutil.ReadAll(val)
+		assert.Equal(t, "world", string(b))
+	}
+
+	_, err = blob.Read("hello", store.BlobNamespace("bar"))
+	assert.Equal(t, store.ErrNotFound, err)
+
+	keys, err := blob.List()
+	assert.NoError(t, err)
+	assert.Contains(t, keys, "hello")
+
+	blobs, err := blob.(store.BlobInfoLister).ListInfo(store.BlobListPrefix("hello"))
+	assert.NoError(t, err)
+	if assert.Len(t, blobs, 1) {
+		assert.Equal(t, int64(len("world")), blobs[0].Size)
+		assert.False(t, blobs[0].Modified.IsZero())
+	}
+
+	assert.NoError(t, blob.Delete("hello", store.BlobNamespace("bar")))
+	assert.NoError(t, blob.Delete("hello"))
+
+	_, err = blob.Read("hello")
+	assert.Equal(t, store.ErrNotFound, err)
+}
@@ -0,0 +1,12 @@
+module github.com/micro/micro/plugin/gcs/v3
+
+go 1.15
+
+require (
+	cloud.google.com/go/storage v1.18.2
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/api v0.58.0
+)
+
+replace github.com/micro/micro/v3 => ../..
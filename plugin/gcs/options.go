@@ -0,0 +1,55 @@
+package gcs
+
+// Options used to configure the gcs blob store
+type Options struct {
+	Bucket          string
+	Project         string
+	CredentialsFile string
+	Endpoint        string
+	PartSize        int
+}
+
+// Option configures one or more options
+type Option func(o *Options)
+
+// Bucket sets the bucket all the blobs are written to. If it isn't set each namespace has its
+// own bucket, which are created in the project.
+func Bucket(name string) Option {
+	return func(o *Options) {
+		o.Bucket = name
+	}
+}
+
+// Project sets the project buckets are created in. If it isn't set it's read from the
+// GOOGLE_CLOUD_PROJECT env var.
+func Project(id string) Option {
+	return func(o *Options) {
+		o.Project = id
+	}
+}
+
+// CredentialsFile sets the path of the service account key to use. If it isn't set the
+// application default credentials are used, e.g. the file GOOGLE_APPLICATION_CREDENTIALS
+// points to or the service account of the instance.
+func CredentialsFile(path string) Option {
+	return func(o *Options) {
+		o.CredentialsFile = path
+	}
+}
+
+// Endpoint sets the URL of the JSON API, for an emulator such as fake-gcs-server. Requests
+// to it aren't authenticated.
+func Endpoint(e string) Option {
+	return func(o *Options) {
+		o.Endpoint = e
+	}
+}
+
+// PartSize sets the size of the chunks blobs are uploaded in, so only one chunk of a blob is
+// held in memory at a time. It's rounded up to a multiple of 256KiB, zero uses the default
+// of the client which is 16MiB.
+func PartSize(n int) Option {
+	return func(o *Options) {
+		o.PartSize = n
+	}
+}
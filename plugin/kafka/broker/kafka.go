@@ -0,0 +1,379 @@
+// Package broker implements the micro broker with Kafka
+package broker
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// requestTimeout is how long to wait for the brokers to respond when connecting or creating a topic
+const requestTimeout = 10 * time.Second
+
+var errNotConnected = errors.New("not connected")
+
+// NewBroker returns a kafka broker. Messages are published to the Kafka topic of the same name,
+// which is created with the Partitions and ReplicationFactor options if it doesn't exist.
+func NewBroker(opts ...broker.Option) broker.Broker {
+	k := &kafkaBroker{
+		opts: broker.Options{
+			Context: context.Background(),
+		},
+		subscribers: make(map[*subscriber]bool),
+		topics:      make(map[string]bool),
+	}
+	k.setOption(opts...)
+	return k
+}
+
+type kafkaBroker struct {
+	opts broker.Options
+
+	// the options parsed from opts
+	addrs             []string
+	clientID          string
+	tlsConfig         *tls.Config
+	mechanism         sasl.Mechanism
+	partitions        int
+	replicationFactor int
+
+	sync.RWMutex
+	connected   bool
+	client      *kafka.Client
+	writer      *kafka.Writer
+	dialer      *kafka.Dialer
+	subscribers map[*subscriber]bool
+	// the topics which are known to exist
+	topics map[string]bool
+}
+
+func (k *kafkaBroker) setOption(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&k.opts)
+	}
+
+	// the addresses can be passed as a comma separated list
+	k.addrs = nil
+	for _, addr := range k.opts.Addrs {
+		for _, a := range strings.Split(addr, ",") {
+			if a = strings.TrimPrefix(strings.TrimSpace(a), "kafka://"); len(a) > 0 {
+				k.addrs = append(k.addrs, a)
+			}
+		}
+	}
+	if len(k.addrs) == 0 {
+		k.addrs = []string{"127.0.0.1:9092"}
+	}
+
+	k.tlsConfig = k.opts.TLSConfig
+	if k.tlsConfig == nil && k.opts.Secure {
+		k.tlsConfig = &tls.Config{}
+	}
+
+	k.clientID = "micro"
+	if id, ok := k.opts.Context.Value(clientIDKey{}).(string); ok && len(id) > 0 {
+		k.clientID = id
+	}
+	k.mechanism = nil
+	if c, ok := k.opts.Context.Value(credentialsKey{}).(credentials); ok && len(c.user) > 0 {
+		k.mechanism = plain.Mechanism{Username: c.user, Password: c.password}
+	}
+	k.partitions = 1
+	if n, ok := k.opts.Context.Value(partitionsKey{}).(int); ok && n > 0 {
+		k.partitions = n
+	}
+	k.replicationFactor = 1
+	if n, ok := k.opts.Context.Value(replicationFactorKey{}).(int); ok && n > 0 {
+		k.replicationFactor = n
+	}
+}
+
+func (k *kafkaBroker) Init(opts ...broker.Option) error {
+	k.setOption(opts...)
+	return nil
+}
+
+func (k *kafkaBroker) Options() broker.Options {
+	return k.opts
+}
+
+func (k *kafkaBroker) Address() string {
+	return k.addrs[0]
+}
+
+// Connect to the brokers, checking they can be reached
+func (k *kafkaBroker) Connect() error {
+	k.Lock()
+	defer k.Unlock()
+	if k.connected {
+		return nil
+	}
+
+	addr := kafka.TCP(k.addrs...)
+	transport := &kafka.Transport{
+		ClientID: k.clientID,
+		TLS:      k.tlsConfig,
+		SASL:     k.mechanism,
+	}
+	client := &kafka.Client{Addr: addr, Transport: transport, Timeout: requestTimeout}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if _, err := client.Metadata(ctx, &kafka.MetadataRequest{}); err != nil {
+		return errors.Wrap(err, "Error connecting to kafka")
+	}
+
+	k.client = client
+	k.writer = &kafka.Writer{
+		Addr:         addr,
+		Transport:    transport,
+		RequiredAcks: kafka.RequireOne,
+		// publishing is synchronous so messages aren't held back to be batched
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	k.dialer = &kafka.Dialer{
+		ClientID:      k.clientID,
+		Timeout:       requestTimeout,
+		DualStack:     true,
+		TLS:           k.tlsConfig,
+		SASLMechanism: k.mechanism,
+	}
+	k.connected = true
+	return nil
+}
+
+// Disconnect from the brokers, unsubscribing the subscribers
+func (k *kafkaBroker) Disconnect() error {
+	k.Lock()
+	if !k.connected {
+		k.Unlock()
+		return nil
+	}
+	subs := make([]*subscriber, 0, len(k.subscribers))
+	for s := range k.subscribers {
+		subs = append(subs, s)
+	}
+	writer := k.writer
+	k.connected = false
+	k.writer = nil
+	k.Unlock()
+
+	for _, s := range subs {
+		s.Unsubscribe()
+	}
+	return writer.Close()
+}
+
+// createTopic creates the topic if the broker hasn't already
+func (k *kafkaBroker) createTopic(client *kafka.Client, topic string) error {
+	k.RLock()
+	ok := k.topics[topic]
+	k.RUnlock()
+	if ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	rsp, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: []kafka.TopicConfig{{
+		Topic:             topic,
+		NumPartitions:     k.partitions,
+		ReplicationFactor: k.replicationFactor,
+	}}})
+	if err != nil {
+		return errors.Wrap(err, "Error creating topic")
+	}
+	if err := rsp.Errors[topic]; err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return errors.Wrap(err, "Error creating topic")
+	}
+
+	k.Lock()
+	k.topics[topic] = true
+	k.Unlock()
+	return nil
+}
+
+// Publish a message to a topic. Kafka supports headers so the message isn't encoded, the body
+// is the value of the Kafka message.
+func (k *kafkaBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	options := broker.PublishOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	k.RLock()
+	connected, client, writer := k.connected, k.client, k.writer
+	k.RUnlock()
+	if !connected {
+		return errNotConnected
+	}
+	if err := k.createTopic(client, topic); err != nil {
+		return err
+	}
+	m, err := broker.PrepareMessage(m, k.opts, options)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]kafka.Header, 0, len(m.Header))
+	for key, v := range m.Header {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(v)})
+	}
+	err = writer.WriteMessages(options.Context, kafka.Message{
+		Topic:   topic,
+		Value:   m.Body,
+		Headers: headers,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error publishing message to topic")
+	}
+	return nil
+}
+
+// Subscribe to a topic. Subscribers with the same queue are members of the same kafka consumer
+// group, so the partitions of the topic are balanced between them. Subscribers without a queue
+// are given a unique group, so they receive every message published after they subscribed.
+func (k *kafkaBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	// validate the topic
+	if len(topic) == 0 {
+		return nil, events.ErrMissingTopic
+	}
+
+	options := broker.SubscribeOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	group := options.Queue
+	if len(group) == 0 {
+		group = uuid.New().String()
+	}
+
+	k.RLock()
+	connected, client, dialer := k.connected, k.client, k.dialer
+	k.RUnlock()
+	if !connected {
+		return nil, errNotConnected
+	}
+	// the reader fails if the topic doesn't exist
+	if err := k.createTopic(client, topic); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &subscriber{
+		opts:   options,
+		topic:  topic,
+		broker: k,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     k.addrs,
+			GroupID:     group,
+			Topic:       topic,
+			Dialer:      dialer,
+			StartOffset: kafka.LastOffset,
+		}),
+	}
+
+	k.Lock()
+	k.subscribers[s] = true
+	k.Unlock()
+
+	go s.run(ctx, h)
+	return s, nil
+}
+
+func (k *kafkaBroker) String() string {
+	return "kafka"
+}
+
+type subscriber struct {
+	opts   broker.SubscribeOptions
+	topic  string
+	broker *kafkaBroker
+	reader *kafka.Reader
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe stops the subscriber, waiting for the message being handled
+func (s *subscriber) Unsubscribe() error {
+	var err error
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+		err = s.reader.Close()
+
+		s.broker.Lock()
+		delete(s.broker.subscribers, s)
+		s.broker.Unlock()
+	})
+	return err
+}
+
+// run passes the messages of the topic to the handler. The offset of a message is committed once
+// it's handled, even if the handler returns an error, as the broker doesn't redeliver messages.
+func (s *subscriber) run(ctx context.Context, h broker.Handler) {
+	defer close(s.done)
+
+	for {
+		m, err := s.reader.FetchMessage(ctx)
+		if ctx.Err() != nil {
+			return
+		} else if err != nil {
+			logger.Errorf("Error reading from topic %v: %v", s.topic, err)
+			return
+		}
+
+		msg := &broker.Message{
+			Header: make(map[string]string, len(m.Headers)),
+			Body:   m.Value,
+		}
+		for _, hdr := range m.Headers {
+			msg.Header[hdr.Key] = string(hdr.Value)
+		}
+		if msg, err = broker.DecompressMessage(msg); err != nil {
+			logger.Errorf("Error decompressing message from topic %v: %v", s.topic, err)
+		} else if err := h(msg); err != nil {
+			if s.opts.ErrorHandler != nil {
+				s.opts.ErrorHandler(msg, err)
+			} else {
+				logger.Errorf("Error handling message from topic %v: %v", s.topic, err)
+			}
+		}
+
+		if err := s.reader.CommitMessages(context.Background(), m); err != nil {
+			logger.Errorf("Error committing message: %v", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package broker
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	b := NewBroker(broker.Addrs("kafka://one:9092,two:9092", "three:9092"), Partitions(3)).(*kafkaBroker)
+	assert.Equal(t, []string{"one:9092", "two:9092", "three:9092"}, b.addrs)
+	assert.Equal(t, "one:9092", b.Address())
+	assert.Equal(t, 3, b.partitions)
+	assert.Equal(t, 1, b.replicationFactor)
+	assert.Nil(t, b.mechanism)
+
+	assert.NoError(t, b.Init(Credentials("user", "pass"), broker.Secure(true)))
+	assert.NotNil(t, b.mechanism)
+	assert.NotNil(t, b.tlsConfig)
+}
+
+func TestNotConnected(t *testing.T) {
+	b := NewBroker()
+	assert.Equal(t, events.ErrMissingTopic, b.Publish("", &broker.Message{}))
+	assert.Equal(t, errNotConnected, b.Publish("foo", &broker.Message{}))
+	_, err := b.Subscribe("foo", func(*broker.Message) error { return nil })
+	assert.Equal(t, errNotConnected, err)
+}
+
+func TestBroker(t *testing.T) {
+	addrs := os.Getenv("KAFKA_ADDRESS")
+	if len(addrs) == 0 {
+		t.Skipf("Missing required config KAFKA_ADDRESS")
+	}
+	b := NewBroker(broker.Addrs(addrs))
+	assert.NoError(t, b.Connect())
+	defer b.Disconnect()
+
+	receive := func(ch <-chan *broker.Message) *broker.Message {
+		select {
+		case m := <-ch:
+			return m
+		case <-time.After(30 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return nil
+		}
+	}
+
+	t.Run("Simple", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ch := make(chan *broker.Message, 1)
+		sub, err := b.Subscribe(topic, func(m *broker.Message) error {
+			ch <- m
+			return nil
+		})
+		assert.NoError(t, err)
+		defer sub.Unsubscribe()
+		assert.Equal(t, topic, sub.Topic())
+		// wait for the subscriber to join its group before publishing
+		time.Sleep(5 * time.Second)
+
+		assert.NoError(t, b.Publish(topic, &broker.Message{
+			Header: map[string]string{"foo": "bar"},
+			Body:   []byte("hello"),
+		}))
+		if m := receive(ch); m != nil {
+			assert.Equal(t, "bar", m.Header["foo"])
+			assert.Equal(t, "hello", string(m.Body))
+		}
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ch := make(chan *broker.Message, 10)
+		h := func(m *broker.Message) error {
+			ch <- m
+			return nil
+		}
+		for i := 0; i < 2; i++ {
+			sub, err := b.Subscribe(topic, h, broker.Queue("queue"))
+			assert.NoError(t, err)
+			defer sub.Unsubscribe()
+		}
+		time.Sleep(5 * time.Second)
+
+		// each message is only delivered to one of the subscribers in the queue
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, b.Publish(topic, &broker.Message{Body: []byte("hello")}))
+		}
+		for i := 0; i < 5; i++ {
+			receive(ch)
+		}
+		select {
+		case <-ch:
+			t.Errorf("Message delivered more than once")
+		case <-time.After(2 * time.Second):
+		}
+	})
+}
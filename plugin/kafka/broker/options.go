@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/broker"
+)
+
+type clientIDKey struct{}
+type credentialsKey struct{}
+type partitionsKey struct{}
+type replicationFactorKey struct{}
+
+type credentials struct {
+	user     string
+	password string
+}
+
+// ClientID sets the client id used when connecting to the brokers
+func ClientID(id string) broker.Option {
+	return setBrokerOption(clientIDKey{}, id)
+}
+
+// Credentials to authenticate with using SASL/PLAIN
+func Credentials(user, password string) broker.Option {
+	return setBrokerOption(credentialsKey{}, credentials{user: user, password: password})
+}
+
+// Partitions sets the number of partitions of the topics the broker creates
+func Partitions(n int) broker.Option {
+	return setBrokerOption(partitionsKey{}, n)
+}
+
+// ReplicationFactor sets the replication factor of the topics the broker creates
+func ReplicationFactor(n int) broker.Option {
+	return setBrokerOption(replicationFactorKey{}, n)
+}
+
+// setBrokerOption returns a function to setup a context with given value
+func setBrokerOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
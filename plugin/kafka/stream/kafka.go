@@ -0,0 +1,331 @@
+// Package stream implements the micro events stream with Kafka
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const (
+	// attemptHeader is the header of the number of times an event has been delivered
+	attemptHeader = "attempt"
+	// requestTimeout is how long to wait for the brokers to create a topic
+	requestTimeout = 10 * time.Second
+)
+
+// NewStream returns an initialized kafka stream. Topics are created with the Partitions,
+// ReplicationFactor and Retention options the first time they're published or consumed.
+func NewStream(opts ...Option) (events.Stream, error) {
+	// parse the options
+	options := Options{
+		ClientID:          "micro",
+		Partitions:        1,
+		ReplicationFactor: 1,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Addresses) == 0 {
+		options.Addresses = []string{"127.0.0.1:9092"}
+	}
+
+	var mechanism sasl.Mechanism
+	if len(options.User) > 0 {
+		mechanism = plain.Mechanism{Username: options.User, Password: options.Password}
+	}
+
+	addr := kafka.TCP(options.Addresses...)
+	transport := &kafka.Transport{
+		ClientID: options.ClientID,
+		TLS:      options.TLSConfig,
+		SASL:     mechanism,
+	}
+
+	return &stream{
+		options: options,
+		client:  &kafka.Client{Addr: addr, Transport: transport},
+		writer: &kafka.Writer{
+			Addr:         addr,
+			Transport:    transport,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// publishing is synchronous so messages aren't held back to be batched
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		dialer: &kafka.Dialer{
+			ClientID:      options.ClientID,
+			Timeout:       requestTimeout,
+			DualStack:     true,
+			TLS:           options.TLSConfig,
+			SASLMechanism: mechanism,
+		},
+		topics: make(map[string]bool),
+	}, nil
+}
+
+type stream struct {
+	options Options
+	client  *kafka.Client
+	writer  *kafka.Writer
+	dialer  *kafka.Dialer
+
+	sync.RWMutex
+	// the topics which are known to exist
+	topics map[string]bool
+}
+
+// createTopic creates the topic if the stream hasn't already
+func (s *stream) createTopic(topic string) error {
+	s.RLock()
+	ok := s.topics[topic]
+	s.RUnlock()
+	if ok {
+		return nil
+	}
+
+	config := kafka.TopicConfig{
+		Topic:             topic,
+		NumPartitions:     s.options.Partitions,
+		ReplicationFactor: s.options.ReplicationFactor,
+	}
+	if s.options.Retention > 0 {
+		config.ConfigEntries = append(config.ConfigEntries, kafka.ConfigEntry{
+			ConfigName:  "retention.ms",
+			ConfigValue: strconv.FormatInt(s.options.Retention.Milliseconds(), 10),
+		})
+	}
+	if s.options.Compacted[topic] {
+		config.ConfigEntries = append(config.ConfigEntries, kafka.ConfigEntry{
+			ConfigName:  "cleanup.policy",
+			ConfigValue: "compact",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	rsp, err := s.client.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: []kafka.TopicConfig{config}})
+	if err != nil {
+		return errors.Wrap(err, "Error creating topic")
+	}
+	if err := rsp.Errors[topic]; err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return errors.Wrap(err, "Error creating topic")
+	}
+
+	s.Lock()
+	s.topics[topic] = true
+	s.Unlock()
+	return nil
+}
+
+// write the message to its topic, which is created if it doesn't exist
+func (s *stream) write(msg kafka.Message) error {
+	if err := s.createTopic(msg.Topic); err != nil {
+		return err
+	}
+	if err := s.writer.WriteMessages(context.Background(), msg); err != nil {
+		return errors.Wrap(err, "Error publishing message to topic")
+	}
+	return nil
+}
+
+// Publish a message to a topic
+func (s *stream) Publish(topic string, msg interface{}, opts ...events.PublishOption) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	// parse the options
+	options := events.PublishOptions{
+		Timestamp: time.Now(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// encode the message if it's not already encoded
+	var payload []byte
+	if p, ok := msg.([]byte); ok {
+		payload = p
+	} else {
+		p, err := json.Marshal(msg)
+		if err != nil {
+			return events.ErrEncodingMessage
+		}
+		payload = p
+	}
+
+	// construct the event
+	event := &events.Event{
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Timestamp:   options.Timestamp,
+		Metadata:    options.Metadata,
+		Payload:     payload,
+		OrderingKey: options.OrderingKey,
+	}
+
+	// serialize the event to bytes
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding event")
+	}
+
+	// the key is hashed to the partition of the event, so the events with the same ordering key
+	// are written to the same partition in order, and the others are spread over the partitions
+	key := event.ID
+	if len(options.OrderingKey) > 0 {
+		key = options.OrderingKey
+	}
+	return s.write(kafka.Message{
+		Topic:   topic,
+		Key:     []byte(key),
+		Value:   bytes,
+		Headers: attemptHeaders(1),
+	})
+}
+
+// Consume a topic. Consumers with the same group are members of the same kafka consumer group,
+// so the partitions of the topic are balanced between them.
+func (s *stream) Consume(topic string, opts ...events.ConsumeOption) (<-chan events.Event, error) {
+	// validate the topic
+	if len(topic) == 0 {
+		return nil, events.ErrMissingTopic
+	}
+
+	// parse the options
+	options := events.ConsumeOptions{
+		Group:   uuid.New().String(),
+		AutoAck: true,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// the reader fails if the topic doesn't exist
+	if err := s.createTopic(topic); err != nil {
+		return nil, err
+	}
+
+	// a group which hasn't committed offsets starts from the end of the topic, unless there's an
+	// offset in which case it reads from the start, skipping the messages written before it
+	start := kafka.LastOffset
+	if !options.Offset.IsZero() {
+		start = kafka.FirstOffset
+	}
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     s.options.Addresses,
+		GroupID:     options.Group,
+		Topic:       topic,
+		Dialer:      s.dialer,
+		StartOffset: start,
+	})
+
+	c := make(chan events.Event)
+	go func() {
+		defer close(c)
+		defer r.Close()
+
+		for {
+			m, err := r.FetchMessage(ctx)
+			if ctx.Err() != nil {
+				return
+			} else if err != nil {
+				logger.Errorf("Error reading from topic %v: %v", topic, err)
+				return
+			}
+
+			// decode the message
+			var evt events.Event
+			if err := json.Unmarshal(m.Value, &evt); err != nil {
+				logger.Warnf("Failed to unmarshal event, discarding %v/%v: %v", m.Partition, m.Offset, err)
+				s.commit(r, m)
+				continue
+			}
+			evt.Partition = m.Partition
+			if !options.Offset.IsZero() && m.Time.Before(options.Offset) {
+				s.commit(r, m)
+				continue
+			}
+
+			if !options.AutoAck {
+				// committing the offset of a message also commits the ones before it in the partition
+				evt.SetAckFunc(func() error {
+					return r.CommitMessages(context.Background(), m)
+				})
+				// a single message can't be redelivered, so it's republished and its offset committed
+				attempt := messageAttempt(m)
+				evt.SetNackFunc(func() error {
+					if limit := options.GetRetryLimit(); limit > -1 && attempt > limit {
+						if len(options.DeadLetterTopic) == 0 {
+							logger.Errorf("Message retry limit reached, discarding: %v", evt.ID)
+						} else if err := events.DeadLetter(s, options.DeadLetterTopic, evt); err != nil {
+							return err
+						}
+					} else if err := s.write(kafka.Message{
+						Topic:   topic,
+						Key:     m.Key,
+						Value:   m.Value,
+						Headers: attemptHeaders(attempt + 1),
+					}); err != nil {
+						return err
+					}
+					return r.CommitMessages(context.Background(), m)
+				})
+			}
+
+			// push onto the channel and wait for the consumer to take the event off before we acknowledge it.
+			select {
+			case c <- evt:
+			case <-ctx.Done():
+				return
+			}
+
+			if options.AutoAck {
+				s.commit(r, m)
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+// commit the offset of the message, logging any error as the message will be redelivered
+func (s *stream) commit(r *kafka.Reader, m kafka.Message) {
+	if err := r.CommitMessages(context.Background(), m); err != nil {
+		logger.Errorf("Error acknowledging message: %v", err)
+	}
+}
+
+// attemptHeaders returns the headers of a message delivered for the attempt
+func attemptHeaders(attempt int) []kafka.Header {
+	return []kafka.Header{{Key: attemptHeader, Value: []byte(strconv.Itoa(attempt))}}
+}
+
+// messageAttempt returns the number of times the message has been delivered
+func messageAttempt(m kafka.Message) int {
+	for _, h := range m.Headers {
+		if h.Key != attemptHeader {
+			continue
+		}
+		if n, err := strconv.Atoi(string(h.Value)); err == nil {
+			return n
+		}
+	}
+	return 1
+}
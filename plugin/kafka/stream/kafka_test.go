@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type testObj struct {
+	One string
+	Two int64
+}
+
+func TestAttempt(t *testing.T) {
+	assert.Equal(t, 1, messageAttempt(kafka.Message{}))
+	assert.Equal(t, 3, messageAttempt(kafka.Message{Headers: attemptHeaders(3)}))
+	assert.Equal(t, 1, messageAttempt(kafka.Message{Headers: []kafka.Header{{Key: attemptHeader, Value: []byte("x")}}}))
+}
+
+func TestMissingTopic(t *testing.T) {
+	s, err := NewStream()
+	assert.NoError(t, err)
+	assert.Equal(t, events.ErrMissingTopic, s.Publish("", nil))
+	_, err = s.Consume("")
+	assert.Equal(t, events.ErrMissingTopic, err)
+}
+
+func TestStream(t *testing.T) {
+	addrs := os.Getenv("KAFKA_ADDRESS")
+	if len(addrs) == 0 {
+		t.Skipf("Missing required config KAFKA_ADDRESS")
+	}
+	s, err := NewStream(Addresses(strings.Split(addrs, ",")...), Retention(time.Hour))
+	assert.NoError(t, err)
+
+	receive := func(ch <-chan events.Event) (events.Event, bool) {
+		select {
+		case ev := <-ch:
+			return ev, true
+		case <-time.After(30 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return events.Event{}, false
+		}
+	}
+
+	t.Run("Simple", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic, events.WithContext(ctx))
+		assert.NoError(t, err)
+		// wait for the consumer to join its group before publishing
+		time.Sleep(5 * time.Second)
+
+		tobj := testObj{One: "foo", Two: 12345}
+		assert.NoError(t, s.Publish(topic, tobj, events.WithMetadata(map[string]string{"meta": "bar"})))
+
+		ev, ok := receive(ch)
+		if !ok {
+			return
+		}
+		assert.NotEmpty(t, ev.ID)
+		assert.Equal(t, topic, ev.Topic)
+		assert.Equal(t, "bar", ev.Metadata["meta"])
+		var tes testObj
+		assert.NoError(t, json.Unmarshal(ev.Payload, &tes))
+		assert.Equal(t, tobj, tes)
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		assert.NoError(t, s.Publish(topic, testObj{One: "before"}))
+		time.Sleep(time.Second)
+		start := time.Now()
+		assert.NoError(t, s.Publish(topic, testObj{One: "after"}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic, events.WithOffset(start), events.WithContext(ctx))
+		assert.NoError(t, err)
+		ev, ok := receive(ch)
+		if !ok {
+			return
+		}
+		var tes testObj
+		assert.NoError(t, ev.Unmarshal(&tes))
+		assert.Equal(t, "after", tes.One)
+	})
+
+	t.Run("Nack", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic,
+			events.WithAutoAck(false, time.Minute),
+			events.WithRetryLimit(1),
+			events.WithOffset(time.Now()),
+			events.WithContext(ctx),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Publish(topic, testObj{One: "retry"}))
+
+		// the event is redelivered once before it's discarded
+		for i := 0; i < 2; i++ {
+			ev, ok := receive(ch)
+			if !ok {
+				return
+			}
+			assert.NoError(t, ev.Nack())
+		}
+		select {
+		case <-ch:
+			t.Errorf("Expected the event to be discarded")
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	t.Run("OrderingKey", func(t *testing.T) {
+		ps, err := NewStream(Addresses(strings.Split(addrs, ",")...), Partitions(4), Retention(time.Hour))
+		assert.NoError(t, err)
+		topic := "test-" + uuid.New().String()
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, ps.Publish(topic, testObj{Two: int64(i)}, events.WithOrderingKey("key")))
+		}
+
+		// the events with the same key are in the same partition, in the order they were published
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := ps.Consume(topic, events.WithOffset(start), events.WithContext(ctx))
+		assert.NoError(t, err)
+		partition := -1
+		for i := 0; i < 10; i++ {
+			ev, ok := receive(ch)
+			if !ok {
+				return
+			}
+			var tes testObj
+			assert.NoError(t, ev.Unmarshal(&tes))
+			assert.Equal(t, int64(i), tes.Two)
+			assert.Equal(t, "key", ev.OrderingKey)
+			if partition == -1 {
+				partition = ev.Partition
+			}
+			assert.Equal(t, partition, ev.Partition)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Options which are used to configure the kafka stream
+type Options struct {
+	// Addresses of the kafka brokers
+	Addresses []string
+	// ClientID identifies the stream to the brokers
+	ClientID  string
+	TLSConfig *tls.Config
+	// User and Password authenticate with SASL/PLAIN if set
+	User     string
+	Password string
+	// Partitions and ReplicationFactor of the topics the stream creates
+	Partitions        int
+	ReplicationFactor int
+	// Retention of the events of the topics the stream creates, the broker default is used if
+	// it's zero
+	Retention time.Duration
+	// Compacted topics are created with the compact cleanup policy, so kafka only keeps the latest
+	// event of each ordering key
+	Compacted map[string]bool
+}
+
+// Option is a function which configures options
+type Option func(o *Options)
+
+// Addresses of the kafka brokers
+func Addresses(addrs ...string) Option {
+	return func(o *Options) {
+		o.Addresses = addrs
+	}
+}
+
+// ClientID sets the client id used when connecting to the brokers
+func ClientID(id string) Option {
+	return func(o *Options) {
+		o.ClientID = id
+	}
+}
+
+// TLSConfig to use when connecting to the brokers
+func TLSConfig(t *tls.Config) Option {
+	return func(o *Options) {
+		o.TLSConfig = t
+	}
+}
+
+// Credentials to authenticate with using SASL/PLAIN
+func Credentials(user, password string) Option {
+	return func(o *Options) {
+		o.User = user
+		o.Password = password
+	}
+}
+
+// Partitions sets the number of partitions of the topics the stream creates
+func Partitions(n int) Option {
+	return func(o *Options) {
+		o.Partitions = n
+	}
+}
+
+// ReplicationFactor sets the replication factor of the topics the stream creates
+func ReplicationFactor(n int) Option {
+	return func(o *Options) {
+		o.ReplicationFactor = n
+	}
+}
+
+// Retention sets how long the events of the topics the stream creates are kept
+func Retention(d time.Duration) Option {
+	return func(o *Options) {
+		o.Retention = d
+	}
+}
+
+// Compacted sets the topics which are created with the compact cleanup policy
+func Compacted(topics ...string) Option {
+	return func(o *Options) {
+		if o.Compacted == nil {
+			o.Compacted = map[string]bool{}
+		}
+		for _, t := range topics {
+			o.Compacted[t] = true
+		}
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/micro/micro/plugin/mqtt/broker/v3
+
+go 1.15
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.3.5
+	github.com/google/uuid v1.1.2
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/stretchr/testify v1.7.0
+)
+
+replace github.com/micro/micro/v3 => ../../..
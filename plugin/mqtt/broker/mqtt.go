@@ -0,0 +1,343 @@
+// Package broker implements the micro broker with MQTT
+package broker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/util/codec/json"
+)
+
+const (
+	// defaultQoS is the QoS level used when none is set, at least once
+	defaultQoS byte = 1
+	// requestTimeout is how long to wait for the server to respond
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	errNotConnected = errors.New("not connected")
+	errTimeout      = errors.New("timed out waiting for the MQTT server")
+	errInvalidQoS   = errors.New("invalid QoS level, must be 0, 1 or 2")
+)
+
+// NewBroker returns an MQTT broker. Messages are encoded with the codec, as MQTT 3.1.1 doesn't
+// support headers, unless they're published with PublishRaw or subscribed to with Raw.
+func NewBroker(opts ...broker.Option) broker.Broker {
+	m := &mqttBroker{
+		opts: broker.Options{
+			Codec:   json.Marshaler{},
+			Context: context.Background(),
+		},
+		subscribers: make(map[*subscriber]bool),
+	}
+	m.setOption(opts...)
+	return m
+}
+
+type mqttBroker struct {
+	opts  broker.Options
+	addrs []string
+
+	sync.RWMutex
+	client mqtt.Client
+	// subscribers are resubscribed when the client reconnects, as the server doesn't keep the
+	// subscriptions of a clean session
+	subscribers map[*subscriber]bool
+}
+
+func (m *mqttBroker) setOption(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&m.opts)
+	}
+
+	// the addresses can be passed as a comma separated list, addresses without a scheme use
+	// tcp, or ssl if the broker is secure
+	scheme := "tcp://"
+	if m.opts.Secure || m.opts.TLSConfig != nil {
+		scheme = "ssl://"
+	}
+	m.addrs = nil
+	for _, addr := range m.opts.Addrs {
+		for _, a := range strings.Split(addr, ",") {
+			if a = strings.TrimSpace(a); len(a) == 0 {
+				continue
+			}
+			if !strings.Contains(a, "://") {
+				a = scheme + a
+			}
+			m.addrs = append(m.addrs, a)
+		}
+	}
+	if len(m.addrs) == 0 {
+		m.addrs = []string{scheme + "127.0.0.1:1883"}
+	}
+}
+
+// clientOptions returns the options of the MQTT client
+func (m *mqttBroker) clientOptions() *mqtt.ClientOptions {
+	opts := mqtt.NewClientOptions()
+	for _, addr := range m.addrs {
+		opts.AddBroker(addr)
+	}
+
+	id, _ := m.opts.Context.Value(clientIDKey{}).(string)
+	if len(id) == 0 {
+		id = "micro-" + uuid.New().String()
+	}
+	opts.SetClientID(id)
+	if c, ok := m.opts.Context.Value(credentialsKey{}).(credentials); ok {
+		opts.SetUsername(c.user)
+		opts.SetPassword(c.password)
+	}
+	if m.opts.TLSConfig != nil {
+		opts.SetTLSConfig(m.opts.TLSConfig)
+	}
+
+	opts.SetCleanSession(true)
+	opts.SetAutoReconnect(true)
+	opts.SetConnectTimeout(requestTimeout)
+	opts.SetOnConnectHandler(m.onConnect)
+	opts.SetConnectionLostHandler(func(c mqtt.Client, err error) {
+		logger.Warnf("Connection to MQTT server lost, reconnecting: %v", err)
+	})
+	return opts
+}
+
+// onConnect resubscribes the subscribers when the client reconnects
+func (m *mqttBroker) onConnect(c mqtt.Client) {
+	m.RLock()
+	subs := make([]*subscriber, 0, len(m.subscribers))
+	for s := range m.subscribers {
+		subs = append(subs, s)
+	}
+	m.RUnlock()
+
+	for _, s := range subs {
+		if err := wait(c.Subscribe(s.subject(), s.qos, s.handle)); err != nil {
+			logger.Errorf("Error resubscribing to %v: %v", s.topic, err)
+		}
+	}
+}
+
+// wait for the token to complete, returning its error
+func wait(t mqtt.Token) error {
+	if !t.WaitTimeout(requestTimeout) {
+		return errTimeout
+	}
+	return t.Error()
+}
+
+func (m *mqttBroker) Init(opts ...broker.Option) error {
+	m.setOption(opts...)
+	return nil
+}
+
+func (m *mqttBroker) Options() broker.Options {
+	return m.opts
+}
+
+func (m *mqttBroker) Address() string {
+	return m.addrs[0]
+}
+
+// Connect to the MQTT server, the client reconnects if the connection is lost
+func (m *mqttBroker) Connect() error {
+	m.Lock()
+	defer m.Unlock()
+	if m.client != nil {
+		return nil
+	}
+
+	c := mqtt.NewClient(m.clientOptions())
+	if err := wait(c.Connect()); err != nil {
+		return err
+	}
+	m.client = c
+	return nil
+}
+
+// Disconnect from the MQTT server. The subscribers are subscribed again if the broker connects.
+func (m *mqttBroker) Disconnect() error {
+	m.Lock()
+	defer m.Unlock()
+	if m.client == nil {
+		return nil
+	}
+
+	// wait for the messages being handled
+	m.client.Disconnect(uint(requestTimeout / time.Millisecond))
+	m.client = nil
+	return nil
+}
+
+// Publish a message to a topic, with the QoS level set with PublishQoS
+func (m *mqttBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	options := broker.PublishOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	qos, ok := options.Context.Value(qosKey{}).(byte)
+	if !ok {
+		qos = defaultQoS
+	} else if qos > 2 {
+		return errInvalidQoS
+	}
+	retained, _ := options.Context.Value(retainedKey{}).(bool)
+
+	msg, err := broker.PrepareMessage(msg, m.opts, options)
+	if err != nil {
+		return err
+	}
+	payload := msg.Body
+	if raw, _ := options.Context.Value(rawKey{}).(bool); !raw {
+		b, err := m.opts.Codec.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		payload = b
+	}
+
+	m.RLock()
+	c := m.client
+	m.RUnlock()
+	if c == nil {
+		return errNotConnected
+	}
+	return wait(c.Publish(topic, qos, retained, payload))
+}
+
+// Subscribe to a topic, which may contain the MQTT wildcards + and #. Subscribers with the same
+// queue share a subscription, using the $share/<queue>/<topic> shared subscriptions supported by
+// most MQTT servers, so each message is delivered to one of them.
+func (m *mqttBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	// validate the topic
+	if len(topic) == 0 {
+		return nil, events.ErrMissingTopic
+	}
+
+	options := broker.SubscribeOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	s := &subscriber{
+		opts:    options,
+		topic:   topic,
+		broker:  m,
+		handler: h,
+		qos:     defaultQoS,
+	}
+	if qos, ok := options.Context.Value(qosKey{}).(byte); ok {
+		if qos > 2 {
+			return nil, errInvalidQoS
+		}
+		s.qos = qos
+	}
+	s.raw, _ = options.Context.Value(rawKey{}).(bool)
+
+	// the subscriber is added before subscribing so it's resubscribed if the client reconnects
+	m.Lock()
+	c := m.client
+	if c != nil {
+		m.subscribers[s] = true
+	}
+	m.Unlock()
+	if c == nil {
+		return nil, errNotConnected
+	}
+	if err := wait(c.Subscribe(s.subject(), s.qos, s.handle)); err != nil {
+		m.Lock()
+		delete(m.subscribers, s)
+		m.Unlock()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (m *mqttBroker) String() string {
+	return "mqtt"
+}
+
+type subscriber struct {
+	opts    broker.SubscribeOptions
+	topic   string
+	broker  *mqttBroker
+	handler broker.Handler
+	qos     byte
+	raw     bool
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+func (s *subscriber) Unsubscribe() error {
+	s.broker.Lock()
+	delete(s.broker.subscribers, s)
+	c := s.broker.client
+	s.broker.Unlock()
+	if c == nil {
+		return nil
+	}
+	return wait(c.Unsubscribe(s.subject()))
+}
+
+// subject returns the MQTT topic filter of the subscription
+func (s *subscriber) subject() string {
+	if len(s.opts.Queue) > 0 {
+		return "$share/" + s.opts.Queue + "/" + s.topic
+	}
+	return s.topic
+}
+
+// handle a message delivered to the subscription
+func (s *subscriber) handle(c mqtt.Client, mm mqtt.Message) {
+	msg := &broker.Message{}
+	if s.raw {
+		msg.Header = map[string]string{"Micro-Topic": mm.Topic()}
+		msg.Body = mm.Payload()
+	} else if err := s.broker.opts.Codec.Unmarshal(mm.Payload(), msg); err != nil {
+		logger.Errorf("Error decoding message from %v: %v", mm.Topic(), err)
+		if s.opts.ErrorHandler != nil {
+			s.opts.ErrorHandler(&broker.Message{Body: mm.Payload()}, err)
+		}
+		return
+	}
+
+	msg, err := broker.DecompressMessage(msg)
+	if err != nil {
+		logger.Errorf("Error decompressing message from %v: %v", mm.Topic(), err)
+		if s.opts.ErrorHandler != nil {
+			s.opts.ErrorHandler(&broker.Message{Body: mm.Payload()}, err)
+		}
+		return
+	}
+	if err := s.handler(msg); err != nil {
+		if s.opts.ErrorHandler != nil {
+			s.opts.ErrorHandler(msg, err)
+		} else {
+			logger.Errorf("Error handling message from %v: %v", mm.Topic(), err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package broker
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	b := NewBroker(broker.Addrs("one:1883,ws://two:80", "three:1883")).(*mqttBroker)
+	assert.Equal(t, []string{"tcp://one:1883", "ws://two:80", "tcp://three:1883"}, b.addrs)
+	assert.Equal(t, "tcp://one:1883", b.Address())
+
+	assert.NoError(t, b.Init(broker.Addrs("one:8883"), broker.Secure(true)))
+	assert.Equal(t, []string{"ssl://one:8883"}, b.addrs)
+
+	s := &subscriber{topic: "devices/+/telemetry"}
+	assert.Equal(t, "devices/+/telemetry", s.subject())
+	s.opts.Queue = "telemetry"
+	assert.Equal(t, "$share/telemetry/devices/+/telemetry", s.subject())
+}
+
+func TestNotConnected(t *testing.T) {
+	b := NewBroker()
+	assert.Equal(t, events.ErrMissingTopic, b.Publish("", &broker.Message{}))
+	assert.Equal(t, errNotConnected, b.Publish("foo", &broker.Message{}))
+	assert.Equal(t, errInvalidQoS, b.Publish("foo", &broker.Message{}, PublishQoS(3)))
+	_, err := b.Subscribe("foo", func(*broker.Message) error { return nil })
+	assert.Equal(t, errNotConnected, err)
+	_, err = b.Subscribe("foo", func(*broker.Message) error { return nil }, SubscribeQoS(3))
+	assert.Equal(t, errInvalidQoS, err)
+}
+
+func TestBroker(t *testing.T) {
+	addr := os.Getenv("MQTT_ADDRESS")
+	if len(addr) == 0 {
+		t.Skipf("Missing required config MQTT_ADDRESS")
+	}
+	b := NewBroker(broker.Addrs(addr))
+	if !assert.NoError(t, b.Connect()) {
+		return
+	}
+	defer b.Disconnect()
+
+	receive := func(ch <-chan *broker.Message) *broker.Message {
+		select {
+		case m := <-ch:
+			return m
+		case <-time.After(10 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return nil
+		}
+	}
+
+	t.Run("Simple", func(t *testing.T) {
+		topic := "test/" + uuid.New().String()
+		ch := make(chan *broker.Message, 1)
+		sub, err := b.Subscribe(topic, func(m *broker.Message) error {
+			ch <- m
+			return nil
+		}, SubscribeQoS(2))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer sub.Unsubscribe()
+
+		assert.NoError(t, b.Publish(topic, &broker.Message{
+			Header: map[string]string{"foo": "bar"},
+			Body:   []byte("hello"),
+		}, PublishQoS(2)))
+		if m := receive(ch); m != nil {
+			assert.Equal(t, "bar", m.Header["foo"])
+			assert.Equal(t, "hello", string(m.Body))
+		}
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		id := uuid.New().String()
+		ch := make(chan *broker.Message, 1)
+		sub, err := b.Subscribe("devices/+/"+id, func(m *broker.Message) error {
+			ch <- m
+			return nil
+		}, Raw())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer sub.Unsubscribe()
+
+		assert.NoError(t, b.Publish("devices/sensor/"+id, &broker.Message{Body: []byte(`{"temp":20}`)}, PublishRaw()))
+		if m := receive(ch); m != nil {
+			assert.Equal(t, "devices/sensor/"+id, m.Header["Micro-Topic"])
+			assert.Equal(t, `{"temp":20}`, string(m.Body))
+		}
+	})
+	t.Run("Queue", func(t *testing.T) {
+		topic := "test/" + uuid.New().String()
+		ch := make(chan *broker.Message, 10)
+		h := func(m *broker.Message) error {
+			ch <- m
+			return nil
+		}
+		for i := 0; i < 2; i++ {
+			sub, err := b.Subscribe(topic, h, broker.Queue("queue"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer sub.Unsubscribe()
+		}
+
+		// each message is only delivered to one of the subscribers in the queue
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, b.Publish(topic, &broker.Message{Body: []byte("hello")}))
+		}
+		for i := 0; i < 4; i++ {
+			receive(ch)
+		}
+		select {
+		case <-ch:
+			t.Errorf("Message delivered more than once")
+		case <-time.After(time.Second):
+		}
+	})
+}
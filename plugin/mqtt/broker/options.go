@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/broker"
+)
+
+type clientIDKey struct{}
+type credentialsKey struct{}
+type qosKey struct{}
+type retainedKey struct{}
+type rawKey struct{}
+
+type credentials struct {
+	user     string
+	password string
+}
+
+// ClientID sets the client id used when connecting to the MQTT server, it must be unique for each
+// client connected to the server. Defaults to a random id.
+func ClientID(id string) broker.Option {
+	return setBrokerOption(clientIDKey{}, id)
+}
+
+// Credentials to authenticate with the MQTT server
+func Credentials(user, password string) broker.Option {
+	return setBrokerOption(credentialsKey{}, credentials{user: user, password: password})
+}
+
+// PublishQoS sets the QoS level of a published message, 0 (at most once), 1 (at least once) or
+// 2 (exactly once). Defaults to 1.
+func PublishQoS(qos byte) broker.PublishOption {
+	return func(o *broker.PublishOptions) {
+		o.Context = setContext(o.Context, qosKey{}, qos)
+	}
+}
+
+// Retained publishes a message which the MQTT server keeps and delivers to the subscribers of
+// the topic when they subscribe
+func Retained() broker.PublishOption {
+	return func(o *broker.PublishOptions) {
+		o.Context = setContext(o.Context, retainedKey{}, true)
+	}
+}
+
+// PublishRaw publishes the body of the message as the payload, rather than the encoded message,
+// for subscribers which aren't micro services. The headers of the message aren't published.
+func PublishRaw() broker.PublishOption {
+	return func(o *broker.PublishOptions) {
+		o.Context = setContext(o.Context, rawKey{}, true)
+	}
+}
+
+// SubscribeQoS sets the maximum QoS level of the messages delivered to a subscriber. Defaults
+// to 1.
+func SubscribeQoS(qos byte) broker.SubscribeOption {
+	return func(o *broker.SubscribeOptions) {
+		o.Context = setContext(o.Context, qosKey{}, qos)
+	}
+}
+
+// Raw subscribes to a topic published to by clients which aren't micro services, such as
+// devices. The payload of a message is the body of the message passed to the handler, with the
+// MQTT topic it was published to in the Micro-Topic header.
+func Raw() broker.SubscribeOption {
+	return func(o *broker.SubscribeOptions) {
+		o.Context = setContext(o.Context, rawKey{}, true)
+	}
+}
+
+// setBrokerOption returns a function to setup a context with given value
+func setBrokerOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		o.Context = setContext(o.Context, k, v)
+	}
+}
+
+func setContext(ctx context.Context, k, v interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, k, v)
+}
@@ -20,11 +20,14 @@ package nats
 import (
 	"context"
 	"errors"
+	"math/rand"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/micro/micro/v3/service/broker"
 	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/metrics"
 	nats "github.com/nats-io/nats.go"
 )
 
@@ -43,11 +46,22 @@ type natsBroker struct {
 	// should we drain the connection
 	drain   bool
 	closeCh chan (error)
+
+	// the delay between attempts to reconnect doubles from the min to the max
+	backoffMin time.Duration
+	backoffMax time.Duration
+
+	// subscribers are resubscribed when the broker connects after the connection was closed
+	subscribers map[*subscriber]bool
 }
 
 type subscriber struct {
-	s    *nats.Subscription
-	opts broker.SubscribeOptions
+	sync.Mutex
+	s      *nats.Subscription
+	topic  string
+	opts   broker.SubscribeOptions
+	fn     nats.MsgHandler
+	broker *natsBroker
 }
 
 func (s *subscriber) Options() broker.SubscribeOptions {
@@ -55,13 +69,38 @@ func (s *subscriber) Options() broker.SubscribeOptions {
 }
 
 func (s *subscriber) Topic() string {
-	return s.s.Subject
+	return s.topic
 }
 
 func (s *subscriber) Unsubscribe() error {
+	s.broker.Lock()
+	delete(s.broker.subscribers, s)
+	s.broker.Unlock()
+
+	s.Lock()
+	defer s.Unlock()
 	return s.s.Unsubscribe()
 }
 
+// subscribe to the topic on the connection
+func (s *subscriber) subscribe(conn *nats.Conn) error {
+	var sub *nats.Subscription
+	var err error
+	if len(s.opts.Queue) > 0 {
+		sub, err = conn.QueueSubscribe(s.topic, s.opts.Queue, s.fn)
+	} else {
+		sub, err = conn.Subscribe(s.topic, s.fn)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	s.s = sub
+	s.Unlock()
+	return nil
+}
+
 func (n *natsBroker) Address() string {
 	if n.conn != nil && n.conn.IsConnected() {
 		return n.conn.ConnectedUrl()
@@ -120,6 +159,31 @@ func (n *natsBroker) Connect() error {
 			opts.Secure = true
 		}
 
+		// the connection reconnects with an exponential backoff, buffering the messages published
+		// until it's reconnected and then resubscribing its subscriptions
+		if opts.CustomReconnectDelayCB == nil {
+			opts.CustomReconnectDelayCB = n.reconnectDelay
+		}
+		disconnected, reconnected, closed := opts.DisconnectedErrCB, opts.ReconnectedCB, opts.ClosedCB
+		opts.DisconnectedErrCB = func(c *nats.Conn, err error) {
+			n.onDisconnected(c, err)
+			if disconnected != nil {
+				disconnected(c, err)
+			}
+		}
+		opts.ReconnectedCB = func(c *nats.Conn) {
+			n.onReconnected(c)
+			if reconnected != nil {
+				reconnected(c)
+			}
+		}
+		opts.ClosedCB = func(c *nats.Conn) {
+			reportConnected(false)
+			if closed != nil {
+				closed(c)
+			}
+		}
+
 		c, err := opts.Connect()
 		if err != nil {
 			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
@@ -130,10 +194,61 @@ func (n *natsBroker) Connect() error {
 		}
 		n.conn = c
 		n.connected = true
+		reportConnected(true)
+
+		// the subscriptions of a closed connection are lost so they're made on the new one
+		for s := range n.subscribers {
+			if err := s.subscribe(c); err != nil {
+				logger.Errorf("Error resubscribing to %v: %v", s.topic, err)
+			}
+		}
 		return nil
 	}
 }
 
+// reconnectDelay returns the delay before attempting to reconnect once every server has been
+// tried, doubling from the min to the max delay with each attempt
+func (n *natsBroker) reconnectDelay(attempts int) time.Duration {
+	d := n.backoffMin
+	for i := 1; i < attempts && d < n.backoffMax; i++ {
+		d *= 2
+	}
+	if d > n.backoffMax {
+		d = n.backoffMax
+	}
+	// jitter the delay so the clients of a server don't reconnect at the same time
+	return d + time.Duration(rand.Int63n(int64(d)/10+1))
+}
+
+func (n *natsBroker) onDisconnected(c *nats.Conn, err error) {
+	reportConnected(false)
+	if err != nil {
+		logger.Warnf("Disconnected from broker, reconnecting: %v", err)
+	} else {
+		logger.Warnf("Disconnected from broker, reconnecting")
+	}
+}
+
+func (n *natsBroker) onReconnected(c *nats.Conn) {
+	reportConnected(true)
+	if metrics.DefaultMetricsReporter != nil {
+		metrics.Count("broker.reconnects", 1, metrics.Tags{"broker": "nats"})
+	}
+	logger.Infof("Reconnected to broker %v", c.ConnectedUrl())
+}
+
+// reportConnected reports the state of the connection with the broker.connected gauge
+func reportConnected(connected bool) {
+	if metrics.DefaultMetricsReporter == nil {
+		return
+	}
+	var v float64
+	if connected {
+		v = 1
+	}
+	metrics.Gauge("broker.connected", v, metrics.Tags{"broker": "nats"})
+}
+
 func (n *natsBroker) Disconnect() error {
 	n.Lock()
 	defer n.Unlock()
@@ -170,11 +285,25 @@ func (n *natsBroker) Publish(topic string, msg *broker.Message, opts ...broker.P
 		return errors.New("not connected")
 	}
 
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	msg, err := broker.PrepareMessage(msg, n.opts, options)
+	if err != nil {
+		return err
+	}
+
 	b, err := n.opts.Codec.Marshal(msg)
 	if err != nil {
 		return err
 	}
-	return n.conn.Publish(topic, b)
+	// messages published while reconnecting are buffered up to the publish buffer size
+	err = n.conn.Publish(topic, b)
+	if err == nats.ErrReconnectBufExceeded {
+		logger.Warnf("Publish buffer full while reconnecting to broker, dropping message to %v", topic)
+	}
+	return err
 }
 
 func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
@@ -207,6 +336,15 @@ func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...bro
 			}
 			return
 		}
+		if m, err = broker.DecompressMessage(m); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Error(err)
+			}
+			if eh != nil {
+				eh(&broker.Message{Body: msg.Data}, err)
+			}
+			return
+		}
 		if err := handler(m); err != nil {
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 				logger.Error(err)
@@ -217,20 +355,15 @@ func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...bro
 		}
 	}
 
-	var sub *nats.Subscription
-	var err error
+	s := &subscriber{topic: topic, opts: opt, fn: fn, broker: n}
 
-	n.RLock()
-	if len(opt.Queue) > 0 {
-		sub, err = n.conn.QueueSubscribe(topic, opt.Queue, fn)
-	} else {
-		sub, err = n.conn.Subscribe(topic, fn)
-	}
-	n.RUnlock()
-	if err != nil {
+	n.Lock()
+	defer n.Unlock()
+	if err := s.subscribe(n.conn); err != nil {
 		return nil, err
 	}
-	return &subscriber{s: sub, opts: opt}, nil
+	n.subscribers[s] = true
+	return s, nil
 }
 
 func (n *natsBroker) String() string {
@@ -244,6 +377,8 @@ func (n *natsBroker) setOption(opts ...broker.Option) {
 
 	n.Once.Do(func() {
 		n.nopts = nats.GetDefaultOptions()
+		// reconnect until the broker is disconnected
+		n.nopts.MaxReconnect = -1
 	})
 
 	if nopts, ok := n.opts.Context.Value(optionsKey{}).(nats.Options); ok {
@@ -266,6 +401,13 @@ func (n *natsBroker) setOption(opts ...broker.Option) {
 	}
 	n.addrs = n.setAddrs(n.opts.Addrs)
 
+	if b, ok := n.opts.Context.Value(reconnectBackoffKey{}).(reconnectBackoff); ok {
+		n.backoffMin, n.backoffMax = b.min, b.max
+	}
+	if size, ok := n.opts.Context.Value(publishBufferSizeKey{}).(int); ok {
+		n.nopts.ReconnectBufSize = size
+	}
+
 	if n.opts.Context.Value(drainConnectionKey{}) != nil {
 		n.drain = true
 		n.closeCh = make(chan error)
@@ -294,12 +436,15 @@ func (n *natsBroker) onDisconnectedError(conn *nats.Conn, err error) {
 func NewBroker(opts ...broker.Option) broker.Broker {
 	options := broker.Options{
 		// Default codec
-		Codec:    Marshaler{},
-		Context:  context.Background(),
+		Codec:   Marshaler{},
+		Context: context.Background(),
 	}
 
 	n := &natsBroker{
-		opts: options,
+		opts:        options,
+		backoffMin:  DefaultReconnectBackoffMin,
+		backoffMax:  DefaultReconnectBackoffMax,
+		subscribers: make(map[*subscriber]bool),
 	}
 	n.setOption(opts...)
 
@@ -17,12 +17,28 @@
 package nats
 
 import (
+	"time"
+
 	"github.com/micro/micro/v3/service/broker"
 	nats "github.com/nats-io/nats.go"
 )
 
+var (
+	// DefaultReconnectBackoffMin is the delay before reconnecting once every server has been tried
+	DefaultReconnectBackoffMin = 250 * time.Millisecond
+	// DefaultReconnectBackoffMax is the maximum delay between attempts to reconnect
+	DefaultReconnectBackoffMax = 30 * time.Second
+)
+
 type optionsKey struct{}
 type drainConnectionKey struct{}
+type reconnectBackoffKey struct{}
+type publishBufferSizeKey struct{}
+
+type reconnectBackoff struct {
+	min time.Duration
+	max time.Duration
+}
 
 // Options accepts nats.Options
 func Options(opts nats.Options) broker.Option {
@@ -33,3 +49,15 @@ func Options(opts nats.Options) broker.Option {
 func DrainConnection() broker.Option {
 	return setBrokerOption(drainConnectionKey{}, struct{}{})
 }
+
+// ReconnectBackoff sets the delay before reconnecting once every server has been tried, which
+// doubles with each attempt from min up to max
+func ReconnectBackoff(min, max time.Duration) broker.Option {
+	return setBrokerOption(reconnectBackoffKey{}, reconnectBackoff{min: min, max: max})
+}
+
+// PublishBufferSize sets the size in bytes of the messages buffered while reconnecting, messages
+// published once the buffer is full return an error. Defaults to 8MB.
+func PublishBufferSize(size int) broker.Option {
+	return setBrokerOption(publishBufferSizeKey{}, size)
+}
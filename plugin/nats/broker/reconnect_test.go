@@ -0,0 +1,91 @@
+package nats
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/nats-io/nats-server/v2/server"
+	nats "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	n := NewBroker(ReconnectBackoff(100*time.Millisecond, time.Second)).(*natsBroker)
+	for _, tc := range []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	} {
+		d := n.reconnectDelay(tc.attempts)
+		// the delay is jittered by up to a tenth
+		assert.GreaterOrEqual(t, int64(d), int64(tc.delay))
+		assert.LessOrEqual(t, int64(d), int64(tc.delay+tc.delay/10))
+	}
+}
+
+func runServer(t *testing.T, port int) *server.Server {
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: port, NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("Server not ready")
+	}
+	return s
+}
+
+func TestReconnect(t *testing.T) {
+	srv := runServer(t, -1)
+	port := srv.Addr().(*net.TCPAddr).Port
+	defer func() { srv.Shutdown() }()
+
+	b := NewBroker(broker.Addrs(fmt.Sprintf("127.0.0.1:%d", port)), ReconnectBackoff(10*time.Millisecond, 50*time.Millisecond))
+	assert.NoError(t, b.Connect())
+	defer b.Disconnect()
+
+	ch := make(chan string, 10)
+	sub, err := b.Subscribe("foo", func(m *broker.Message) error {
+		ch <- string(m.Body)
+		return nil
+	})
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	receive := func() string {
+		select {
+		case m := <-ch:
+			return m
+		case <-time.After(5 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return ""
+		}
+	}
+
+	// messages published while reconnecting are buffered and the subscriptions are resubscribed
+	t.Run("Buffer", func(t *testing.T) {
+		srv.Shutdown()
+		conn := b.(*natsBroker).conn
+		assert.Eventually(t, func() bool { return conn.Status() == nats.RECONNECTING }, 5*time.Second, 10*time.Millisecond)
+		assert.NoError(t, b.Publish("foo", &broker.Message{Body: []byte("buffered")}))
+
+		srv = runServer(t, port)
+		assert.Equal(t, "buffered", receive())
+	})
+
+	// the subscriptions are made again when the broker connects after disconnecting
+	t.Run("Resubscribe", func(t *testing.T) {
+		assert.NoError(t, b.Disconnect())
+		assert.NoError(t, b.Connect())
+		assert.NoError(t, b.Publish("foo", &broker.Message{Body: []byte("resubscribed")}))
+		assert.Equal(t, "resubscribed", receive())
+	})
+}
@@ -0,0 +1,14 @@
+module github.com/micro/micro/plugin/nats/jetstream/v3
+
+go 1.15
+
+require (
+	github.com/google/uuid v1.1.2
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/nats-io/nats-server/v2 v2.10.4
+	github.com/nats-io/nats.go v1.31.0
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.7.1
+)
+
+replace github.com/micro/micro/v3 => ../../..
@@ -0,0 +1,405 @@
+// Package jetstream implements the micro events stream with NATS JetStream
+package jetstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultStream = "events"
+	// defaultAckWait is the ack wait of jetstream consumers which don't set one
+	defaultAckWait = 30 * time.Second
+	// maxBackoffs is the number of deliveries the backoff is calculated for, the deliveries after
+	// them are delayed by the last backoff
+	maxBackoffs = 10
+)
+
+// consumer names may only contain letters, numbers, dashes and underscores
+var invalidName = regexp.MustCompile("[^a-zA-Z0-9_-]+")
+
+// NewStream returns an initialized jetstream stream or an error if the connection to the nats
+// server could not be established. The JetStream stream the events are written to is created if
+// it doesn't exist.
+func NewStream(opts ...Option) (events.Stream, error) {
+	// parse the options
+	options := Options{
+		Stream:   defaultStream,
+		Replicas: 1,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	nopts := nats.GetDefaultOptions()
+	nopts.MaxReconnect = -1
+	if options.TLSConfig != nil {
+		nopts.Secure = true
+		nopts.TLSConfig = options.TLSConfig
+	}
+	if len(options.Address) > 0 {
+		nopts.Servers = []string{options.Address}
+	}
+	conn, err := nopts.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats at %v with tls enabled (%v): %v", options.Address, nopts.TLSConfig != nil, err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "Error connecting to jetstream")
+	}
+
+	// create the stream if it doesn't exist
+	_, err = js.StreamInfo(options.Stream)
+	if err == nats.ErrStreamNotFound {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:     options.Stream,
+			Subjects: []string{options.Stream + ".>"},
+			Storage:  nats.FileStorage,
+			Replicas: options.Replicas,
+			MaxAge:   options.MaxAge,
+		})
+	}
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "Error creating stream")
+	}
+
+	return &stream{opts: options, conn: conn, js: js}, nil
+}
+
+type stream struct {
+	opts Options
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+// subject returns the subject of the topic in the stream which the events with the key are
+// published to. The partition of the event is the first token after the name of the stream if the
+// subjects are sharded.
+func (s *stream) subject(topic, key string) string {
+	if s.opts.Partitions <= 0 {
+		return s.opts.Stream + "." + topic
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("%s.%d.%s", s.opts.Stream, h.Sum32()%uint32(s.opts.Partitions), topic)
+}
+
+// filter returns the subject matching the events of the topic in every partition
+func (s *stream) filter(topic string) string {
+	if s.opts.Partitions <= 0 {
+		return s.opts.Stream + "." + topic
+	}
+	return s.opts.Stream + ".*." + topic
+}
+
+// partition returns the partition of the subject, or zero if the subjects aren't sharded
+func (s *stream) partition(subject string) int {
+	if s.opts.Partitions <= 0 {
+		return 0
+	}
+	token := strings.SplitN(strings.TrimPrefix(subject, s.opts.Stream+"."), ".", 2)[0]
+	p, _ := strconv.Atoi(token)
+	return p
+}
+
+// Publish a message to a topic
+func (s *stream) Publish(topic string, msg interface{}, opts ...events.PublishOption) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	// parse the options
+	options := events.PublishOptions{
+		Timestamp: time.Now(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// encode the message if it's not already encoded
+	var payload []byte
+	if p, ok := msg.([]byte); ok {
+		payload = p
+	} else {
+		p, err := json.Marshal(msg)
+		if err != nil {
+			return events.ErrEncodingMessage
+		}
+		payload = p
+	}
+
+	// construct the event
+	event := &events.Event{
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Timestamp:   options.Timestamp,
+		Metadata:    options.Metadata,
+		Payload:     payload,
+		OrderingKey: options.OrderingKey,
+	}
+
+	// serialize the event to bytes
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding event")
+	}
+
+	// publish the event, the message id lets jetstream discard duplicates within its duplicate
+	// window, so the idempotency key is used if there is one. The stream is shared by the topics
+	// so the key is scoped to the topic.
+	msgID := event.ID
+	if len(options.IdempotencyKey) > 0 {
+		msgID = topic + ":" + options.IdempotencyKey
+	}
+	// the events without an ordering key are spread over the partitions
+	key := event.ID
+	if len(options.OrderingKey) > 0 {
+		key = options.OrderingKey
+	}
+	if _, err := s.js.Publish(s.subject(topic, key), bytes, nats.MsgId(msgID)); err != nil {
+		return errors.Wrap(err, "Error publishing message to topic")
+	}
+
+	return nil
+}
+
+// Consume a topic. Consumers with a group share a durable consumer, which resumes from the last
+// event acknowledged by the group. Consumers without a group have an ephemeral consumer.
+func (s *stream) Consume(topic string, opts ...events.ConsumeOption) (<-chan events.Event, error) {
+	// validate the topic
+	if len(topic) == 0 {
+		return nil, events.ErrMissingTopic
+	}
+
+	// parse the options
+	options := events.ConsumeOptions{
+		AutoAck: true,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// setup the subscriber
+	c := make(chan events.Event)
+	handleMsg := func(m *nats.Msg) {
+		// decode the message
+		var evt events.Event
+		if err := json.Unmarshal(m.Data, &evt); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error decoding message: %v", err)
+			}
+			// the message can't be decoded so there's no point redelivering it
+			m.Term()
+			return
+		}
+		md, err := m.Metadata()
+		if err == nil {
+			evt.Sequence = md.Sequence.Stream
+		}
+		evt.Partition = s.partition(m.Subject)
+
+		// messages with a dead-letter topic are redelivered until they're dead-lettered here, rather
+		// than jetstream dropping them once they reach the max deliveries
+		if limit := options.GetRetryLimit(); md != nil && limit > -1 && len(options.DeadLetterTopic) > 0 && md.NumDelivered > uint64(limit+1) {
+			if err := events.DeadLetter(s, options.DeadLetterTopic, evt); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Error publishing message %v to dead-letter topic: %v", evt.ID, err)
+				}
+				m.Nak()
+				return
+			}
+			m.Ack()
+			return
+		}
+
+		if !options.AutoAck {
+			// set up the ack funcs
+			evt.SetAckFunc(func() error {
+				return m.Ack()
+			})
+			evt.SetNackFunc(func() error {
+				if md != nil && options.BackoffMin > 0 {
+					return m.NakWithDelay(options.RedeliveryDelay(int(md.NumDelivered)))
+				}
+				return m.Nak()
+			})
+		}
+
+		// push onto the channel and wait for the consumer to take the event off before we acknowledge it.
+		if options.Context != nil {
+			select {
+			case c <- evt:
+			case <-options.Context.Done():
+				return
+			}
+		} else {
+			c <- evt
+		}
+
+		if !options.AutoAck {
+			return
+		}
+		if err := m.Ack(); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error acknowledging message: %v", err)
+		}
+	}
+
+	var sub *nats.Subscription
+	var err error
+	if len(options.Group) == 0 {
+		subOpts := []nats.SubOpt{nats.BindStream(s.opts.Stream), nats.ManualAck()}
+		switch {
+		case options.Sequence > 0:
+			subOpts = append(subOpts, nats.StartSequence(options.Sequence))
+		case !options.Offset.IsZero():
+			subOpts = append(subOpts, nats.StartTime(options.Offset))
+		default:
+			subOpts = append(subOpts, nats.DeliverNew())
+		}
+		if options.AckWait > 0 {
+			subOpts = append(subOpts, nats.AckWait(options.AckWait))
+		}
+		if n := maxDeliver(options); n > 0 {
+			subOpts = append(subOpts, nats.MaxDeliver(n))
+		}
+		if b := backoff(options); len(b) > 0 {
+			subOpts = append(subOpts, nats.BackOff(b))
+		}
+		sub, err = s.js.Subscribe(s.filter(topic), handleMsg, subOpts...)
+	} else {
+		var name string
+		if name, err = s.durable(topic, options); err == nil {
+			sub, err = s.js.QueueSubscribe(s.filter(topic), name, handleMsg, nats.Bind(s.opts.Stream, name), nats.ManualAck())
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error subscribing to topic")
+	}
+
+	// unsubscribing from a durable consumer leaves it in place for the rest of the group
+	if options.Context != nil {
+		go func() {
+			<-options.Context.Done()
+			sub.Unsubscribe()
+		}()
+	}
+
+	return c, nil
+}
+
+// durable returns the name of the durable consumer of the group, creating it if it doesn't exist.
+// The start, ack wait and retry limit of the group are set by the first consumer to join it.
+func (s *stream) durable(topic string, options events.ConsumeOptions) (string, error) {
+	name := invalidName.ReplaceAllString(options.Group+"_"+topic, "_")
+	if _, err := s.js.ConsumerInfo(s.opts.Stream, name); err == nil {
+		return name, nil
+	} else if err != nats.ErrConsumerNotFound {
+		return "", err
+	}
+
+	config := &nats.ConsumerConfig{
+		Durable:        name,
+		Description:    options.Group,
+		DeliverSubject: fmt.Sprintf("_deliver.%s.%s", s.opts.Stream, name),
+		DeliverGroup:   name,
+		FilterSubject:  s.filter(topic),
+		AckPolicy:      nats.AckExplicitPolicy,
+		AckWait:        options.AckWait,
+		DeliverPolicy:  nats.DeliverNewPolicy,
+	}
+	switch {
+	case options.Sequence > 0:
+		config.DeliverPolicy = nats.DeliverByStartSequencePolicy
+		config.OptStartSeq = options.Sequence
+	case !options.Offset.IsZero():
+		config.DeliverPolicy = nats.DeliverByStartTimePolicy
+		config.OptStartTime = &options.Offset
+	}
+	if n := maxDeliver(options); n > 0 {
+		config.MaxDeliver = n
+	}
+	config.BackOff = backoff(options)
+
+	// consumers of the group may create it at the same time, which succeeds as the config is the same
+	if _, err := s.js.AddConsumer(s.opts.Stream, config); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Status returns the status of the consumers of the topic, or of every topic if it's blank. The
+// number of members of a group isn't known, so the consumers of the groups are zero.
+func (s *stream) Status(topic string) ([]events.GroupStatus, error) {
+	var result []events.GroupStatus
+	for info := range s.js.Consumers(s.opts.Stream) {
+		t := strings.TrimPrefix(info.Config.FilterSubject, s.opts.Stream+".")
+		if s.opts.Partitions > 0 {
+			t = strings.TrimPrefix(t, "*.")
+		}
+		if len(topic) > 0 && t != topic {
+			continue
+		}
+
+		// the description of a durable consumer is its group, consumers without a group are ephemeral
+		group := info.Config.Description
+		if len(group) == 0 {
+			group = info.Config.Durable
+		}
+		result = append(result, events.GroupStatus{
+			Topic:   t,
+			Group:   group,
+			Pending: info.NumPending,
+			Unacked: uint64(info.NumAckPending),
+		})
+	}
+	return result, nil
+}
+
+// maxDeliver returns the number of times jetstream should deliver a message, or zero if there
+// is no limit. Consumers with a dead-letter topic dead-letter the message themselves.
+func maxDeliver(options events.ConsumeOptions) int {
+	if limit := options.GetRetryLimit(); limit > -1 && len(options.DeadLetterTopic) == 0 {
+		return limit + 1
+	}
+	return 0
+}
+
+// backoff returns the ack wait of each delivery of an event, which is the ack wait plus the backoff
+// before the redelivery. JetStream only supports it for consumers with a max deliveries, so the
+// events of the other consumers are only delayed when they're nacked.
+func backoff(options events.ConsumeOptions) []time.Duration {
+	n := maxDeliver(options) - 1
+	if options.BackoffMin <= 0 || n < 1 {
+		return nil
+	}
+	if n > maxBackoffs {
+		n = maxBackoffs
+	}
+	ackWait := options.AckWait
+	if ackWait <= 0 {
+		ackWait = defaultAckWait
+	}
+
+	// the last ack wait is used for the deliveries after them
+	waits := make([]time.Duration, n)
+	for i := range waits {
+		waits[i] = ackWait + options.RedeliveryDelay(i+1)
+	}
+	return waits
+}
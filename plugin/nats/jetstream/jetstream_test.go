@@ -0,0 +1,328 @@
+package jetstream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+)
+
+type testObj struct {
+	One string
+	Two int64
+}
+
+// runServer starts an embedded nats server with jetstream enabled
+func runServer(t *testing.T) string {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(10 * time.Second) {
+		t.Fatalf("Server failed to start")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv.ClientURL()
+}
+
+func TestStream(t *testing.T) {
+	s, err := NewStream(Address(runServer(t)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	receive := func(ch <-chan events.Event) (events.Event, bool) {
+		select {
+		case ev := <-ch:
+			return ev, true
+		case <-time.After(5 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return events.Event{}, false
+		}
+	}
+
+	t.Run("MissingTopic", func(t *testing.T) {
+		assert.Equal(t, events.ErrMissingTopic, s.Publish("", nil))
+		_, err := s.Consume("")
+		assert.Equal(t, events.ErrMissingTopic, err)
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic, events.WithContext(ctx))
+		assert.NoError(t, err)
+
+		tobj := testObj{One: "foo", Two: 12345}
+		assert.NoError(t, s.Publish(topic, tobj, events.WithMetadata(map[string]string{"meta": "bar"})))
+
+		ev, ok := receive(ch)
+		if !ok {
+			return
+		}
+		assert.NotEmpty(t, ev.ID)
+		assert.NotZero(t, ev.Sequence)
+		assert.Equal(t, topic, ev.Topic)
+		assert.Equal(t, "bar", ev.Metadata["meta"])
+		var tes testObj
+		assert.NoError(t, json.Unmarshal(ev.Payload, &tes))
+		assert.Equal(t, tobj, tes)
+	})
+
+	t.Run("IdempotencyKey", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic, events.WithContext(ctx))
+		assert.NoError(t, err)
+
+		// the retried publish is discarded
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, s.Publish(topic, testObj{One: "once"}, events.WithIdempotencyKey("key")))
+		}
+		if _, ok := receive(ch); !ok {
+			return
+		}
+		select {
+		case <-ch:
+			t.Errorf("Expected the event to be delivered once")
+		case <-time.After(time.Second):
+		}
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic, events.WithGroup("status"), events.WithAutoAck(false, time.Minute), events.WithContext(ctx))
+		assert.NoError(t, err)
+		assert.NoError(t, s.Publish(topic, testObj{One: "one"}))
+		assert.NoError(t, s.Publish(topic, testObj{One: "two"}))
+		if _, ok := receive(ch); !ok {
+			return
+		}
+
+		// both events are delivered to the consumer, which hasn't acknowledged them
+		assert.Eventually(t, func() bool {
+			groups, err := s.(events.Statuser).Status(topic)
+			return err == nil && assert.ObjectsAreEqual([]events.GroupStatus{
+				{Topic: topic, Group: "status", Unacked: 2},
+			}, groups)
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch1, err := s.Consume(topic, events.WithGroup("group"), events.WithContext(ctx))
+		assert.NoError(t, err)
+		ch2, err := s.Consume(topic, events.WithGroup("group"), events.WithContext(ctx))
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.Publish(topic, testObj{One: "group"}))
+
+		// the event is only delivered to one consumer of the group
+		select {
+		case <-ch1:
+		case <-ch2:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Failed to receive message within the time limit")
+		}
+		select {
+		case <-ch1:
+			t.Errorf("Expected the event to be delivered once")
+		case <-ch2:
+			t.Errorf("Expected the event to be delivered once")
+		case <-time.After(time.Second):
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		assert.NoError(t, s.Publish(topic, testObj{One: "first"}))
+		time.Sleep(100 * time.Millisecond)
+		start := time.Now()
+		assert.NoError(t, s.Publish(topic, testObj{One: "second"}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// replay from the offset
+		ch, err := s.Consume(topic, events.WithOffset(start), events.WithContext(ctx))
+		assert.NoError(t, err)
+		ev, ok := receive(ch)
+		if !ok {
+			return
+		}
+		var tes testObj
+		assert.NoError(t, ev.Unmarshal(&tes))
+		assert.Equal(t, "second", tes.One)
+
+		// replay from the sequence of the second event, which is the one before it in the stream
+		ch, err = s.Consume(topic, events.WithSequence(ev.Sequence-1), events.WithGroup("replay"), events.WithContext(ctx))
+		assert.NoError(t, err)
+		ev, ok = receive(ch)
+		if !ok {
+			return
+		}
+		assert.NoError(t, ev.Unmarshal(&tes))
+		assert.Equal(t, "first", tes.One)
+	})
+
+	t.Run("Nack", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic,
+			events.WithAutoAck(false, time.Minute),
+			events.WithRetryLimit(1),
+			events.WithGroup("nack"),
+			events.WithContext(ctx),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Publish(topic, testObj{One: "retry"}))
+
+		// the event is redelivered once before it's discarded
+		for i := 0; i < 2; i++ {
+			ev, ok := receive(ch)
+			if !ok {
+				return
+			}
+			assert.NoError(t, ev.Nack())
+		}
+		select {
+		case <-ch:
+			t.Errorf("Expected the event to be discarded")
+		case <-time.After(time.Second):
+		}
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic,
+			events.WithAutoAck(false, 100*time.Millisecond),
+			events.WithRetryLimit(2),
+			events.WithBackoff(500*time.Millisecond, time.Second),
+			events.WithGroup("backoff"),
+			events.WithContext(ctx),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Publish(topic, testObj{One: "backoff"}))
+		if _, ok := receive(ch); !ok {
+			return
+		}
+
+		// the event isn't acked so it's redelivered after the ack wait and the backoff
+		start := time.Now()
+		ev, ok := receive(ch)
+		if !ok {
+			return
+		}
+		assert.True(t, time.Since(start) >= 600*time.Millisecond, "Redelivered after %v", time.Since(start))
+
+		// the backoff doubles with each delivery
+		start = time.Now()
+		assert.NoError(t, ev.Nack())
+		if _, ok := receive(ch); !ok {
+			return
+		}
+		assert.True(t, time.Since(start) >= time.Second, "Redelivered after %v", time.Since(start))
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dlq, err := s.Consume(topic+"-dlq", events.WithContext(ctx))
+		assert.NoError(t, err)
+		ch, err := s.Consume(topic,
+			events.WithAutoAck(false, time.Minute),
+			events.WithRetryLimit(1),
+			events.WithDeadLetter(topic+"-dlq"),
+			events.WithGroup("dlq"),
+			events.WithContext(ctx),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Publish(topic, testObj{One: "dead"}))
+
+		// the event is published to the dead-letter topic once it's been retried
+		for i := 0; i < 2; i++ {
+			ev, ok := receive(ch)
+			if !ok {
+				return
+			}
+			assert.NoError(t, ev.Nack())
+		}
+		ev, ok := receive(dlq)
+		if !ok {
+			return
+		}
+		assert.Equal(t, topic, ev.Metadata[events.DeadLetterTopicKey])
+		var tes testObj
+		assert.NoError(t, ev.Unmarshal(&tes))
+		assert.Equal(t, "dead", tes.One)
+	})
+}
+
+func TestPartitions(t *testing.T) {
+	s, err := NewStream(Address(runServer(t)), Partitions(4))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	topic := "test-" + uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := s.Consume(topic, events.WithGroup("partitions"), events.WithContext(ctx))
+	assert.NoError(t, err)
+
+	// events of other topics which share the prefix aren't consumed
+	assert.NoError(t, s.Publish(topic+".other", testObj{One: "other"}))
+	for i := 0; i < 5; i++ {
+		for _, key := range []string{"a", "b"} {
+			assert.NoError(t, s.Publish(topic, testObj{One: key, Two: int64(i)}, events.WithOrderingKey(key)))
+		}
+	}
+
+	// the events with the same key are in the same partition, in the order they were published
+	next := map[string]int64{}
+	partitions := map[string]int{}
+	for i := 0; i < 10; i++ {
+		var ev events.Event
+		select {
+		case ev = <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Failed to receive message within the time limit")
+		}
+		var tes testObj
+		assert.NoError(t, ev.Unmarshal(&tes))
+		assert.Equal(t, tes.One, ev.OrderingKey)
+		assert.Equal(t, next[tes.One], tes.Two)
+		next[tes.One]++
+		if p, ok := partitions[tes.One]; ok {
+			assert.Equal(t, p, ev.Partition)
+		}
+		partitions[tes.One] = ev.Partition
+		assert.True(t, ev.Partition >= 0 && ev.Partition < 4)
+	}
+	select {
+	case <-ch:
+		t.Errorf("Expected only the events of the topic")
+	case <-time.After(time.Second):
+	}
+}
@@ -0,0 +1,68 @@
+package jetstream
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Options which are used to configure the jetstream stream
+type Options struct {
+	Address   string
+	TLSConfig *tls.Config
+	// Stream is the name of the JetStream stream the events are written to, its subjects are
+	// the topics prefixed with the name
+	Stream string
+	// Replicas and MaxAge of the stream, if it's created by the events stream
+	Replicas int
+	MaxAge   time.Duration
+	// Partitions the subjects of the topics are sharded into, by the ordering key of the events.
+	// The subjects aren't sharded if it's zero.
+	Partitions int
+}
+
+// Option is a function which configures options
+type Option func(o *Options)
+
+// Address of the nats cluster
+func Address(addr string) Option {
+	return func(o *Options) {
+		o.Address = addr
+	}
+}
+
+// TLSConfig to use when connecting to the cluster
+func TLSConfig(t *tls.Config) Option {
+	return func(o *Options) {
+		o.TLSConfig = t
+	}
+}
+
+// Stream sets the name of the JetStream stream, defaults to events
+func Stream(name string) Option {
+	return func(o *Options) {
+		o.Stream = name
+	}
+}
+
+// Replicas sets the number of replicas of the stream, defaults to 1
+func Replicas(n int) Option {
+	return func(o *Options) {
+		o.Replicas = n
+	}
+}
+
+// MaxAge sets how long events are kept in the stream, they're kept indefinitely by default
+func MaxAge(d time.Duration) Option {
+	return func(o *Options) {
+		o.MaxAge = d
+	}
+}
+
+// Partitions sets the number of partitions the subjects of the topics are sharded into, so the
+// events with the same ordering key are published to the same subject. The subjects of an existing
+// stream must be sharded the same way.
+func Partitions(n int) Option {
+	return func(o *Options) {
+		o.Partitions = n
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/micro/micro/plugin/nats/store/v3
+
+go 1.15
+
+require (
+	github.com/micro/micro/v3 v3.2.2-0.20210607125937-d152c4ec7749
+	github.com/nats-io/nats-server/v2 v2.10.4
+	github.com/nats-io/nats.go v1.31.0
+	github.com/pkg/errors v0.9.1
+)
+
+replace github.com/micro/micro/v3 => ../../..
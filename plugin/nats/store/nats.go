@@ -0,0 +1,643 @@
+// Package store implements a store on NATS JetStream key value buckets
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base32"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultDatabase is the namespace that the nats store
+	// will use if no namespace is provided.
+	DefaultDatabase = "micro"
+	// DefaultTable when none is specified
+	DefaultTable = "micro"
+
+	// ErrNoConnection is returned when the store has not connected to nats
+	ErrNoConnection = errors.New("nats connection not initialised")
+
+	// bucket names may only contain letters, numbers, dashes and underscores
+	re = regexp.MustCompile("[^a-zA-Z0-9_-]+")
+
+	// keys are restricted to a small set of characters so they're encoded. The
+	// base32 hex alphabet is used as it preserves the sort order of the keys.
+	encoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+)
+
+// NewStore returns a new micro Store backed by NATS JetStream
+func NewStore(opts ...store.Option) store.Store {
+	s := &natsStore{
+		options: store.Options{
+			Database: DefaultDatabase,
+			Table:    DefaultTable,
+		},
+		buckets: make(map[string]nats.KeyValue),
+	}
+
+	// best-effort configure the store
+	if err := s.Init(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return s
+}
+
+type natsStore struct {
+	options store.Options
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+
+	sync.RWMutex
+	buckets map[string]nats.KeyValue
+}
+
+// record stored by us
+type record struct {
+	Key       string                 `json:"key"`
+	Value     []byte                 `json:"value"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	ExpiresAt time.Time              `json:"expires_at,omitempty"`
+}
+
+func (n *natsStore) configure() error {
+	nopts := nats.GetDefaultOptions()
+	if len(n.options.Nodes) > 0 {
+		nopts.Servers = n.options.Nodes
+	}
+
+	if n.options.Context != nil {
+		if c, ok := n.options.Context.Value(tlsConfigKey{}).(*tls.Config); ok {
+			nopts.Secure = true
+			nopts.TLSConfig = c
+		}
+	}
+
+	conn, err := nopts.Connect()
+	if err != nil {
+		return err
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	n.Lock()
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	n.conn = conn
+	n.js = js
+	n.buckets = make(map[string]nats.KeyValue)
+	n.Unlock()
+
+	return nil
+}
+
+// bucket returns the key value bucket for the database and table, creating it if it doesn't exist
+func (n *natsStore) bucket(database, table string) (nats.KeyValue, error) {
+	if len(database) == 0 {
+		database = n.options.Database
+	}
+	if len(table) == 0 {
+		table = n.options.Table
+	}
+
+	name := re.ReplaceAllString(database+"_"+table, "_")
+
+	n.RLock()
+	js, kv := n.js, n.buckets[name]
+	n.RUnlock()
+
+	if js == nil {
+		return nil, ErrNoConnection
+	}
+	if kv != nil {
+		return kv, nil
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	if kv := n.buckets[name]; kv != nil {
+		return kv, nil
+	}
+
+	kv, err := js.KeyValue(name)
+	if err == nats.ErrBucketNotFound {
+		cfg := &nats.KeyValueConfig{Bucket: name}
+		if ctx := n.options.Context; ctx != nil {
+			if r, ok := ctx.Value(replicasKey{}).(int); ok {
+				cfg.Replicas = r
+			}
+			if d, ok := ctx.Value(maxAgeKey{}).(time.Duration); ok {
+				cfg.TTL = d
+			}
+		}
+		kv, err = js.CreateKeyValue(cfg)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't create bucket "+name)
+	}
+
+	n.buckets[name] = kv
+	return kv, nil
+}
+
+func decode(entry nats.KeyValueEntry) (*store.Record, error) {
+	var r record
+	if err := json.Unmarshal(entry.Value(), &r); err != nil {
+		return nil, err
+	}
+
+	if !r.ExpiresAt.IsZero() && r.ExpiresAt.Before(time.Now()) {
+		return nil, nil
+	}
+
+	newRecord := &store.Record{
+		Key:      r.Key,
+		Value:    r.Value,
+		Metadata: make(map[string]interface{}),
+		// the revision of the entry in the bucket
+		Version: entry.Revision(),
+	}
+	for k, v := range r.Metadata {
+		newRecord.Metadata[k] = v
+	}
+	if !r.ExpiresAt.IsZero() {
+		newRecord.Expiry = time.Until(r.ExpiresAt)
+	}
+
+	return newRecord, nil
+}
+
+// scan returns all the live records in a bucket which match the prefix and suffix. Keys are
+// encoded so the filtering can't be done by the server, a watch is used to fetch all the latest
+// values in a single request rather than a request per key.
+func (n *natsStore) scan(kv nats.KeyValue, prefix, suffix string, order store.Order, limit, offset uint) ([]*store.Record, error) {
+	w, err := kv.WatchAll(nats.IgnoreDeletes())
+	if err != nil {
+		return nil, err
+	}
+	defer w.Stop()
+
+	var records []*store.Record
+
+	// the initial values are followed by a nil entry
+	for entry := range w.Updates() {
+		if entry == nil {
+			break
+		}
+
+		r, err := decode(entry)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			// expired, remove it
+			kv.Delete(entry.Key())
+			continue
+		}
+		if !strings.HasPrefix(r.Key, prefix) || !strings.HasSuffix(r.Key, suffix) {
+			continue
+		}
+
+		records = append(records, r)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if order == store.OrderDesc {
+			return records[i].Key > records[j].Key
+		}
+		return records[i].Key < records[j].Key
+	})
+
+	if offset > 0 {
+		// offset is greater than the records we have
+		if int(offset) >= len(records) {
+			return nil, nil
+		}
+
+		records = records[offset:]
+	}
+
+	if limit > 0 && int(limit) < len(records) {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
+
+func (n *natsStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&n.options)
+	}
+	return n.configure()
+}
+
+func (n *natsStore) Options() store.Options {
+	return n.options
+}
+
+func (n *natsStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	options := store.ReadOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// earlier revisions of records aren't kept
+	if options.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys aren't paged through with a cursor
+	if options.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys matched against a pattern are read like a prefix
+	if options.Match != nil && !options.Suffix {
+		options.Prefix = true
+	}
+
+	if len(options.Where) > 0 || options.OrderBy == store.OrderByExpiry || options.Match != nil {
+		return n.where(key, options)
+	}
+
+	kv, err := n.bucket(options.Database, options.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.Prefix && !options.Suffix {
+		entry, err := kv.Get(encoding.EncodeToString([]byte(key)))
+		if err == nats.ErrKeyNotFound {
+			return nil, store.ErrNotFound
+		} else if err != nil {
+			return nil, err
+		}
+
+		r, err := decode(entry)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			kv.Delete(entry.Key())
+			return nil, store.ErrNotFound
+		}
+		return []*store.Record{r}, nil
+	}
+
+	var prefix, suffix string
+	if options.Prefix {
+		prefix = key
+	}
+	if options.Suffix {
+		suffix = key
+	}
+
+	records, err := n.scan(kv, prefix, suffix, options.Order, options.Limit, options.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*store.Record{}
+	}
+	return records, nil
+}
+
+// where reads the records without the metadata values and then filters them, as
+// nats can't query by metadata. The limit and offset are applied once filtered.
+func (n *natsStore) where(key string, options store.ReadOptions) ([]*store.Record, error) {
+	opts := []store.ReadOption{
+		store.ReadFrom(options.Database, options.Table),
+		store.ReadOrder(options.Order),
+	}
+	if options.Prefix {
+		opts = append(opts, store.ReadPrefix())
+	}
+	if options.Suffix {
+		opts = append(opts, store.ReadSuffix())
+	}
+
+	var match *regexp.Regexp
+	if options.Match != nil {
+		var err error
+		if match, err = options.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	recs, err := n.Read(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*store.Record{}
+	for _, r := range recs {
+		if r.Match(options.Where) && (match == nil || match.MatchString(r.Key)) {
+			results = append(results, r)
+		}
+	}
+	if options.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, options.Order)
+	}
+
+	if !options.Prefix && !options.Suffix {
+		if len(results) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return results, nil
+	}
+
+	if options.Offset > 0 {
+		// offset is greater than the records we have
+		if int(options.Offset) >= len(results) {
+			return []*store.Record{}, nil
+		}
+		results = results[options.Offset:]
+	}
+
+	if options.Limit > 0 && int(options.Limit) < len(results) {
+		results = results[:options.Limit]
+	}
+
+	return results, nil
+}
+
+func (n *natsStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if n.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	kv, err := n.bucket(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+
+	rec := &record{
+		Key:      r.Key,
+		Value:    r.Value,
+		Metadata: r.Metadata,
+	}
+	if r.Expiry != 0 {
+		rec.ExpiresAt = time.Now().Add(r.Expiry)
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	key := encoding.EncodeToString([]byte(r.Key))
+
+	var rev uint64
+	switch {
+	case options.IfVersion > 0:
+		rev, err = kv.Update(key, b, options.IfVersion)
+	case options.IfNotExists:
+		rev, err = kv.Create(key, b)
+		if errors.Is(err, nats.ErrKeyExists) {
+			// the key may be an expired record which hasn't been removed yet
+			if entry, gerr := kv.Get(key); gerr == nil {
+				if old, derr := decode(entry); derr == nil && old == nil {
+					rev, err = kv.Update(key, b, entry.Revision())
+				}
+			}
+		}
+	default:
+		rev, err = kv.Put(key, b)
+	}
+	if errors.Is(err, nats.ErrKeyExists) {
+		return store.ErrConflict
+	} else if err != nil {
+		return errors.Wrap(err, "Couldn't write record "+r.Key)
+	}
+
+	r.Version = rev
+	return nil
+}
+
+// Touch rewrites the entry with the new expiry. The update is made against the revision
+// which was read, so it's retried rather than overwriting a concurrent write.
+func (n *natsStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if n.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	kv, err := n.bucket(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+
+	k := encoding.EncodeToString([]byte(key))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry, err := kv.Get(k)
+		if err == nats.ErrKeyNotFound {
+			return store.ErrNotFound
+		} else if err != nil {
+			return err
+		}
+		r, err := decode(entry)
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			return store.ErrNotFound
+		}
+
+		rec := &record{
+			Key:      r.Key,
+			Value:    r.Value,
+			Metadata: r.Metadata,
+		}
+		if expiry != 0 {
+			rec.ExpiresAt = time.Now().Add(expiry)
+		}
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+
+		_, err = kv.Update(k, b, entry.Revision())
+		if errors.Is(err, nats.ErrKeyExists) {
+			// written since it was read
+			continue
+		} else if err != nil {
+			return errors.Wrap(err, "Couldn't touch record "+key)
+		}
+		return nil
+	}
+}
+
+func (n *natsStore) Delete(key string, opts ...store.DeleteOption) error {
+	if n.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	kv, err := n.bucket(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+
+	if !options.Prefix && !options.Suffix {
+		return del(kv, key)
+	}
+
+	var prefix, suffix string
+	if options.Prefix {
+		prefix = key
+	}
+	if options.Suffix {
+		suffix = key
+	}
+
+	records, err := n.scan(kv, prefix, suffix, store.OrderAsc, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := del(kv, r.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// del removes the key from the bucket
+func del(kv nats.KeyValue, key string) error {
+	if err := kv.Delete(encoding.EncodeToString([]byte(key))); err != nil && err != nats.ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+func (n *natsStore) List(opts ...store.ListOption) ([]string, error) {
+	options := store.ListOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(options.Delimiter) > 0 {
+		return store.ListDelimited(n, options)
+	}
+	if options.Match != nil {
+		return store.ListMatched(n, options)
+	}
+
+	if options.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	if options.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := n.where(options.Prefix, store.ReadOptions{
+			Database: options.Database,
+			Table:    options.Table,
+			Prefix:   true,
+			Order:    options.Order,
+			OrderBy:  options.OrderBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys := []string{}
+		for _, r := range recs {
+			if strings.HasSuffix(r.Key, options.Suffix) {
+				keys = append(keys, r.Key)
+			}
+		}
+		if options.Offset > 0 {
+			// offset is greater than the keys we have
+			if int(options.Offset) >= len(keys) {
+				return []string{}, nil
+			}
+			keys = keys[options.Offset:]
+		}
+		if options.Limit > 0 && int(options.Limit) < len(keys) {
+			keys = keys[:options.Limit]
+		}
+		return keys, nil
+	}
+
+	kv, err := n.bucket(options.Database, options.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := n.scan(kv, options.Prefix, options.Suffix, options.Order, options.Limit, options.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, r := range records {
+		keys = append(keys, r.Key)
+	}
+	return keys, nil
+}
+
+// Healthy returns an error if the connection to the nats server has been lost
+func (n *natsStore) Healthy(ctx context.Context) error {
+	if n.conn == nil {
+		return ErrNoConnection
+	}
+	if status := n.conn.Status(); status != nats.CONNECTED {
+		return errors.Errorf("nats connection is %v", status)
+	}
+	return nil
+}
+
+func (n *natsStore) Close() error {
+	n.Lock()
+	defer n.Unlock()
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+		n.js = nil
+	}
+	return nil
+}
+
+func (n *natsStore) String() string {
+	return "nats"
+}
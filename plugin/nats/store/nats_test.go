@@ -0,0 +1,36 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/storetest"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// runServer starts an embedded nats server with jetstream enabled
+func runServer(t *testing.T) string {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(10 * time.Second) {
+		t.Fatalf("Server failed to start")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv.ClientURL()
+}
+
+func TestConformance(t *testing.T) {
+	addr := runServer(t)
+	storetest.Conformance(t, func() store.Store {
+		return NewStore(store.Nodes(addr))
+	})
+}
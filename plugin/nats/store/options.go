@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type tlsConfigKey struct{}
+
+type replicasKey struct{}
+
+type maxAgeKey struct{}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// TLSConfig to use when connecting to the cluster
+func TLSConfig(c *tls.Config) store.Option {
+	return setOption(tlsConfigKey{}, c)
+}
+
+// Replicas sets the number of replicas used when creating a bucket, defaults to 1
+func Replicas(n int) store.Option {
+	return setOption(replicasKey{}, n)
+}
+
+// MaxAge sets the maximum age of any value in a bucket. JetStream expires whole buckets rather
+// than individual keys, so records with an expiry are also filtered when read.
+func MaxAge(d time.Duration) store.Option {
+	return setOption(maxAgeKey{}, d)
+}
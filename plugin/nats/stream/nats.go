@@ -144,11 +144,12 @@ func (s *stream) Publish(topic string, msg interface{}, opts ...events.PublishOp
 
 	// construct the event
 	event := &events.Event{
-		ID:        uuid.New().String(),
-		Topic:     topic,
-		Timestamp: options.Timestamp,
-		Metadata:  options.Metadata,
-		Payload:   payload,
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Timestamp:   options.Timestamp,
+		Metadata:    options.Metadata,
+		Payload:     payload,
+		OrderingKey: options.OrderingKey,
 	}
 
 	// serialize the event to bytes
@@ -186,7 +187,16 @@ func (s *stream) Consume(topic string, opts ...events.ConsumeOption) (<-chan eve
 	handleMsg := func(m *stan.Msg) {
 		// poison message handling
 		if options.GetRetryLimit() > -1 && m.Redelivered && int(m.RedeliveryCount) > options.GetRetryLimit() {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			var evt events.Event
+			if len(options.DeadLetterTopic) > 0 && json.Unmarshal(m.Data, &evt) == nil {
+				if err := events.DeadLetter(s, options.DeadLetterTopic, evt); err != nil {
+					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+						logger.Errorf("Error publishing message %v to dead-letter topic: %v", m.Sequence, err)
+					}
+					// not acknowledging the message so it's dead-lettered when it's redelivered
+					return
+				}
+			} else if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 				logger.Errorf("Message retry limit reached, discarding: %v", m.Sequence)
 			}
 			m.Ack() // ignoring error
@@ -0,0 +1,79 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/broker"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewPublishWrapper returns a broker publish wrapper which starts a producer span for each
+// message published, propagating its context in the header of the message. The span continues
+// the trace of the context set with broker.PublishContext, or else the one already in the header,
+// e.g. set by the client.
+func NewPublishWrapper(opts ...Option) broker.PublishWrapper {
+	options := newOptions(opts...)
+	return func(fn broker.PublishFunc) broker.PublishFunc {
+		return func(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+			var pubOpts broker.PublishOptions
+			for _, o := range opts {
+				o(&pubOpts)
+			}
+			ctx := pubOpts.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if !trace.SpanContextFromContext(ctx).IsValid() {
+				ctx = options.Propagator.Extract(ctx, metadataCarrier(m.Header))
+			}
+
+			ctx, span := options.tracer().Start(ctx, topic+" send",
+				trace.WithSpanKind(trace.SpanKindProducer),
+				trace.WithAttributes(messagingAttributes(topic)...),
+			)
+
+			// the message is owned by the publisher, so its copied with the header set
+			msg := *m
+			msg.Header = make(map[string]string, len(m.Header)+2)
+			for k, v := range m.Header {
+				msg.Header[k] = v
+			}
+			options.Propagator.Inject(ctx, metadataCarrier(msg.Header))
+
+			err := fn(topic, &msg, opts...)
+			finish(span, err)
+			return err
+		}
+	}
+}
+
+// NewSubscribeWrapper returns a broker subscribe wrapper which starts a consumer span for each
+// message received by the handler, continuing the trace propagated in the header of the message.
+// The handler is passed the message with the context of the span in its header, so the traces
+// of the subscribers of the server carry on from it.
+func NewSubscribeWrapper(opts ...Option) broker.SubscribeWrapper {
+	options := newOptions(opts...)
+	return func(fn broker.SubscribeFunc) broker.SubscribeFunc {
+		return func(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+			return fn(topic, func(m *broker.Message) error {
+				ctx := options.Propagator.Extract(context.Background(), metadataCarrier(m.Header))
+				ctx, span := options.tracer().Start(ctx, topic+" receive",
+					trace.WithSpanKind(trace.SpanKindConsumer),
+					trace.WithAttributes(messagingAttributes(topic, semconv.MessagingOperationReceive)...),
+				)
+
+				msg := *m
+				msg.Header = make(map[string]string, len(m.Header)+2)
+				for k, v := range m.Header {
+					msg.Header[k] = v
+				}
+				options.Propagator.Inject(ctx, metadataCarrier(msg.Header))
+
+				err := h(&msg)
+				finish(span, err)
+				return err
+			}, opts...)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package opentelemetry
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/micro/micro/v3/service/client"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type otelClient struct {
+	client.Client
+	opts Options
+}
+
+type otelStream struct {
+	client.Stream
+	span trace.Span
+	once sync.Once
+}
+
+// NewClientWrapper returns a client wrapper which starts a client span for each call and stream,
+// and a producer span for each message published. The context of the span is propagated to
+// the server in the metadata of the request.
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	options := newOptions(opts...)
+	return func(c client.Client) client.Client {
+		return &otelClient{Client: c, opts: options}
+	}
+}
+
+func (o *otelClient) start(ctx context.Context, req client.Request) (context.Context, trace.Span) {
+	ctx, span := o.opts.tracer().Start(ctx, req.Service()+"/"+req.Endpoint(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcAttributes(req.Service(), req.Endpoint())...),
+	)
+	return inject(ctx, o.opts.Propagator), span
+}
+
+func (o *otelClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	ctx, span := o.start(ctx, req)
+	err := o.Client.Call(ctx, req, rsp, opts...)
+	finish(span, err)
+	return err
+}
+
+// Stream opens a stream whose span ends when the stream does, with an error or once closed
+func (o *otelClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	ctx, span := o.start(ctx, req)
+	s, err := o.Client.Stream(ctx, req, opts...)
+	if err != nil {
+		finish(span, err)
+		return nil, err
+	}
+	return &otelStream{Stream: s, span: span}, nil
+}
+
+func (o *otelClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	ctx, span := o.opts.tracer().Start(ctx, msg.Topic()+" send",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(msg.Topic())...),
+	)
+	err := o.Client.Publish(inject(ctx, o.opts.Propagator), msg, opts...)
+	finish(span, err)
+	return err
+}
+
+// end the span of the stream, once
+func (s *otelStream) end(err error) {
+	s.once.Do(func() {
+		finish(s.span, err)
+	})
+}
+
+func (s *otelStream) Send(msg interface{}) error {
+	err := s.Stream.Send(msg)
+	if err != nil {
+		s.end(err)
+	}
+	return err
+}
+
+func (s *otelStream) Recv(msg interface{}) error {
+	err := s.Stream.Recv(msg)
+	if err == io.EOF {
+		s.end(nil)
+	} else if err != nil {
+		s.end(err)
+	}
+	return err
+}
+
+func (s *otelStream) Close() error {
+	err := s.Stream.Close()
+	s.end(nil)
+	return err
+}
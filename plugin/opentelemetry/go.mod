@@ -0,0 +1,14 @@
+module github.com/micro/micro/plugin/opentelemetry/v3
+
+go 1.16
+
+require (
+	github.com/micro/micro/v3 v3.0.4
+	github.com/stretchr/testify v1.7.1
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.7.0
+	go.opentelemetry.io/otel/sdk v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
+)
+
+replace github.com/micro/micro/v3 => ../..
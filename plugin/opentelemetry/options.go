@@ -0,0 +1,52 @@
+package opentelemetry
+
+import (
+	gotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options of the wrappers
+type Options struct {
+	// TracerProvider the spans are started with, the global tracer provider if nil
+	TracerProvider trace.TracerProvider
+	// Propagator injects the context of the spans in the metadata of the requests and the
+	// headers of the messages, and extracts it
+	Propagator propagation.TextMapPropagator
+}
+
+type Option func(o *Options)
+
+// WithTracerProvider sets the tracer provider the spans are started with
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *Options) {
+		o.TracerProvider = tp
+	}
+}
+
+// WithPropagator sets the propagator of the context of the spans, DefaultPropagator by default
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *Options) {
+		o.Propagator = p
+	}
+}
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Propagator: DefaultPropagator,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// tracer returns the tracer of the wrappers. The global tracer provider is looked up on each
+// span, so wrappers created before it's set still use it.
+func (o Options) tracer() trace.Tracer {
+	tp := o.TracerProvider
+	if tp == nil {
+		tp = gotel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
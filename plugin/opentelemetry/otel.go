@@ -0,0 +1,100 @@
+// Package opentelemetry provides OpenTelemetry wrappers for the client, server, broker and store. The
+// spans follow the semantic conventions of rpcs, messaging and databases, and their context is
+// propagated as a W3C traceparent in the metadata of requests and the headers of messages.
+package opentelemetry
+
+import (
+	"context"
+	"strings"
+
+	mmd "github.com/micro/micro/v3/service/context/metadata"
+	"github.com/micro/micro/v3/service/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName is the name of the tracer of the wrappers
+	instrumentationName = "github.com/micro/micro/plugin/opentelemetry/v3"
+	// system is the rpc and messaging system of the spans
+	system = "micro"
+)
+
+var (
+	// DefaultPropagator propagates the W3C trace context and baggage
+	DefaultPropagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+
+	// StatusCodeKey is the attribute of the code of the micro errors returned
+	StatusCodeKey = attribute.Key("rpc.micro.status_code")
+)
+
+// metadataCarrier carries the span context in the metadata of a request or the header of a
+// message. Transports may have title cased the keys, so they're looked up either way.
+type metadataCarrier map[string]string
+
+func (m metadataCarrier) Get(key string) string {
+	v, _ := mmd.Metadata(m).Get(key)
+	return v
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	// the keys are lower case, as grpc requires
+	m[strings.ToLower(key)] = value
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// inject the span context of the context into its metadata
+func inject(ctx context.Context, p propagation.TextMapPropagator) context.Context {
+	md := mmd.Metadata{}
+	p.Inject(ctx, metadataCarrier(md))
+	return mmd.MergeContext(ctx, md, true)
+}
+
+// extract the span context from the metadata of the context
+func extract(ctx context.Context, p propagation.TextMapPropagator) context.Context {
+	md, _ := mmd.FromContext(ctx)
+	return p.Extract(ctx, metadataCarrier(md))
+}
+
+// rpcAttributes are the attributes of the span of a request to the endpoint of the service
+func rpcAttributes(service, endpoint string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.RPCSystemKey.String(system),
+		semconv.RPCServiceKey.String(service),
+		semconv.RPCMethodKey.String(endpoint),
+	}
+}
+
+// messagingAttributes are the attributes of the span of a message of the topic
+func messagingAttributes(topic string, attrs ...attribute.KeyValue) []attribute.KeyValue {
+	return append([]attribute.KeyValue{
+		semconv.MessagingSystemKey.String(system),
+		semconv.MessagingDestinationKey.String(topic),
+		semconv.MessagingDestinationKindTopic,
+	}, attrs...)
+}
+
+// finish ends the span, recording the error if there is one
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if merr, ok := err.(*errors.Error); ok {
+			span.SetAttributes(StatusCodeKey.Int64(int64(merr.Code)))
+		}
+	}
+	span.End()
+}
@@ -0,0 +1,158 @@
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/broker/memory"
+	"github.com/micro/micro/v3/service/client"
+	mmd "github.com/micro/micro/v3/service/context/metadata"
+	"github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/server"
+	"github.com/micro/micro/v3/service/store"
+	mstore "github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testRequest struct {
+	client.Request
+}
+
+func (r testRequest) Service() string  { return "helloworld" }
+func (r testRequest) Endpoint() string { return "Greeter.Hello" }
+
+type testServerRequest struct {
+	server.Request
+}
+
+func (r testServerRequest) Service() string  { return "helloworld" }
+func (r testServerRequest) Endpoint() string { return "Greeter.Hello" }
+
+// testClient passes the metadata of the calls to the handler, as a transport would
+type testClient struct {
+	client.Client
+	handler server.HandlerFunc
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	md, _ := mmd.FromContext(ctx)
+	return c.handler(mmd.NewContext(context.Background(), mmd.Copy(md)), testServerRequest{}, rsp)
+}
+
+func newProvider() (*tracetest.SpanRecorder, trace.TracerProvider) {
+	sr := tracetest.NewSpanRecorder()
+	return sr, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+}
+
+func TestClientServer(t *testing.T) {
+	sr, tp := newProvider()
+
+	var handled context.Context
+	h := NewHandlerWrapper(WithTracerProvider(tp))(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		handled = ctx
+		return errors.NotFound("helloworld", "not found")
+	})
+	c := NewClientWrapper(WithTracerProvider(tp))(&testClient{handler: h})
+
+	err := c.Call(context.TODO(), testRequest{}, nil)
+	assert.Error(t, err)
+
+	// the trace context is propagated as a w3c traceparent
+	_, ok := mmd.Get(handled, "traceparent")
+	assert.True(t, ok)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 2)
+	srv, cli := spans[0], spans[1]
+
+	assert.Equal(t, "helloworld/Greeter.Hello", cli.Name())
+	assert.Equal(t, trace.SpanKindClient, cli.SpanKind())
+	assert.Equal(t, trace.SpanKindServer, srv.SpanKind())
+	assert.Equal(t, cli.SpanContext().TraceID(), srv.SpanContext().TraceID())
+	assert.Equal(t, cli.SpanContext().SpanID(), srv.Parent().SpanID())
+	assert.True(t, srv.Parent().IsRemote())
+
+	// the error is recorded with its code
+	assert.Equal(t, codes.Error, srv.Status().Code)
+	assert.Contains(t, srv.Attributes(), StatusCodeKey.Int64(404))
+}
+
+func TestBroker(t *testing.T) {
+	sr, tp := newProvider()
+
+	b := broker.Wrap(memory.NewBroker(
+		broker.WrapPublish(NewPublishWrapper(WithTracerProvider(tp))),
+		broker.WrapSubscribe(NewSubscribeWrapper(WithTracerProvider(tp))),
+	))
+	assert.NoError(t, b.Connect())
+
+	received := make(chan *broker.Message, 1)
+	_, err := b.Subscribe("events", func(m *broker.Message) error {
+		received <- m
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// the message published continues the trace of the publish context
+	ctx, parent := tp.Tracer("test").Start(context.TODO(), "request")
+	msg := &broker.Message{Header: map[string]string{"foo": "bar"}, Body: []byte("hello")}
+	assert.NoError(t, b.Publish("events", msg, broker.PublishContext(ctx)))
+	parent.End()
+
+	select {
+	case m := <-received:
+		assert.Equal(t, "bar", m.Header["foo"])
+		assert.NotEmpty(t, m.Header["traceparent"])
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+
+	// the message of the publisher isn't modified
+	assert.Equal(t, map[string]string{"foo": "bar"}, msg.Header)
+
+	// the receive span ends once the handler returns
+	assert.Eventually(t, func() bool { return len(sr.Ended()) == 3 }, time.Second, time.Millisecond*10)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range sr.Ended() {
+		spans[s.Name()] = s
+	}
+	send, receive := spans["events send"], spans["events receive"]
+	assert.NotNil(t, send)
+	assert.NotNil(t, receive)
+	assert.Equal(t, parent.SpanContext().SpanID(), send.Parent().SpanID())
+	assert.Equal(t, send.SpanContext().SpanID(), receive.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindProducer, send.SpanKind())
+	assert.Equal(t, trace.SpanKindConsumer, receive.SpanKind())
+}
+
+func TestStore(t *testing.T) {
+	sr, tp := newProvider()
+	s := NewStore(mstore.NewStore(store.Database("db"), store.Table("table")), WithTracerProvider(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.TODO(), "request")
+	assert.NoError(t, WithContext(ctx, s).Write(&store.Record{Key: "foo", Value: []byte("bar")}))
+	_, err := WithContext(ctx, s).Read("missing")
+	assert.Equal(t, store.ErrNotFound, err)
+	parent.End()
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 3)
+
+	write := spans[0]
+	assert.Equal(t, "store.Write", write.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), write.Parent().SpanID())
+	assert.Contains(t, write.Attributes(), TableKey.String("table"))
+	assert.Contains(t, write.Attributes(), RecordKey.String("foo"))
+
+	// records which aren't found aren't errors
+	read := spans[1]
+	assert.Equal(t, "store.Read", read.Name())
+	assert.NotEqual(t, codes.Error, read.Status().Code)
+}
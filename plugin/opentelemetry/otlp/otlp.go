@@ -0,0 +1,49 @@
+// Package otlp provides an OpenTelemetry tracer provider exporting the spans to a collector by
+// OTLP over grpc
+package otlp
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/util/opentelemetry"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+const (
+	DefaultReporterAddress = "localhost:4317"
+)
+
+// New returns a tracer provider which batches the spans and exports them to the collector at the
+// reporter address, localhost:4317 by default. Traces started by the service are sampled at the
+// sampling rate, and the others as their parent was. Shutdown the provider to flush the spans.
+func New(opts ...opentelemetry.Option) (*sdktrace.TracerProvider, error) {
+	options := opentelemetry.DefaultOptions()
+	options.TraceReporterAddress = DefaultReporterAddress
+	for _, o := range opts {
+		o(&options)
+	}
+
+	logger.Debug("Creating a new OTLP tracer provider")
+
+	// the exporter connects in the background, so the collector needn't be up yet
+	exporter, err := otlptracegrpc.New(context.Background(),
+		otlptracegrpc.WithEndpoint(options.TraceReporterAddress),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SamplingRate))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(options.ServiceName),
+		)),
+	), nil
+}
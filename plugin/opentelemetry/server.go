@@ -0,0 +1,49 @@
+package opentelemetry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/micro/micro/v3/service/server"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewHandlerWrapper returns a handler wrapper which starts a server span for each request,
+// continuing the trace propagated in the metadata of the request. Calls to debug aren't traced.
+func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
+	options := newOptions(opts...)
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			if strings.HasPrefix(req.Endpoint(), "Debug.") {
+				return h(ctx, req, rsp)
+			}
+
+			ctx, span := options.tracer().Start(extract(ctx, options.Propagator), req.Service()+"/"+req.Endpoint(),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(rpcAttributes(req.Service(), req.Endpoint())...),
+			)
+			err := h(ctx, req, rsp)
+			finish(span, err)
+			return err
+		}
+	}
+}
+
+// NewSubscriberWrapper returns a subscriber wrapper which starts a consumer span for each
+// message processed, continuing the trace propagated in the header of the message
+func NewSubscriberWrapper(opts ...Option) server.SubscriberWrapper {
+	options := newOptions(opts...)
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			ctx = options.Propagator.Extract(ctx, metadataCarrier(msg.Header()))
+			ctx, span := options.tracer().Start(ctx, msg.Topic()+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(messagingAttributes(msg.Topic(), semconv.MessagingOperationProcess)...),
+			)
+			err := fn(ctx, msg)
+			finish(span, err)
+			return err
+		}
+	}
+}
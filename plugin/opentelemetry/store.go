@@ -0,0 +1,134 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/store"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// TableKey and RecordKey are the attributes of the table and key of the store operations
+	TableKey  = attribute.Key("db.micro.table")
+	RecordKey = attribute.Key("db.micro.key")
+)
+
+type otelStore struct {
+	// forwards the optional interfaces of the backing store, tracing their operations. The
+	// context of the decorator is that of the request the operations are made for.
+	store.Decorator
+
+	b    store.Store // the backing store
+	opts Options
+}
+
+// NewStore returns a store which starts a client span for each operation on the backing store.
+// Operations which don't take a context start new traces, use WithContext to make them part of
+// the trace of a request.
+func NewStore(s store.Store, opts ...Option) store.Store {
+	o := &otelStore{b: s, opts: newOptions(opts...)}
+	o.Decorator = store.Decorator{Store: s, Context: context.Background(), Around: o.around}
+	return o
+}
+
+// WithContext returns the store with the operations which don't take a context traced as part
+// of the request the context belongs to. Stores not returned by NewStore are returned as
+// they are.
+func WithContext(ctx context.Context, s store.Store) store.Store {
+	o, ok := s.(*otelStore)
+	if !ok {
+		return s
+	}
+	oc := *o
+	oc.Decorator.Context = ctx
+	return &oc
+}
+
+// around traces the optional operations made by the decorator
+func (s *otelStore) around(ctx context.Context, op store.Operation) (context.Context, func(error)) {
+	return s.start(ctx, op.Method, op.Database, op.Table, op.Key)
+}
+
+// start a span for the operation, the returned func ends it
+func (s *otelStore) start(ctx context.Context, method, database, table, key string) (context.Context, func(error)) {
+	// the database and table default to those of the backing store
+	o := s.b.Options()
+	if len(database) == 0 {
+		database = o.Database
+	}
+	if len(table) == 0 {
+		table = o.Table
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemKey.String(s.b.String()),
+		semconv.DBNameKey.String(database),
+		semconv.DBOperationKey.String(method),
+		TableKey.String(table),
+	}
+	if len(key) > 0 {
+		attrs = append(attrs, RecordKey.String(key))
+	}
+
+	newCtx, span := s.opts.tracer().Start(ctx, "store."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return newCtx, func(err error) {
+		// records which aren't found aren't errors
+		if err == store.ErrNotFound {
+			err = nil
+		}
+		finish(span, err)
+	}
+}
+
+// Read reads the records from the backing store
+func (s *otelStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := s.start(s.Decorator.Context, "Read", options.Database, options.Table, key)
+	recs, err := s.b.Read(key, opts...)
+	end(err)
+	return recs, err
+}
+
+// Write writes the record to the backing store
+func (s *otelStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := s.start(s.Decorator.Context, "Write", options.Database, options.Table, r.Key)
+	err := s.b.Write(r, opts...)
+	end(err)
+	return err
+}
+
+// Delete removes the record from the backing store
+func (s *otelStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := s.start(s.Decorator.Context, "Delete", options.Database, options.Table, key)
+	err := s.b.Delete(key, opts...)
+	end(err)
+	return err
+}
+
+// List returns the keys from the backing store, the prefix listed is the key of the span
+func (s *otelStore) List(opts ...store.ListOption) ([]string, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := s.start(s.Decorator.Context, "List", options.Database, options.Table, options.Prefix)
+	keys, err := s.b.List(opts...)
+	end(err)
+	return keys, err
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type maxOpenConnsKey struct{}
+
+type maxIdleConnsKey struct{}
+
+type connMaxLifetimeKey struct{}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// MaxOpenConns sets the maximum number of open connections to the database, zero means there's
+// no limit
+func MaxOpenConns(n int) store.Option {
+	return setOption(maxOpenConnsKey{}, n)
+}
+
+// MaxIdleConns sets the maximum number of connections kept in the idle pool
+func MaxIdleConns(n int) store.Option {
+	return setOption(maxIdleConnsKey{}, n)
+}
+
+// ConnMaxLifetime sets the maximum amount of time a connection may be reused, so connections
+// are spread over the nodes of a cluster as they're replaced
+func ConnMaxLifetime(d time.Duration) store.Option {
+	return setOption(connMaxLifetimeKey{}, d)
+}
@@ -18,12 +18,14 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"net"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -46,18 +48,30 @@ var (
 var (
 	re = regexp.MustCompile("[^a-zA-Z0-9]+")
 
+	// the number of rows written by each insert of WriteMany
+	batchSize = 1000
+
 	// alternative ordering
 	orderAsc  = "ORDER BY key ASC"
 	orderDesc = "ORDER BY key DESC"
+	// records which don't expire have a NULL expiry so are sorted last
+	orderExpiryAsc  = "ORDER BY expiry ASC NULLS LAST, key ASC"
+	orderExpiryDesc = "ORDER BY expiry DESC NULLS FIRST, key DESC"
 
 	// the sql statements we prepare and use
 	statements = map[string]string{
 		"list":          "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1 ORDER BY key ASC LIMIT $2 OFFSET $3;",
 		"read":          "SELECT key, value, metadata, expiry FROM %s.%s WHERE key = $1;",
+		"readForUpdate": "SELECT key, value, metadata, expiry FROM %s.%s WHERE key = $1 FOR UPDATE;",
 		"readMany":      "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1 ORDER BY key ASC;",
 		"readOffset":    "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1 ORDER BY key ASC LIMIT $2 OFFSET $3;",
+		"readWhere":     "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1%s ORDER BY key ASC LIMIT $2 OFFSET $3;",
 		"write":         "INSERT INTO %s.%s(key, value, metadata, expiry) VALUES ($1, $2::bytea, $3, $4) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, metadata = EXCLUDED.metadata, expiry = EXCLUDED.expiry;",
+		"writeMany":     "INSERT INTO %s.%s(key, value, metadata, expiry) VALUES %s ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, metadata = EXCLUDED.metadata, expiry = EXCLUDED.expiry;",
+		"touch":         "UPDATE %s.%s SET expiry = $2 WHERE key = $1 AND (expiry IS NULL OR expiry > now());",
 		"delete":        "DELETE FROM %s.%s WHERE key = $1;",
+		"deleteMany":    "DELETE FROM %s.%s WHERE key = ANY($1);",
+		"deletePattern": "DELETE FROM %s.%s WHERE key LIKE $1;",
 		"deleteExpired": "DELETE FROM %s.%s WHERE expiry < now();",
 		"showTables":    "SELECT schemaname, tablename FROM pg_catalog.pg_tables WHERE schemaname != 'pg_catalog' AND schemaname != 'information_schema';",
 	}
@@ -70,6 +84,10 @@ type sqlStore struct {
 	sync.RWMutex
 	// known databases
 	databases map[string]bool
+
+	// the statements prepared on the connection, keyed by their query
+	stmtLock sync.Mutex
+	stmts    map[string]*sql.Stmt
 }
 
 func (s *sqlStore) getDB(database, table string) (string, string) {
@@ -209,6 +227,15 @@ func (s *sqlStore) initDB(database, table string) error {
 		return err
 	}
 
+	// Create an index for each of the metadata fields used by ReadWhere
+	for _, field := range s.options.Indexes {
+		idx := pq.QuoteIdentifier("metadata_" + field + "_index_" + table)
+		_, err = db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s.%s ((metadata->>%s));`, idx, database, table, pq.QuoteLiteral(field)))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -233,10 +260,26 @@ func (s *sqlStore) configure() error {
 		return err
 	}
 
+	if ctx := s.options.Context; ctx != nil {
+		if n, ok := ctx.Value(maxOpenConnsKey{}).(int); ok {
+			db.SetMaxOpenConns(n)
+		}
+		if n, ok := ctx.Value(maxIdleConnsKey{}).(int); ok {
+			db.SetMaxIdleConns(n)
+		}
+		if d, ok := ctx.Value(connMaxLifetimeKey{}).(time.Duration); ok {
+			db.SetConnMaxLifetime(d)
+		}
+	}
+
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return err
 	}
 
+	// statements prepared on the previous connection can't be used with the new one
+	s.closeStmts()
+
 	if s.dbConn != nil {
 		s.dbConn.Close()
 	}
@@ -251,6 +294,9 @@ func (s *sqlStore) configure() error {
 	return s.initDB(database, table)
 }
 
+// prepare returns the statement for the query on the table. Statements are prepared once and
+// reused until the connection is reconfigured or the store is closed, so they mustn't be
+// closed by the caller.
 func (s *sqlStore) prepare(database, table, query string, order store.Order) (*sql.Stmt, error) {
 	st, ok := statements[query]
 	if !ok {
@@ -272,14 +318,45 @@ func (s *sqlStore) prepare(database, table, query string, order store.Order) (*s
 	if err != nil {
 		return nil, err
 	}
+
+	s.stmtLock.Lock()
+	defer s.stmtLock.Unlock()
+
+	if stmt, ok := s.stmts[q]; ok {
+		return stmt, nil
+	}
 	stmt, err := db.Prepare(q)
 	if err != nil {
 		return nil, err
 	}
+	if s.stmts == nil {
+		s.stmts = make(map[string]*sql.Stmt)
+	}
+	s.stmts[q] = stmt
 	return stmt, nil
 }
 
+// closeStmts closes the prepared statements
+func (s *sqlStore) closeStmts() {
+	s.stmtLock.Lock()
+	defer s.stmtLock.Unlock()
+
+	for _, stmt := range s.stmts {
+		stmt.Close()
+	}
+	s.stmts = nil
+}
+
+// Healthy pings the database
+func (s *sqlStore) Healthy(ctx context.Context) error {
+	if s.dbConn == nil {
+		return ErrNoConnection
+	}
+	return s.dbConn.PingContext(ctx)
+}
+
 func (s *sqlStore) Close() error {
+	s.closeStmts()
 	if s.dbConn != nil {
 		return s.dbConn.Close()
 	}
@@ -304,10 +381,22 @@ func (s *sqlStore) List(opts ...store.ListOption) ([]string, error) {
 		o(&options)
 	}
 
+	// the keys are grouped once they've been listed
+	if len(options.Delimiter) > 0 {
+		return store.ListDelimited(s, options)
+	}
+	if options.Match != nil {
+		return store.ListMatched(s, options)
+	}
+
 	// create the db if not exists
 	if err := s.createDB(options.Database, options.Table); err != nil {
 		return nil, err
 	}
+	if options.Cursor != nil || options.OrderBy == store.OrderByExpiry {
+		return s.listWhere(options)
+	}
+
 	limit := sql.NullInt32{}
 	offset := 0
 	pattern := "%"
@@ -330,7 +419,6 @@ func (s *sqlStore) List(opts ...store.ListOption) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer st.Close()
 
 	rows, err := st.Query(pattern, limit, offset)
 	if err != nil {
@@ -361,6 +449,27 @@ func (s *sqlStore) List(opts ...store.ListOption) ([]string, error) {
 }
 
 // rowToRecord converts from sql.Row to a store.Record. If the record has expired it will issue a delete in a separate goroutine
+// listWhere lists the keys using where, for the pages the list statement can't read
+func (s *sqlStore) listWhere(options store.ListOptions) ([]string, error) {
+	records, err := s.where(options.Prefix+"%"+options.Suffix, store.ReadOptions{
+		Database: options.Database,
+		Table:    options.Table,
+		Limit:    options.Limit,
+		Offset:   options.Offset,
+		Order:    options.Order,
+		OrderBy:  options.OrderBy,
+		Cursor:   options.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(records))
+	for _, r := range records {
+		keys = append(keys, r.Key)
+	}
+	return keys, nil
+}
+
 func (s *sqlStore) rowToRecord(row *sql.Row) (*store.Record, error) {
 	var timehelper pq.NullTime
 	record := &store.Record{}
@@ -427,11 +536,34 @@ func (s *sqlStore) Read(key string, opts ...store.ReadOption) ([]*store.Record,
 		o(&options)
 	}
 
+	// earlier revisions of records aren't kept
+	if options.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
 	// create the db if not exists
 	if err := s.createDB(options.Database, options.Table); err != nil {
 		return nil, err
 	}
 
+	// keys matched against a pattern are read like a prefix
+	if options.Match != nil && !options.Suffix {
+		options.Prefix = true
+	}
+
+	if (len(options.Where) > 0 || options.Cursor != nil || options.OrderBy == store.OrderByExpiry || options.Match != nil) && (options.Prefix || options.Suffix) {
+		pattern := "%"
+		if options.Prefix {
+			pattern = key + pattern
+		}
+		if options.Suffix {
+			pattern = pattern + key
+		}
+		return s.where(pattern, options)
+	}
+	if options.Cursor != nil {
+		*options.Cursor = ""
+	}
 	if options.Prefix || options.Suffix {
 		return s.read(key, options)
 	}
@@ -440,17 +572,116 @@ func (s *sqlStore) Read(key string, opts ...store.ReadOption) ([]*store.Record,
 	if err != nil {
 		return nil, err
 	}
-	defer st.Close()
 
 	row := st.QueryRow(key)
 	record, err := s.rowToRecord(row)
 	if err != nil {
 		return nil, err
 	}
+	if !record.Match(options.Where) {
+		return nil, store.ErrNotFound
+	}
 	var records []*store.Record
 	return append(records, record), nil
 }
 
+// where reads the records matching the LIKE pattern which have the metadata values, the
+// fields are compared as text so the indexes created for them can be used. Pages read
+// with a cursor continue from its key rather than offsetting from the start.
+func (s *sqlStore) where(pattern string, options store.ReadOptions) ([]*store.Record, error) {
+	// cursors page by key
+	if options.Cursor != nil && options.OrderBy == store.OrderByExpiry {
+		return nil, store.ErrNotSupported
+	}
+
+	fields := make([]string, 0, len(options.Where))
+	for k := range options.Where {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	var conds strings.Builder
+	args := []interface{}{pattern, nil, options.Offset}
+	if options.Limit != 0 {
+		args[1] = options.Limit
+	}
+	for _, k := range fields {
+		args = append(args, fmt.Sprint(options.Where[k]))
+		fmt.Fprintf(&conds, " AND metadata->>%s = $%d", pq.QuoteLiteral(k), len(args))
+	}
+	if p := options.Match; p != nil {
+		if like, ok := p.Like(); ok {
+			args = append(args, like)
+			fmt.Fprintf(&conds, " AND key LIKE $%d", len(args))
+		} else {
+			// check the pattern is valid before it's sent
+			if _, err := p.Compile(); err != nil {
+				return nil, err
+			}
+			expr, err := p.Expression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, expr)
+			fmt.Fprintf(&conds, " AND key ~ $%d", len(args))
+		}
+	}
+	if options.Cursor != nil && len(*options.Cursor) > 0 {
+		after, err := store.DecodeCursor(*options.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, after)
+		if options.Order == store.OrderDesc {
+			fmt.Fprintf(&conds, " AND key < $%d", len(args))
+		} else {
+			fmt.Fprintf(&conds, " AND key > $%d", len(args))
+		}
+	}
+
+	st := statements["readWhere"]
+	switch {
+	case options.OrderBy == store.OrderByExpiry && options.Order == store.OrderDesc:
+		st = strings.Replace(st, orderAsc, orderExpiryDesc, 1)
+	case options.OrderBy == store.OrderByExpiry:
+		st = strings.Replace(st, orderAsc, orderExpiryAsc, 1)
+	case options.Order == store.OrderDesc:
+		st = strings.Replace(st, orderAsc, orderDesc, 1)
+	}
+
+	database, table := s.getDB(options.Database, options.Table)
+	q := fmt.Sprintf(st, database, table, conds.String())
+
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlStore.where failed")
+	}
+	defer rows.Close()
+
+	records, err := s.rowsToRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+	if records == nil {
+		records = []*store.Record{}
+	}
+	if options.Cursor != nil {
+		var last string
+		if len(records) > 0 {
+			last = records[len(records)-1].Key
+		}
+		*options.Cursor = store.NextCursor(options.Limit, len(records), last)
+	}
+	return records, nil
+}
+
 // Read Many records
 func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record, error) {
 	pattern := "%"
@@ -470,7 +701,6 @@ func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record,
 		if err != nil {
 			return nil, err
 		}
-		defer st.Close()
 
 		rows, err = st.Query(pattern, options.Limit, options.Offset)
 	} else {
@@ -478,7 +708,6 @@ func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record,
 		if err != nil {
 			return nil, err
 		}
-		defer st.Close()
 
 		rows, err = st.Query(pattern)
 	}
@@ -509,11 +738,20 @@ func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record,
 
 // Write records
 func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
 	var options store.WriteOptions
 	for _, o := range opts {
 		o(&options)
 	}
 
+	// records aren't versioned
+	if options.IfVersion > 0 || options.IfNotExists {
+		return store.ErrNotSupported
+	}
+
 	// create the db if not exists
 	if err := s.createDB(options.Database, options.Table); err != nil {
 		return err
@@ -523,7 +761,6 @@ func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
 	if err != nil {
 		return err
 	}
-	defer st.Close()
 
 	metadata := make(Metadata)
 	for k, v := range r.Metadata {
@@ -548,8 +785,131 @@ func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
 	return nil
 }
 
+// WriteMany writes the records using multi-row inserts in a single transaction
+func (s *sqlStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// records aren't versioned
+	if options.IfVersion > 0 || options.IfNotExists {
+		return store.ErrNotSupported
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	// a row can't be updated twice by the same insert so only the last write of a key is kept
+	seen := make(map[string]int, len(recs))
+	var rows []*store.Record
+	for _, r := range recs {
+		if i, ok := seen[r.Key]; ok {
+			rows[i] = r
+			continue
+		}
+		seen[r.Key] = len(rows)
+		rows = append(rows, r)
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	database, table := s.getDB(options.Database, options.Table)
+
+	for len(rows) > 0 {
+		n := len(rows)
+		if n > batchSize {
+			n = batchSize
+		}
+
+		values := make([]string, 0, n)
+		args := make([]interface{}, 0, n*4)
+
+		for i, r := range rows[:n] {
+			metadata := make(Metadata)
+			for k, v := range r.Metadata {
+				metadata[k] = v
+			}
+
+			var expiry interface{}
+			if r.Expiry != 0 {
+				expiry = time.Now().Add(r.Expiry)
+			}
+
+			values = append(values, fmt.Sprintf("($%d, $%d::bytea, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4))
+			args = append(args, r.Key, r.Value, metadata, expiry)
+		}
+
+		q := fmt.Sprintf(statements["writeMany"], database, table, strings.Join(values, ", "))
+		if _, err := tx.Exec(q, args...); err != nil {
+			return errors.Wrap(err, "Couldn't insert records")
+		}
+
+		rows = rows[n:]
+	}
+
+	return tx.Commit()
+}
+
 // Delete records with keys
+// Touch sets the expiry of the record
+func (s *sqlStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	st, err := s.prepare(options.Database, options.Table, "touch", store.OrderAsc)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt pq.NullTime
+	if expiry != 0 {
+		expiresAt = pq.NullTime{Time: time.Now().Add(expiry), Valid: true}
+	}
+
+	result, err := st.ExecContext(ctx, key, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "Couldn't touch record "+key)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
 func (s *sqlStore) Delete(key string, opts ...store.DeleteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
 	var options store.DeleteOptions
 	for _, o := range opts {
 		o(&options)
@@ -560,13 +920,24 @@ func (s *sqlStore) Delete(key string, opts ...store.DeleteOption) error {
 		return err
 	}
 
-	st, err := s.prepare(options.Database, options.Table, "delete", store.OrderAsc)
+	query, arg := "delete", key
+	if options.Prefix || options.Suffix {
+		pattern := "%"
+		if options.Prefix {
+			pattern = key + pattern
+		}
+		if options.Suffix {
+			pattern = pattern + key
+		}
+		query, arg = "deletePattern", pattern
+	}
+
+	st, err := s.prepare(options.Database, options.Table, query, store.OrderAsc)
 	if err != nil {
 		return err
 	}
-	defer st.Close()
 
-	result, err := st.Exec(key)
+	result, err := st.Exec(arg)
 	if err != nil {
 		return err
 	}
@@ -579,6 +950,144 @@ func (s *sqlStore) Delete(key string, opts ...store.DeleteOption) error {
 	return nil
 }
 
+// DeleteMany deletes the records with keys in a single statement
+func (s *sqlStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	st, err := s.prepare(options.Database, options.Table, "deleteMany", store.OrderAsc)
+	if err != nil {
+		return err
+	}
+
+	if _, err := st.Exec(pq.Array(keys)); err != nil {
+		return errors.Wrap(err, "Couldn't delete records")
+	}
+
+	return nil
+}
+
+// Txn runs fn in a transaction. Records read in the transaction are locked until it ends.
+func (s *sqlStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	if s.options.ReadOnly {
+		fn = store.ReadOnlyTxn(fn)
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&sqlTx{s: s, tx: tx, database: options.Database, table: options.Table}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type sqlTx struct {
+	s               *sqlStore
+	tx              *sql.Tx
+	database, table string
+}
+
+// stmt returns the store's prepared statement for the query, bound to the transaction
+func (t *sqlTx) stmt(query string) (*sql.Stmt, error) {
+	st, err := t.s.prepare(t.database, t.table, query, store.OrderAsc)
+	if err != nil {
+		return nil, err
+	}
+	return t.tx.Stmt(st), nil
+}
+
+func (t *sqlTx) Read(key string) (*store.Record, error) {
+	var timehelper pq.NullTime
+	record := &store.Record{}
+	metadata := make(Metadata)
+
+	st, err := t.stmt("readForUpdate")
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	if err := st.QueryRow(key).Scan(&record.Key, &record.Value, &metadata, &timehelper); err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	record.Metadata = toMetadata(&metadata)
+	if timehelper.Valid {
+		if timehelper.Time.Before(time.Now()) {
+			return nil, store.ErrNotFound
+		}
+		record.Expiry = time.Until(timehelper.Time)
+	}
+	return record, nil
+}
+
+func (t *sqlTx) Write(r *store.Record) error {
+	metadata := make(Metadata)
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+
+	var expiry interface{}
+	if r.Expiry != 0 {
+		expiry = time.Now().Add(r.Expiry)
+	}
+
+	st, err := t.stmt("write")
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	if _, err := st.Exec(r.Key, r.Value, metadata, expiry); err != nil {
+		return errors.Wrap(err, "Couldn't insert record "+r.Key)
+	}
+	return nil
+}
+
+func (t *sqlTx) Delete(key string) error {
+	st, err := t.stmt("delete")
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	_, err = st.Exec(key)
+	return err
+}
+
 func (s *sqlStore) Options() store.Options {
 	return s.options
 }
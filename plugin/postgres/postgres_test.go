@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"encoding/json"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/storetest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -143,3 +146,15 @@ func TestPostgres(t *testing.T) {
 		assert.Equal(t, "foo/baz", recs2[0])
 	})
 }
+
+func TestConformance(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", "localhost:5432", time.Second)
+	if err != nil {
+		t.Skipf("Postgres isn't reachable: %v", err)
+	}
+	conn.Close()
+
+	storetest.Conformance(t, func() store.Store {
+		return NewStore(store.Nodes("postgresql://postgres@localhost:5432/?sslmode=disable"))
+	})
+}
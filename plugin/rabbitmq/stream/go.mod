@@ -0,0 +1,13 @@
+module github.com/micro/micro/plugin/rabbitmq/stream/v3
+
+go 1.15
+
+require (
+	github.com/google/uuid v1.1.2
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/pkg/errors v0.9.1
+	github.com/rabbitmq/amqp091-go v1.9.0
+	github.com/stretchr/testify v1.8.0
+)
+
+replace github.com/micro/micro/v3 => ../../..
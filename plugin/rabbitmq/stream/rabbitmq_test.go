@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type testObj struct {
+	One string
+	Two int64
+}
+
+func TestAttempt(t *testing.T) {
+	assert.Equal(t, 1, deliveryAttempt(amqp.Delivery{}))
+	assert.Equal(t, 3, deliveryAttempt(amqp.Delivery{Headers: amqp.Table{attemptHeader: int32(3)}}))
+	assert.Equal(t, 1, deliveryAttempt(amqp.Delivery{Headers: amqp.Table{attemptHeader: "x"}}))
+}
+
+func TestStream(t *testing.T) {
+	addr := os.Getenv("RABBITMQ_ADDRESS")
+	if len(addr) == 0 {
+		t.Skipf("Missing required config RABBITMQ_ADDRESS")
+	}
+	s, err := NewStream(Address(addr))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	receive := func(ch <-chan events.Event) (events.Event, bool) {
+		select {
+		case ev := <-ch:
+			return ev, true
+		case <-time.After(10 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return events.Event{}, false
+		}
+	}
+
+	t.Run("MissingTopic", func(t *testing.T) {
+		assert.Equal(t, events.ErrMissingTopic, s.Publish("", nil))
+		_, err := s.Consume("")
+		assert.Equal(t, events.ErrMissingTopic, err)
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic, events.WithContext(ctx))
+		assert.NoError(t, err)
+
+		tobj := testObj{One: "foo", Two: 12345}
+		assert.NoError(t, s.Publish(topic, tobj, events.WithMetadata(map[string]string{"meta": "bar"})))
+
+		ev, ok := receive(ch)
+		if !ok {
+			return
+		}
+		assert.NotEmpty(t, ev.ID)
+		assert.Equal(t, topic, ev.Topic)
+		assert.Equal(t, "bar", ev.Metadata["meta"])
+		var tes testObj
+		assert.NoError(t, json.Unmarshal(ev.Payload, &tes))
+		assert.Equal(t, tobj, tes)
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch1, err := s.Consume(topic, events.WithGroup("group"), events.WithContext(ctx))
+		assert.NoError(t, err)
+		ch2, err := s.Consume(topic, events.WithGroup("group"), events.WithContext(ctx))
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.Publish(topic, testObj{One: "group"}))
+
+		// the event is only delivered to one consumer of the group
+		select {
+		case <-ch1:
+		case <-ch2:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Failed to receive message within the time limit")
+		}
+		select {
+		case <-ch1:
+			t.Errorf("Expected the event to be delivered once")
+		case <-ch2:
+			t.Errorf("Expected the event to be delivered once")
+		case <-time.After(time.Second):
+		}
+	})
+
+	t.Run("Nack", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Consume(topic,
+			events.WithAutoAck(false, time.Minute),
+			events.WithRetryLimit(1),
+			events.WithGroup("nack"),
+			events.WithContext(ctx),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Publish(topic, testObj{One: "retry"}))
+
+		// the event is redelivered once before it's discarded
+		for i := 0; i < 2; i++ {
+			ev, ok := receive(ch)
+			if !ok {
+				return
+			}
+			assert.NoError(t, ev.Nack())
+		}
+		select {
+		case <-ch:
+			t.Errorf("Expected the event to be discarded")
+		case <-time.After(2 * time.Second):
+		}
+	})
+}
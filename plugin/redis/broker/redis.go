@@ -78,6 +78,15 @@ func (r *redisBroker) Publish(topic string, m *broker.Message, opts ...broker.Pu
 	}
 	topic = fmt.Sprintf("broker-%s", topic)
 
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	m, err := broker.PrepareMessage(m, r.opts, options)
+	if err != nil {
+		return err
+	}
+
 	payload, err := r.opts.Codec.Marshal(m)
 	if err != nil {
 		return err
@@ -276,15 +285,21 @@ func (r *redisBroker) processMessages(msgs []redis.XMessage, topic, group string
 			r.redisClient.XAck(context.Background(), topic, group, v.ID)
 			continue
 		}
-		var msg broker.Message
-		if err := r.opts.Codec.Unmarshal([]byte(bStr), &msg); err != nil {
+		var m broker.Message
+		if err := r.opts.Codec.Unmarshal([]byte(bStr), &m); err != nil {
 			logger.Warnf("Failed to unmarshal event, discarding %s %s", err, v.ID)
 			r.redisClient.XAck(context.Background(), topic, group, v.ID)
 			continue
 		}
-		if err := h(&msg); err != nil {
+		msg, err := broker.DecompressMessage(&m)
+		if err != nil {
+			logger.Warnf("Failed to decompress event, discarding %s %s", err, v.ID)
+			r.redisClient.XAck(context.Background(), topic, group, v.ID)
+			continue
+		}
+		if err := h(msg); err != nil {
 			if eh != nil {
-				eh(&msg, err)
+				eh(msg, err)
 			}
 			return errHandler
 		}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type tlsConfigKey struct{}
+
+// TLSConfig sets the TLS config used when connecting to redis. It is only
+// required when the node address doesn't use the rediss:// scheme.
+func TLSConfig(c *tls.Config) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, tlsConfigKey{}, c)
+	}
+}
@@ -0,0 +1,598 @@
+// Package store implements a redis backed store
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultDatabase is the namespace that the redis store
+	// will use if no namespace is provided.
+	DefaultDatabase = "micro"
+	// DefaultTable when none is specified
+	DefaultTable = "micro"
+	// DefaultAddress of the redis server
+	DefaultAddress = "localhost:6379"
+
+	// number of keys requested per SCAN call
+	scanCount int64 = 1000
+	// characters which have special meaning in a SCAN MATCH pattern
+	globReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	// persist removes the expiry of a key, PERSIST alone doesn't tell a
+	// missing key apart from one which doesn't expire
+	persist = redis.NewScript(`if redis.call("EXISTS", KEYS[1]) == 0 then return 0 end
+redis.call("PERSIST", KEYS[1])
+return 1`)
+)
+
+type redisStore struct {
+	options store.Options
+	client  *redis.Client
+}
+
+// record stored by us
+type record struct {
+	Key      string                 `json:"key"`
+	Value    []byte                 `json:"value"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// NewStore returns a new micro Store backed by redis
+func NewStore(opts ...store.Option) store.Store {
+	s := &redisStore{
+		options: store.Options{
+			Database: DefaultDatabase,
+			Table:    DefaultTable,
+		},
+	}
+
+	// best-effort configure the store
+	if err := s.Init(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return s
+}
+
+func (r *redisStore) configure() error {
+	addr := DefaultAddress
+	if len(r.options.Nodes) > 0 {
+		addr = r.options.Nodes[0]
+	}
+
+	var opts *redis.Options
+	if strings.HasPrefix(addr, "redis://") || strings.HasPrefix(addr, "rediss://") {
+		o, err := redis.ParseURL(addr)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing redis address")
+		}
+		opts = o
+	} else {
+		opts = &redis.Options{Addr: addr}
+	}
+
+	if r.options.Context != nil {
+		if c, ok := r.options.Context.Value(tlsConfigKey{}).(*tls.Config); ok {
+			opts.TLSConfig = c
+		}
+	}
+
+	if r.client != nil {
+		r.client.Close()
+	}
+	r.client = redis.NewClient(opts)
+
+	return r.client.Ping(context.Background()).Err()
+}
+
+// prefix returns the key prefix for the given database and table
+func (r *redisStore) prefix(database, table string) string {
+	if len(database) == 0 {
+		database = r.options.Database
+	}
+	if len(table) == 0 {
+		table = r.options.Table
+	}
+	return database + ":" + table + ":"
+}
+
+func (r *redisStore) get(prefix string, keys []string) ([]*store.Record, error) {
+	ctx := context.Background()
+
+	// fetch the values and the remaining ttl in a single round trip
+	pipe := r.client.Pipeline()
+	gets := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, k := range keys {
+		gets[i] = pipe.Get(ctx, prefix+k)
+		ttls[i] = pipe.PTTL(ctx, prefix+k)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var records []*store.Record
+
+	for i := range keys {
+		b, err := gets[i].Bytes()
+		if err == redis.Nil {
+			// the key expired or was deleted since it was listed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var rec record
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return nil, err
+		}
+
+		newRecord := &store.Record{
+			Key:      rec.Key,
+			Value:    rec.Value,
+			Metadata: make(map[string]interface{}),
+		}
+		for k, v := range rec.Metadata {
+			newRecord.Metadata[k] = v
+		}
+		if ttl := ttls[i].Val(); ttl > 0 {
+			newRecord.Expiry = ttl
+		}
+
+		records = append(records, newRecord)
+	}
+
+	return records, nil
+}
+
+// scanPattern returns the SCAN MATCH pattern of the keys with the prefix and suffix, escaping
+// the characters of them which have special meaning in a pattern so they're matched literally
+func scanPattern(prefix, suffix string) string {
+	return globReplacer.Replace(prefix) + "*" + globReplacer.Replace(suffix)
+}
+
+func (r *redisStore) list(prefix string, order store.Order, limit, offset uint, prefixFilter, suffixFilter string) ([]string, error) {
+	ctx := context.Background()
+	match := scanPattern(prefix+prefixFilter, suffixFilter)
+
+	var keys []string
+	var cursor uint64
+
+	for {
+		res, next, err := r.client.Scan(ctx, cursor, match, scanCount).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range res {
+			keys = append(keys, strings.TrimPrefix(k, prefix))
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	// SCAN may return the same key more than once
+	sort.Strings(keys)
+	uniq := keys[:0]
+	for i, k := range keys {
+		if i > 0 && keys[i-1] == k {
+			continue
+		}
+		uniq = append(uniq, k)
+	}
+	keys = uniq
+
+	if order == store.OrderDesc {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	if offset > 0 {
+		// offset is greater than the keys we have
+		if int(offset) >= len(keys) {
+			return nil, nil
+		}
+
+		// otherwise set the offset for the keys
+		keys = keys[offset:]
+	}
+
+	// check key limit
+	if limit > 0 && int(limit) < len(keys) {
+		keys = keys[:limit]
+	}
+
+	return keys, nil
+}
+
+func (r *redisStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&r.options)
+	}
+	return r.configure()
+}
+
+func (r *redisStore) Options() store.Options {
+	return r.options
+}
+
+func (r *redisStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	readOpts := store.ReadOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&readOpts)
+	}
+
+	// earlier revisions of records aren't kept
+	if readOpts.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys aren't paged through with a cursor
+	if readOpts.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	// keys matched against a pattern are read like a prefix
+	if readOpts.Match != nil && !readOpts.Suffix {
+		readOpts.Prefix = true
+	}
+
+	if len(readOpts.Where) > 0 || readOpts.OrderBy == store.OrderByExpiry || readOpts.Match != nil {
+		return r.where(key, readOpts)
+	}
+
+	prefix := r.prefix(readOpts.Database, readOpts.Table)
+
+	if !readOpts.Prefix && !readOpts.Suffix {
+		recs, err := r.get(prefix, []string{key})
+		if err != nil {
+			return nil, err
+		}
+		if len(recs) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return recs, nil
+	}
+
+	prefixFilter := ""
+	if readOpts.Prefix {
+		prefixFilter = key
+	}
+	suffixFilter := ""
+	if readOpts.Suffix {
+		suffixFilter = key
+	}
+
+	keys, err := r.list(prefix, readOpts.Order, readOpts.Limit, readOpts.Offset, prefixFilter, suffixFilter)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return []*store.Record{}, nil
+	}
+
+	return r.get(prefix, keys)
+}
+
+// where reads the records without the metadata values and then filters them, as
+// redis can't query by metadata. The limit and offset are applied once filtered.
+func (r *redisStore) where(key string, options store.ReadOptions) ([]*store.Record, error) {
+	opts := []store.ReadOption{
+		store.ReadFrom(options.Database, options.Table),
+		store.ReadOrder(options.Order),
+	}
+	if options.Prefix {
+		opts = append(opts, store.ReadPrefix())
+	}
+	if options.Suffix {
+		opts = append(opts, store.ReadSuffix())
+	}
+
+	var match *regexp.Regexp
+	if options.Match != nil {
+		var err error
+		if match, err = options.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	recs, err := r.Read(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*store.Record{}
+	for _, r := range recs {
+		if r.Match(options.Where) && (match == nil || match.MatchString(r.Key)) {
+			results = append(results, r)
+		}
+	}
+	if options.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, options.Order)
+	}
+
+	if !options.Prefix && !options.Suffix {
+		if len(results) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return results, nil
+	}
+
+	if options.Offset > 0 {
+		// offset is greater than the records we have
+		if int(options.Offset) >= len(results) {
+			return []*store.Record{}, nil
+		}
+		results = results[options.Offset:]
+	}
+
+	if options.Limit > 0 && int(options.Limit) < len(results) {
+		results = results[:options.Limit]
+	}
+
+	return results, nil
+}
+
+func (r *redisStore) Write(rec *store.Record, opts ...store.WriteOption) error {
+	if r.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	// records aren't versioned
+	if writeOpts.IfVersion > 0 {
+		return store.ErrNotSupported
+	}
+
+	b, err := json.Marshal(&record{
+		Key:      rec.Key,
+		Value:    rec.Value,
+		Metadata: rec.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := r.prefix(writeOpts.Database, writeOpts.Table)
+
+	if writeOpts.IfNotExists {
+		ok, err := r.client.SetNX(context.Background(), prefix+rec.Key, b, rec.Expiry).Result()
+		if err != nil {
+			return errors.Wrap(err, "Couldn't write record "+rec.Key)
+		}
+		if !ok {
+			return store.ErrConflict
+		}
+		return nil
+	}
+
+	if err := r.client.Set(context.Background(), prefix+rec.Key, b, rec.Expiry).Err(); err != nil {
+		return errors.Wrap(err, "Couldn't write record "+rec.Key)
+	}
+
+	return nil
+}
+
+// WriteMany writes the records in a single MULTI/EXEC transaction
+func (r *redisStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if r.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	// records aren't versioned, and a MULTI/EXEC transaction isn't aborted by a key which exists
+	if writeOpts.IfVersion > 0 || writeOpts.IfNotExists {
+		return store.ErrNotSupported
+	}
+
+	ctx := context.Background()
+	prefix := r.prefix(writeOpts.Database, writeOpts.Table)
+
+	pipe := r.client.TxPipeline()
+	for _, rec := range recs {
+		b, err := json.Marshal(&record{
+			Key:      rec.Key,
+			Value:    rec.Value,
+			Metadata: rec.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, prefix+rec.Key, b, rec.Expiry)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "Couldn't write records")
+	}
+
+	return nil
+}
+
+// Touch sets the expiry of the key, the value isn't read or rewritten
+func (r *redisStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if r.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	k := r.prefix(options.Database, options.Table) + key
+
+	var ok bool
+	var err error
+	if expiry > 0 {
+		ok, err = r.client.PExpire(ctx, k, expiry).Result()
+	} else {
+		var n int
+		n, err = persist.Run(ctx, r.client, []string{k}).Int()
+		ok = n == 1
+	}
+	if err != nil {
+		return errors.Wrap(err, "Couldn't touch record "+key)
+	}
+	if !ok {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (r *redisStore) Delete(key string, opts ...store.DeleteOption) error {
+	if r.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOpts store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOpts)
+	}
+
+	prefix := r.prefix(deleteOpts.Database, deleteOpts.Table)
+
+	if !deleteOpts.Prefix && !deleteOpts.Suffix {
+		return r.client.Del(context.Background(), prefix+key).Err()
+	}
+
+	prefixFilter := ""
+	if deleteOpts.Prefix {
+		prefixFilter = key
+	}
+	suffixFilter := ""
+	if deleteOpts.Suffix {
+		suffixFilter = key
+	}
+
+	keys, err := r.list(prefix, store.OrderAsc, 0, 0, prefixFilter, suffixFilter)
+	if err != nil {
+		return err
+	}
+	return r.del(prefix, keys)
+}
+
+// DeleteMany deletes the records with a single DEL command
+func (r *redisStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if r.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOpts store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOpts)
+	}
+
+	return r.del(r.prefix(deleteOpts.Database, deleteOpts.Table), keys)
+}
+
+func (r *redisStore) del(prefix string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ks := make([]string, len(keys))
+	for i, k := range keys {
+		ks[i] = prefix + k
+	}
+
+	if err := r.client.Del(context.Background(), ks...).Err(); err != nil {
+		return errors.Wrap(err, "Couldn't delete records")
+	}
+	return nil
+}
+
+func (r *redisStore) List(opts ...store.ListOption) ([]string, error) {
+	listOpts := store.ListOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&listOpts)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(listOpts.Delimiter) > 0 {
+		return store.ListDelimited(r, listOpts)
+	}
+	if listOpts.Match != nil {
+		return store.ListMatched(r, listOpts)
+	}
+
+	if listOpts.Cursor != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	if listOpts.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := r.where(listOpts.Prefix, store.ReadOptions{
+			Database: listOpts.Database,
+			Table:    listOpts.Table,
+			Prefix:   true,
+			Order:    listOpts.Order,
+			OrderBy:  listOpts.OrderBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys := []string{}
+		for _, rec := range recs {
+			if strings.HasSuffix(rec.Key, listOpts.Suffix) {
+				keys = append(keys, rec.Key)
+			}
+		}
+		if listOpts.Offset > 0 {
+			// offset is greater than the keys we have
+			if int(listOpts.Offset) >= len(keys) {
+				return []string{}, nil
+			}
+			keys = keys[listOpts.Offset:]
+		}
+		if listOpts.Limit > 0 && int(listOpts.Limit) < len(keys) {
+			keys = keys[:listOpts.Limit]
+		}
+		return keys, nil
+	}
+
+	prefix := r.prefix(listOpts.Database, listOpts.Table)
+	return r.list(prefix, listOpts.Order, listOpts.Limit, listOpts.Offset, listOpts.Prefix, listOpts.Suffix)
+}
+
+// Healthy pings the redis server
+func (r *redisStore) Healthy(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisStore) Close() error {
+	if r.client != nil {
+		return r.client.Close()
+	}
+	return nil
+}
+
+func (r *redisStore) String() string {
+	return "redis"
+}
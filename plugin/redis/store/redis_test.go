@@ -0,0 +1,30 @@
+package store
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+const addr = "localhost:6379"
+
+func TestScanPattern(t *testing.T) {
+	assert.Equal(t, `db:table:foo*`, scanPattern("db:table:foo", ""))
+	assert.Equal(t, `db:table:a\*b\?c\[d\]\\e*f\*`, scanPattern(`db:table:a*b?c[d]\e`, "f*"))
+}
+
+func TestConformance(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		t.Skipf("Redis isn't reachable at %s: %v", addr, err)
+	}
+	conn.Close()
+
+	storetest.Conformance(t, func() store.Store {
+		return NewStore(store.Nodes(addr))
+	})
+}
@@ -78,11 +78,12 @@ func (r *redisStream) Publish(topic string, msg interface{}, opts ...events.Publ
 
 	// construct the event
 	event := &events.Event{
-		ID:        uuid.New().String(),
-		Topic:     topic,
-		Timestamp: options.Timestamp,
-		Metadata:  options.Metadata,
-		Payload:   payload,
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Timestamp:   options.Timestamp,
+		Metadata:    options.Metadata,
+		Payload:     payload,
+		OrderingKey: options.OrderingKey,
 	}
 
 	// serialize the event to bytes
@@ -184,7 +185,7 @@ func (r *redisStream) consumeWithGroup(topic, group string, options events.Consu
 				return
 			}
 			msgs := claimCmd.Val()
-			if err := r.processMessages(msgs, ch, topic, group, options.AutoAck, options.RetryLimit); err != nil {
+			if err := r.processMessages(msgs, ch, topic, group, options.AutoAck, options.RetryLimit, options.DeadLetterTopic); err != nil {
 				logger.Errorf("Error reprocessing message %s", err)
 				return
 			}
@@ -221,7 +222,7 @@ func (r *redisStream) consumeWithGroup(topic, group string, options events.Consu
 				continue
 			}
 
-			if err := r.processMessages(sl[0].Messages, ch, topic, group, options.AutoAck, options.RetryLimit); err != nil {
+			if err := r.processMessages(sl[0].Messages, ch, topic, group, options.AutoAck, options.RetryLimit, options.DeadLetterTopic); err != nil {
 				logger.Errorf("Error processing message %s", err)
 				return
 			}
@@ -255,7 +256,7 @@ func isTimeoutError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), errMsgPoolTimeout)
 }
 
-func (r *redisStream) processMessages(msgs []redis.XMessage, ch chan events.Event, topic, group string, autoAck bool, retryLimit int) error {
+func (r *redisStream) processMessages(msgs []redis.XMessage, ch chan events.Event, topic, group string, autoAck bool, retryLimit int, deadLetter string) error {
 	for _, v := range msgs {
 		vid := v.ID
 		evBytes := v.Values["event"]
@@ -297,6 +298,9 @@ func (r *redisStream) processMessages(msgs []redis.XMessage, ch chan events.Even
 					r.Lock()
 					delete(r.attempts, attemptsKey)
 					r.Unlock()
+					if len(deadLetter) > 0 {
+						return events.DeadLetter(r, deadLetter, ev)
+					}
 					return nil
 				}
 				bytes, err := json.Marshal(ev)
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	sthree "github.com/aws/aws-sdk-go/service/s3"
 	store2 "github.com/micro/micro/v3/service/events/store"
@@ -28,11 +27,7 @@ func NewBackup(opts ...Option) store2.Backup {
 		o(&options)
 	}
 
-	sess := session.Must(session.NewSession(&aws.Config{
-		Endpoint:    &options.Endpoint,
-		Region:      &options.Region,
-		Credentials: credentials.NewStaticCredentials(options.AccessKeyID, options.SecretAccessKey, ""),
-	}))
+	sess := session.Must(newSession(options))
 	client := sthree.New(sess)
 
 	return &backupImpl{client: client, opts: options}
@@ -120,7 +115,7 @@ dateLoop:
 		for num := 1; num <= rollBackNum; num++ {
 			if _, err := i.client.DeleteObject(&sthree.DeleteObjectInput{
 				Bucket: aws.String(i.opts.Bucket),
-				Key:    aws.String(fmt.Sprintf("micro/eventsBackup/%s-%s", d, num)),
+				Key:    aws.String(fmt.Sprintf("micro/eventsBackup/%s-%d", d, num)),
 			}); err != nil {
 				logger.Errorf("Error during rollback %s", err)
 			}
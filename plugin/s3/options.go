@@ -16,6 +16,10 @@ package s3
 
 import "crypto/tls"
 
+// DefaultPartSize is the size of the parts blobs are uploaded in if it isn't set, which is
+// the smallest part S3 accepts
+const DefaultPartSize = 5 * 1024 * 1024
+
 // Options used to configure the s3 blob store
 type Options struct {
 	Bucket          string
@@ -24,6 +28,8 @@ type Options struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	Secure          bool
+	PathStyle       bool
+	PartSize        int64
 	TLSConfig       *tls.Config
 }
 
@@ -44,7 +50,9 @@ func Region(r string) Option {
 	}
 }
 
-// Credentials sets the AccessKeyID and SecretAccessKey options
+// Credentials sets the AccessKeyID and SecretAccessKey options. If they aren't set the
+// credentials are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY env vars, the
+// shared credentials file or the instance's role.
 func Credentials(id, secret string) Option {
 	return func(o *Options) {
 		o.AccessKeyID = id
@@ -72,3 +80,20 @@ func TLSConfig(c *tls.Config) Option {
 		o.TLSConfig = c
 	}
 }
+
+// PathStyle addresses buckets in the path of the URL rather than as a subdomain of the
+// endpoint, which S3 compatible stores such as MinIO need
+func PathStyle() Option {
+	return func(o *Options) {
+		o.PathStyle = true
+	}
+}
+
+// PartSize sets the size of the parts blobs larger than it are uploaded in, so only one part
+// of a blob is held in memory at a time. S3 requires every part but the last to be at least
+// 5MiB.
+func PartSize(n int64) Option {
+	return func(o *Options) {
+		o.PartSize = n
+	}
+}
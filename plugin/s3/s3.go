@@ -17,14 +17,18 @@ package s3
 import (
 	"bytes"
 	"io"
+	"net/http"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	sthree "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/store"
 )
@@ -36,7 +40,34 @@ func cleanKey(s string) string {
 	return doubleSlash.ReplaceAllLiteralString(removeCol.ReplaceAllLiteralString(s, "/"), "/")
 }
 
-// NewBlobStore returns an initialized s3 blob store
+// newSession returns a session for the endpoint of the options
+func newSession(options Options) (*session.Session, error) {
+	cfg := &aws.Config{
+		Region:           aws.String(options.Region),
+		DisableSSL:       aws.Bool(!options.Secure),
+		S3ForcePathStyle: aws.Bool(options.PathStyle),
+	}
+	if len(options.Endpoint) > 0 {
+		cfg.Endpoint = aws.String(options.Endpoint)
+	}
+	// without static credentials the default chain is used, e.g. the env vars
+	if len(options.AccessKeyID) > 0 || len(options.SecretAccessKey) > 0 {
+		cfg.Credentials = credentials.NewStaticCredentials(options.AccessKeyID, options.SecretAccessKey, "")
+	}
+	if options.TLSConfig != nil {
+		cfg.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: options.TLSConfig,
+			},
+		}
+	}
+	return session.NewSession(cfg)
+}
+
+// NewBlobStore returns an initialized s3 blob store. Blobs are written to the bucket set with
+// the Bucket option, under a directory for their namespace, or if it isn't set to a bucket
+// for each namespace.
 func NewBlobStore(opts ...Option) (store.BlobStore, error) {
 	// parse the options
 	options := Options{Secure: true}
@@ -44,22 +75,47 @@ func NewBlobStore(opts ...Option) (store.BlobStore, error) {
 		o(&options)
 	}
 
-	sess := session.Must(session.NewSession(&aws.Config{
-		Endpoint:    &options.Endpoint,
-		Region:      &options.Region,
-		Credentials: credentials.NewStaticCredentials(options.AccessKeyID, options.SecretAccessKey, ""),
-	}))
-	client := sthree.New(sess)
+	sess, err := newSession(options)
+	if err != nil {
+		return nil, err
+	}
 
 	// return the blob store
-	return &s3{client, &options}, nil
+	return &s3{sthree.New(sess), &options}, nil
 }
 
 type s3 struct {
-	client  *sthree.S3
+	client  s3iface.S3API
 	options *Options
 }
 
+// object returns the bucket and name of the object the key of the namespace is written to
+func (s *s3) object(namespace, key string) (string, string) {
+	if len(s.options.Bucket) > 0 {
+		return s.options.Bucket, filepath.Join(namespace, key)
+	}
+	return namespace, key
+}
+
+// hasCode returns true if the error is an s3 error with one of the codes
+func hasCode(err error, codes ...string) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	for _, c := range codes {
+		if aerr.Code() == c {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotFound returns true if the error is for a missing object or bucket
+func isNotFound(err error) bool {
+	return hasCode(err, sthree.ErrCodeNoSuchKey, sthree.ErrCodeNoSuchBucket, "NotFound")
+}
+
 func (s *s3) Read(key string, opts ...store.BlobOption) (io.Reader, error) {
 	// validate the key
 	if len(key) == 0 {
@@ -78,30 +134,19 @@ func (s *s3) Read(key string, opts ...store.BlobOption) (io.Reader, error) {
 		options.Namespace = "micro"
 	}
 
-	var err error
-	var res *sthree.GetObjectOutput
-	if len(s.options.Bucket) > 0 {
-		k := filepath.Join(options.Namespace, key)
-		res, err = s.client.GetObject(&sthree.GetObjectInput{
-			Bucket: &s.options.Bucket, // bucket name
-			Key:    &k,                // object name
-		})
-	} else {
-		res, err = s.client.GetObject(&sthree.GetObjectInput{
-			Bucket: &options.Namespace, // bucket name
-			Key:    &key,               // object name
-		})
-	}
-
-	if err != nil {
+	bucket, k := s.object(options.Namespace, key)
+	res, err := s.client.GetObject(&sthree.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(k),
+	})
+	if isNotFound(err) {
+		return nil, store.ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 
-	out := bytes.NewBuffer([]byte{})
-	_, err = io.Copy(out, res.Body)
-
-	// return the result
-	return out, nil
+	// the blob is streamed from the response, which is closed by the caller
+	return res.Body, nil
 }
 
 func (s *s3) Write(key string, blob io.Reader, opts ...store.BlobOption) error {
@@ -122,44 +167,100 @@ func (s *s3) Write(key string, blob io.Reader, opts ...store.BlobOption) error {
 		options.Namespace = "micro"
 	}
 
-	// if the bucket exists, write using the namespace as a filepath
-	buf := new(strings.Builder)
-	_, err := io.Copy(buf, blob)
-	if err != nil {
-		return err
-	}
 	acl := "private"
 	if options.Public {
 		acl = "public-read"
 	}
 	logger.Infof("Saving file %v with ACL %v into namespace %v", key, acl, options.Namespace)
-	if len(s.options.Bucket) > 0 {
-		k := filepath.Join(options.Namespace, key)
-		object := sthree.PutObjectInput{
-			Bucket:      &s.options.Bucket,
-			Key:         &k,
-			Body:        strings.NewReader(buf.String()),
-			ACL:         aws.String(acl),
-			ContentType: &options.ContentType,
+
+	// without a bucket each namespace has its own, which may not exist yet
+	if len(s.options.Bucket) == 0 {
+		_, err := s.client.CreateBucket(&sthree.CreateBucketInput{
+			Bucket: aws.String(options.Namespace),
+		})
+		if err != nil && !hasCode(err, sthree.ErrCodeBucketAlreadyOwnedByYou) {
+			return err
 		}
-		_, err := s.client.PutObject(&object)
+	}
+
+	bucket, k := s.object(options.Namespace, key)
+	var contentType *string
+	if len(options.ContentType) > 0 {
+		contentType = aws.String(options.ContentType)
+	}
+
+	// parts have to be seekable so the requests can be signed and retried, so they're read
+	// into memory one at a time
+	partSize := s.options.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(blob, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// blobs smaller than a part are put in a single request
+		_, err := s.client.PutObject(&sthree.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(k),
+			Body:        bytes.NewReader(buf[:n]),
+			ACL:         aws.String(acl),
+			ContentType: contentType,
+			Metadata:    aws.StringMap(options.Metadata),
+		})
+		return err
+	} else if err != nil {
 		return err
 	}
 
-	s.client.CreateBucket(&sthree.CreateBucketInput{
-		Bucket: &options.Namespace,
+	mp, err := s.client.CreateMultipartUpload(&sthree.CreateMultipartUploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(k),
+		ACL:         aws.String(acl),
+		ContentType: contentType,
+		Metadata:    aws.StringMap(options.Metadata),
 	})
+	if err != nil {
+		return err
+	}
+	abort := func(err error) error {
+		s.client.AbortMultipartUpload(&sthree.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(k),
+			UploadId: mp.UploadId,
+		})
+		return err
+	}
 
-	k := filepath.Join(options.Namespace, key)
-	object := sthree.PutObjectInput{
-		Bucket:      &s.options.Bucket,
-		Key:         &k,
-		Body:        strings.NewReader(buf.String()),
-		ACL:         aws.String(acl),
-		ContentType: &options.ContentType,
+	var parts []*sthree.CompletedPart
+	for num := int64(1); n > 0; num++ {
+		res, err := s.client.UploadPart(&sthree.UploadPartInput{
+			Bucket:     aws.String(bucket),
+			Key:        aws.String(k),
+			UploadId:   mp.UploadId,
+			PartNumber: aws.Int64(num),
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return abort(err)
+		}
+		parts = append(parts, &sthree.CompletedPart{ETag: res.ETag, PartNumber: aws.Int64(num)})
+
+		n, err = io.ReadFull(blob, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return abort(err)
+		}
 	}
-	_, err = s.client.PutObject(&object)
-	return err
+
+	_, err = s.client.CompleteMultipartUpload(&sthree.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(k),
+		UploadId:        mp.UploadId,
+		MultipartUpload: &sthree.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return abort(err)
+	}
+	return nil
 }
 
 func (s *s3) Delete(key string, opts ...store.BlobOption) error {
@@ -180,24 +281,74 @@ func (s *s3) Delete(key string, opts ...store.BlobOption) error {
 		options.Namespace = "micro"
 	}
 
-	if len(s.options.Bucket) > 0 {
-		k := filepath.Join(options.Namespace, key) // object name
-		_, err := s.client.DeleteObject(&sthree.DeleteObjectInput{
-			Bucket: &s.options.Bucket, // bucket name
-			Key:    &k,
-		})
-		return err
-	}
-
-	k := filepath.Join(options.Namespace, key) // object name
+	bucket, k := s.object(options.Namespace, key)
 	_, err := s.client.DeleteObject(&sthree.DeleteObjectInput{
-		Bucket: &options.Namespace, // bucket name
-		Key:    &k,
+		Bucket: aws.String(bucket),
+		Key:    aws.String(k),
 	})
+	// deleting from a namespace which has no bucket is a no-op, like deleting a missing key
+	if isNotFound(err) {
+		return nil
+	}
 	return err
 }
 
 func (s *s3) List(opts ...store.BlobListOption) ([]string, error) {
+	objs, keyPrefix, err := s.list(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(objs))
+	for i, obj := range objs {
+		// return the key without the prefix
+		keys[i] = strings.TrimPrefix(aws.StringValue(obj.Key), keyPrefix)
+	}
+	// return the result
+	return keys, nil
+}
+
+// ListInfo returns the blobs with their attributes. Listing objects doesn't return their content
+// type or metadata, so each object is also requested. S3 returns the metadata keys canonicalized,
+// e.g. foo-bar is returned as Foo-Bar.
+func (s *s3) ListInfo(opts ...store.BlobListOption) ([]*store.BlobInfo, error) {
+	objs, keyPrefix, err := s.list(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make([]*store.BlobInfo, 0, len(objs))
+	for _, obj := range objs {
+		head, err := s.client.HeadObject(&sthree.HeadObjectInput{
+			Bucket: obj.bucket,
+			Key:    obj.Key,
+		})
+		if isNotFound(err) {
+			// the object was deleted since it was listed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, &store.BlobInfo{
+			Key:         strings.TrimPrefix(aws.StringValue(obj.Key), keyPrefix),
+			Size:        aws.Int64Value(head.ContentLength),
+			ContentType: aws.StringValue(head.ContentType),
+			Metadata:    aws.StringValueMap(head.Metadata),
+			Modified:    aws.TimeValue(head.LastModified),
+		})
+	}
+	return blobs, nil
+}
+
+// object is an object listed in a bucket
+type object struct {
+	*sthree.Object
+	bucket *string
+}
+
+// list returns the objects of the namespace which match, and the prefix of their keys which isn't
+// part of the key of the blob
+func (s *s3) list(opts ...store.BlobListOption) ([]object, string, error) {
 	// parse the options
 	var options store.BlobListOptions
 	for _, o := range opts {
@@ -207,43 +358,87 @@ func (s *s3) List(opts ...store.BlobListOption) ([]string, error) {
 		options.Namespace = "micro"
 	}
 
-	keys := []string{}
-	continuation := ""
+	// with a bucket the keys of the namespace are in its directory
+	bucket, prefix := options.Namespace, options.Prefix
 	keyPrefix := ""
+	if len(s.options.Bucket) > 0 {
+		keyPrefix = options.Namespace + "/"
+		bucket, prefix = s.options.Bucket, keyPrefix+options.Prefix
+	}
+
+	objs := []object{}
+	inp := &sthree.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
 	for {
-		var err error
-		var inp *sthree.ListObjectsV2Input
-		if len(s.options.Bucket) > 0 {
-			k := filepath.Join(options.Namespace, options.Prefix)
-			keyPrefix = options.Namespace
-			inp = &sthree.ListObjectsV2Input{
-				Bucket: &s.options.Bucket, // bucket name
-				Prefix: &k,                // prefix
-			}
-		} else {
-			inp = &sthree.ListObjectsV2Input{
-				Bucket: &options.Namespace, // bucket name
-				Prefix: &options.Prefix,    // object name
-			}
-		}
-		inp.SetContinuationToken(continuation)
 		res, err := s.client.ListObjectsV2(inp)
-		if err != nil {
-			return nil, err
+		if isNotFound(err) {
+			return objs, keyPrefix, nil
+		} else if err != nil {
+			return nil, "", err
 		}
 		for _, obj := range res.Contents {
-			// return the key without the prefix
-			key := *obj.Key
-			if len(keyPrefix) > 0 {
-				key = key[len(keyPrefix)+1:]
-			}
-			keys = append(keys, key)
+			objs = append(objs, object{obj, inp.Bucket})
 		}
-		if !*res.IsTruncated {
+		if !aws.BoolValue(res.IsTruncated) {
 			break
 		}
-		continuation = *res.ContinuationToken
+		inp.ContinuationToken = res.NextContinuationToken
 	}
-	// return the result
-	return keys, nil
+	return objs, keyPrefix, nil
+}
+
+func (s *s3) PresignGet(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	// validate the key
+	if len(key) == 0 {
+		return "", store.ErrMissingKey
+	}
+
+	// make the key safe for use with s3
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	bucket, k := s.object(options.Namespace, key)
+	req, _ := s.client.GetObjectRequest(&sthree.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(k),
+	})
+	return req.Presign(ttl)
+}
+
+func (s *s3) PresignPut(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	// validate the key
+	if len(key) == 0 {
+		return "", store.ErrMissingKey
+	}
+
+	// make the key safe for use with s3
+	key = cleanKey(key)
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+
+	// the bucket of the namespace isn't created here, so without a bucket set the
+	// namespace must have been written to before a url to upload to it is signed
+	bucket, k := s.object(options.Namespace, key)
+	req, _ := s.client.PutObjectRequest(&sthree.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(k),
+	})
+	return req.Presign(ttl)
 }
@@ -17,9 +17,18 @@ package s3
 import (
 	"bytes"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	sthree "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/micro/micro/v3/service/store"
 	"github.com/stretchr/testify/assert"
 )
@@ -140,3 +149,260 @@ func TestBlobStore(t *testing.T) {
 		assert.Nil(t, res, "Result should be nil")
 	})
 }
+
+// fakeS3 keeps objects in memory, listing them a page of two at a time
+type fakeS3 struct {
+	s3iface.S3API
+	buckets map[string]map[string][]byte
+	// the parts of the multipart uploads which are in progress
+	uploads map[string][][]byte
+	// the size of the largest part uploaded
+	largest int
+	// the objects which were put, with their attributes
+	puts map[string]*sthree.PutObjectInput
+}
+
+func (f *fakeS3) bucket(name *string) (map[string][]byte, error) {
+	b, ok := f.buckets[aws.StringValue(name)]
+	if !ok {
+		return nil, awserr.New(sthree.ErrCodeNoSuchBucket, "no such bucket", nil)
+	}
+	return b, nil
+}
+
+func (f *fakeS3) CreateBucket(in *sthree.CreateBucketInput) (*sthree.CreateBucketOutput, error) {
+	if _, ok := f.buckets[*in.Bucket]; ok {
+		return nil, awserr.New(sthree.ErrCodeBucketAlreadyOwnedByYou, "bucket exists", nil)
+	}
+	f.buckets[*in.Bucket] = make(map[string][]byte)
+	return &sthree.CreateBucketOutput{}, nil
+}
+
+func (f *fakeS3) PutObject(in *sthree.PutObjectInput) (*sthree.PutObjectOutput, error) {
+	b, err := f.bucket(in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := ioutil.ReadAll(in.Body)
+	b[*in.Key] = v
+	if f.puts == nil {
+		f.puts = make(map[string]*sthree.PutObjectInput)
+	}
+	f.puts[*in.Bucket+"/"+*in.Key] = in
+	return &sthree.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) CreateMultipartUpload(in *sthree.CreateMultipartUploadInput) (*sthree.CreateMultipartUploadOutput, error) {
+	if _, err := f.bucket(in.Bucket); err != nil {
+		return nil, err
+	}
+	id := strconv.Itoa(len(f.uploads))
+	f.uploads[id] = nil
+	return &sthree.CreateMultipartUploadOutput{UploadId: aws.String(id)}, nil
+}
+
+func (f *fakeS3) UploadPart(in *sthree.UploadPartInput) (*sthree.UploadPartOutput, error) {
+	v, _ := ioutil.ReadAll(in.Body)
+	if len(v) > f.largest {
+		f.largest = len(v)
+	}
+	f.uploads[*in.UploadId] = append(f.uploads[*in.UploadId], v)
+	return &sthree.UploadPartOutput{ETag: aws.String(strconv.FormatInt(*in.PartNumber, 10))}, nil
+}
+
+func (f *fakeS3) CompleteMultipartUpload(in *sthree.CompleteMultipartUploadInput) (*sthree.CompleteMultipartUploadOutput, error) {
+	parts := f.uploads[*in.UploadId]
+	if len(parts) != len(in.MultipartUpload.Parts) {
+		return nil, awserr.New("InvalidPart", "missing parts", nil)
+	}
+	f.buckets[*in.Bucket][*in.Key] = bytes.Join(parts, nil)
+	delete(f.uploads, *in.UploadId)
+	return &sthree.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(in *sthree.GetObjectInput) (*sthree.GetObjectOutput, error) {
+	b, err := f.bucket(in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := b[*in.Key]
+	if !ok {
+		return nil, awserr.New(sthree.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &sthree.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(v))}, nil
+}
+
+func (f *fakeS3) HeadObject(in *sthree.HeadObjectInput) (*sthree.HeadObjectOutput, error) {
+	b, err := f.bucket(in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := b[*in.Key]
+	if !ok {
+		return nil, awserr.New("NotFound", "not found", nil)
+	}
+	out := &sthree.HeadObjectOutput{ContentLength: aws.Int64(int64(len(v))), LastModified: aws.Time(time.Unix(1600000000, 0))}
+	if put, ok := f.puts[*in.Bucket+"/"+*in.Key]; ok {
+		out.ContentType = put.ContentType
+		out.Metadata = put.Metadata
+	}
+	return out, nil
+}
+
+func (f *fakeS3) DeleteObject(in *sthree.DeleteObjectInput) (*sthree.DeleteObjectOutput, error) {
+	b, err := f.bucket(in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	delete(b, *in.Key)
+	return &sthree.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2(in *sthree.ListObjectsV2Input) (*sthree.ListObjectsV2Output, error) {
+	b, err := f.bucket(in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for k := range b {
+		if strings.HasPrefix(k, aws.StringValue(in.Prefix)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	start, _ := strconv.Atoi(aws.StringValue(in.ContinuationToken))
+	keys = keys[start:]
+	out := &sthree.ListObjectsV2Output{IsTruncated: aws.Bool(len(keys) > 2)}
+	if len(keys) > 2 {
+		keys = keys[:2]
+		out.NextContinuationToken = aws.String(strconv.Itoa(start + 2))
+	}
+	for _, k := range keys {
+		out.Contents = append(out.Contents, &sthree.Object{Key: aws.String(k)})
+	}
+	return out, nil
+}
+
+func TestObjects(t *testing.T) {
+	for _, bucket := range []string{"", "blobs"} {
+		t.Run("Bucket"+bucket, func(t *testing.T) {
+			f := &fakeS3{buckets: map[string]map[string][]byte{"blobs": {}}, uploads: map[string][][]byte{}}
+			blob := &s3{client: f, options: &Options{Bucket: bucket}}
+
+			_, err := blob.Read("hello")
+			assert.Equal(t, store.ErrNotFound, err)
+			assert.NoError(t, blob.Delete("hello", store.BlobNamespace("bar")))
+
+			for _, k := range []string{"a/1", "a/2", "a/3", "b/1"} {
+				assert.NoError(t, blob.Write(k, bytes.NewBufferString(k)))
+			}
+			assert.NoError(t, blob.Write("a/1", bytes.NewBufferString("other"), store.BlobNamespace("foo")))
+
+			val, err := blob.Read("a/1")
+			assert.NoError(t, err)
+			b, _ := ioutil.ReadAll(val)
+			assert.Equal(t, "a/1", string(b))
+
+			keys, err := blob.List(store.BlobListPrefix("a/"))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"a/1", "a/2", "a/3"}, keys)
+
+			keys, err = blob.List(store.BlobListNamespace("foo"))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"a/1"}, keys)
+
+			keys, err = blob.List(store.BlobListNamespace("bar"))
+			assert.NoError(t, err)
+			assert.Empty(t, keys)
+
+			assert.NoError(t, blob.Delete("a/1"))
+			_, err = blob.Read("a/1")
+			assert.Equal(t, store.ErrNotFound, err)
+			_, err = blob.Read("a/1", store.BlobNamespace("foo"))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListInfo(t *testing.T) {
+	f := &fakeS3{buckets: map[string]map[string][]byte{"blobs": {}}, uploads: map[string][][]byte{}}
+	blob := &s3{client: f, options: &Options{Bucket: "blobs"}}
+
+	assert.NoError(t, blob.Write("index.html", bytes.NewBufferString("<html>"),
+		store.BlobContentType("text/html"), store.BlobMetadata(map[string]string{"Foo": "bar"})))
+	assert.NoError(t, blob.Write("raw", bytes.NewBufferString("abc")))
+
+	blobs, err := blob.ListInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, []*store.BlobInfo{
+		{
+			Key:         "index.html",
+			Size:        int64(len("<html>")),
+			ContentType: "text/html",
+			Metadata:    map[string]string{"Foo": "bar"},
+			Modified:    time.Unix(1600000000, 0),
+		},
+		{
+			Key:      "raw",
+			Size:     3,
+			Metadata: map[string]string{},
+			Modified: time.Unix(1600000000, 0),
+		},
+	}, blobs)
+
+	blobs, err = blob.ListInfo(store.BlobListNamespace("bar"))
+	assert.NoError(t, err)
+	assert.Empty(t, blobs)
+}
+
+func TestMultipart(t *testing.T) {
+	f := &fakeS3{buckets: map[string]map[string][]byte{"blobs": {}}, uploads: map[string][][]byte{}}
+	blob := &s3{client: f, options: &Options{Bucket: "blobs", PartSize: 4}}
+
+	// blobs smaller than a part aren't uploaded in parts
+	assert.NoError(t, blob.Write("small", bytes.NewBufferString("abc")))
+	assert.Zero(t, f.largest)
+
+	assert.NoError(t, blob.Write("large", bytes.NewBufferString("abcdefghij")))
+	assert.Equal(t, 4, f.largest)
+	assert.Empty(t, f.uploads)
+
+	val, err := blob.Read("large")
+	assert.NoError(t, err)
+	b, _ := ioutil.ReadAll(val)
+	assert.Equal(t, "abcdefghij", string(b))
+}
+
+func TestPresign(t *testing.T) {
+	blob, err := NewBlobStore(
+		Region("us-east-1"),
+		Endpoint("localhost:9000"),
+		Credentials("access", "secret"),
+		Bucket("blobs"),
+		PathStyle(),
+	)
+	assert.NoError(t, err)
+	p, ok := blob.(store.Presigner)
+	assert.True(t, ok, "Expected the blob store to presign urls")
+
+	_, err = p.PresignGet("", time.Minute)
+	assert.Equal(t, store.ErrMissingKey, err)
+
+	// signing doesn't call s3, so the urls can be checked offline
+	get, err := p.PresignGet("build://name:version", time.Minute, store.BlobNamespace("foo"))
+	assert.NoError(t, err)
+	u, err := url.Parse(get)
+	assert.NoError(t, err)
+	assert.Equal(t, "/blobs/foo/build/name/version", u.Path)
+	assert.Equal(t, "60", u.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+
+	put, err := p.PresignPut("blob", time.Hour, store.BlobNamespace("foo"))
+	assert.NoError(t, err)
+	u, err = url.Parse(put)
+	assert.NoError(t, err)
+	assert.Equal(t, "/blobs/foo/blob", u.Path)
+	assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+	assert.NotEqual(t, get, put)
+}
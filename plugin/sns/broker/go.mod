@@ -0,0 +1,12 @@
+module github.com/micro/micro/plugin/sns/broker/v3
+
+go 1.15
+
+require (
+	github.com/aws/aws-sdk-go v1.23.0
+	github.com/google/uuid v1.1.2
+	github.com/micro/micro/v3 v3.3.1-0.20210803122146-2a2fa437600d
+	github.com/stretchr/testify v1.8.0
+)
+
+replace github.com/micro/micro/v3 => ../../..
@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/broker"
+)
+
+type regionKey struct{}
+type endpointKey struct{}
+type credentialsKey struct{}
+type waitTimeKey struct{}
+type visibilityTimeoutKey struct{}
+
+type credentials struct {
+	id     string
+	secret string
+}
+
+// Region sets the AWS region of the topics and queues, if not set the region is loaded from the
+// env vars, e.g. AWS_REGION
+func Region(region string) broker.Option {
+	return setBrokerOption(regionKey{}, region)
+}
+
+// Endpoint overrides the endpoint of SNS and SQS, e.g. to use localstack
+func Endpoint(endpoint string) broker.Option {
+	return setBrokerOption(endpointKey{}, endpoint)
+}
+
+// Credentials to authenticate with, if not set the default chain is used
+func Credentials(id, secret string) broker.Option {
+	return setBrokerOption(credentialsKey{}, credentials{id: id, secret: secret})
+}
+
+// WaitTime sets how long a subscriber waits for messages when polling its queue, up to 20s
+func WaitTime(d time.Duration) broker.Option {
+	return setBrokerOption(waitTimeKey{}, d)
+}
+
+// VisibilityTimeout sets how long a message received by a subscriber is hidden from the other
+// subscribers of the queue. Messages whose handler returns an error are redelivered once it ends.
+func VisibilityTimeout(d time.Duration) broker.Option {
+	return setBrokerOption(visibilityTimeoutKey{}, d)
+}
+
+// setBrokerOption returns a function to setup a context with given value
+func setBrokerOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
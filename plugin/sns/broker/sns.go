@@ -0,0 +1,496 @@
+// Package broker implements the micro broker with AWS SNS and SQS
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	jsoncodec "github.com/micro/micro/v3/util/codec/json"
+)
+
+const (
+	// maxWaitTime is the longest SQS waits for messages when polling a queue
+	maxWaitTime = 20 * time.Second
+	// maxTopicName and maxQueueName are the maximum lengths of SNS topic and SQS queue names
+	maxTopicName = 256
+	maxQueueName = 80
+)
+
+var errNotConnected = errors.New("not connected")
+
+// NewBroker returns a broker which publishes messages to SNS topics and delivers them to the
+// subscribers from SQS queues subscribed to the topics. Messages are encoded with the codec, as
+// SNS only supports a limited number of message attributes.
+func NewBroker(opts ...broker.Option) broker.Broker {
+	b := &snsBroker{
+		opts: broker.Options{
+			Codec:   jsoncodec.Marshaler{},
+			Context: context.Background(),
+		},
+		subscribers: make(map[*subscriber]bool),
+		topics:      make(map[string]string),
+	}
+	b.setOption(opts...)
+	return b
+}
+
+type snsBroker struct {
+	opts broker.Options
+
+	// the options parsed from opts
+	region            string
+	endpoint          string
+	creds             *credentials
+	waitTime          time.Duration
+	visibilityTimeout time.Duration
+
+	sync.RWMutex
+	sns         snsiface.SNSAPI
+	sqs         sqsiface.SQSAPI
+	subscribers map[*subscriber]bool
+	// the ARNs of the topics which are known to exist
+	topics map[string]string
+}
+
+func (b *snsBroker) setOption(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&b.opts)
+	}
+
+	b.region, _ = b.opts.Context.Value(regionKey{}).(string)
+	b.endpoint, _ = b.opts.Context.Value(endpointKey{}).(string)
+	b.creds = nil
+	if c, ok := b.opts.Context.Value(credentialsKey{}).(credentials); ok && len(c.id) > 0 {
+		b.creds = &c
+	}
+	b.waitTime = maxWaitTime
+	if d, ok := b.opts.Context.Value(waitTimeKey{}).(time.Duration); ok && d >= 0 && d < maxWaitTime {
+		b.waitTime = d
+	}
+	b.visibilityTimeout = 30 * time.Second
+	if d, ok := b.opts.Context.Value(visibilityTimeoutKey{}).(time.Duration); ok && d > 0 {
+		b.visibilityTimeout = d
+	}
+}
+
+// newSession returns an AWS session configured with the options of the broker
+func (b *snsBroker) newSession() (*session.Session, error) {
+	cfg := &aws.Config{}
+	if len(b.region) > 0 {
+		cfg.Region = aws.String(b.region)
+	}
+	if len(b.endpoint) > 0 {
+		cfg.Endpoint = aws.String(b.endpoint)
+	}
+	// without static credentials the default chain is used, e.g. the env vars or instance role
+	if b.creds != nil {
+		cfg.Credentials = awscreds.NewStaticCredentials(b.creds.id, b.creds.secret, "")
+	}
+	if b.opts.TLSConfig != nil {
+		cfg.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: b.opts.TLSConfig,
+			},
+		}
+	}
+	return session.NewSession(cfg)
+}
+
+func (b *snsBroker) Init(opts ...broker.Option) error {
+	b.setOption(opts...)
+	return nil
+}
+
+func (b *snsBroker) Options() broker.Options {
+	return b.opts
+}
+
+func (b *snsBroker) Address() string {
+	if len(b.endpoint) > 0 {
+		return b.endpoint
+	}
+	return b.region
+}
+
+// Connect creates the SNS and SQS clients, the credentials are checked by the first request
+func (b *snsBroker) Connect() error {
+	b.Lock()
+	defer b.Unlock()
+	if b.sns != nil {
+		return nil
+	}
+
+	sess, err := b.newSession()
+	if err != nil {
+		return err
+	}
+	b.sns = sns.New(sess)
+	b.sqs = sqs.New(sess)
+	return nil
+}
+
+// Disconnect unsubscribes the subscribers, deleting the queues of those without a queue name
+func (b *snsBroker) Disconnect() error {
+	b.Lock()
+	if b.sns == nil {
+		b.Unlock()
+		return nil
+	}
+	subs := make([]*subscriber, 0, len(b.subscribers))
+	for s := range b.subscribers {
+		subs = append(subs, s)
+	}
+	b.sns = nil
+	b.sqs = nil
+	b.Unlock()
+
+	for _, s := range subs {
+		s.Unsubscribe()
+	}
+	return nil
+}
+
+// clients returns the SNS and SQS clients, or errNotConnected
+func (b *snsBroker) clients() (snsiface.SNSAPI, sqsiface.SQSAPI, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.sns == nil {
+		return nil, nil, errNotConnected
+	}
+	return b.sns, b.sqs, nil
+}
+
+// topicARN returns the ARN of the SNS topic, creating it if the broker hasn't already
+func (b *snsBroker) topicARN(client snsiface.SNSAPI, topic string) (string, error) {
+	b.RLock()
+	arn, ok := b.topics[topic]
+	b.RUnlock()
+	if ok {
+		return arn, nil
+	}
+
+	// creating a topic which exists returns its ARN
+	rsp, err := client.CreateTopic(&sns.CreateTopicInput{
+		Name: aws.String(resourceName(topic, maxTopicName)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error creating topic: %v", err)
+	}
+	arn = aws.StringValue(rsp.TopicArn)
+
+	b.Lock()
+	b.topics[topic] = arn
+	b.Unlock()
+	return arn, nil
+}
+
+// Publish a message to the SNS topic
+func (b *snsBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	options := broker.PublishOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, _, err := b.clients()
+	if err != nil {
+		return err
+	}
+	arn, err := b.topicARN(client, topic)
+	if err != nil {
+		return err
+	}
+
+	msg, err = broker.PrepareMessage(msg, b.opts, options)
+	if err != nil {
+		return err
+	}
+	bytes, err := b.opts.Codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = client.PublishWithContext(options.Context, &sns.PublishInput{
+		TopicArn: aws.String(arn),
+		Message:  aws.String(string(bytes)),
+	})
+	if err != nil {
+		return fmt.Errorf("Error publishing message to topic: %v", err)
+	}
+	return nil
+}
+
+// Subscribe to a topic. Each queue, set with broker.Queue, is an SQS queue subscribed to the SNS
+// topic which is polled by the subscribers of the queue, so each message is delivered to one of
+// them. Subscribers without a queue are given their own SQS queue, deleted when they unsubscribe.
+func (b *snsBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	// validate the topic
+	if len(topic) == 0 {
+		return nil, events.ErrMissingTopic
+	}
+
+	options := broker.SubscribeOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	group := options.Queue
+	if len(group) == 0 {
+		group = uuid.New().String()
+	}
+
+	snsClient, sqsClient, err := b.clients()
+	if err != nil {
+		return nil, err
+	}
+	topicARN, err := b.topicARN(snsClient, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	// create the queue, allowing the topic to send messages to it
+	q, err := sqsClient.CreateQueue(&sqs.CreateQueueInput{
+		QueueName: aws.String(resourceName(topic+"-"+group, maxQueueName)),
+		Attributes: map[string]*string{
+			sqs.QueueAttributeNameVisibilityTimeout: aws.String(fmt.Sprint(int64(b.visibilityTimeout / time.Second))),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error creating queue: %v", err)
+	}
+	attrs, err := sqsClient.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       q.QueueUrl,
+		AttributeNames: []*string{aws.String(sqs.QueueAttributeNameQueueArn)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading queue attributes: %v", err)
+	}
+	queueARN := aws.StringValue(attrs.Attributes[sqs.QueueAttributeNameQueueArn])
+	_, err = sqsClient.SetQueueAttributes(&sqs.SetQueueAttributesInput{
+		QueueUrl: q.QueueUrl,
+		Attributes: map[string]*string{
+			sqs.QueueAttributeNamePolicy: aws.String(queuePolicy(queueARN, topicARN)),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error setting queue policy: %v", err)
+	}
+
+	// raw delivery passes the message to the queue without wrapping it in an SNS notification,
+	// subscribing a queue which is already subscribed returns the existing subscription
+	sub, err := snsClient.Subscribe(&sns.SubscribeInput{
+		TopicArn:              aws.String(topicARN),
+		Protocol:              aws.String("sqs"),
+		Endpoint:              aws.String(queueARN),
+		Attributes:            map[string]*string{"RawMessageDelivery": aws.String("true")},
+		ReturnSubscriptionArn: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error subscribing queue to topic: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &subscriber{
+		opts:            options,
+		topic:           topic,
+		broker:          b,
+		sns:             snsClient,
+		sqs:             sqsClient,
+		queueURL:        aws.StringValue(q.QueueUrl),
+		subscriptionARN: aws.StringValue(sub.SubscriptionArn),
+		cancel:          cancel,
+		done:            make(chan struct{}),
+	}
+
+	b.Lock()
+	b.subscribers[s] = true
+	b.Unlock()
+
+	go s.run(ctx, h)
+	return s, nil
+}
+
+func (b *snsBroker) String() string {
+	return "sns"
+}
+
+type subscriber struct {
+	opts            broker.SubscribeOptions
+	topic           string
+	broker          *snsBroker
+	sns             snsiface.SNSAPI
+	sqs             sqsiface.SQSAPI
+	queueURL        string
+	subscriptionARN string
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe stops polling the queue, waiting for the messages being handled. The queue of a
+// subscriber without a queue name is unsubscribed from the topic and deleted, the queues shared
+// by subscribers are kept so messages published while they're unsubscribed aren't lost.
+func (s *subscriber) Unsubscribe() error {
+	var err error
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+
+		s.broker.Lock()
+		delete(s.broker.subscribers, s)
+		s.broker.Unlock()
+
+		if len(s.opts.Queue) > 0 {
+			return
+		}
+		if _, err = s.sns.Unsubscribe(&sns.UnsubscribeInput{SubscriptionArn: aws.String(s.subscriptionARN)}); err != nil {
+			return
+		}
+		_, err = s.sqs.DeleteQueue(&sqs.DeleteQueueInput{QueueUrl: aws.String(s.queueURL)})
+	})
+	return err
+}
+
+// run polls the queue, passing the messages to the handler
+func (s *subscriber) run(ctx context.Context, h broker.Handler) {
+	defer close(s.done)
+
+	for {
+		rsp, err := s.sqs.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueURL),
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(int64(s.broker.waitTime / time.Second)),
+		})
+		if ctx.Err() != nil {
+			return
+		} else if err != nil {
+			logger.Errorf("Error receiving messages from topic %v: %v", s.topic, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, m := range rsp.Messages {
+			// the messages which aren't handled are redelivered once their visibility times out
+			if ctx.Err() != nil {
+				return
+			}
+			s.handle(h, m)
+		}
+	}
+}
+
+// handle a message received from the queue. The message is deleted once it's handled, if the
+// handler returns an error it's left in the queue to be redelivered.
+func (s *subscriber) handle(h broker.Handler, m *sqs.Message) {
+	body := []byte(aws.StringValue(m.Body))
+	msg := &broker.Message{}
+	err := s.broker.opts.Codec.Unmarshal(body, msg)
+	if err == nil {
+		msg, err = broker.DecompressMessage(msg)
+	}
+	if err != nil {
+		logger.Errorf("Error decoding message from topic %v: %v", s.topic, err)
+		if s.opts.ErrorHandler != nil {
+			s.opts.ErrorHandler(&broker.Message{Body: body}, err)
+		}
+		// the message would fail to decode every time it's redelivered
+		s.delete(m)
+		return
+	}
+
+	if err := h(msg); err != nil {
+		if s.opts.ErrorHandler != nil {
+			s.opts.ErrorHandler(msg, err)
+		} else {
+			logger.Errorf("Error handling message from topic %v: %v", s.topic, err)
+		}
+		return
+	}
+	s.delete(m)
+}
+
+// delete the message from the queue
+func (s *subscriber) delete(m *sqs.Message) {
+	_, err := s.sqs.DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.queueURL),
+		ReceiptHandle: m.ReceiptHandle,
+	})
+	if err != nil {
+		logger.Errorf("Error deleting message from topic %v: %v", s.topic, err)
+	}
+}
+
+// queuePolicy returns the policy of a queue allowing the topic to send messages to it
+func queuePolicy(queueARN, topicARN string) string {
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{{
+			"Effect":    "Allow",
+			"Principal": map[string]string{"Service": "sns.amazonaws.com"},
+			"Action":    "sqs:SendMessage",
+			"Resource":  queueARN,
+			"Condition": map[string]interface{}{
+				"ArnEquals": map[string]string{"aws:SourceArn": topicARN},
+			},
+		}},
+	}
+	bytes, _ := json.Marshal(policy)
+	return string(bytes)
+}
+
+// resourceName returns a valid SNS topic or SQS queue name for the name, which may only contain
+// alphanumeric characters, hyphens and underscores. Names longer than max are truncated and
+// suffixed with a hash of the name so they remain unique.
+func resourceName(name string, max int) string {
+	res := []byte(name)
+	for i, c := range res {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			res[i] = '-'
+		}
+	}
+	if len(res) <= max {
+		return string(res)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%s-%016x", res[:max-17], h.Sum64())
+}
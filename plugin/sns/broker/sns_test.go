@@ -0,0 +1,364 @@
+package broker
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/broker"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAWS is an in memory implementation of the SNS and SQS APIs used by the broker
+type fakeAWS struct {
+	sync.Mutex
+	topics map[string]string
+	subs   map[string]fakeSubscription
+	queues map[string]*fakeQueue
+}
+
+type fakeSubscription struct {
+	topicARN string
+	queueARN string
+	raw      bool
+}
+
+type fakeQueue struct {
+	arn        string
+	visibility time.Duration
+	messages   []*sqs.Message
+	// the received messages by receipt handle, with the time they become visible again
+	inflight map[string]inflight
+}
+
+type inflight struct {
+	msg     *sqs.Message
+	visible time.Time
+}
+
+func newFakeAWS() *fakeAWS {
+	return &fakeAWS{
+		topics: make(map[string]string),
+		subs:   make(map[string]fakeSubscription),
+		queues: make(map[string]*fakeQueue),
+	}
+}
+
+type fakeSNS struct {
+	snsiface.SNSAPI
+	*fakeAWS
+}
+
+func (f fakeSNS) CreateTopic(in *sns.CreateTopicInput) (*sns.CreateTopicOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	name := aws.StringValue(in.Name)
+	if _, ok := f.topics[name]; !ok {
+		f.topics[name] = "arn:aws:sns:local:0:" + name
+	}
+	return &sns.CreateTopicOutput{TopicArn: aws.String(f.topics[name])}, nil
+}
+
+func (f fakeSNS) Subscribe(in *sns.SubscribeInput) (*sns.SubscribeOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	arn := aws.StringValue(in.TopicArn) + ":" + aws.StringValue(in.Endpoint)
+	f.subs[arn] = fakeSubscription{
+		topicARN: aws.StringValue(in.TopicArn),
+		queueARN: aws.StringValue(in.Endpoint),
+		raw:      aws.StringValue(in.Attributes["RawMessageDelivery"]) == "true",
+	}
+	return &sns.SubscribeOutput{SubscriptionArn: aws.String(arn)}, nil
+}
+
+func (f fakeSNS) Unsubscribe(in *sns.UnsubscribeInput) (*sns.UnsubscribeOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.subs, aws.StringValue(in.SubscriptionArn))
+	return &sns.UnsubscribeOutput{}, nil
+}
+
+func (f fakeSNS) PublishWithContext(ctx aws.Context, in *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	for _, s := range f.subs {
+		if s.topicARN != aws.StringValue(in.TopicArn) {
+			continue
+		}
+		body := aws.StringValue(in.Message)
+		if !s.raw {
+			body = `{"Type":"Notification","Message":` + strconv.Quote(body) + `}`
+		}
+		for _, q := range f.queues {
+			if q.arn == s.queueARN {
+				q.messages = append(q.messages, &sqs.Message{Body: aws.String(body)})
+			}
+		}
+	}
+	return &sns.PublishOutput{}, nil
+}
+
+type fakeSQS struct {
+	sqsiface.SQSAPI
+	*fakeAWS
+}
+
+func (f fakeSQS) CreateQueue(in *sqs.CreateQueueInput) (*sqs.CreateQueueOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	name := aws.StringValue(in.QueueName)
+	url := "https://sqs.local/0/" + name
+	if _, ok := f.queues[url]; !ok {
+		secs, _ := strconv.Atoi(aws.StringValue(in.Attributes[sqs.QueueAttributeNameVisibilityTimeout]))
+		f.queues[url] = &fakeQueue{
+			arn:        "arn:aws:sqs:local:0:" + name,
+			visibility: time.Duration(secs) * time.Second,
+			inflight:   make(map[string]inflight),
+		}
+	}
+	return &sqs.CreateQueueOutput{QueueUrl: aws.String(url)}, nil
+}
+
+func (f fakeSQS) GetQueueAttributes(in *sqs.GetQueueAttributesInput) (*sqs.GetQueueAttributesOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	q := f.queues[aws.StringValue(in.QueueUrl)]
+	return &sqs.GetQueueAttributesOutput{Attributes: map[string]*string{
+		sqs.QueueAttributeNameQueueArn: aws.String(q.arn),
+	}}, nil
+}
+
+func (f fakeSQS) SetQueueAttributes(in *sqs.SetQueueAttributesInput) (*sqs.SetQueueAttributesOutput, error) {
+	return &sqs.SetQueueAttributesOutput{}, nil
+}
+
+func (f fakeSQS) DeleteQueue(in *sqs.DeleteQueueInput) (*sqs.DeleteQueueOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.queues, aws.StringValue(in.QueueUrl))
+	return &sqs.DeleteQueueOutput{}, nil
+}
+
+func (f fakeSQS) ReceiveMessageWithContext(ctx aws.Context, in *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	deadline := time.Now().Add(time.Duration(aws.Int64Value(in.WaitTimeSeconds)) * time.Second)
+	for {
+		if msgs := f.receive(aws.StringValue(in.QueueUrl), int(aws.Int64Value(in.MaxNumberOfMessages))); len(msgs) > 0 {
+			return &sqs.ReceiveMessageOutput{Messages: msgs}, nil
+		}
+		if time.Now().After(deadline) {
+			return &sqs.ReceiveMessageOutput{}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// receive up to max messages from the queue, hiding them until their visibility times out
+func (f fakeSQS) receive(url string, max int) []*sqs.Message {
+	f.Lock()
+	defer f.Unlock()
+	q, ok := f.queues[url]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	for h, m := range q.inflight {
+		if now.After(m.visible) {
+			q.messages = append(q.messages, m.msg)
+			delete(q.inflight, h)
+		}
+	}
+
+	var msgs []*sqs.Message
+	for len(q.messages) > 0 && len(msgs) < max {
+		m := &sqs.Message{Body: q.messages[0].Body, ReceiptHandle: aws.String(uuid.New().String())}
+		q.inflight[*m.ReceiptHandle] = inflight{msg: q.messages[0], visible: now.Add(q.visibility)}
+		q.messages = q.messages[1:]
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func (f fakeSQS) DeleteMessage(in *sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	if q, ok := f.queues[aws.StringValue(in.QueueUrl)]; ok {
+		delete(q.inflight, aws.StringValue(in.ReceiptHandle))
+	}
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestResourceName(t *testing.T) {
+	assert.Equal(t, "go-micro-foo", resourceName("go.micro.foo", maxTopicName))
+	assert.Equal(t, "foo_bar-1", resourceName("foo_bar-1", maxTopicName))
+
+	long := resourceName(strings.Repeat("a", 100), maxQueueName)
+	assert.Len(t, long, maxQueueName)
+	assert.NotEqual(t, long, resourceName(strings.Repeat("a", 101), maxQueueName))
+}
+
+func TestOptions(t *testing.T) {
+	b := NewBroker(Region("eu-west-1"), WaitTime(time.Minute)).(*snsBroker)
+	assert.Equal(t, "eu-west-1", b.Address())
+	assert.Equal(t, maxWaitTime, b.waitTime)
+	assert.Equal(t, 30*time.Second, b.visibilityTimeout)
+	assert.Nil(t, b.creds)
+
+	assert.NoError(t, b.Init(Endpoint("http://localhost:4566"), Credentials("id", "secret"), WaitTime(time.Second)))
+	assert.Equal(t, "http://localhost:4566", b.Address())
+	assert.Equal(t, time.Second, b.waitTime)
+	assert.Equal(t, &credentials{id: "id", secret: "secret"}, b.creds)
+}
+
+func TestNotConnected(t *testing.T) {
+	b := NewBroker()
+	assert.Equal(t, events.ErrMissingTopic, b.Publish("", &broker.Message{}))
+	assert.Equal(t, errNotConnected, b.Publish("foo", &broker.Message{}))
+	_, err := b.Subscribe("foo", func(*broker.Message) error { return nil })
+	assert.Equal(t, errNotConnected, err)
+}
+
+func TestBroker(t *testing.T) {
+	f := newFakeAWS()
+	b := NewBroker(WaitTime(time.Second), VisibilityTimeout(time.Second)).(*snsBroker)
+	b.sns = fakeSNS{fakeAWS: f}
+	b.sqs = fakeSQS{fakeAWS: f}
+	defer b.Disconnect()
+
+	testBroker(t, b)
+
+	t.Run("Redelivery", func(t *testing.T) {
+		ch := make(chan *broker.Message, 10)
+		var attempts int
+		sub, err := b.Subscribe("redelivery", func(m *broker.Message) error {
+			ch <- m
+			if attempts++; attempts == 1 {
+				return context.DeadlineExceeded
+			}
+			return nil
+		}, broker.Queue("queue"))
+		assert.NoError(t, err)
+		defer sub.Unsubscribe()
+
+		// the message is redelivered once its visibility times out, as the handler failed
+		assert.NoError(t, b.Publish("redelivery", &broker.Message{Body: []byte("hello")}))
+		for i := 0; i < 2; i++ {
+			select {
+			case m := <-ch:
+				assert.Equal(t, "hello", string(m.Body))
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Message wasn't redelivered")
+			}
+		}
+		select {
+		case <-ch:
+			t.Errorf("Message delivered after it was handled")
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		h := func(*broker.Message) error { return nil }
+		sub, err := b.Subscribe("unsubscribe", h)
+		assert.NoError(t, err)
+		queueSub, err := b.Subscribe("unsubscribe", h, broker.Queue("queue"))
+		assert.NoError(t, err)
+
+		// the queue of the subscriber without a queue name is deleted, the shared queue is kept
+		f.Lock()
+		n := len(f.queues)
+		f.Unlock()
+		assert.NoError(t, sub.Unsubscribe())
+		assert.NoError(t, queueSub.Unsubscribe())
+		f.Lock()
+		assert.Len(t, f.queues, n-1)
+		f.Unlock()
+	})
+}
+
+func TestAWS(t *testing.T) {
+	region := os.Getenv("AWS_REGION")
+	if len(region) == 0 {
+		t.Skipf("Missing required config AWS_REGION")
+	}
+	b := NewBroker(Region(region), Endpoint(os.Getenv("AWS_ENDPOINT")))
+	assert.NoError(t, b.Connect())
+	defer b.Disconnect()
+
+	testBroker(t, b)
+}
+
+func testBroker(t *testing.T, b broker.Broker) {
+	receive := func(ch <-chan *broker.Message) *broker.Message {
+		select {
+		case m := <-ch:
+			return m
+		case <-time.After(30 * time.Second):
+			t.Errorf("Failed to receive message within the time limit")
+			return nil
+		}
+	}
+
+	t.Run("Simple", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ch := make(chan *broker.Message, 1)
+		sub, err := b.Subscribe(topic, func(m *broker.Message) error {
+			ch <- m
+			return nil
+		})
+		assert.NoError(t, err)
+		defer sub.Unsubscribe()
+		assert.Equal(t, topic, sub.Topic())
+
+		assert.NoError(t, b.Publish(topic, &broker.Message{
+			Header: map[string]string{"foo": "bar"},
+			Body:   []byte("hello"),
+		}))
+		if m := receive(ch); m != nil {
+			assert.Equal(t, "bar", m.Header["foo"])
+			assert.Equal(t, "hello", string(m.Body))
+		}
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		topic := "test-" + uuid.New().String()
+		ch := make(chan *broker.Message, 10)
+		h := func(m *broker.Message) error {
+			ch <- m
+			return nil
+		}
+		for i := 0; i < 2; i++ {
+			sub, err := b.Subscribe(topic, h, broker.Queue("queue"))
+			assert.NoError(t, err)
+			defer sub.Unsubscribe()
+		}
+
+		// each message is only delivered to one of the subscribers in the queue
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, b.Publish(topic, &broker.Message{Body: []byte("hello")}))
+		}
+		for i := 0; i < 5; i++ {
+			receive(ch)
+		}
+		select {
+		case <-ch:
+			t.Errorf("Message delivered more than once")
+		case <-time.After(2 * time.Second):
+		}
+	})
+}
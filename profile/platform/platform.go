@@ -4,7 +4,10 @@ package platform
 import (
 	"crypto/tls"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/micro/micro/plugin/postgres/v3"
@@ -31,10 +34,17 @@ import (
 	"github.com/urfave/cli/v2"
 
 	// plugins
+	"github.com/micro/micro/plugin/consul/v3"
 	"github.com/micro/micro/plugin/etcd/v3"
+	kafkaBroker "github.com/micro/micro/plugin/kafka/broker/v3"
+	kafkastream "github.com/micro/micro/plugin/kafka/stream/v3"
+	mqttBroker "github.com/micro/micro/plugin/mqtt/broker/v3"
+	"github.com/micro/micro/plugin/nats/jetstream/v3"
 	"github.com/micro/micro/plugin/prometheus/v3"
+	rabbitmqstream "github.com/micro/micro/plugin/rabbitmq/stream/v3"
 	redisBroker "github.com/micro/micro/plugin/redis/broker/v3"
 	redisstream "github.com/micro/micro/plugin/redis/stream/v3"
+	snsBroker "github.com/micro/micro/plugin/sns/broker/v3"
 )
 
 func init() {
@@ -59,8 +69,22 @@ var Profile = &profile.Profile{
 			// when the store is created. The cockroach store address contains the location
 			// of certs so it can't be defaulted like the broker and registry.
 			store.DefaultStore = postgres.NewStore(store.Nodes(ctx.String("store_address")))
-			profile.SetupBroker(redisBroker.NewBroker(broker.Addrs(ctx.String("broker_address"))))
-			profile.SetupRegistry(etcd.NewRegistry(registry.Addrs(ctx.String("registry_address"))))
+			switch os.Getenv("MICRO_BROKER") {
+			case "kafka":
+				profile.SetupBroker(kafkaBroker.NewBroker(kafkaBrokerOpts(ctx)...))
+			case "mqtt":
+				profile.SetupBroker(mqttBroker.NewBroker(mqttBrokerOpts(ctx)...))
+			case "sns":
+				profile.SetupBroker(snsBroker.NewBroker(snsBrokerOpts()...))
+			default:
+				profile.SetupBroker(redisBroker.NewBroker(broker.Addrs(ctx.String("broker_address"))))
+			}
+			switch os.Getenv("MICRO_REGISTRY") {
+			case "consul":
+				profile.SetupRegistry(consul.NewRegistry(consulRegistryOpts(ctx)...))
+			default:
+				profile.SetupRegistry(etcd.NewRegistry(registry.Addrs(ctx.String("registry_address"))))
+			}
 			profile.SetupJWT(ctx)
 			profile.SetupConfigSecretKey(ctx)
 
@@ -76,7 +100,16 @@ var Profile = &profile.Profile{
 
 			var err error
 			if ctx.Args().Get(1) == "events" {
-				events.DefaultStream, err = redisstream.NewStream(redisStreamOpts(ctx)...)
+				switch os.Getenv("MICRO_EVENTS") {
+				case "kafka":
+					events.DefaultStream, err = kafkastream.NewStream(kafkaStreamOpts()...)
+				case "jetstream":
+					events.DefaultStream, err = jetstream.NewStream(jetstreamOpts()...)
+				case "rabbitmq":
+					events.DefaultStream, err = rabbitmqstream.NewStream(rabbitmqStreamOpts()...)
+				default:
+					events.DefaultStream, err = redisstream.NewStream(redisStreamOpts(ctx)...)
+				}
 				if err != nil {
 					logger.Fatalf("Error configuring stream: %v", err)
 				}
@@ -112,6 +145,10 @@ var Profile = &profile.Profile{
 				if val := os.Getenv("MICRO_BLOB_STORE_INSECURE"); len(val) > 0 {
 					opts = append(opts, s3.Insecure())
 				}
+				// S3 compatible stores such as MinIO address buckets by path
+				if val := os.Getenv("MICRO_BLOB_STORE_PATH_STYLE"); len(val) > 0 {
+					opts = append(opts, s3.PathStyle())
+				}
 
 				store.DefaultBlobStore, err = s3.NewBlobStore(opts...)
 				if err != nil {
@@ -170,6 +207,137 @@ func redisStreamOpts(ctx *cli.Context) []redisstream.Option {
 	return opts
 }
 
+// kafkaBrokerOpts returns a slice of options which should be used to configure the kafka broker
+func kafkaBrokerOpts(ctx *cli.Context) []broker.Option {
+	opts := []broker.Option{
+		broker.Addrs(ctx.String("broker_address")),
+		kafkaBroker.Credentials(os.Getenv("MICRO_BROKER_KAFKA_USER"), os.Getenv("MICRO_BROKER_KAFKA_PASSWORD")),
+	}
+	if val := os.Getenv("MICRO_BROKER_KAFKA_TLS"); len(val) > 0 {
+		opts = append(opts, broker.TLSConfig(&tls.Config{}))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_BROKER_KAFKA_PARTITIONS")); err == nil {
+		opts = append(opts, kafkaBroker.Partitions(val))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_BROKER_KAFKA_REPLICATION_FACTOR")); err == nil {
+		opts = append(opts, kafkaBroker.ReplicationFactor(val))
+	}
+
+	return opts
+}
+
+// mqttBrokerOpts returns a slice of options which should be used to configure the mqtt broker
+func mqttBrokerOpts(ctx *cli.Context) []broker.Option {
+	opts := []broker.Option{
+		broker.Addrs(ctx.String("broker_address")),
+		mqttBroker.Credentials(os.Getenv("MICRO_BROKER_MQTT_USER"), os.Getenv("MICRO_BROKER_MQTT_PASSWORD")),
+	}
+	if val := os.Getenv("MICRO_BROKER_MQTT_TLS"); len(val) > 0 {
+		opts = append(opts, broker.TLSConfig(&tls.Config{}))
+	}
+
+	return opts
+}
+
+// snsBrokerOpts returns a slice of options which should be used to configure the sns broker
+func snsBrokerOpts() []broker.Option {
+	opts := []broker.Option{
+		snsBroker.Credentials(
+			os.Getenv("MICRO_BROKER_SNS_ACCESS_KEY"),
+			os.Getenv("MICRO_BROKER_SNS_SECRET_KEY"),
+		),
+		snsBroker.Endpoint(os.Getenv("MICRO_BROKER_SNS_ENDPOINT")),
+		snsBroker.Region(os.Getenv("MICRO_BROKER_SNS_REGION")),
+	}
+	if val, err := time.ParseDuration(os.Getenv("MICRO_BROKER_SNS_VISIBILITY_TIMEOUT")); err == nil {
+		opts = append(opts, snsBroker.VisibilityTimeout(val))
+	}
+
+	return opts
+}
+
+// consulRegistryOpts returns a slice of options which should be used to configure the consul registry
+func consulRegistryOpts(ctx *cli.Context) []registry.Option {
+	opts := []registry.Option{
+		registry.Addrs(ctx.String("registry_address")),
+		consul.Token(os.Getenv("MICRO_REGISTRY_CONSUL_TOKEN")),
+		consul.Datacenter(os.Getenv("MICRO_REGISTRY_CONSUL_DATACENTER")),
+	}
+	if val := os.Getenv("MICRO_REGISTRY_CONSUL_TLS"); len(val) > 0 {
+		opts = append(opts, registry.Secure(true))
+	}
+
+	return opts
+}
+
+// kafkaStreamOpts returns a slice of options which should be used to configure kafka
+func kafkaStreamOpts() []kafkastream.Option {
+	opts := []kafkastream.Option{
+		kafkastream.Credentials(os.Getenv("MICRO_EVENTS_KAFKA_USER"), os.Getenv("MICRO_EVENTS_KAFKA_PASSWORD")),
+	}
+	if val := os.Getenv("MICRO_EVENTS_KAFKA_ADDRESS"); len(val) > 0 {
+		opts = append(opts, kafkastream.Addresses(strings.Split(val, ",")...))
+	}
+	if val := os.Getenv("MICRO_EVENTS_KAFKA_TLS"); len(val) > 0 {
+		opts = append(opts, kafkastream.TLSConfig(&tls.Config{}))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_EVENTS_KAFKA_PARTITIONS")); err == nil {
+		opts = append(opts, kafkastream.Partitions(val))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_EVENTS_KAFKA_REPLICATION_FACTOR")); err == nil {
+		opts = append(opts, kafkastream.ReplicationFactor(val))
+	}
+	if val, err := time.ParseDuration(os.Getenv("MICRO_EVENTS_KAFKA_RETENTION")); err == nil {
+		opts = append(opts, kafkastream.Retention(val))
+	}
+	if val := os.Getenv("MICRO_EVENTS_KAFKA_COMPACTED_TOPICS"); len(val) > 0 {
+		opts = append(opts, kafkastream.Compacted(strings.Split(val, ",")...))
+	}
+
+	return opts
+}
+
+// jetstreamOpts returns a slice of options which should be used to configure jetstream
+func jetstreamOpts() []jetstream.Option {
+	var opts []jetstream.Option
+	if val := os.Getenv("MICRO_EVENTS_NATS_ADDRESS"); len(val) > 0 {
+		opts = append(opts, jetstream.Address(val))
+	}
+	if val := os.Getenv("MICRO_EVENTS_NATS_TLS"); len(val) > 0 {
+		opts = append(opts, jetstream.TLSConfig(&tls.Config{}))
+	}
+	if val := os.Getenv("MICRO_EVENTS_NATS_STREAM"); len(val) > 0 {
+		opts = append(opts, jetstream.Stream(val))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_EVENTS_NATS_REPLICAS")); err == nil {
+		opts = append(opts, jetstream.Replicas(val))
+	}
+	if val, err := time.ParseDuration(os.Getenv("MICRO_EVENTS_NATS_MAX_AGE")); err == nil {
+		opts = append(opts, jetstream.MaxAge(val))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_EVENTS_NATS_PARTITIONS")); err == nil {
+		opts = append(opts, jetstream.Partitions(val))
+	}
+
+	return opts
+}
+
+// rabbitmqStreamOpts returns a slice of options which should be used to configure rabbitmq
+func rabbitmqStreamOpts() []rabbitmqstream.Option {
+	var opts []rabbitmqstream.Option
+	if val := os.Getenv("MICRO_EVENTS_RABBITMQ_ADDRESS"); len(val) > 0 {
+		opts = append(opts, rabbitmqstream.Address(val))
+	}
+	if val := os.Getenv("MICRO_EVENTS_RABBITMQ_TLS"); len(val) > 0 {
+		opts = append(opts, rabbitmqstream.TLSConfig(&tls.Config{}))
+	}
+	if val, err := strconv.Atoi(os.Getenv("MICRO_EVENTS_RABBITMQ_PREFETCH")); err == nil {
+		opts = append(opts, rabbitmqstream.Prefetch(val))
+	}
+
+	return opts
+}
+
 // ClientProfile is for clients running on the micro platform
 var ClientProfile = &profile.Profile{
 	Name: "platform_client",
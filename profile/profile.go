@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/micro/micro/v3/service/auth/jwt"
 	"github.com/micro/micro/v3/service/auth/noop"
@@ -17,10 +20,13 @@ import (
 	"github.com/micro/micro/v3/service/config"
 	storeConfig "github.com/micro/micro/v3/service/config/store"
 	evStore "github.com/micro/micro/v3/service/events/store"
+	"github.com/micro/micro/v3/service/events/store/segment"
 	memStream "github.com/micro/micro/v3/service/events/stream/memory"
 	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/model"
 	"github.com/micro/micro/v3/service/registry"
+	dnsRegistry "github.com/micro/micro/v3/service/registry/dns"
+	k8sRegistry "github.com/micro/micro/v3/service/registry/kubernetes"
 	"github.com/micro/micro/v3/service/registry/memory"
 	"github.com/micro/micro/v3/service/router"
 	k8sRouter "github.com/micro/micro/v3/service/router/kubernetes"
@@ -28,6 +34,8 @@ import (
 	"github.com/micro/micro/v3/service/runtime/kubernetes"
 	"github.com/micro/micro/v3/service/runtime/local"
 	"github.com/micro/micro/v3/service/server"
+	"github.com/micro/micro/v3/service/store/badger"
+	storeClient "github.com/micro/micro/v3/service/store/client"
 	"github.com/micro/micro/v3/service/store/file"
 	mem "github.com/micro/micro/v3/service/store/memory"
 	"github.com/micro/micro/v3/util/opentelemetry"
@@ -92,7 +100,7 @@ var Local = &Profile{
 	Name: "local",
 	Setup: func(ctx *cli.Context) error {
 		microAuth.DefaultAuth = jwt.NewAuth()
-		microStore.DefaultStore = file.NewStore(file.WithDir(filepath.Join(user.Dir, "server", "store")))
+		microStore.DefaultStore = localStore(ctx)
 		SetupConfigSecretKey(ctx)
 		config.DefaultConfig, _ = storeConfig.NewConfig(microStore.DefaultStore, "")
 		SetupJWT(ctx)
@@ -135,9 +143,16 @@ var Local = &Profile{
 		if err != nil {
 			logger.Fatalf("Error configuring stream: %v", err)
 		}
-		microEvents.DefaultStore = evStore.NewStore(
-			evStore.WithStore(microStore.DefaultStore),
-		)
+		// the events service keeps the events in a segmented log, the other core services will use
+		// the default rpc client and call the events service
+		if ctx.Args().Get(1) == "events" {
+			microEvents.DefaultStore, err = segment.NewStore(
+				append(localRetention(), segment.WithDir(filepath.Join(user.Dir, "server", "events")))...,
+			)
+			if err != nil {
+				logger.Fatalf("Error configuring events store: %v", err)
+			}
+		}
 
 		microStore.DefaultBlobStore, err = file.NewBlobStore()
 		if err != nil {
@@ -191,10 +206,21 @@ var Kubernetes = &Profile{
 			model.WithStore(microStore.DefaultStore),
 		)
 
-		// the registry service uses the memory registry, the other core services will use the default
-		// rpc client and call the registry service
-		if ctx.Args().Get(1) == "registry" {
-			SetupRegistry(memory.NewRegistry())
+		// with MICRO_REGISTRY=kubernetes the services are discovered from the endpointslices of the
+		// kubernetes services, and with MICRO_REGISTRY=dns from the records of headless services.
+		// Otherwise the registry service uses the memory registry and the other core services will
+		// use the default rpc client and call the registry service
+		k8sRegistryEnabled := true
+		switch os.Getenv("MICRO_REGISTRY") {
+		case "kubernetes":
+			SetupRegistry(k8sRegistry.NewRegistry())
+		case "dns":
+			SetupRegistry(dnsRegistry.NewRegistry(dnsRegistryOpts()...))
+		default:
+			k8sRegistryEnabled = false
+			if ctx.Args().Get(1) == "registry" {
+				SetupRegistry(memory.NewRegistry())
+			}
 		}
 
 		// the broker service uses the memory broker, the other core services will use the default
@@ -209,9 +235,12 @@ var Kubernetes = &Profile{
 		}
 		SetupConfigSecretKey(ctx)
 
-		// Use k8s routing which is DNS based
-		router.DefaultRouter = k8sRouter.NewRouter()
-		client.DefaultClient.Init(client.Router(router.DefaultRouter))
+		// Use k8s routing which is DNS based, unless the services are discovered from kubernetes
+		// in which case they're routed to the pods directly
+		if !k8sRegistryEnabled {
+			router.DefaultRouter = k8sRouter.NewRouter()
+			client.DefaultClient.Init(client.Router(router.DefaultRouter))
+		}
 
 		// Configure tracing with Jaeger:
 		tracingServiceName := ctx.Args().Get(1)
@@ -254,6 +283,75 @@ var Test = &Profile{
 	},
 }
 
+// localStore returns the store used by the local profile, set using MICRO_STORE. Badger holds an
+// exclusive lock on its directory so only the store service opens it, the other services call
+// the store service.
+func localStore(ctx *cli.Context) microStore.Store {
+	if ctx.String("store") != "badger" {
+		return file.NewStore(file.WithDir(filepath.Join(user.Dir, "server", "store")))
+	}
+	if ctx.Args().Get(1) == "store" {
+		return badger.NewStore(badger.WithDir(filepath.Join(user.Dir, "server", "badger")))
+	}
+	return storeClient.NewStore()
+}
+
+// localRetention returns the retention policy of the events kept by the local profile, set using
+// MICRO_EVENTS_RETENTION_MAX_AGE, MICRO_EVENTS_RETENTION_MAX_EVENTS and MICRO_EVENTS_RETENTION_MAX_SIZE.
+// The topics in the comma separated MICRO_EVENTS_COMPACTED_TOPICS are also compacted. Only the
+// events service prunes the events, the other services call the events service.
+func localRetention() []segment.Option {
+	var r evStore.Retention
+	if v := os.Getenv("MICRO_EVENTS_RETENTION_MAX_AGE"); len(v) > 0 {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			logger.Fatalf("Invalid MICRO_EVENTS_RETENTION_MAX_AGE %s: %v", v, err)
+		}
+		r.MaxAge = d
+	}
+	if v := os.Getenv("MICRO_EVENTS_RETENTION_MAX_EVENTS"); len(v) > 0 {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Fatalf("Invalid MICRO_EVENTS_RETENTION_MAX_EVENTS %s: %v", v, err)
+		}
+		r.MaxEvents = n
+	}
+	if v := os.Getenv("MICRO_EVENTS_RETENTION_MAX_SIZE"); len(v) > 0 {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			logger.Fatalf("Invalid MICRO_EVENTS_RETENTION_MAX_SIZE %s: %v", v, err)
+		}
+		r.MaxSize = n
+	}
+	opts := []segment.Option{segment.WithDefaultRetention(r)}
+	if v := os.Getenv("MICRO_EVENTS_COMPACTED_TOPICS"); len(v) > 0 {
+		compacted := r
+		compacted.Compact = true
+		for _, topic := range strings.Split(v, ",") {
+			opts = append(opts, segment.WithRetention(strings.TrimSpace(topic), compacted))
+		}
+	}
+	return opts
+}
+
+// dnsRegistryOpts returns the options of the dns registry used by the kubernetes profile. The
+// services are resolved in the namespace of their domain, unless MICRO_REGISTRY_DNS_TEMPLATE is
+// set, and MICRO_REGISTRY_DNS_PORT sets the port of the services resolved from A records.
+func dnsRegistryOpts() []registry.Option {
+	opts := []registry.Option{dnsRegistry.Template("{service}.{domain}.svc.cluster.local")}
+	if v := os.Getenv("MICRO_REGISTRY_DNS_TEMPLATE"); len(v) > 0 {
+		opts = append(opts, dnsRegistry.Template(v))
+	}
+	if v := os.Getenv("MICRO_REGISTRY_DNS_PORT"); len(v) > 0 {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Fatalf("Invalid MICRO_REGISTRY_DNS_PORT %s: %v", v, err)
+		}
+		opts = append(opts, dnsRegistry.Port(port))
+	}
+	return opts
+}
+
 // SetupRegistry configures the registry
 func SetupRegistry(reg registry.Registry) {
 	registry.DefaultRegistry = reg
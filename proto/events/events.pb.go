@@ -23,13 +23,17 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type PublishRequest struct {
-	Topic                string            `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
-	Metadata             map[string]string `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	Payload              []byte            `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
-	Timestamp            int64             `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Topic     string            `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Metadata  map[string]string `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Payload   []byte            `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	Timestamp int64             `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// events with the same key published to the topic within the deduplication window are dropped
+	IdempotencyKey string `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// events with the same ordering key are delivered in the order they were published
+	OrderingKey          string   `protobuf:"bytes,6,opt,name=ordering_key,json=orderingKey,proto3" json:"ordering_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *PublishRequest) Reset()         { *m = PublishRequest{} }
@@ -85,6 +89,20 @@ func (m *PublishRequest) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *PublishRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *PublishRequest) GetOrderingKey() string {
+	if m != nil {
+		return m.OrderingKey
+	}
+	return ""
+}
+
 type PublishResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -122,8 +140,17 @@ type ConsumeRequest struct {
 	Offset  int64  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
 	AutoAck bool   `protobuf:"varint,4,opt,name=auto_ack,json=autoAck,proto3" json:"auto_ack,omitempty"`
 	// duration in nanoseconds
-	AckWait              int64    `protobuf:"varint,5,opt,name=ack_wait,json=ackWait,proto3" json:"ack_wait,omitempty"`
-	RetryLimit           int64    `protobuf:"varint,6,opt,name=retry_limit,json=retryLimit,proto3" json:"retry_limit,omitempty"`
+	AckWait    int64 `protobuf:"varint,5,opt,name=ack_wait,json=ackWait,proto3" json:"ack_wait,omitempty"`
+	RetryLimit int64 `protobuf:"varint,6,opt,name=retry_limit,json=retryLimit,proto3" json:"retry_limit,omitempty"`
+	// sequence to start consuming from, for streams which number their events
+	Sequence uint64 `protobuf:"varint,7,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// topic events are published to once they reach the retry limit
+	DeadLetterTopic string `protobuf:"bytes,8,opt,name=dead_letter_topic,json=deadLetterTopic,proto3" json:"dead_letter_topic,omitempty"`
+	// only the events matching the filter expression are sent
+	Filter string `protobuf:"bytes,9,opt,name=filter,proto3" json:"filter,omitempty"`
+	// bounds of the exponential backoff between redeliveries in nanoseconds
+	BackoffMin           int64    `protobuf:"varint,10,opt,name=backoff_min,json=backoffMin,proto3" json:"backoff_min,omitempty"`
+	BackoffMax           int64    `protobuf:"varint,11,opt,name=backoff_max,json=backoffMax,proto3" json:"backoff_max,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -196,12 +223,50 @@ func (m *ConsumeRequest) GetRetryLimit() int64 {
 	return 0
 }
 
+func (m *ConsumeRequest) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *ConsumeRequest) GetDeadLetterTopic() string {
+	if m != nil {
+		return m.DeadLetterTopic
+	}
+	return ""
+}
+
+func (m *ConsumeRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *ConsumeRequest) GetBackoffMin() int64 {
+	if m != nil {
+		return m.BackoffMin
+	}
+	return 0
+}
+
+func (m *ConsumeRequest) GetBackoffMax() int64 {
+	if m != nil {
+		return m.BackoffMax
+	}
+	return 0
+}
+
 type Event struct {
 	Id                   string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Topic                string            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
 	Metadata             map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	Payload              []byte            `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
 	Timestamp            int64             `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Sequence             uint64            `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	OrderingKey          string            `protobuf:"bytes,7,opt,name=ordering_key,json=orderingKey,proto3" json:"ordering_key,omitempty"`
+	Partition            int32             `protobuf:"varint,8,opt,name=partition,proto3" json:"partition,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -267,6 +332,27 @@ func (m *Event) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *Event) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Event) GetOrderingKey() string {
+	if m != nil {
+		return m.OrderingKey
+	}
+	return ""
+}
+
+func (m *Event) GetPartition() int32 {
+	if m != nil {
+		return m.Partition
+	}
+	return 0
+}
+
 type ReadRequest struct {
 	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
 	Limit                uint64   `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
@@ -439,6 +525,234 @@ func (m *WriteResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_WriteResponse proto.InternalMessageInfo
 
+type DeleteRequest struct {
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Id                   string   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ec31f2d2a3db598, []int{8}
+}
+
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(m, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ec31f2d2a3db598, []int{9}
+}
+
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+}
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(m, src)
+}
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
+}
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+
+type StatusRequest struct {
+	// the groups of every topic are returned if it's blank
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
+func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()    {}
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ec31f2d2a3db598, []int{10}
+}
+
+func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusRequest.Unmarshal(m, b)
+}
+func (m *StatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusRequest.Marshal(b, m, deterministic)
+}
+func (m *StatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusRequest.Merge(m, src)
+}
+func (m *StatusRequest) XXX_Size() int {
+	return xxx_messageInfo_StatusRequest.Size(m)
+}
+func (m *StatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusRequest proto.InternalMessageInfo
+
+func (m *StatusRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+type StatusResponse struct {
+	Groups               []*GroupStatus `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *StatusResponse) Reset()         { *m = StatusResponse{} }
+func (m *StatusResponse) String() string { return proto.CompactTextString(m) }
+func (*StatusResponse) ProtoMessage()    {}
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ec31f2d2a3db598, []int{11}
+}
+
+func (m *StatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusResponse.Unmarshal(m, b)
+}
+func (m *StatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusResponse.Marshal(b, m, deterministic)
+}
+func (m *StatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusResponse.Merge(m, src)
+}
+func (m *StatusResponse) XXX_Size() int {
+	return xxx_messageInfo_StatusResponse.Size(m)
+}
+func (m *StatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusResponse proto.InternalMessageInfo
+
+func (m *StatusResponse) GetGroups() []*GroupStatus {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type GroupStatus struct {
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Group                string   `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Consumers            int64    `protobuf:"varint,3,opt,name=consumers,proto3" json:"consumers,omitempty"`
+	Pending              uint64   `protobuf:"varint,4,opt,name=pending,proto3" json:"pending,omitempty"`
+	Unacked              uint64   `protobuf:"varint,5,opt,name=unacked,proto3" json:"unacked,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupStatus) Reset()         { *m = GroupStatus{} }
+func (m *GroupStatus) String() string { return proto.CompactTextString(m) }
+func (*GroupStatus) ProtoMessage()    {}
+func (*GroupStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ec31f2d2a3db598, []int{12}
+}
+
+func (m *GroupStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupStatus.Unmarshal(m, b)
+}
+func (m *GroupStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupStatus.Marshal(b, m, deterministic)
+}
+func (m *GroupStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupStatus.Merge(m, src)
+}
+func (m *GroupStatus) XXX_Size() int {
+	return xxx_messageInfo_GroupStatus.Size(m)
+}
+func (m *GroupStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupStatus proto.InternalMessageInfo
+
+func (m *GroupStatus) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *GroupStatus) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *GroupStatus) GetConsumers() int64 {
+	if m != nil {
+		return m.Consumers
+	}
+	return 0
+}
+
+func (m *GroupStatus) GetPending() uint64 {
+	if m != nil {
+		return m.Pending
+	}
+	return 0
+}
+
+func (m *GroupStatus) GetUnacked() uint64 {
+	if m != nil {
+		return m.Unacked
+	}
+	return 0
+}
+
 type AckRequest struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Success              bool     `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
@@ -451,7 +765,7 @@ func (m *AckRequest) Reset()         { *m = AckRequest{} }
 func (m *AckRequest) String() string { return proto.CompactTextString(m) }
 func (*AckRequest) ProtoMessage()    {}
 func (*AckRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ec31f2d2a3db598, []int{8}
+	return fileDescriptor_8ec31f2d2a3db598, []int{13}
 }
 
 func (m *AckRequest) XXX_Unmarshal(b []byte) error {
@@ -497,49 +811,72 @@ func init() {
 	proto.RegisterType((*ReadResponse)(nil), "events.ReadResponse")
 	proto.RegisterType((*WriteRequest)(nil), "events.WriteRequest")
 	proto.RegisterType((*WriteResponse)(nil), "events.WriteResponse")
+	proto.RegisterType((*DeleteRequest)(nil), "events.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "events.DeleteResponse")
+	proto.RegisterType((*StatusRequest)(nil), "events.StatusRequest")
+	proto.RegisterType((*StatusResponse)(nil), "events.StatusResponse")
+	proto.RegisterType((*GroupStatus)(nil), "events.GroupStatus")
 	proto.RegisterType((*AckRequest)(nil), "events.AckRequest")
 }
 
 func init() { proto.RegisterFile("events/events.proto", fileDescriptor_8ec31f2d2a3db598) }
 
 var fileDescriptor_8ec31f2d2a3db598 = []byte{
-	// 562 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0x95, 0xed, 0x38, 0x4e, 0x27, 0x1f, 0x85, 0x6d, 0x28, 0x26, 0x20, 0x11, 0x19, 0x90, 0x72,
-	0x40, 0x09, 0xa4, 0x7c, 0xa9, 0xbd, 0x50, 0x50, 0x6e, 0x20, 0xc1, 0xf6, 0x50, 0x89, 0x4b, 0xb4,
-	0x71, 0xb6, 0xed, 0xca, 0x71, 0xd6, 0x78, 0xd7, 0x81, 0xfc, 0x26, 0x7e, 0x12, 0xe2, 0xbf, 0x20,
-	0xef, 0xae, 0x13, 0x3b, 0x85, 0x8a, 0x03, 0x97, 0xc4, 0x6f, 0xc6, 0x33, 0x9e, 0xf7, 0xde, 0xec,
-	0xc2, 0x01, 0x5d, 0xd1, 0xa5, 0x14, 0x23, 0xfd, 0x37, 0x4c, 0x52, 0x2e, 0x39, 0xaa, 0x6b, 0x14,
-	0xfc, 0xb2, 0xa0, 0xf3, 0x29, 0x9b, 0x2d, 0x98, 0xb8, 0xc2, 0xf4, 0x6b, 0x46, 0x85, 0x44, 0x5d,
-	0x70, 0x25, 0x4f, 0x58, 0xe8, 0x5b, 0x7d, 0x6b, 0xb0, 0x87, 0x35, 0x40, 0x6f, 0xa1, 0x11, 0x53,
-	0x49, 0xe6, 0x44, 0x12, 0xdf, 0xee, 0x3b, 0x83, 0xe6, 0xf8, 0xf1, 0xd0, 0x74, 0xac, 0xd6, 0x0f,
-	0x3f, 0x9a, 0xd7, 0x26, 0x4b, 0x99, 0xae, 0xf1, 0xa6, 0x0a, 0xf9, 0xe0, 0x25, 0x64, 0xbd, 0xe0,
-	0x64, 0xee, 0x3b, 0x7d, 0x6b, 0xd0, 0xc2, 0x05, 0x44, 0x0f, 0x60, 0x4f, 0xb2, 0x98, 0x0a, 0x49,
-	0xe2, 0xc4, 0xaf, 0xf5, 0xad, 0x81, 0x83, 0xb7, 0x81, 0xde, 0x09, 0xb4, 0x2b, 0x2d, 0xd1, 0x2d,
-	0x70, 0x22, 0xba, 0x36, 0xe3, 0xe5, 0x8f, 0xf9, 0xc8, 0x2b, 0xb2, 0xc8, 0xa8, 0x6f, 0xeb, 0x91,
-	0x15, 0x38, 0xb6, 0xdf, 0x58, 0xc1, 0x6d, 0xd8, 0xdf, 0x8c, 0x27, 0x12, 0xbe, 0x14, 0x34, 0xf8,
-	0x61, 0x41, 0xe7, 0x3d, 0x5f, 0x8a, 0x2c, 0xa6, 0x25, 0xca, 0x97, 0x29, 0xcf, 0x92, 0x82, 0xb2,
-	0x02, 0x5b, 0x21, 0xec, 0xb2, 0x10, 0x87, 0x50, 0xe7, 0x17, 0x17, 0x82, 0x4a, 0xc5, 0xc2, 0xc1,
-	0x06, 0xa1, 0x7b, 0xd0, 0x20, 0x99, 0xe4, 0x53, 0x12, 0x46, 0x8a, 0x43, 0x03, 0x7b, 0x39, 0x3e,
-	0x0d, 0x23, 0x95, 0x0a, 0xa3, 0xe9, 0x37, 0xc2, 0xa4, 0xef, 0xaa, 0x22, 0x8f, 0x84, 0xd1, 0x39,
-	0x61, 0x12, 0x3d, 0x84, 0x66, 0x4a, 0x65, 0xba, 0x9e, 0x2e, 0x58, 0xcc, 0xa4, 0x5f, 0x57, 0x59,
-	0x50, 0xa1, 0x0f, 0x79, 0x24, 0xf8, 0x69, 0x81, 0x3b, 0xc9, 0x75, 0x46, 0x1d, 0xb0, 0xd9, 0xdc,
-	0x4c, 0x68, 0xb3, 0xf9, 0x5f, 0xc6, 0x7b, 0x5d, 0xf2, 0xc9, 0x51, 0x3e, 0xdd, 0x2f, 0x7c, 0x52,
-	0x6d, 0xfe, 0xc5, 0x9e, 0xda, 0x0d, 0xf6, 0xb8, 0xff, 0xd5, 0x9e, 0xcf, 0xd0, 0xc4, 0x94, 0xcc,
-	0x6f, 0x5e, 0xbd, 0x2e, 0xb8, 0x5a, 0x9d, 0xbc, 0xbc, 0x86, 0x35, 0xd8, 0xf1, 0xa1, 0x56, 0xf8,
-	0x10, 0xbc, 0x84, 0x96, 0x6e, 0xa9, 0xed, 0x46, 0x4f, 0xc0, 0xec, 0xba, 0x6f, 0x29, 0x39, 0xda,
-	0x15, 0x39, 0x70, 0x71, 0x10, 0x26, 0xd0, 0x3a, 0x4f, 0x99, 0xdc, 0xac, 0xc4, 0x23, 0x70, 0x55,
-	0x46, 0x8d, 0x72, 0xad, 0x4a, 0xe7, 0x72, 0xaa, 0x52, 0x2e, 0xd4, 0x5c, 0x0e, 0xce, 0x1f, 0x83,
-	0x7d, 0x68, 0x9b, 0x36, 0x66, 0xdb, 0x5e, 0x01, 0x9c, 0x86, 0x51, 0xd1, 0x75, 0xd7, 0x43, 0x1f,
-	0x3c, 0x91, 0x85, 0x21, 0x15, 0x42, 0x35, 0x69, 0xe0, 0x02, 0x8e, 0xbf, 0x43, 0xfd, 0x4c, 0xa6,
-	0x94, 0xc4, 0xe8, 0x18, 0x3c, 0xb3, 0xc2, 0xe8, 0xf0, 0xcf, 0x47, 0xae, 0x77, 0xf7, 0x5a, 0xdc,
-	0x90, 0x1f, 0x83, 0x67, 0x56, 0x7d, 0x5b, 0x5b, 0xdd, 0xfd, 0x5e, 0x95, 0xd9, 0x33, 0x6b, 0x9c,
-	0x80, 0x7b, 0x26, 0x79, 0x4a, 0xd1, 0x73, 0xa8, 0xe5, 0x4a, 0xa2, 0x83, 0xe2, 0x8d, 0x92, 0x55,
-	0xbd, 0x6e, 0x35, 0x68, 0xbe, 0xf7, 0x02, 0x5c, 0x45, 0x1f, 0x6d, 0xd2, 0x65, 0x51, 0x7b, 0x77,
-	0x76, 0xa2, 0xba, 0xea, 0xdd, 0xf0, 0xcb, 0xd3, 0x4b, 0x26, 0xaf, 0xb2, 0xd9, 0x30, 0xe4, 0xf1,
-	0x28, 0x66, 0x61, 0xca, 0xcd, 0xef, 0xea, 0x68, 0xa4, 0xee, 0x2b, 0x7d, 0x79, 0x9d, 0xe8, 0xea,
-	0x59, 0x5d, 0xc5, 0x8e, 0x7e, 0x07, 0x00, 0x00, 0xff, 0xff, 0xd2, 0x87, 0x7d, 0x33, 0xda, 0x04,
-	0x00, 0x00,
+	// 849 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x51, 0x6f, 0x1b, 0x45,
+	0x10, 0xd6, 0xdd, 0xf9, 0xce, 0xce, 0x38, 0x76, 0xda, 0x6d, 0x1a, 0x0e, 0x83, 0x84, 0x39, 0xa8,
+	0xb0, 0x00, 0x39, 0x90, 0x52, 0x82, 0x5a, 0x21, 0x51, 0x20, 0xe2, 0xa1, 0xad, 0x04, 0x1b, 0xa4,
+	0x4a, 0xbc, 0x58, 0x9b, 0xbb, 0x75, 0xba, 0xb2, 0xef, 0xd6, 0xdc, 0xed, 0x85, 0xfa, 0x37, 0xf0,
+	0x4b, 0xfa, 0xc8, 0x1b, 0x7f, 0x82, 0xff, 0x84, 0x76, 0x76, 0xd7, 0xf6, 0x5d, 0x48, 0x84, 0xd4,
+	0x97, 0xc4, 0xf3, 0xed, 0xcc, 0xf8, 0x9b, 0x6f, 0xbf, 0x1d, 0xc3, 0x3d, 0x7e, 0xc5, 0x0b, 0x55,
+	0x1d, 0x9b, 0x7f, 0xd3, 0x55, 0x29, 0x95, 0x24, 0x91, 0x89, 0x92, 0x37, 0x3e, 0x0c, 0x7f, 0xae,
+	0x2f, 0x96, 0xa2, 0x7a, 0x45, 0xf9, 0xef, 0x35, 0xaf, 0x14, 0x39, 0x84, 0x50, 0xc9, 0x95, 0x48,
+	0x63, 0x6f, 0xec, 0x4d, 0xf6, 0xa8, 0x09, 0xc8, 0x77, 0xd0, 0xcb, 0xb9, 0x62, 0x19, 0x53, 0x2c,
+	0xf6, 0xc7, 0xc1, 0xa4, 0x7f, 0xf2, 0xf1, 0xd4, 0x76, 0x6c, 0xd6, 0x4f, 0x5f, 0xd8, 0xb4, 0xb3,
+	0x42, 0x95, 0x6b, 0xba, 0xa9, 0x22, 0x31, 0x74, 0x57, 0x6c, 0xbd, 0x94, 0x2c, 0x8b, 0x83, 0xb1,
+	0x37, 0xd9, 0xa7, 0x2e, 0x24, 0xef, 0xc3, 0x9e, 0x12, 0x39, 0xaf, 0x14, 0xcb, 0x57, 0x71, 0x67,
+	0xec, 0x4d, 0x02, 0xba, 0x05, 0xc8, 0x27, 0x70, 0x20, 0x32, 0x9e, 0xaf, 0xa4, 0xe2, 0x45, 0xba,
+	0x9e, 0x2d, 0xf8, 0x3a, 0x0e, 0x91, 0xd9, 0x70, 0x07, 0x7e, 0xc6, 0xd7, 0xe4, 0x43, 0xd8, 0x97,
+	0x65, 0xc6, 0x4b, 0x51, 0x5c, 0x62, 0x56, 0x84, 0x59, 0x7d, 0x87, 0x3d, 0xe3, 0xeb, 0xd1, 0x13,
+	0x18, 0x34, 0xe8, 0x91, 0x3b, 0x10, 0xe8, 0x54, 0x33, 0xaa, 0xfe, 0xa8, 0xc7, 0xbf, 0x62, 0xcb,
+	0x9a, 0xc7, 0xbe, 0x19, 0x1f, 0x83, 0xc7, 0xfe, 0x37, 0x5e, 0x72, 0x17, 0x0e, 0x36, 0xa3, 0x56,
+	0x2b, 0x59, 0x54, 0x3c, 0xf9, 0xc7, 0x87, 0xe1, 0x0f, 0xb2, 0xa8, 0xea, 0x9c, 0xef, 0xc8, 0x77,
+	0x59, 0xca, 0x7a, 0xe5, 0xe4, 0xc3, 0x60, 0x2b, 0xaa, 0xbf, 0x2b, 0xea, 0x11, 0x44, 0x72, 0x3e,
+	0xaf, 0xb8, 0x42, 0x45, 0x02, 0x6a, 0x23, 0xf2, 0x2e, 0xf4, 0x58, 0xad, 0xe4, 0x8c, 0xa5, 0x0b,
+	0xd4, 0xa3, 0x47, 0xbb, 0x3a, 0x7e, 0x9a, 0x2e, 0xf0, 0x28, 0x5d, 0xcc, 0xfe, 0x60, 0x42, 0xa1,
+	0x0c, 0x01, 0xed, 0xb2, 0x74, 0xf1, 0x92, 0x09, 0x45, 0x3e, 0x80, 0x7e, 0xc9, 0x55, 0xb9, 0x9e,
+	0x2d, 0x45, 0x2e, 0x14, 0x8e, 0x1f, 0x50, 0x40, 0xe8, 0xb9, 0x46, 0xc8, 0x08, 0x7a, 0x95, 0x66,
+	0x59, 0xa4, 0x3c, 0xee, 0x8e, 0xbd, 0x49, 0x87, 0x6e, 0x62, 0xf2, 0x29, 0xdc, 0xcd, 0x38, 0xcb,
+	0x66, 0x4b, 0xae, 0x14, 0x2f, 0x67, 0x86, 0x6c, 0x0f, 0xc9, 0x1e, 0xe8, 0x83, 0xe7, 0x88, 0xff,
+	0xea, 0x68, 0xcf, 0xc5, 0x52, 0xf1, 0x32, 0xde, 0xc3, 0x04, 0x1b, 0x69, 0x02, 0x17, 0x2c, 0x5d,
+	0xc8, 0xf9, 0x7c, 0x96, 0x8b, 0x22, 0x06, 0x43, 0xc0, 0x42, 0x2f, 0x44, 0xd1, 0x48, 0x60, 0xaf,
+	0xe3, 0x7e, 0x33, 0x81, 0xbd, 0x4e, 0xfe, 0xf6, 0x21, 0x3c, 0xd3, 0xae, 0x22, 0x43, 0xf0, 0x45,
+	0x66, 0x35, 0xf4, 0x45, 0x76, 0x83, 0x80, 0xa7, 0x3b, 0xae, 0x0c, 0xd0, 0x95, 0xef, 0x39, 0x57,
+	0x62, 0x9b, 0xff, 0x63, 0xc6, 0xce, 0x2d, 0x66, 0x0c, 0xdb, 0x66, 0xdc, 0x95, 0x30, 0x6a, 0x49,
+	0xd8, 0xf6, 0x5f, 0xf7, 0x9a, 0xff, 0x74, 0xf3, 0x15, 0x2b, 0x95, 0x50, 0x42, 0x16, 0xa8, 0x6e,
+	0x48, 0xb7, 0xc0, 0xdb, 0xb9, 0xf3, 0x17, 0xe8, 0x53, 0xce, 0xb2, 0xdb, 0x5f, 0xf1, 0x21, 0x84,
+	0xc6, 0x1c, 0x3e, 0x72, 0x37, 0x41, 0xcb, 0x86, 0x1d, 0x67, 0xc3, 0xe4, 0x11, 0xec, 0x9b, 0x96,
+	0xc6, 0xed, 0xe4, 0x01, 0xd8, 0xb5, 0x11, 0x7b, 0xa8, 0xf5, 0xa0, 0xa1, 0x35, 0x75, 0x3b, 0xe5,
+	0x0c, 0xf6, 0x5f, 0x96, 0x42, 0x6d, 0x5e, 0xc4, 0x47, 0x10, 0xe2, 0x09, 0x52, 0xb9, 0x56, 0x65,
+	0xce, 0xf4, 0xa8, 0x4a, 0x2d, 0x91, 0x57, 0x40, 0xf5, 0xc7, 0xe4, 0x00, 0x06, 0xb6, 0x8d, 0x7d,
+	0x6c, 0x8f, 0x60, 0xf0, 0x23, 0x5f, 0x72, 0xc5, 0x6f, 0x9f, 0xd1, 0x38, 0xc7, 0x77, 0xce, 0x49,
+	0xee, 0xc0, 0xd0, 0x95, 0xd9, 0x46, 0x0f, 0x60, 0x70, 0xae, 0x98, 0xaa, 0xab, 0x5b, 0x1b, 0x25,
+	0xdf, 0xc2, 0xd0, 0xa5, 0x59, 0x01, 0x3e, 0x83, 0x08, 0x9f, 0xb3, 0x13, 0xe0, 0x9e, 0x1b, 0xe5,
+	0x27, 0x8d, 0xda, 0x64, 0x9b, 0x92, 0xfc, 0xe9, 0x41, 0x7f, 0x07, 0xbf, 0xf9, 0x46, 0x30, 0xdf,
+	0x5d, 0x28, 0x06, 0xda, 0x27, 0xa9, 0x59, 0x2b, 0x65, 0x65, 0x77, 0xc3, 0x16, 0x40, 0xf3, 0xf2,
+	0x22, 0x13, 0xc5, 0x25, 0x9a, 0xb7, 0x43, 0x5d, 0xa8, 0x4f, 0xea, 0x82, 0xa5, 0x0b, 0x9e, 0xa1,
+	0x75, 0x3b, 0xd4, 0x85, 0xc9, 0xd7, 0x00, 0x4f, 0xd3, 0x85, 0x1b, 0xb8, 0xfd, 0xba, 0x62, 0xe8,
+	0x56, 0x75, 0x9a, 0xf2, 0xaa, 0x42, 0x1e, 0x3d, 0xea, 0xc2, 0x93, 0xbf, 0x3c, 0x88, 0xce, 0x55,
+	0xc9, 0x59, 0x4e, 0x1e, 0x43, 0xd7, 0xee, 0x3f, 0x72, 0xf4, 0xdf, 0xbb, 0x7f, 0xf4, 0xce, 0x35,
+	0xdc, 0x2a, 0x77, 0x02, 0x5d, 0xbb, 0x27, 0xb7, 0xb5, 0xcd, 0xc5, 0x39, 0x6a, 0xfa, 0xe2, 0x0b,
+	0x8f, 0x9c, 0x42, 0x64, 0xa5, 0xbb, 0xef, 0x8e, 0x1a, 0xd7, 0x36, 0x3a, 0x6a, 0xc3, 0xe6, 0xcb,
+	0x4e, 0xde, 0x78, 0x10, 0x9e, 0x2b, 0x59, 0x72, 0xf2, 0x25, 0x74, 0xb4, 0x83, 0xc9, 0xe6, 0xa2,
+	0x76, 0x9e, 0xc8, 0xe8, 0xb0, 0x09, 0x5a, 0xa6, 0x5f, 0x41, 0x88, 0xb6, 0x23, 0x9b, 0xe3, 0x5d,
+	0x33, 0x8f, 0xee, 0xb7, 0x50, 0x5b, 0x75, 0x0a, 0x91, 0x31, 0xd9, 0x96, 0x6b, 0xc3, 0xab, 0xa3,
+	0xa3, 0x36, 0x6c, 0x0a, 0xbf, 0x9f, 0xfe, 0xf6, 0xf9, 0xa5, 0x50, 0xaf, 0xea, 0x8b, 0x69, 0x2a,
+	0xf3, 0xe3, 0x5c, 0xa4, 0xa5, 0xb4, 0x7f, 0xaf, 0x1e, 0x1e, 0xe3, 0x6f, 0xb5, 0xf9, 0xe1, 0x7e,
+	0x62, 0xca, 0x2f, 0x22, 0xc4, 0x1e, 0xfe, 0x3b, 0x00, 0x3f, 0xec, 0xf5, 0x0d, 0xd6, 0x07, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -556,6 +893,7 @@ const _ = grpc.SupportPackageIsVersion4
 type StreamClient interface {
 	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
 	Consume(ctx context.Context, in *ConsumeRequest, opts ...grpc.CallOption) (Stream_ConsumeClient, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 }
 
 type streamClient struct {
@@ -607,10 +945,20 @@ func (x *streamConsumeClient) Recv() (*Event, error) {
 	return m, nil
 }
 
+func (c *streamClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/events.Stream/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StreamServer is the server API for Stream service.
 type StreamServer interface {
 	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
 	Consume(*ConsumeRequest, Stream_ConsumeServer) error
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
 }
 
 func RegisterStreamServer(s *grpc.Server, srv StreamServer) {
@@ -656,6 +1004,24 @@ func (x *streamConsumeServer) Send(m *Event) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Stream_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.Stream/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Stream_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "events.Stream",
 	HandlerType: (*StreamServer)(nil),
@@ -664,6 +1030,10 @@ var _Stream_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Publish",
 			Handler:    _Stream_Publish_Handler,
 		},
+		{
+			MethodName: "Status",
+			Handler:    _Stream_Status_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -681,6 +1051,7 @@ var _Stream_serviceDesc = grpc.ServiceDesc{
 type StoreClient interface {
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
 	Write(ctx context.Context, in *WriteRequest, opts ...grpc.CallOption) (*WriteResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 }
 
 type storeClient struct {
@@ -709,10 +1080,20 @@ func (c *storeClient) Write(ctx context.Context, in *WriteRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *storeClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/events.Store/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StoreServer is the server API for Store service.
 type StoreServer interface {
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
 	Write(context.Context, *WriteRequest) (*WriteResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 }
 
 func RegisterStoreServer(s *grpc.Server, srv StoreServer) {
@@ -755,6 +1136,24 @@ func _Store_Write_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Store_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoreServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.Store/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoreServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Store_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "events.Store",
 	HandlerType: (*StoreServer)(nil),
@@ -767,6 +1166,10 @@ var _Store_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Write",
 			Handler:    _Store_Write_Handler,
 		},
+		{
+			MethodName: "Delete",
+			Handler:    _Store_Delete_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "events/events.proto",
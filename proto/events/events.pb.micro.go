@@ -44,6 +44,7 @@ func NewStreamEndpoints() []*api.Endpoint {
 type StreamService interface {
 	Publish(ctx context.Context, in *PublishRequest, opts ...client.CallOption) (*PublishResponse, error)
 	Consume(ctx context.Context, in *ConsumeRequest, opts ...client.CallOption) (Stream_ConsumeService, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...client.CallOption) (*StatusResponse, error)
 }
 
 type streamService struct {
@@ -117,17 +118,29 @@ func (x *streamServiceConsume) Recv() (*Event, error) {
 	return m, nil
 }
 
+func (c *streamService) Status(ctx context.Context, in *StatusRequest, opts ...client.CallOption) (*StatusResponse, error) {
+	req := c.c.NewRequest(c.name, "Stream.Status", in)
+	out := new(StatusResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Stream service
 
 type StreamHandler interface {
 	Publish(context.Context, *PublishRequest, *PublishResponse) error
 	Consume(context.Context, *ConsumeRequest, Stream_ConsumeStream) error
+	Status(context.Context, *StatusRequest, *StatusResponse) error
 }
 
 func RegisterStreamHandler(s server.Server, hdlr StreamHandler, opts ...server.HandlerOption) error {
 	type stream interface {
 		Publish(ctx context.Context, in *PublishRequest, out *PublishResponse) error
 		Consume(ctx context.Context, stream server.Stream) error
+		Status(ctx context.Context, in *StatusRequest, out *StatusResponse) error
 	}
 	type Stream struct {
 		stream
@@ -184,6 +197,10 @@ func (x *streamConsumeStream) Send(m *Event) error {
 	return x.stream.Send(m)
 }
 
+func (h *streamHandler) Status(ctx context.Context, in *StatusRequest, out *StatusResponse) error {
+	return h.StreamHandler.Status(ctx, in, out)
+}
+
 // Api Endpoints for Store service
 
 func NewStoreEndpoints() []*api.Endpoint {
@@ -195,6 +212,7 @@ func NewStoreEndpoints() []*api.Endpoint {
 type StoreService interface {
 	Read(ctx context.Context, in *ReadRequest, opts ...client.CallOption) (*ReadResponse, error)
 	Write(ctx context.Context, in *WriteRequest, opts ...client.CallOption) (*WriteResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...client.CallOption) (*DeleteResponse, error)
 }
 
 type storeService struct {
@@ -229,17 +247,29 @@ func (c *storeService) Write(ctx context.Context, in *WriteRequest, opts ...clie
 	return out, nil
 }
 
+func (c *storeService) Delete(ctx context.Context, in *DeleteRequest, opts ...client.CallOption) (*DeleteResponse, error) {
+	req := c.c.NewRequest(c.name, "Store.Delete", in)
+	out := new(DeleteResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Store service
 
 type StoreHandler interface {
 	Read(context.Context, *ReadRequest, *ReadResponse) error
 	Write(context.Context, *WriteRequest, *WriteResponse) error
+	Delete(context.Context, *DeleteRequest, *DeleteResponse) error
 }
 
 func RegisterStoreHandler(s server.Server, hdlr StoreHandler, opts ...server.HandlerOption) error {
 	type store interface {
 		Read(ctx context.Context, in *ReadRequest, out *ReadResponse) error
 		Write(ctx context.Context, in *WriteRequest, out *WriteResponse) error
+		Delete(ctx context.Context, in *DeleteRequest, out *DeleteResponse) error
 	}
 	type Store struct {
 		store
@@ -259,3 +289,7 @@ func (h *storeHandler) Read(ctx context.Context, in *ReadRequest, out *ReadRespo
 func (h *storeHandler) Write(ctx context.Context, in *WriteRequest, out *WriteResponse) error {
 	return h.StoreHandler.Write(ctx, in, out)
 }
+
+func (h *storeHandler) Delete(ctx context.Context, in *DeleteRequest, out *DeleteResponse) error {
+	return h.StoreHandler.Delete(ctx, in, out)
+}
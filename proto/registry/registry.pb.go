@@ -368,6 +368,7 @@ type Result struct {
 	Action               string   `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
 	Service              *Service `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
 	Timestamp            int64    `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Token                string   `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -419,6 +420,13 @@ func (m *Result) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *Result) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
 type EmptyResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -451,11 +459,13 @@ func (m *EmptyResponse) XXX_DiscardUnknown() {
 var xxx_messageInfo_EmptyResponse proto.InternalMessageInfo
 
 type GetRequest struct {
-	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
-	Options              *Options `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Service string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Options *Options `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// metadata the nodes must match
+	Filters              map[string]string `protobuf:"bytes,3,rep,name=filters,proto3" json:"filters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *GetRequest) Reset()         { *m = GetRequest{} }
@@ -497,6 +507,13 @@ func (m *GetRequest) GetOptions() *Options {
 	return nil
 }
 
+func (m *GetRequest) GetFilters() map[string]string {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
 type GetResponse struct {
 	Services             []*Service `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
@@ -616,8 +633,10 @@ func (m *ListResponse) GetServices() []*Service {
 
 type WatchRequest struct {
 	// service is optional
-	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
-	Options              *Options `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	Service string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Options *Options `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// token of the result to resume the watch after
+	Resume               string   `protobuf:"bytes,3,opt,name=resume,proto3" json:"resume,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -662,6 +681,13 @@ func (m *WatchRequest) GetOptions() *Options {
 	return nil
 }
 
+func (m *WatchRequest) GetResume() string {
+	if m != nil {
+		return m.Resume
+	}
+	return ""
+}
+
 // Event is registry event
 type Event struct {
 	// Event Id
@@ -743,6 +769,7 @@ func init() {
 	proto.RegisterType((*Result)(nil), "registry.Result")
 	proto.RegisterType((*EmptyResponse)(nil), "registry.EmptyResponse")
 	proto.RegisterType((*GetRequest)(nil), "registry.GetRequest")
+	proto.RegisterMapType((map[string]string)(nil), "registry.GetRequest.FiltersEntry")
 	proto.RegisterType((*GetResponse)(nil), "registry.GetResponse")
 	proto.RegisterType((*ListRequest)(nil), "registry.ListRequest")
 	proto.RegisterType((*ListResponse)(nil), "registry.ListResponse")
@@ -753,52 +780,55 @@ func init() {
 func init() { proto.RegisterFile("registry/registry.proto", fileDescriptor_f3f64dc2c9630278) }
 
 var fileDescriptor_f3f64dc2c9630278 = []byte{
-	// 709 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xdb, 0x6e, 0xd3, 0x4c,
-	0x10, 0x8e, 0x9d, 0xf3, 0xa4, 0x87, 0x74, 0xff, 0xfe, 0xad, 0x15, 0x55, 0x22, 0xb2, 0x90, 0x1a,
-	0xa8, 0x48, 0x4a, 0x22, 0xa4, 0xaa, 0x29, 0x42, 0x82, 0x46, 0xdc, 0x70, 0x90, 0x5c, 0x0a, 0x88,
-	0x3b, 0x37, 0x1e, 0xb5, 0x56, 0x63, 0xaf, 0xd9, 0xdd, 0x44, 0xca, 0x33, 0xf0, 0x3c, 0x70, 0xc3,
-	0x53, 0xf1, 0x06, 0x68, 0xd7, 0xeb, 0x43, 0x9b, 0x40, 0xa4, 0x02, 0x37, 0xd1, 0xec, 0xcc, 0x37,
-	0xe7, 0x6f, 0x62, 0xd8, 0x65, 0x78, 0xe9, 0x73, 0xc1, 0xe6, 0xbd, 0x44, 0xe8, 0x46, 0x8c, 0x0a,
-	0x4a, 0x6a, 0xc9, 0xdb, 0xfe, 0x66, 0x42, 0xf5, 0x0c, 0xd9, 0xcc, 0x1f, 0x23, 0x21, 0x50, 0x0a,
-	0xdd, 0x00, 0x2d, 0xa3, 0x6d, 0x74, 0xea, 0x8e, 0x92, 0x89, 0x05, 0xd5, 0x19, 0x32, 0xee, 0xd3,
-	0xd0, 0x32, 0x95, 0x3a, 0x79, 0x92, 0x21, 0xd4, 0x02, 0x14, 0xae, 0xe7, 0x0a, 0xd7, 0x2a, 0xb6,
-	0x8b, 0x9d, 0x46, 0xff, 0x5e, 0x37, 0x4d, 0xa3, 0x43, 0x76, 0x5f, 0x6b, 0xc4, 0x28, 0x14, 0x6c,
-	0xee, 0xa4, 0x0e, 0xe4, 0x10, 0xea, 0x18, 0x7a, 0x11, 0xf5, 0x43, 0xc1, 0xad, 0x92, 0xf2, 0x26,
-	0x99, 0xf7, 0x48, 0x9b, 0x9c, 0x0c, 0x44, 0xee, 0x43, 0x39, 0xa4, 0x1e, 0x72, 0xab, 0xac, 0xd0,
-	0x1b, 0x19, 0xfa, 0x0d, 0xf5, 0xd0, 0x89, 0x8d, 0xe4, 0x00, 0xaa, 0x34, 0x12, 0x3e, 0x0d, 0xb9,
-	0x55, 0x69, 0x1b, 0x9d, 0x46, 0x7f, 0x2b, 0xc3, 0xbd, 0x8d, 0x0d, 0x4e, 0x82, 0x68, 0x0d, 0x61,
-	0xfd, 0x46, 0x7d, 0xa4, 0x09, 0xc5, 0x6b, 0x9c, 0xeb, 0xfe, 0xa5, 0x48, 0xb6, 0xa1, 0x3c, 0x73,
-	0x27, 0x53, 0xd4, 0xcd, 0xc7, 0x8f, 0x63, 0xf3, 0xc8, 0xb0, 0xbf, 0x1b, 0x50, 0x92, 0x99, 0xc9,
-	0x06, 0x98, 0xbe, 0xa7, 0x7d, 0x4c, 0xdf, 0x93, 0x13, 0x73, 0x3d, 0x8f, 0x21, 0xe7, 0xc9, 0xc4,
-	0xf4, 0x53, 0xce, 0x37, 0xa2, 0x4c, 0x58, 0xc5, 0xb6, 0xd1, 0x29, 0x3a, 0x4a, 0x26, 0x47, 0xb9,
-	0x29, 0xc6, 0x73, 0xd8, 0xbb, 0xd9, 0xd9, 0xaf, 0x46, 0xf8, 0x67, 0xd5, 0xff, 0x30, 0xa0, 0x96,
-	0x4c, 0x79, 0xe9, 0xde, 0x1f, 0x40, 0x95, 0xe1, 0xe7, 0x29, 0x72, 0xa1, 0x9c, 0x1b, 0xfd, 0xcd,
-	0xac, 0xac, 0xf7, 0x32, 0x8c, 0x93, 0xd8, 0xc9, 0x01, 0xd4, 0x18, 0xf2, 0x88, 0x86, 0x1c, 0x55,
-	0x6b, 0x4b, 0xb0, 0x29, 0x80, 0x9c, 0x2c, 0xf4, 0xdb, 0x5e, 0xdc, 0xfb, 0xbf, 0xe9, 0xf9, 0x23,
-	0x94, 0x55, 0x35, 0x4b, 0xfb, 0x25, 0x50, 0x12, 0xf3, 0x28, 0xf1, 0x52, 0x32, 0xd9, 0x87, 0x8a,
-	0xf2, 0xe6, 0x9a, 0xdf, 0x0b, 0x6d, 0x69, 0xb3, 0x3d, 0x80, 0xaa, 0x26, 0x97, 0x2c, 0x48, 0x88,
-	0x89, 0x0a, 0x5d, 0x74, 0xa4, 0x48, 0x76, 0xa0, 0xe2, 0xd1, 0xc0, 0xf5, 0x93, 0x03, 0xd2, 0x2f,
-	0xfb, 0x1a, 0x2a, 0x0e, 0xf2, 0xe9, 0x44, 0x48, 0x84, 0x3b, 0x96, 0xee, 0xba, 0x22, 0xfd, 0x92,
-	0x64, 0xe6, 0xf1, 0x1d, 0xe9, 0x1d, 0x6c, 0x2d, 0x1c, 0x98, 0x93, 0x20, 0xc8, 0x1e, 0xd4, 0x85,
-	0x1f, 0x20, 0x17, 0x6e, 0x10, 0x69, 0x86, 0x65, 0x0a, 0x7b, 0x13, 0xd6, 0x47, 0x41, 0x24, 0xe6,
-	0x8e, 0xde, 0x83, 0x7d, 0x06, 0xf0, 0x12, 0x85, 0xa3, 0x57, 0x68, 0x65, 0x99, 0xe2, 0x12, 0xd2,
-	0xb0, 0xb9, 0x83, 0x32, 0x57, 0x1d, 0x94, 0x7d, 0x02, 0x0d, 0x15, 0x54, 0xef, 0xfa, 0x11, 0xd4,
-	0x74, 0x18, 0x6e, 0x19, 0x6a, 0x82, 0x4b, 0x1a, 0x48, 0x21, 0xf6, 0x31, 0x34, 0x5e, 0xf9, 0x3c,
-	0xad, 0x29, 0x97, 0xd9, 0x58, 0x99, 0xf9, 0x29, 0xac, 0xc5, 0xbe, 0x77, 0x4b, 0x7d, 0x0e, 0x6b,
-	0x1f, 0x5c, 0x31, 0xbe, 0xfa, 0xcb, 0xf3, 0xf8, 0x62, 0x40, 0x79, 0x34, 0xc3, 0x50, 0x2c, 0xfc,
-	0x49, 0xec, 0xe7, 0xe8, 0xb6, 0xd1, 0xff, 0x2f, 0x77, 0x02, 0x12, 0xfe, 0x6e, 0x1e, 0xa1, 0xe6,
-	0xe0, 0x6f, 0xd7, 0x9a, 0x67, 0x48, 0x69, 0x15, 0x43, 0x1e, 0xf6, 0xa0, 0x9e, 0x46, 0x27, 0x00,
-	0x95, 0x17, 0x0c, 0x5d, 0x81, 0xcd, 0x82, 0x94, 0x4f, 0x71, 0x82, 0x02, 0x9b, 0x86, 0x94, 0xcf,
-	0x23, 0x4f, 0xea, 0xcd, 0xfe, 0x57, 0x13, 0x6a, 0x8e, 0x0e, 0x47, 0x86, 0x8a, 0x30, 0xc9, 0xa7,
-	0x62, 0x3b, 0xcb, 0x93, 0xd1, 0xa8, 0xf5, 0xff, 0x2d, 0xad, 0xe6, 0x5a, 0x41, 0xfe, 0xcb, 0xc5,
-	0x81, 0x90, 0x91, 0xc5, 0x12, 0x5b, 0xbb, 0xb9, 0xfe, 0x6f, 0xb0, 0xb4, 0x40, 0x8e, 0x01, 0x4e,
-	0x91, 0xdd, 0xcd, 0xf7, 0x59, 0x4c, 0x0a, 0x8d, 0xe4, 0x24, 0x57, 0x5e, 0x8e, 0x68, 0xad, 0x9d,
-	0xdb, 0xea, 0x34, 0xc0, 0x13, 0x28, 0x2b, 0x5a, 0x90, 0x1c, 0x24, 0xcf, 0x93, 0x56, 0x33, 0xd3,
-	0xc7, 0xb7, 0x6c, 0x17, 0x0e, 0x8d, 0xe7, 0x83, 0x4f, 0x8f, 0x2f, 0x7d, 0x71, 0x35, 0xbd, 0xe8,
-	0x8e, 0x69, 0xd0, 0x0b, 0xfc, 0x31, 0xa3, 0xfa, 0x77, 0x36, 0xe8, 0xa9, 0x0f, 0x70, 0xfa, 0x3d,
-	0x1e, 0x26, 0xc2, 0x45, 0x45, 0x19, 0x06, 0x3f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xde, 0x1c, 0x89,
-	0xfa, 0xb4, 0x07, 0x00, 0x00,
+	// 765 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xd9, 0x6e, 0xd3, 0x4c,
+	0x14, 0x8e, 0xed, 0xac, 0x27, 0x5d, 0xf2, 0xcf, 0xdf, 0xbf, 0xb5, 0xa2, 0x4a, 0x7f, 0xb0, 0x90,
+	0x1a, 0xa8, 0x48, 0x4a, 0x22, 0xa4, 0x2a, 0x29, 0x42, 0x82, 0x06, 0x6e, 0x58, 0x24, 0xb3, 0x8a,
+	0x3b, 0x37, 0x3e, 0xb4, 0x56, 0xe3, 0x85, 0x99, 0x49, 0xa4, 0x5c, 0x72, 0xcd, 0xf3, 0xc0, 0x0d,
+	0xaf, 0xc0, 0xcb, 0xf0, 0x06, 0xc8, 0xe3, 0x19, 0xdb, 0x69, 0x02, 0x15, 0x05, 0x6e, 0xaa, 0xb3,
+	0x7c, 0xe7, 0xcc, 0x59, 0xbe, 0x93, 0x1a, 0x76, 0x28, 0x9e, 0x7a, 0x8c, 0xd3, 0x79, 0x57, 0x09,
+	0x9d, 0x88, 0x86, 0x3c, 0x24, 0x55, 0xa5, 0x5b, 0x9f, 0x75, 0xa8, 0x3c, 0x47, 0x3a, 0xf3, 0xc6,
+	0x48, 0x08, 0x14, 0x03, 0xc7, 0x47, 0x53, 0x6b, 0x69, 0xed, 0x9a, 0x2d, 0x64, 0x62, 0x42, 0x65,
+	0x86, 0x94, 0x79, 0x61, 0x60, 0xea, 0xc2, 0xac, 0x54, 0x32, 0x84, 0xaa, 0x8f, 0xdc, 0x71, 0x1d,
+	0xee, 0x98, 0x46, 0xcb, 0x68, 0xd7, 0x7b, 0xff, 0x77, 0xd2, 0x67, 0x64, 0xca, 0xce, 0x13, 0x89,
+	0x18, 0x05, 0x9c, 0xce, 0xed, 0x34, 0x80, 0x1c, 0x40, 0x0d, 0x03, 0x37, 0x0a, 0xbd, 0x80, 0x33,
+	0xb3, 0x28, 0xa2, 0x49, 0x16, 0x3d, 0x92, 0x2e, 0x3b, 0x03, 0x91, 0xeb, 0x50, 0x0a, 0x42, 0x17,
+	0x99, 0x59, 0x12, 0xe8, 0x8d, 0x0c, 0xfd, 0x34, 0x74, 0xd1, 0x4e, 0x9c, 0x64, 0x1f, 0x2a, 0x61,
+	0xc4, 0xbd, 0x30, 0x60, 0x66, 0xb9, 0xa5, 0xb5, 0xeb, 0xbd, 0x7f, 0x32, 0xdc, 0xb3, 0xc4, 0x61,
+	0x2b, 0x44, 0x73, 0x08, 0xeb, 0x0b, 0xf5, 0x91, 0x06, 0x18, 0xe7, 0x38, 0x97, 0xfd, 0xc7, 0x22,
+	0xd9, 0x82, 0xd2, 0xcc, 0x99, 0x4c, 0x51, 0x36, 0x9f, 0x28, 0x03, 0xfd, 0x50, 0xb3, 0xbe, 0x68,
+	0x50, 0x8c, 0x5f, 0x26, 0x1b, 0xa0, 0x7b, 0xae, 0x8c, 0xd1, 0x3d, 0x37, 0x9e, 0x98, 0xe3, 0xba,
+	0x14, 0x19, 0x53, 0x13, 0x93, 0x6a, 0x3c, 0xdf, 0x28, 0xa4, 0xdc, 0x34, 0x5a, 0x5a, 0xdb, 0xb0,
+	0x85, 0x4c, 0x0e, 0x73, 0x53, 0x4c, 0xe6, 0xb0, 0xbb, 0xd8, 0xd9, 0x8f, 0x46, 0xf8, 0x7b, 0xd5,
+	0x7f, 0xd3, 0xa0, 0xaa, 0xa6, 0xbc, 0x72, 0xef, 0x37, 0xa0, 0x42, 0xf1, 0xfd, 0x14, 0x19, 0x17,
+	0xc1, 0xf5, 0xde, 0x66, 0x56, 0xd6, 0xab, 0x38, 0x8d, 0xad, 0xfc, 0x64, 0x1f, 0xaa, 0x14, 0x59,
+	0x14, 0x06, 0x0c, 0x4d, 0x63, 0x35, 0x36, 0x05, 0x90, 0xa3, 0xa5, 0x7e, 0x5b, 0xcb, 0x7b, 0xff,
+	0x3b, 0x3d, 0xbf, 0x81, 0x92, 0xa8, 0x66, 0x65, 0xbf, 0x04, 0x8a, 0x7c, 0x1e, 0xa9, 0x28, 0x21,
+	0x93, 0x3d, 0x28, 0x8b, 0x68, 0x26, 0xf9, 0xbd, 0xd4, 0x96, 0x74, 0x5b, 0x7d, 0xa8, 0x48, 0x72,
+	0xc5, 0x05, 0x71, 0x3e, 0x11, 0xa9, 0x0d, 0x3b, 0x16, 0xc9, 0x36, 0x94, 0xdd, 0xd0, 0x77, 0x3c,
+	0x75, 0x40, 0x52, 0xb3, 0x3e, 0x68, 0x50, 0xb6, 0x91, 0x4d, 0x27, 0x3c, 0x86, 0x38, 0xe3, 0x38,
+	0x5e, 0x96, 0x24, 0xb5, 0x98, 0xcd, 0x2c, 0x39, 0x24, 0x53, 0xbf, 0xc8, 0x66, 0x79, 0x61, 0xb6,
+	0x42, 0x90, 0x5d, 0xa8, 0x71, 0xcf, 0x47, 0xc6, 0x1d, 0x3f, 0x92, 0x14, 0xcb, 0x0c, 0xf1, 0x58,
+	0x78, 0x78, 0x8e, 0x81, 0x59, 0x4c, 0xc6, 0x22, 0x14, 0x6b, 0x13, 0xd6, 0x47, 0x7e, 0xc4, 0xe7,
+	0xb6, 0x5c, 0x8f, 0xf5, 0x55, 0x03, 0x78, 0x84, 0xdc, 0x96, 0xab, 0x35, 0xb3, 0x02, 0x92, 0xca,
+	0xd2, 0xd7, 0x72, 0x87, 0xa6, 0x5f, 0x76, 0x68, 0x64, 0x08, 0x95, 0x77, 0xde, 0x84, 0x23, 0x55,
+	0x93, 0xbc, 0x96, 0x81, 0xb3, 0xd7, 0x3a, 0x0f, 0x13, 0x4c, 0xb2, 0x74, 0x15, 0xd1, 0x1c, 0xc0,
+	0x5a, 0xde, 0xf1, 0x4b, 0x2b, 0x3f, 0x82, 0xba, 0xc8, 0x2f, 0xc9, 0x77, 0x0b, 0xaa, 0xb2, 0x7e,
+	0x66, 0x6a, 0x2d, 0x63, 0xb1, 0x6a, 0x35, 0xd0, 0x14, 0x62, 0x0d, 0xa0, 0xfe, 0xd8, 0x63, 0xe9,
+	0x30, 0x72, 0x2d, 0x6b, 0x97, 0xb5, 0x6c, 0xdd, 0x85, 0xb5, 0x24, 0xf6, 0x6a, 0x4f, 0xfb, 0xb0,
+	0xf6, 0xda, 0xe1, 0xe3, 0xb3, 0x3f, 0xbc, 0x88, 0x6d, 0x28, 0x53, 0x64, 0x53, 0x3f, 0x39, 0xd4,
+	0x9a, 0x2d, 0x35, 0xeb, 0xa3, 0x06, 0xa5, 0xd1, 0x0c, 0x03, 0xbe, 0xf4, 0x6b, 0xb6, 0x97, 0xbb,
+	0x8b, 0x8d, 0xde, 0xbf, 0xb9, 0x5b, 0x8d, 0xe1, 0x2f, 0xe6, 0x11, 0xca, 0x63, 0xf9, 0x39, 0xfd,
+	0x72, 0x4c, 0x2e, 0x5e, 0xc6, 0xe4, 0x9b, 0x5d, 0xa8, 0xa5, 0xd9, 0x09, 0x40, 0xf9, 0x01, 0x45,
+	0x87, 0x63, 0xa3, 0x10, 0xcb, 0xc7, 0x38, 0x41, 0x8e, 0x0d, 0x2d, 0x96, 0x5f, 0x46, 0x6e, 0x6c,
+	0xd7, 0x7b, 0x9f, 0x74, 0xa8, 0xda, 0x32, 0x1d, 0x19, 0x0a, 0x06, 0xab, 0xff, 0x69, 0x5b, 0xab,
+	0x98, 0xd6, 0xfc, 0xef, 0x82, 0x55, 0xb2, 0xbf, 0x40, 0x0e, 0x55, 0x22, 0xa4, 0x64, 0xb9, 0xc4,
+	0xe6, 0x4e, 0xae, 0xff, 0x85, 0xbb, 0x29, 0x90, 0x01, 0xc0, 0x31, 0xd2, 0xab, 0xc5, 0xde, 0x4b,
+	0xc8, 0x22, 0x91, 0x8c, 0xe4, 0xca, 0xcb, 0x11, 0xb0, 0xb9, 0x7d, 0xd1, 0x9c, 0x26, 0xb8, 0x03,
+	0x25, 0x41, 0x17, 0x92, 0x83, 0xe4, 0xf9, 0xd3, 0x6c, 0x64, 0xf6, 0xe4, 0x37, 0xc7, 0x2a, 0x1c,
+	0x68, 0xf7, 0xfb, 0x6f, 0x6f, 0x9f, 0x7a, 0xfc, 0x6c, 0x7a, 0xd2, 0x19, 0x87, 0x7e, 0xd7, 0xf7,
+	0xc6, 0x34, 0x94, 0x7f, 0x67, 0xfd, 0xae, 0xf8, 0x52, 0x48, 0x3f, 0x1c, 0x86, 0x4a, 0x38, 0x29,
+	0x0b, 0x47, 0xff, 0xfb, 0x00, 0x58, 0x37, 0x44, 0xab, 0x5d, 0x08, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
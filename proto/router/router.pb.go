@@ -422,6 +422,7 @@ type LookupOptions struct {
 	Network              string   `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
 	Router               string   `protobuf:"bytes,4,opt,name=router,proto3" json:"router,omitempty"`
 	Link                 string   `protobuf:"bytes,5,opt,name=link,proto3" json:"link,omitempty"`
+	Version              string   `protobuf:"bytes,6,opt,name=version,proto3" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -487,6 +488,13 @@ func (m *LookupOptions) GetLink() string {
 	return ""
 }
 
+func (m *LookupOptions) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 // Route is a service route
 type Route struct {
 	// service for the route
@@ -504,10 +512,12 @@ type Route struct {
 	// the metric / score of this route
 	Metric int64 `protobuf:"varint,7,opt,name=metric,proto3" json:"metric,omitempty"`
 	// metadata for the route
-	Metadata             map[string]string `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Metadata map[string]string `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// version of the service
+	Version              string   `protobuf:"bytes,9,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Route) Reset()         { *m = Route{} }
@@ -591,6 +601,13 @@ func (m *Route) GetMetadata() map[string]string {
 	return nil
 }
 
+func (m *Route) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterEnum("router.EventType", EventType_name, EventType_value)
 	proto.RegisterType((*ReadRequest)(nil), "router.ReadRequest")
@@ -610,44 +627,45 @@ func init() {
 func init() { proto.RegisterFile("router/router.proto", fileDescriptor_7214bc1619ffe283) }
 
 var fileDescriptor_7214bc1619ffe283 = []byte{
-	// 589 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcb, 0x6e, 0xd3, 0x4c,
-	0x14, 0x8e, 0x9d, 0xd8, 0xad, 0x4f, 0x9b, 0x28, 0xff, 0xb4, 0x7f, 0x65, 0x05, 0x16, 0x91, 0x51,
-	0x45, 0x84, 0x44, 0x5c, 0x12, 0xa1, 0x02, 0xdd, 0x01, 0xdd, 0x81, 0x90, 0x46, 0x45, 0x48, 0xdd,
-	0x4d, 0xe2, 0xa3, 0xd6, 0x4a, 0x7c, 0x61, 0x3c, 0x4e, 0x95, 0x25, 0x4b, 0x1e, 0x85, 0x07, 0xe2,
-	0x7d, 0x90, 0xe7, 0x92, 0xc4, 0x16, 0x01, 0xb1, 0xc9, 0xcc, 0xb9, 0xe6, 0x3b, 0x9f, 0xbf, 0x33,
-	0x70, 0xc2, 0xb3, 0x52, 0x20, 0x0f, 0xd5, 0x31, 0xce, 0x79, 0x26, 0x32, 0xe2, 0x2a, 0x2b, 0x78,
-	0x0a, 0x47, 0x14, 0x59, 0x44, 0xf1, 0x6b, 0x89, 0x85, 0x20, 0x3e, 0x1c, 0x14, 0xc8, 0x57, 0xf1,
-	0x1c, 0x7d, 0x6b, 0x68, 0x8d, 0x3c, 0x6a, 0xcc, 0xe0, 0x25, 0x1c, 0xab, 0xc4, 0x22, 0xcf, 0xd2,
-	0x02, 0xc9, 0x39, 0xa8, 0x16, 0x85, 0x6f, 0x0d, 0xdb, 0xa3, 0xa3, 0x49, 0x77, 0xac, 0xfb, 0xd3,
-	0xea, 0xa0, 0x3a, 0x18, 0xdc, 0x42, 0xf7, 0x43, 0x96, 0x2d, 0xca, 0xfc, 0xaf, 0xff, 0x40, 0x42,
-	0x38, 0xc8, 0x72, 0x11, 0x67, 0x69, 0xe1, 0xdb, 0x43, 0x6b, 0x74, 0x34, 0xf9, 0xdf, 0xb4, 0x54,
-	0x1d, 0x3e, 0xa9, 0x20, 0x35, 0x59, 0xc1, 0x25, 0xf4, 0x4c, 0xef, 0x7f, 0x03, 0xd5, 0x83, 0xe3,
-	0x2f, 0x4c, 0xcc, 0xef, 0x35, 0xa6, 0xa0, 0x0f, 0xbd, 0x77, 0x1c, 0x99, 0x40, 0xd3, 0xa8, 0xf2,
-	0xbc, 0xc7, 0x25, 0xd6, 0x3d, 0x9f, 0xf3, 0x68, 0x37, 0xe7, 0x9b, 0x05, 0xce, 0xf5, 0x0a, 0x53,
-	0x41, 0x7a, 0x60, 0xc7, 0x91, 0x1e, 0xc7, 0x8e, 0x23, 0x72, 0x0e, 0x1d, 0xb1, 0xce, 0x51, 0x8e,
-	0xd1, 0x9b, 0xfc, 0x67, 0x40, 0xc8, 0xe4, 0x9b, 0x75, 0x8e, 0x54, 0x86, 0xc9, 0x63, 0xf0, 0x44,
-	0x9c, 0x60, 0x21, 0x58, 0x92, 0xfb, 0xed, 0xa1, 0x35, 0x6a, 0xd3, 0xad, 0x83, 0x3c, 0x01, 0x47,
-	0xd6, 0xf9, 0x1d, 0x49, 0x46, 0x63, 0x14, 0x15, 0x0b, 0xbe, 0x5b, 0x86, 0x5f, 0xcd, 0x4e, 0xc5,
-	0x2f, 0x8b, 0x22, 0x8e, 0x45, 0x61, 0xf8, 0xd5, 0x66, 0x15, 0xb9, 0x63, 0x02, 0x1f, 0xd8, 0x5a,
-	0x02, 0xf3, 0xa8, 0x31, 0xab, 0x48, 0x8a, 0xe2, 0x21, 0xe3, 0x0b, 0x09, 0xc3, 0xa3, 0xc6, 0x24,
-	0x67, 0x9a, 0x50, 0x2e, 0x51, 0x78, 0x9a, 0x41, 0x4e, 0x08, 0x74, 0x96, 0x71, 0xba, 0xf0, 0x1d,
-	0xe9, 0x95, 0xf7, 0xe0, 0x87, 0x0d, 0x8e, 0x04, 0xf7, 0x87, 0x6f, 0xbc, 0x83, 0xce, 0xde, 0x8b,
-	0xae, 0xbd, 0x17, 0x5d, 0x67, 0x1f, 0x3a, 0xe7, 0xb7, 0xe8, 0xdc, 0x2d, 0xba, 0x2a, 0x37, 0x41,
-	0xc1, 0xe3, 0xb9, 0x7f, 0x20, 0x99, 0xd6, 0x16, 0xb9, 0x84, 0xc3, 0x04, 0x05, 0x8b, 0x98, 0x60,
-	0xfe, 0xa1, 0x14, 0xcd, 0xa3, 0x1a, 0xd3, 0xe3, 0x8f, 0x3a, 0x7a, 0x9d, 0x0a, 0xbe, 0xa6, 0x9b,
-	0xe4, 0xc1, 0x15, 0x74, 0x6b, 0x21, 0xd2, 0x87, 0xf6, 0x02, 0xd7, 0x7a, 0xe2, 0xea, 0x4a, 0x4e,
-	0xc1, 0x59, 0xb1, 0x65, 0x89, 0x7a, 0x56, 0x65, 0xbc, 0xb1, 0x5f, 0x59, 0xcf, 0x42, 0xf0, 0x36,
-	0x6a, 0x20, 0x00, 0xae, 0x92, 0x5f, 0xbf, 0x55, 0xdd, 0x95, 0xf0, 0xfa, 0x56, 0x75, 0x57, 0x92,
-	0xeb, 0xdb, 0x93, 0x12, 0x5c, 0xaa, 0x86, 0x7b, 0x0d, 0xae, 0xfa, 0xe2, 0xa4, 0xb1, 0x1f, 0x5a,
-	0xcd, 0x83, 0xb3, 0xa6, 0x5b, 0xeb, 0xb5, 0x45, 0x2e, 0xc0, 0x91, 0xba, 0x27, 0xa7, 0x26, 0x65,
-	0x77, 0x0d, 0x06, 0xdd, 0x9a, 0x50, 0x83, 0xd6, 0x85, 0x35, 0xf9, 0x69, 0x81, 0x73, 0xc3, 0x66,
-	0x4b, 0x24, 0x2f, 0x0c, 0x48, 0x52, 0x57, 0xe2, 0xf6, 0xef, 0x1a, 0x2b, 0xd4, 0xaa, 0x4a, 0xd4,
-	0x2c, 0x7b, 0x4b, 0x1a, 0x3b, 0x26, 0x4b, 0xd4, 0xc8, 0x7b, 0x4b, 0x1a, 0x4b, 0xd8, 0x22, 0x53,
-	0xe8, 0x54, 0x0f, 0x13, 0x39, 0xd9, 0x14, 0x6c, 0xdf, 0xb3, 0xc1, 0x69, 0xdd, 0x69, 0x8a, 0xde,
-	0x86, 0xb7, 0xcf, 0xef, 0x62, 0x71, 0x5f, 0xce, 0xc6, 0xf3, 0x2c, 0x09, 0x93, 0x78, 0xce, 0x33,
-	0xfd, 0xbb, 0x9a, 0x86, 0xf2, 0x85, 0xd4, 0xcf, 0xe5, 0x95, 0x3a, 0x66, 0xae, 0x74, 0x4e, 0x7f,
-	0x05, 0x00, 0x00, 0xff, 0xff, 0x54, 0xb5, 0x59, 0x40, 0x4d, 0x05, 0x00, 0x00,
+	// 605 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x4e,
+	0x10, 0xcf, 0x3a, 0xb1, 0x5b, 0x4f, 0x9b, 0x28, 0xff, 0x6d, 0xff, 0x95, 0x15, 0x38, 0x44, 0x46,
+	0x15, 0x11, 0x12, 0x49, 0x9b, 0x0a, 0x15, 0xe8, 0x0d, 0xe8, 0x0d, 0x84, 0xb4, 0x2a, 0x42, 0xea,
+	0x6d, 0x1b, 0x8f, 0x5a, 0x2b, 0x89, 0xd7, 0xac, 0xd7, 0xa9, 0x72, 0xe4, 0x59, 0x78, 0x14, 0x9e,
+	0x81, 0xf7, 0x41, 0xde, 0x8f, 0x26, 0x0e, 0x04, 0xc4, 0xc5, 0xbb, 0xf3, 0xe9, 0xdf, 0xcc, 0xfe,
+	0x66, 0xe0, 0x40, 0x8a, 0x52, 0xa1, 0x1c, 0x99, 0x63, 0x98, 0x4b, 0xa1, 0x04, 0x0d, 0x8c, 0x14,
+	0x3f, 0x85, 0x3d, 0x86, 0x3c, 0x61, 0xf8, 0xa5, 0xc4, 0x42, 0xd1, 0x08, 0x76, 0x0a, 0x94, 0x8b,
+	0x74, 0x82, 0x11, 0xe9, 0x93, 0x41, 0xc8, 0x9c, 0x18, 0xbf, 0x80, 0x7d, 0xe3, 0x58, 0xe4, 0x22,
+	0x2b, 0x90, 0x1e, 0x83, 0x49, 0x51, 0x44, 0xa4, 0xdf, 0x1c, 0xec, 0x8d, 0xdb, 0x43, 0x9b, 0x9f,
+	0x55, 0x07, 0xb3, 0xc6, 0xf8, 0x1a, 0xda, 0xef, 0x85, 0x98, 0x96, 0xf9, 0x5f, 0xff, 0x40, 0x47,
+	0xb0, 0x23, 0x72, 0x95, 0x8a, 0xac, 0x88, 0xbc, 0x3e, 0x19, 0xec, 0x8d, 0xff, 0x77, 0x29, 0x4d,
+	0x86, 0x8f, 0xc6, 0xc8, 0x9c, 0x57, 0x7c, 0x0e, 0x1d, 0x97, 0xfb, 0xdf, 0x40, 0x75, 0x60, 0xff,
+	0x33, 0x57, 0x93, 0x3b, 0x8b, 0x29, 0xee, 0x42, 0xe7, 0xad, 0x44, 0xae, 0xd0, 0x25, 0xaa, 0x34,
+	0xef, 0x70, 0x86, 0x75, 0xcd, 0xa7, 0x3c, 0x59, 0xf7, 0xf9, 0x4a, 0xc0, 0xbf, 0x5c, 0x60, 0xa6,
+	0x68, 0x07, 0xbc, 0x34, 0xb1, 0xe5, 0x78, 0x69, 0x42, 0x8f, 0xa1, 0xa5, 0x96, 0x39, 0xea, 0x32,
+	0x3a, 0xe3, 0xff, 0x1c, 0x08, 0xed, 0x7c, 0xb5, 0xcc, 0x91, 0x69, 0x33, 0x7d, 0x0c, 0xa1, 0x4a,
+	0xe7, 0x58, 0x28, 0x3e, 0xcf, 0xa3, 0x66, 0x9f, 0x0c, 0x9a, 0x6c, 0xa5, 0xa0, 0x4f, 0xc0, 0xd7,
+	0x71, 0x51, 0xab, 0x4f, 0x7e, 0x2d, 0xc5, 0xd8, 0xe2, 0x6f, 0x04, 0xda, 0xb5, 0xee, 0x54, 0xfd,
+	0xe5, 0x49, 0x22, 0xb1, 0x28, 0x5c, 0x7f, 0xad, 0x58, 0x59, 0x6e, 0xb9, 0xc2, 0x7b, 0xbe, 0xd4,
+	0xc0, 0x42, 0xe6, 0xc4, 0xca, 0x92, 0xa1, 0xba, 0x17, 0x72, 0xaa, 0x61, 0x84, 0xcc, 0x89, 0xf4,
+	0xc8, 0x36, 0x54, 0x6a, 0x14, 0xa1, 0xed, 0xa0, 0xa4, 0x14, 0x5a, 0xb3, 0x34, 0x9b, 0x46, 0xbe,
+	0xd6, 0xea, 0x7b, 0x95, 0x65, 0x81, 0xb2, 0x48, 0x45, 0x16, 0x05, 0x26, 0x8b, 0x15, 0xe3, 0xef,
+	0x1e, 0xf8, 0x1a, 0xf6, 0x1f, 0x5e, 0x7f, 0x0d, 0xb7, 0xb7, 0x15, 0x77, 0x73, 0x2b, 0xee, 0xd6,
+	0x36, 0xdc, 0xfe, 0x6f, 0x71, 0x07, 0x6b, 0xb8, 0x8f, 0x20, 0x98, 0xa3, 0x92, 0xe9, 0x24, 0xda,
+	0xd1, 0x6f, 0x60, 0x25, 0x7a, 0x0e, 0xbb, 0x73, 0x54, 0x3c, 0xe1, 0x8a, 0x47, 0xbb, 0x9a, 0x4e,
+	0x8f, 0x6a, 0x6f, 0x30, 0xfc, 0x60, 0xad, 0x97, 0x99, 0x92, 0x4b, 0xf6, 0xe0, 0xbc, 0xde, 0x88,
+	0xb0, 0xd6, 0x88, 0xde, 0x05, 0xb4, 0x6b, 0x41, 0xb4, 0x0b, 0xcd, 0x29, 0x2e, 0x6d, 0x2f, 0xaa,
+	0x2b, 0x3d, 0x04, 0x7f, 0xc1, 0x67, 0x25, 0xda, 0x2e, 0x18, 0xe1, 0xb5, 0xf7, 0x92, 0x3c, 0x1b,
+	0x41, 0xf8, 0xc0, 0x20, 0x0a, 0x10, 0x18, 0xca, 0x76, 0x1b, 0xd5, 0xdd, 0x90, 0xb5, 0x4b, 0xaa,
+	0xbb, 0xa1, 0x69, 0xd7, 0x1b, 0x97, 0x10, 0x30, 0x53, 0xf6, 0x2b, 0x08, 0x0c, 0x4b, 0xe8, 0xc6,
+	0x4c, 0xd9, 0x09, 0xe8, 0x1d, 0x6d, 0xaa, 0x2d, 0xc7, 0x1b, 0xf4, 0x04, 0x7c, 0x3d, 0x2b, 0xf4,
+	0xd0, 0xb9, 0xac, 0x8f, 0x4e, 0xaf, 0x5d, 0x23, 0x77, 0xdc, 0x38, 0x21, 0xe3, 0x1f, 0x04, 0xfc,
+	0x2b, 0x7e, 0x33, 0x43, 0x7a, 0xea, 0x40, 0xd2, 0x3a, 0x7b, 0x57, 0xbf, 0xdb, 0x18, 0xbb, 0x06,
+	0x3d, 0x75, 0xb5, 0x6c, 0x0d, 0xd9, 0x98, 0x4b, 0x1d, 0x62, 0x4a, 0xde, 0x1a, 0xb2, 0x31, 0xb8,
+	0x0d, 0x7a, 0x06, 0xad, 0x6a, 0x99, 0xd1, 0x83, 0x87, 0x80, 0xd5, 0x0e, 0xec, 0x1d, 0xd6, 0x95,
+	0x2e, 0xe8, 0xcd, 0xe8, 0xfa, 0xf9, 0x6d, 0xaa, 0xee, 0xca, 0x9b, 0xe1, 0x44, 0xcc, 0x47, 0xf3,
+	0x74, 0x22, 0x85, 0xfd, 0x2e, 0xce, 0x46, 0x7a, 0xab, 0xda, 0x15, 0x7b, 0x61, 0x8e, 0x9b, 0x40,
+	0x2b, 0xcf, 0x7e, 0x0e, 0x00, 0x90, 0x56, 0xfc, 0x01, 0x81, 0x05, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
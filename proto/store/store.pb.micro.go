@@ -48,6 +48,12 @@ type StoreService interface {
 	List(ctx context.Context, in *ListRequest, opts ...client.CallOption) (Store_ListService, error)
 	Databases(ctx context.Context, in *DatabasesRequest, opts ...client.CallOption) (*DatabasesResponse, error)
 	Tables(ctx context.Context, in *TablesRequest, opts ...client.CallOption) (*TablesResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...client.CallOption) (Store_WatchService, error)
+	Touch(ctx context.Context, in *TouchRequest, opts ...client.CallOption) (*TouchResponse, error)
+	Compact(ctx context.Context, in *CompactRequest, opts ...client.CallOption) (*CompactResponse, error)
+	Usage(ctx context.Context, in *UsageRequest, opts ...client.CallOption) (*UsageResponse, error)
+	ReadStream(ctx context.Context, in *ReadRequest, opts ...client.CallOption) (Store_ReadStreamService, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...client.CallOption) (*StatsResponse, error)
 }
 
 type storeService struct {
@@ -161,6 +167,144 @@ func (c *storeService) Tables(ctx context.Context, in *TablesRequest, opts ...cl
 	return out, nil
 }
 
+func (c *storeService) Watch(ctx context.Context, in *WatchRequest, opts ...client.CallOption) (Store_WatchService, error) {
+	req := c.c.NewRequest(c.name, "Store.Watch", &WatchRequest{})
+	stream, err := c.c.Stream(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(in); err != nil {
+		return nil, err
+	}
+	return &storeServiceWatch{stream}, nil
+}
+
+type Store_WatchService interface {
+	Context() context.Context
+	SendMsg(interface{}) error
+	RecvMsg(interface{}) error
+	Close() error
+	Recv() (*WatchResponse, error)
+}
+
+type storeServiceWatch struct {
+	stream client.Stream
+}
+
+func (x *storeServiceWatch) Close() error {
+	return x.stream.Close()
+}
+
+func (x *storeServiceWatch) Context() context.Context {
+	return x.stream.Context()
+}
+
+func (x *storeServiceWatch) SendMsg(m interface{}) error {
+	return x.stream.Send(m)
+}
+
+func (x *storeServiceWatch) RecvMsg(m interface{}) error {
+	return x.stream.Recv(m)
+}
+
+func (x *storeServiceWatch) Recv() (*WatchResponse, error) {
+	m := new(WatchResponse)
+	err := x.stream.Recv(m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storeService) Touch(ctx context.Context, in *TouchRequest, opts ...client.CallOption) (*TouchResponse, error) {
+	req := c.c.NewRequest(c.name, "Store.Touch", in)
+	out := new(TouchResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storeService) Compact(ctx context.Context, in *CompactRequest, opts ...client.CallOption) (*CompactResponse, error) {
+	req := c.c.NewRequest(c.name, "Store.Compact", in)
+	out := new(CompactResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storeService) Usage(ctx context.Context, in *UsageRequest, opts ...client.CallOption) (*UsageResponse, error) {
+	req := c.c.NewRequest(c.name, "Store.Usage", in)
+	out := new(UsageResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storeService) ReadStream(ctx context.Context, in *ReadRequest, opts ...client.CallOption) (Store_ReadStreamService, error) {
+	req := c.c.NewRequest(c.name, "Store.ReadStream", &ReadRequest{})
+	stream, err := c.c.Stream(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(in); err != nil {
+		return nil, err
+	}
+	return &storeServiceReadStream{stream}, nil
+}
+
+type Store_ReadStreamService interface {
+	Context() context.Context
+	SendMsg(interface{}) error
+	RecvMsg(interface{}) error
+	Close() error
+	Recv() (*ReadResponse, error)
+}
+
+type storeServiceReadStream struct {
+	stream client.Stream
+}
+
+func (x *storeServiceReadStream) Close() error {
+	return x.stream.Close()
+}
+
+func (x *storeServiceReadStream) Context() context.Context {
+	return x.stream.Context()
+}
+
+func (x *storeServiceReadStream) SendMsg(m interface{}) error {
+	return x.stream.Send(m)
+}
+
+func (x *storeServiceReadStream) RecvMsg(m interface{}) error {
+	return x.stream.Recv(m)
+}
+
+func (x *storeServiceReadStream) Recv() (*ReadResponse, error) {
+	m := new(ReadResponse)
+	err := x.stream.Recv(m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storeService) Stats(ctx context.Context, in *StatsRequest, opts ...client.CallOption) (*StatsResponse, error) {
+	req := c.c.NewRequest(c.name, "Store.Stats", in)
+	out := new(StatsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Store service
 
 type StoreHandler interface {
@@ -170,6 +314,12 @@ type StoreHandler interface {
 	List(context.Context, *ListRequest, Store_ListStream) error
 	Databases(context.Context, *DatabasesRequest, *DatabasesResponse) error
 	Tables(context.Context, *TablesRequest, *TablesResponse) error
+	Watch(context.Context, *WatchRequest, Store_WatchStream) error
+	Touch(context.Context, *TouchRequest, *TouchResponse) error
+	Compact(context.Context, *CompactRequest, *CompactResponse) error
+	Usage(context.Context, *UsageRequest, *UsageResponse) error
+	ReadStream(context.Context, *ReadRequest, Store_ReadStreamStream) error
+	Stats(context.Context, *StatsRequest, *StatsResponse) error
 }
 
 func RegisterStoreHandler(s server.Server, hdlr StoreHandler, opts ...server.HandlerOption) error {
@@ -180,6 +330,12 @@ func RegisterStoreHandler(s server.Server, hdlr StoreHandler, opts ...server.Han
 		List(ctx context.Context, stream server.Stream) error
 		Databases(ctx context.Context, in *DatabasesRequest, out *DatabasesResponse) error
 		Tables(ctx context.Context, in *TablesRequest, out *TablesResponse) error
+		Watch(ctx context.Context, stream server.Stream) error
+		Touch(ctx context.Context, in *TouchRequest, out *TouchResponse) error
+		Compact(ctx context.Context, in *CompactRequest, out *CompactResponse) error
+		Usage(ctx context.Context, in *UsageRequest, out *UsageResponse) error
+		ReadStream(ctx context.Context, stream server.Stream) error
+		Stats(ctx context.Context, in *StatsRequest, out *StatsResponse) error
 	}
 	type Store struct {
 		store
@@ -252,6 +408,102 @@ func (h *storeHandler) Tables(ctx context.Context, in *TablesRequest, out *Table
 	return h.StoreHandler.Tables(ctx, in, out)
 }
 
+func (h *storeHandler) Watch(ctx context.Context, stream server.Stream) error {
+	m := new(WatchRequest)
+	if err := stream.Recv(m); err != nil {
+		return err
+	}
+	return h.StoreHandler.Watch(ctx, m, &storeWatchStream{stream})
+}
+
+type Store_WatchStream interface {
+	Context() context.Context
+	SendMsg(interface{}) error
+	RecvMsg(interface{}) error
+	Close() error
+	Send(*WatchResponse) error
+}
+
+type storeWatchStream struct {
+	stream server.Stream
+}
+
+func (x *storeWatchStream) Close() error {
+	return x.stream.Close()
+}
+
+func (x *storeWatchStream) Context() context.Context {
+	return x.stream.Context()
+}
+
+func (x *storeWatchStream) SendMsg(m interface{}) error {
+	return x.stream.Send(m)
+}
+
+func (x *storeWatchStream) RecvMsg(m interface{}) error {
+	return x.stream.Recv(m)
+}
+
+func (x *storeWatchStream) Send(m *WatchResponse) error {
+	return x.stream.Send(m)
+}
+
+func (h *storeHandler) Touch(ctx context.Context, in *TouchRequest, out *TouchResponse) error {
+	return h.StoreHandler.Touch(ctx, in, out)
+}
+
+func (h *storeHandler) Compact(ctx context.Context, in *CompactRequest, out *CompactResponse) error {
+	return h.StoreHandler.Compact(ctx, in, out)
+}
+
+func (h *storeHandler) Usage(ctx context.Context, in *UsageRequest, out *UsageResponse) error {
+	return h.StoreHandler.Usage(ctx, in, out)
+}
+
+func (h *storeHandler) ReadStream(ctx context.Context, stream server.Stream) error {
+	m := new(ReadRequest)
+	if err := stream.Recv(m); err != nil {
+		return err
+	}
+	return h.StoreHandler.ReadStream(ctx, m, &storeReadStreamStream{stream})
+}
+
+type Store_ReadStreamStream interface {
+	Context() context.Context
+	SendMsg(interface{}) error
+	RecvMsg(interface{}) error
+	Close() error
+	Send(*ReadResponse) error
+}
+
+type storeReadStreamStream struct {
+	stream server.Stream
+}
+
+func (x *storeReadStreamStream) Close() error {
+	return x.stream.Close()
+}
+
+func (x *storeReadStreamStream) Context() context.Context {
+	return x.stream.Context()
+}
+
+func (x *storeReadStreamStream) SendMsg(m interface{}) error {
+	return x.stream.Send(m)
+}
+
+func (x *storeReadStreamStream) RecvMsg(m interface{}) error {
+	return x.stream.Recv(m)
+}
+
+func (x *storeReadStreamStream) Send(m *ReadResponse) error {
+	return x.stream.Send(m)
+}
+
+func (h *storeHandler) Stats(ctx context.Context, in *StatsRequest, out *StatsResponse) error {
+	return h.StoreHandler.Stats(ctx, in, out)
+}
+
 // Api Endpoints for BlobStore service
 
 func NewBlobStoreEndpoints() []*api.Endpoint {
@@ -265,6 +517,8 @@ type BlobStoreService interface {
 	Write(ctx context.Context, opts ...client.CallOption) (BlobStore_WriteService, error)
 	Delete(ctx context.Context, in *BlobDeleteRequest, opts ...client.CallOption) (*BlobDeleteResponse, error)
 	List(ctx context.Context, in *BlobListRequest, opts ...client.CallOption) (*BlobListResponse, error)
+	Presign(ctx context.Context, in *BlobPresignRequest, opts ...client.CallOption) (*BlobPresignResponse, error)
+	ListInfo(ctx context.Context, in *BlobListRequest, opts ...client.CallOption) (*BlobListInfoResponse, error)
 }
 
 type blobStoreService struct {
@@ -394,6 +648,26 @@ func (c *blobStoreService) List(ctx context.Context, in *BlobListRequest, opts .
 	return out, nil
 }
 
+func (c *blobStoreService) Presign(ctx context.Context, in *BlobPresignRequest, opts ...client.CallOption) (*BlobPresignResponse, error) {
+	req := c.c.NewRequest(c.name, "BlobStore.Presign", in)
+	out := new(BlobPresignResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobStoreService) ListInfo(ctx context.Context, in *BlobListRequest, opts ...client.CallOption) (*BlobListInfoResponse, error) {
+	req := c.c.NewRequest(c.name, "BlobStore.ListInfo", in)
+	out := new(BlobListInfoResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for BlobStore service
 
 type BlobStoreHandler interface {
@@ -401,6 +675,8 @@ type BlobStoreHandler interface {
 	Write(context.Context, BlobStore_WriteStream) error
 	Delete(context.Context, *BlobDeleteRequest, *BlobDeleteResponse) error
 	List(context.Context, *BlobListRequest, *BlobListResponse) error
+	Presign(context.Context, *BlobPresignRequest, *BlobPresignResponse) error
+	ListInfo(context.Context, *BlobListRequest, *BlobListInfoResponse) error
 }
 
 func RegisterBlobStoreHandler(s server.Server, hdlr BlobStoreHandler, opts ...server.HandlerOption) error {
@@ -409,6 +685,8 @@ func RegisterBlobStoreHandler(s server.Server, hdlr BlobStoreHandler, opts ...se
 		Write(ctx context.Context, stream server.Stream) error
 		Delete(ctx context.Context, in *BlobDeleteRequest, out *BlobDeleteResponse) error
 		List(ctx context.Context, in *BlobListRequest, out *BlobListResponse) error
+		Presign(ctx context.Context, in *BlobPresignRequest, out *BlobPresignResponse) error
+		ListInfo(ctx context.Context, in *BlobListRequest, out *BlobListInfoResponse) error
 	}
 	type BlobStore struct {
 		blobStore
@@ -511,3 +789,11 @@ func (h *blobStoreHandler) Delete(ctx context.Context, in *BlobDeleteRequest, ou
 func (h *blobStoreHandler) List(ctx context.Context, in *BlobListRequest, out *BlobListResponse) error {
 	return h.BlobStoreHandler.List(ctx, in, out)
 }
+
+func (h *blobStoreHandler) Presign(ctx context.Context, in *BlobPresignRequest, out *BlobPresignResponse) error {
+	return h.BlobStoreHandler.Presign(ctx, in, out)
+}
+
+func (h *blobStoreHandler) ListInfo(ctx context.Context, in *BlobListRequest, out *BlobListInfoResponse) error {
+	return h.BlobStoreHandler.ListInfo(ctx, in, out)
+}
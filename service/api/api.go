@@ -28,11 +28,13 @@ import (
 
 	"github.com/micro/micro/v3/service/api/resolver"
 	"github.com/micro/micro/v3/service/context/metadata"
+	merrors "github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/service/server"
 	"github.com/micro/micro/v3/util/acme"
 	"github.com/micro/micro/v3/util/codec"
 	"github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/micro/micro/v3/util/codec/jsonrpc"
 	"github.com/micro/micro/v3/util/codec/protorpc"
 	"github.com/micro/micro/v3/util/qson"
@@ -43,6 +45,9 @@ var (
 	bufferPool = bpool.NewSizedBufferPool(1024, 8)
 )
 
+// VersionHeader is the header of the version of the service a request is routed to
+const VersionHeader = "Micro-Version"
+
 type buffer struct {
 	io.ReadCloser
 }
@@ -244,6 +249,23 @@ func Validate(e *Endpoint) error {
 	return nil
 }
 
+// Versions returns the services of the version in the version header of the request, or all the
+// services if no version was requested
+func Versions(r *http.Request, services []*registry.Service) []*registry.Service {
+	version := r.Header.Get(VersionHeader)
+	if len(version) == 0 {
+		return services
+	}
+
+	var srvs []*registry.Service
+	for _, s := range services {
+		if s.Version == version {
+			srvs = append(srvs, s)
+		}
+	}
+	return srvs
+}
+
 func WithEndpoint(e *Endpoint) server.HandlerOption {
 	return server.EndpointMetadata(e.Name, Encode(e))
 }
@@ -270,6 +292,21 @@ func RequestPayload(r *http.Request) ([]byte, error) {
 	// well actually because there's no proxy codec right now
 
 	ct := r.Header.Get("Content-Type")
+
+	// cbor is converted to json so it's merged with the fields of the url like json
+	if strings.Contains(ct, "application/cbor") && r.Body != nil {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 0 {
+			if b, err = cbor.ToJSON(b); err != nil {
+				return nil, merrors.BadRequest("go.micro.api", "invalid cbor body: %v", err)
+			}
+		}
+		r.Body = ioutil.NopCloser(strings.NewReader(string(b)))
+	}
+
 	switch {
 	case strings.Contains(ct, "application/json-rpc"):
 		msg := codec.Message{
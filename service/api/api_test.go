@@ -24,6 +24,8 @@ import (
 	"github.com/golang/protobuf/proto"
 	go_api "github.com/micro/micro/v3/proto/api"
 	"github.com/micro/micro/v3/service/api"
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/util/codec/cbor"
 )
 
 func TestEncoding(t *testing.T) {
@@ -236,6 +238,26 @@ func TestRequestPayloadFromRequest(t *testing.T) {
 		}
 	})
 
+	t.Run("extracting cbor from a POST request with url params", func(t *testing.T) {
+		cborBytes, err := cbor.FromJSON(jsonBytes)
+		if err != nil {
+			t.Fatalf("Failed to convert JSON to CBOR: %v", err)
+		}
+		r, err := http.NewRequest("POST", "http://localhost/my/path?key1=val1&key2=val2", bytes.NewReader(cborBytes))
+		if err != nil {
+			t.Fatalf("Failed to created http.Request: %v", err)
+		}
+		r.Header.Set("Content-Type", "application/cbor")
+
+		extByte, err := api.RequestPayload(r)
+		if err != nil {
+			t.Fatalf("Failed to extract payload from request: %v", err)
+		}
+		if string(extByte) != string(jsonUrlBytes) {
+			t.Fatalf("Expected %v and %v to match", string(extByte), string(jsonUrlBytes))
+		}
+	})
+
 	t.Run("extracting params from a GET request", func(t *testing.T) {
 
 		r, err := http.NewRequest("GET", "http://localhost/my/path", nil)
@@ -272,3 +294,25 @@ func TestRequestPayloadFromRequest(t *testing.T) {
 		}
 	})
 }
+
+func TestVersions(t *testing.T) {
+	services := []*registry.Service{
+		{Name: "foo", Version: "v1"},
+		{Name: "foo", Version: "v2"},
+	}
+
+	r, _ := http.NewRequest("GET", "/foo", nil)
+	if srvs := api.Versions(r, services); len(srvs) != 2 {
+		t.Fatalf("Expected all versions without the header, got %v", len(srvs))
+	}
+
+	r.Header.Set(api.VersionHeader, "v2")
+	if srvs := api.Versions(r, services); len(srvs) != 1 || srvs[0].Version != "v2" {
+		t.Fatalf("Expected the version of the header, got %+v", srvs)
+	}
+
+	r.Header.Set(api.VersionHeader, "v3")
+	if srvs := api.Versions(r, services); len(srvs) != 0 {
+		t.Fatalf("Expected no services of an unknown version, got %+v", srvs)
+	}
+}
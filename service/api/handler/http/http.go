@@ -81,9 +81,9 @@ func (h *httpHandler) getService(r *http.Request) (string, error) {
 		return "", errors.New("no route found")
 	}
 
-	// get the nodes for this service
+	// get the nodes for this service, of the version requested
 	var nodes []*registry.Node
-	for _, srv := range service.Services {
+	for _, srv := range api.Versions(r, service.Services) {
 		nodes = append(nodes, srv.Nodes...)
 	}
 
@@ -31,6 +31,7 @@ import (
 	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/micro/micro/v3/util/ctx"
 	"github.com/micro/micro/v3/util/router"
 )
@@ -56,6 +57,11 @@ var (
 		"application/proto-rpc",
 		"application/octet-stream",
 	}
+
+	// supported cbor codecs, converted to and from json for the services
+	cborCodecs = []string{
+		"application/cbor",
+	}
 )
 
 type rpcHandler struct {
@@ -130,7 +136,7 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// create custom router
-	callOpt := client.WithRouter(router.New(service.Services))
+	callOpt := client.WithRouter(router.New(api.Versions(r, service.Services)))
 
 	// walk the standard call path
 	// get payload
@@ -167,6 +173,9 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		rsp = response.Data
 	default:
+		// cbor is called as json, the payload having been converted already
+		isCBOR := hasCodec(ct, cborCodecs)
+
 		// if json codec is not present set to json
 		if !hasCodec(ct, jsonCodecs) {
 			ct = "application/json"
@@ -201,6 +210,13 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			writeError(w, r, err)
 			return
 		}
+
+		if isCBOR {
+			if rsp, err = cbor.FromJSON(rsp); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
 	}
 
 	// write the response
@@ -34,6 +34,7 @@ import (
 	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/logger"
 	raw "github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/micro/micro/v3/util/router"
 )
 
@@ -79,6 +80,15 @@ func serveStream(ctx context.Context, w http.ResponseWriter, r *http.Request, se
 		}
 		return
 	}
+
+	// cbor is streamed as json, the payload having been converted already, and the responses are
+	// converted back to a sequence of cbor data items
+	rspCt := ct
+	isCBOR := hasCodec(ct, cborCodecs)
+	if isCBOR {
+		ct = "application/json"
+	}
+
 	if len(payload) == 0 {
 		// make it valid json
 		payload = []byte("{}")
@@ -99,6 +109,9 @@ func serveStream(ctx context.Context, w http.ResponseWriter, r *http.Request, se
 	if ct == "" {
 		ct = "application/json"
 	}
+	if rspCt == "" {
+		rspCt = ct
+	}
 	req := c.NewRequest(
 		service.Name,
 		service.Endpoint.Name,
@@ -107,10 +120,10 @@ func serveStream(ctx context.Context, w http.ResponseWriter, r *http.Request, se
 		client.StreamingRequest(),
 	)
 
-	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Content-Type", rspCt)
 
 	// create custom router
-	callOpt := client.WithRouter(router.New(service.Services))
+	callOpt := client.WithRouter(router.New(api.Versions(r, service.Services)))
 
 	// create a new stream
 	stream, err := c.Stream(ctx, req, callOpt)
@@ -186,6 +199,15 @@ func serveStream(ctx context.Context, w http.ResponseWriter, r *http.Request, se
 				}
 				w.WriteHeader(int(apiRsp.StatusCode))
 				bufOut = apiRsp.Body
+			} else if isCBOR {
+				b, err := cbor.FromJSON(buf)
+				if err != nil {
+					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+						logger.Error(err)
+					}
+					return
+				}
+				bufOut = string(b)
 			} else {
 				bufOut = string(buf)
 			}
@@ -210,6 +232,8 @@ func serveStream(ctx context.Context, w http.ResponseWriter, r *http.Request, se
 type stream struct {
 	// message type requested (binary or text)
 	messageType int
+	// cbor converts the messages to and from json
+	cbor bool
 	// request context
 	ctx context.Context
 	// the websocket connection.
@@ -261,9 +285,18 @@ func (s *stream) clientToServerLoop(cancel context.CancelFunc, wg *sync.WaitGrou
 			return
 		}
 
+		if s.cbor {
+			if msg, err = cbor.ToJSON(msg); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Error(err)
+				}
+				return
+			}
+		}
+
 		var request interface{}
-		switch s.messageType {
-		case websocket.TextMessage:
+		switch {
+		case s.cbor, s.messageType == websocket.TextMessage:
 			m := json.RawMessage(msg)
 			request = &m
 		default:
@@ -293,6 +326,9 @@ func (s *stream) rspToBufLoop(cancel context.CancelFunc, wg *sync.WaitGroup, sto
 		default:
 		}
 		bytes, err := rsp.Read()
+		if err == nil && s.cbor {
+			bytes, err = cbor.FromJSON(bytes)
+		}
 		if err != nil {
 			if err == io.EOF {
 				// clean exit
@@ -300,6 +336,9 @@ func (s *stream) rspToBufLoop(cancel context.CancelFunc, wg *sync.WaitGroup, sto
 			}
 			// write error then close the connection
 			b, _ := json.Marshal(err)
+			if s.cbor {
+				b, _ = cbor.FromJSON(b)
+			}
 			s.conn.WriteMessage(s.messageType, b)
 			s.conn.WriteMessage(websocket.CloseAbnormalClosure, []byte{})
 			return
@@ -384,9 +423,15 @@ func serveWebsocket(ctx context.Context, w http.ResponseWriter, r *http.Request,
 		ct = "application/json"
 	}
 
+	// cbor is streamed as json, converting the messages
+	isCBOR := hasCodec(ct, cborCodecs)
+	if isCBOR {
+		ct = "application/json"
+	}
+
 	// create stream
 	req := c.NewRequest(service.Name, service.Endpoint.Name, nil, client.WithContentType(ct), client.StreamingRequest())
-	str, err := c.Stream(ctx, req, client.WithRouter(router.New(service.Services)))
+	str, err := c.Stream(ctx, req, client.WithRouter(router.New(api.Versions(r, service.Services))))
 	if err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 			logger.Error(err)
@@ -396,11 +441,11 @@ func serveWebsocket(ctx context.Context, w http.ResponseWriter, r *http.Request,
 
 	// determine the message type
 	msgType := websocket.BinaryMessage
-	if ct == "application/json" {
+	if ct == "application/json" && !isCBOR {
 		msgType = websocket.TextMessage
 	}
 
-	s := stream{ctx: ctx, conn: conn, stream: str, messageType: msgType}
+	s := stream{ctx: ctx, conn: conn, stream: str, messageType: msgType, cbor: isCBOR}
 	s.processWSReadsAndWrites()
 }
 
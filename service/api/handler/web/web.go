@@ -87,9 +87,9 @@ func (wh *webHandler) getService(r *http.Request) (string, error) {
 		return "", errors.New("no route found")
 	}
 
-	// get the nodes
+	// get the nodes of the version requested
 	var nodes []*registry.Node
-	for _, srv := range service.Services {
+	for _, srv := range api.Versions(r, service.Services) {
 		nodes = append(nodes, srv.Nodes...)
 	}
 	if len(nodes) == 0 {
@@ -27,6 +27,7 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/micro/micro/v3/service/api"
 	"github.com/micro/micro/v3/service/logger"
+	mnet "github.com/micro/micro/v3/util/net"
 )
 
 type httpServer struct {
@@ -91,22 +92,27 @@ func (s *httpServer) Start() error {
 	if s.opts.EnableACME && s.opts.ACMEProvider != nil {
 		// should we check the address to make sure its using :443?
 		l, err = s.opts.ACMEProvider.Listen(s.opts.ACMEHosts...)
-	} else if s.opts.EnableTLS && s.opts.TLSConfig != nil {
-		l, err = tls.Listen("tcp", s.address, s.opts.TLSConfig)
 	} else {
-		// otherwise plain listen
-		l, err = net.Listen("tcp", s.address)
+		l, err = mnet.Listen(s.address, func(addr string) (net.Listener, error) {
+			// listen on a unix socket or tcp
+			network, addr := mnet.Network(addr)
+			if s.opts.EnableTLS && s.opts.TLSConfig != nil {
+				return tls.Listen(network, addr, s.opts.TLSConfig)
+			}
+			// otherwise plain listen
+			return net.Listen(network, addr)
+		})
 	}
 	if err != nil {
 		return err
 	}
 
 	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("HTTP API Listening on %s", l.Addr().String())
+		logger.Infof("HTTP API Listening on %s", mnet.Addr(l))
 	}
 
 	s.mtx.Lock()
-	s.address = l.Addr().String()
+	s.address = mnet.Addr(l)
 	s.mtx.Unlock()
 
 	go func() {
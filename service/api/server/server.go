@@ -59,7 +59,7 @@ var (
 	Flags = []cli.Flag{
 		&cli.StringFlag{
 			Name:    "address",
-			Usage:   "Set the api address e.g 0.0.0.0:8080",
+			Usage:   "Set the api address e.g 0.0.0.0:8080 or unix:///var/run/micro.sock",
 			EnvVars: []string{"MICRO_API_ADDRESS"},
 		},
 		&cli.StringFlag{
@@ -0,0 +1,75 @@
+package broker
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultAckWait is how long a message is waited on to be acknowledged in ManualAck mode
+	// before it's redelivered, if the AckWait isn't set
+	DefaultAckWait = 30 * time.Second
+	// DefaultRedeliveryDelay is the delay before redelivering a nacked message, so a handler
+	// which keeps failing isn't redelivered the message in a tight loop
+	DefaultRedeliveryDelay = 100 * time.Millisecond
+)
+
+// Redeliver wraps the handler of a subscriber in ManualAck mode, for brokers which can't requeue
+// messages themselves, simulating at-least-once delivery in memory. A message is passed to the
+// handler again if it's nacked, the handler returns an error without acknowledging it, or it
+// isn't acknowledged within the AckWait. Messages aren't redelivered once done is closed, so
+// they're lost if the process exits. The handler is returned as it is if ManualAck isn't set.
+func Redeliver(h Handler, opts SubscribeOptions, done <-chan struct{}) Handler {
+	if !opts.ManualAck {
+		return h
+	}
+	wait := opts.AckWait
+	if wait <= 0 {
+		wait = DefaultAckWait
+	}
+
+	var deliver Handler
+	deliver = func(m *Message) error {
+		var once sync.Once
+		settle := func(requeue bool, delay time.Duration) {
+			once.Do(func() {
+				if !requeue {
+					return
+				}
+				time.AfterFunc(delay, func() {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					if err := deliver(m); err != nil && opts.ErrorHandler != nil {
+						opts.ErrorHandler(m, err)
+					}
+				})
+			})
+		}
+
+		// each delivery gets its own copy of the message, so acknowledging a previous delivery
+		// doesn't settle this one
+		msg := &Message{Header: m.Header, Body: m.Body}
+		timer := time.AfterFunc(wait, func() { settle(true, 0) })
+		msg.SetAckFunc(func() error {
+			timer.Stop()
+			settle(false, 0)
+			return nil
+		})
+		msg.SetNackFunc(func() error {
+			timer.Stop()
+			settle(true, DefaultRedeliveryDelay)
+			return nil
+		})
+
+		err := h(msg)
+		if err != nil {
+			timer.Stop()
+			settle(true, DefaultRedeliveryDelay)
+		}
+		return err
+	}
+	return deliver
+}
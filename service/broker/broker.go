@@ -39,9 +39,42 @@ type Handler func(*Message) error
 
 type ErrorHandler func(*Message, error)
 
+type AckFunc func() error
+type NackFunc func() error
+
 type Message struct {
 	Header map[string]string
 	Body   []byte
+
+	ackFunc  AckFunc
+	nackFunc NackFunc
+}
+
+// Ack acknowledges successful processing of the message in ManualAck mode. Messages delivered
+// to subscribers which aren't in ManualAck mode are acknowledged by the broker, so it's a no-op.
+func (m *Message) Ack() error {
+	if m.ackFunc == nil {
+		return nil
+	}
+	return m.ackFunc()
+}
+
+func (m *Message) SetAckFunc(f AckFunc) {
+	m.ackFunc = f
+}
+
+// Nack negatively acknowledges processing of the message in ManualAck mode, so it's requeued
+// and redelivered. Messages delivered to subscribers which aren't in ManualAck mode are
+// acknowledged by the broker, so it's a no-op.
+func (m *Message) Nack() error {
+	if m.nackFunc == nil {
+		return nil
+	}
+	return m.nackFunc()
+}
+
+func (m *Message) SetNackFunc(f NackFunc) {
+	m.nackFunc = f
 }
 
 // Subscriber is a convenience return type for the Subscribe method
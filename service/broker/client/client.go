@@ -46,10 +46,19 @@ func (b *serviceBroker) Options() broker.Options {
 }
 
 func (b *serviceBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	msg, err := broker.PrepareMessage(msg, b.options, options)
+	if err != nil {
+		return err
+	}
+
 	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 		logger.Debugf("Publishing to topic %s broker %v", topic, b.Addrs)
 	}
-	_, err := b.Client.Publish(context.DefaultContext, &pb.PublishRequest{
+	_, err = b.Client.Publish(context.DefaultContext, &pb.PublishRequest{
 		Topic: topic,
 		Message: &pb.Message{
 			Header: msg.Header,
@@ -78,11 +87,19 @@ func (b *serviceBroker) Subscribe(topic string, handler broker.Handler, opts ...
 	sub := &serviceSub{
 		topic:   topic,
 		queue:   options.Queue,
-		handler: handler,
 		stream:  stream,
-		closed:  make(chan bool),
+		closed:  make(chan struct{}),
 		options: options,
 	}
+	// messages can't be acknowledged over the stream so redeliveries are simulated by the client
+	h := broker.Redeliver(handler, options, sub.closed)
+	sub.handler = func(msg *broker.Message) error {
+		msg, err := broker.DecompressMessage(msg)
+		if err != nil {
+			return err
+		}
+		return h(msg)
+	}
 
 	go func() {
 		for {
@@ -11,7 +11,7 @@ type serviceSub struct {
 	queue   string
 	handler broker.Handler
 	stream  pb.Broker_SubscribeService
-	closed  chan bool
+	closed  chan struct{}
 	options broker.SubscribeOptions
 }
 
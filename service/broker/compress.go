@@ -0,0 +1,121 @@
+package broker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/snappy"
+)
+
+// Compression of the body of a published message
+type Compression string
+
+const (
+	// NoCompression publishes the body as it is
+	NoCompression Compression = ""
+	// Gzip compresses the body with gzip, which has the best ratio
+	Gzip Compression = "gzip"
+	// Snappy compresses the body with snappy, which is the fastest
+	Snappy Compression = "snappy"
+)
+
+// ContentEncodingHeader is the header of a compressed message, set to its compression
+const ContentEncodingHeader = "Micro-Content-Encoding"
+
+var (
+	// ErrMessageTooLarge is returned when publishing a message larger than the max message size
+	ErrMessageTooLarge = errors.New("message too large")
+	// ErrUnknownCompression is returned when publishing or receiving a message compressed with
+	// an unknown compression
+	ErrUnknownCompression = errors.New("unknown compression")
+)
+
+// PrepareMessage returns the message to publish, with its body compressed with the compression
+// of the publish options. An error wrapping ErrMessageTooLarge is returned if the size of the
+// body and headers exceeds the MaxMessageSize of the broker. The message isn't modified.
+func PrepareMessage(m *Message, opts Options, popts PublishOptions) (*Message, error) {
+	msg := m
+	if popts.Compression != NoCompression {
+		body, err := compress(popts.Compression, m.Body)
+		if err != nil {
+			return nil, err
+		}
+		msg = &Message{Header: make(map[string]string, len(m.Header)+1), Body: body}
+		for k, v := range m.Header {
+			msg.Header[k] = v
+		}
+		msg.Header[ContentEncodingHeader] = string(popts.Compression)
+	}
+
+	if opts.MaxMessageSize <= 0 {
+		return msg, nil
+	}
+	size := len(msg.Body)
+	for k, v := range msg.Header {
+		size += len(k) + len(v)
+	}
+	if size > opts.MaxMessageSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the maximum of %d bytes", ErrMessageTooLarge, size, opts.MaxMessageSize)
+	}
+	return msg, nil
+}
+
+// DecompressMessage returns the message with its body decompressed if it was compressed when
+// it was published, otherwise the message is returned as it is
+func DecompressMessage(m *Message) (*Message, error) {
+	c, ok := m.Header[ContentEncodingHeader]
+	if !ok {
+		return m, nil
+	}
+	body, err := decompress(Compression(c), m.Body)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{Header: make(map[string]string, len(m.Header)), Body: body}
+	for k, v := range m.Header {
+		if k != ContentEncodingHeader {
+			msg.Header[k] = v
+		}
+	}
+	msg.SetAckFunc(m.ackFunc)
+	msg.SetNackFunc(m.nackFunc)
+	return msg, nil
+}
+
+func compress(c Compression, body []byte) ([]byte, error) {
+	switch c {
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Snappy:
+		return snappy.Encode(nil, body), nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCompression, c)
+	}
+}
+
+func decompress(c Compression, body []byte) ([]byte, error) {
+	switch c {
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case Snappy:
+		return snappy.Decode(nil, body)
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCompression, c)
+	}
+}
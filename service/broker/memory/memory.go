@@ -43,6 +43,7 @@ type memorySubscriber struct {
 	id      string
 	topic   string
 	exit    chan bool
+	done    chan struct{}
 	handler broker.Handler
 	opts    broker.SubscribeOptions
 }
@@ -107,6 +108,15 @@ func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker
 
 	subs, ok := m.Subscribers[topic]
 	m.RUnlock()
+
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	msg, err := broker.PrepareMessage(msg, m.opts, options)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return nil
 	}
@@ -137,11 +147,20 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 	}
 
 	sub := &memorySubscriber{
-		exit:    make(chan bool, 1),
-		id:      uuid.New().String(),
-		topic:   topic,
-		handler: handler,
-		opts:    options,
+		exit:  make(chan bool, 1),
+		done:  make(chan struct{}),
+		id:    uuid.New().String(),
+		topic: topic,
+		opts:  options,
+	}
+	// the memory broker can't requeue messages so redeliveries are simulated
+	h := broker.Redeliver(handler, options, sub.done)
+	sub.handler = func(msg *broker.Message) error {
+		msg, err := broker.DecompressMessage(msg)
+		if err != nil {
+			return err
+		}
+		return h(msg)
 	}
 
 	m.Lock()
@@ -150,6 +169,7 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 
 	go func() {
 		<-sub.exit
+		close(sub.done)
 		m.Lock()
 		var newSubscribers []*memorySubscriber
 		for _, sb := range m.Subscribers[topic] {
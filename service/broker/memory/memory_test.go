@@ -17,8 +17,12 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/micro/micro/v3/service/broker"
 )
@@ -64,3 +68,179 @@ func TestMemoryBroker(t *testing.T) {
 		t.Fatalf("Unexpected connect error %v", err)
 	}
 }
+
+func TestMemoryBrokerManualAck(t *testing.T) {
+	b := NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	receive := func(ch <-chan *broker.Message) *broker.Message {
+		select {
+		case m := <-ch:
+			return m
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Message wasn't redelivered")
+			return nil
+		}
+	}
+	noRedelivery := func(ch <-chan *broker.Message) {
+		select {
+		case <-ch:
+			t.Fatalf("Message redelivered after it was acknowledged")
+		case <-time.After(300 * time.Millisecond):
+		}
+	}
+
+	tests := []struct {
+		name    string
+		ackWait time.Duration
+		// handle the first delivery of the message, subsequent deliveries are acknowledged
+		handle func(m *broker.Message) error
+	}{
+		{"Nack", time.Minute, func(m *broker.Message) error { return m.Nack() }},
+		{"Error", time.Minute, func(m *broker.Message) error { return errors.New("failed") }},
+		{"AckWait", 100 * time.Millisecond, func(m *broker.Message) error { return nil }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := make(chan *broker.Message, 10)
+			var deliveries int32
+			sub, err := b.Subscribe(tc.name, func(m *broker.Message) error {
+				ch <- m
+				if atomic.AddInt32(&deliveries, 1) == 1 {
+					return tc.handle(m)
+				}
+				return m.Ack()
+			}, broker.ManualAck(tc.ackWait))
+			if err != nil {
+				t.Fatalf("Unexpected error subscribing %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			b.Publish(tc.name, &broker.Message{Body: []byte(`hello world`)})
+			receive(ch)
+			if m := receive(ch); string(m.Body) != "hello world" {
+				t.Fatalf("Unexpected message body %v", string(m.Body))
+			}
+			noRedelivery(ch)
+		})
+	}
+
+	t.Run("Ack", func(t *testing.T) {
+		ch := make(chan *broker.Message, 10)
+		sub, err := b.Subscribe("ack", func(m *broker.Message) error {
+			ch <- m
+			return m.Ack()
+		}, broker.ManualAck(100*time.Millisecond))
+		if err != nil {
+			t.Fatalf("Unexpected error subscribing %v", err)
+		}
+		defer sub.Unsubscribe()
+
+		b.Publish("ack", &broker.Message{Body: []byte(`hello world`)})
+		receive(ch)
+		noRedelivery(ch)
+	})
+}
+
+func TestMemoryBrokerCompression(t *testing.T) {
+	b := NewBroker(broker.MaxMessageSize(1024))
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	ch := make(chan *broker.Message, 1)
+	sub, err := b.Subscribe("test", func(m *broker.Message) error {
+		ch <- m
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	body := []byte(strings.Repeat("hello world ", 200))
+	for _, c := range []broker.Compression{broker.Gzip, broker.Snappy} {
+		msg := &broker.Message{Header: map[string]string{"foo": "bar"}, Body: body}
+		if err := b.Publish("test", msg, broker.Compress(c)); err != nil {
+			t.Fatalf("Unexpected error publishing with %v: %v", c, err)
+		}
+		m := <-ch
+		if string(m.Body) != string(body) || m.Header["foo"] != "bar" {
+			t.Fatalf("Message published with %v wasn't decompressed", c)
+		}
+		if _, ok := m.Header[broker.ContentEncodingHeader]; ok {
+			t.Fatalf("Content encoding header wasn't removed")
+		}
+	}
+
+	// the body exceeds the max message size unless it's compressed
+	err = b.Publish("test", &broker.Message{Body: body})
+	if !errors.Is(err, broker.ErrMessageTooLarge) {
+		t.Fatalf("Expected ErrMessageTooLarge, got %v", err)
+	}
+	err = b.Publish("test", &broker.Message{Body: body}, broker.Compress("zip"))
+	if !errors.Is(err, broker.ErrUnknownCompression) {
+		t.Fatalf("Expected ErrUnknownCompression, got %v", err)
+	}
+}
+
+func TestMemoryBrokerWrappers(t *testing.T) {
+	var calls []string
+	publishWrapper := func(name string) broker.PublishWrapper {
+		return func(fn broker.PublishFunc) broker.PublishFunc {
+			return func(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+				calls = append(calls, name)
+				msg := &broker.Message{Header: map[string]string{name: "true"}, Body: m.Body}
+				for k, v := range m.Header {
+					msg.Header[k] = v
+				}
+				return fn(topic, msg, opts...)
+			}
+		}
+	}
+	subscribeWrapper := func(fn broker.SubscribeFunc) broker.SubscribeFunc {
+		return func(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+			return fn(topic, func(m *broker.Message) error {
+				calls = append(calls, "subscribe "+topic)
+				return h(m)
+			}, opts...)
+		}
+	}
+
+	b := broker.Wrap(NewBroker(broker.WrapPublish(publishWrapper("first"), publishWrapper("second"))))
+	if broker.Wrap(b) != b {
+		t.Fatalf("Broker wrapped twice")
+	}
+	// wrappers set with Init are applied too
+	if err := b.Init(broker.WrapSubscribe(subscribeWrapper)); err != nil {
+		t.Fatalf("Unexpected init error %v", err)
+	}
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	var msg *broker.Message
+	sub, err := b.Subscribe("test", func(m *broker.Message) error {
+		msg = m
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if err := b.Publish("test", &broker.Message{Body: []byte(`hello world`)}); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+	if msg == nil || msg.Header["first"] != "true" || msg.Header["second"] != "true" {
+		t.Fatalf("Publish wrappers weren't applied to the message %v", msg)
+	}
+	if got := strings.Join(calls, ","); got != "first,second,subscribe test" {
+		t.Fatalf("Unexpected order of wrapper calls %v", got)
+	}
+}
@@ -19,6 +19,7 @@ package broker
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
 	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/util/codec"
@@ -30,14 +31,23 @@ type Options struct {
 	Codec  codec.Marshaler
 
 	TLSConfig *tls.Config
+	// MaxMessageSize is the maximum size of the body and headers of a published message, after
+	// it's compressed. Messages aren't limited if it's zero.
+	MaxMessageSize int
 	// Registry used for clustering
 	Registry registry.Registry
+	// PublishWrappers and SubscribeWrappers are applied to Publish and Subscribe by the broker
+	// returned by Wrap
+	PublishWrappers   []PublishWrapper
+	SubscribeWrappers []SubscribeWrapper
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
 }
 
 type PublishOptions struct {
+	// Compression of the body of the message
+	Compression Compression
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -52,6 +62,13 @@ type SubscribeOptions struct {
 	// receives a subset of messages.
 	Queue string
 
+	// ManualAck if true, each message needs to be acknowledged by the handler with Message.Ack.
+	// A message which is nacked, or whose handler returns an error without acknowledging it, is
+	// redelivered, as is a message which isn't acknowledged within the AckWait. Otherwise the
+	// message is acknowledged once the handler returns.
+	ManualAck bool
+	AckWait   time.Duration
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -68,6 +85,13 @@ func PublishContext(ctx context.Context) PublishOption {
 	}
 }
 
+// Compress the body of the message, it's decompressed before it's passed to the subscribers
+func Compress(c Compression) PublishOption {
+	return func(o *PublishOptions) {
+		o.Compression = c
+	}
+}
+
 type SubscribeOption func(*SubscribeOptions)
 
 func NewSubscribeOptions(opts ...SubscribeOption) SubscribeOptions {
@@ -103,6 +127,16 @@ func HandleError(h ErrorHandler) SubscribeOption {
 	}
 }
 
+// ManualAck sets the subscriber to acknowledge each message with Message.Ack, so messages whose
+// handler fails are redelivered rather than dropped. Messages which aren't acknowledged within
+// the ackWait are redelivered, DefaultAckWait is used if it's zero.
+func ManualAck(ackWait time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.ManualAck = true
+		o.AckWait = ackWait
+	}
+}
+
 // Queue sets the name of the queue to share messages on
 func Queue(name string) SubscribeOption {
 	return func(o *SubscribeOptions) {
@@ -110,6 +144,14 @@ func Queue(name string) SubscribeOption {
 	}
 }
 
+// MaxMessageSize sets the maximum size of a published message, ErrMessageTooLarge is returned
+// when publishing a larger message
+func MaxMessageSize(size int) Option {
+	return func(o *Options) {
+		o.MaxMessageSize = size
+	}
+}
+
 func Registry(r registry.Registry) Option {
 	return func(o *Options) {
 		o.Registry = r
@@ -130,6 +172,20 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// WrapPublish adds wrappers to the list of PublishFunc wrappers
+func WrapPublish(w ...PublishWrapper) Option {
+	return func(o *Options) {
+		o.PublishWrappers = append(o.PublishWrappers, w...)
+	}
+}
+
+// WrapSubscribe adds wrappers to the list of SubscribeFunc wrappers
+func WrapSubscribe(w ...SubscribeWrapper) Option {
+	return func(o *Options) {
+		o.SubscribeWrappers = append(o.SubscribeWrappers, w...)
+	}
+}
+
 // SubscribeContext set context
 func SubscribeContext(ctx context.Context) SubscribeOption {
 	return func(o *SubscribeOptions) {
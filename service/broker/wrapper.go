@@ -0,0 +1,47 @@
+package broker
+
+// PublishFunc publishes a message to a topic
+type PublishFunc func(topic string, m *Message, opts ...PublishOption) error
+
+// PublishWrapper wraps a PublishFunc, e.g. to set tracing headers or sign the messages published.
+// Wrappers shouldn't modify the message they're passed, as it's owned by the publisher.
+type PublishWrapper func(PublishFunc) PublishFunc
+
+// SubscribeFunc subscribes a handler to a topic
+type SubscribeFunc func(topic string, h Handler, opts ...SubscribeOption) (Subscriber, error)
+
+// SubscribeWrapper wraps a SubscribeFunc, e.g. to wrap the handler so it verifies the messages
+// before they're handled or records metrics for the topic
+type SubscribeWrapper func(SubscribeFunc) SubscribeFunc
+
+// Wrap returns the broker with the PublishWrappers and SubscribeWrappers of its options applied
+// to Publish and Subscribe, so the wrappers work with any implementation of the broker. The
+// wrappers are read from the options on each call, so wrappers set with Init are applied too.
+func Wrap(b Broker) Broker {
+	if _, ok := b.(*wrappedBroker); ok {
+		return b
+	}
+	return &wrappedBroker{b}
+}
+
+type wrappedBroker struct {
+	Broker
+}
+
+func (w *wrappedBroker) Publish(topic string, m *Message, opts ...PublishOption) error {
+	var fn PublishFunc = w.Broker.Publish
+	wrappers := w.Broker.Options().PublishWrappers
+	for i := len(wrappers); i > 0; i-- {
+		fn = wrappers[i-1](fn)
+	}
+	return fn(topic, m, opts...)
+}
+
+func (w *wrappedBroker) Subscribe(topic string, h Handler, opts ...SubscribeOption) (Subscriber, error) {
+	var fn SubscribeFunc = w.Broker.Subscribe
+	wrappers := w.Broker.Options().SubscribeWrappers
+	for i := len(wrappers); i > 0; i-- {
+		fn = wrappers[i-1](fn)
+	}
+	return fn(topic, h, opts...)
+}
@@ -92,6 +92,9 @@ type Stream interface {
 	Recv(interface{}) error
 	// Error returns the stream error
 	Error() error
+	// CloseSend closes the sending side of the stream, so the peer receives the end of the stream
+	// while the responses can still be received
+	CloseSend() error
 	// Close closes the stream
 	Close() error
 }
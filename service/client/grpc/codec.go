@@ -27,6 +27,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/micro/micro/v3/util/codec"
 	"github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/oxtoacart/bpool"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/encoding"
@@ -35,6 +36,7 @@ import (
 type jsonCodec struct{}
 type protoCodec struct{}
 type bytesCodec struct{}
+type cborCodec struct{}
 type wrapCodec struct{ encoding.Codec }
 
 var jsonpbMarshaler = &jsonpb.Marshaler{
@@ -55,6 +57,8 @@ var (
 		"application/grpc+json":    jsonCodec{},
 		"application/grpc+proto":   protoCodec{},
 		"application/grpc+bytes":   bytesCodec{},
+		"application/grpc+cbor":    cborCodec{},
+		"application/cbor":         cborCodec{},
 		"multipart/form-data":      jsonCodec{},
 	}
 )
@@ -168,6 +172,28 @@ func (jsonCodec) Name() string {
 	return "json"
 }
 
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	if b, ok := v.(*bytes.Frame); ok {
+		return b.Data, nil
+	}
+	return cbor.Marshaler{}.Marshal(v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if b, ok := v.(*bytes.Frame); ok {
+		b.Data = data
+		return nil
+	}
+	return cbor.Marshaler{}.Unmarshal(data, v)
+}
+
+func (cborCodec) Name() string {
+	return "cbor"
+}
+
 type grpcCodec struct {
 	// headers
 	id       string
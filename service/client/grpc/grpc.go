@@ -32,6 +32,9 @@ import (
 	"github.com/micro/micro/v3/service/context/metadata"
 	"github.com/micro/micro/v3/service/errors"
 	raw "github.com/micro/micro/v3/util/codec/bytes"
+	mnet "github.com/micro/micro/v3/util/net"
+	"github.com/micro/micro/v3/util/selector"
+	mls "github.com/micro/micro/v3/util/tls"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -49,11 +52,34 @@ func init() {
 	encoding.RegisterCodec(wrapCodec{jsonCodec{}})
 	encoding.RegisterCodec(wrapCodec{protoCodec{}})
 	encoding.RegisterCodec(wrapCodec{bytesCodec{}})
+	encoding.RegisterCodec(wrapCodec{cborCodec{}})
+}
+
+// tlsConfig returns the tls config of the call, or of the service it's to, if any
+func (g *grpcClient) tlsConfig(service string, opts client.CallOptions) *tls.Config {
+	if opts.TLSConfig != nil {
+		return opts.TLSConfig
+	}
+	return g.opts.TLSConfigs[service]
+}
+
+// poolKey returns the key of the conns to the address secured by the tls config in the pool,
+// equal configs share the conns so a config built for each call doesn't dial a conn of its own
+func poolKey(addr string, config *tls.Config) string {
+	if config == nil {
+		return addr
+	}
+	return addr + "#" + mls.ConfigKey(config)
 }
 
 // secure returns the dial option for whether its a secure or insecure connection
-func (g *grpcClient) secure(addr string) grpc.DialOption {
-	// first we check if theres'a  tls config
+func (g *grpcClient) secure(addr string, config *tls.Config) grpc.DialOption {
+	// the tls config of the call or target service goes first
+	if config != nil {
+		return grpc.WithTransportCredentials(credentials.NewTLS(config))
+	}
+
+	// then we check if theres'a  tls config
 	if g.opts.Context != nil {
 		if v := g.opts.Context.Value(tlsAuth{}); v != nil {
 			tls := v.(*tls.Config)
@@ -63,6 +89,11 @@ func (g *grpcClient) secure(addr string) grpc.DialOption {
 		}
 	}
 
+	// a unix socket is local so isn't secured by default
+	if mnet.IsUnix(addr) {
+		return grpc.WithInsecure()
+	}
+
 	// default config
 	tlsConfig := &tls.Config{}
 	defaultCreds := grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
@@ -116,9 +147,10 @@ func (g *grpcClient) call(ctx context.Context, addr string, req client.Request,
 
 	var grr error
 
+	config := g.tlsConfig(req.Service(), opts)
 	grpcDialOptions := []grpc.DialOption{
 		grpc.WithTimeout(opts.DialTimeout),
-		g.secure(addr),
+		g.secure(addr, config),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(maxRecvMsgSize),
 			grpc.MaxCallSendMsgSize(maxSendMsgSize),
@@ -129,7 +161,7 @@ func (g *grpcClient) call(ctx context.Context, addr string, req client.Request,
 		grpcDialOptions = append(grpcDialOptions, opts...)
 	}
 
-	cc, err := g.pool.getConn(addr, grpcDialOptions...)
+	cc, err := g.pool.getConn(poolKey(addr, config), addr, grpcDialOptions...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", fmt.Sprintf("Error sending request: %v", err))
 	}
@@ -193,9 +225,10 @@ func (g *grpcClient) stream(ctx context.Context, addr string, req client.Request
 	maxRecvMsgSize := g.maxRecvMsgSizeValue()
 	maxSendMsgSize := g.maxSendMsgSizeValue()
 
+	config := g.tlsConfig(req.Service(), opts)
 	grpcDialOptions := []grpc.DialOption{
 		grpc.WithTimeout(opts.DialTimeout),
-		g.secure(addr),
+		g.secure(addr, config),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(maxRecvMsgSize),
 			grpc.MaxCallSendMsgSize(maxSendMsgSize),
@@ -206,7 +239,7 @@ func (g *grpcClient) stream(ctx context.Context, addr string, req client.Request
 		grpcDialOptions = append(grpcDialOptions, opts...)
 	}
 
-	cc, err := g.pool.getConn(addr, grpcDialOptions...)
+	cc, err := g.pool.getConn(poolKey(addr, config), addr, grpcDialOptions...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", fmt.Sprintf("Error sending request: %v", err))
 	}
@@ -300,6 +333,17 @@ func (g *grpcClient) poolMaxIdle() int {
 	return v.(int)
 }
 
+func (g *grpcClient) poolIdleTimeout() time.Duration {
+	if g.opts.Context == nil {
+		return DefaultPoolIdleTimeout
+	}
+	v := g.opts.Context.Value(poolIdleTimeout{})
+	if v == nil {
+		return DefaultPoolIdleTimeout
+	}
+	return v.(time.Duration)
+}
+
 func (g *grpcClient) maxRecvMsgSizeValue() int {
 	if g.opts.Context == nil {
 		return DefaultMaxRecvMsgSize
@@ -339,20 +383,14 @@ func (g *grpcClient) newGRPCCodec(contentType string) (encoding.Codec, error) {
 }
 
 func (g *grpcClient) Init(opts ...client.Option) error {
-	size := g.opts.PoolSize
-	ttl := g.opts.PoolTTL
-
 	for _, o := range opts {
 		o(&g.opts)
 	}
 
-	// update pool configuration if the options changed
-	if size != g.opts.PoolSize || ttl != g.opts.PoolTTL {
-		g.pool.Lock()
-		g.pool.size = g.opts.PoolSize
-		g.pool.ttl = int64(g.opts.PoolTTL.Seconds())
-		g.pool.Unlock()
-	}
+	// update the pool configuration in case the options changed
+	g.pool.Lock()
+	g.pool.configure(g.opts.PoolSize, g.opts.PoolTTL, g.poolMaxIdle(), g.poolMaxStreams(), g.poolIdleTimeout())
+	g.pool.Unlock()
 
 	return nil
 }
@@ -370,6 +408,11 @@ func (g *grpcClient) NewRequest(service, method string, req interface{}, reqOpts
 }
 
 func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	return client.ChainUnaryInterceptors(g.invoke, g.opts.UnaryInterceptors...)(ctx, req, rsp, opts...)
+}
+
+// invoke makes the call once it's passed through the interceptors
+func (g *grpcClient) invoke(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
 	if req == nil {
 		return errors.InternalServerError("go.micro.client", "req is nil")
 	} else if rsp == nil {
@@ -433,7 +476,7 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(ctx, routes, callOpts)...)
 	if err != nil {
 		return err
 	}
@@ -451,14 +494,29 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 			time.Sleep(t)
 		}
 
+		// limit the attempt to the per try timeout
+		tctx, topts := ctx, callOpts
+		if callOpts.PerTryTimeout > 0 && callOpts.PerTryTimeout < callOpts.RequestTimeout {
+			var cancel context.CancelFunc
+			tctx, cancel = context.WithTimeout(ctx, callOpts.PerTryTimeout)
+			defer cancel()
+			topts.RequestTimeout = callOpts.PerTryTimeout
+		}
+
+		// hedge the call to another node if the first is slow to respond
+		if callOpts.HedgeDelay > 0 || callOpts.HedgePercentile > 0 {
+			return client.HedgeCall(tctx, req, rsp, topts, next, gcall)
+		}
+
 		// get the next node
 		node := next()
+		start := time.Now()
 
 		// make the call
-		err = gcall(ctx, node, req, rsp, callOpts)
+		err = gcall(tctx, node, req, rsp, topts)
 
 		// record the result of the call to inform future routing decisions
-		g.opts.Selector.Record(node, err)
+		callOpts.Selector.Record(node, err, selector.Started(start))
 
 		// try and transform the error to a go-micro error
 		if verr, ok := err.(*errors.Error); ok {
@@ -502,6 +560,11 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 }
 
 func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return client.ChainStreamInterceptors(g.openStream, g.opts.StreamInterceptors...)(ctx, req, opts...)
+}
+
+// openStream opens the stream once it's passed through the interceptors
+func (g *grpcClient) openStream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
 	// make a copy of call opts
 	callOpts := g.opts.CallOptions
 	for _, opt := range opts {
@@ -548,7 +611,7 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(ctx, routes, callOpts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -567,20 +630,20 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 
 		// get the next node
 		node := next()
+		start := time.Now()
 
 		// make the call
 		stream := &grpcStream{}
 		err = g.stream(ctx, node, req, stream, callOpts)
 
 		// record the result of the call to inform future routing decisions
-		g.opts.Selector.Record(node, err)
+		callOpts.Selector.Record(node, err, selector.Started(start))
 
 		// try and transform the error to a go-micro error
 		if verr, ok := err.(*errors.Error); ok {
 			return nil, verr
 		}
 
-		g.opts.Selector.Record(node, err)
 		return stream, err
 	}
 
@@ -604,7 +667,7 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 		case rsp := <-ch:
 			// if the call succeeded lets bail early
 			if rsp.err == nil {
-				return rsp.stream, nil
+				return client.BufferStream(rsp.stream, callOpts), nil
 			}
 
 			retry, rerr := callOpts.Retry(ctx, req, i, grr)
@@ -734,7 +797,7 @@ func newClient(opts ...client.Option) client.Client {
 	}
 	rc.once.Store(false)
 
-	rc.pool = newPool(options.PoolSize, options.PoolTTL, rc.poolMaxIdle(), rc.poolMaxStreams())
+	rc.pool = newPool(options.PoolSize, options.PoolTTL, rc.poolMaxIdle(), rc.poolMaxStreams(), rc.poolIdleTimeout())
 
 	c := client.Client(rc)
 
@@ -18,6 +18,8 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net"
 	"testing"
 
@@ -27,7 +29,9 @@ import (
 	"github.com/micro/micro/v3/service/registry/memory"
 	"github.com/micro/micro/v3/service/router"
 	regRouter "github.com/micro/micro/v3/service/router/registry"
+	mls "github.com/micro/micro/v3/util/tls"
 	pgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	pb "google.golang.org/grpc/examples/helloworld/helloworld"
 )
 
@@ -124,3 +128,98 @@ func TestGRPCClient(t *testing.T) {
 	}
 
 }
+
+func TestGRPCClientTargetTLS(t *testing.T) {
+	// certificates of the server and client, the server's being its own CA
+	serverCert, err := mls.Certificate("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, err := mls.Certificate("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(serverCert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCA := x509.NewCertPool()
+	serverCA.AddCert(c)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	// the server requires mutual tls
+	s := pgrpc.NewServer(pgrpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})))
+	pb.RegisterGreeterServer(s, &greeterServer{})
+
+	go s.Serve(l)
+	defer s.Stop()
+
+	r := memory.NewRegistry()
+	for _, name := range []string{"helloworld", "external"} {
+		r.Register(&registry.Service{
+			Name:    name,
+			Version: "test",
+			Nodes: []*registry.Node{
+				{
+					Id:      name + "-1",
+					Address: l.Addr().String(),
+					Metadata: map[string]string{
+						"protocol": "grpc",
+					},
+				},
+			},
+		})
+	}
+
+	mtls := &tls.Config{
+		RootCAs:      serverCA,
+		Certificates: []tls.Certificate{clientCert},
+	}
+
+	cl := NewClient(
+		client.Router(regRouter.NewRouter(router.Registry(r))),
+		client.TargetTLS("helloworld", mtls),
+		client.Retries(0),
+	)
+
+	call := func(service string, opts ...client.CallOption) error {
+		req := cl.NewRequest(service, "/helloworld.Greeter/SayHello", &pb.HelloRequest{Name: "John"})
+		rsp := pb.HelloReply{}
+		if err := cl.Call(context.TODO(), req, &rsp, opts...); err != nil {
+			return err
+		}
+		if rsp.Message != "Hello John" {
+			t.Fatalf("Got unexpected response %v", rsp.Message)
+		}
+		return nil
+	}
+
+	// the target is called with mutual tls
+	if err := call("helloworld"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the other services aren't secured by the config of the target
+	if err := call("external"); err == nil {
+		t.Fatal("expected an error calling without the tls config of the target")
+	}
+
+	// unless the call is
+	if err := call("external", client.WithTLSConfig(mtls)); err != nil {
+		t.Fatal(err)
+	}
+
+	// and the tls config of the call goes over that of the target
+	noCert := &tls.Config{RootCAs: serverCA}
+	if err := call("helloworld", client.WithTLSConfig(noCert)); err == nil {
+		t.Fatal("expected an error calling without a client certificate")
+	}
+}
@@ -20,6 +20,7 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
 	"github.com/micro/micro/v3/service/client"
 	"google.golang.org/grpc"
@@ -35,6 +36,10 @@ var (
 	// (50)
 	DefaultPoolMaxIdle = 50
 
+	// DefaultPoolIdleTimeout how long a conn can be idle before it's closed
+	// (0, conns are only closed once they're older than the pool ttl)
+	DefaultPoolIdleTimeout time.Duration = 0
+
 	// DefaultMaxRecvMsgSize maximum message that client can receive
 	// (32 MB).
 	DefaultMaxRecvMsgSize = 1024 * 1024 * 32
@@ -46,6 +51,7 @@ var (
 
 type poolMaxStreams struct{}
 type poolMaxIdle struct{}
+type poolIdleTimeout struct{}
 type codecsKey struct{}
 type tlsAuth struct{}
 type maxRecvMsgSizeKey struct{}
@@ -73,6 +79,16 @@ func PoolMaxIdle(d int) client.Option {
 	}
 }
 
+// how long a conn of a pool can be idle before it's closed
+func PoolIdleTimeout(d time.Duration) client.Option {
+	return func(o *client.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, poolIdleTimeout{}, d)
+	}
+}
+
 // gRPC Codec to be used to encode/decode requests for a given content type
 func Codec(contentType string, c encoding.Codec) client.Option {
 	return func(o *client.Options) {
@@ -18,8 +18,10 @@ package grpc
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/micro/micro/v3/service/metrics"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 )
@@ -32,11 +34,26 @@ type pool struct {
 	maxStreams int
 	//  max idle conns
 	maxIdle int
+	//  how long a conn can be idle before it's closed
+	idleTimeout time.Duration
+
+	//  the conns dialled, reused and closed
+	stats poolStats
 
 	sync.Mutex
 	conns map[string]*streamsPool
 }
 
+//  the counts of the conns of a pool
+type poolStats struct {
+	//  conns dialled
+	dials int64
+	//  calls and streams made on an existing conn
+	reuses int64
+	//  conns closed
+	closes int64
+}
+
 type streamsPool struct {
 	//  head of list
 	head *poolConn
@@ -59,6 +76,8 @@ type poolConn struct {
 	sp      *streamsPool
 	streams int
 	created int64
+	//  the time the conn became idle
+	idle time.Time
 
 	//  list
 	pre  *poolConn
@@ -66,29 +85,77 @@ type poolConn struct {
 	in   bool
 }
 
-func newPool(size int, ttl time.Duration, idle int, ms int) *pool {
+func newPool(size int, ttl time.Duration, idle int, ms int, idleTimeout time.Duration) *pool {
+	p := &pool{
+		conns: make(map[string]*streamsPool),
+	}
+	p.configure(size, ttl, idle, ms, idleTimeout)
+	return p
+}
+
+//  configure sets the limits of the pool, the caller must hold the lock if the pool is in use
+func (p *pool) configure(size int, ttl time.Duration, idle int, ms int, idleTimeout time.Duration) {
 	if ms <= 0 {
 		ms = 1
 	}
 	if idle < 0 {
 		idle = 0
 	}
-	return &pool{
-		size:       size,
-		ttl:        int64(ttl.Seconds()),
-		maxStreams: ms,
-		maxIdle:    idle,
-		conns:      make(map[string]*streamsPool),
+	p.size = size
+	p.ttl = int64(ttl.Seconds())
+	p.maxStreams = ms
+	p.maxIdle = idle
+	p.idleTimeout = idleTimeout
+}
+
+//  getStats returns the counts of the conns of the pool
+func (p *pool) getStats() poolStats {
+	return poolStats{
+		dials:  atomic.LoadInt64(&p.stats.dials),
+		reuses: atomic.LoadInt64(&p.stats.reuses),
+		closes: atomic.LoadInt64(&p.stats.closes),
+	}
+}
+
+//  count adds to the count of the stat and reports it as a metric
+func (p *pool) count(stat *int64, id, addr, reason string) {
+	atomic.AddInt64(stat, 1)
+	if metrics.DefaultMetricsReporter == nil {
+		return
+	}
+	tags := metrics.Tags{"address": addr}
+	if len(reason) > 0 {
+		tags["reason"] = reason
 	}
+	metrics.Count(id, 1, tags)
 }
 
-func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error) {
-	now := time.Now().Unix()
+//  close closes the conn, which must have been removed from the pool
+func (p *pool) close(conn *poolConn, reason string) {
+	conn.ClientConn.Close()
+	p.count(&p.stats.closes, "client.pool.closes", conn.addr, reason)
+}
+
+//  broken returns true if the call on the conn failed because of the conn
+func broken(conn *poolConn, err error) bool {
+	if err == nil {
+		return false
+	}
+	switch conn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return true
+	}
+	return false
+}
+
+// getConn returns a conn to the address from those pooled by the key, dialling one if needed
+func (p *pool) getConn(key, addr string, opts ...grpc.DialOption) (*poolConn, error) {
+	now := time.Now()
 	p.Lock()
-	sp, ok := p.conns[addr]
+	sp, ok := p.conns[key]
 	if !ok {
 		sp = &streamsPool{head: &poolConn{}, busy: &poolConn{}, count: 0, idle: 0}
-		p.conns[addr] = sp
+		p.conns[key] = sp
 	}
 	//  while we have conns check streams and then return one
 	//  otherwise we'll create a new conn
@@ -112,7 +179,7 @@ func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error)
 			next := conn.next
 			if conn.streams == 0 {
 				removeConn(conn)
-				conn.ClientConn.Close()
+				p.close(conn, "error")
 				sp.idle--
 			}
 			conn = next
@@ -121,16 +188,25 @@ func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error)
 		case connectivity.Idle:
 		}
 		//  a old conn
-		if now-conn.created > p.ttl {
+		if now.Unix()-conn.created > p.ttl {
 			next := conn.next
 			if conn.streams == 0 {
 				removeConn(conn)
-				conn.ClientConn.Close()
+				p.close(conn, "ttl")
 				sp.idle--
 			}
 			conn = next
 			continue
 		}
+		//  a conn idle for too long
+		if conn.streams == 0 && p.idleTimeout > 0 && now.Sub(conn.idle) > p.idleTimeout {
+			next := conn.next
+			removeConn(conn)
+			p.close(conn, "idle_timeout")
+			sp.idle--
+			conn = next
+			continue
+		}
 		//  a busy conn
 		if conn.streams >= p.maxStreams {
 			next := conn.next
@@ -146,6 +222,7 @@ func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error)
 		//  a good conn
 		conn.streams++
 		p.Unlock()
+		p.count(&p.stats.reuses, "client.pool.reuses", addr, "")
 		return conn, nil
 	}
 	p.Unlock()
@@ -155,7 +232,15 @@ func (p *pool) getConn(addr string, opts ...grpc.DialOption) (*poolConn, error)
 	if err != nil {
 		return nil, err
 	}
-	conn = &poolConn{cc, nil, addr, p, sp, 1, time.Now().Unix(), nil, nil, false}
+	p.count(&p.stats.dials, "client.pool.dials", addr, "")
+	conn = &poolConn{
+		ClientConn: cc,
+		addr:       addr,
+		pool:       p,
+		sp:         sp,
+		streams:    1,
+		created:    time.Now().Unix(),
+	}
 
 	//  add conn to streams pool
 	p.Lock()
@@ -176,7 +261,7 @@ func (p *pool) release(addr string, conn *poolConn, err error) {
 	}
 	if !conn.in {
 		p.Unlock()
-		conn.ClientConn.Close()
+		p.close(conn, "full")
 		return
 	}
 	//  a busy conn
@@ -187,16 +272,26 @@ func (p *pool) release(addr string, conn *poolConn, err error) {
 	conn.streams--
 	//  if streams == 0, we can do something
 	if conn.streams == 0 {
-		//  1. it has errored
+		//  1. it has broken
 		//  2. too many idle conn or
 		//  3. conn is too old
-		now := time.Now().Unix()
-		if err != nil || sp.idle >= p.maxIdle || now-created > p.ttl {
+		now := time.Now()
+		var reason string
+		switch {
+		case broken(conn, err):
+			reason = "error"
+		case sp.idle >= p.maxIdle:
+			reason = "max_idle"
+		case now.Unix()-created > p.ttl:
+			reason = "ttl"
+		}
+		if len(reason) > 0 {
 			removeConn(conn)
 			p.Unlock()
-			conn.ClientConn.Close()
+			p.close(conn, reason)
 			return
 		}
+		conn.idle = now
 		sp.idle++
 	}
 	p.Unlock()
@@ -42,11 +42,11 @@ func testPool(t *testing.T, size int, ttl time.Duration, idle int, ms int) {
 	defer s.Stop()
 
 	// zero pool
-	p := newPool(size, ttl, idle, ms)
+	p := newPool(size, ttl, idle, ms, 0)
 
 	for i := 0; i < 10; i++ {
 		// get a conn
-		cc, err := p.getConn(l.Addr().String(), grpc.WithInsecure())
+		cc, err := p.getConn(l.Addr().String(), l.Addr().String(), grpc.WithInsecure())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -78,3 +78,53 @@ func TestGRPCPool(t *testing.T) {
 	testPool(t, 0, time.Minute, 10, 2)
 	testPool(t, 2, time.Minute, 10, 1)
 }
+
+func TestGRPCPoolReuse(t *testing.T) {
+	// setup server
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	s := pgrpc.NewServer()
+	pb.RegisterGreeterServer(s, &greeterServer{})
+
+	go s.Serve(l)
+	defer s.Stop()
+
+	addr := l.Addr().String()
+	p := newPool(2, time.Minute, 10, 1, 50*time.Millisecond)
+
+	call := func(name string) error {
+		cc, err := p.getConn(addr, addr, grpc.WithInsecure())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsp := pb.HelloReply{}
+		err = cc.Invoke(context.TODO(), "/helloworld.Greeter/SayHello", &pb.HelloRequest{Name: name}, &rsp)
+		p.release(addr, cc, err)
+		return err
+	}
+
+	// the conn is reused, even when the call returns an error
+	for i := 0; i < 5; i++ {
+		name := "John"
+		if i%2 == 0 {
+			name = "Error"
+		}
+		call(name)
+	}
+	if stats := p.getStats(); stats.dials != 1 || stats.reuses != 4 || stats.closes != 0 {
+		t.Fatalf("Expected the conn to be reused, got %+v", stats)
+	}
+
+	// the conn is closed once it's idle for too long
+	time.Sleep(100 * time.Millisecond)
+	if err := call("John"); err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.getStats(); stats.dials != 2 || stats.closes != 1 {
+		t.Fatalf("Expected the idle conn to be closed, got %+v", stats)
+	}
+}
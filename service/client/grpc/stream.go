@@ -90,6 +90,15 @@ func (g *grpcStream) setError(e error) {
 	g.Unlock()
 }
 
+// CloseSend closes the sending side of the stream, the responses can still be received
+func (g *grpcStream) CloseSend() error {
+	if err := g.ClientStream.CloseSend(); err != nil {
+		g.setError(err)
+		return err
+	}
+	return nil
+}
+
 // Close the gRPC send stream
 // #202 - inconsistent gRPC stream behavior
 // The underlying gRPC stream should not be closed here since the
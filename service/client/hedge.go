@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/util/selector"
+)
+
+const (
+	// hedgeSamples is the number of the latest latencies of each endpoint kept to calculate the
+	// percentile hedge delay with
+	hedgeSamples = 100
+	// hedgeMinSamples is the number of latencies of an endpoint needed before the percentile is
+	// used as the hedge delay
+	hedgeMinSamples = 20
+)
+
+var (
+	// the latest latencies of the hedged calls to each endpoint
+	latencies = map[string]*latency{}
+	latencyMu sync.Mutex
+)
+
+// latency keeps a ring of the latest latencies of an endpoint
+type latency struct {
+	samples []time.Duration
+	next    int
+}
+
+func (l *latency) add(d time.Duration) {
+	if len(l.samples) < hedgeSamples {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % hedgeSamples
+}
+
+func (l *latency) percentile(p float64) (time.Duration, bool) {
+	if len(l.samples) < hedgeMinSamples {
+		return 0, false
+	}
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(p*float64(len(sorted)-1))], true
+}
+
+// hedgeDelay returns the time to wait for a response before hedging the call, the percentile of
+// the latencies of the endpoint once there are enough of them, otherwise the fixed delay
+func hedgeDelay(key string, opts CallOptions) time.Duration {
+	if opts.HedgePercentile <= 0 {
+		return opts.HedgeDelay
+	}
+
+	latencyMu.Lock()
+	defer latencyMu.Unlock()
+	if l, ok := latencies[key]; ok {
+		if d, ok := l.percentile(opts.HedgePercentile); ok {
+			return d
+		}
+	}
+	return opts.HedgeDelay
+}
+
+func recordLatency(key string, d time.Duration) {
+	latencyMu.Lock()
+	defer latencyMu.Unlock()
+
+	l, ok := latencies[key]
+	if !ok {
+		l = &latency{}
+		latencies[key] = l
+	}
+	l.add(d)
+}
+
+// HedgeCall makes the call to the next node, and to another node if the first hasn't responded
+// within the hedge delay, returning the first successful response and cancelling the other call.
+// The result of each call is recorded with the selector, the call cancelled is recorded without an
+// error. Only idempotent calls should be hedged.
+func HedgeCall(ctx context.Context, req Request, rsp interface{}, opts CallOptions, next selector.Next, call CallFunc) error {
+	key := req.Service() + "." + req.Endpoint()
+	delay := hedgeDelay(key, opts)
+
+	type result struct {
+		node string
+		rsp  interface{}
+		err  error
+		// the context of the call, to tell whether it was cancelled
+		ctx context.Context
+		// when the call was started
+		start time.Time
+	}
+
+	// record the result of a call with the selector, calls which were cancelled haven't failed
+	// nor completed so their routes are only released
+	record := func(res result) {
+		if res.err != nil && res.ctx.Err() != nil {
+			opts.Selector.Record(res.node, nil)
+			return
+		}
+		opts.Selector.Record(res.node, res.err, selector.Started(res.start))
+	}
+
+	// each call decodes into its own response so the one which loses can't race the winner
+	results := make(chan result, 2)
+	var cancels []context.CancelFunc
+	var pending int
+	defer func() {
+		// cancel the call which lost, recording its result once it returns
+		for _, cancel := range cancels {
+			cancel()
+		}
+		if pending == 0 {
+			return
+		}
+		go func(pending int) {
+			for i := 0; i < pending; i++ {
+				record(<-results)
+			}
+		}(pending)
+	}()
+	start := time.Now()
+	send := func(node string) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		crsp := newResponse(rsp)
+		started := time.Now()
+		go func() {
+			err := call(cctx, node, req, crsp, opts)
+			results <- result{node, crsp, err, cctx, started}
+		}()
+	}
+
+	first := next()
+	send(first)
+	pending = 1
+
+	var timer <-chan time.Time
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer:
+			timer = nil
+			// hedge the call to another node, unless there's only one in which case the
+			// selection is released as no call is made to it
+			if node := next(); node != first {
+				send(node)
+				pending++
+			} else {
+				opts.Selector.Record(node, nil)
+			}
+		case res := <-results:
+			pending--
+			record(res)
+			if res.err == nil {
+				recordLatency(key, time.Since(start))
+				copyResponse(rsp, res.rsp)
+				return nil
+			}
+			err = res.err
+			// the first call failed before it was hedged so it's retried instead
+			if timer != nil {
+				return err
+			}
+		}
+	}
+
+	return err
+}
+
+// newResponse returns a new response of the same type as the response
+func newResponse(rsp interface{}) interface{} {
+	v := reflect.ValueOf(rsp)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return rsp
+	}
+	return reflect.New(v.Type().Elem()).Interface()
+}
+
+// copyResponse copies the response decoded into the response of the call
+func copyResponse(dst, src interface{}) {
+	if dst == src {
+		return
+	}
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(src).Elem())
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/util/selector"
+	"github.com/micro/micro/v3/util/selector/roundrobin"
+)
+
+// recorder is a selector which records the results recorded with it
+type recorder struct {
+	selector.Selector
+
+	sync.Mutex
+	results map[string][]error
+}
+
+func (r *recorder) Record(node string, err error, opts ...selector.RecordOption) error {
+	r.Lock()
+	defer r.Unlock()
+	r.results[node] = append(r.results[node], err)
+	return nil
+}
+
+// recorded waits for the number of results to be recorded and returns them
+func (r *recorder) recorded(t *testing.T, n int) map[string][]error {
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.Lock()
+		var count int
+		for _, errs := range r.results {
+			count += len(errs)
+		}
+		if count >= n {
+			defer r.Unlock()
+			return r.results
+		}
+		r.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d results to be recorded, got %d", n, count)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type testResponse struct {
+	Node string
+}
+
+func TestHedgeCall(t *testing.T) {
+	r := &testRequest{service: "hedge", method: "Test.Hedge"}
+	sel := roundrobin.NewSelector()
+	opts := CallOptions{Selector: sel, HedgeDelay: 10 * time.Millisecond}
+
+	// the slow node only responds when its call is cancelled
+	var mu sync.Mutex
+	cancelled := map[string]bool{}
+	call := func(slow string, fail bool) CallFunc {
+		return func(ctx context.Context, node string, req Request, rsp interface{}, opts CallOptions) error {
+			if node == slow {
+				<-ctx.Done()
+				mu.Lock()
+				cancelled[node] = true
+				mu.Unlock()
+				return ctx.Err()
+			}
+			if fail {
+				return errors.New("connection refused")
+			}
+			rsp.(*testResponse).Node = node
+			return nil
+		}
+	}
+
+	// selects the nodes in order
+	nodes := func(nodes ...string) func() string {
+		var i int
+		return func() string {
+			node := nodes[i%len(nodes)]
+			i++
+			return node
+		}
+	}
+
+	t.Run("Hedged", func(t *testing.T) {
+		slow := "a"
+		rsp := &testResponse{}
+		if err := HedgeCall(context.TODO(), r, rsp, opts, nodes("a", "b"), call(slow, false)); err != nil {
+			t.Fatalf("Error making the hedged call: %v", err)
+		}
+		if rsp.Node != "b" {
+			t.Errorf("Expected the response of the fast node, got %+v", rsp)
+		}
+
+		// the slow call is cancelled
+		deadline := time.Now().Add(time.Second)
+		for {
+			mu.Lock()
+			done := cancelled[slow]
+			mu.Unlock()
+			if done {
+				break
+			} else if time.Now().After(deadline) {
+				t.Fatal("Expected the slow call to be cancelled")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("Recorded", func(t *testing.T) {
+		rec := &recorder{Selector: sel, results: map[string][]error{}}
+		opts := CallOptions{Selector: rec, HedgeDelay: 10 * time.Millisecond}
+		rsp := &testResponse{}
+		if err := HedgeCall(context.TODO(), r, rsp, opts, nodes("a", "b"), call("a", false)); err != nil {
+			t.Fatalf("Error making the hedged call: %v", err)
+		}
+
+		// the call cancelled isn't recorded as a failure
+		results := rec.recorded(t, 2)
+		if len(results["a"]) != 1 || results["a"][0] != nil {
+			t.Errorf("Expected the cancelled call to be recorded without an error, got %v", results["a"])
+		}
+		if len(results["b"]) != 1 || results["b"][0] != nil {
+			t.Errorf("Expected the call to be recorded, got %v", results["b"])
+		}
+	})
+
+	t.Run("Single", func(t *testing.T) {
+		rec := &recorder{Selector: sel, results: map[string][]error{}}
+		opts := CallOptions{Selector: rec, HedgeDelay: 10 * time.Millisecond}
+		slow := func(ctx context.Context, node string, req Request, rsp interface{}, opts CallOptions) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}
+		if err := HedgeCall(context.TODO(), r, &testResponse{}, opts, nodes("a"), slow); err != nil {
+			t.Fatalf("Error making the hedged call: %v", err)
+		}
+
+		// the node selected again to hedge the call is released
+		if results := rec.recorded(t, 2); len(results["a"]) != 2 {
+			t.Errorf("Expected each selection of the node to be recorded, got %v", results["a"])
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		rsp := &testResponse{}
+		if err := HedgeCall(context.TODO(), r, rsp, opts, nodes("a", "b"), call("", true)); err == nil {
+			t.Fatal("Expected the error of the call")
+		}
+	})
+
+	t.Run("Percentile", func(t *testing.T) {
+		opts := CallOptions{HedgeDelay: time.Second, HedgePercentile: 0.9}
+		key := "percentile.Test"
+		latencyMu.Lock()
+		delete(latencies, key)
+		latencyMu.Unlock()
+
+		if d := hedgeDelay(key, opts); d != time.Second {
+			t.Fatalf("Expected the hedge delay until there are enough latencies, got %v", d)
+		}
+		for i := 1; i <= 100; i++ {
+			recordLatency(key, time.Duration(i)*time.Millisecond)
+		}
+		if d := hedgeDelay(key, opts); d != 90*time.Millisecond {
+			t.Errorf("Expected the 90th percentile of the latencies, got %v", d)
+		}
+	})
+}
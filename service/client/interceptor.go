@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+)
+
+// UnaryInvoker makes a unary call. It's what the unary interceptors call to continue the call.
+type UnaryInvoker func(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error
+
+// Streamer opens a stream. It's what the stream interceptors call to continue the call.
+type Streamer func(ctx context.Context, req Request, opts ...CallOption) (Stream, error)
+
+// UnaryInterceptor intercepts a unary call. The metadata of the request can be read and set on
+// the context before calling the invoker to continue the call, or an error returned to fail it
+// without making it.
+type UnaryInterceptor func(ctx context.Context, req Request, rsp interface{}, invoker UnaryInvoker, opts ...CallOption) error
+
+// StreamInterceptor intercepts the opening of a stream. The metadata of the request can be read
+// and set on the context before calling the streamer to continue the call, and the stream it
+// returns can be wrapped.
+type StreamInterceptor func(ctx context.Context, req Request, streamer Streamer, opts ...CallOption) (Stream, error)
+
+// ChainUnaryInterceptors returns the invoker wrapped by the interceptors, which are called in
+// order with the first being the outermost
+func ChainUnaryInterceptors(invoker UnaryInvoker, interceptors ...UnaryInterceptor) UnaryInvoker {
+	for i := len(interceptors); i > 0; i-- {
+		interceptor, next := interceptors[i-1], invoker
+		invoker = func(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error {
+			return interceptor(ctx, req, rsp, next, opts...)
+		}
+	}
+	return invoker
+}
+
+// ChainStreamInterceptors returns the streamer wrapped by the interceptors, which are called in
+// order with the first being the outermost
+func ChainStreamInterceptors(streamer Streamer, interceptors ...StreamInterceptor) Streamer {
+	for i := len(interceptors); i > 0; i-- {
+		interceptor, next := interceptors[i-1], streamer
+		streamer = func(ctx context.Context, req Request, opts ...CallOption) (Stream, error) {
+			return interceptor(ctx, req, next, opts...)
+		}
+	}
+	return streamer
+}
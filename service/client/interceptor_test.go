@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	r := &testRequest{service: "test", method: "Test.Call"}
+
+	var calls []string
+	unary := func(name string) UnaryInterceptor {
+		return func(ctx context.Context, req Request, rsp interface{}, invoke UnaryInvoker, opts ...CallOption) error {
+			calls = append(calls, name)
+			return invoke(ctx, req, rsp, opts...)
+		}
+	}
+	stream := func(name string) StreamInterceptor {
+		return func(ctx context.Context, req Request, streamer Streamer, opts ...CallOption) (Stream, error) {
+			calls = append(calls, name)
+			return streamer(ctx, req, opts...)
+		}
+	}
+
+	t.Run("Unary", func(t *testing.T) {
+		calls = nil
+		invoke := ChainUnaryInterceptors(func(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error {
+			calls = append(calls, "invoke")
+			return nil
+		}, unary("first"), unary("second"))
+		if err := invoke(context.TODO(), r, nil); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(calls) != "[first second invoke]" {
+			t.Errorf("Expected the interceptors to be called in order, got %v", calls)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		calls = nil
+		streamer := ChainStreamInterceptors(func(ctx context.Context, req Request, opts ...CallOption) (Stream, error) {
+			calls = append(calls, "stream")
+			return nil, nil
+		}, stream("first"), stream("second"))
+		if _, err := streamer(context.TODO(), r); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(calls) != "[first second stream]" {
+			t.Errorf("Expected the interceptors to be called in order, got %v", calls)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		calls = nil
+		invoke := ChainUnaryInterceptors(func(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error {
+			calls = append(calls, "invoke")
+			return nil
+		}, func(ctx context.Context, req Request, rsp interface{}, invoke UnaryInvoker, opts ...CallOption) error {
+			return errors.New("invalid request")
+		}, unary("second"))
+		if err := invoke(context.TODO(), r, nil); err == nil {
+			t.Fatal("Expected the error of the interceptor")
+		}
+		if len(calls) > 0 {
+			t.Errorf("Expected the call to stop at the interceptor, got %v", calls)
+		}
+	})
+}
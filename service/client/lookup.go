@@ -19,21 +19,80 @@ package client
 import (
 	"context"
 	"sort"
+	"strconv"
 
+	"github.com/micro/micro/v3/service/context/metadata"
 	"github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/service/router"
+	"github.com/micro/micro/v3/util/selector"
 )
 
 // LookupFunc is used to lookup routes for a service
-type LookupFunc func(context.Context, Request, CallOptions) ([]string, error)
+type LookupFunc func(context.Context, Request, CallOptions) ([]router.Route, error)
 
 // LookupRoute for a request using the router and then choose one using the selector
-func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]string, error) {
+func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]router.Route, error) {
 	// check to see if an address was provided as a call option
 	if len(opts.Address) > 0 {
-		return opts.Address, nil
+		routes := make([]router.Route, len(opts.Address))
+		for i, addr := range opts.Address {
+			routes[i] = router.Route{Service: req.Service(), Address: addr}
+		}
+		return routes, nil
 	}
 
+	return lookupRoutes(req, opts)
+}
+
+// Addresses returns the addresses of the routes, which are selected from
+func Addresses(routes []router.Route) []string {
+	addrs := make([]string, len(routes))
+	for i, route := range routes {
+		addrs[i] = route.Address
+	}
+	return addrs
+}
+
+// SelectKeyHeader is the metadata header of the key the route is selected by, e.g. the id of the
+// user a request is for, so the requests with the same key are sent to the same node by the
+// consistent selector
+const SelectKeyHeader = "Micro-Select-Key"
+
+// SelectOptions returns the options to select one of the routes looked up for a request with. The
+// key is read from the metadata, the weights of the routes are read from the weight in the
+// metadata of their nodes for the weighted selector, then the select options of the call are
+// applied.
+func SelectOptions(ctx context.Context, routes []router.Route, opts CallOptions) []selector.SelectOption {
+	var sopts []selector.SelectOption
+	if key, ok := metadata.Get(ctx, SelectKeyHeader); ok && len(key) > 0 {
+		sopts = append(sopts, selector.Key(key))
+	}
+	if weights := routeWeights(routes); weights != nil {
+		sopts = append(sopts, selector.Weights(weights))
+	}
+	return append(sopts, opts.SelectOptions...)
+}
+
+// routeWeights returns the weights of the routes with one, nil if none of them have one
+func routeWeights(routes []router.Route) map[string]int {
+	var weights map[string]int
+	for _, route := range routes {
+		weight, err := strconv.Atoi(route.Metadata[registry.WeightKey])
+		if err != nil {
+			continue
+		}
+		if weights == nil {
+			weights = make(map[string]int, len(routes))
+		}
+		weights[route.Address] = weight
+	}
+	return weights
+}
+
+// lookupRoutes returns the routes for the request, preferring the local routes and sorted by
+// lowest metric first
+func lookupRoutes(req Request, opts CallOptions) ([]router.Route, error) {
 	// construct the router query
 	query := []router.LookupOption{}
 
@@ -43,6 +102,11 @@ func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]string,
 		query = append(query, router.LookupNetwork(opts.Network))
 	}
 
+	// only lookup the routes to the version of the service if one was requested
+	if len(opts.Version) > 0 {
+		query = append(query, router.LookupVersion(opts.Version))
+	}
+
 	// lookup the routes which can be used to execute the request
 	routes, err := opts.Router.Lookup(req.Service(), query...)
 	if err == router.ErrRouteNotFound {
@@ -51,16 +115,39 @@ func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]string,
 		return nil, errors.InternalServerError("go.micro.client", "error getting next %s node: %s", req.Service(), err.Error())
 	}
 
+	// prefer the routes in the zone, then the region, failing over to the others
+	routes = local(routes, opts.Zone, opts.Region)
+
 	// sort by lowest metric first
 	sort.Slice(routes, func(i, j int) bool {
 		return routes[i].Metric < routes[j].Metric
 	})
 
-	var addrs []string
+	return routes, nil
+}
+
+// local returns the routes to the nodes in the zone if there are any, otherwise the routes to
+// the nodes in the region if there are any, otherwise all the routes
+func local(routes []router.Route, zone, region string) []router.Route {
+	if len(zone) == 0 && len(region) == 0 {
+		return routes
+	}
 
+	var inZone, inRegion []router.Route
 	for _, route := range routes {
-		addrs = append(addrs, route.Address)
+		if len(zone) > 0 && route.Metadata[registry.ZoneKey] == zone {
+			inZone = append(inZone, route)
+		}
+		if len(region) > 0 && route.Metadata[registry.RegionKey] == region {
+			inRegion = append(inRegion, route)
+		}
 	}
 
-	return addrs, nil
+	if len(inZone) > 0 {
+		return inZone
+	}
+	if len(inRegion) > 0 {
+		return inRegion
+	}
+	return routes
 }
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/micro/micro/v3/service/context/metadata"
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/router"
+	"github.com/micro/micro/v3/util/selector"
+)
+
+type testRouter struct {
+	router.Router
+	routes []router.Route
+}
+
+func (t *testRouter) Lookup(service string, opts ...router.LookupOption) ([]router.Route, error) {
+	return t.routes, nil
+}
+
+func TestLookupRouteLocality(t *testing.T) {
+	route := func(address, zone, region string) router.Route {
+		return router.Route{
+			Service: "foo",
+			Address: address,
+			Metadata: map[string]string{
+				registry.ZoneKey:   zone,
+				registry.RegionKey: region,
+			},
+		}
+	}
+	r := &testRouter{routes: []router.Route{
+		route("10.0.0.1:8080", "eu-west-1a", "eu-west-1"),
+		route("10.0.0.2:8080", "eu-west-1b", "eu-west-1"),
+		route("10.0.1.1:8080", "us-east-1a", "us-east-1"),
+		{Service: "foo", Address: "10.0.2.1:8080"},
+	}}
+
+	tests := []struct {
+		name   string
+		zone   string
+		region string
+		addrs  []string
+	}{
+		{"NoLocality", "", "", []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.1.1:8080", "10.0.2.1:8080"}},
+		{"Zone", "eu-west-1a", "eu-west-1", []string{"10.0.0.1:8080"}},
+		{"Region", "eu-west-1c", "eu-west-1", []string{"10.0.0.1:8080", "10.0.0.2:8080"}},
+		{"RegionOnly", "", "us-east-1", []string{"10.0.1.1:8080"}},
+		{"Failover", "ap-south-1a", "ap-south-1", []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.1.1:8080", "10.0.2.1:8080"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			routes, err := LookupRoute(context.TODO(), &testRequest{service: "foo"}, CallOptions{
+				Router: r,
+				Zone:   tc.zone,
+				Region: tc.region,
+			})
+			if err != nil {
+				t.Fatalf("Error looking up routes: %v", err)
+			}
+			addrs := Addresses(routes)
+			sort.Strings(addrs)
+			if !reflect.DeepEqual(addrs, tc.addrs) {
+				t.Errorf("Expected %v, got %v", tc.addrs, addrs)
+			}
+		})
+	}
+}
+
+func TestSelectOptions(t *testing.T) {
+	r := &testRouter{routes: []router.Route{
+		{Service: "foo", Address: "10.0.0.1:8080", Metadata: map[string]string{registry.WeightKey: "3"}},
+		{Service: "foo", Address: "10.0.0.2:8080", Metadata: map[string]string{registry.WeightKey: "heavy"}},
+		{Service: "foo", Address: "10.0.0.3:8080"},
+	}}
+
+	if opts := SelectOptions(context.TODO(), r.routes[2:], CallOptions{}); len(opts) != 0 {
+		t.Fatalf("Expected no select options for the routes without a weight, got %v", len(opts))
+	}
+
+	// the weights are read from the routes looked up
+	opts := SelectOptions(context.TODO(), r.routes, CallOptions{})
+	weights := selector.NewSelectOptions(opts...).Weights
+	if expected := map[string]int{"10.0.0.1:8080": 3}; !reflect.DeepEqual(weights, expected) {
+		t.Errorf("Expected weights %v, got %v", expected, weights)
+	}
+
+	// the key is read from the metadata, with the select options of the call taking precedence
+	ctx := metadata.Set(context.TODO(), SelectKeyHeader, "user-1")
+	if key := selector.NewSelectOptions(SelectOptions(ctx, r.routes, CallOptions{})...).Key; key != "user-1" {
+		t.Errorf("Expected the key from the metadata, got %v", key)
+	}
+	if key := selector.NewSelectOptions(SelectOptions(ctx, r.routes, CallOptions{
+		SelectOptions: []selector.SelectOption{selector.Key("user-2")},
+	})...).Key; key != "user-2" {
+		t.Errorf("Expected the key from the call options, got %v", key)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/util/codec"
 	raw "github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/micro/micro/v3/util/codec/grpc"
 	"github.com/micro/micro/v3/util/codec/json"
 	"github.com/micro/micro/v3/util/codec/jsonrpc"
@@ -73,6 +74,7 @@ var (
 		"application/grpc+json":    grpc.NewCodec,
 		"application/grpc+proto":   grpc.NewCodec,
 		"application/protobuf":     proto.NewCodec,
+		"application/cbor":         cbor.NewCodec,
 		"application/json":         json.NewCodec,
 		"application/json-rpc":     jsonrpc.NewCodec,
 		"application/proto-rpc":    protorpc.NewCodec,
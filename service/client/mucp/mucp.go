@@ -19,6 +19,7 @@ package mucp
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -33,6 +34,7 @@ import (
 	"github.com/micro/micro/v3/util/codec"
 	raw "github.com/micro/micro/v3/util/codec/bytes"
 	"github.com/micro/micro/v3/util/pool"
+	"github.com/micro/micro/v3/util/selector"
 )
 
 type rpcClient struct {
@@ -79,6 +81,14 @@ func (r *rpcClient) newCodec(contentType string) (codec.NewCodec, error) {
 	return nil, fmt.Errorf("Unsupported Content-Type: %s", contentType)
 }
 
+// tlsConfig returns the tls config of the call, or of the service it's to, if any
+func (r *rpcClient) tlsConfig(service string, opts client.CallOptions) *tls.Config {
+	if opts.TLSConfig != nil {
+		return opts.TLSConfig
+	}
+	return r.opts.TLSConfigs[service]
+}
+
 func (r *rpcClient) call(ctx context.Context, addr string, req client.Request, resp interface{}, opts client.CallOptions) error {
 	msg := &transport.Message{
 		Header: make(map[string]string),
@@ -116,6 +126,10 @@ func (r *rpcClient) call(ctx context.Context, addr string, req client.Request, r
 		dOpts = append(dOpts, transport.WithTimeout(opts.DialTimeout))
 	}
 
+	if config := r.tlsConfig(req.Service(), opts); config != nil {
+		dOpts = append(dOpts, transport.WithTLSConfig(config))
+	}
+
 	c, err := r.pool.Get(addr, dOpts...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", "connection error: %v", err)
@@ -223,6 +237,10 @@ func (r *rpcClient) stream(ctx context.Context, addr string, req client.Request,
 		dOpts = append(dOpts, transport.WithTimeout(opts.DialTimeout))
 	}
 
+	if config := r.tlsConfig(req.Service(), opts); config != nil {
+		dOpts = append(dOpts, transport.WithTLSConfig(config))
+	}
+
 	c, err := r.opts.Transport.Dial(addr, dOpts...)
 	if err != nil {
 		return nil, errors.InternalServerError("go.micro.client", "connection error: %v", err)
@@ -319,6 +337,11 @@ func (r *rpcClient) Options() client.Options {
 }
 
 func (r *rpcClient) Call(ctx context.Context, request client.Request, response interface{}, opts ...client.CallOption) error {
+	return client.ChainUnaryInterceptors(r.invoke, r.opts.UnaryInterceptors...)(ctx, request, response, opts...)
+}
+
+// invoke makes the call once it's passed through the interceptors
+func (r *rpcClient) invoke(ctx context.Context, request client.Request, response interface{}, opts ...client.CallOption) error {
 	// make a copy of call opts
 	callOpts := r.opts.CallOptions
 	for _, opt := range opts {
@@ -376,7 +399,7 @@ func (r *rpcClient) Call(ctx context.Context, request client.Request, response i
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(ctx, routes, callOpts)...)
 	if err != nil {
 		return err
 	}
@@ -394,14 +417,29 @@ func (r *rpcClient) Call(ctx context.Context, request client.Request, response i
 			time.Sleep(t)
 		}
 
+		// limit the attempt to the per try timeout
+		tctx, topts := ctx, callOpts
+		if callOpts.PerTryTimeout > 0 && callOpts.PerTryTimeout < callOpts.RequestTimeout {
+			var cancel context.CancelFunc
+			tctx, cancel = context.WithTimeout(ctx, callOpts.PerTryTimeout)
+			defer cancel()
+			topts.RequestTimeout = callOpts.PerTryTimeout
+		}
+
+		// hedge the call to another node if the first is slow to respond
+		if callOpts.HedgeDelay > 0 || callOpts.HedgePercentile > 0 {
+			return client.HedgeCall(tctx, request, response, topts, next, rcall)
+		}
+
 		// get the next node
 		node := next()
+		start := time.Now()
 
 		// make the call
-		err = rcall(ctx, node, request, response, callOpts)
+		err = rcall(tctx, node, request, response, topts)
 
 		// record the result of the call to inform future routing decisions
-		r.opts.Selector.Record(node, err)
+		callOpts.Selector.Record(node, err, selector.Started(start))
 
 		return err
 	}
@@ -448,6 +486,11 @@ func (r *rpcClient) Call(ctx context.Context, request client.Request, response i
 }
 
 func (r *rpcClient) Stream(ctx context.Context, request client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return client.ChainStreamInterceptors(r.openStream, r.opts.StreamInterceptors...)(ctx, request, opts...)
+}
+
+// openStream opens the stream once it's passed through the interceptors
+func (r *rpcClient) openStream(ctx context.Context, request client.Request, opts ...client.CallOption) (client.Stream, error) {
 	// make a copy of call opts
 	callOpts := r.opts.CallOptions
 	for _, opt := range opts {
@@ -484,7 +527,7 @@ func (r *rpcClient) Stream(ctx context.Context, request client.Request, opts ...
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(ctx, routes, callOpts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -503,12 +546,13 @@ func (r *rpcClient) Stream(ctx context.Context, request client.Request, opts ...
 
 		// get the next node
 		node := next()
+		start := time.Now()
 
 		// perform the call
 		stream, err := r.stream(ctx, node, request, callOpts)
 
 		// record the result of the call to inform future routing decisions
-		r.opts.Selector.Record(node, err)
+		callOpts.Selector.Record(node, err, selector.Started(start))
 
 		return stream, err
 	}
@@ -541,7 +585,7 @@ func (r *rpcClient) Stream(ctx context.Context, request client.Request, opts ...
 		case rsp := <-ch:
 			// if the call succeeded lets bail early
 			if rsp.err == nil {
-				return rsp.stream, nil
+				return client.BufferStream(rsp.stream, callOpts), nil
 			}
 
 			retry, rerr := callOpts.Retry(ctx, request, i, rsp.err)
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/micro/micro/v3/service/client"
 	"github.com/micro/micro/v3/service/errors"
@@ -120,6 +121,43 @@ func TestCallRetry(t *testing.T) {
 	}
 }
 
+func TestCallRetryPolicy(t *testing.T) {
+	var called int
+
+	// the first attempt hangs until it times out
+	wrap := func(cf client.CallFunc) client.CallFunc {
+		return func(ctx context.Context, node string, req client.Request, rsp interface{}, opts client.CallOptions) error {
+			called++
+			if opts.RequestTimeout != 50*time.Millisecond {
+				return errors.BadRequest("test.error", "unexpected request timeout %v", opts.RequestTimeout)
+			}
+			if called == 1 {
+				<-ctx.Done()
+				return errors.Timeout("test.error", "%v", ctx.Err())
+			}
+			return nil
+		}
+	}
+
+	c := NewClient(
+		client.Router(newTestRouter()),
+		client.WrapCall(wrap),
+		client.DefaultRetryPolicy(client.RetryPolicy{
+			MaxAttempts:   3,
+			Backoff:       time.Millisecond,
+			PerTryTimeout: 50 * time.Millisecond,
+		}),
+	)
+
+	req := c.NewRequest("test.service", "Test.Endpoint", nil)
+	if err := c.Call(context.Background(), req, nil, client.WithAddress("10.1.10.1")); err != nil {
+		t.Fatal("call with retry policy error", err)
+	}
+	if called != 2 {
+		t.Fatalf("Expected the call to be retried once after timing out, got %v calls", called)
+	}
+}
+
 func TestCallWrapper(t *testing.T) {
 	var called bool
 	id := "test.1"
@@ -146,6 +146,35 @@ func (r *rpcStream) Error() error {
 	return r.err
 }
 
+// CloseSend sends the end of the stream, the responses can still be received
+func (r *rpcStream) CloseSend() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.isClosed() {
+		return errShutdown
+	}
+	if !r.sendEOS {
+		return nil
+	}
+
+	// the end of stream is only sent once
+	r.sendEOS = false
+	if err := r.codec.Write(&codec.Message{
+		Id:       r.id,
+		Target:   r.request.Service(),
+		Method:   r.request.Method(),
+		Endpoint: r.request.Endpoint(),
+		Type:     codec.Error,
+		Error:    lastStreamResponseError,
+	}, nil); err != nil {
+		r.err = err
+		return err
+	}
+
+	return nil
+}
+
 func (r *rpcStream) Close() error {
 	r.Lock()
 
@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
 	"github.com/micro/micro/v3/service/broker"
@@ -55,6 +56,15 @@ type Options struct {
 	// Middleware for client
 	Wrappers []Wrapper
 
+	// UnaryInterceptors intercept the unary calls, in order
+	UnaryInterceptors []UnaryInterceptor
+	// StreamInterceptors intercept the streams opened, in order
+	StreamInterceptors []StreamInterceptor
+
+	// TLSConfigs secure the connections to the services, by
+	// the name of the service, over the default of the client
+	TLSConfigs map[string]*tls.Config
+
 	// Default Call Options
 	CallOptions CallOptions
 
@@ -76,6 +86,12 @@ type CallOptions struct {
 	Retry RetryFunc
 	// Request/Response timeout
 	RequestTimeout time.Duration
+	// Timeout of each attempt of the request, if shorter than the request timeout
+	PerTryTimeout time.Duration
+	// Time to wait for a response before hedging the request to another node
+	HedgeDelay time.Duration
+	// Percentile of the latencies of the endpoint to wait for before hedging the request
+	HedgePercentile float64
 	// Router to use for this call
 	Router router.Router
 	// Selector to use for the call
@@ -84,10 +100,26 @@ type CallOptions struct {
 	SelectOptions []selector.SelectOption
 	// Stream timeout for the stream
 	StreamTimeout time.Duration
+	// SendBufferSize is the number of messages sent on a stream which are queued to be written
+	// rather than waiting for the peer
+	SendBufferSize int
+	// RecvBufferSize is the number of messages received on a stream ahead of the calls to Recv
+	RecvBufferSize int
+	// BufferPolicy is what the stream does when one of its buffers is full
+	BufferPolicy BufferPolicy
 	// Use the auth token as the authorization header
 	AuthToken bool
+	// TLSConfig secures the connection for the call, over
+	// the config of the target service
+	TLSConfig *tls.Config
 	// Network to lookup the route within
 	Network string
+	// Zone the routes are preferred in
+	Zone string
+	// Region the routes are preferred in when there are none in the zone
+	Region string
+	// Version of the service to call
+	Version string
 
 	// Middleware for low level call func
 	CallWrappers []CallWrapper
@@ -223,6 +255,22 @@ func Wrap(w Wrapper) Option {
 	}
 }
 
+// InterceptUnary adds interceptors to the chain of the unary calls. They're called in the order
+// they're added, inside of the client wrappers.
+func InterceptUnary(i ...UnaryInterceptor) Option {
+	return func(o *Options) {
+		o.UnaryInterceptors = append(o.UnaryInterceptors, i...)
+	}
+}
+
+// InterceptStream adds interceptors to the chain of the streams opened. They're called in the
+// order they're added, inside of the client wrappers.
+func InterceptStream(i ...StreamInterceptor) Option {
+	return func(o *Options) {
+		o.StreamInterceptors = append(o.StreamInterceptors, i...)
+	}
+}
+
 // Adds a Wrapper to the list of CallFunc wrappers
 func WrapCall(cw ...CallWrapper) Option {
 	return func(o *Options) {
@@ -245,6 +293,17 @@ func Lookup(l LookupFunc) Option {
 	}
 }
 
+// TargetTLS secures the connections to the service by the tls config, e.g. for mutual tls with
+// the services of the mesh while other calls verify the server by the public CAs
+func TargetTLS(service string, c *tls.Config) Option {
+	return func(o *Options) {
+		if o.TLSConfigs == nil {
+			o.TLSConfigs = make(map[string]*tls.Config)
+		}
+		o.TLSConfigs[service] = c
+	}
+}
+
 // Number of retries when making the request.
 // Should this be a Call Option?
 func Retries(i int) Option {
@@ -260,6 +319,14 @@ func Retry(fn RetryFunc) Option {
 	}
 }
 
+// DefaultRetryPolicy sets the retries, retry and backoff functions and per try timeout of the
+// calls to those of the retry policy
+func DefaultRetryPolicy(p RetryPolicy) Option {
+	return func(o *Options) {
+		p.apply(&o.CallOptions)
+	}
+}
+
 // The request timeout.
 // Should this be a Call Option?
 func RequestTimeout(d time.Duration) Option {
@@ -282,6 +349,22 @@ func DialTimeout(d time.Duration) Option {
 	}
 }
 
+// Zone sets the zone of the client, calls are routed to the nodes in the zone when there are
+// any, to cut the traffic across zones
+func Zone(z string) Option {
+	return func(o *Options) {
+		o.CallOptions.Zone = z
+	}
+}
+
+// Region sets the region of the client, calls are routed to the nodes in the region when there
+// are none in the zone
+func Region(r string) Option {
+	return func(o *Options) {
+		o.CallOptions.Region = r
+	}
+}
+
 // Call Options
 
 // WithExchange sets the exchange to route a message through
@@ -336,6 +419,54 @@ func WithRetries(i int) CallOption {
 	}
 }
 
+// WithRetryPolicy is a CallOption which overrides the retries, retry and backoff functions and
+// per try timeout of the client with those of the retry policy
+func WithRetryPolicy(p RetryPolicy) CallOption {
+	return p.apply
+}
+
+// WithHedge is a CallOption which hedges the request with a request to another node if the first
+// hasn't responded within the delay, using the first response. Only idempotent requests should be
+// hedged.
+func WithHedge(d time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.HedgeDelay = d
+	}
+}
+
+// WithHedgePercentile is a CallOption which hedges the request with a request to another node if
+// the first hasn't responded within the percentile of the latencies of the endpoint, e.g. 0.95.
+// The hedge delay is used until enough latencies have been recorded.
+func WithHedgePercentile(p float64) CallOption {
+	return func(o *CallOptions) {
+		o.HedgePercentile = p
+	}
+}
+
+// WithSendBuffer is a CallOption which queues up to n messages sent on the stream to be written
+// rather than waiting for the peer
+func WithSendBuffer(n int) CallOption {
+	return func(o *CallOptions) {
+		o.SendBufferSize = n
+	}
+}
+
+// WithRecvBuffer is a CallOption which receives up to n messages on the stream ahead of the calls
+// to Recv
+func WithRecvBuffer(n int) CallOption {
+	return func(o *CallOptions) {
+		o.RecvBufferSize = n
+	}
+}
+
+// WithBufferPolicy is a CallOption which sets what the stream does when one of its buffers is full,
+// blocking by default
+func WithBufferPolicy(p BufferPolicy) CallOption {
+	return func(o *CallOptions) {
+		o.BufferPolicy = p
+	}
+}
+
 // WithRequestTimeout is a CallOption which overrides that which
 // set in Options.CallOptions
 func WithRequestTimeout(d time.Duration) CallOption {
@@ -374,6 +505,38 @@ func WithNetwork(n string) CallOption {
 	}
 }
 
+// WithZone is a CallOption which sets the zone the routes are preferred in
+func WithZone(z string) CallOption {
+	return func(o *CallOptions) {
+		o.Zone = z
+	}
+}
+
+// WithRegion is a CallOption which sets the region the routes are preferred in when there are
+// none in the zone
+func WithRegion(r string) CallOption {
+	return func(o *CallOptions) {
+		o.Region = r
+	}
+}
+
+// WithVersion is a CallOption which routes the call to the nodes of the version of the service,
+// so multiple versions can run concurrently
+func WithVersion(v string) CallOption {
+	return func(o *CallOptions) {
+		o.Version = v
+	}
+}
+
+// WithTLSConfig secures the connection for the call by the tls config, e.g. to present a client
+// certificate or verify the server by a CA bundle of its own. Connections are reused by calls with
+// an equal config, see tls.ConfigKey, so the pool of root CAs should be shared between them.
+func WithTLSConfig(c *tls.Config) CallOption {
+	return func(o *CallOptions) {
+		o.TLSConfig = c
+	}
+}
+
 // WithRouter sets the router to use for this call
 func WithRouter(r router.Router) CallOption {
 	return func(o *CallOptions) {
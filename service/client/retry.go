@@ -18,7 +18,9 @@ package client
 
 import (
 	"context"
+	"math/rand"
 	"strings"
+	"time"
 
 	"github.com/micro/micro/v3/service/errors"
 )
@@ -73,3 +75,78 @@ func RetryOnConnectFailure(ctx context.Context, req Request, retryCount int, err
 func RetryNever(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
 	return false, nil
 }
+
+// DefaultRetryCodes are the error codes a call is retried on by a retry policy which doesn't set
+// any: timeouts, internal server errors and unavailable services
+var DefaultRetryCodes = []int32{408, 500, 502, 503, 504}
+
+// RetryPolicy configures how a call is retried. A call is retried on the error codes of the policy
+// and on connection failures, backing off exponentially between the attempts.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the call is made, including the first attempt
+	MaxAttempts int
+	// Codes are the error codes the call is retried on, by default the DefaultRetryCodes
+	Codes []int32
+	// Backoff is the time waited before the first retry, doubling each retry
+	Backoff time.Duration
+	// MaxBackoff is the longest time waited before a retry, if set
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff which is randomised, so the retries of many calls
+	// are spread out
+	Jitter float64
+	// PerTryTimeout is the timeout of each attempt, if shorter than the request timeout
+	PerTryTimeout time.Duration
+}
+
+// Retry returns true if the call should be retried after the error
+func (p RetryPolicy) Retry(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	if ok, _ := RetryOnConnectFailure(ctx, req, retryCount, err); ok {
+		return true, nil
+	}
+
+	codes := p.Codes
+	if len(codes) == 0 {
+		codes = DefaultRetryCodes
+	}
+	code := errors.FromError(err).Code
+	for _, c := range codes {
+		if c == code {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Wait returns the time to wait before the attempt, 0 for the first attempt then the backoff
+// doubling each retry, with jitter
+func (p RetryPolicy) Wait(ctx context.Context, req Request, attempts int) (time.Duration, error) {
+	if attempts == 0 || p.Backoff <= 0 {
+		return 0, nil
+	}
+
+	d := p.Backoff
+	for i := 1; i < attempts && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d, nil
+}
+
+// apply the policy to the call options
+func (p RetryPolicy) apply(o *CallOptions) {
+	o.Retries = p.MaxAttempts - 1
+	if o.Retries < 0 {
+		o.Retries = 0
+	}
+	o.Retry = p.Retry
+	o.Backoff = p.Wait
+	o.PerTryTimeout = p.PerTryTimeout
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	merrors "github.com/micro/micro/v3/service/errors"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	r := &testRequest{service: "test", method: "test"}
+
+	t.Run("Retry", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			codes []int32
+			err   error
+			retry bool
+		}{
+			{"NoError", nil, nil, false},
+			{"Timeout", nil, merrors.Timeout("test", "timeout"), true},
+			{"NotFound", nil, merrors.NotFound("test", "not found"), false},
+			{"TooManyRequests", nil, merrors.TooManyRequests("test", "limit reached"), false},
+			{"ConnectFailure", nil, errors.New("connection refused"), true},
+			{"Codes", []int32{404}, merrors.NotFound("test", "not found"), true},
+			{"NotInCodes", []int32{404}, merrors.Timeout("test", "timeout"), false},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				retry, err := RetryPolicy{Codes: tc.codes}.Retry(context.TODO(), r, 0, tc.err)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if retry != tc.retry {
+					t.Errorf("Expected retry %v, got %v", tc.retry, retry)
+				}
+			})
+		}
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		p := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+		results := []time.Duration{
+			0,
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		}
+		for i, expected := range results {
+			if d, _ := p.Wait(context.TODO(), r, i); d != expected {
+				t.Errorf("Expected a backoff of %v before attempt %v, got %v", expected, i, d)
+			}
+		}
+
+		// the jitter shortens the backoff by up to its fraction
+		p.Jitter = 0.5
+		for i := 0; i < 100; i++ {
+			if d, _ := p.Wait(context.TODO(), r, 2); d > 200*time.Millisecond || d < 100*time.Millisecond {
+				t.Fatalf("Expected a backoff between 100ms and 200ms, got %v", d)
+			}
+		}
+	})
+
+	t.Run("CallOptions", func(t *testing.T) {
+		var opts CallOptions
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, PerTryTimeout: time.Second})(&opts)
+		if opts.Retries != 2 || opts.PerTryTimeout != time.Second || opts.Retry == nil || opts.Backoff == nil {
+			t.Errorf("Expected the call options to be set by the retry policy, got %+v", opts)
+		}
+	})
+}
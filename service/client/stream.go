@@ -0,0 +1,226 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrBufferFull is returned by a stream with the ErrorWhenFull buffer policy once one of its
+	// buffers is full
+	ErrBufferFull = errors.New("stream buffer is full")
+	// ErrStreamClosed is returned when sending on a stream which was closed
+	ErrStreamClosed = errors.New("stream is closed")
+)
+
+// BufferPolicy is what a stream does when one of its buffers is full because the peer or the
+// receiver is too slow
+type BufferPolicy int
+
+const (
+	// BlockWhenFull blocks Send until there's room in the send buffer and stops receiving until
+	// Recv is called, pushing back on the peer
+	BlockWhenFull BufferPolicy = iota
+	// ErrorWhenFull returns ErrBufferFull from Send when the send buffer is full, and fails the
+	// stream with it when the receive buffer is full
+	ErrorWhenFull
+)
+
+// BufferStream returns the stream buffered by the send and receive buffer sizes of the call
+// options, or the stream itself if neither is set
+func BufferStream(s Stream, opts CallOptions) Stream {
+	if opts.SendBufferSize <= 0 && opts.RecvBufferSize <= 0 {
+		return s
+	}
+
+	b := &bufferedStream{
+		Stream: s,
+		policy: opts.BufferPolicy,
+		closed: make(chan struct{}),
+	}
+	if opts.SendBufferSize > 0 {
+		b.send = make(chan interface{}, opts.SendBufferSize)
+		b.sent = make(chan struct{})
+		go b.sendLoop()
+	}
+	if opts.RecvBufferSize > 0 {
+		b.recv = make(chan interface{}, opts.RecvBufferSize)
+	}
+	return b
+}
+
+// bufferedStream queues the messages sent to be written by a goroutine, and reads the messages
+// received ahead of the calls to Recv
+type bufferedStream struct {
+	Stream
+	policy BufferPolicy
+
+	// sendMu guards closing the send buffer while messages are sent
+	sendMu     sync.RWMutex
+	sendClosed bool
+	send       chan interface{}
+	// sent is closed once the send buffer has been written
+	sent chan struct{}
+
+	recvOnce sync.Once
+	recv     chan interface{}
+	// recvErr is the error which stopped the receiving, returned once the buffer is read
+	recvErr error
+
+	// closed is closed when the stream is closed
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	sync.RWMutex
+	err error
+}
+
+func (b *bufferedStream) setError(err error) {
+	b.Lock()
+	if b.err == nil {
+		b.err = err
+	}
+	b.Unlock()
+}
+
+func (b *bufferedStream) Error() error {
+	b.RLock()
+	err := b.err
+	b.RUnlock()
+	if err != nil {
+		return err
+	}
+	return b.Stream.Error()
+}
+
+func (b *bufferedStream) Send(msg interface{}) error {
+	if b.send == nil {
+		return b.Stream.Send(msg)
+	}
+
+	b.sendMu.RLock()
+	defer b.sendMu.RUnlock()
+
+	if b.sendClosed {
+		return ErrStreamClosed
+	}
+	b.RLock()
+	err := b.err
+	b.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// the message is written by the send loop, so it mustn't be changed once it's sent
+	if b.policy == ErrorWhenFull {
+		select {
+		case b.send <- msg:
+			return nil
+		default:
+			return ErrBufferFull
+		}
+	}
+
+	select {
+	case b.send <- msg:
+		return nil
+	case <-b.Context().Done():
+		return b.Context().Err()
+	}
+}
+
+// sendLoop writes the messages in the send buffer until it's closed
+func (b *bufferedStream) sendLoop() {
+	defer close(b.sent)
+
+	for msg := range b.send {
+		// the messages queued after an error are dropped
+		if b.Error() != nil {
+			continue
+		}
+		if err := b.Stream.Send(msg); err != nil {
+			b.setError(err)
+		}
+	}
+}
+
+// flush closes the send buffer and waits for the messages queued to be written
+func (b *bufferedStream) flush() {
+	if b.send == nil {
+		return
+	}
+
+	b.sendMu.Lock()
+	if !b.sendClosed {
+		b.sendClosed = true
+		close(b.send)
+	}
+	b.sendMu.Unlock()
+
+	<-b.sent
+}
+
+func (b *bufferedStream) Recv(msg interface{}) error {
+	if b.recv == nil {
+		return b.Stream.Recv(msg)
+	}
+
+	// start reading ahead into new messages of the type received
+	b.recvOnce.Do(func() {
+		go b.recvLoop(msg)
+	})
+
+	rsp, ok := <-b.recv
+	if !ok {
+		return b.recvErr
+	}
+	copyResponse(msg, rsp)
+	return nil
+}
+
+// recvLoop reads the messages into the receive buffer until the stream fails or is closed
+func (b *bufferedStream) recvLoop(msg interface{}) {
+	defer close(b.recv)
+
+	for {
+		rsp := newResponse(msg)
+		if err := b.Stream.Recv(rsp); err != nil {
+			b.recvErr = err
+			return
+		}
+
+		if b.policy == ErrorWhenFull {
+			select {
+			case b.recv <- rsp:
+				continue
+			default:
+				b.recvErr = ErrBufferFull
+				b.setError(ErrBufferFull)
+				return
+			}
+		}
+
+		select {
+		case b.recv <- rsp:
+		case <-b.closed:
+			b.recvErr = io.EOF
+			return
+		}
+	}
+}
+
+// CloseSend writes the messages queued then closes the sending side of the stream
+func (b *bufferedStream) CloseSend() error {
+	b.flush()
+	return b.Stream.CloseSend()
+}
+
+// Close writes the messages queued then closes the stream
+func (b *bufferedStream) Close() error {
+	b.flush()
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+	return b.Stream.Close()
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStream is a stream to a peer which reads the messages sent once it's released and sends the
+// messages queued to be received
+type testStream struct {
+	ctx     context.Context
+	release chan struct{}
+	started chan struct{}
+
+	sync.Mutex
+	sent       []int
+	recv       []int
+	closedSend bool
+}
+
+func newTestStream(ctx context.Context, recv ...int) *testStream {
+	return &testStream{
+		ctx:     ctx,
+		release: make(chan struct{}),
+		started: make(chan struct{}, 100),
+		recv:    recv,
+	}
+}
+
+func (t *testStream) Context() context.Context { return t.ctx }
+func (t *testStream) Request() Request         { return nil }
+func (t *testStream) Response() Response       { return nil }
+func (t *testStream) Error() error             { return nil }
+func (t *testStream) Close() error             { return nil }
+
+func (t *testStream) Send(msg interface{}) error {
+	t.started <- struct{}{}
+	<-t.release
+	t.Lock()
+	t.sent = append(t.sent, *msg.(*int))
+	t.Unlock()
+	return nil
+}
+
+func (t *testStream) Recv(msg interface{}) error {
+	t.Lock()
+	defer t.Unlock()
+	if len(t.recv) == 0 {
+		return io.EOF
+	}
+	*msg.(*int), t.recv = t.recv[0], t.recv[1:]
+	return nil
+}
+
+func (t *testStream) CloseSend() error {
+	t.Lock()
+	t.closedSend = true
+	t.Unlock()
+	return nil
+}
+
+func TestBufferStream(t *testing.T) {
+	msg := func(i int) *int { return &i }
+
+	t.Run("Unbuffered", func(t *testing.T) {
+		s := newTestStream(context.TODO())
+		if BufferStream(s, CallOptions{}) != Stream(s) {
+			t.Fatal("Expected the stream not to be buffered")
+		}
+	})
+
+	t.Run("SendErrorWhenFull", func(t *testing.T) {
+		s := newTestStream(context.TODO())
+		b := BufferStream(s, CallOptions{SendBufferSize: 2, BufferPolicy: ErrorWhenFull})
+
+		// the first message is being written while the next two are queued
+		if err := b.Send(msg(1)); err != nil {
+			t.Fatal(err)
+		}
+		<-s.started
+		for i := 2; i <= 3; i++ {
+			if err := b.Send(msg(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := b.Send(msg(4)); err != ErrBufferFull {
+			t.Fatalf("Expected the send buffer to be full, got %v", err)
+		}
+
+		// closing the sending side writes the messages queued first
+		close(s.release)
+		if err := b.CloseSend(); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 3 || !s.closedSend {
+			t.Fatalf("Expected the messages queued to be written before closing, got %v", s.sent)
+		}
+		if err := b.Send(msg(5)); err != ErrStreamClosed {
+			t.Fatalf("Expected the stream to be closed, got %v", err)
+		}
+	})
+
+	t.Run("SendBlockWhenFull", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		s := newTestStream(ctx)
+		b := BufferStream(s, CallOptions{SendBufferSize: 1})
+
+		b.Send(msg(1))
+		<-s.started
+		b.Send(msg(2))
+
+		errc := make(chan error, 1)
+		go func() {
+			errc <- b.Send(msg(3))
+		}()
+		select {
+		case err := <-errc:
+			t.Fatalf("Expected the send to block until there's room, got %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		cancel()
+		if err := <-errc; err != context.Canceled {
+			t.Fatalf("Expected the send to stop once the stream is cancelled, got %v", err)
+		}
+		close(s.release)
+		b.Close()
+	})
+
+	t.Run("Recv", func(t *testing.T) {
+		s := newTestStream(context.TODO(), 1, 2, 3)
+		b := BufferStream(s, CallOptions{RecvBufferSize: 2})
+
+		for i := 1; i <= 3; i++ {
+			var rsp int
+			if err := b.Recv(&rsp); err != nil {
+				t.Fatal(err)
+			}
+			if rsp != i {
+				t.Fatalf("Expected message %v, got %v", i, rsp)
+			}
+		}
+		var rsp int
+		if err := b.Recv(&rsp); err != io.EOF {
+			t.Fatalf("Expected the end of the stream, got %v", err)
+		}
+	})
+
+	t.Run("RecvErrorWhenFull", func(t *testing.T) {
+		s := newTestStream(context.TODO(), 1, 2, 3, 4, 5)
+		b := BufferStream(s, CallOptions{RecvBufferSize: 2, BufferPolicy: ErrorWhenFull})
+
+		var rsp int
+		if err := b.Recv(&rsp); err != nil {
+			t.Fatal(err)
+		}
+
+		// the peer sends faster than the messages are received
+		time.Sleep(20 * time.Millisecond)
+
+		// the messages buffered are received before the error
+		var err error
+		var received int
+		for err == nil {
+			if err = b.Recv(&rsp); err == nil {
+				received++
+			}
+		}
+		if err != ErrBufferFull || received != 2 {
+			t.Fatalf("Expected the buffered messages then the buffer to be full, got %v messages and %v", received, err)
+		}
+		if b.Error() != ErrBufferFull {
+			t.Fatalf("Expected the stream to fail, got %v", b.Error())
+		}
+	})
+}
@@ -3,6 +3,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	pb "github.com/micro/micro/v3/proto/debug"
@@ -10,8 +11,12 @@ import (
 	"github.com/micro/micro/v3/service/debug/log"
 	"github.com/micro/micro/v3/service/debug/stats"
 	"github.com/micro/micro/v3/service/debug/trace"
+	"github.com/micro/micro/v3/service/store"
 )
 
+// healthTimeout is how long the health of the store is checked for
+var healthTimeout = 5 * time.Second
+
 // NewHandler returns an instance of the Debug Handler
 func NewHandler() *Debug {
 	return &Debug{
@@ -32,7 +37,16 @@ type Debug struct {
 	trace trace.Tracer
 }
 
+// Health returns ok, or why the service is unhealthy if the store it uses can't reach its
+// database
 func (d *Debug) Health(ctx context.Context, req *pb.HealthRequest, rsp *pb.HealthResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	if err := store.Healthy(ctx); err != nil {
+		rsp.Status = fmt.Sprintf("store unhealthy: %v", err)
+		return nil
+	}
 	rsp.Status = "ok"
 	return nil
 }
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/micro/micro/v3/proto/debug"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+type unhealthyStore struct {
+	store.Store
+}
+
+func (s *unhealthyStore) Healthy(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestHealth(t *testing.T) {
+	defer func(s store.Store) { store.DefaultStore = s }(store.DefaultStore)
+	d := NewHandler()
+
+	store.DefaultStore = memory.NewStore()
+	rsp := &pb.HealthResponse{}
+	assert.NoError(t, d.Health(context.TODO(), &pb.HealthRequest{}, rsp))
+	assert.Equal(t, "ok", rsp.Status)
+
+	store.DefaultStore = &unhealthyStore{Store: memory.NewStore()}
+	rsp = &pb.HealthResponse{}
+	assert.NoError(t, d.Health(context.TODO(), &pb.HealthRequest{}, rsp))
+	assert.Equal(t, "store unhealthy: connection refused", rsp.Status)
+}
@@ -1,9 +1,12 @@
 package client
 
 import (
+	"net/http"
+
 	pb "github.com/micro/micro/v3/proto/events"
 	"github.com/micro/micro/v3/service/client"
 	"github.com/micro/micro/v3/service/context"
+	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/events"
 	"github.com/micro/micro/v3/service/events/util"
 )
@@ -73,6 +76,18 @@ func (s *store) Write(ev *events.Event, opts ...events.WriteOption) error {
 	return err
 }
 
+// Delete an event from a topic
+func (s *store) Delete(topic, id string) error {
+	_, err := s.client().Delete(context.DefaultContext, &pb.DeleteRequest{
+		Topic: topic,
+		Id:    id,
+	}, client.WithAuthToken())
+	if verr := errors.FromError(err); verr != nil && verr.Code == http.StatusNotImplemented {
+		return events.ErrNotSupported
+	}
+	return err
+}
+
 // this is a tmp solution since the client isn't initialized when NewStream is called. There is a
 // fix in the works in another PR.
 func (s *store) client() pb.StoreService {
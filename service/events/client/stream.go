@@ -3,11 +3,13 @@ package client
 import (
 	gocontext "context"
 	"encoding/json"
+	"net/http"
 	"time"
 
 	pb "github.com/micro/micro/v3/proto/events"
 	"github.com/micro/micro/v3/service/client"
 	"github.com/micro/micro/v3/service/context"
+	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/events"
 	"github.com/micro/micro/v3/service/events/util"
 	log "github.com/micro/micro/v3/service/logger"
@@ -45,10 +47,12 @@ func (s *stream) Publish(topic string, msg interface{}, opts ...events.PublishOp
 
 	// execute the RPC
 	_, err := s.client().Publish(context.DefaultContext, &pb.PublishRequest{
-		Topic:     topic,
-		Payload:   payload,
-		Metadata:  options.Metadata,
-		Timestamp: options.Timestamp.Unix(),
+		Topic:          topic,
+		Payload:        payload,
+		Metadata:       options.Metadata,
+		Timestamp:      options.Timestamp.Unix(),
+		IdempotencyKey: options.IdempotencyKey,
+		OrderingKey:    options.OrderingKey,
 	}, client.WithAuthToken())
 
 	return err
@@ -65,13 +69,25 @@ func (s *stream) Consume(topic string, opts ...events.ConsumeOption) (<-chan eve
 		o(&options)
 	}
 
+	// validate the filter before subscribing
+	if len(options.Filter) > 0 {
+		if _, err := events.ParseFilter(options.Filter); err != nil {
+			return nil, err
+		}
+	}
+
 	subReq := &pb.ConsumeRequest{
-		Topic:      topic,
-		Group:      options.Group,
-		Offset:     options.Offset.Unix(),
-		AutoAck:    options.AutoAck,
-		AckWait:    options.AckWait.Nanoseconds(),
-		RetryLimit: int64(options.GetRetryLimit()),
+		Topic:           topic,
+		Group:           options.Group,
+		Offset:          options.Offset.Unix(),
+		AutoAck:         options.AutoAck,
+		AckWait:         options.AckWait.Nanoseconds(),
+		RetryLimit:      int64(options.GetRetryLimit()),
+		Sequence:        options.Sequence,
+		DeadLetterTopic: options.DeadLetterTopic,
+		Filter:          options.Filter,
+		BackoffMin:      options.BackoffMin.Nanoseconds(),
+		BackoffMax:      options.BackoffMax.Nanoseconds(),
 	}
 
 	// start the stream
@@ -118,6 +134,30 @@ func (s *stream) Consume(topic string, opts ...events.ConsumeOption) (<-chan eve
 	return evChan, nil
 }
 
+// Status returns the status of the consumer groups of the topic
+func (s *stream) Status(topic string) ([]events.GroupStatus, error) {
+	rsp, err := s.client().Status(context.DefaultContext, &pb.StatusRequest{
+		Topic: topic,
+	}, client.WithAuthToken())
+	if verr := errors.FromError(err); verr != nil && verr.Code == http.StatusNotImplemented {
+		return nil, events.ErrNotSupported
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := make([]events.GroupStatus, len(rsp.Groups))
+	for i, g := range rsp.Groups {
+		result[i] = events.GroupStatus{
+			Topic:     g.Topic,
+			Group:     g.Group,
+			Consumers: int(g.Consumers),
+			Pending:   g.Pending,
+			Unacked:   g.Unacked,
+		}
+	}
+	return result, nil
+}
+
 // this is a tmp solution since the client isn't initialized when NewStream is called. There is a
 // fix in the works in another PR.
 func (s *stream) client() pb.StreamService {
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/logger"
+)
+
+// DeadLetterTopicKey is the metadata key of the topic a dead-lettered event was consumed from
+const DeadLetterTopicKey = "Micro-Dead-Letter-Topic"
+
+// DeadLetter publishes an event which reached the retry limit of its consumer to the dead-letter
+// topic. The event is also written to the DefaultStore without an expiry, so it can be inspected
+// and requeued to the topic it was consumed from until it's deleted. Streams call it instead of
+// discarding the event when the consumer has a DeadLetterTopic.
+func DeadLetter(s Stream, topic string, ev Event) error {
+	md := make(map[string]string, len(ev.Metadata)+1)
+	for k, v := range ev.Metadata {
+		md[k] = v
+	}
+	md[DeadLetterTopicKey] = ev.Topic
+
+	if err := s.Publish(topic, ev.Payload, WithMetadata(md), WithOrderingKey(ev.OrderingKey)); err != nil {
+		return err
+	}
+	if DefaultStore == nil {
+		return nil
+	}
+
+	// the event is still published if it can't be recorded, so the error is only logged
+	dead := &Event{
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Timestamp:   time.Now(),
+		Metadata:    md,
+		Payload:     ev.Payload,
+		OrderingKey: ev.OrderingKey,
+	}
+	if err := DefaultStore.Write(dead, WithTTL(0)); err != nil {
+		logger.Errorf("Error writing dead-lettered event %v to store: %v", ev.ID, err)
+	}
+	return nil
+}
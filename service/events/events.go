@@ -33,6 +33,8 @@ var (
 	ErrMissingTopic = errors.New("Missing topic")
 	// ErrEncodingMessage is returned from publish if there was an error encoding the message option
 	ErrEncodingMessage = errors.New("Error encoding message")
+	// ErrNotSupported is returned if the implementation doesn't support the operation
+	ErrNotSupported = errors.New("Operation not supported")
 )
 
 // Stream is an event streaming interface
@@ -47,6 +49,40 @@ type Store interface {
 	Write(event *Event, opts ...WriteOption) error
 }
 
+// Deleter is implemented by event stores which can delete events, so dead-lettered events can be
+// removed once they've been requeued
+type Deleter interface {
+	Delete(topic, id string) error
+}
+
+// Deduplicator is implemented by event stores which deduplicate published events by their
+// idempotency keys within a window
+type Deduplicator interface {
+	// Published returns true if an event with the key was published to the topic within the window
+	Published(topic, key string) (bool, error)
+	// RecordPublished records that an event with the key was published to the topic
+	RecordPublished(topic, key string) error
+}
+
+// Statuser is implemented by streams which can report the status of their consumer groups, so
+// operators can detect consumers which are stuck or falling behind
+type Statuser interface {
+	// Status returns the status of the consumer groups of the topic, or of every topic if it's blank
+	Status(topic string) ([]GroupStatus, error)
+}
+
+// GroupStatus is the status of a consumer group of a topic
+type GroupStatus struct {
+	Topic string
+	Group string
+	// Consumers is the number of consumers in the group, zero if the stream doesn't know it
+	Consumers int
+	// Pending is the number of events which haven't been delivered to the group
+	Pending uint64
+	// Unacked is the number of events delivered to the group which haven't been acknowledged
+	Unacked uint64
+}
+
 type AckFunc func() error
 type NackFunc func() error
 
@@ -62,6 +98,12 @@ type Event struct {
 	Metadata map[string]string
 	// Payload contains the encoded message
 	Payload []byte
+	// Sequence of the event in the stream, set by streams which number their events
+	Sequence uint64
+	// OrderingKey the event was published with
+	OrderingKey string
+	// Partition of the topic the event was read from, set by streams which partition their topics
+	Partition int
 
 	ackFunc  AckFunc
 	nackFunc NackFunc
@@ -104,3 +146,22 @@ func Consume(topic string, opts ...ConsumeOption) (<-chan Event, error) {
 func Read(topic string, opts ...ReadOption) ([]*Event, error) {
 	return DefaultStore.Read(topic, opts...)
 }
+
+// Delete an event from a topic. If the store doesn't implement Deleter ErrNotSupported is returned.
+func Delete(topic, id string) error {
+	d, ok := DefaultStore.(Deleter)
+	if !ok {
+		return ErrNotSupported
+	}
+	return d.Delete(topic, id)
+}
+
+// Status returns the status of the consumer groups of the topic, or of every topic if it's blank.
+// If the stream doesn't implement Statuser ErrNotSupported is returned.
+func Status(topic string) ([]GroupStatus, error) {
+	s, ok := DefaultStream.(Statuser)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return s.Status(topic)
+}
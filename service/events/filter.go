@@ -0,0 +1,227 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Filter of the events delivered to a consumer, parsed from an expression by ParseFilter
+type Filter struct {
+	match func(ev *Event) bool
+}
+
+// Match returns true if the event matches the filter
+func (f *Filter) Match(ev *Event) bool {
+	return f.match(ev)
+}
+
+// ParseFilter parses a filter expression. The expression compares the fields of an event to
+// quoted strings with == and !=, or to a list of strings with in, and combines the comparisons
+// with &&, ||, ! and parentheses. The fields are topic, id and metadata.<key>, a missing metadata
+// key is an empty string, e.g.
+//
+//	metadata.customer == "1234" && (metadata.type in ["order", "refund"] || topic != "orders")
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	match, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Invalid filter, unexpected %v", p.tokens[p.pos].value)
+	}
+	return &Filter{match: match}, nil
+}
+
+type filterToken struct {
+	// str is true if the token is a quoted string
+	str   bool
+	value string
+}
+
+// tokenizeFilter splits the expression into operators, fields and strings
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("Invalid filter, unterminated string at %d", i)
+			}
+			tokens = append(tokens, filterToken{str: true, value: expr[i+1 : i+1+end]})
+			i += end + 2
+		case strings.HasPrefix(expr[i:], "==") || strings.HasPrefix(expr[i:], "!=") ||
+			strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, filterToken{value: expr[i : i+2]})
+			i += 2
+		case strings.IndexByte("!()[],", c) >= 0:
+			tokens = append(tokens, filterToken{value: expr[i : i+1]})
+			i++
+		case isFieldChar(c):
+			j := i
+			for j < len(expr) && isFieldChar(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, filterToken{value: expr[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("Invalid filter, unexpected %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isFieldChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// filterParser is a recursive descent parser of the tokens of a filter expression
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+// next returns the next token if it's the operator, advancing past it
+func (p *filterParser) next(op string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].str && p.tokens[p.pos].value == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// or parses comparisons joined by ||
+func (p *filterParser) or() (func(*Event) bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.next("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(ev *Event) bool { return l(ev) || right(ev) }
+	}
+	return left, nil
+}
+
+// and parses comparisons joined by &&
+func (p *filterParser) and() (func(*Event) bool, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.next("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(ev *Event) bool { return l(ev) && right(ev) }
+	}
+	return left, nil
+}
+
+// unary parses a negation, an expression in parentheses or a comparison
+func (p *filterParser) unary() (func(*Event) bool, error) {
+	if p.next("!") {
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(ev *Event) bool { return !m(ev) }, nil
+	}
+	if p.next("(") {
+		m, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(")") {
+			return nil, fmt.Errorf("Invalid filter, missing )")
+		}
+		return m, nil
+	}
+	return p.comparison()
+}
+
+// comparison parses a field compared to a string or a list of strings
+func (p *filterParser) comparison() (func(*Event) bool, error) {
+	field, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.next("=="):
+		v, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		return func(ev *Event) bool { return field(ev) == v }, nil
+	case p.next("!="):
+		v, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		return func(ev *Event) bool { return field(ev) != v }, nil
+	case p.next("in"):
+		if !p.next("[") {
+			return nil, fmt.Errorf("Invalid filter, expected [ after in")
+		}
+		values := map[string]bool{}
+		for !p.next("]") {
+			if len(values) > 0 && !p.next(",") {
+				return nil, fmt.Errorf("Invalid filter, expected , or ] in list")
+			}
+			v, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			values[v] = true
+		}
+		return func(ev *Event) bool { return values[field(ev)] }, nil
+	default:
+		return nil, fmt.Errorf("Invalid filter, expected ==, != or in")
+	}
+}
+
+// field parses the field of the event being compared
+func (p *filterParser) field() (func(*Event) string, error) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].str {
+		return nil, fmt.Errorf("Invalid filter, expected a field")
+	}
+	name := p.tokens[p.pos].value
+	p.pos++
+
+	switch {
+	case name == "topic":
+		return func(ev *Event) string { return ev.Topic }, nil
+	case name == "id":
+		return func(ev *Event) string { return ev.ID }, nil
+	case strings.HasPrefix(name, "metadata.") && len(name) > len("metadata."):
+		key := strings.TrimPrefix(name, "metadata.")
+		return func(ev *Event) string { return ev.Metadata[key] }, nil
+	default:
+		return nil, fmt.Errorf("Invalid filter, unknown field %v", name)
+	}
+}
+
+// str parses a quoted string
+func (p *filterParser) str() (string, error) {
+	if p.pos >= len(p.tokens) || !p.tokens[p.pos].str {
+		return "", fmt.Errorf("Invalid filter, expected a quoted string")
+	}
+	p.pos++
+	return p.tokens[p.pos-1].value, nil
+}
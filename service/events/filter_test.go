@@ -0,0 +1,58 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	ev := &Event{
+		ID:       "1",
+		Topic:    "orders",
+		Metadata: map[string]string{"customer": "1234", "type": "refund"},
+	}
+
+	tt := []struct {
+		expr  string
+		match bool
+	}{
+		{`metadata.customer == "1234"`, true},
+		{`metadata.customer != '1234'`, false},
+		{`metadata.missing == ""`, true},
+		{`topic == "orders" && id == "1"`, true},
+		{`topic == "orders" && id == "2"`, false},
+		{`topic == "users" || id == "1"`, true},
+		{`metadata.type in ["order", "refund"]`, true},
+		{`metadata.type in []`, false},
+		{`!(metadata.type in ["order"])`, true},
+		{`metadata.customer == "1234" && (metadata.type == "order" || topic != "orders")`, false},
+		{`topic == "users" || topic == "orders" && metadata.type == "refund"`, true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := ParseFilter(tc.expr)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.match, f.Match(ev))
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`metadata.customer`,
+		`metadata.customer == 1234`,
+		`metadata.customer == "1234`,
+		`unknown == "1234"`,
+		`metadata. == "1234"`,
+		`(topic == "orders"`,
+		`topic == "orders" topic`,
+		`topic in ["a" "b"]`,
+		`topic > "a"`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.Errorf(t, err, "Expected an error parsing %v", expr)
+	}
+}
@@ -0,0 +1,25 @@
+package events
+
+// GroupTopic is the topic the events service publishes a GroupChange to when a consumer joins or
+// leaves a consumer group, so operators can be alerted when a group loses its consumers
+const GroupTopic = "micro.events.group"
+
+const (
+	// GroupJoined is the action of a consumer joining a group
+	GroupJoined = "joined"
+	// GroupLeft is the action of a consumer leaving a group
+	GroupLeft = "left"
+)
+
+// GroupChange is the payload of the events published to the GroupTopic
+type GroupChange struct {
+	Topic string
+	Group string
+	// Action is either GroupJoined or GroupLeft
+	Action string
+	// Consumer is the id of the account which joined or left the group
+	Consumer string
+	// Consumers is the number of consumers in the group after the change, as seen by the events
+	// service which published it
+	Consumers int
+}
@@ -51,3 +51,27 @@ func (s *Store) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadRespo
 func (s *Store) Write(ctx context.Context, req *pb.WriteRequest, rsp *pb.WriteResponse) error {
 	return errors.NotImplemented("events.Store.Write", "Writing to the store directly is not supported")
 }
+
+func (s *Store) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.DeleteResponse) error {
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, namespace.DefaultNamespace, "events.Store.Delete"); err != nil {
+		return err
+	}
+
+	// validate the request
+	if len(req.Topic) == 0 {
+		return errors.BadRequest("events.Store.Delete", goevents.ErrMissingTopic.Error())
+	}
+	if len(req.Id) == 0 {
+		return errors.BadRequest("events.Store.Delete", "Missing id")
+	}
+
+	// delete from the store
+	if err := events.Delete(req.Topic, req.Id); err == goevents.ErrNotSupported {
+		return errors.NotImplemented("events.Store.Delete", err.Error())
+	} else if err != nil {
+		return errors.InternalServerError("events.Store.Delete", err.Error())
+	}
+
+	return nil
+}
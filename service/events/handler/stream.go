@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	pb "github.com/micro/micro/v3/proto/events"
+	"github.com/micro/micro/v3/service/auth"
 	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/events"
 	"github.com/micro/micro/v3/service/events/util"
@@ -14,7 +15,47 @@ import (
 	"github.com/micro/micro/v3/util/auth/namespace"
 )
 
-type Stream struct{}
+type Stream struct {
+	sync.Mutex
+	// consumers is the number of consumers of each group of each topic connected to the service
+	consumers map[string]map[string]int
+	// publishing is the lock of each idempotency key being published, so the events published
+	// concurrently with the same key are deduplicated
+	publishing map[string]*keyLock
+}
+
+// keyLock is the lock of an idempotency key, removed once it has no holders
+type keyLock struct {
+	sync.Mutex
+	holders int
+}
+
+// lockKey locks the idempotency key of the topic, returning the func to unlock it
+func (s *Stream) lockKey(topic, key string) func() {
+	id := topic + "/" + key
+
+	s.Lock()
+	if s.publishing == nil {
+		s.publishing = make(map[string]*keyLock)
+	}
+	l, ok := s.publishing[id]
+	if !ok {
+		l = &keyLock{}
+		s.publishing[id] = l
+	}
+	l.holders++
+	s.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.Lock()
+		if l.holders--; l.holders == 0 {
+			delete(s.publishing, id)
+		}
+		s.Unlock()
+	}
+}
 
 func (s *Stream) Publish(ctx context.Context, req *pb.PublishRequest, rsp *pb.PublishResponse) error {
 	// authorize the request
@@ -35,19 +76,48 @@ func (s *Stream) Publish(ctx context.Context, req *pb.PublishRequest, rsp *pb.Pu
 	if req.Metadata != nil {
 		opts = append(opts, events.WithMetadata(req.Metadata))
 	}
+	if len(req.IdempotencyKey) > 0 {
+		opts = append(opts, events.WithIdempotencyKey(req.IdempotencyKey))
+	}
+	if len(req.OrderingKey) > 0 {
+		opts = append(opts, events.WithOrderingKey(req.OrderingKey))
+	}
+
+	// drop the event if it was already published with the idempotency key, e.g. by a publish
+	// which was retried after the response was lost. The key is locked until it's recorded so a
+	// concurrent publish with it waits to be dropped.
+	dedup, ok := events.DefaultStore.(events.Deduplicator)
+	if ok && len(req.IdempotencyKey) > 0 {
+		unlock := s.lockKey(req.Topic, req.IdempotencyKey)
+		defer unlock()
+
+		if published, err := dedup.Published(req.Topic, req.IdempotencyKey); err != nil {
+			return errors.InternalServerError("events.Stream.Publish", err.Error())
+		} else if published {
+			return nil
+		}
+	}
 
 	// publish the event
 	if err := events.Publish(req.Topic, req.Payload, opts...); err != nil {
 		return errors.InternalServerError("events.Stream.Publish", err.Error())
 	}
 
+	// the key is recorded once the event is published, so a failed publish can be retried
+	if ok && len(req.IdempotencyKey) > 0 {
+		if err := dedup.RecordPublished(req.Topic, req.IdempotencyKey); err != nil {
+			logger.Errorf("Error recording idempotency key %v of topic %v: %v", req.IdempotencyKey, req.Topic, err)
+		}
+	}
+
 	// write the event to the store
 	event := events.Event{
-		ID:        uuid.New().String(),
-		Metadata:  req.Metadata,
-		Payload:   req.Payload,
-		Topic:     req.Topic,
-		Timestamp: time.Unix(req.Timestamp, 0),
+		ID:          uuid.New().String(),
+		Metadata:    req.Metadata,
+		Payload:     req.Payload,
+		Topic:       req.Topic,
+		Timestamp:   time.Unix(req.Timestamp, 0),
+		OrderingKey: req.OrderingKey,
 	}
 
 	if err := events.DefaultStore.Write(&event, events.WithTTL(time.Hour*24)); err != nil {
@@ -68,6 +138,9 @@ func (s *Stream) Consume(ctx context.Context, req *pb.ConsumeRequest, rsp pb.Str
 	if req.Offset > 0 {
 		opts = append(opts, events.WithOffset(time.Unix(req.Offset, 0)))
 	}
+	if req.Sequence > 0 {
+		opts = append(opts, events.WithSequence(req.Sequence))
+	}
 	if len(req.Group) > 0 {
 		opts = append(opts, events.WithGroup(req.Group))
 	}
@@ -77,6 +150,22 @@ func (s *Stream) Consume(ctx context.Context, req *pb.ConsumeRequest, rsp pb.Str
 	if req.RetryLimit > -1 {
 		opts = append(opts, events.WithRetryLimit(int(req.RetryLimit)))
 	}
+	if len(req.DeadLetterTopic) > 0 {
+		opts = append(opts, events.WithDeadLetter(req.DeadLetterTopic))
+	}
+	if req.BackoffMin > 0 {
+		opts = append(opts, events.WithBackoff(time.Duration(req.BackoffMin), time.Duration(req.BackoffMax)))
+	}
+
+	// the events which don't match the filter aren't sent to the consumer
+	var filter *events.Filter
+	if len(req.Filter) > 0 {
+		f, err := events.ParseFilter(req.Filter)
+		if err != nil {
+			return errors.BadRequest("events.Stream.Consume", err.Error())
+		}
+		filter = f
+	}
 
 	// append the context
 	opts = append(opts, events.WithContext(ctx))
@@ -87,6 +176,16 @@ func (s *Stream) Consume(ctx context.Context, req *pb.ConsumeRequest, rsp pb.Str
 		return errors.InternalServerError("events.Stream.Consume", err.Error())
 	}
 
+	// track the members of the group until the consumer disconnects
+	if len(req.Group) > 0 {
+		var consumer string
+		if acc, ok := auth.AccountFromContext(ctx); ok {
+			consumer = acc.ID
+		}
+		s.groupChanged(req.Topic, req.Group, consumer, events.GroupJoined)
+		defer s.groupChanged(req.Topic, req.Group, consumer, events.GroupLeft)
+	}
+
 	type eventSent struct {
 		sent  time.Time
 		event events.Event
@@ -159,6 +258,13 @@ func (s *Stream) Consume(ctx context.Context, req *pb.ConsumeRequest, rsp pb.Str
 				// ignore
 				continue
 			}
+			if filter != nil && !filter.Match(&ev) {
+				// acknowledge the event so it's not redelivered
+				if !req.AutoAck {
+					ev.Ack()
+				}
+				continue
+			}
 			if !req.AutoAck {
 				// track the acks
 				mutex.Lock()
@@ -180,3 +286,70 @@ func (s *Stream) Consume(ctx context.Context, req *pb.ConsumeRequest, rsp pb.Str
 	return nil
 
 }
+
+func (s *Stream) Status(ctx context.Context, req *pb.StatusRequest, rsp *pb.StatusResponse) error {
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, namespace.DefaultNamespace, "events.Stream.Status"); err != nil {
+		return err
+	}
+
+	groups, err := events.Status(req.Topic)
+	if err == events.ErrNotSupported {
+		return errors.NotImplemented("events.Stream.Status", err.Error())
+	} else if err != nil {
+		return errors.InternalServerError("events.Stream.Status", err.Error())
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	rsp.Groups = make([]*pb.GroupStatus, len(groups))
+	for i, g := range groups {
+		// the consumers connected to the service are used if the stream doesn't know them
+		consumers := g.Consumers
+		if consumers == 0 {
+			consumers = s.consumers[g.Topic][g.Group]
+		}
+		rsp.Groups[i] = &pb.GroupStatus{
+			Topic:     g.Topic,
+			Group:     g.Group,
+			Consumers: int64(consumers),
+			Pending:   g.Pending,
+			Unacked:   g.Unacked,
+		}
+	}
+	return nil
+}
+
+// groupChanged updates the number of consumers of the group and publishes the change to the
+// GroupTopic
+func (s *Stream) groupChanged(topic, group, consumer, action string) {
+	s.Lock()
+	if s.consumers == nil {
+		s.consumers = map[string]map[string]int{}
+	}
+	if s.consumers[topic] == nil {
+		s.consumers[topic] = map[string]int{}
+	}
+	if action == events.GroupJoined {
+		s.consumers[topic][group]++
+	} else {
+		s.consumers[topic][group]--
+	}
+	count := s.consumers[topic][group]
+	if count == 0 {
+		delete(s.consumers[topic], group)
+	}
+	s.Unlock()
+
+	logger.Infof("Consumer %v %v group %v of topic %v, %d consumers", consumer, action, group, topic, count)
+	change := events.GroupChange{
+		Topic:     topic,
+		Group:     group,
+		Action:    action,
+		Consumer:  consumer,
+		Consumers: count,
+	}
+	if err := events.Publish(events.GroupTopic, change); err != nil {
+		logger.Errorf("Error publishing change of group %v of topic %v: %v", group, topic, err)
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/micro/micro/v3/proto/events"
+	"github.com/micro/micro/v3/service/auth"
+	"github.com/micro/micro/v3/service/events"
+	evStore "github.com/micro/micro/v3/service/events/store"
+	"github.com/micro/micro/v3/service/events/stream/memory"
+	"github.com/micro/micro/v3/util/auth/namespace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishIdempotencyKey(t *testing.T) {
+	defer func(s events.Stream, st events.Store) {
+		events.DefaultStream, events.DefaultStore = s, st
+	}(events.DefaultStream, events.DefaultStore)
+	var err error
+	events.DefaultStream, err = memory.NewStream()
+	assert.NoError(t, err)
+	events.DefaultStore = evStore.NewStore()
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: namespace.DefaultNamespace, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &Stream{}
+
+	ch, err := events.Consume("foo")
+	assert.NoError(t, err)
+
+	// the retried publish is dropped, the event with another key isn't
+	for _, key := range []string{"one", "one", "two"} {
+		req := &pb.PublishRequest{Topic: "foo", Payload: []byte(key), IdempotencyKey: key}
+		assert.NoError(t, h.Publish(ctx, req, &pb.PublishResponse{}))
+	}
+
+	var received []string
+	timeout := time.After(time.Second)
+loop:
+	for {
+		select {
+		case ev := <-ch:
+			received = append(received, string(ev.Payload))
+		case <-timeout:
+			break loop
+		}
+	}
+	assert.ElementsMatch(t, []string{"one", "two"}, received)
+}
+
+// slowDedup is a store which is slow to record the idempotency keys published
+type slowDedup struct {
+	events.Store
+
+	sync.Mutex
+	published map[string]bool
+}
+
+func (s *slowDedup) Published(topic, key string) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.published[topic+"/"+key], nil
+}
+
+func (s *slowDedup) RecordPublished(topic, key string) error {
+	time.Sleep(10 * time.Millisecond)
+	s.Lock()
+	defer s.Unlock()
+	s.published[topic+"/"+key] = true
+	return nil
+}
+
+func TestPublishIdempotencyKeyConcurrent(t *testing.T) {
+	defer func(s events.Stream, st events.Store) {
+		events.DefaultStream, events.DefaultStore = s, st
+	}(events.DefaultStream, events.DefaultStore)
+	var err error
+	events.DefaultStream, err = memory.NewStream()
+	assert.NoError(t, err)
+	events.DefaultStore = &slowDedup{Store: evStore.NewStore(), published: map[string]bool{}}
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: namespace.DefaultNamespace, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &Stream{}
+
+	ch, err := events.Consume("foo")
+	assert.NoError(t, err)
+
+	// only one of the events published concurrently with the same key is published
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := &pb.PublishRequest{Topic: "foo", Payload: []byte("one"), IdempotencyKey: "one"}
+			assert.NoError(t, h.Publish(ctx, req, &pb.PublishResponse{}))
+		}()
+	}
+	wg.Wait()
+
+	var received int
+	timeout := time.After(time.Second)
+loop:
+	for {
+		select {
+		case <-ch:
+			received++
+		case <-timeout:
+			break loop
+		}
+	}
+	assert.Equal(t, 1, received)
+	assert.Len(t, h.publishing, 0)
+}
+
+func TestGroupStatus(t *testing.T) {
+	defer func(s events.Stream) { events.DefaultStream = s }(events.DefaultStream)
+	var err error
+	events.DefaultStream, err = memory.NewStream()
+	assert.NoError(t, err)
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: namespace.DefaultNamespace, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &Stream{}
+
+	changes, err := events.Consume(events.GroupTopic)
+	assert.NoError(t, err)
+	receive := func() events.GroupChange {
+		var change events.GroupChange
+		select {
+		case ev := <-changes:
+			assert.NoError(t, ev.Unmarshal(&change))
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the group change")
+		}
+		return change
+	}
+
+	// consumers joining and leaving the group are published to the group topic
+	h.groupChanged("foo", "group", "consumer", events.GroupJoined)
+	assert.Equal(t, events.GroupChange{
+		Topic: "foo", Group: "group", Action: events.GroupJoined, Consumer: "consumer", Consumers: 1,
+	}, receive())
+
+	_, err = events.Consume("foo", events.WithGroup("group"))
+	assert.NoError(t, err)
+	var rsp pb.StatusResponse
+	assert.NoError(t, h.Status(ctx, &pb.StatusRequest{Topic: "foo"}, &rsp))
+	if assert.Len(t, rsp.Groups, 1) {
+		assert.Equal(t, "group", rsp.Groups[0].Group)
+		assert.Equal(t, int64(1), rsp.Groups[0].Consumers)
+	}
+
+	h.groupChanged("foo", "group", "consumer", events.GroupLeft)
+	assert.Equal(t, events.GroupLeft, receive().Action)
+	assert.Empty(t, h.consumers["foo"])
+}
+
+// consumeStream sends the events of a Consume to a channel
+type consumeStream struct {
+	ctx    context.Context
+	events chan *pb.Event
+}
+
+func (c *consumeStream) Context() context.Context  { return c.ctx }
+func (c *consumeStream) SendMsg(interface{}) error { return nil }
+func (c *consumeStream) Close() error              { return nil }
+
+func (c *consumeStream) RecvMsg(interface{}) error {
+	<-c.ctx.Done()
+	return c.ctx.Err()
+}
+
+func (c *consumeStream) Send(ev *pb.Event) error {
+	c.events <- ev
+	return nil
+}
+
+func TestConsumeFilter(t *testing.T) {
+	defer func(s events.Stream) { events.DefaultStream = s }(events.DefaultStream)
+	var err error
+	events.DefaultStream, err = memory.NewStream()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: namespace.DefaultNamespace, Type: "user", Scopes: []string{"admin"},
+	}))
+	defer cancel()
+	h := &Stream{}
+
+	// invalid filters are rejected
+	rsp := &consumeStream{ctx: ctx, events: make(chan *pb.Event, 10)}
+	err = h.Consume(ctx, &pb.ConsumeRequest{Topic: "foo", AutoAck: true, RetryLimit: -1, Filter: `type ==`}, rsp)
+	assert.Error(t, err)
+
+	go h.Consume(ctx, &pb.ConsumeRequest{Topic: "foo", AutoAck: true, RetryLimit: -1, Filter: `metadata.type == "a"`}, rsp)
+	time.Sleep(100 * time.Millisecond)
+	for _, typ := range []string{"b", "a"} {
+		assert.NoError(t, events.Publish("foo", []byte(typ), events.WithMetadata(map[string]string{"type": typ})))
+	}
+
+	// only the event matching the filter is sent
+	select {
+	case ev := <-rsp.events:
+		assert.Equal(t, "a", string(ev.Payload))
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the event")
+	}
+	select {
+	case ev := <-rsp.events:
+		t.Errorf("Expected the event %v to be filtered", string(ev.Payload))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -16,6 +16,7 @@ package events
 
 import (
 	"context"
+	"math"
 	"time"
 )
 
@@ -25,6 +26,12 @@ type PublishOptions struct {
 	Metadata map[string]string
 	// Timestamp to set for the event, if the timestamp is a zero value, the current time will be used
 	Timestamp time.Time
+	// IdempotencyKey identifies the event across retried publishes, events with the same key
+	// published to the topic within the deduplication window are only delivered once
+	IdempotencyKey string
+	// OrderingKey of the event, streams which partition their topics publish the events with the
+	// same key to the same partition so they're delivered in the order they were published
+	OrderingKey string
 }
 
 // PublishOption sets attributes on PublishOptions
@@ -44,6 +51,20 @@ func WithTimestamp(t time.Time) PublishOption {
 	}
 }
 
+// WithIdempotencyKey sets the IdempotencyKey field on PublishOptions
+func WithIdempotencyKey(key string) PublishOption {
+	return func(o *PublishOptions) {
+		o.IdempotencyKey = key
+	}
+}
+
+// WithOrderingKey sets the OrderingKey field on PublishOptions
+func WithOrderingKey(key string) PublishOption {
+	return func(o *PublishOptions) {
+		o.OrderingKey = key
+	}
+}
+
 // ConsumeOptions contains all the options which can be provided when subscribing to a topic
 type ConsumeOptions struct {
 	// Group is the name of the consumer group, if two consumers have the same group the events
@@ -52,6 +73,9 @@ type ConsumeOptions struct {
 	// Offset is the time from which the messages should be consumed from. If not provided then
 	// the messages will be consumed starting from the moment the Subscription starts.
 	Offset time.Time
+	// Sequence of the event to start consuming from, for streams which number their events. It
+	// takes precedence over the Offset.
+	Sequence uint64
 	// AutoAck if true (default true), automatically acknowledges every message so it will not be redelivered.
 	// If false specifies that each message need ts to be manually acknowledged by the subscriber.
 	// If processing is successful the message should be ack'ed to remove the message from the stream.
@@ -63,6 +87,17 @@ type ConsumeOptions struct {
 	RetryLimit int
 	// CustomRetries indicates whether to use RetryLimit
 	CustomRetries bool
+	// BackoffMin and BackoffMax are the bounds of the delay before an event which wasn't
+	// acknowledged is redelivered, which doubles with each delivery. Events are redelivered
+	// without a delay if BackoffMin is zero.
+	BackoffMin time.Duration
+	BackoffMax time.Duration
+	// DeadLetterTopic is the topic events are published to once they reach the RetryLimit,
+	// instead of being discarded
+	DeadLetterTopic string
+	// Filter expression, see ParseFilter. It's evaluated by the events service, so only the events
+	// which match it are sent to the consumer.
+	Filter string
 	// Context used to close the stream
 	Context context.Context
 }
@@ -91,6 +126,27 @@ func WithOffset(t time.Time) ConsumeOption {
 	}
 }
 
+// WithSequence sets the sequence of the event at which to start consuming events
+func WithSequence(seq uint64) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.Sequence = seq
+	}
+}
+
+// ReadFrom replays the events of the topic published from the time before consuming new ones,
+// e.g. so a new service can rebuild its state from the history of the topic. The events are
+// only replayed as far back as the stream retains them.
+func ReadFrom(t time.Time) ConsumeOption {
+	return WithOffset(t)
+}
+
+// ReadFromOffset replays the events of the topic from the offset of the event in the stream
+// before consuming new ones. It's only supported by streams which number their events, which
+// set Event.Sequence, and is ignored by the others.
+func ReadFromOffset(n uint64) ConsumeOption {
+	return WithSequence(n)
+}
+
 // WithAutoAck sets the AutoAck field on ConsumeOptions and an ackWait duration after which if no ack is received
 // the message is requeued in case auto ack is turned off
 func WithAutoAck(ack bool, ackWait time.Duration) ConsumeOption {
@@ -109,6 +165,47 @@ func WithRetryLimit(retries int) ConsumeOption {
 	}
 }
 
+// WithDeadLetter sets the topic events are published to once they reach the retry limit
+func WithDeadLetter(topic string) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.DeadLetterTopic = topic
+	}
+}
+
+// WithFilter sets the filter expression of the events sent to the consumer, see ParseFilter
+func WithFilter(expr string) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.Filter = expr
+	}
+}
+
+// WithBackoff sets an exponential backoff between the redeliveries of an event, so a slow
+// consumer isn't flooded with redeliveries. The delay starts at min and doubles with each delivery
+// up to max, or without a limit if max is zero. It's supported by the streams which can delay
+// redeliveries.
+func WithBackoff(min, max time.Duration) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.BackoffMin = min
+		o.BackoffMax = max
+	}
+}
+
+// RedeliveryDelay returns the delay before redelivering an event which has been delivered the
+// number of times
+func (s ConsumeOptions) RedeliveryDelay(deliveries int) time.Duration {
+	if s.BackoffMin <= 0 || deliveries < 1 {
+		return 0
+	}
+	d := s.BackoffMin
+	for i := 1; i < deliveries && (s.BackoffMax <= 0 || d < s.BackoffMax) && d < math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if s.BackoffMax > 0 && d > s.BackoffMax {
+		return s.BackoffMax
+	}
+	return d
+}
+
 func (s ConsumeOptions) GetRetryLimit() int {
 	if !s.CustomRetries {
 		return -1
@@ -0,0 +1,26 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedeliveryDelay(t *testing.T) {
+	var options ConsumeOptions
+	assert.Equal(t, time.Duration(0), options.RedeliveryDelay(3))
+
+	WithBackoff(time.Second, 5*time.Second)(&options)
+	assert.Equal(t, time.Duration(0), options.RedeliveryDelay(0))
+	assert.Equal(t, time.Second, options.RedeliveryDelay(1))
+	assert.Equal(t, 2*time.Second, options.RedeliveryDelay(2))
+	assert.Equal(t, 4*time.Second, options.RedeliveryDelay(3))
+	assert.Equal(t, 5*time.Second, options.RedeliveryDelay(4))
+	assert.Equal(t, 5*time.Second, options.RedeliveryDelay(100))
+
+	// the backoff isn't limited without a max
+	WithBackoff(time.Second, 0)(&options)
+	assert.Equal(t, 8*time.Second, options.RedeliveryDelay(4))
+	assert.True(t, options.RedeliveryDelay(1000) > 0, "The backoff shouldn't overflow")
+}
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/store"
+)
+
+// Options which are used to configure the outbox
+type Options struct {
+	// Store the records and the outbox are written to, it must implement store.Transactional.
+	// Defaults to store.DefaultStore.
+	Store store.Store
+	// Stream the relay publishes the events to, defaults to events.DefaultStream
+	Stream events.Stream
+	// Database and Table of the records and the outbox, transactions are on a single table so
+	// the outbox is kept with the records
+	Database string
+	Table    string
+	// Prefix of the keys of the outbox entries, defaults to "outbox/"
+	Prefix string
+	// Interval the relay checks for entries at, defaults to 1 second
+	Interval time.Duration
+}
+
+// Option is a function which configures options
+type Option func(o *Options)
+
+// WithStore sets the store the records and the outbox are written to
+func WithStore(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// WithStream sets the stream the relay publishes the events to
+func WithStream(s events.Stream) Option {
+	return func(o *Options) {
+		o.Stream = s
+	}
+}
+
+// WithTable sets the database and table of the records and the outbox
+func WithTable(database, table string) Option {
+	return func(o *Options) {
+		o.Database = database
+		o.Table = table
+	}
+}
+
+// WithPrefix sets the prefix of the keys of the outbox entries
+func WithPrefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// WithInterval sets how often the relay checks for entries
+func WithInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
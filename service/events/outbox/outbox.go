@@ -0,0 +1,213 @@
+// Package outbox implements a transactional outbox, so a change to the store and the event which
+// announces it are either both made or not at all. The event is written to the store in the same
+// transaction as the change, and a relay publishes it to the events stream once it's committed.
+//
+// Events are published at least once: the relay deletes an event from the outbox after it's
+// published, so an event is published again if the relay stops in between. Events are published
+// with an idempotency key, their id unless one is given, so the events service drops the repeats.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+)
+
+// NewOutbox returns an outbox configured with the options
+func NewOutbox(opts ...Option) *Outbox {
+	options := Options{
+		Prefix:   "outbox/",
+		Interval: time.Second,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Outbox{opts: options, notify: make(chan struct{}, 1)}
+}
+
+// Outbox of events waiting to be published
+type Outbox struct {
+	opts Options
+	// notify wakes the relay when a transaction adding events is committed
+	notify chan struct{}
+
+	// the entries are published by one flush at a time so they're published in order
+	mtx sync.Mutex
+}
+
+// entry of the outbox, an event waiting to be published
+type entry struct {
+	Topic          string
+	Payload        []byte
+	Metadata       map[string]string
+	Timestamp      time.Time
+	IdempotencyKey string
+	OrderingKey    string
+}
+
+// store returns the store of the outbox, the default store is set after the outbox may be created
+func (o *Outbox) store() store.Store {
+	if o.opts.Store != nil {
+		return o.opts.Store
+	}
+	return store.DefaultStore
+}
+
+// stream returns the stream the events are published to
+func (o *Outbox) stream() events.Stream {
+	if o.opts.Stream != nil {
+		return o.opts.Stream
+	}
+	return events.DefaultStream
+}
+
+// Write the record and add the event to the outbox in a single transaction
+func (o *Outbox) Write(ctx context.Context, rec *store.Record, topic string, msg interface{}, opts ...events.PublishOption) error {
+	return o.Txn(ctx, func(tx store.Tx) error {
+		if err := tx.Write(rec); err != nil {
+			return err
+		}
+		return o.Add(tx, topic, msg, opts...)
+	})
+}
+
+// Txn runs fn in a transaction on the table of the outbox, the events added to the outbox with
+// Add are published once it's committed. If the store doesn't implement store.Transactional
+// store.ErrNotSupported is returned.
+func (o *Outbox) Txn(ctx context.Context, fn func(tx store.Tx) error) error {
+	t, ok := o.store().(store.Transactional)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	if err := t.Txn(ctx, fn, store.TxnOn(o.opts.Database, o.opts.Table)); err != nil {
+		return err
+	}
+
+	// wake the relay
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Add the event to the outbox as part of the transaction, which must be on the table of the outbox
+func (o *Outbox) Add(tx store.Tx, topic string, msg interface{}, opts ...events.PublishOption) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	// parse the options
+	options := events.PublishOptions{
+		Timestamp: time.Now(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// encode the message if it's not already encoded
+	var payload []byte
+	if p, ok := msg.([]byte); ok {
+		payload = p
+	} else {
+		p, err := json.Marshal(msg)
+		if err != nil {
+			return events.ErrEncodingMessage
+		}
+		payload = p
+	}
+
+	// the events which are published again if the relay stops are dropped by their key
+	id := uuid.New().String()
+	if len(options.IdempotencyKey) == 0 {
+		options.IdempotencyKey = id
+	}
+	bytes, err := json.Marshal(&entry{
+		Topic:          topic,
+		Payload:        payload,
+		Metadata:       options.Metadata,
+		Timestamp:      options.Timestamp,
+		IdempotencyKey: options.IdempotencyKey,
+		OrderingKey:    options.OrderingKey,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error encoding event")
+	}
+
+	// the keys are ordered by the time the events were added, so they're published in order
+	key := fmt.Sprintf("%s%020d/%s", o.opts.Prefix, time.Now().UnixNano(), id)
+	return tx.Write(&store.Record{Key: key, Value: bytes})
+}
+
+// Flush publishes the events of the outbox in the order they were added, and deletes each one
+// once it's published. It stops at the first event which can't be published so the order is kept,
+// and returns the number of events published.
+func (o *Outbox) Flush() (int, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	recs, err := o.store().Read(o.opts.Prefix, store.ReadPrefix(), store.ReadFrom(o.opts.Database, o.opts.Table))
+	if err == store.ErrNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "Error reading the outbox")
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Key < recs[j].Key })
+
+	var count int
+	for _, rec := range recs {
+		var e entry
+		if err := json.Unmarshal(rec.Value, &e); err != nil {
+			// the event can never be published so it's dropped
+			logger.Errorf("Error decoding outbox entry %v, discarding: %v", rec.Key, err)
+		} else {
+			err := o.stream().Publish(e.Topic, e.Payload,
+				events.WithMetadata(e.Metadata),
+				events.WithTimestamp(e.Timestamp),
+				events.WithIdempotencyKey(e.IdempotencyKey),
+				events.WithOrderingKey(e.OrderingKey),
+			)
+			if err != nil {
+				return count, errors.Wrapf(err, "Error publishing outbox entry %v", rec.Key)
+			}
+			count++
+		}
+
+		if err := o.store().Delete(rec.Key, store.DeleteFrom(o.opts.Database, o.opts.Table)); err != nil {
+			return count, errors.Wrapf(err, "Error deleting outbox entry %v", rec.Key)
+		}
+	}
+	return count, nil
+}
+
+// Relay publishes the events of the outbox until the context is done. It's woken by transactions
+// adding events, and checks the outbox every Interval for the events of other processes and the
+// ones which failed to publish.
+func (o *Outbox) Relay(ctx context.Context) {
+	tick := time.NewTicker(o.opts.Interval)
+	defer tick.Stop()
+
+	for {
+		if _, err := o.Flush(); err != nil {
+			logger.Errorf("Error relaying outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		case <-o.notify:
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStream records the events published to it
+type testStream struct {
+	published []events.PublishOptions
+	payloads  []string
+	err       error
+}
+
+func (s *testStream) Publish(topic string, msg interface{}, opts ...events.PublishOption) error {
+	if s.err != nil {
+		return s.err
+	}
+	var options events.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	s.published = append(s.published, options)
+	s.payloads = append(s.payloads, topic+":"+string(msg.([]byte)))
+	return nil
+}
+
+func (s *testStream) Consume(topic string, opts ...events.ConsumeOption) (<-chan events.Event, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestOutbox(t *testing.T) {
+	st := memory.NewStore()
+	stream := &testStream{}
+	o := NewOutbox(WithStore(st), WithStream(stream), WithTable("db", "users"))
+	ctx := context.TODO()
+
+	t.Run("Write", func(t *testing.T) {
+		err := o.Write(ctx, &store.Record{Key: "john", Value: []byte("v1")}, "users", []byte("created"),
+			events.WithIdempotencyKey("created-john"))
+		assert.NoError(t, err)
+		recs, err := st.Read("john", store.ReadFrom("db", "users"))
+		assert.NoError(t, err)
+		assert.Len(t, recs, 1)
+
+		// the event isn't published until the outbox is flushed
+		assert.Empty(t, stream.payloads)
+		n, err := o.Flush()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"users:created"}, stream.payloads)
+		assert.Equal(t, "created-john", stream.published[0].IdempotencyKey)
+
+		// the outbox is empty once it's flushed
+		n, err = o.Flush()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		err := o.Txn(ctx, func(tx store.Tx) error {
+			assert.NoError(t, tx.Write(&store.Record{Key: "jane", Value: []byte("v1")}))
+			assert.NoError(t, o.Add(tx, "users", []byte("created")))
+			return errors.New("rollback")
+		})
+		assert.Error(t, err)
+		_, err = st.Read("jane", store.ReadFrom("db", "users"))
+		assert.Equal(t, store.ErrNotFound, err)
+		n, err := o.Flush()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		stream.payloads = nil
+		stream.published = nil
+		stream.err = errors.New("unavailable")
+		for _, msg := range []string{"one", "two", "three"} {
+			assert.NoError(t, o.Txn(ctx, func(tx store.Tx) error {
+				return o.Add(tx, "users", []byte(msg))
+			}))
+		}
+
+		// the events are kept until they're published
+		_, err := o.Flush()
+		assert.Error(t, err)
+		stream.err = nil
+		n, err := o.Flush()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []string{"users:one", "users:two", "users:three"}, stream.payloads)
+
+		// events without an idempotency key are given a unique one
+		assert.NotEmpty(t, stream.published[0].IdempotencyKey)
+		assert.NotEqual(t, stream.published[0].IdempotencyKey, stream.published[1].IdempotencyKey)
+	})
+
+	t.Run("Relay", func(t *testing.T) {
+		stream.payloads = nil
+		rctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go o.Relay(rctx)
+
+		// the relay is woken by the transaction rather than waiting for the interval
+		assert.NoError(t, o.Write(ctx, &store.Record{Key: "john", Value: []byte("v2")}, "users", []byte("updated")))
+		assert.Eventually(t, func() bool {
+			o.mtx.Lock()
+			defer o.mtx.Unlock()
+			return len(stream.payloads) == 1
+		}, 500*time.Millisecond, 10*time.Millisecond)
+	})
+}
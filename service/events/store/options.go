@@ -24,6 +24,27 @@ type Options struct {
 	Store  store.Store
 	TTL    time.Duration
 	Backup Backup
+	// DeduplicationWindow is how long the idempotency keys of published events are kept
+	DeduplicationWindow time.Duration
+	// Retention policies of the topics, topics without one use the DefaultRetention
+	Retention        map[string]Retention
+	DefaultRetention Retention
+	// PruneInterval is how often the events outside the retention policies are pruned
+	PruneInterval time.Duration
+}
+
+// Retention policy of the events of a topic, the oldest events of the topic are pruned once
+// any of the limits is exceeded. Zero values are unlimited.
+type Retention struct {
+	// MaxAge of the events
+	MaxAge time.Duration
+	// MaxEvents is the number of events kept
+	MaxEvents int
+	// MaxSize is the total size in bytes of the events kept
+	MaxSize int64
+	// Compact keeps only the latest event of each ordering key, so the topic can be read as a
+	// changelog. Events without an ordering key are kept.
+	Compact bool
 }
 
 type Option func(o *Options)
@@ -42,6 +63,39 @@ func WithTTL(ttl time.Duration) Option {
 	}
 }
 
+// WithDeduplicationWindow sets how long the idempotency keys of published events are kept, events
+// published with the same key within the window are dropped. Defaults to 10 minutes.
+func WithDeduplicationWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.DeduplicationWindow = d
+	}
+}
+
+// WithRetention sets the retention policy of a topic
+func WithRetention(topic string, r Retention) Option {
+	return func(o *Options) {
+		if o.Retention == nil {
+			o.Retention = map[string]Retention{}
+		}
+		o.Retention[topic] = r
+	}
+}
+
+// WithDefaultRetention sets the retention policy of the topics without their own
+func WithDefaultRetention(r Retention) Option {
+	return func(o *Options) {
+		o.DefaultRetention = r
+	}
+}
+
+// WithPruneInterval sets how often the events outside the retention policies are pruned,
+// defaults to 1 minute
+func WithPruneInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.PruneInterval = d
+	}
+}
+
 func WithBackup(back Backup) Option {
 	return func(o *Options) {
 		o.Backup = back
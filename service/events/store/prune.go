@@ -0,0 +1,107 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+)
+
+func (s *evStore) pruneLoop() {
+	for {
+		time.Sleep(s.opts.PruneInterval)
+
+		if err := s.prune(); err != nil {
+			logger.Errorf("Error pruning events %s", err)
+		}
+	}
+}
+
+// prune deletes the events outside the retention policies of their topics
+func (s *evStore) prune() error {
+	keys, err := s.opts.Store.List()
+	if err != nil {
+		return errors.Wrap(err, "Error listing the store")
+	}
+
+	// the keys of the events are prefixed with their topic
+	topics := map[string]bool{}
+	for _, k := range keys {
+		if strings.HasPrefix(k, dedupPrefix) {
+			continue
+		}
+		if i := strings.Index(k, joinKey); i > 0 {
+			topics[k[:i]] = true
+		}
+	}
+
+	for topic := range topics {
+		r, ok := s.opts.Retention[topic]
+		if !ok {
+			r = s.opts.DefaultRetention
+		}
+		if r == (Retention{}) {
+			continue
+		}
+		if err := s.pruneTopic(topic, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pruneTopic deletes the oldest events of the topic which exceed the limits of the retention policy
+func (s *evStore) pruneTopic(topic string, r Retention) error {
+	recs, err := s.opts.Store.Read(topic+joinKey, store.ReadPrefix())
+	if err != nil {
+		return errors.Wrap(err, "Error reading from store")
+	}
+
+	type event struct {
+		key         string
+		size        int64
+		timestamp   time.Time
+		orderingKey string
+	}
+	evs := make([]event, 0, len(recs))
+	for _, rec := range recs {
+		var e struct {
+			Timestamp   time.Time
+			OrderingKey string
+		}
+		if err := json.Unmarshal(rec.Value, &e); err != nil {
+			return errors.Wrap(err, "Invalid event returned from store")
+		}
+		evs = append(evs, event{key: rec.Key, size: int64(len(rec.Value)), timestamp: e.Timestamp, orderingKey: e.OrderingKey})
+	}
+
+	// keep the newest events within the limits
+	sort.Slice(evs, func(i, j int) bool { return evs[i].timestamp.After(evs[j].timestamp) })
+	var count int
+	var size int64
+	latest := map[string]bool{}
+	for _, e := range evs {
+		// compacted topics only keep the newest event of each key
+		superseded := r.Compact && len(e.orderingKey) > 0 && latest[e.orderingKey]
+		latest[e.orderingKey] = true
+		if !superseded {
+			count++
+			size += e.size
+		}
+		if !superseded && (r.MaxAge == 0 || time.Since(e.timestamp) <= r.MaxAge) &&
+			(r.MaxEvents == 0 || count <= r.MaxEvents) &&
+			(r.MaxSize == 0 || size <= r.MaxSize) {
+			continue
+		}
+		if err := s.opts.Store.Delete(e.key); err != nil {
+			return errors.Wrap(err, "Error deleting from store")
+		}
+	}
+
+	return nil
+}
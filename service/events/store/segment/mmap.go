@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package segment
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap maps the file into memory for reading
+func mmap(f *os.File, size int64) ([]byte, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	return syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmap unmaps a file mapped by mmap
+func munmap(b []byte) error {
+	if b == nil {
+		return nil
+	}
+	return syscall.Munmap(b)
+}
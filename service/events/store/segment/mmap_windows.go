@@ -0,0 +1,20 @@
+package segment
+
+import (
+	"io"
+	"os"
+)
+
+// mmap reads the file into memory, sealed segments aren't mapped on windows
+func mmap(f *os.File, size int64) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := f.ReadAt(b, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return b, nil
+}
+
+// munmap releases a file read by mmap
+func munmap(b []byte) error {
+	return nil
+}
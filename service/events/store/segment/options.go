@@ -0,0 +1,88 @@
+package segment
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/micro/micro/v3/service/events/store"
+)
+
+var (
+	// DefaultDir is the default directory of the logs
+	DefaultDir = filepath.Join(os.TempDir(), "micro", "events")
+	// DefaultSegmentSize is the size in bytes at which the active segment of a topic is rotated
+	DefaultSegmentSize int64 = 64 * 1024 * 1024
+)
+
+type Options struct {
+	// Dir holds a directory of segments for each topic
+	Dir string
+	// SegmentSize is the size in bytes at which the active segment of a topic is rotated
+	SegmentSize int64
+	TTL         time.Duration
+	// DeduplicationWindow is how long the idempotency keys of published events are kept
+	DeduplicationWindow time.Duration
+	// Retention policies of the topics, topics without one use the DefaultRetention
+	Retention        map[string]store.Retention
+	DefaultRetention store.Retention
+	// PruneInterval is how often the segments outside the retention policies are pruned
+	PruneInterval time.Duration
+}
+
+type Option func(o *Options)
+
+// WithDir sets the directory of the logs
+func WithDir(dir string) Option {
+	return func(o *Options) {
+		o.Dir = dir
+	}
+}
+
+// WithSegmentSize sets the size in bytes at which the active segment of a topic is rotated,
+// defaults to 64MB
+func WithSegmentSize(size int64) Option {
+	return func(o *Options) {
+		o.SegmentSize = size
+	}
+}
+
+// WithTTL sets the default TTL
+func WithTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = ttl
+	}
+}
+
+// WithDeduplicationWindow sets how long the idempotency keys of published events are kept, events
+// published with the same key within the window are dropped. Defaults to 10 minutes.
+func WithDeduplicationWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.DeduplicationWindow = d
+	}
+}
+
+// WithRetention sets the retention policy of a topic
+func WithRetention(topic string, r store.Retention) Option {
+	return func(o *Options) {
+		if o.Retention == nil {
+			o.Retention = map[string]store.Retention{}
+		}
+		o.Retention[topic] = r
+	}
+}
+
+// WithDefaultRetention sets the retention policy of the topics without their own
+func WithDefaultRetention(r store.Retention) Option {
+	return func(o *Options) {
+		o.DefaultRetention = r
+	}
+}
+
+// WithPruneInterval sets how often the segments outside the retention policies are pruned,
+// defaults to 1 minute
+func WithPruneInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.PruneInterval = d
+	}
+}
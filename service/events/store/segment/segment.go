@@ -0,0 +1,294 @@
+package segment
+
+import (
+	"encoding/binary"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	logExt   = ".log"
+	indexExt = ".idx"
+	// compactSuffix is the suffix of the path of a segment being rewritten by compaction
+	compactSuffix = ".compact"
+	// entrySize is the size in bytes of an entry of an index
+	entrySize = 48
+)
+
+// entry of the index of a segment, locating an event in the log of the segment
+type entry struct {
+	// seq is the sequence number of the event in its topic
+	seq uint64
+	// pos and size of the event in the log
+	pos  uint64
+	size uint32
+	// timestamp of the event and the time it expires at, in unix nanoseconds. A zero expiry
+	// never expires.
+	timestamp int64
+	expiry    int64
+	// key is the hash of the ordering key of the event, zero if it has none
+	key uint64
+}
+
+func (e entry) marshal(b []byte) {
+	binary.BigEndian.PutUint64(b[0:], e.seq)
+	binary.BigEndian.PutUint64(b[8:], e.pos)
+	binary.BigEndian.PutUint32(b[16:], e.size)
+	binary.BigEndian.PutUint32(b[20:], 0) // reserved
+	binary.BigEndian.PutUint64(b[24:], uint64(e.timestamp))
+	binary.BigEndian.PutUint64(b[32:], uint64(e.expiry))
+	binary.BigEndian.PutUint64(b[40:], e.key)
+}
+
+func unmarshalEntry(b []byte) entry {
+	return entry{
+		seq:       binary.BigEndian.Uint64(b[0:]),
+		pos:       binary.BigEndian.Uint64(b[8:]),
+		size:      binary.BigEndian.Uint32(b[16:]),
+		timestamp: int64(binary.BigEndian.Uint64(b[24:])),
+		expiry:    int64(binary.BigEndian.Uint64(b[32:])),
+		key:       binary.BigEndian.Uint64(b[40:]),
+	}
+}
+
+// expired returns true if the event expired before now
+func (e entry) expired(now int64) bool {
+	return e.expiry > 0 && e.expiry <= now
+}
+
+// segment of the log of a topic. Events are appended to the log of the active segment and
+// located by its index, which is kept in memory. Once the active segment is rotated it's sealed
+// and both files are mapped into memory for reading.
+type segment struct {
+	// path of the segment, without the extensions of its files
+	path string
+	size int64
+
+	// the files and index of the active segment
+	log     *os.File
+	idx     *os.File
+	entries []entry
+
+	// the mapped files of a sealed segment
+	sealed bool
+	data   []byte
+	index  []byte
+}
+
+// openSegment opens the active segment at the path, creating it if it doesn't exist. Entries
+// partially written when the process last stopped are truncated.
+func openSegment(path string) (*segment, error) {
+	s := &segment{path: path}
+	var err error
+	if s.log, err = os.OpenFile(path+logExt, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return nil, errors.Wrap(err, "Error opening segment")
+	}
+	if s.idx, err = os.OpenFile(path+indexExt, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		s.log.Close()
+		return nil, errors.Wrap(err, "Error opening segment index")
+	}
+
+	logInfo, err := s.log.Stat()
+	if err != nil {
+		s.close()
+		return nil, errors.Wrap(err, "Error opening segment")
+	}
+	idxInfo, err := s.idx.Stat()
+	if err != nil {
+		s.close()
+		return nil, errors.Wrap(err, "Error opening segment index")
+	}
+	b := make([]byte, idxInfo.Size()-idxInfo.Size()%entrySize)
+	if _, err := s.idx.ReadAt(b, 0); err != nil {
+		s.close()
+		return nil, errors.Wrap(err, "Error reading segment index")
+	}
+	for i := 0; i < len(b); i += entrySize {
+		e := unmarshalEntry(b[i:])
+		if int64(e.pos)+int64(e.size) > logInfo.Size() {
+			break
+		}
+		s.entries = append(s.entries, e)
+		s.size = int64(e.pos) + int64(e.size)
+	}
+
+	if err := s.log.Truncate(s.size); err != nil {
+		s.close()
+		return nil, errors.Wrap(err, "Error truncating segment")
+	}
+	if err := s.idx.Truncate(int64(len(s.entries) * entrySize)); err != nil {
+		s.close()
+		return nil, errors.Wrap(err, "Error truncating segment index")
+	}
+	return s, nil
+}
+
+// openSealedSegment opens a sealed segment at the path, mapping its files into memory
+func openSealedSegment(path string) (*segment, error) {
+	s := &segment{path: path, sealed: true}
+	log, err := os.Open(path + logExt)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error opening segment")
+	}
+	defer log.Close()
+	idx, err := os.Open(path + indexExt)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error opening segment index")
+	}
+	defer idx.Close()
+	return s, s.mmap(log, idx)
+}
+
+// mmap maps the files of the segment into memory
+func (s *segment) mmap(log, idx *os.File) error {
+	logInfo, err := log.Stat()
+	if err != nil {
+		return errors.Wrap(err, "Error opening segment")
+	}
+	idxInfo, err := idx.Stat()
+	if err != nil {
+		return errors.Wrap(err, "Error opening segment index")
+	}
+	s.size = logInfo.Size()
+	if s.data, err = mmap(log, logInfo.Size()); err != nil {
+		return errors.Wrap(err, "Error mapping segment")
+	}
+	if s.index, err = mmap(idx, idxInfo.Size()-idxInfo.Size()%entrySize); err != nil {
+		munmap(s.data)
+		return errors.Wrap(err, "Error mapping segment index")
+	}
+	return nil
+}
+
+// seal the active segment, mapping its files into memory once no more events are appended
+func (s *segment) seal() error {
+	if err := s.mmap(s.log, s.idx); err != nil {
+		return err
+	}
+	s.log.Close()
+	s.idx.Close()
+	s.log, s.idx, s.entries = nil, nil, nil
+	s.sealed = true
+	return nil
+}
+
+// len returns the number of entries in the segment
+func (s *segment) len() int {
+	if s.sealed {
+		return len(s.index) / entrySize
+	}
+	return len(s.entries)
+}
+
+// entry returns the entry of the index at i
+func (s *segment) entry(i int) entry {
+	if s.sealed {
+		return unmarshalEntry(s.index[i*entrySize:])
+	}
+	return s.entries[i]
+}
+
+// read returns the event located by the entry. The event read from a sealed segment is only
+// valid until the segment is closed.
+func (s *segment) read(e entry) ([]byte, error) {
+	if s.sealed {
+		return s.data[e.pos : e.pos+uint64(e.size)], nil
+	}
+	b := make([]byte, e.size)
+	if _, err := s.log.ReadAt(b, int64(e.pos)); err != nil {
+		return nil, errors.Wrap(err, "Error reading segment")
+	}
+	return b, nil
+}
+
+// append an event to the active segment
+func (s *segment) append(e entry, data []byte) error {
+	e.pos = uint64(s.size)
+	e.size = uint32(len(data))
+	if _, err := s.log.WriteAt(data, s.size); err != nil {
+		return errors.Wrap(err, "Error writing segment")
+	}
+	b := make([]byte, entrySize)
+	e.marshal(b)
+	if _, err := s.idx.WriteAt(b, int64(len(s.entries)*entrySize)); err != nil {
+		return errors.Wrap(err, "Error writing segment index")
+	}
+	s.entries = append(s.entries, e)
+	s.size += int64(len(data))
+	return nil
+}
+
+// rewrite the sealed segment with only the entries kept, replacing its files. The segment is
+// closed once it's replaced by the returned segment.
+func (s *segment) rewrite(keep func(entry) bool) (*segment, error) {
+	tmp := s.path + compactSuffix
+	os.Remove(tmp + logExt)
+	os.Remove(tmp + indexExt)
+	c, err := openSegment(tmp)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < s.len(); i++ {
+		e := s.entry(i)
+		if !keep(e) {
+			continue
+		}
+		b, _ := s.read(e)
+		if err := c.append(e, b); err != nil {
+			c.remove()
+			return nil, err
+		}
+	}
+	if err := c.seal(); err != nil {
+		c.remove()
+		return nil, err
+	}
+
+	// the files are replaced before the segment is closed so it's still valid if they can't be
+	if err := os.Rename(tmp+indexExt, s.path+indexExt); err != nil {
+		c.remove()
+		return nil, errors.Wrap(err, "Error replacing segment index")
+	}
+	if err := os.Rename(tmp+logExt, s.path+logExt); err != nil {
+		c.remove()
+		return nil, errors.Wrap(err, "Error replacing segment")
+	}
+	c.path = s.path
+	s.close()
+	return c, nil
+}
+
+// close the files of the segment
+func (s *segment) close() error {
+	if s.sealed {
+		err := munmap(s.data)
+		if err2 := munmap(s.index); err == nil {
+			err = err2
+		}
+		s.data, s.index = nil, nil
+		return err
+	}
+	var err error
+	if s.log != nil {
+		err = s.log.Close()
+	}
+	if s.idx != nil {
+		if err2 := s.idx.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// remove the files of the segment
+func (s *segment) remove() error {
+	s.close()
+	if err := os.Remove(s.path + logExt); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Error removing segment")
+	}
+	if err := os.Remove(s.path + indexExt); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Error removing segment index")
+	}
+	return nil
+}
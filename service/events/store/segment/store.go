@@ -0,0 +1,246 @@
+// Package segment is an events store which keeps the events of each topic in an append-only log
+package segment
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/pkg/errors"
+)
+
+// NewStore returns an events store which appends the events of each topic to a log in its own
+// directory. The log is split into segments, each with an index locating its events, which are
+// rotated once they reach the segment size. Sealed segments are mapped into memory, so events
+// are replayed without reading the whole log, and pruned whole by the retention policies.
+func NewStore(opts ...Option) (events.Store, error) {
+	// parse the options
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Dir) == 0 {
+		options.Dir = DefaultDir
+	}
+	if options.SegmentSize == 0 {
+		options.SegmentSize = DefaultSegmentSize
+	}
+	if options.TTL.Seconds() == 0 {
+		options.TTL = time.Hour * 24
+	}
+	if options.DeduplicationWindow == 0 {
+		options.DeduplicationWindow = 10 * time.Minute
+	}
+	if options.PruneInterval == 0 {
+		options.PruneInterval = time.Minute
+	}
+
+	if err := os.MkdirAll(options.Dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "Error creating events directory")
+	}
+
+	s := &segmentStore{
+		opts:      options,
+		topics:    map[string]*topic{},
+		published: map[string]time.Time{},
+	}
+	go s.pruneLoop()
+	return s, nil
+}
+
+type segmentStore struct {
+	opts Options
+
+	sync.Mutex
+	topics map[string]*topic
+	// published holds the expiry of the idempotency keys of the published events, they're not
+	// persisted so events published before a restart aren't deduplicated
+	published map[string]time.Time
+}
+
+// topicDir returns the directory of the topic, escaping the topic so it's a single directory
+func (s *segmentStore) topicDir(name string) string {
+	dir := url.PathEscape(name)
+	if dir == "." || dir == ".." {
+		dir = strings.Replace(dir, ".", "%2E", -1)
+	}
+	return filepath.Join(s.opts.Dir, dir)
+}
+
+// topic returns the log of the topic, opening it if it isn't open. Nil is returned if the topic
+// has no log and create is false.
+func (s *segmentStore) topic(name string, create bool) (*topic, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if t, ok := s.topics[name]; ok {
+		return t, nil
+	}
+	dir := s.topicDir(name)
+	if _, err := os.Stat(dir); os.IsNotExist(err) && !create {
+		return nil, nil
+	}
+	t, err := openTopic(dir, s.opts.SegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	s.topics[name] = t
+	return t, nil
+}
+
+// Read events for a topic
+func (s *segmentStore) Read(topic string, opts ...events.ReadOption) ([]*events.Event, error) {
+	// validate the topic
+	if len(topic) == 0 {
+		return nil, events.ErrMissingTopic
+	}
+
+	// parse the options
+	options := events.ReadOptions{
+		Offset: 0,
+		Limit:  250,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	t, err := s.topic(topic, false)
+	if err != nil {
+		return nil, err
+	} else if t == nil {
+		return []*events.Event{}, nil
+	}
+	return t.read(int(options.Offset), int(options.Limit))
+}
+
+// Write an event to the log of its topic
+func (s *segmentStore) Write(event *events.Event, opts ...events.WriteOption) error {
+	// parse the options
+	options := events.WriteOptions{
+		TTL: s.opts.TTL,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "Error mashaling event to JSON")
+	}
+
+	now := time.Now()
+	e := entry{timestamp: event.Timestamp.UnixNano()}
+	if event.Timestamp.IsZero() {
+		e.timestamp = now.UnixNano()
+	}
+	if options.TTL > 0 {
+		e.expiry = now.Add(options.TTL).UnixNano()
+	}
+	if len(event.OrderingKey) > 0 {
+		h := fnv.New64a()
+		h.Write([]byte(event.OrderingKey))
+		// zero is reserved for events without an ordering key
+		if e.key = h.Sum64(); e.key == 0 {
+			e.key = 1
+		}
+	}
+
+	t, err := s.topic(event.Topic, true)
+	if err != nil {
+		return err
+	}
+	return t.append(e, bytes)
+}
+
+// Delete an event from a topic. The event is recorded as deleted and removed from the log when
+// its segment is pruned or compacted.
+func (s *segmentStore) Delete(topic, id string) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	t, err := s.topic(topic, false)
+	if err != nil || t == nil {
+		return err
+	}
+	return t.delete(id)
+}
+
+// Published returns true if an event with the idempotency key was published to the topic within
+// the deduplication window
+func (s *segmentStore) Published(topic, key string) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	expiry, ok := s.published[topic+"/"+key]
+	return ok && time.Now().Before(expiry), nil
+}
+
+// RecordPublished records the idempotency key of an event published to the topic for the
+// deduplication window
+func (s *segmentStore) RecordPublished(topic, key string) error {
+	s.Lock()
+	defer s.Unlock()
+	s.published[topic+"/"+key] = time.Now().Add(s.opts.DeduplicationWindow)
+	return nil
+}
+
+func (s *segmentStore) pruneLoop() {
+	for {
+		time.Sleep(s.opts.PruneInterval)
+
+		if err := s.prune(); err != nil {
+			logger.Errorf("Error pruning events %s", err)
+		}
+	}
+}
+
+// prune the segments outside the retention policies of their topics and the expired
+// idempotency keys
+func (s *segmentStore) prune() error {
+	s.Lock()
+	now := time.Now()
+	for k, expiry := range s.published {
+		if now.After(expiry) {
+			delete(s.published, k)
+		}
+	}
+	s.Unlock()
+
+	files, err := ioutil.ReadDir(s.opts.Dir)
+	if err != nil {
+		return errors.Wrap(err, "Error reading events directory")
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		name, err := url.PathUnescape(f.Name())
+		if err != nil {
+			continue
+		}
+		t, err := s.topic(name, false)
+		if err != nil {
+			return err
+		} else if t == nil {
+			continue
+		}
+
+		r, ok := s.opts.Retention[name]
+		if !ok {
+			r = s.opts.DefaultRetention
+		}
+		if err := t.prune(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
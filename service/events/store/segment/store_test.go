@@ -0,0 +1,156 @@
+package segment
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/events/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	st, err := NewStore(WithDir(dir), WithSegmentSize(1024))
+	assert.NoError(t, err)
+
+	var ids []string
+	for i := 0; i < 100; i++ {
+		ev := &events.Event{ID: uuid.New().String(), Topic: "foo", Timestamp: time.Now()}
+		assert.NoError(t, st.Write(ev))
+		ids = append(ids, ev.ID)
+	}
+	assert.NoError(t, st.Write(&events.Event{ID: uuid.New().String(), Topic: "bar"}))
+	assert.NoError(t, st.Write(&events.Event{ID: uuid.New().String(), Topic: "expired"}, events.WithTTL(time.Nanosecond)))
+
+	t.Run("ReadMissingTopic", func(t *testing.T) {
+		evs, err := st.Read("")
+		assert.Equal(t, events.ErrMissingTopic, err)
+		assert.Nil(t, evs)
+	})
+
+	t.Run("ReadTopic", func(t *testing.T) {
+		evs, err := st.Read("foo", events.ReadLimit(0))
+		assert.NoError(t, err)
+		if assert.Len(t, evs, 100) {
+			for i, ev := range evs {
+				assert.Equal(t, ids[i], ev.ID)
+			}
+		}
+		evs, err = st.Read("missing")
+		assert.NoError(t, err)
+		assert.Empty(t, evs)
+		evs, err = st.Read("expired")
+		assert.NoError(t, err)
+		assert.Empty(t, evs)
+	})
+
+	// the log of the topic is rotated once the active segment reaches the segment size
+	t.Run("Rotation", func(t *testing.T) {
+		segments, err := filepath.Glob(filepath.Join(dir, "foo", "*"+logExt))
+		assert.NoError(t, err)
+		assert.Greater(t, len(segments), 2)
+	})
+
+	t.Run("ReadLimitOffset", func(t *testing.T) {
+		evs, err := st.Read("foo", events.ReadLimit(10), events.ReadOffset(45))
+		assert.NoError(t, err)
+		if assert.Len(t, evs, 10) {
+			assert.Equal(t, ids[45], evs[0].ID)
+			assert.Equal(t, ids[54], evs[9].ID)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, st.(events.Deleter).Delete("foo", ids[0]))
+		assert.NoError(t, st.(events.Deleter).Delete("foo", ids[99]))
+		evs, err := st.Read("foo", events.ReadLimit(0))
+		assert.NoError(t, err)
+		if assert.Len(t, evs, 98) {
+			assert.Equal(t, ids[1], evs[0].ID)
+			assert.Equal(t, ids[98], evs[97].ID)
+		}
+	})
+
+	t.Run("Published", func(t *testing.T) {
+		ok, err := st.(events.Deduplicator).Published("foo", "key")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, st.(events.Deduplicator).RecordPublished("foo", "key"))
+		ok, err = st.(events.Deduplicator).Published("foo", "key")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	// the logs are read back when the store is reopened
+	t.Run("Reopen", func(t *testing.T) {
+		st2, err := NewStore(WithDir(dir), WithSegmentSize(1024))
+		assert.NoError(t, err)
+		evs, err := st2.Read("foo", events.ReadLimit(0))
+		assert.NoError(t, err)
+		assert.Len(t, evs, 98)
+
+		ev := &events.Event{ID: uuid.New().String(), Topic: "foo"}
+		assert.NoError(t, st2.Write(ev))
+		evs, err = st2.Read("foo", events.ReadOffset(98))
+		assert.NoError(t, err)
+		if assert.Len(t, evs, 1) {
+			assert.Equal(t, ev.ID, evs[0].ID)
+		}
+	})
+}
+
+func TestPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "segment")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	st, err := NewStore(
+		WithDir(dir),
+		WithSegmentSize(512),
+		WithPruneInterval(time.Hour),
+		WithRetention("orders", store.Retention{MaxEvents: 20}),
+		WithRetention("users", store.Retention{Compact: true}),
+	)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, st.Write(&events.Event{ID: fmt.Sprint(i), Topic: "orders"}))
+		assert.NoError(t, st.Write(&events.Event{ID: fmt.Sprint(i), Topic: "users", OrderingKey: fmt.Sprint(i % 3)}))
+	}
+	assert.NoError(t, st.Write(&events.Event{ID: "unkeyed", Topic: "users"}))
+	assert.NoError(t, st.(*segmentStore).prune())
+
+	t.Run("MaxEvents", func(t *testing.T) {
+		evs, err := st.Read("orders", events.ReadLimit(0))
+		assert.NoError(t, err)
+		// whole segments are pruned so the newest events are kept
+		assert.GreaterOrEqual(t, len(evs), 20)
+		assert.Less(t, len(evs), 100)
+		assert.Equal(t, "99", evs[len(evs)-1].ID)
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		evs, err := st.Read("users", events.ReadLimit(0))
+		assert.NoError(t, err)
+
+		// only the active segment may hold superseded events
+		seen := map[string]bool{}
+		for _, ev := range evs {
+			seen[ev.ID] = true
+		}
+		for _, id := range []string{"97", "98", "99", "unkeyed"} {
+			assert.True(t, seen[id], "The latest event of each key should be kept")
+		}
+		assert.False(t, seen["0"], "Superseded events should be compacted")
+		assert.Less(t, len(evs), 50)
+	})
+}
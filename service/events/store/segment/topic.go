@@ -0,0 +1,374 @@
+package segment
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/events/store"
+	"github.com/pkg/errors"
+)
+
+// tombstonesFile records the sequence numbers of the deleted events of a topic
+const tombstonesFile = "deleted"
+
+// topic is the log of the events of a topic, split into segments named by the sequence number
+// of their first event
+type topic struct {
+	sync.RWMutex
+	dir         string
+	segmentSize int64
+
+	// segments of the log, oldest first. The last segment is active.
+	segments []*segment
+	// next is the sequence number of the next event appended
+	next uint64
+	// deleted holds the sequence numbers of the deleted events until they're pruned
+	deleted    map[uint64]bool
+	tombstones *os.File
+}
+
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d", seq))
+}
+
+// openTopic opens the log in the directory, creating it if it doesn't exist
+func openTopic(dir string, segmentSize int64) (*topic, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "Error creating topic directory")
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading topic directory")
+	}
+
+	// the segments are sorted by name, which is padded so that it sorts by sequence number
+	var paths []string
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), logExt)
+		if name == f.Name() {
+			continue
+		}
+		if _, err := strconv.ParseUint(name, 10, 64); err == nil {
+			paths = append(paths, filepath.Join(dir, name))
+		} else if strings.HasSuffix(name, compactSuffix) {
+			// remove the segment of a compaction which didn't complete
+			os.Remove(filepath.Join(dir, name+logExt))
+			os.Remove(filepath.Join(dir, name+indexExt))
+		}
+	}
+	if len(paths) == 0 {
+		paths = append(paths, segmentPath(dir, 1))
+	}
+
+	t := &topic{dir: dir, segmentSize: segmentSize, next: 1, deleted: map[uint64]bool{}}
+	for i, path := range paths {
+		var s *segment
+		if i < len(paths)-1 {
+			s, err = openSealedSegment(path)
+		} else {
+			s, err = openSegment(path)
+		}
+		if err != nil {
+			t.close()
+			return nil, err
+		}
+		t.segments = append(t.segments, s)
+	}
+	for i := len(t.segments) - 1; i >= 0; i-- {
+		if n := t.segments[i].len(); n > 0 {
+			t.next = t.segments[i].entry(n-1).seq + 1
+			break
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, tombstonesFile))
+	if err != nil && !os.IsNotExist(err) {
+		t.close()
+		return nil, errors.Wrap(err, "Error reading tombstones")
+	}
+	for i := 0; i+8 <= len(b); i += 8 {
+		t.deleted[binary.BigEndian.Uint64(b[i:])] = true
+	}
+	if err := t.writeTombstones(); err != nil {
+		t.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// dead returns true if the event located by the entry was deleted or expired
+func (t *topic) dead(e entry, now int64) bool {
+	return e.expired(now) || t.deleted[e.seq]
+}
+
+// append an event to the active segment, rotating it once it reaches the segment size
+func (t *topic) append(e entry, data []byte) error {
+	t.Lock()
+	defer t.Unlock()
+
+	active := t.segments[len(t.segments)-1]
+	if active.size > 0 && active.size+int64(len(data)) > t.segmentSize {
+		s, err := openSegment(segmentPath(t.dir, t.next))
+		if err != nil {
+			return err
+		}
+		if err := active.seal(); err != nil {
+			s.remove()
+			return err
+		}
+		t.segments = append(t.segments, s)
+		active = s
+	}
+
+	e.seq = t.next
+	if err := active.append(e, data); err != nil {
+		return err
+	}
+	t.next++
+	return nil
+}
+
+// read the events of the topic, oldest first. Only the entries of the events returned are
+// unmarshaled, the others are skipped using the indexes.
+func (t *topic) read(offset, limit int) ([]*events.Event, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	now := time.Now().UnixNano()
+	result := []*events.Event{}
+	for _, s := range t.segments {
+		for i := 0; i < s.len(); i++ {
+			if limit > 0 && len(result) >= limit {
+				return result, nil
+			}
+			e := s.entry(i)
+			if t.dead(e, now) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			b, err := s.read(e)
+			if err != nil {
+				return nil, err
+			}
+			var ev events.Event
+			if err := json.Unmarshal(b, &ev); err != nil {
+				return nil, errors.Wrap(err, "Invalid event returned from segment")
+			}
+			result = append(result, &ev)
+		}
+	}
+	return result, nil
+}
+
+// delete the events with the ID, recording their sequence numbers in the tombstones file
+func (t *topic) delete(id string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, s := range t.segments {
+		for i := 0; i < s.len(); i++ {
+			e := s.entry(i)
+			if t.deleted[e.seq] {
+				continue
+			}
+			b, err := s.read(e)
+			if err != nil {
+				return err
+			}
+			var ev struct{ ID string }
+			if err := json.Unmarshal(b, &ev); err != nil {
+				return errors.Wrap(err, "Invalid event returned from segment")
+			}
+			if ev.ID != id {
+				continue
+			}
+
+			seq := make([]byte, 8)
+			binary.BigEndian.PutUint64(seq, e.seq)
+			if _, err := t.tombstones.Write(seq); err != nil {
+				return errors.Wrap(err, "Error writing tombstones")
+			}
+			t.deleted[e.seq] = true
+		}
+	}
+	return nil
+}
+
+// prune the sealed segments outside the retention policy, oldest first. Whole segments are
+// pruned so the topic keeps up to a segment more than the limits of the policy. The segments
+// of compacted topics are then rewritten without the events superseded by a newer event with
+// the same ordering key.
+func (t *topic) prune(r store.Retention) error {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now().UnixNano()
+	var count int
+	var size int64
+	for _, s := range t.segments {
+		count += s.len()
+		size += s.size
+	}
+	for len(t.segments) > 1 && t.prunable(t.segments[0], r, count, size, now) {
+		s := t.segments[0]
+		count -= s.len()
+		size -= s.size
+		if err := s.remove(); err != nil {
+			return err
+		}
+		t.segments = t.segments[1:]
+	}
+
+	if r.Compact {
+		if err := t.compact(now); err != nil {
+			return err
+		}
+	}
+	return t.purgeTombstones()
+}
+
+// prunable returns true if the segment can be pruned from the topic with the count and size
+func (t *topic) prunable(s *segment, r store.Retention, count int, size int64, now int64) bool {
+	n := s.len()
+	if n == 0 {
+		return true
+	}
+	if r.MaxEvents > 0 && count-n >= r.MaxEvents {
+		return true
+	}
+	if r.MaxSize > 0 && size-s.size >= r.MaxSize {
+		return true
+	}
+	if r.MaxAge > 0 && s.entry(n-1).timestamp < now-int64(r.MaxAge) {
+		return true
+	}
+	for i := 0; i < n; i++ {
+		if !t.dead(s.entry(i), now) {
+			return false
+		}
+	}
+	return true
+}
+
+// compact rewrites the sealed segments holding events which are superseded, deleted or expired.
+// The active segment is compacted once it's sealed.
+func (t *topic) compact(now int64) error {
+	latest := map[uint64]uint64{}
+	for _, s := range t.segments {
+		for i := 0; i < s.len(); i++ {
+			if e := s.entry(i); e.key != 0 {
+				latest[e.key] = e.seq
+			}
+		}
+	}
+	keep := func(e entry) bool {
+		return !t.dead(e, now) && (e.key == 0 || latest[e.key] == e.seq)
+	}
+
+	segments := make([]*segment, 0, len(t.segments))
+	for i, s := range t.segments {
+		if i == len(t.segments)-1 {
+			segments = append(segments, s)
+			break
+		}
+
+		var n int
+		for j := 0; j < s.len(); j++ {
+			if keep(s.entry(j)) {
+				n++
+			}
+		}
+		if n == s.len() {
+			segments = append(segments, s)
+			continue
+		}
+
+		var err error
+		if n == 0 {
+			err = s.remove()
+		} else if s, err = s.rewrite(keep); err == nil {
+			segments = append(segments, s)
+		}
+		if err != nil {
+			t.segments = append(segments, t.segments[i:]...)
+			return err
+		}
+	}
+	t.segments = segments
+	return nil
+}
+
+// purgeTombstones removes the sequence numbers of the events no longer in the log from the
+// tombstones file
+func (t *topic) purgeTombstones() error {
+	if len(t.deleted) == 0 {
+		return nil
+	}
+	deleted := map[uint64]bool{}
+	for _, s := range t.segments {
+		for i := 0; i < s.len(); i++ {
+			if e := s.entry(i); t.deleted[e.seq] {
+				deleted[e.seq] = true
+			}
+		}
+	}
+	if len(deleted) == len(t.deleted) {
+		return nil
+	}
+	t.deleted = deleted
+	return t.writeTombstones()
+}
+
+// writeTombstones replaces the tombstones file with the deleted sequence numbers and opens it
+// for appending
+func (t *topic) writeTombstones() error {
+	b := make([]byte, 0, len(t.deleted)*8)
+	for seq := range t.deleted {
+		b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[len(b)-8:], seq)
+	}
+	path := filepath.Join(t.dir, tombstonesFile)
+	if err := ioutil.WriteFile(path+".tmp", b, 0644); err != nil {
+		return errors.Wrap(err, "Error writing tombstones")
+	}
+	if t.tombstones != nil {
+		t.tombstones.Close()
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return errors.Wrap(err, "Error writing tombstones")
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Error opening tombstones")
+	}
+	t.tombstones = f
+	return nil
+}
+
+// close the segments and tombstones file of the topic
+func (t *topic) close() error {
+	var err error
+	for _, s := range t.segments {
+		if err2 := s.close(); err == nil {
+			err = err2
+		}
+	}
+	if t.tombstones != nil {
+		if err2 := t.tombstones.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
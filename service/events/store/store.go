@@ -25,7 +25,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-const joinKey = "/"
+const (
+	joinKey = "/"
+	// dedupPrefix prefixes the keys of the idempotency keys of published events, the null byte
+	// keeps them apart from the events of the topics
+	dedupPrefix = "\x00dedup" + joinKey
+)
 
 // NewStore returns an initialized events store
 func NewStore(opts ...Option) events.Store {
@@ -37,6 +42,12 @@ func NewStore(opts ...Option) events.Store {
 	if options.TTL.Seconds() == 0 {
 		options.TTL = time.Hour * 24
 	}
+	if options.DeduplicationWindow == 0 {
+		options.DeduplicationWindow = 10 * time.Minute
+	}
+	if options.PruneInterval == 0 {
+		options.PruneInterval = time.Minute
+	}
 	if options.Store == nil {
 		options.Store = memory.NewStore()
 	}
@@ -46,6 +57,9 @@ func NewStore(opts ...Option) events.Store {
 	if options.Backup != nil {
 		go evs.backupLoop()
 	}
+	if len(options.Retention) > 0 || options.DefaultRetention != (Retention{}) {
+		go evs.pruneLoop()
+	}
 	return evs
 }
 
@@ -125,6 +139,52 @@ func (s *evStore) Write(event *events.Event, opts ...events.WriteOption) error {
 	return nil
 }
 
+// Delete an event from a topic
+func (s *evStore) Delete(topic, id string) error {
+	// validate the topic
+	if len(topic) == 0 {
+		return events.ErrMissingTopic
+	}
+
+	// the key of the event is suffixed with the hour it was written
+	keys, err := s.opts.Store.List(store.ListPrefix(topic + joinKey + id + joinKey))
+	if err != nil {
+		return errors.Wrap(err, "Error reading from store")
+	}
+	for _, k := range keys {
+		if err := s.opts.Store.Delete(k); err != nil {
+			return errors.Wrap(err, "Error deleting from store")
+		}
+	}
+
+	return nil
+}
+
+// Published returns true if an event with the idempotency key was published to the topic within
+// the deduplication window
+func (s *evStore) Published(topic, key string) (bool, error) {
+	_, err := s.opts.Store.Read(dedupPrefix + topic + joinKey + key)
+	if err == store.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "Error reading from store")
+	}
+	return true, nil
+}
+
+// RecordPublished records the idempotency key of an event published to the topic for the
+// deduplication window
+func (s *evStore) RecordPublished(topic, key string) error {
+	record := &store.Record{
+		Key:    dedupPrefix + topic + joinKey + key,
+		Expiry: s.opts.DeduplicationWindow,
+	}
+	if err := s.opts.Store.Write(record); err != nil {
+		return errors.Wrap(err, "Error writing to the store")
+	}
+	return nil
+}
+
 func (s *evStore) backupLoop() {
 	for {
 		err := s.opts.Backup.Snapshot(s.opts.Store)
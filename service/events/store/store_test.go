@@ -16,6 +16,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/micro/micro/v3/service/events"
@@ -59,4 +60,89 @@ func TestStore(t *testing.T) {
 		assert.Nilf(t, err, "No error should be returned")
 		assert.Len(t, evs, 1, "The result should include no more than the read limit")
 	})
+
+	// deleted events should no longer be read
+	t.Run("Delete", func(t *testing.T) {
+		err := store.(events.Deleter).Delete("foo", testData[0].ID)
+		assert.Nilf(t, err, "Deleting an event should not return an error")
+
+		evs, err := store.Read("foo")
+		assert.Nilf(t, err, "No error should be returned")
+		assert.Len(t, evs, 1, "The deleted event should not be returned")
+		assert.Equal(t, testData[1].ID, evs[0].ID)
+	})
+
+	// idempotency keys should only be published once per topic
+	t.Run("Deduplicate", func(t *testing.T) {
+		dedup := store.(events.Deduplicator)
+		published, err := dedup.Published("foo", "key")
+		assert.Nilf(t, err, "No error should be returned")
+		assert.False(t, published, "The key should not have been published")
+
+		assert.Nilf(t, dedup.RecordPublished("foo", "key"), "Recording a key should not return an error")
+		published, err = dedup.Published("foo", "key")
+		assert.Nilf(t, err, "No error should be returned")
+		assert.True(t, published, "The key should have been published")
+
+		published, err = dedup.Published("bar", "key")
+		assert.Nilf(t, err, "No error should be returned")
+		assert.False(t, published, "The key should only have been published to its topic")
+	})
+}
+
+func TestRetention(t *testing.T) {
+	store := NewStore(
+		WithRetention("foo", Retention{MaxAge: time.Hour}),
+		WithRetention("bar", Retention{MaxEvents: 2}),
+		WithRetention("users", Retention{Compact: true}),
+		WithDefaultRetention(Retention{MaxSize: 1}),
+		WithPruneInterval(time.Hour),
+	)
+
+	now := time.Now()
+	testData := []events.Event{
+		{ID: uuid.New().String(), Topic: "foo", Timestamp: now.Add(-2 * time.Hour)},
+		{ID: uuid.New().String(), Topic: "foo", Timestamp: now},
+		{ID: uuid.New().String(), Topic: "bar", Timestamp: now.Add(-2 * time.Second)},
+		{ID: uuid.New().String(), Topic: "bar", Timestamp: now.Add(-time.Second)},
+		{ID: uuid.New().String(), Topic: "bar", Timestamp: now},
+		{ID: uuid.New().String(), Topic: "baz", Timestamp: now},
+		{ID: uuid.New().String(), Topic: "users", Timestamp: now.Add(-2 * time.Second), OrderingKey: "john"},
+		{ID: uuid.New().String(), Topic: "users", Timestamp: now.Add(-time.Second), OrderingKey: "john"},
+		{ID: uuid.New().String(), Topic: "users", Timestamp: now.Add(-2 * time.Second), OrderingKey: "jane"},
+		{ID: uuid.New().String(), Topic: "users", Timestamp: now.Add(-3 * time.Second)},
+	}
+	for _, event := range testData {
+		err := store.Write(&event)
+		assert.Nilf(t, err, "Writing an event should not return an error")
+	}
+	assert.Nilf(t, store.(*evStore).prune(), "Pruning should not return an error")
+
+	// events older than the max age are pruned
+	evs, err := store.Read("foo")
+	assert.Nilf(t, err, "No error should be returned")
+	assert.Len(t, evs, 1, "The expired event should be pruned")
+	assert.Equal(t, testData[1].ID, evs[0].ID)
+
+	// the oldest events above the max events are pruned
+	evs, err = store.Read("bar")
+	assert.Nilf(t, err, "No error should be returned")
+	assert.Len(t, evs, 2, "The oldest event should be pruned")
+	for _, ev := range evs {
+		assert.NotEqual(t, testData[2].ID, ev.ID)
+	}
+
+	// topics without their own policy use the default
+	evs, err = store.Read("baz")
+	assert.Nilf(t, err, "No error should be returned")
+	assert.Len(t, evs, 0, "Events above the default max size should be pruned")
+
+	// compacted topics keep the latest event of each key
+	evs, err = store.Read("users")
+	assert.Nilf(t, err, "No error should be returned")
+	var ids []string
+	for _, ev := range evs {
+		ids = append(ids, ev.ID)
+	}
+	assert.ElementsMatch(t, []string{testData[7].ID, testData[8].ID, testData[9].ID}, ids)
 }
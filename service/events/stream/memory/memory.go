@@ -49,9 +49,13 @@ type subscriber struct {
 
 	sync.RWMutex
 	retryMap   map[string]int
+	pending    int
 	retryLimit int
+	deadLetter string
 	autoAck    bool
 	ackWait    time.Duration
+	// backoff returns the delay before redelivering an event delivered the number of times
+	backoff func(deliveries int) time.Duration
 }
 
 type mem struct {
@@ -89,11 +93,12 @@ func (m *mem) Publish(topic string, msg interface{}, opts ...events.PublishOptio
 
 	// construct the event
 	event := &events.Event{
-		ID:        uuid.New().String(),
-		Topic:     topic,
-		Timestamp: options.Timestamp,
-		Metadata:  options.Metadata,
-		Payload:   payload,
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Timestamp:   options.Timestamp,
+		Metadata:    options.Metadata,
+		Payload:     payload,
+		OrderingKey: options.OrderingKey,
 	}
 
 	// serialize the event to bytes
@@ -138,6 +143,8 @@ func (m *mem) Consume(topic string, opts ...events.ConsumeOption) (<-chan events
 		retryMap:   map[string]int{},
 		autoAck:    true,
 		retryLimit: options.GetRetryLimit(),
+		deadLetter: options.DeadLetterTopic,
+		backoff:    options.RedeliveryDelay,
 	}
 
 	if !options.AutoAck {
@@ -153,6 +160,14 @@ func (m *mem) Consume(topic string, opts ...events.ConsumeOption) (<-chan events
 	m.subs = append(m.subs, sub)
 	m.Unlock()
 
+	// deregister the subscriber once the context is done
+	if options.Context != nil {
+		go func() {
+			<-options.Context.Done()
+			m.removeSubscriber(sub)
+		}()
+	}
+
 	// lookup previous events if the start time option was passed
 	if options.Offset.Unix() > 0 {
 		go m.lookupPreviousEvents(sub, options.Offset)
@@ -162,6 +177,52 @@ func (m *mem) Consume(topic string, opts ...events.ConsumeOption) (<-chan events
 	return sub.Channel, nil
 }
 
+func (m *mem) removeSubscriber(sub *subscriber) {
+	m.Lock()
+	defer m.Unlock()
+	for i, s := range m.subs {
+		if s == sub {
+			m.subs = append(m.subs[:i:i], m.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Status returns the status of the consumer groups of the topic. Events are pending until they're
+// delivered to a consumer of the group, and unacked once they've been delivered until they're
+// acknowledged.
+func (m *mem) Status(topic string) ([]events.GroupStatus, error) {
+	m.RLock()
+	subs := m.subs
+	m.RUnlock()
+
+	var result []events.GroupStatus
+	index := map[string]int{}
+	for _, sub := range subs {
+		if len(topic) > 0 && sub.Topic != topic {
+			continue
+		}
+		key := sub.Topic + "/" + sub.Group
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, events.GroupStatus{Topic: sub.Topic, Group: sub.Group})
+		}
+
+		sub.RLock()
+		result[i].Consumers++
+		result[i].Pending += uint64(sub.pending)
+		for _, count := range sub.retryMap {
+			if count > 0 {
+				result[i].Unacked++
+			}
+		}
+		sub.RUnlock()
+	}
+	return result, nil
+}
+
 // lookupPreviousEvents finds events for a subscriber which occurred before a given time and sends
 // them into the subscribers channel
 func (m *mem) lookupPreviousEvents(sub *subscriber, startTime time.Time) {
@@ -183,7 +244,7 @@ func (m *mem) lookupPreviousEvents(sub *subscriber, startTime time.Time) {
 		if ev.Timestamp.Unix() < startTime.Unix() {
 			continue
 		}
-		sendEvent(&ev, sub)
+		m.sendEvent(&ev, sub)
 	}
 }
 
@@ -206,23 +267,31 @@ func (m *mem) handleEvent(ev *events.Event) {
 
 	// send the message to each channel async (since one channel might be blocked)
 	for _, sub := range filteredSubs {
-		sendEvent(ev, sub)
+		m.sendEvent(ev, sub)
 	}
 }
 
-func sendEvent(ev *events.Event, sub *subscriber) {
+func (m *mem) sendEvent(ev *events.Event, sub *subscriber) {
 	go func(s *subscriber) {
 		evCopy := *ev
+		s.Lock()
+		s.pending++
+		s.Unlock()
 		if s.autoAck {
 			s.Channel <- evCopy
+			s.Lock()
+			s.pending--
+			s.Unlock()
 			return
 		}
 		evCopy.SetAckFunc(ackFunc(s, evCopy))
 		evCopy.SetNackFunc(nackFunc(s, evCopy))
+		s.Lock()
 		s.retryMap[evCopy.ID] = 0
-		tick := time.NewTicker(s.ackWait)
-		defer tick.Stop()
-		for range tick.C {
+		s.Unlock()
+		timer := time.NewTimer(s.ackWait)
+		defer timer.Stop()
+		for range timer.C {
 			s.Lock()
 			count, ok := s.retryMap[evCopy.ID]
 			s.Unlock()
@@ -232,7 +301,11 @@ func sendEvent(ev *events.Event, sub *subscriber) {
 			}
 
 			if s.retryLimit > -1 && count > s.retryLimit {
-				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				if len(s.deadLetter) > 0 {
+					if err := events.DeadLetter(m, s.deadLetter, evCopy); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+						logger.Errorf("Error publishing message %v to dead-letter topic: %v", evCopy.ID, err)
+					}
+				} else if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 					logger.Errorf("Message retry limit reached, discarding: %v %d %d", evCopy.ID, count, s.retryLimit)
 				}
 				s.Lock()
@@ -242,8 +315,14 @@ func sendEvent(ev *events.Event, sub *subscriber) {
 			}
 			s.Channel <- evCopy
 			s.Lock()
+			if count == 0 {
+				s.pending--
+			}
 			s.retryMap[evCopy.ID] = count + 1
 			s.Unlock()
+
+			// the event is redelivered if it's not acked within the ack wait, after the backoff
+			timer.Reset(s.ackWait + s.backoff(count+1))
 		}
 	}(sub)
 }
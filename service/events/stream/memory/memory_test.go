@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	evStore "github.com/micro/micro/v3/service/events/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetter(t *testing.T) {
+	defer func(s events.Store) { events.DefaultStore = s }(events.DefaultStore)
+	events.DefaultStore = evStore.NewStore()
+
+	stream, err := NewStream()
+	assert.NoError(t, err)
+
+	dlq, err := stream.Consume("foo.dlq")
+	assert.NoError(t, err)
+	ch, err := stream.Consume("foo",
+		events.WithAutoAck(false, 100*time.Millisecond),
+		events.WithRetryLimit(1),
+		events.WithDeadLetter("foo.dlq"),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Publish("foo", []byte("bar"), events.WithMetadata(map[string]string{"meta": "baz"})))
+
+	// the event is never acked, so it's redelivered until it reaches the retry limit
+	go func() {
+		for range ch {
+		}
+	}()
+
+	select {
+	case ev := <-dlq:
+		assert.Equal(t, "foo.dlq", ev.Topic)
+		assert.Equal(t, []byte("bar"), ev.Payload)
+		assert.Equal(t, "baz", ev.Metadata["meta"])
+		assert.Equal(t, "foo", ev.Metadata[events.DeadLetterTopicKey])
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the event to be dead-lettered")
+	}
+
+	// the dead-lettered event is recorded so it can be requeued
+	evs, err := events.Read("foo.dlq")
+	assert.NoError(t, err)
+	if assert.Len(t, evs, 1) {
+		assert.Equal(t, "foo", evs[0].Metadata[events.DeadLetterTopicKey])
+		assert.NoError(t, events.Delete("foo.dlq", evs[0].ID))
+	}
+	evs, err = events.Read("foo.dlq")
+	assert.NoError(t, err)
+	assert.Len(t, evs, 0)
+}
+
+func TestStatus(t *testing.T) {
+	stream, err := NewStream()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := stream.Consume("foo",
+		events.WithGroup("group"),
+		events.WithAutoAck(false, 50*time.Millisecond),
+		events.WithContext(ctx),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Publish("foo", []byte("one")))
+	assert.NoError(t, stream.Publish("foo", []byte("two")))
+
+	status := func(topic string) []events.GroupStatus {
+		groups, err := stream.(events.Statuser).Status(topic)
+		assert.NoError(t, err)
+		return groups
+	}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]events.GroupStatus{
+			{Topic: "foo", Group: "group", Consumers: 1, Pending: 2},
+		}, status("foo"))
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, status("bar"))
+
+	// the event is unacked once it's been delivered until it's acked
+	ev := <-ch
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]events.GroupStatus{
+			{Topic: "foo", Group: "group", Consumers: 1, Pending: 1, Unacked: 1},
+		}, status(""))
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, ev.Ack())
+	assert.Equal(t, uint64(0), status("foo")[0].Unacked)
+
+	// the consumer leaves the group once its context is done
+	cancel()
+	assert.Eventually(t, func() bool {
+		return len(status("foo")) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBackoff(t *testing.T) {
+	stream, err := NewStream()
+	assert.NoError(t, err)
+
+	ch, err := stream.Consume("foo",
+		events.WithAutoAck(false, 50*time.Millisecond),
+		events.WithBackoff(200*time.Millisecond, 400*time.Millisecond),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Publish("foo", []byte("bar")))
+
+	// the event isn't acked so it's redelivered after the ack wait and the backoff, which doubles
+	// with each delivery
+	var deliveries []time.Time
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ch:
+			deliveries = append(deliveries, time.Now())
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for the event to be redelivered")
+		}
+	}
+	assert.True(t, deliveries[1].Sub(deliveries[0]) >= 250*time.Millisecond)
+	assert.True(t, deliveries[2].Sub(deliveries[1]) >= 450*time.Millisecond)
+}
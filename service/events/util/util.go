@@ -9,20 +9,26 @@ import (
 
 func SerializeEvent(ev *events.Event) *pb.Event {
 	return &pb.Event{
-		Id:        ev.ID,
-		Topic:     ev.Topic,
-		Metadata:  ev.Metadata,
-		Payload:   ev.Payload,
-		Timestamp: ev.Timestamp.Unix(),
+		Id:          ev.ID,
+		Topic:       ev.Topic,
+		Metadata:    ev.Metadata,
+		Payload:     ev.Payload,
+		Timestamp:   ev.Timestamp.Unix(),
+		Sequence:    ev.Sequence,
+		OrderingKey: ev.OrderingKey,
+		Partition:   int32(ev.Partition),
 	}
 }
 
 func DeserializeEvent(ev *pb.Event) events.Event {
 	return events.Event{
-		ID:        ev.Id,
-		Topic:     ev.Topic,
-		Metadata:  ev.Metadata,
-		Payload:   ev.Payload,
-		Timestamp: time.Unix(ev.Timestamp, 0),
+		ID:          ev.Id,
+		Topic:       ev.Topic,
+		Metadata:    ev.Metadata,
+		Payload:     ev.Payload,
+		Timestamp:   time.Unix(ev.Timestamp, 0),
+		Sequence:    ev.Sequence,
+		OrderingKey: ev.OrderingKey,
+		Partition:   int(ev.Partition),
 	}
 }
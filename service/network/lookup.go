@@ -5,10 +5,11 @@ import (
 
 	"github.com/micro/micro/v3/service/client"
 	"github.com/micro/micro/v3/service/context/metadata"
+	"github.com/micro/micro/v3/service/router"
 )
 
 // Lookup provides a lookup function that checks for namespace as the Micro-Namespace header
-func Lookup(ctx context.Context, req client.Request, opts client.CallOptions) ([]string, error) {
+func Lookup(ctx context.Context, req client.Request, opts client.CallOptions) ([]router.Route, error) {
 	// only set if the value is already nil
 	if len(opts.Network) == 0 {
 		val, ok := metadata.Get(ctx, "Micro-Namespace")
@@ -15,6 +15,7 @@ import (
 	"github.com/micro/micro/v3/service/network/mucp"
 	"github.com/micro/micro/v3/service/network/transport"
 	"github.com/micro/micro/v3/service/network/transport/grpc"
+	"github.com/micro/micro/v3/service/network/transport/quic"
 	"github.com/micro/micro/v3/service/network/tunnel"
 	tmucp "github.com/micro/micro/v3/service/network/tunnel/mucp"
 	"github.com/micro/micro/v3/service/proxy"
@@ -23,6 +24,7 @@ import (
 	"github.com/micro/micro/v3/service/router"
 	"github.com/micro/micro/v3/service/server"
 	mucpServer "github.com/micro/micro/v3/service/server/mucp"
+	"github.com/micro/micro/v3/service/store"
 	"github.com/micro/micro/v3/util/helper"
 	"github.com/micro/micro/v3/util/muxer"
 	"github.com/urfave/cli/v2"
@@ -69,6 +71,11 @@ var (
 			Usage:   "Set the micro network nodes to connect to. This can be a comma separated list.",
 			EnvVars: []string{"MICRO_NETWORK_NODES"},
 		},
+		&cli.StringFlag{
+			Name:    "transport",
+			Usage:   "Set the transport the network peers over: grpc or quic",
+			EnvVars: []string{"MICRO_NETWORK_TRANSPORT"},
+		},
 		&cli.StringFlag{
 			Name:    "token",
 			Usage:   "Set the micro network token for authentication",
@@ -115,6 +122,7 @@ func Run(ctx *cli.Context) error {
 		tunnel.Token(token),
 	}
 
+	var trOpts []transport.Option
 	if ctx.Bool("enable_tls") {
 		config, err := helper.TLSConfig(ctx)
 		if err != nil {
@@ -123,9 +131,18 @@ func Run(ctx *cli.Context) error {
 		}
 		config.InsecureSkipVerify = true
 
-		tunOpts = append(tunOpts, tunnel.Transport(
-			grpc.NewTransport(transport.TLSConfig(config)),
-		))
+		trOpts = append(trOpts, transport.TLSConfig(config))
+	}
+
+	switch tr := ctx.String("transport"); tr {
+	case "quic":
+		tunOpts = append(tunOpts, tunnel.Transport(quic.NewTransport(trOpts...)))
+	case "", "grpc":
+		if len(trOpts) > 0 {
+			tunOpts = append(tunOpts, tunnel.Transport(grpc.NewTransport(trOpts...)))
+		}
+	default:
+		return fmt.Errorf("unsupported transport %v", tr)
 	}
 
 	gateway := ctx.String("gateway")
@@ -145,6 +162,7 @@ func Run(ctx *cli.Context) error {
 		router.Id(id),
 		router.Gateway(gateway),
 		router.Cache(),
+		router.Store(store.DefaultStore),
 	)
 
 	// create new network
@@ -62,7 +62,7 @@ func getTLSConfig(addr string) (*tls.Config, error) {
 }
 
 func (t *grpcTransportListener) Addr() string {
-	return t.listener.Addr().String()
+	return mnet.Addr(t.listener)
 }
 
 func (t *grpcTransportListener) Close() error {
@@ -109,8 +109,11 @@ func (t *grpcTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 
 	options := []grpc.DialOption{}
 
-	if t.opts.Secure || t.opts.TLSConfig != nil {
+	if t.opts.Secure || t.opts.TLSConfig != nil || dopts.TLSConfig != nil {
 		config := t.opts.TLSConfig
+		if dopts.TLSConfig != nil {
+			config = dopts.TLSConfig
+		}
 		if config == nil {
 			config = &tls.Config{
 				InsecureSkipVerify: true,
@@ -152,7 +155,7 @@ func (t *grpcTransport) Listen(addr string, opts ...transport.ListenOption) (tra
 	}
 
 	ln, err := mnet.Listen(addr, func(addr string) (net.Listener, error) {
-		return net.Listen("tcp", addr)
+		return net.Listen(mnet.Network(addr))
 	})
 	if err != nil {
 		return nil, err
@@ -105,16 +105,22 @@ func (h *httpTransportClient) Send(m *transport.Message) error {
 	b := buf.New(bytes.NewBuffer(m.Body))
 	defer b.Close()
 
+	// the host of a unix socket is its path, which isn't a valid host header
+	host := h.addr
+	if mnet.IsUnix(host) {
+		host = "localhost"
+	}
+
 	req := &http.Request{
 		Method: "POST",
 		URL: &url.URL{
 			Scheme: "http",
-			Host:   h.addr,
+			Host:   host,
 		},
 		Header:        header,
 		Body:          b,
 		ContentLength: int64(b.Len()),
-		Host:          h.addr,
+		Host:          host,
 	}
 
 	h.Lock()
@@ -400,7 +406,7 @@ func (h *httpTransportSocket) Close() error {
 }
 
 func (h *httpTransportListener) Addr() string {
-	return h.listener.Addr().String()
+	return mnet.Addr(h.listener)
 }
 
 func (h *httpTransportListener) Close() error {
@@ -503,9 +509,11 @@ func (h *httpTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 	var conn net.Conn
 	var err error
 
-	// TODO: support dial option here rather than using internal config
-	if h.opts.Secure || h.opts.TLSConfig != nil {
+	if h.opts.Secure || h.opts.TLSConfig != nil || dopts.TLSConfig != nil {
 		config := h.opts.TLSConfig
+		if dopts.TLSConfig != nil {
+			config = dopts.TLSConfig.Clone()
+		}
 		if config == nil {
 			config = &tls.Config{
 				InsecureSkipVerify: true,
@@ -513,11 +521,13 @@ func (h *httpTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 		}
 		config.NextProtos = []string{"http/1.1"}
 		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "tcp", addr, config)
+			network, addr := mnet.Network(addr)
+			return tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, network, addr, config)
 		})(addr)
 	} else {
 		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return net.DialTimeout("tcp", addr, dopts.Timeout)
+			network, addr := mnet.Network(addr)
+			return net.DialTimeout(network, addr, dopts.Timeout)
 		})(addr)
 	}
 
@@ -570,13 +580,14 @@ func (h *httpTransport) Listen(addr string, opts ...transport.ListenOption) (tra
 				}
 				config = &tls.Config{Certificates: []tls.Certificate{cert}}
 			}
-			return tls.Listen("tcp", addr, config)
+			network, addr := mnet.Network(addr)
+			return tls.Listen(network, addr, config)
 		}
 
 		l, err = mnet.Listen(addr, fn)
 	} else {
 		fn := func(addr string) (net.Listener, error) {
-			return net.Listen("tcp", addr)
+			return net.Listen(mnet.Network(addr))
 		}
 
 		l, err = mnet.Listen(addr, fn)
@@ -23,6 +23,8 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+
+	mnet "github.com/micro/micro/v3/util/net"
 )
 
 const (
@@ -93,6 +95,11 @@ func proxyDial(conn net.Conn, addr string, proxyURL *url.URL) (_ net.Conn, err e
 // Creates a new connection
 func newConn(dial func(string) (net.Conn, error)) func(string) (net.Conn, error) {
 	return func(addr string) (net.Conn, error) {
+		// a unix socket is local so never proxied
+		if mnet.IsUnix(addr) {
+			return dial(addr)
+		}
+
 		// get the proxy url
 		proxyURL, err := getURL(addr)
 		if err != nil {
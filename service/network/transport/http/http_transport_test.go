@@ -17,6 +17,7 @@ package http
 import (
 	"io"
 	"net"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -126,6 +127,55 @@ func TestHTTPTransportCommunication(t *testing.T) {
 	close(done)
 }
 
+func TestHTTPTransportUnix(t *testing.T) {
+	tr := NewTransport()
+
+	addr := "unix://" + filepath.Join(t.TempDir(), "micro.sock")
+	l, err := tr.Listen(addr)
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	if l.Addr() != addr {
+		t.Fatalf("Expected the address %v, got %v", addr, l.Addr())
+	}
+
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		sock.Send(&m)
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	m := transport.Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: []byte(`{"message": "Hello World"}`),
+	}
+	if err := c.Send(&m); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+
+	var rm transport.Message
+	if err := c.Recv(&rm); err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+	if string(rm.Body) != string(m.Body) {
+		t.Errorf("Expected %v, got %v", m.Body, rm.Body)
+	}
+}
+
 func TestHTTPTransportError(t *testing.T) {
 	tr := NewTransport()
 
@@ -48,9 +48,9 @@ type DialOptions struct {
 	Stream bool
 	// Timeout for dialing
 	Timeout time.Duration
-
-	// TODO: add tls options when dialling
-	// Currently set in global options
+	// TLSConfig secures the connection, overriding the
+	// tls config of the transport
+	TLSConfig *tls.Config
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -116,3 +116,11 @@ func WithTimeout(d time.Duration) DialOption {
 		o.Timeout = d
 	}
 }
+
+// WithTLSConfig secures the connection dialled by the tls
+// config, overriding the tls config of the transport
+func WithTLSConfig(c *tls.Config) DialOption {
+	return func(o *DialOptions) {
+		o.TLSConfig = c
+	}
+}
@@ -0,0 +1,20 @@
+package quic
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/network/transport"
+)
+
+type maxStreamsKey struct{}
+
+// MaxStreams sets the number of streams a connection accepts concurrently, the sockets dialled
+// over it waiting for a stream once there are as many
+func MaxStreams(n int) transport.Option {
+	return func(o *transport.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, maxStreamsKey{}, n)
+	}
+}
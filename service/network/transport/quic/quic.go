@@ -0,0 +1,278 @@
+// Package quic provides a QUIC transport. The sockets dialled to an address are streams of a
+// single connection, which are independent of each other so a lost packet only holds up its own
+// stream, and the connection survives changes of the network of the client.
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/gob"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/network/transport"
+	maddr "github.com/micro/micro/v3/util/addr"
+	mls "github.com/micro/micro/v3/util/tls"
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	// alpn is the protocol negotiated by the tls handshake of the connections
+	alpn = "micro-quic"
+)
+
+var (
+	// DefaultMaxStreams is the number of streams a connection accepts concurrently
+	DefaultMaxStreams = 1000
+	// DefaultKeepAlive is how often a keep alive is sent on an idle connection
+	DefaultKeepAlive = time.Second * 15
+	// DefaultIdleTimeout is how long a connection is idle before it's closed
+	DefaultIdleTimeout = time.Minute * 2
+)
+
+type quicTransport struct {
+	opts transport.Options
+
+	sync.Mutex
+	// the connections dialled by address, shared by their sockets
+	conns map[string]quic.Connection
+}
+
+type quicSocket struct {
+	conn    quic.Connection
+	stream  quic.Stream
+	timeout time.Duration
+	enc     *gob.Encoder
+	dec     *gob.Decoder
+
+	// guards the encoder
+	sync.Mutex
+}
+
+type quicListener struct {
+	listener *quic.Listener
+	timeout  time.Duration
+}
+
+func newSocket(conn quic.Connection, stream quic.Stream, timeout time.Duration) *quicSocket {
+	return &quicSocket{
+		conn:    conn,
+		stream:  stream,
+		timeout: timeout,
+		enc:     gob.NewEncoder(stream),
+		dec:     gob.NewDecoder(stream),
+	}
+}
+
+func (q *quicSocket) Recv(m *transport.Message) error {
+	if m == nil {
+		return nil
+	}
+	if q.timeout > time.Duration(0) {
+		q.stream.SetReadDeadline(time.Now().Add(q.timeout))
+	}
+	return q.dec.Decode(m)
+}
+
+func (q *quicSocket) Send(m *transport.Message) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.timeout > time.Duration(0) {
+		q.stream.SetWriteDeadline(time.Now().Add(q.timeout))
+	}
+	return q.enc.Encode(m)
+}
+
+// Close closes the stream of the socket, the connection is kept for the other streams
+func (q *quicSocket) Close() error {
+	q.stream.CancelRead(0)
+	return q.stream.Close()
+}
+
+func (q *quicSocket) Local() string {
+	return q.conn.LocalAddr().String()
+}
+
+func (q *quicSocket) Remote() string {
+	return q.conn.RemoteAddr().String()
+}
+
+func (q *quicListener) Addr() string {
+	return q.listener.Addr().String()
+}
+
+func (q *quicListener) Close() error {
+	return q.listener.Close()
+}
+
+func (q *quicListener) Accept(fn func(transport.Socket)) error {
+	for {
+		conn, err := q.listener.Accept(context.Background())
+		if err != nil {
+			return err
+		}
+
+		// every stream of the connection is a socket
+		go func() {
+			for {
+				stream, err := conn.AcceptStream(context.Background())
+				if err != nil {
+					return
+				}
+				go fn(newSocket(conn, stream, q.timeout))
+			}
+		}()
+	}
+}
+
+func getTLSConfig(addr string) (*tls.Config, error) {
+	hosts := []string{addr}
+
+	// check if its a valid host:port
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if len(host) == 0 {
+			hosts = maddr.IPs()
+		} else {
+			hosts = []string{host}
+		}
+	}
+
+	// generate a certificate
+	cert, err := mls.Certificate(hosts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+func (q *quicTransport) config() *quic.Config {
+	maxStreams := DefaultMaxStreams
+	if q.opts.Context != nil {
+		if n, ok := q.opts.Context.Value(maxStreamsKey{}).(int); ok && n > 0 {
+			maxStreams = n
+		}
+	}
+
+	return &quic.Config{
+		KeepAlivePeriod:    DefaultKeepAlive,
+		MaxIdleTimeout:     DefaultIdleTimeout,
+		MaxIncomingStreams: int64(maxStreams),
+	}
+}
+
+// dial returns the connection to the address secured by the tls config, dialling it unless it's
+// connected already
+func (q *quicTransport) dial(ctx context.Context, addr string, tc *tls.Config) (quic.Connection, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	// the connections secured by the tls config of the transport are shared by address
+	key := addr
+	if tc != nil {
+		key = addr + "#" + mls.ConfigKey(tc)
+	}
+
+	if conn, ok := q.conns[key]; ok && conn.Context().Err() == nil {
+		return conn, nil
+	}
+
+	// quic connections are always encrypted
+	config := &tls.Config{InsecureSkipVerify: true}
+	if tc != nil {
+		config = tc.Clone()
+	} else if q.opts.TLSConfig != nil {
+		config = q.opts.TLSConfig.Clone()
+	}
+	config.NextProtos = []string{alpn}
+
+	conn, err := quic.DialAddr(ctx, addr, config, q.config())
+	if err != nil {
+		return nil, err
+	}
+	q.conns[key] = conn
+	return conn, nil
+}
+
+func (q *quicTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	dopts := transport.DialOptions{
+		Timeout: transport.DefaultDialTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&dopts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dopts.Timeout)
+	defer cancel()
+
+	conn, err := q.dial(ctx, addr, dopts.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// open a stream of the connection, blocking while it has as many as the peer accepts
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSocket(conn, stream, q.opts.Timeout), nil
+}
+
+func (q *quicTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	var options transport.ListenOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	config := q.opts.TLSConfig
+	if config == nil {
+		var err error
+		config, err = getTLSConfig(addr)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		config = config.Clone()
+	}
+	config.NextProtos = []string{alpn}
+
+	l, err := quic.ListenAddr(addr, config, q.config())
+	if err != nil {
+		return nil, err
+	}
+
+	return &quicListener{
+		listener: l,
+		timeout:  q.opts.Timeout,
+	}, nil
+}
+
+func (q *quicTransport) Init(opts ...transport.Option) error {
+	for _, o := range opts {
+		o(&q.opts)
+	}
+	return nil
+}
+
+func (q *quicTransport) Options() transport.Options {
+	return q.opts
+}
+
+func (q *quicTransport) String() string {
+	return "quic"
+}
+
+func NewTransport(opts ...transport.Option) transport.Transport {
+	var options transport.Options
+	for _, o := range opts {
+		o(&options)
+	}
+	return &quicTransport{
+		opts:  options,
+		conns: make(map[string]quic.Connection),
+	}
+}
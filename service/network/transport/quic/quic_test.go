@@ -0,0 +1,72 @@
+package quic
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/micro/micro/v3/service/network/transport"
+)
+
+func TestQUICTransport(t *testing.T) {
+	tr := NewTransport()
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	// echo the messages of every socket
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	var clients []transport.Client
+	for i := 0; i < 3; i++ {
+		c, err := tr.Dial(l.Addr())
+		if err != nil {
+			t.Fatalf("Unexpected error dialing %v", err)
+		}
+		defer c.Close()
+		clients = append(clients, c)
+	}
+
+	// the sockets are streams of the same connection
+	if clients[0].Local() != clients[2].Local() {
+		t.Fatalf("Expected the sockets to share a connection, got %v and %v", clients[0].Local(), clients[2].Local())
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c transport.Client) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				body := fmt.Sprintf("ping %d %d", i, j)
+				if err := c.Send(&transport.Message{Header: map[string]string{"Id": body}, Body: []byte(body)}); err != nil {
+					t.Errorf("Unexpected error sending %v", err)
+					return
+				}
+				var m transport.Message
+				if err := c.Recv(&m); err != nil {
+					t.Errorf("Unexpected error receiving %v", err)
+					return
+				}
+				if string(m.Body) != body || m.Header["Id"] != body {
+					t.Errorf("Expected %v, got %v", body, string(m.Body))
+				}
+			}
+		}(i, c)
+	}
+	wg.Wait()
+}
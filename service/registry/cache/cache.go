@@ -23,6 +23,7 @@ import (
 
 	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/registry"
+	"golang.org/x/sync/singleflight"
 )
 
 // Cache is the registry cache interface
@@ -36,6 +37,10 @@ type Cache interface {
 type Options struct {
 	// TTL is the cache TTL
 	TTL time.Duration
+	// Stale is how long after its TTL an entry is served while it's refreshed in the background
+	Stale time.Duration
+	// Jitter is the maximum fraction of the TTL randomly subtracted from the TTL of each entry
+	Jitter float64
 }
 
 type Option func(o *Options)
@@ -56,13 +61,20 @@ type cache struct {
 
 	// indicate whether its running status of the registry used to hold onto the cache in failure state
 	status error
+
+	// lookups of the same service share a request to the registry
+	lookups singleflight.Group
 }
 
 type services map[string][]*registry.Service
 type ttls map[string]time.Time
 type watched map[string]bool
 
-var defaultTTL = time.Minute
+var (
+	defaultTTL    = time.Minute
+	defaultStale  = time.Minute
+	defaultJitter = 0.1
+)
 
 func backoff(attempts int) time.Duration {
 	if attempts == 0 {
@@ -104,6 +116,24 @@ func (c *cache) isValid(services []*registry.Service, ttl time.Time) bool {
 	return true
 }
 
+// isStale checks if the expired service can be served while it's refreshed
+func (c *cache) isStale(services []*registry.Service, ttl time.Time) bool {
+	if len(services) == 0 || ttl.IsZero() {
+		return false
+	}
+	return time.Since(ttl) <= c.opts.Stale
+}
+
+// ttl returns the TTL of an entry, with the jitter subtracted so the entries cached together
+// aren't refreshed together
+func (c *cache) ttl() time.Duration {
+	jitter := int64(float64(c.opts.TTL) * c.opts.Jitter)
+	if jitter <= 0 {
+		return c.opts.TTL
+	}
+	return c.opts.TTL - time.Duration(rand.Int63n(jitter))
+}
+
 func (c *cache) quit() bool {
 	select {
 	case <-c.exit:
@@ -150,34 +180,6 @@ func (c *cache) get(domain, service string) ([]*registry.Service, error) {
 		return Copy(services), nil
 	}
 
-	// get does the actual request for a service and cache it
-	get := func(domain string, service string, cached []*registry.Service) ([]*registry.Service, error) {
-		// ask the registry
-		services, err := c.Registry.GetService(service, registry.GetDomain(domain))
-		if err != nil {
-			// set the error status
-			c.setStatus(err)
-
-			// check the cache
-			if len(cached) > 0 {
-				return cached, nil
-			}
-
-			// otherwise return error
-			return nil, err
-		}
-
-		// reset the status
-		if err := c.getStatus(); err != nil {
-			c.setStatus(nil)
-		}
-
-		// cache results
-		c.set(domain, service, Copy(services))
-
-		return services, nil
-	}
-
 	// watch service if not watched
 	c.RLock()
 	var ok bool
@@ -209,8 +211,50 @@ func (c *cache) get(domain, service string) ([]*registry.Service, error) {
 		}
 	}
 
+	// serve the stale services while they're refreshed in the background
+	if c.isStale(services, ttl) {
+		go c.lookup(domain, service)
+		return Copy(services), nil
+	}
+
 	// get and return services
-	return get(domain, service, services)
+	srvs, err := c.lookup(domain, service)
+	if err != nil {
+		// return the cached services when the registry fails, however old they are
+		if len(services) > 0 {
+			return Copy(services), nil
+		}
+		return nil, err
+	}
+	return Copy(srvs), nil
+}
+
+// lookup does the actual request for a service and caches it, sharing the request with
+// concurrent lookups of the service
+func (c *cache) lookup(domain, service string) ([]*registry.Service, error) {
+	v, err, _ := c.lookups.Do(domain+"/"+service, func() (interface{}, error) {
+		// ask the registry
+		services, err := c.Registry.GetService(service, registry.GetDomain(domain))
+		if err != nil {
+			// set the error status
+			c.setStatus(err)
+			return nil, err
+		}
+
+		// reset the status
+		if err := c.getStatus(); err != nil {
+			c.setStatus(nil)
+		}
+
+		// cache results
+		c.set(domain, service, Copy(services))
+
+		return services, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*registry.Service), nil
 }
 
 func (c *cache) set(domain string, service string, srvs []*registry.Service) {
@@ -225,7 +269,7 @@ func (c *cache) set(domain string, service string, srvs []*registry.Service) {
 	}
 
 	c.services[domain][service] = srvs
-	c.ttls[domain][service] = time.Now().Add(c.opts.TTL)
+	c.ttls[domain][service] = time.Now().Add(c.ttl())
 }
 
 func (c *cache) update(domain string, res *registry.Result) {
@@ -483,6 +527,9 @@ func (c *cache) GetService(service string, opts ...registry.GetOption) ([]*regis
 		return nil, err
 	}
 
+	// the services are cached unfiltered so the nodes are filtered on each lookup
+	services = registry.FilterServices(services, options.Filters)
+
 	// if there's nothing return err
 	if len(services) == 0 {
 		return nil, registry.ErrNotFound
@@ -512,7 +559,9 @@ func (c *cache) String() string {
 func New(r registry.Registry, opts ...Option) Cache {
 	rand.Seed(time.Now().UnixNano())
 	options := Options{
-		TTL: defaultTTL,
+		TTL:    defaultTTL,
+		Stale:  defaultStale,
+		Jitter: defaultJitter,
 	}
 
 	for _, o := range opts {
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/registry/memory"
+)
+
+// testRegistry counts the lookups of services and fails them when it's down
+type testRegistry struct {
+	registry.Registry
+
+	sync.Mutex
+	lookups int
+	down    bool
+}
+
+func (t *testRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	t.Lock()
+	t.lookups++
+	down := t.down
+	t.Unlock()
+
+	if down {
+		return nil, errors.New("registry unavailable")
+	}
+	return t.Registry.GetService(name, opts...)
+}
+
+func (t *testRegistry) setDown(down bool) {
+	t.Lock()
+	defer t.Unlock()
+	t.down = down
+}
+
+func (t *testRegistry) getLookups() int {
+	t.Lock()
+	defer t.Unlock()
+	return t.lookups
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	r := &testRegistry{Registry: memory.NewRegistry()}
+	if err := r.Register(testService("foo-1")); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func testService(ids ...string) *registry.Service {
+	s := &registry.Service{Name: "foo", Version: "1.0.0"}
+	for _, id := range ids {
+		s.Nodes = append(s.Nodes, &registry.Node{Id: id, Address: id + ":8080"})
+	}
+	return s
+}
+
+func TestCacheStale(t *testing.T) {
+	r := newTestRegistry(t)
+	c := New(r, WithTTL(50*time.Millisecond), WithStale(time.Minute), WithJitter(0))
+	defer c.Stop()
+
+	if _, err := c.GetService("foo"); err != nil {
+		t.Fatalf("Error getting foo: %v", err)
+	}
+	if _, err := c.GetService("foo"); err != nil || r.getLookups() != 1 {
+		t.Fatalf("Expected foo to be cached, got %v lookups: %v", r.getLookups(), err)
+	}
+
+	// once expired the stale entry is served while the registry is looked up in the background
+	time.Sleep(60 * time.Millisecond)
+	r.setDown(true)
+	srvs, err := c.GetService("foo")
+	if err != nil || len(srvs) != 1 || len(srvs[0].Nodes) != 1 {
+		t.Fatalf("Expected the stale entry while the registry is down, got %+v: %v", srvs, err)
+	}
+	waitFor(t, func() bool { return r.getLookups() == 2 })
+
+	// the entry is refreshed when the registry is back
+	r.setDown(false)
+	if err := r.Registry.Register(testService("foo-2")); err != nil {
+		t.Fatal(err)
+	}
+	c.GetService("foo")
+	waitFor(t, func() bool {
+		srvs, err := c.GetService("foo")
+		return err == nil && len(srvs[0].Nodes) == 2
+	})
+}
+
+func TestCacheOutage(t *testing.T) {
+	r := newTestRegistry(t)
+	c := New(r, WithTTL(10*time.Millisecond), WithStale(10*time.Millisecond), WithJitter(0))
+	defer c.Stop()
+
+	if _, err := c.GetService("foo"); err != nil {
+		t.Fatalf("Error getting foo: %v", err)
+	}
+
+	// entries past their stale period are looked up, falling back to the entry if that fails
+	time.Sleep(30 * time.Millisecond)
+	r.setDown(true)
+	srvs, err := c.GetService("foo")
+	if err != nil || len(srvs) != 1 {
+		t.Fatalf("Expected the cached entry while the registry is down, got %+v: %v", srvs, err)
+	}
+	if l := r.getLookups(); l != 2 {
+		t.Fatalf("Expected the registry to be looked up, got %v lookups", l)
+	}
+
+	if _, err := c.GetService("bar"); err == nil {
+		t.Fatal("Expected an error getting an uncached service while the registry is down")
+	}
+}
+
+func TestCacheConcurrentLookups(t *testing.T) {
+	r := newTestRegistry(t)
+	c := New(r)
+	defer c.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.GetService("foo")
+		}()
+	}
+	wg.Wait()
+
+	if l := r.getLookups(); l > 2 {
+		t.Fatalf("Expected concurrent lookups to share requests, got %v lookups", l)
+	}
+}
+
+func TestCacheJitter(t *testing.T) {
+	c := New(nil, WithTTL(time.Minute), WithJitter(0.5)).(*cache)
+	for i := 0; i < 100; i++ {
+		if ttl := c.ttl(); ttl > time.Minute || ttl <= 30*time.Second {
+			t.Fatalf("Expected a ttl between 30s and 1m, got %v", ttl)
+		}
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -24,3 +24,20 @@ func WithTTL(t time.Duration) Option {
 		o.TTL = t
 	}
 }
+
+// WithStale sets how long after its TTL an entry is served while it's refreshed in the
+// background. Entries older than that are refreshed before being served, unless the registry
+// fails.
+func WithStale(t time.Duration) Option {
+	return func(o *Options) {
+		o.Stale = t
+	}
+}
+
+// WithJitter sets the maximum fraction of the TTL randomly subtracted from the TTL of each entry,
+// e.g. 0.1 for up to 10%, so the entries cached together aren't refreshed together
+func WithJitter(j float64) Option {
+	return func(o *Options) {
+		o.Jitter = j
+	}
+}
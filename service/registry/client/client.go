@@ -85,7 +85,7 @@ func (s *srv) GetService(name string, opts ...registry.GetOption) ([]*registry.S
 	}
 
 	rsp, err := s.client.GetService(context.DefaultContext, &pb.GetRequest{
-		Service: name, Options: &pb.Options{Domain: options.Domain},
+		Service: name, Options: &pb.Options{Domain: options.Domain}, Filters: options.Filters,
 	}, s.callOpts()...)
 
 	if verr := errors.FromError(err); verr != nil && verr.Code == 404 {
@@ -128,7 +128,7 @@ func (s *srv) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
 	}
 
 	stream, err := s.client.Watch(context.DefaultContext, &pb.WatchRequest{
-		Service: options.Service, Options: &pb.Options{Domain: options.Domain},
+		Service: options.Service, Options: &pb.Options{Domain: options.Domain}, Resume: options.Resume,
 	}, s.callOpts()...)
 
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	pb "github.com/micro/micro/v3/proto/registry"
+	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/service/registry/util"
 )
@@ -25,7 +26,9 @@ func (s *serviceWatcher) Next() (*registry.Result, error) {
 		}
 
 		r, err := s.stream.Recv()
-		if err != nil {
+		if verr := errors.FromError(err); verr != nil && verr.Code == 410 {
+			return nil, registry.ErrWatchExpired
+		} else if err != nil {
 			return nil, err
 		}
 
@@ -48,6 +51,7 @@ func (s *serviceWatcher) Next() (*registry.Result, error) {
 		return &registry.Result{
 			Action:  r.Action,
 			Service: util.ToService(r.Service),
+			Token:   r.Token,
 		}, nil
 	}
 }
@@ -0,0 +1,278 @@
+// Package dns is a read-only registry which resolves services from SRV, A and AAAA records
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+)
+
+var (
+	// DefaultPort is the port of the nodes resolved from A and AAAA records
+	DefaultPort = 8080
+	// DefaultInterval is how often the services watched are resolved
+	DefaultInterval = 30 * time.Second
+)
+
+// resolver resolves the records, it's implemented by net.Resolver
+type resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type dnsRegistry struct {
+	options  registry.Options
+	template string
+	port     int
+	interval time.Duration
+	resolver resolver
+
+	sync.RWMutex
+	// the services which have been resolved by domain, which are listed and watched
+	services map[string]map[string]bool
+}
+
+// NewRegistry returns a registry which resolves the services from DNS, for environments where
+// the services are registered out-of-band, e.g. headless kubernetes services or consul DNS. The
+// SRV records of the name of a service are resolved, falling back to its A and AAAA records with
+// the port set by the Port option. Services can't be registered, Register and Deregister do
+// nothing. The DNS servers are set with registry.Addrs, otherwise the system resolver is used.
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	d := &dnsRegistry{
+		template: "{service}",
+		port:     DefaultPort,
+		interval: DefaultInterval,
+		services: make(map[string]map[string]bool),
+	}
+	d.configure(opts...)
+	return d
+}
+
+func (d *dnsRegistry) configure(opts ...registry.Option) {
+	for _, o := range opts {
+		o(&d.options)
+	}
+	if d.options.Timeout == 0 {
+		d.options.Timeout = 5 * time.Second
+	}
+
+	if ctx := d.options.Context; ctx != nil {
+		if t, ok := ctx.Value(templateKey{}).(string); ok && len(t) > 0 {
+			d.template = t
+		}
+		if p, ok := ctx.Value(portKey{}).(int); ok && p > 0 {
+			d.port = p
+		}
+		if i, ok := ctx.Value(intervalKey{}).(time.Duration); ok && i > 0 {
+			d.interval = i
+		}
+		if names, ok := ctx.Value(servicesKey{}).([]string); ok {
+			for _, name := range names {
+				d.add(registry.DefaultDomain, name)
+			}
+		}
+	}
+
+	d.resolver = newResolver(d.options.Addrs)
+}
+
+// newResolver returns a resolver which queries the servers in turn, or the system resolver if
+// there are none
+func newResolver(addrs []string) resolver {
+	var servers []string
+	for _, addr := range addrs {
+		if len(addr) == 0 {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "53")
+		}
+		servers = append(servers, addr)
+	}
+	if len(servers) == 0 {
+		return net.DefaultResolver
+	}
+
+	var next uint32
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			server := servers[int(atomic.AddUint32(&next, 1))%len(servers)]
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+func (d *dnsRegistry) Init(opts ...registry.Option) error {
+	d.configure(opts...)
+	return nil
+}
+
+func (d *dnsRegistry) Options() registry.Options {
+	return d.options
+}
+
+func (d *dnsRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+		logger.Tracef("Skipping registering %s, the dns registry is read-only", s.Name)
+	}
+	return nil
+}
+
+func (d *dnsRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	return nil
+}
+
+// GetService resolves the nodes of the service, which has no version
+func (d *dnsRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	domain := domainOf(options.Domain)
+
+	s, err := d.resolve(name, domain)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nodes) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	d.add(domain, name)
+
+	services := registry.FilterServices([]*registry.Service{s}, options.Filters)
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	return services, nil
+}
+
+// ListServices lists the services set with the Services option and the services which have been
+// resolved, as services can't be listed with DNS
+func (d *dnsRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	domain := domainOf(options.Domain)
+
+	var services []*registry.Service
+	for _, name := range d.names(domain) {
+		services = append(services, &registry.Service{
+			Name:     name,
+			Metadata: map[string]string{"domain": domain},
+		})
+	}
+	return services, nil
+}
+
+func (d *dnsRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	// the records resolved are diffed so the watch can't be resumed
+	var options registry.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Resume) > 0 {
+		return nil, registry.ErrWatchExpired
+	}
+	return newWatcher(d, opts...), nil
+}
+
+func (d *dnsRegistry) String() string {
+	return "dns"
+}
+
+// resolve the nodes of the service from the SRV records of its name, or its A and AAAA records
+// if it has no SRV records
+func (d *dnsRegistry) resolve(name, domain string) (*registry.Service, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.options.Timeout)
+	defer cancel()
+
+	host := strings.NewReplacer("{service}", name, "{domain}", domain).Replace(d.template)
+	s := &registry.Service{
+		Name:     name,
+		Metadata: map[string]string{"domain": domain},
+	}
+
+	_, records, err := d.resolver.LookupSRV(ctx, "", "", host)
+	if err != nil && !notFound(err) {
+		return nil, err
+	}
+	for _, r := range records {
+		addr := net.JoinHostPort(strings.TrimSuffix(r.Target, "."), strconv.Itoa(int(r.Port)))
+		s.Nodes = append(s.Nodes, &registry.Node{
+			Id:      addr,
+			Address: addr,
+			Metadata: map[string]string{
+				"domain":   domain,
+				"priority": strconv.Itoa(int(r.Priority)),
+				"weight":   strconv.Itoa(int(r.Weight)),
+			},
+		})
+	}
+	if len(s.Nodes) > 0 {
+		return s, nil
+	}
+
+	addrs, err := d.resolver.LookupHost(ctx, host)
+	if err != nil && !notFound(err) {
+		return nil, err
+	}
+	sort.Strings(addrs)
+	for _, a := range addrs {
+		addr := net.JoinHostPort(a, strconv.Itoa(d.port))
+		s.Nodes = append(s.Nodes, &registry.Node{
+			Id:       addr,
+			Address:  addr,
+			Metadata: map[string]string{"domain": domain},
+		})
+	}
+	return s, nil
+}
+
+// add the service to the services listed and watched
+func (d *dnsRegistry) add(domain, name string) {
+	d.Lock()
+	defer d.Unlock()
+	if _, ok := d.services[domain]; !ok {
+		d.services[domain] = make(map[string]bool)
+	}
+	d.services[domain][name] = true
+}
+
+// names returns the names of the services listed and watched in the domain, sorted
+func (d *dnsRegistry) names(domain string) []string {
+	d.RLock()
+	defer d.RUnlock()
+	names := make([]string, 0, len(d.services[domain]))
+	for name := range d.services[domain] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// domainOf returns the domain of the options, the services of all domains can't be resolved so
+// the wildcard domain is the default domain
+func domainOf(domain string) string {
+	if len(domain) == 0 || domain == registry.WildcardDomain {
+		return registry.DefaultDomain
+	}
+	return domain
+}
+
+// notFound returns whether the error is the name not existing or having no records
+func notFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,165 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+)
+
+// fakeResolver resolves the records it's set
+type fakeResolver struct {
+	sync.Mutex
+	srv   map[string][]*net.SRV
+	hosts map[string][]string
+}
+
+func (f *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.Lock()
+	defer f.Unlock()
+	records, ok := f.srv[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return name, records, nil
+}
+
+func (f *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	f.Lock()
+	defer f.Unlock()
+	addrs, ok := f.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func (f *fakeResolver) setSRV(name string, records ...*net.SRV) {
+	f.Lock()
+	defer f.Unlock()
+	f.srv[name] = records
+}
+
+func newTestRegistry(opts ...registry.Option) (*dnsRegistry, *fakeResolver) {
+	r := &fakeResolver{
+		srv: map[string][]*net.SRV{
+			"foo.micro.svc.cluster.local": {
+				{Target: "10-0-0-1.foo.micro.svc.cluster.local.", Port: 9090, Priority: 1, Weight: 10},
+				{Target: "10-0-0-2.foo.micro.svc.cluster.local.", Port: 9090, Priority: 1, Weight: 10},
+			},
+		},
+		hosts: map[string][]string{
+			"bar.micro.svc.cluster.local": {"10.0.1.2", "10.0.1.1"},
+		},
+	}
+	d := NewRegistry(append([]registry.Option{Template("{service}.{domain}.svc.cluster.local")}, opts...)...).(*dnsRegistry)
+	d.resolver = r
+	return d, r
+}
+
+func TestGetService(t *testing.T) {
+	d, _ := newTestRegistry(Port(8081))
+
+	srvs, err := d.GetService("foo")
+	if err != nil {
+		t.Fatalf("Error getting foo: %v", err)
+	}
+	if len(srvs) != 1 || len(srvs[0].Nodes) != 2 {
+		t.Fatalf("Expected foo with two nodes, got %+v", srvs)
+	}
+	if n := srvs[0].Nodes[0]; n.Address != "10-0-0-1.foo.micro.svc.cluster.local:9090" || n.Metadata["weight"] != "10" {
+		t.Fatalf("Expected the node of the SRV record, got %+v", n)
+	}
+
+	// bar has no SRV records so it's resolved from its A records
+	srvs, err = d.GetService("bar")
+	if err != nil {
+		t.Fatalf("Error getting bar: %v", err)
+	}
+	if len(srvs) != 1 || len(srvs[0].Nodes) != 2 || srvs[0].Nodes[0].Address != "10.0.1.1:8081" {
+		t.Fatalf("Expected bar with two nodes on the port, got %+v", srvs)
+	}
+
+	if _, err := d.GetService("baz"); err != registry.ErrNotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+	if _, err := d.GetService("foo", registry.GetDomain("other")); err != registry.ErrNotFound {
+		t.Fatalf("Expected not found in another domain, got %v", err)
+	}
+}
+
+func TestListServices(t *testing.T) {
+	d, _ := newTestRegistry(Services("qux"))
+	if _, err := d.GetService("foo"); err != nil {
+		t.Fatalf("Error getting foo: %v", err)
+	}
+
+	list, err := d.ListServices()
+	if err != nil {
+		t.Fatalf("Error listing services: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "foo" || list[1].Name != "qux" {
+		t.Fatalf("Expected the resolved and set services, got %+v", list)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	d, r := newTestRegistry(Interval(10 * time.Millisecond))
+	if _, err := d.GetService("foo"); err != nil {
+		t.Fatalf("Error getting foo: %v", err)
+	}
+
+	w, err := d.Watch()
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer w.Stop()
+
+	r.setSRV("foo.micro.svc.cluster.local",
+		&net.SRV{Target: "10-0-0-2.foo.micro.svc.cluster.local.", Port: 9090, Priority: 1, Weight: 10},
+		&net.SRV{Target: "10-0-0-3.foo.micro.svc.cluster.local.", Port: 9090, Priority: 1, Weight: 10},
+	)
+	results := map[string]string{}
+	for i := 0; i < 2; i++ {
+		res := nextResult(t, w)
+		results[res.Service.Nodes[0].Id] = res.Action
+	}
+	if results["10-0-0-1.foo.micro.svc.cluster.local:9090"] != "delete" || results["10-0-0-3.foo.micro.svc.cluster.local:9090"] != "create" {
+		t.Fatalf("Expected a node deleted and a node created, got %v", results)
+	}
+
+	// a service resolved after the watcher was created is watched
+	if _, err := d.GetService("bar"); err != nil {
+		t.Fatalf("Error getting bar: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if res := nextResult(t, w); res.Action != "create" || res.Service.Name != "bar" {
+			t.Fatalf("Expected bar to be created, got %v of %v", res.Action, res.Service.Name)
+		}
+	}
+
+	w.Stop()
+	if _, err := w.Next(); err != registry.ErrWatcherStopped {
+		t.Fatalf("Expected watcher stopped, got %v", err)
+	}
+}
+
+func nextResult(t *testing.T, w registry.Watcher) *registry.Result {
+	t.Helper()
+	results := make(chan *registry.Result, 1)
+	go func() {
+		if res, err := w.Next(); err == nil {
+			results <- res
+		}
+	}()
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for result")
+	}
+	return nil
+}
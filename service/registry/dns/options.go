@@ -0,0 +1,48 @@
+package dns
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+)
+
+type templateKey struct{}
+type portKey struct{}
+type intervalKey struct{}
+type servicesKey struct{}
+
+// Template sets the name resolved for a service, with {service} replaced by the name of the
+// service and {domain} by its domain, e.g. "{service}.{domain}.svc.cluster.local" for headless
+// kubernetes services in the namespace of the domain, or "{service}.service.consul" for consul.
+// By default the name of the service is resolved, using the search domains of the resolver.
+func Template(t string) registry.Option {
+	return setRegistryOption(templateKey{}, t)
+}
+
+// Port sets the port of the nodes resolved from A and AAAA records, which don't have a port,
+// by default 8080
+func Port(p int) registry.Option {
+	return setRegistryOption(portKey{}, p)
+}
+
+// Interval sets how often the services watched are resolved, by default every 30 seconds
+func Interval(d time.Duration) registry.Option {
+	return setRegistryOption(intervalKey{}, d)
+}
+
+// Services sets the names of the services listed in the default domain, as services can't be
+// listed with DNS. The services which have been resolved are listed too.
+func Services(names ...string) registry.Option {
+	return setRegistryOption(servicesKey{}, names)
+}
+
+// setRegistryOption returns a function to setup a context with given value
+func setRegistryOption(k, v interface{}) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
@@ -0,0 +1,141 @@
+package dns
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+)
+
+// watcher resolves the services at the interval of the registry, emitting the nodes created
+// and deleted. If no service is watched the services resolved by the registry are watched, the
+// nodes of a service resolved after the watcher was created are emitted as created.
+type watcher struct {
+	registry *dnsRegistry
+	domain   string
+	service  string
+
+	next chan *registry.Result
+	exit chan bool
+	once sync.Once
+
+	// the nodes of each service keyed by their ids
+	nodes map[string]map[string]*registry.Service
+}
+
+func newWatcher(d *dnsRegistry, opts ...registry.WatchOption) registry.Watcher {
+	var options registry.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	w := &watcher{
+		registry: d,
+		domain:   domainOf(options.Domain),
+		service:  options.Service,
+		next:     make(chan *registry.Result),
+		exit:     make(chan bool),
+		nodes:    make(map[string]map[string]*registry.Service),
+	}
+
+	// resolve the services so the watcher only emits the changes after it was created
+	for _, name := range w.names() {
+		if nodes, err := w.resolve(name); err == nil {
+			w.nodes[name] = nodes
+		}
+	}
+	go w.run()
+	return w
+}
+
+func (w *watcher) names() []string {
+	if len(w.service) > 0 {
+		return []string{w.service}
+	}
+	return w.registry.names(w.domain)
+}
+
+// resolve the nodes of the service keyed by their ids
+func (w *watcher) resolve(name string) (map[string]*registry.Service, error) {
+	s, err := w.registry.resolve(name, w.domain)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*registry.Service, len(s.Nodes))
+	for _, n := range s.Nodes {
+		nodes[n.Id] = &registry.Service{
+			Name:     s.Name,
+			Version:  s.Version,
+			Metadata: s.Metadata,
+			Nodes:    []*registry.Node{n},
+		}
+	}
+	return nodes, nil
+}
+
+func (w *watcher) run() {
+	t := time.NewTicker(w.registry.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.exit:
+			return
+		case <-t.C:
+		}
+
+		for _, name := range w.names() {
+			nodes, err := w.resolve(name)
+			if err != nil {
+				// the nodes are kept if the service can't be resolved, e.g. if the DNS server
+				// is unavailable
+				logger.Errorf("Error resolving %s: %v", name, err)
+				continue
+			}
+			for _, r := range diff(w.nodes[name], nodes) {
+				select {
+				case w.next <- r:
+				case <-w.exit:
+					return
+				}
+			}
+			w.nodes[name] = nodes
+		}
+	}
+}
+
+func (w *watcher) Next() (*registry.Result, error) {
+	select {
+	case r := <-w.next:
+		return r, nil
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+func (w *watcher) Stop() {
+	w.once.Do(func() {
+		close(w.exit)
+	})
+}
+
+// diff returns the results of the changes between the nodes of a service
+func diff(prev, cur map[string]*registry.Service) []*registry.Result {
+	var results []*registry.Result
+	for id, s := range cur {
+		p, ok := prev[id]
+		if !ok {
+			results = append(results, &registry.Result{Action: "create", Service: s})
+		} else if !reflect.DeepEqual(p, s) {
+			results = append(results, &registry.Result{Action: "update", Service: s})
+		}
+	}
+	for id, s := range prev {
+		if _, ok := cur[id]; !ok {
+			results = append(results, &registry.Result{Action: "delete", Service: s})
+		}
+	}
+	return results
+}
@@ -0,0 +1,45 @@
+package registry
+
+// FilterServices returns copies of the services with only the nodes matching all the filters,
+// dropping the services with no matching nodes. It's used by implementations of the registry
+// which can't filter the nodes natively.
+func FilterServices(services []*Service, filters map[string]string) []*Service {
+	if len(filters) == 0 {
+		return services
+	}
+
+	var filtered []*Service
+	for _, s := range services {
+		var nodes []*Node
+		for _, n := range s.Nodes {
+			if matches(s, n, filters) {
+				nodes = append(nodes, n)
+			}
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+
+		cp := *s
+		cp.Nodes = nodes
+		filtered = append(filtered, &cp)
+	}
+	return filtered
+}
+
+// matches returns true if the node has all the values of the filters
+func matches(s *Service, n *Node, filters map[string]string) bool {
+	for k, v := range filters {
+		val, ok := n.Metadata[k]
+		if !ok {
+			val, ok = s.Metadata[k]
+		}
+		if !ok && k == "version" {
+			val = s.Version
+		}
+		if val != v {
+			return false
+		}
+	}
+	return true
+}
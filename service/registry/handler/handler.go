@@ -65,8 +65,12 @@ func (r *Registry) GetService(ctx context.Context, req *pb.GetRequest, rsp *pb.G
 		return err
 	}
 
-	// get the services in the namespace
-	services, err := registry.DefaultRegistry.GetService(req.Service, registry.GetDomain(options.Domain))
+	// get the services in the namespace, with the nodes matching the filters
+	opts := []registry.GetOption{registry.GetDomain(options.Domain)}
+	for k, v := range req.Filters {
+		opts = append(opts, registry.Filter(k, v))
+	}
+	services, err := registry.DefaultRegistry.GetService(req.Service, opts...)
 	if err == registry.ErrNotFound || len(services) == 0 {
 		return errors.NotFound("registry.Registry.GetService", registry.ErrNotFound.Error())
 	} else if err != nil {
@@ -188,21 +192,31 @@ func (r *Registry) Watch(ctx context.Context, req *pb.WatchRequest, rsp pb.Regis
 		return err
 	}
 
-	// setup the watcher
-	watcher, err := registry.DefaultRegistry.Watch(registry.WatchService(req.Service), registry.WatchDomain(domain))
-	if err != nil {
+	// setup the watcher, resuming after the token if one was passed
+	opts := []registry.WatchOption{registry.WatchService(req.Service), registry.WatchDomain(domain)}
+	if len(req.Resume) > 0 {
+		opts = append(opts, registry.WatchResume(req.Resume))
+	}
+	watcher, err := registry.DefaultRegistry.Watch(opts...)
+	if err == registry.ErrWatchExpired {
+		return errors.New("registry.Registry.Watch", err.Error(), 410)
+	} else if err != nil {
 		return errors.InternalServerError("registry.Registry.Watch", err.Error())
 	}
+	defer watcher.Stop()
 
 	for {
 		next, err := watcher.Next()
-		if err != nil {
+		if err == registry.ErrWatchExpired {
+			return errors.New("registry.Registry.Watch", err.Error(), 410)
+		} else if err != nil {
 			return errors.InternalServerError("registry.Registry.Watch", err.Error())
 		}
 
 		err = rsp.Send(&pb.Result{
 			Action:  next.Action,
 			Service: util.ToProto(next.Service),
+			Token:   next.Token,
 		})
 		if err != nil {
 			return errors.InternalServerError("registry.Registry.Watch", err.Error())
@@ -0,0 +1,241 @@
+// Package health checks the health of the nodes in the registry, deregistering the nodes which
+// keep failing their checks so they're removed from the results of GetService before their TTL
+// expires
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/micro/micro/v3/proto/debug"
+	"github.com/micro/micro/v3/service/client"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+)
+
+// Options of the checker
+type Options struct {
+	// Interval is how often the nodes are checked
+	Interval time.Duration
+	// Timeout is how long a node has to respond to a check
+	Timeout time.Duration
+	// Threshold is the number of consecutive checks a node has to fail to be deregistered
+	Threshold int
+	// Concurrency is the number of nodes checked at once
+	Concurrency int
+	// Client is used to call the health endpoint of the nodes
+	Client client.Client
+}
+
+// Option sets an option of the checker
+type Option func(o *Options)
+
+// Interval sets how often the nodes are checked, by default every 10 seconds
+func Interval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
+
+// Timeout sets how long a node has to respond to a check, by default 5 seconds
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// Threshold sets the number of consecutive checks a node has to fail to be deregistered, by
+// default 3
+func Threshold(n int) Option {
+	return func(o *Options) {
+		o.Threshold = n
+	}
+}
+
+// Concurrency sets the number of nodes checked at once, by default 10
+func Concurrency(n int) Option {
+	return func(o *Options) {
+		o.Concurrency = n
+	}
+}
+
+// Client sets the client used to call the health endpoint of the nodes, by default the
+// default client
+func Client(c client.Client) Option {
+	return func(o *Options) {
+		o.Client = c
+	}
+}
+
+// Checker periodically calls the Debug.Health endpoint every micro service exposes on each node
+// in the registry. A node which fails the threshold of consecutive checks is deregistered, and is
+// deregistered again each time it's registered until it passes a check.
+type Checker struct {
+	registry registry.Registry
+	options  Options
+
+	sync.Mutex
+	// the number of consecutive checks each node failed, keyed by domain, service and node id
+	failures map[string]int
+
+	once sync.Once
+	exit chan bool
+}
+
+// NewChecker returns a checker of the nodes in the registry
+func NewChecker(r registry.Registry, opts ...Option) *Checker {
+	options := Options{
+		Interval:    10 * time.Second,
+		Timeout:     5 * time.Second,
+		Threshold:   3,
+		Concurrency: 10,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	return &Checker{
+		registry: r,
+		options:  options,
+		failures: make(map[string]int),
+		exit:     make(chan bool),
+	}
+}
+
+// Start checking the nodes at the interval until the checker is stopped
+func (c *Checker) Start() {
+	go func() {
+		t := time.NewTicker(c.options.Interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-c.exit:
+				return
+			case <-t.C:
+				c.Check()
+			}
+		}
+	}()
+}
+
+// Stop checking the nodes
+func (c *Checker) Stop() {
+	c.once.Do(func() {
+		close(c.exit)
+	})
+}
+
+// Check the nodes of the services in all domains once, deregistering the nodes which reached the
+// threshold of failed checks
+func (c *Checker) Check() {
+	services, err := c.registry.ListServices(registry.ListDomain(registry.WildcardDomain))
+	if err != nil {
+		logger.Errorf("Error listing services to check: %v", err)
+		return
+	}
+
+	// get the nodes of each service, as services may be listed without their nodes
+	seen := make(map[string]bool)
+	checked := make(map[string]bool)
+	sem := make(chan bool, c.options.Concurrency)
+	var wg sync.WaitGroup
+	for _, ls := range services {
+		domain := ls.Metadata["domain"]
+		if len(domain) == 0 {
+			domain = registry.DefaultDomain
+		}
+		if seen[domain+"/"+ls.Name] {
+			continue
+		}
+		seen[domain+"/"+ls.Name] = true
+
+		versions, err := c.registry.GetService(ls.Name, registry.GetDomain(domain))
+		if err != nil {
+			continue
+		}
+		for _, s := range versions {
+			for _, n := range s.Nodes {
+				key := domain + "/" + s.Name + "/" + n.Id
+				checked[key] = true
+
+				wg.Add(1)
+				sem <- true
+				go func(s *registry.Service, n *registry.Node, key string) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					c.checkNode(s, n, domain, key)
+				}(s, n, key)
+			}
+		}
+	}
+	wg.Wait()
+
+	// forget the failures of the nodes which are no longer registered
+	c.Lock()
+	for key := range c.failures {
+		if !checked[key] {
+			delete(c.failures, key)
+		}
+	}
+	c.Unlock()
+}
+
+func (c *Checker) checkNode(s *registry.Service, n *registry.Node, domain, key string) {
+	err := c.health(s.Name, n.Address)
+
+	c.Lock()
+	if err == nil {
+		delete(c.failures, key)
+		c.Unlock()
+		return
+	}
+	c.failures[key]++
+	failures := c.failures[key]
+	c.Unlock()
+
+	logger.Debugf("Health check of %s node %s failed %d times: %v", s.Name, n.Id, failures, err)
+	if failures < c.options.Threshold {
+		return
+	}
+
+	logger.Infof("Deregistering %s node %s which failed %d health checks: %v", s.Name, n.Id, failures, err)
+	svc := &registry.Service{Name: s.Name, Version: s.Version, Nodes: []*registry.Node{n}}
+	if err := c.registry.Deregister(svc, registry.DeregisterDomain(domain)); err != nil {
+		logger.Errorf("Error deregistering %s node %s: %v", s.Name, n.Id, err)
+	}
+}
+
+// health calls the health endpoint of the service at the address, returning an error if the
+// call fails or the service isn't healthy
+func (c *Checker) health(name, address string) error {
+	cl := c.options.Client
+	if cl == nil {
+		cl = client.DefaultClient
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.Timeout)
+	defer cancel()
+
+	req := cl.NewRequest(name, "Debug.Health", &pb.HealthRequest{})
+	rsp := &pb.HealthResponse{}
+	err := cl.Call(ctx, req, rsp,
+		client.WithAddress(address),
+		client.WithRetries(0),
+		client.WithRequestTimeout(c.options.Timeout),
+	)
+	if err != nil {
+		return err
+	}
+	if rsp.Status != "ok" {
+		return fmt.Errorf("unhealthy: %s", rsp.Status)
+	}
+	return nil
+}
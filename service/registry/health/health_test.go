@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	pb "github.com/micro/micro/v3/proto/debug"
+	"github.com/micro/micro/v3/service/client"
+	"github.com/micro/micro/v3/service/client/grpc"
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/registry/memory"
+)
+
+// fakeClient responds to the health checks with the status of the address called
+type fakeClient struct {
+	client.Client
+
+	sync.Mutex
+	status map[string]string
+}
+
+func (f *fakeClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	var options client.CallOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if req.Endpoint() != "Debug.Health" || len(options.Address) != 1 {
+		return errors.New("unexpected call")
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	status, ok := f.status[options.Address[0]]
+	if !ok {
+		return errors.New("connection refused")
+	}
+	rsp.(*pb.HealthResponse).Status = status
+	return nil
+}
+
+func (f *fakeClient) set(address, status string) {
+	f.Lock()
+	defer f.Unlock()
+	f.status[address] = status
+}
+
+func testService(name string, nodes ...string) *registry.Service {
+	s := &registry.Service{Name: name, Version: "latest"}
+	for _, n := range nodes {
+		s.Nodes = append(s.Nodes, &registry.Node{Id: n, Address: n + ":8080"})
+	}
+	return s
+}
+
+func nodes(t *testing.T, r registry.Registry, name, domain string) map[string]bool {
+	ids := map[string]bool{}
+	srvs, err := r.GetService(name, registry.GetDomain(domain))
+	if err == registry.ErrNotFound {
+		return ids
+	} else if err != nil {
+		t.Fatalf("Error getting %v: %v", name, err)
+	}
+	for _, s := range srvs {
+		for _, n := range s.Nodes {
+			ids[n.Id] = true
+		}
+	}
+	return ids
+}
+
+func TestChecker(t *testing.T) {
+	r := memory.NewRegistry()
+	if err := r.Register(testService("foo", "foo-1", "foo-2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(testService("bar", "bar-1"), registry.RegisterDomain("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &fakeClient{Client: grpc.NewClient(), status: map[string]string{
+		"foo-1:8080": "ok",
+		"bar-1:8080": "store unhealthy: timeout",
+	}}
+	checker := NewChecker(r, Threshold(2), Client(c))
+
+	// the failing nodes are kept until they reach the threshold
+	checker.Check()
+	if ids := nodes(t, r, "foo", registry.DefaultDomain); len(ids) != 2 {
+		t.Fatalf("Expected both nodes of foo after one check, got %v", ids)
+	}
+	checker.Check()
+	if ids := nodes(t, r, "foo", registry.DefaultDomain); len(ids) != 1 || !ids["foo-1"] {
+		t.Fatalf("Expected only the healthy node of foo, got %v", ids)
+	}
+	if ids := nodes(t, r, "bar", "other"); len(ids) != 0 {
+		t.Fatalf("Expected the unhealthy node of bar to be deregistered, got %v", ids)
+	}
+
+	// a failing node registered again is deregistered on its next failed check
+	if err := r.Register(testService("foo", "foo-2")); err != nil {
+		t.Fatal(err)
+	}
+	checker.Check()
+	if ids := nodes(t, r, "foo", registry.DefaultDomain); ids["foo-2"] {
+		t.Fatalf("Expected foo-2 to be deregistered again, got %v", ids)
+	}
+
+	// a node which recovers is kept
+	c.set("foo-2:8080", "ok")
+	if err := r.Register(testService("foo", "foo-2")); err != nil {
+		t.Fatal(err)
+	}
+	checker.Check()
+	if ids := nodes(t, r, "foo", registry.DefaultDomain); len(ids) != 2 {
+		t.Fatalf("Expected both nodes of foo once foo-2 recovered, got %v", ids)
+	}
+}
@@ -0,0 +1,225 @@
+// Package kubernetes is a registry which discovers services from the endpointslices of the
+// kubernetes services
+package kubernetes
+
+import (
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/runtime/kubernetes/api"
+	"github.com/micro/micro/v3/service/runtime/kubernetes/client"
+)
+
+const (
+	// serviceLabel is the label of an endpointslice set to the name of its service
+	serviceLabel = "kubernetes.io/service-name"
+	// versionLabel is the label of a service created by the runtime set to its version, which is
+	// copied to its endpointslices
+	versionLabel = "version"
+	// servicePort is the name of the port of a service created by the runtime
+	servicePort = "service-port"
+)
+
+type kubernetesRegistry struct {
+	options registry.Options
+	client  client.Client
+}
+
+// NewRegistry returns a registry which discovers the services from the endpointslices of the
+// kubernetes services. The domain of a service is its namespace. Kubernetes adds a pod to the
+// endpointslices of its services once it's ready and removes it when it's terminating, so
+// services aren't registered and Register and Deregister do nothing. The registry connects to the
+// api server at the address, e.g. the address of `kubectl proxy`, or the api server of the cluster
+// it's running in if no address is set.
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	k := &kubernetesRegistry{}
+	k.configure(opts...)
+	return k
+}
+
+func (k *kubernetesRegistry) configure(opts ...registry.Option) {
+	for _, o := range opts {
+		o(&k.options)
+	}
+	if len(k.options.Addrs) > 0 {
+		k.client = client.NewLocalClient(k.options.Addrs...)
+	} else if k.client == nil {
+		k.client = client.NewClusterClient()
+	}
+}
+
+func (k *kubernetesRegistry) Init(opts ...registry.Option) error {
+	k.configure(opts...)
+	return nil
+}
+
+func (k *kubernetesRegistry) Options() registry.Options {
+	return k.options
+}
+
+func (k *kubernetesRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+		logger.Tracef("Skipping registering %s, it's registered by kubernetes", s.Name)
+	}
+	return nil
+}
+
+func (k *kubernetesRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	return nil
+}
+
+// GetService returns the versions of the service with the pods which are ready
+func (k *kubernetesRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	slices, _, err := k.slices(namespace(options.Domain), map[string]string{serviceLabel: client.Format(name)})
+	if err != nil {
+		return nil, err
+	}
+
+	services := group(slices)
+	for _, s := range services {
+		s.Name = name
+	}
+	services = registry.FilterServices(services, options.Filters)
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	return services, nil
+}
+
+func (k *kubernetesRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	slices, _, err := k.slices(namespace(options.Domain), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	services := group(slices)
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+func (k *kubernetesRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return newWatcher(k, opts...)
+}
+
+func (k *kubernetesRegistry) String() string {
+	return "kubernetes"
+}
+
+// slices lists the endpointslices with the labels in the namespace, returning the resource
+// version of the list
+func (k *kubernetesRegistry) slices(ns string, labels map[string]string) ([]client.EndpointSlice, string, error) {
+	var list client.EndpointSliceList
+	r := &client.Resource{Kind: "endpointslice", Value: &list}
+	if err := k.client.Get(r, client.GetNamespace(ns), client.GetLabels(labels)); err != nil {
+		return nil, "", err
+	}
+
+	var version string
+	if list.Metadata != nil {
+		version = list.Metadata.ResourceVersion
+	}
+	return list.Items, version, nil
+}
+
+// namespace returns the namespace of the domain
+func namespace(domain string) string {
+	switch domain {
+	case "":
+		return registry.DefaultDomain
+	case registry.WildcardDomain:
+		return api.AllNamespaces
+	default:
+		return client.Format(domain)
+	}
+}
+
+// group the nodes of the endpointslices by the name, version and namespace of their services
+func group(slices []client.EndpointSlice) []*registry.Service {
+	versions := make(map[string]*registry.Service)
+	var keys []string
+	for _, slice := range slices {
+		for _, sn := range decode(slice) {
+			key := sn.Name + sn.Version + sn.Metadata["domain"]
+			s, ok := versions[key]
+			if !ok {
+				versions[key] = sn
+				keys = append(keys, key)
+				continue
+			}
+			s.Nodes = append(s.Nodes, sn.Nodes...)
+		}
+	}
+
+	services := make([]*registry.Service, 0, len(keys))
+	for _, key := range keys {
+		services = append(services, versions[key])
+	}
+	return services
+}
+
+// decode the ready endpoints of the endpointslice as services with a single node. The id of a
+// node is the name of its pod, and its address the address of the pod with the port of the
+// service created by the runtime, or the first port of the endpointslice.
+func decode(slice client.EndpointSlice) []*registry.Service {
+	if slice.Metadata == nil || len(slice.Metadata.Labels[serviceLabel]) == 0 {
+		return nil
+	}
+	name := slice.Metadata.Labels[serviceLabel]
+	domain := slice.Metadata.Namespace
+
+	var port *client.EndpointPort
+	for i, p := range slice.Ports {
+		if port == nil || p.Name == servicePort {
+			port = &slice.Ports[i]
+		}
+	}
+
+	var services []*registry.Service
+	for _, e := range slice.Endpoints {
+		if len(e.Addresses) == 0 || !ready(e.Conditions) {
+			continue
+		}
+
+		id := e.Addresses[0]
+		if e.TargetRef != nil && len(e.TargetRef.Name) > 0 {
+			id = e.TargetRef.Name
+		}
+		node := &registry.Node{
+			Id:       id,
+			Address:  e.Addresses[0],
+			Metadata: map[string]string{"domain": domain},
+		}
+		if port != nil && port.Port > 0 {
+			node.Address = net.JoinHostPort(e.Addresses[0], strconv.Itoa(port.Port))
+		}
+
+		services = append(services, &registry.Service{
+			Name:     name,
+			Version:  slice.Metadata.Labels[versionLabel],
+			Metadata: map[string]string{"domain": domain},
+			Nodes:    []*registry.Node{node},
+		})
+	}
+	return services
+}
+
+// ready returns whether the endpoint is ready to receive traffic, an unknown condition is ready
+func ready(c client.EndpointConditions) bool {
+	if c.Terminating != nil && *c.Terminating {
+		return false
+	}
+	return c.Ready == nil || *c.Ready
+}
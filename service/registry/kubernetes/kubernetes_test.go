@@ -0,0 +1,230 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/runtime/kubernetes/api"
+	"github.com/micro/micro/v3/service/runtime/kubernetes/client"
+)
+
+// fakeClient serves the endpointslices it's set, and the events sent to its watches
+type fakeClient struct {
+	client.Client
+
+	sync.Mutex
+	slices  []client.EndpointSlice
+	version string
+	watches chan *fakeWatch
+}
+
+func (f *fakeClient) Get(r *client.Resource, opts ...client.GetOption) error {
+	var options client.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if r.Kind != "endpointslice" {
+		return errors.New("unexpected kind " + r.Kind)
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	list := r.Value.(*client.EndpointSliceList)
+	list.Metadata = &client.ListMetadata{ResourceVersion: f.version}
+	for _, s := range f.slices {
+		if options.Namespace != api.AllNamespaces && s.Metadata.Namespace != options.Namespace {
+			continue
+		}
+		if name, ok := options.Labels[serviceLabel]; ok && s.Metadata.Labels[serviceLabel] != name {
+			continue
+		}
+		list.Items = append(list.Items, s)
+	}
+	return nil
+}
+
+func (f *fakeClient) set(version string, slices ...client.EndpointSlice) {
+	f.Lock()
+	defer f.Unlock()
+	f.version = version
+	f.slices = slices
+}
+
+func (f *fakeClient) Watch(r *client.Resource, opts ...client.WatchOption) (client.Watcher, error) {
+	var options client.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	w := &fakeWatch{events: make(chan client.Event), version: options.Params["resourceVersion"]}
+	f.watches <- w
+	return w, nil
+}
+
+type fakeWatch struct {
+	version string
+	events  chan client.Event
+	once    sync.Once
+}
+
+func (w *fakeWatch) Chan() <-chan client.Event {
+	return w.events
+}
+
+func (w *fakeWatch) Stop() {}
+
+func (w *fakeWatch) send(typ client.EventType, s client.EndpointSlice) {
+	b, _ := json.Marshal(s)
+	w.events <- client.Event{Type: typ, Object: b}
+}
+
+func (w *fakeWatch) end() {
+	w.once.Do(func() { close(w.events) })
+}
+
+type endpoint struct {
+	pod, address string
+	ready        bool
+}
+
+func slice(namespace, name, service, version string, endpoints ...endpoint) client.EndpointSlice {
+	s := client.EndpointSlice{
+		Metadata: &client.Metadata{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{serviceLabel: service, versionLabel: version},
+		},
+		Ports: []client.EndpointPort{{Name: "metrics", Port: 9090}, {Name: servicePort, Port: 8080}},
+	}
+	for _, e := range endpoints {
+		ready := e.ready
+		s.Endpoints = append(s.Endpoints, client.Endpoint{
+			Addresses:  []string{e.address},
+			Conditions: client.EndpointConditions{Ready: &ready},
+			TargetRef:  &client.ObjectReference{Kind: "Pod", Name: e.pod},
+		})
+	}
+	return s
+}
+
+func newTestRegistry() (*kubernetesRegistry, *fakeClient) {
+	c := &fakeClient{watches: make(chan *fakeWatch, 10)}
+	return &kubernetesRegistry{client: c}, c
+}
+
+func TestGetService(t *testing.T) {
+	r, c := newTestRegistry()
+	c.set("1",
+		slice("micro", "foo-abc", "foo", "latest", endpoint{"foo-1", "10.0.0.1", true}, endpoint{"foo-2", "10.0.0.2", false}),
+		slice("micro", "foo-def", "foo", "latest", endpoint{"foo-3", "10.0.0.3", true}),
+		slice("micro", "bar-abc", "bar", "latest", endpoint{"bar-1", "10.0.1.1", true}),
+		slice("other", "foo-abc", "foo", "v2", endpoint{"foo-4", "10.0.2.1", true}),
+	)
+
+	srvs, err := r.GetService("foo")
+	if err != nil {
+		t.Fatalf("Error getting service: %v", err)
+	}
+	if len(srvs) != 1 || srvs[0].Version != "latest" || srvs[0].Metadata["domain"] != "micro" {
+		t.Fatalf("Expected one version of foo, got %+v", srvs)
+	}
+	if len(srvs[0].Nodes) != 2 {
+		t.Fatalf("Expected the two ready pods, got %+v", srvs[0].Nodes)
+	}
+	if n := srvs[0].Nodes[0]; n.Id != "foo-1" || n.Address != "10.0.0.1:8080" {
+		t.Fatalf("Expected the pod with the service port, got %+v", n)
+	}
+
+	srvs, err = r.GetService("foo", registry.GetDomain(registry.WildcardDomain))
+	if err != nil || len(srvs) != 2 {
+		t.Fatalf("Expected foo in two namespaces, got %+v %v", srvs, err)
+	}
+	if _, err := r.GetService("baz"); err != registry.ErrNotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+
+	list, err := r.ListServices()
+	if err != nil || len(list) != 2 || list[0].Name != "bar" || list[1].Name != "foo" {
+		t.Fatalf("Expected bar and foo, got %+v %v", list, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	r, c := newTestRegistry()
+	c.set("1", slice("micro", "foo-abc", "foo", "latest", endpoint{"foo-1", "10.0.0.1", true}))
+
+	w, err := r.Watch(registry.WatchService("foo"))
+	if err != nil {
+		t.Fatalf("Error watching: %v", err)
+	}
+	defer w.Stop()
+
+	fw := <-c.watches
+	if fw.version != "1" {
+		t.Fatalf("Expected to watch from the version of the list, got %v", fw.version)
+	}
+
+	// a pod being added to the slice once it's ready is created
+	go fw.send(client.Modified, slice("micro", "foo-abc", "foo", "latest",
+		endpoint{"foo-1", "10.0.0.1", true}, endpoint{"foo-2", "10.0.0.2", true}))
+	expectResult(t, w, "create", "foo-2")
+
+	// a pod which isn't ready is deleted
+	go fw.send(client.Modified, slice("micro", "foo-abc", "foo", "latest",
+		endpoint{"foo-1", "10.0.0.1", false}, endpoint{"foo-2", "10.0.0.2", true}))
+	expectResult(t, w, "delete", "foo-1")
+
+	// the pods of a deleted slice are deleted
+	go fw.send(client.Added, slice("micro", "foo-def", "foo", "latest", endpoint{"foo-3", "10.0.0.3", true}))
+	expectResult(t, w, "create", "foo-3")
+	go fw.send(client.Deleted, slice("micro", "foo-def", "foo", "latest", endpoint{"foo-3", "10.0.0.3", true}))
+	expectResult(t, w, "delete", "foo-3")
+
+	// the slices are listed again when the watch ends, emitting the changes missed
+	c.set("2", slice("micro", "foo-abc", "foo", "latest", endpoint{"foo-4", "10.0.0.4", true}))
+	fw.end()
+	results := map[string]string{}
+	for i := 0; i < 2; i++ {
+		res := nextResult(t, w)
+		results[res.Service.Nodes[0].Id] = res.Action
+	}
+	if results["foo-2"] != "delete" || results["foo-4"] != "create" {
+		t.Fatalf("Expected foo-2 deleted and foo-4 created, got %v", results)
+	}
+	if fw = <-c.watches; fw.version != "2" {
+		t.Fatalf("Expected to watch from the version of the new list, got %v", fw.version)
+	}
+
+	w.Stop()
+	if _, err := w.Next(); err != registry.ErrWatcherStopped {
+		t.Fatalf("Expected watcher stopped, got %v", err)
+	}
+}
+
+func nextResult(t *testing.T, w registry.Watcher) *registry.Result {
+	t.Helper()
+	results := make(chan *registry.Result, 1)
+	go func() {
+		if res, err := w.Next(); err == nil {
+			results <- res
+		}
+	}()
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for result")
+	}
+	return nil
+}
+
+func expectResult(t *testing.T, w registry.Watcher, action, id string) {
+	t.Helper()
+	res := nextResult(t, w)
+	if res.Action != action || res.Service.Nodes[0].Id != id {
+		t.Fatalf("Expected %v of %v, got %v of %v", action, id, res.Action, res.Service.Nodes[0].Id)
+	}
+}
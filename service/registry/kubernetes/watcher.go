@@ -0,0 +1,232 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/runtime/kubernetes/client"
+)
+
+// retryDelay is the delay before listing the endpointslices again after an error
+var retryDelay = time.Second
+
+// watcher watches the endpointslices, emitting the nodes created, updated and deleted. The
+// endpointslices are listed and then watched from the version of the list, and listed again
+// if the watch ends, diffing the list against the nodes already emitted.
+type watcher struct {
+	registry  *kubernetesRegistry
+	namespace string
+	labels    map[string]string
+
+	next chan *registry.Result
+	exit chan bool
+	once sync.Once
+
+	sync.Mutex
+	watch client.Watcher
+
+	// the nodes of each endpointslice, keyed by the namespace and name of the slice and the id
+	// of the node
+	slices map[string]map[string]*registry.Service
+}
+
+func newWatcher(k *kubernetesRegistry, opts ...registry.WatchOption) (registry.Watcher, error) {
+	var options registry.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	// the nodes are diffed with the slices listed so the watch can't be resumed
+	if len(options.Resume) > 0 {
+		return nil, registry.ErrWatchExpired
+	}
+
+	w := &watcher{
+		registry:  k,
+		namespace: namespace(options.Domain),
+		next:      make(chan *registry.Result),
+		exit:      make(chan bool),
+		slices:    make(map[string]map[string]*registry.Service),
+	}
+	if len(options.Service) > 0 {
+		w.labels = map[string]string{serviceLabel: client.Format(options.Service)}
+	}
+
+	// list the endpointslices so the watcher only emits the changes after it was created
+	version, err := w.list(false)
+	if err != nil {
+		return nil, err
+	}
+	go w.run(version)
+	return w, nil
+}
+
+func (w *watcher) run(version string) {
+	for {
+		if len(version) > 0 {
+			w.watchSlices(version)
+		}
+
+		select {
+		case <-w.exit:
+			return
+		default:
+		}
+
+		var err error
+		if version, err = w.list(true); err != nil {
+			logger.Errorf("Error listing endpointslices: %v", err)
+			select {
+			case <-w.exit:
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+// list the endpointslices, returning the version of the list. If emit is true the changes since
+// the endpointslices were last listed or watched are emitted.
+func (w *watcher) list(emit bool) (string, error) {
+	slices, version, err := w.registry.slices(w.namespace, w.labels)
+	if err != nil {
+		return "", err
+	}
+
+	current := make(map[string]map[string]*registry.Service, len(slices))
+	for _, s := range slices {
+		current[sliceKey(s)] = nodes(s)
+	}
+	if emit {
+		for key, prev := range w.slices {
+			if _, ok := current[key]; !ok {
+				w.emit(diff(prev, nil))
+			}
+		}
+		for key, cur := range current {
+			w.emit(diff(w.slices[key], cur))
+		}
+	}
+	w.slices = current
+	return version, nil
+}
+
+// watchSlices watches the endpointslices from the version until the watch ends
+func (w *watcher) watchSlices(version string) {
+	params := map[string]string{"resourceVersion": version}
+	if len(w.labels) > 0 {
+		params["labelSelector"] = serviceLabel + "=" + w.labels[serviceLabel]
+	}
+	r := &client.Resource{Kind: "endpointslice"}
+	watch, err := w.registry.client.Watch(r, client.WatchNamespace(w.namespace), client.WatchParams(params))
+	if err != nil {
+		logger.Errorf("Error watching endpointslices: %v", err)
+		return
+	}
+
+	w.Lock()
+	select {
+	case <-w.exit:
+		w.Unlock()
+		watch.Stop()
+		return
+	default:
+		w.watch = watch
+	}
+	w.Unlock()
+	defer watch.Stop()
+
+	for ev := range watch.Chan() {
+		// an error, e.g. the version being too old, ends the watch so the slices are listed again
+		if ev.Type == client.Error {
+			return
+		}
+
+		var slice client.EndpointSlice
+		if err := json.Unmarshal(ev.Object, &slice); err != nil {
+			logger.Errorf("Error decoding endpointslice: %v", err)
+			continue
+		}
+		key := sliceKey(slice)
+
+		var cur map[string]*registry.Service
+		if ev.Type != client.Deleted {
+			cur = nodes(slice)
+		}
+		w.emit(diff(w.slices[key], cur))
+		if cur == nil {
+			delete(w.slices, key)
+		} else {
+			w.slices[key] = cur
+		}
+	}
+}
+
+func (w *watcher) emit(results []*registry.Result) {
+	for _, r := range results {
+		select {
+		case w.next <- r:
+		case <-w.exit:
+			return
+		}
+	}
+}
+
+func (w *watcher) Next() (*registry.Result, error) {
+	select {
+	case r := <-w.next:
+		return r, nil
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+func (w *watcher) Stop() {
+	w.once.Do(func() {
+		w.Lock()
+		close(w.exit)
+		if w.watch != nil {
+			w.watch.Stop()
+		}
+		w.Unlock()
+	})
+}
+
+func sliceKey(s client.EndpointSlice) string {
+	if s.Metadata == nil {
+		return ""
+	}
+	return s.Metadata.Namespace + "/" + s.Metadata.Name
+}
+
+// nodes returns the nodes of the endpointslice keyed by their ids
+func nodes(s client.EndpointSlice) map[string]*registry.Service {
+	services := decode(s)
+	nodes := make(map[string]*registry.Service, len(services))
+	for _, s := range services {
+		nodes[s.Nodes[0].Id] = s
+	}
+	return nodes
+}
+
+// diff returns the results of the changes between the nodes of an endpointslice
+func diff(prev, cur map[string]*registry.Service) []*registry.Result {
+	var results []*registry.Result
+	for id, s := range cur {
+		p, ok := prev[id]
+		if !ok {
+			results = append(results, &registry.Result{Action: "create", Service: s})
+		} else if !reflect.DeepEqual(p, s) {
+			results = append(results, &registry.Result{Action: "update", Service: s})
+		}
+	}
+	for id, s := range prev {
+		if _, ok := cur[id]; !ok {
+			results = append(results, &registry.Result{Action: "delete", Service: s})
+		}
+	}
+	return results
+}
@@ -17,6 +17,9 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,8 +29,9 @@ import (
 )
 
 var (
-	sendEventTime = 10 * time.Millisecond
-	ttlPruneTime  = time.Second
+	ttlPruneTime = time.Second
+	// historySize is the number of events kept to resume watches
+	historySize = 1000
 )
 
 type node struct {
@@ -46,11 +50,24 @@ type record struct {
 
 type Registry struct {
 	options registry.Options
+	// id of the registry, which is part of the tokens of its events
+	id string
 
 	sync.RWMutex
 	// records is a KV map with domain name as the key and a services map as the value
-	records  map[string]services
-	watchers map[string]*Watcher
+	records map[string]services
+	// the last events in the order they happened, read by the watchers
+	events []*event
+	// sequence of the last event
+	sequence uint64
+	// notify is closed when an event happens
+	notify chan bool
+}
+
+// event is a result with its position in the sequence of events
+type event struct {
+	sequence uint64
+	result   *registry.Result
 }
 
 // services is a KV map with service name as the key and a map of records as the value
@@ -72,9 +89,10 @@ func NewRegistry(opts ...registry.Option) registry.Registry {
 	}
 
 	reg := &Registry{
-		options:  options,
-		records:  map[string]services{registry.DefaultDomain: records},
-		watchers: make(map[string]*Watcher),
+		options: options,
+		id:      uuid.New().String(),
+		records: map[string]services{registry.DefaultDomain: records},
+		notify:  make(chan bool),
 	}
 
 	go reg.ttlPrune()
@@ -119,27 +137,50 @@ func (m *Registry) ttlPrune() {
 	}
 }
 
+// sendEvent adds the result to the events and notifies the watchers. Should be called under lock.
 func (m *Registry) sendEvent(r *registry.Result) {
+	m.sequence++
+	r.Token = fmt.Sprintf("%s:%d", m.id, m.sequence)
+
+	m.events = append(m.events, &event{sequence: m.sequence, result: r})
+	if len(m.events) > historySize {
+		m.events = m.events[len(m.events)-historySize:]
+	}
+
+	close(m.notify)
+	m.notify = make(chan bool)
+}
+
+// next returns the events after the sequence, and a channel closed when there are more events.
+// ErrWatchExpired is returned if the events after the sequence are no longer kept.
+func (m *Registry) next(sequence uint64) ([]*event, <-chan bool, error) {
 	m.RLock()
-	watchers := make([]*Watcher, 0, len(m.watchers))
-	for _, w := range m.watchers {
-		watchers = append(watchers, w)
+	defer m.RUnlock()
+
+	if len(m.events) == 0 || sequence >= m.sequence {
+		return nil, m.notify, nil
 	}
-	m.RUnlock()
+	first := m.events[0].sequence
+	if sequence+1 < first {
+		return nil, nil, registry.ErrWatchExpired
+	}
+	return m.events[sequence+1-first:], m.notify, nil
+}
 
-	for _, w := range watchers {
-		select {
-		case <-w.exit:
-			m.Lock()
-			delete(m.watchers, w.id)
-			m.Unlock()
-		default:
-			select {
-			case w.res <- r:
-			case <-time.After(sendEventTime):
-			}
-		}
+// resume returns the sequence of the event with the token
+func (m *Registry) resume(token string) (uint64, error) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 2 || parts[0] != m.id {
+		return 0, registry.ErrWatchExpired
+	}
+	sequence, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || sequence > m.sequence {
+		return 0, registry.ErrWatchExpired
+	}
+	if len(m.events) > 0 && sequence+1 < m.events[0].sequence {
+		return 0, registry.ErrWatchExpired
 	}
+	return sequence, nil
 }
 
 func (m *Registry) Init(opts ...registry.Option) error {
@@ -220,7 +261,7 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 			logger.Debugf("Registry added new service: %s, version: %s", s.Name, s.Version)
 		}
 		m.records[options.Domain] = srvs
-		go m.sendEvent(&registry.Result{Action: "create", Service: s})
+		m.sendEvent(&registry.Result{Action: "create", Service: s})
 	}
 
 	var addedNodes bool
@@ -259,7 +300,7 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 			logger.Debugf("Registry added new node to service: %s, version: %s", s.Name, s.Version)
 		}
-		go m.sendEvent(&registry.Result{Action: "update", Service: s})
+		m.sendEvent(&registry.Result{Action: "update", Service: s})
 	} else {
 		// refresh TTL and timestamp
 		for _, n := range s.Nodes {
@@ -326,7 +367,7 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 	// is cleanup
 	if len(version.Nodes) > 0 {
 		m.records[options.Domain][s.Name][s.Version] = version
-		go m.sendEvent(&registry.Result{Action: "update", Service: s})
+		m.sendEvent(&registry.Result{Action: "update", Service: s})
 		return nil
 	}
 
@@ -334,7 +375,7 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 	// registry and exit
 	if len(versions) == 1 {
 		delete(m.records[options.Domain], s.Name)
-		go m.sendEvent(&registry.Result{Action: "delete", Service: s})
+		m.sendEvent(&registry.Result{Action: "delete", Service: s})
 
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 			logger.Debugf("Registry removed service: %s", s.Name)
@@ -344,7 +385,7 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 
 	// there are other versions of the service running, so only remove this version of it
 	delete(m.records[options.Domain][s.Name], s.Version)
-	go m.sendEvent(&registry.Result{Action: "delete", Service: s})
+	m.sendEvent(&registry.Result{Action: "delete", Service: s})
 	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 		logger.Debugf("Registry removed service: %s, version: %s", s.Name, s.Version)
 	}
@@ -411,6 +452,14 @@ func (m *Registry) GetService(name string, opts ...registry.GetOption) ([]*regis
 		i++
 	}
 
+	// filter the nodes by their metadata
+	if len(options.Filters) > 0 {
+		result = registry.FilterServices(result, options.Filters)
+		if len(result) == 0 {
+			return nil, registry.ErrNotFound
+		}
+	}
+
 	return result, nil
 }
 
@@ -474,19 +523,24 @@ func (m *Registry) Watch(opts ...registry.WatchOption) (registry.Watcher, error)
 		wo.Domain = registry.DefaultDomain
 	}
 
-	// construct the watcher
-	w := &Watcher{
-		exit: make(chan bool),
-		res:  make(chan *registry.Result),
-		id:   uuid.New().String(),
-		wo:   wo,
-	}
+	// watch the events after the current one, or the event of the token when resuming
+	m.RLock()
+	defer m.RUnlock()
 
-	m.Lock()
-	m.watchers[w.id] = w
-	m.Unlock()
+	sequence := m.sequence
+	if len(wo.Resume) > 0 {
+		var err error
+		if sequence, err = m.resume(wo.Resume); err != nil {
+			return nil, err
+		}
+	}
 
-	return w, nil
+	return &Watcher{
+		registry: m,
+		sequence: sequence,
+		exit:     make(chan bool),
+		wo:       wo,
+	}, nil
 }
 
 func (m *Registry) String() string {
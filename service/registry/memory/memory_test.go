@@ -17,6 +17,8 @@ package memory
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -294,3 +296,78 @@ func TestMemoryWildcard(t *testing.T) {
 		t.Errorf("Expected 2 records, got %v", len(recs))
 	}
 }
+
+func TestMemoryFilter(t *testing.T) {
+	m := NewRegistry()
+	testSrvs := []*registry.Service{
+		{
+			Name:     "foo",
+			Version:  "1.0.0",
+			Metadata: map[string]string{"region": "eu-west-1"},
+			Nodes: []*registry.Node{
+				{Id: "foo-1.0.0-1", Address: "localhost:9999"},
+				{Id: "foo-1.0.0-2", Address: "localhost:9998", Metadata: map[string]string{"region": "us-east-1"}},
+			},
+		},
+		{
+			Name:    "foo",
+			Version: "1.0.1",
+			Nodes: []*registry.Node{
+				{Id: "foo-1.0.1-1", Address: "localhost:6666", Metadata: map[string]string{"region": "eu-west-1", "canary": "true"}},
+			},
+		},
+	}
+	for _, s := range testSrvs {
+		if err := m.Register(s); err != nil {
+			t.Fatalf("Register err: %v", err)
+		}
+	}
+
+	nodes := func(opts ...registry.GetOption) []string {
+		recs, err := m.GetService("foo", opts...)
+		if err == registry.ErrNotFound {
+			return nil
+		} else if err != nil {
+			t.Fatalf("Get err: %v", err)
+		}
+		var ids []string
+		for _, r := range recs {
+			for _, n := range r.Nodes {
+				ids = append(ids, n.Id)
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	tests := []struct {
+		name    string
+		filters map[string]string
+		nodes   []string
+	}{
+		{"NodeOrServiceMetadata", map[string]string{"region": "eu-west-1"}, []string{"foo-1.0.0-1", "foo-1.0.1-1"}},
+		{"NodeMetadata", map[string]string{"region": "us-east-1"}, []string{"foo-1.0.0-2"}},
+		{"Version", map[string]string{"version": "1.0.1"}, []string{"foo-1.0.1-1"}},
+		{"AllFilters", map[string]string{"region": "eu-west-1", "canary": "true"}, []string{"foo-1.0.1-1"}},
+		{"NoMatch", map[string]string{"region": "ap-south-1"}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []registry.GetOption
+			for k, v := range tc.filters {
+				opts = append(opts, registry.Filter(k, v))
+			}
+			if ids := nodes(opts...); !reflect.DeepEqual(ids, tc.nodes) {
+				t.Errorf("Expected nodes %v, got %v", tc.nodes, ids)
+			}
+			if ids := nodes(append(opts, registry.GetDomain(registry.WildcardDomain))...); !reflect.DeepEqual(ids, tc.nodes) {
+				t.Errorf("Expected nodes %v in the wildcard domain, got %v", tc.nodes, ids)
+			}
+		})
+	}
+
+	// the registered services aren't modified by the filters
+	if ids := nodes(); len(ids) != 3 {
+		t.Errorf("Expected 3 nodes without filters, got %v", ids)
+	}
+}
@@ -15,48 +15,64 @@
 package memory
 
 import (
-	"errors"
-
 	"github.com/micro/micro/v3/service/registry"
 )
 
+// Watcher returns the events of the registry after its sequence in order
 type Watcher struct {
-	id   string
-	wo   registry.WatchOptions
-	res  chan *registry.Result
-	exit chan bool
+	registry *Registry
+	sequence uint64
+	wo       registry.WatchOptions
+	exit     chan bool
 }
 
 func (m *Watcher) Next() (*registry.Result, error) {
 	for {
-		select {
-		case r := <-m.res:
-			if r.Service == nil {
-				continue
-			}
-
-			if len(m.wo.Service) > 0 && m.wo.Service != r.Service.Name {
-				continue
-			}
+		events, notify, err := m.registry.next(m.sequence)
+		if err != nil {
+			return nil, err
+		}
 
-			// extract domain from service metadata
-			var domain string
-			if r.Service.Metadata != nil && len(r.Service.Metadata["domain"]) > 0 {
-				domain = r.Service.Metadata["domain"]
-			} else {
-				domain = registry.DefaultDomain
+		for _, ev := range events {
+			m.sequence = ev.sequence
+			if m.matches(ev.result) {
+				return ev.result, nil
 			}
+		}
+		if len(events) > 0 {
+			continue
+		}
 
-			// only send the event if watching the wildcard or this specific domain
-			if m.wo.Domain == registry.WildcardDomain || m.wo.Domain == domain {
-				return r, nil
-			}
+		select {
+		case <-notify:
 		case <-m.exit:
-			return nil, errors.New("watcher stopped")
+			return nil, registry.ErrWatcherStopped
 		}
 	}
 }
 
+// matches returns true if the result is of the service and domain watched
+func (m *Watcher) matches(r *registry.Result) bool {
+	if r.Service == nil {
+		return false
+	}
+
+	if len(m.wo.Service) > 0 && m.wo.Service != r.Service.Name {
+		return false
+	}
+
+	// extract domain from service metadata
+	var domain string
+	if r.Service.Metadata != nil && len(r.Service.Metadata["domain"]) > 0 {
+		domain = r.Service.Metadata["domain"]
+	} else {
+		domain = registry.DefaultDomain
+	}
+
+	// only send the event if watching the wildcard or this specific domain
+	return m.wo.Domain == registry.WildcardDomain || m.wo.Domain == domain
+}
+
 func (m *Watcher) Stop() {
 	select {
 	case <-m.exit:
@@ -21,22 +21,17 @@ import (
 )
 
 func TestWatcher(t *testing.T) {
-	w := &Watcher{
-		id:   "test",
-		res:  make(chan *registry.Result),
-		exit: make(chan bool),
-		wo: registry.WatchOptions{
-			Domain: registry.WildcardDomain,
-		},
-	}
-
-	go func() {
-		w.res <- &registry.Result{
-			Service: &registry.Service{Name: "foo"},
-		}
-	}()
+	m := NewRegistry()
+	w, err := m.Watch(registry.WatchDomain(registry.WildcardDomain))
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
 
-	_, err := w.Next()
+	if err := m.Register(&registry.Service{Name: "foo"}); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+
+	_, err = w.Next()
 	if err != nil {
 		t.Fatal("unexpected err", err)
 	}
@@ -47,3 +42,69 @@ func TestWatcher(t *testing.T) {
 		t.Fatal("expected error on Next()")
 	}
 }
+
+func TestWatcherResume(t *testing.T) {
+	m := NewRegistry()
+	w, err := m.Watch(registry.WatchService("foo"))
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+
+	foo := &registry.Service{Name: "foo", Version: "1", Nodes: []*registry.Node{{Id: "foo-1"}}}
+	if err := m.Register(foo); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	res, err := w.Next()
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if res.Action != "create" || len(res.Token) == 0 {
+		t.Fatalf("expected a create with a token, got %v %q", res.Action, res.Token)
+	}
+	w.Stop()
+
+	// the events missed while not watching are returned when resuming, filtered by service
+	if err := m.Register(&registry.Service{Name: "bar", Version: "1"}); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	if err := m.Deregister(foo); err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	w, err = m.Watch(registry.WatchService("foo"), registry.WatchResume(res.Token))
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	defer w.Stop()
+	if res, err := w.Next(); err != nil || res.Action != "delete" || res.Service.Name != "foo" {
+		t.Fatalf("expected foo to be deleted, got %+v: %v", res, err)
+	}
+
+	// tokens of another registry can't be resumed
+	if _, err := NewRegistry().Watch(registry.WatchResume(res.Token)); err != registry.ErrWatchExpired {
+		t.Fatalf("expected the watch to expire, got %v", err)
+	}
+}
+
+func TestWatcherExpired(t *testing.T) {
+	size := historySize
+	historySize = 2
+	defer func() { historySize = size }()
+
+	m := NewRegistry()
+	w, err := m.Watch()
+	if err != nil {
+		t.Fatal("unexpected err", err)
+	}
+	defer w.Stop()
+
+	for _, v := range []string{"1", "2", "3"} {
+		if err := m.Register(&registry.Service{Name: "foo", Version: v}); err != nil {
+			t.Fatal("unexpected err", err)
+		}
+	}
+
+	// the first event is no longer kept so the watcher can't return the events in order
+	if _, err := w.Next(); err != registry.ErrWatchExpired {
+		t.Fatalf("expected the watch to expire, got %v", err)
+	}
+}
@@ -60,6 +60,8 @@ type WatchOptions struct {
 	Context context.Context
 	// Domain to watch
 	Domain string
+	// Resume the watch after the result with the token
+	Resume string
 }
 
 type DeregisterOptions struct {
@@ -72,6 +74,8 @@ type GetOptions struct {
 	Context context.Context
 	// Domain to scope the request to
 	Domain string
+	// Filters the nodes must match, keyed by metadata key
+	Filters map[string]string
 }
 
 type ListOptions struct {
@@ -144,6 +148,15 @@ func WatchDomain(d string) WatchOption {
 	}
 }
 
+// WatchResume resumes a watch after the result with the token, so the results missed while
+// reconnecting are returned first. ErrWatchExpired is returned by Watch or the first call to Next
+// if the watch can't be resumed.
+func WatchResume(token string) WatchOption {
+	return func(o *WatchOptions) {
+		o.Resume = token
+	}
+}
+
 func DeregisterContext(ctx context.Context) DeregisterOption {
 	return func(o *DeregisterOptions) {
 		o.Context = ctx
@@ -168,6 +181,18 @@ func GetDomain(d string) GetOption {
 	}
 }
 
+// Filter the nodes returned to those with the metadata value, falling back to the metadata of
+// their service. The version key also matches the version of the service. Services with no
+// matching nodes aren't returned.
+func Filter(key, value string) GetOption {
+	return func(o *GetOptions) {
+		if o.Filters == nil {
+			o.Filters = make(map[string]string)
+		}
+		o.Filters[key] = value
+	}
+}
+
 func ListContext(ctx context.Context) ListOption {
 	return func(o *ListOptions) {
 		o.Context = ctx
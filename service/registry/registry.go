@@ -26,6 +26,9 @@ var (
 	ErrNotFound = errors.New("service not found")
 	// ErrWatcherStopped error when watcher is stopped
 	ErrWatcherStopped = errors.New("watcher stopped")
+	// ErrWatchExpired error when a watch can't be resumed from its token, e.g. as the events
+	// after it are no longer kept. The services should be listed again before watching.
+	ErrWatchExpired = errors.New("watch expired")
 )
 
 const (
@@ -33,6 +36,13 @@ const (
 	WildcardDomain = "*"
 	// DefaultDomain to use if none was provided in options
 	DefaultDomain = "micro"
+	// ZoneKey is the metadata key of the zone a node runs in, e.g. eu-west-1a
+	ZoneKey = "zone"
+	// RegionKey is the metadata key of the region a node runs in, e.g. eu-west-1
+	RegionKey = "region"
+	// WeightKey is the metadata key of the weight of a node, the share of the calls it's sent
+	// relative to the other nodes when using the weighted selector
+	WeightKey = "weight"
 )
 
 // Registry provides an interface for service discovery
@@ -77,8 +87,8 @@ type Value struct {
 }
 
 // GetService from the registry
-func GetService(service string) ([]*Service, error) {
-	return DefaultRegistry.GetService(service)
+func GetService(service string, opts ...GetOption) ([]*Service, error) {
+	return DefaultRegistry.GetService(service, opts...)
 }
 
 // ListServices in the registry
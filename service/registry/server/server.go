@@ -9,6 +9,7 @@ import (
 	log "github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/service/registry/handler"
+	"github.com/micro/micro/v3/service/registry/health"
 	"github.com/micro/micro/v3/service/registry/util"
 	"github.com/urfave/cli/v2"
 )
@@ -22,6 +23,30 @@ var (
 	topic = "registry.events"
 )
 
+var (
+	// Flags specific to the registry service
+	Flags = []cli.Flag{
+		&cli.DurationFlag{
+			Name:    "health_check_interval",
+			EnvVars: []string{"MICRO_REGISTRY_HEALTH_CHECK_INTERVAL"},
+			Usage:   "Interval at which the health of the registered nodes is checked, 0 disables the checks",
+			Value:   10 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    "health_check_timeout",
+			EnvVars: []string{"MICRO_REGISTRY_HEALTH_CHECK_TIMEOUT"},
+			Usage:   "Timeout of the health check of a node",
+			Value:   5 * time.Second,
+		},
+		&cli.IntFlag{
+			Name:    "health_check_threshold",
+			EnvVars: []string{"MICRO_REGISTRY_HEALTH_CHECK_THRESHOLD"},
+			Usage:   "Number of consecutive health checks a node has to fail to be deregistered",
+			Value:   3,
+		},
+	}
+)
+
 // Sub processes registry events
 type subscriber struct {
 	// id is registry id
@@ -108,6 +133,19 @@ func Run(ctx *cli.Context) error {
 		Event: service.NewEvent(topic),
 	})
 
+	// check the health of the registered nodes, deregistering the failing nodes before their
+	// ttl expires
+	if i := ctx.Duration("health_check_interval"); i > 0 {
+		checker := health.NewChecker(registry.DefaultRegistry,
+			health.Interval(i),
+			health.Timeout(ctx.Duration("health_check_timeout")),
+			health.Threshold(ctx.Int("health_check_threshold")),
+			health.Client(srv.Client()),
+		)
+		checker.Start()
+		defer checker.Stop()
+	}
+
 	// run the service
 	if err := srv.Run(); err != nil {
 		log.Fatal(err)
@@ -29,6 +29,10 @@ type Watcher interface {
 type Result struct {
 	Action  string
 	Service *Service
+	// Token is an opaque position of the result in the events of the registry, which is passed
+	// to WatchResume to resume watching after the result. It's empty if the registry doesn't
+	// support resuming watches.
+	Token string
 }
 
 // EventType defines registry event type
@@ -111,6 +111,7 @@ func (s *svc) Lookup(service string, q ...router.LookupOption) ([]router.Route,
 			Network: query.Network,
 			Router:  query.Router,
 			Link:    query.Link,
+			Version: query.Version,
 		},
 	}, s.callOpts...)
 
@@ -130,6 +131,7 @@ func (s *svc) Lookup(service string, q ...router.LookupOption) ([]router.Route,
 			Link:     route.Link,
 			Metric:   route.Metric,
 			Metadata: route.Metadata,
+			Version:  route.Version,
 		}
 	}
 
@@ -21,6 +21,7 @@ func (t *table) Create(r router.Route) error {
 		Network: r.Network,
 		Link:    r.Link,
 		Metric:  r.Metric,
+		Version: r.Version,
 	}
 
 	if _, err := t.table.Create(context.DefaultContext, route, t.callOpts...); err != nil {
@@ -39,6 +40,7 @@ func (t *table) Delete(r router.Route) error {
 		Network: r.Network,
 		Link:    r.Link,
 		Metric:  r.Metric,
+		Version: r.Version,
 	}
 
 	if _, err := t.table.Delete(context.DefaultContext, route, t.callOpts...); err != nil {
@@ -57,6 +59,7 @@ func (t *table) Update(r router.Route) error {
 		Network: r.Network,
 		Link:    r.Link,
 		Metric:  r.Metric,
+		Version: r.Version,
 	}
 
 	if _, err := t.table.Update(context.DefaultContext, route, t.callOpts...); err != nil {
@@ -90,6 +93,7 @@ func (t *table) Read(opts ...router.ReadOption) ([]router.Route, error) {
 			Network: route.Network,
 			Link:    route.Link,
 			Metric:  route.Metric,
+			Version: route.Version,
 		}
 	}
 
@@ -63,6 +63,7 @@ func (w *watcher) watch(stream pb.Router_WatchService) error {
 			Link:     resp.Route.Link,
 			Metric:   resp.Route.Metric,
 			Metadata: resp.Route.Metadata,
+			Version:  resp.Route.Version,
 		}
 
 		event := &router.Event{
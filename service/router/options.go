@@ -16,10 +16,12 @@ package router
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/service/registry/memory"
+	"github.com/micro/micro/v3/service/store"
 )
 
 // Options are router options
@@ -38,6 +40,12 @@ type Options struct {
 	Context context.Context
 	// Cache routes
 	Cache bool
+	// Store the cached routes are persisted in
+	Store store.Store
+	// SaveInterval is the time at which the routes are saved in the store once they've changed
+	SaveInterval time.Duration
+	// Static routes merged with the routes looked up, e.g. to services which don't register
+	Static []Route
 }
 
 // Id sets Router Id
@@ -82,6 +90,30 @@ func Cache() Option {
 	}
 }
 
+// Store persists the cached routes in the store, so a router which restarts starts with the
+// routes it had rather than waiting for them to be loaded from the registry
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// StaticRoutes adds static routes which are merged with the routes looked up, e.g. to services
+// which don't register themselves
+func StaticRoutes(routes ...Route) Option {
+	return func(o *Options) {
+		o.Static = append(o.Static, routes...)
+	}
+}
+
+// SaveInterval sets the time at which the routes are saved in the store once they've changed, by
+// default 10 seconds
+func SaveInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.SaveInterval = d
+	}
+}
+
 // DefaultOptions returns router default options
 func DefaultOptions() Options {
 	return Options{
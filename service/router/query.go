@@ -30,6 +30,8 @@ type LookupOptions struct {
 	Router string
 	// Link to query
 	Link string
+	// Version of the service to query
+	Version string
 }
 
 // LookupAddress sets service to query
@@ -67,6 +69,13 @@ func LookupLink(link string) LookupOption {
 	}
 }
 
+// LookupVersion sets the version of the service to query
+func LookupVersion(v string) LookupOption {
+	return func(o *LookupOptions) {
+		o.Version = v
+	}
+}
+
 // NewLookup creates new query and returns it
 func NewLookup(opts ...LookupOption) LookupOptions {
 	// default options
@@ -76,6 +85,7 @@ func NewLookup(opts ...LookupOption) LookupOptions {
 		Network: "*",
 		Router:  "*",
 		Link:    DefaultLink,
+		Version: "*",
 	}
 
 	for _, o := range opts {
@@ -86,7 +96,7 @@ func NewLookup(opts ...LookupOption) LookupOptions {
 }
 
 // isMatch checks if the route matches given query options
-func isMatch(route Route, address, gateway, network, rtr, link string) bool {
+func isMatch(route Route, address, gateway, network, rtr, link, version string) bool {
 	// matches the values provided
 	match := func(a, b string) bool {
 		if a == "*" || b == "*" || a == b {
@@ -108,6 +118,7 @@ func isMatch(route Route, address, gateway, network, rtr, link string) bool {
 		{rtr, route.Router},
 		{address, route.Address},
 		{link, route.Link},
+		{version, route.Version},
 	}
 
 	for _, v := range values {
@@ -127,12 +138,13 @@ func Filter(routes []Route, opts LookupOptions) []Route {
 	network := opts.Network
 	rtr := opts.Router
 	link := opts.Link
+	version := opts.Version
 
 	// routeMap stores the routes we're going to advertise
 	routeMap := make(map[string][]Route)
 
 	for _, route := range routes {
-		if isMatch(route, address, gateway, network, rtr, link) {
+		if isMatch(route, address, gateway, network, rtr, link, version) {
 			// add matchihg route to the routeMap
 			routeKey := route.Service + "@" + route.Network
 			routeMap[routeKey] = append(routeMap[routeKey], route)
@@ -174,6 +174,7 @@ func (r *registryRouter) createRoutes(service *registry.Service, network string)
 			Link:     router.DefaultLink,
 			Metric:   router.DefaultMetric,
 			Metadata: node.Metadata,
+			Version:  service.Version,
 		})
 	}
 
@@ -318,17 +319,32 @@ func (r *registryRouter) Close() error {
 	return nil
 }
 
-// lookup retrieves all the routes for a given service and creates them in the routing table
+// Lookup retrieves all the routes for a given service, merged with its static routes
 func (r *registryRouter) Lookup(service string, opts ...router.LookupOption) ([]router.Route, error) {
 	q := router.NewLookup(opts...)
 
-	// if we find the routes filter and return them
+	routes, err := r.lookup(service)
+
+	// the static routes are used even if the service can't be looked up
+	if static := r.staticRoutes(service); len(static) > 0 {
+		routes, err = append(routes, static...), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	routes = router.Filter(routes, q)
+	if len(routes) == 0 {
+		return nil, router.ErrRouteNotFound
+	}
+	return routes, nil
+}
+
+// lookup retrieves all the routes for a given service and creates them in the routing table
+func (r *registryRouter) lookup(service string) ([]router.Route, error) {
+	// if we find the routes return them
 	routes, err := r.table.Read(router.ReadService(service))
 	if err == nil {
-		routes = router.Filter(routes, q)
-		if len(routes) == 0 {
-			return nil, router.ErrRouteNotFound
-		}
 		return routes, nil
 	}
 
@@ -358,16 +374,40 @@ func (r *registryRouter) Lookup(service string, opts ...router.LookupOption) ([]
 		}
 	}
 
-	routes = router.Filter(routes, q)
-	if len(routes) == 0 {
-		return nil, router.ErrRouteNotFound
-	}
 	return routes, nil
 }
 
-// watchRegistry watches registry and updates routing table based on the received events.
+// staticRoutes returns the static routes of the service, defaulting the fields which weren't set
+func (r *registryRouter) staticRoutes(service string) []router.Route {
+	r.RLock()
+	defer r.RUnlock()
+
+	var routes []router.Route
+	for _, route := range r.options.Static {
+		if route.Service != service {
+			continue
+		}
+		if len(route.Network) == 0 {
+			route.Network = registry.DefaultDomain
+		}
+		if len(route.Router) == 0 {
+			route.Router = r.options.Id
+		}
+		if len(route.Link) == 0 {
+			route.Link = router.DefaultLink
+		}
+		if route.Metric == 0 {
+			route.Metric = router.DefaultMetric
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// watchRegistry watches registry and updates routing table based on the received events, setting
+// the token of the last event processed so the watch can be resumed after it.
 // It returns error if either the registry watcher fails with error or if the routing table update fails.
-func (r *registryRouter) watchRegistry(w registry.Watcher) error {
+func (r *registryRouter) watchRegistry(w registry.Watcher, token *string) error {
 	exit := make(chan bool)
 
 	defer func() {
@@ -415,6 +455,7 @@ func (r *registryRouter) watchRegistry(w registry.Watcher) error {
 		if err := r.loadRoutes(res.Service.Name, domain); err != nil {
 			return err
 		}
+		*token = res.Token
 	}
 
 	return nil
@@ -429,6 +470,15 @@ func (r *registryRouter) run() error {
 	// set running
 	r.running = true
 
+	// start with the routes saved in the store while they're loaded from the registry, then keep
+	// them saved
+	if r.options.Store != nil {
+		if err := r.restoreRoutes(); err != nil {
+			logger.Errorf("Error restoring the routes from the store: %v", err)
+		}
+		go r.persistRoutes()
+	}
+
 	// create a refresh notify channel
 	refresh := make(chan bool, 1)
 
@@ -475,31 +525,45 @@ func (r *registryRouter) run() error {
 	}()
 
 	go func() {
+		// token of the last event processed
+		var token string
+
 		for {
 			select {
 			case <-r.exit:
 				return
 			default:
 				logger.Tracef("Router starting registry watch")
-				w, err := r.options.Registry.Watch(registry.WatchDomain(registry.WildcardDomain))
+
+				// resume the watch after the last event processed so the routes don't need to be
+				// refreshed unless the registry no longer has the events
+				opts := []registry.WatchOption{registry.WatchDomain(registry.WildcardDomain)}
+				if len(token) > 0 {
+					opts = append(opts, registry.WatchResume(token))
+				}
+				w, err := r.options.Registry.Watch(opts...)
 				if err != nil {
 					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 						logger.Debugf("failed creating registry watcher: %v", err)
 					}
 					time.Sleep(time.Second)
 					// in the event of an error reload routes
+					token = ""
 					refreshRoutes()
 					continue
 				}
 
 				// watchRegistry calls stop when it's done
-				if err := r.watchRegistry(w); err != nil {
+				if err := r.watchRegistry(w, &token); err != nil {
 					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 						logger.Debugf("Error watching the registry: %v", err)
 					}
 					time.Sleep(time.Second)
-					// in the event of an error reload routes
-					refreshRoutes()
+					// reload the routes unless the watch can be resumed
+					if err == registry.ErrWatchExpired || len(token) == 0 {
+						token = ""
+						refreshRoutes()
+					}
 				}
 			}
 		}
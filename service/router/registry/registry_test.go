@@ -17,6 +17,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/micro/micro/v3/service/registry"
 	"github.com/micro/micro/v3/service/registry/memory"
 	"github.com/micro/micro/v3/service/router"
 )
@@ -36,3 +37,40 @@ func TestRouterClose(t *testing.T) {
 		t.Logf("TestRouterStartStop STOPPED")
 	}
 }
+
+func TestRouterStaticRoutes(t *testing.T) {
+	reg := memory.NewRegistry()
+	err := reg.Register(&registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "10.0.0.1:8080"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouter(router.Registry(reg), router.StaticRoutes(
+		router.Route{Service: "foo", Address: "10.0.1.1:8080"},
+		router.Route{Service: "bar", Address: "10.0.2.1:8080"},
+	))
+	defer r.Close()
+
+	// the static routes are merged with the routes of the registry
+	routes, err := r.Lookup("foo")
+	if err != nil || len(routes) != 2 {
+		t.Fatalf("Expected the registry and static routes of foo, got %v: %v", routes, err)
+	}
+
+	// the static routes are used for the services which don't register
+	routes, err = r.Lookup("bar")
+	if err != nil || len(routes) != 1 {
+		t.Fatalf("Expected the static route of bar, got %v: %v", routes, err)
+	}
+	if routes[0].Address != "10.0.2.1:8080" || routes[0].Network != registry.DefaultDomain {
+		t.Errorf("Unexpected static route %+v", routes[0])
+	}
+
+	if _, err := r.Lookup("baz"); err != router.ErrRouteNotFound {
+		t.Errorf("Expected no routes for baz, got %v", err)
+	}
+}
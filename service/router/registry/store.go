@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/router"
+	"github.com/micro/micro/v3/service/store"
+)
+
+const (
+	// defaultSaveInterval is the time at which the routes are saved in the store once they've
+	// changed, unless set with the router options
+	defaultSaveInterval = time.Second * 10
+)
+
+// storeKey is the key of the routes of the network in the store
+func (r *registryRouter) storeKey() string {
+	return "router/routes/" + r.options.Network
+}
+
+// restoreRoutes creates the routes saved in the store in the table
+func (r *registryRouter) restoreRoutes() error {
+	recs, err := r.options.Store.Read(r.storeKey())
+	if err == store.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var routes []router.Route
+	if err := json.Unmarshal(recs[0].Value, &routes); err != nil {
+		return err
+	}
+	for _, route := range routes {
+		if err := r.table.Create(route); err != nil && err != router.ErrDuplicateRoute {
+			return err
+		}
+	}
+
+	logger.Debugf("Restored %d routes from the store", len(routes))
+	return nil
+}
+
+// saveRoutes saves the routes in the table in the store
+func (r *registryRouter) saveRoutes() error {
+	routes, err := r.table.Read()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(routes)
+	if err != nil {
+		return err
+	}
+	return r.options.Store.Write(&store.Record{Key: r.storeKey(), Value: b})
+}
+
+// persistRoutes saves the routes in the store at the save interval once they've changed, and when
+// the router is closed
+func (r *registryRouter) persistRoutes() {
+	w, err := r.table.Watch()
+	if err != nil {
+		logger.Errorf("Error watching the routes to persist them: %v", err)
+		return
+	}
+	defer w.Stop()
+
+	events, _ := w.Chan()
+	interval := r.options.SaveInterval
+	if interval <= 0 {
+		interval = defaultSaveInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	var changed bool
+	save := func() {
+		if !changed {
+			return
+		}
+		if err := r.saveRoutes(); err != nil {
+			logger.Errorf("Error saving the routes in the store: %v", err)
+			return
+		}
+		changed = false
+	}
+
+	for {
+		select {
+		case <-r.exit:
+			save()
+			return
+		case <-events:
+			changed = true
+		case <-t.C:
+			save()
+		}
+	}
+}
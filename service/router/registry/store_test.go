@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/registry"
+	"github.com/micro/micro/v3/service/registry/memory"
+	"github.com/micro/micro/v3/service/router"
+	smemory "github.com/micro/micro/v3/service/store/memory"
+)
+
+func TestRouterStore(t *testing.T) {
+	reg := memory.NewRegistry()
+	err := reg.Register(&registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "10.0.0.1:8080"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := smemory.NewStore()
+
+	// the routes loaded from the registry are saved in the store
+	r := NewRouter(router.Registry(reg), router.Cache(), router.Store(st), router.SaveInterval(10*time.Millisecond))
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := st.Read("router/routes/" + router.DefaultNetwork); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Expected the routes to be saved in the store: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Close()
+
+	// a router started without the service in its registry is restored with its route
+	r = NewRouter(router.Registry(memory.NewRegistry()), router.Cache(), router.Store(st))
+	defer r.Close()
+
+	routes, err := r.Table().Read(router.ReadService("foo"))
+	if err != nil || len(routes) != 1 {
+		t.Fatalf("Expected the route of foo to be restored, got %v: %v", routes, err)
+	}
+	if routes[0].Address != "10.0.0.1:8080" || routes[0].Version != "1.0.0" {
+		t.Errorf("Unexpected route restored %+v", routes[0])
+	}
+}
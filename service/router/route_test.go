@@ -36,3 +36,18 @@ func TestHash(t *testing.T) {
 		t.Errorf("identical routes result in different hashes")
 	}
 }
+
+func TestFilterVersion(t *testing.T) {
+	routes := []Route{
+		{Service: "foo", Address: "10.0.0.1:8080", Link: DefaultLink, Version: "v1"},
+		{Service: "foo", Address: "10.0.0.2:8080", Link: DefaultLink, Version: "v2"},
+	}
+
+	if filtered := Filter(routes, NewLookup()); len(filtered) != 2 {
+		t.Errorf("Expected routes of all versions, got %v", filtered)
+	}
+	filtered := Filter(routes, NewLookup(LookupVersion("v2")))
+	if len(filtered) != 1 || filtered[0].Address != "10.0.0.2:8080" {
+		t.Errorf("Expected the route of v2, got %v", filtered)
+	}
+}
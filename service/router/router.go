@@ -100,6 +100,8 @@ type Route struct {
 	Metric int64
 	// Metadata for the route
 	Metadata map[string]string
+	// Version of the service
+	Version string
 }
 
 // Hash returns route hash sum.
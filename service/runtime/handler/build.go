@@ -41,6 +41,9 @@ func (b *Build) Read(ctx context.Context, req *pb.Service, stream pb.Build_ReadS
 	} else if err != nil {
 		return err
 	}
+	if c, ok := build.(io.Closer); ok {
+		defer c.Close()
+	}
 
 	// read bytes from the store and stream it to the client
 	buffer := make([]byte, bufferSize)
@@ -136,6 +136,20 @@ var tests = []testcase{
 		Method: "GET",
 		URI:    "/api/v1/namespaces/default/pods/foolog/log",
 	},
+	testcase{
+		ReqFn: func(opts *Options) *Request {
+			return NewRequest(opts).Get().Resource("endpointslice").Namespace("test")
+		},
+		Method: "GET",
+		URI:    "/apis/discovery.k8s.io/v1/namespaces/test/endpointslices/",
+	},
+	testcase{
+		ReqFn: func(opts *Options) *Request {
+			return NewRequest(opts).Get().Resource("endpointslice").Namespace(AllNamespaces)
+		},
+		Method: "GET",
+		URI:    "/apis/discovery.k8s.io/v1/endpointslices/",
+	},
 }
 
 var wrappedHandler = func(test *testcase, t *testing.T) http.HandlerFunc {
@@ -27,6 +27,10 @@ import (
 	"github.com/micro/micro/v3/service/logger"
 )
 
+// AllNamespaces is the namespace of a request for the resources in all namespaces, which is
+// only supported for endpointslices
+const AllNamespaces = "*"
+
 // Request is used to construct a http request for the k8s API.
 type Request struct {
 	// the request context
@@ -185,6 +189,13 @@ func (r *Request) request() (*http.Request, error) {
 	case "networkpolicy", "networkpolicies":
 		// /apis/networking.k8s.io/v1/namespaces/{namespace}/networkpolicies
 		url = fmt.Sprintf("%s/apis/networking.k8s.io/v1/namespaces/%s/networkpolicies/", r.host, r.namespace)
+	case "endpointslice":
+		// /apis/discovery.k8s.io/v1/namespaces/{namespace}/endpointslices
+		if r.namespace == AllNamespaces {
+			url = fmt.Sprintf("%s/apis/discovery.k8s.io/v1/endpointslices/", r.host)
+		} else {
+			url = fmt.Sprintf("%s/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices/", r.host, r.namespace)
+		}
 	default:
 		// /api/v1/namespaces/{namespace}/{resource}
 		url = fmt.Sprintf("%s/api/v1/namespaces/%s/%ss/", r.host, r.namespace, r.resource)
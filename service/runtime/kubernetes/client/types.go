@@ -114,11 +114,12 @@ type LoadBalancerStatus struct {
 
 // Metadata defines api object metadata
 type Metadata struct {
-	Name        string            `json:"name,omitempty"`
-	Namespace   string            `json:"namespace,omitempty"`
-	Version     string            `json:"version,omitempty"`
-	Labels      map[string]string `json:"labels,omitempty"`
-	Annotations map[string]string `json:"annotations,omitempty"`
+	Name            string            `json:"name,omitempty"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Version         string            `json:"version,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
 }
 
 // PodSpec is a pod
@@ -206,6 +207,52 @@ type ServiceList struct {
 	Items []Service `json:"items"`
 }
 
+// EndpointSlice is a set of the endpoints of a kubernetes service
+type EndpointSlice struct {
+	Metadata  *Metadata      `json:"metadata"`
+	Endpoints []Endpoint     `json:"endpoints"`
+	Ports     []EndpointPort `json:"ports,omitempty"`
+}
+
+// EndpointSliceList
+type EndpointSliceList struct {
+	Metadata *ListMetadata   `json:"metadata,omitempty"`
+	Items    []EndpointSlice `json:"items"`
+}
+
+// Endpoint is an endpoint of a kubernetes service, e.g. a pod
+type Endpoint struct {
+	Addresses  []string           `json:"addresses"`
+	Conditions EndpointConditions `json:"conditions"`
+	TargetRef  *ObjectReference   `json:"targetRef,omitempty"`
+}
+
+// EndpointConditions describes the state of an endpoint, a nil condition is unknown
+type EndpointConditions struct {
+	Ready       *bool `json:"ready,omitempty"`
+	Terminating *bool `json:"terminating,omitempty"`
+}
+
+// EndpointPort is a port of the endpoints of a slice
+type EndpointPort struct {
+	Name        string `json:"name,omitempty"`
+	Port        int    `json:"port,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+	AppProtocol string `json:"appProtocol,omitempty"`
+}
+
+// ObjectReference references an api object
+type ObjectReference struct {
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ListMetadata is the metadata of a list of api objects
+type ListMetadata struct {
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
 // Template is micro deployment template
 type Template struct {
 	Metadata *Metadata `json:"metadata,omitempty"`
@@ -58,7 +58,8 @@ type bodyWatcher struct {
 	req     *api.Request
 }
 
-// Changes returns the results channel
+// Chan returns the results channel, which is closed when the watch ends, e.g. when the api
+// server times out the request
 func (wr *bodyWatcher) Chan() <-chan Event {
 	return wr.results
 }
@@ -80,6 +81,9 @@ func (wr *bodyWatcher) stream() {
 	reader := bufio.NewReader(wr.res.Body)
 
 	go func() {
+		defer close(wr.results)
+		defer wr.res.Body.Close()
+
 		for {
 			// read a line
 			b, err := reader.ReadBytes('\n')
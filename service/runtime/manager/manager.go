@@ -227,6 +227,9 @@ func (m *manager) build(srv *service) error {
 		// if the source was uploaded to the blob store, it'll have source:// as the prefix
 		nsOpt := store.BlobNamespace(srv.Options.Namespace)
 		source, err = store.DefaultBlobStore.Read(srv.Service.Source, nsOpt)
+		if c, ok := source.(io.Closer); ok {
+			defer c.Close()
+		}
 	} else {
 		// the source will otherwise be a git remote, we'll clone it and then tar archive the result
 		gitSrc, err := git.ParseSource(srv.Service.Source)
@@ -354,6 +357,9 @@ func (m *manager) checkoutBlobSource(srv *service) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if c, ok := source.(io.Closer); ok {
+		defer c.Close()
+	}
 
 	dir, err := ioutil.TempDir(os.TempDir(), "blob-*")
 	if err != nil {
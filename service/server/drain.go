@@ -0,0 +1,42 @@
+package server
+
+import (
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+)
+
+// Drain runs the before drain hooks, then waits for the requests in flight to finish for up to the
+// drain timeout, calling stop if they haven't, then runs the after drain hooks. It's called by
+// the servers once they've deregistered and stopped accepting requests. It returns true if the
+// requests finished before the timeout.
+func Drain(opts Options, wait func(), stop func()) bool {
+	for _, fn := range opts.BeforeDrain {
+		if err := fn(); err != nil {
+			logger.Errorf("Server before drain error: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	drained := true
+	select {
+	case <-done:
+	case <-time.After(opts.DrainTimeout):
+		logger.Warnf("Server drain timed out after %v, stopping the requests in flight", opts.DrainTimeout)
+		drained = false
+		stop()
+	}
+
+	for _, fn := range opts.AfterDrain {
+		if err := fn(); err != nil {
+			logger.Errorf("Server after drain error: %v", err)
+		}
+	}
+
+	return drained
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/micro/micro/v3/util/codec"
 	"github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/metadata"
@@ -33,6 +34,7 @@ import (
 
 type jsonCodec struct{}
 type bytesCodec struct{}
+type cborCodec struct{}
 type protoCodec struct{}
 type wrapCodec struct{ encoding.Codec }
 
@@ -52,6 +54,8 @@ var (
 		"application/grpc+json":    jsonCodec{},
 		"application/grpc+proto":   protoCodec{},
 		"application/grpc+bytes":   bytesCodec{},
+		"application/grpc+cbor":    cborCodec{},
+		"application/cbor":         cborCodec{},
 		"multipart/form-data":      jsonCodec{},
 	}
 )
@@ -123,6 +127,21 @@ func (jsonCodec) Name() string {
 	return "json"
 }
 
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return cbor.Marshaler{}.Marshal(v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return cbor.Marshaler{}.Unmarshal(data, v)
+}
+
+func (cborCodec) Name() string {
+	return "cbor"
+}
+
 func (bytesCodec) Marshal(v interface{}) ([]byte, error) {
 	b, ok := v.(*[]byte)
 	if !ok {
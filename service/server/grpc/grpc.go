@@ -53,6 +53,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
@@ -75,6 +76,8 @@ const (
 type grpcServer struct {
 	rpc *rServer
 	srv *grpc.Server
+	// health serves the grpc health checking protocol
+	health *healthServer
 
 	exit chan chan error
 	wg   *sync.WaitGroup
@@ -96,6 +99,7 @@ func init() {
 	encoding.RegisterCodec(wrapCodec{jsonCodec{}})
 	encoding.RegisterCodec(wrapCodec{protoCodec{}})
 	encoding.RegisterCodec(wrapCodec{bytesCodec{}})
+	encoding.RegisterCodec(wrapCodec{cborCodec{}})
 }
 
 func newGRPCServer(opts ...server.Option) server.Server {
@@ -113,6 +117,9 @@ func newGRPCServer(opts ...server.Option) server.Server {
 		wg:          wait(options.Context),
 	}
 
+	// serve the health checks
+	srv.health = newHealthServer(srv)
+
 	// configure the grpc server
 	srv.configure()
 
@@ -166,6 +173,7 @@ func (g *grpcServer) configure(opts ...server.Option) {
 
 	g.rsvc = nil
 	g.srv = grpc.NewServer(gopts...)
+	healthpb.RegisterHealthServer(g.srv, g.health)
 }
 
 func (g *grpcServer) maxRecvMsgSizeValue() int {
@@ -490,7 +498,8 @@ func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service,
 			return err
 		}
 
-		// wrap the handler func
+		// intercept and wrap the handler func
+		fn = server.ChainUnaryInterceptors(fn, g.opts.UnaryInterceptors...)
 		for i := len(g.opts.HdlrWrappers); i > 0; i-- {
 			fn = g.opts.HdlrWrappers[i-1](fn)
 		}
@@ -572,6 +581,8 @@ func (g *grpcServer) processStream(stream grpc.ServerStream, service *service, m
 		return nil
 	}
 
+	// intercept and wrap the handler func
+	fn = server.ChainStreamHandler(fn, opts.StreamInterceptors...)
 	for i := len(opts.HdlrWrappers); i > 0; i-- {
 		fn = opts.HdlrWrappers[i-1](fn)
 	}
@@ -746,7 +757,10 @@ func (g *grpcServer) Register() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if mnet.IsUnix(advt) {
+		// a unix socket is registered as it's dialled
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -893,7 +907,10 @@ func (g *grpcServer) Deregister() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if mnet.IsUnix(advt) {
+		// a unix socket is registered as it's dialled
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -976,13 +993,17 @@ func (g *grpcServer) Start() error {
 	} else {
 		var err error
 
-		// check the tls config for secure connect
-		if tc := config.TLSConfig; tc != nil {
-			ts, err = tls.Listen("tcp", config.Address, tc)
-			// otherwise just plain tcp listener
-		} else {
-			ts, err = net.Listen("tcp", config.Address)
-		}
+		ts, err = mnet.Listen(config.Address, func(addr string) (net.Listener, error) {
+			// listen on a unix socket or tcp
+			network, addr := mnet.Network(addr)
+
+			// check the tls config for secure connect
+			if tc := config.TLSConfig; tc != nil {
+				return tls.Listen(network, addr, tc)
+			}
+			// otherwise just plain listener
+			return net.Listen(network, addr)
+		})
 		if err != nil {
 			return err
 		}
@@ -995,10 +1016,10 @@ func (g *grpcServer) Start() error {
 	}
 
 	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Server [grpc] Listening on %s", ts.Addr().String())
+		logger.Infof("Server [grpc] Listening on %s", mnet.Addr(ts))
 	}
 	g.Lock()
-	g.opts.Address = ts.Addr().String()
+	g.opts.Address = mnet.Addr(ts)
 	g.Unlock()
 
 	// only connect if we're subscribed
@@ -1023,6 +1044,9 @@ func (g *grpcServer) Start() error {
 		}
 	}
 
+	// report the server as serving to the health checks
+	g.health.setServing(true)
+
 	if g.opts.Context != nil {
 		gRPCWebAddr := ":8082"
 		if g.opts.Context.Value(grpcWebPort{}) != nil {
@@ -1082,6 +1106,9 @@ func (g *grpcServer) Start() error {
 			}
 		}
 
+		// fail the health checks so no more requests are routed here
+		g.health.setServing(false)
+
 		// deregister self
 		if err := g.Deregister(); err != nil {
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
@@ -1089,24 +1116,14 @@ func (g *grpcServer) Start() error {
 			}
 		}
 
-		// wait for waitgroup
-		if g.wg != nil {
-			g.wg.Wait()
-		}
-
-		// stop the grpc server
-		exit := make(chan bool)
-
-		go func() {
+		// stop accepting requests and wait for those in flight to finish, stopping the grpc server
+		// if they haven't by the drain timeout
+		server.Drain(g.Options(), func() {
 			g.srv.GracefulStop()
-			close(exit)
-		}()
-
-		select {
-		case <-exit:
-		case <-time.After(time.Second):
-			g.srv.Stop()
-		}
+			if g.wg != nil {
+				g.wg.Wait()
+			}
+		}, g.srv.Stop)
 
 		// close transport
 		ch <- nil
@@ -19,12 +19,16 @@ package grpc_test
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	pberr "github.com/micro/micro/v3/proto/errors"
 	bmemory "github.com/micro/micro/v3/service/broker/memory"
 	"github.com/micro/micro/v3/service/client"
 	gcli "github.com/micro/micro/v3/service/client/grpc"
+	"github.com/micro/micro/v3/service/context/metadata"
 	"github.com/micro/micro/v3/service/errors"
 	tgrpc "github.com/micro/micro/v3/service/network/transport/grpc"
 	rmemory "github.com/micro/micro/v3/service/registry/memory"
@@ -34,6 +38,8 @@ import (
 	gsrv "github.com/micro/micro/v3/service/server/grpc"
 	pb "github.com/micro/micro/v3/service/server/grpc/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 )
 
@@ -202,6 +208,19 @@ func TestGRPCServer(t *testing.T) {
 		}
 	}
 
+	// the content type of the request selects the codec
+	for _, ct := range []string{"application/json", "application/cbor"} {
+		rsp := pb.Response{}
+		req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"}, client.WithContentType(ct))
+		if err := c.Call(ctx, req, &rsp); err != nil {
+			t.Fatalf("error calling server with %v: %v", ct, err)
+		}
+
+		if rsp.Msg != "Hello John" {
+			t.Fatalf("Got unexpected response %v with %v", rsp.Msg, ct)
+		}
+	}
+
 	// Test grpc error
 	rsp := pb.Response{}
 
@@ -317,3 +336,273 @@ func TestGRPCServerWithPanicHandler(t *testing.T) {
 		t.Fatal("this must return error, as handler should be panic")
 	}
 }
+
+// TestGRPCServerWithInterceptors test the interceptors of the client and server are called in
+// order and pass the metadata of the request
+func TestGRPCServerWithInterceptors(t *testing.T) {
+	r := rmemory.NewRegistry()
+	b := bmemory.NewBroker()
+	tr := tgrpc.NewTransport()
+	rtr := rtreg.NewRouter(router.Registry(r))
+
+	var calls []string
+	unary := func(name string) server.UnaryInterceptor {
+		return func(ctx context.Context, req server.Request, rsp interface{}, h server.HandlerFunc) error {
+			id, _ := metadata.Get(ctx, "Request-Id")
+			calls = append(calls, name+" "+id)
+			return h(ctx, req, rsp)
+		}
+	}
+
+	s := gsrv.NewServer(
+		server.Broker(b),
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tr),
+		server.InterceptUnary(unary("first"), unary("second")),
+		server.InterceptUnary(func(ctx context.Context, req server.Request, rsp interface{}, h server.HandlerFunc) error {
+			if req.Endpoint() != "Test.Call" {
+				return errors.BadRequest("foo", "unexpected endpoint %v", req.Endpoint())
+			}
+			return h(ctx, req, rsp)
+		}),
+	)
+
+	c := gcli.NewClient(
+		client.Router(rtr),
+		client.Broker(b),
+		client.Transport(tr),
+		client.InterceptUnary(func(ctx context.Context, req client.Request, rsp interface{}, invoke client.UnaryInvoker, opts ...client.CallOption) error {
+			calls = append(calls, "client")
+			return invoke(metadata.Set(ctx, "Request-Id", "1"), req, rsp, opts...)
+		}),
+	)
+
+	h := &testServer{}
+	pb.RegisterTestHandler(s, h)
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	defer func() {
+		if err := s.Stop(); err != nil {
+			t.Fatalf("failed to stop: %v", err)
+		}
+	}()
+
+	rsp := pb.Response{}
+	req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"})
+	if err := c.Call(context.TODO(), req, &rsp); err != nil {
+		t.Fatalf("error calling server: %v", err)
+	}
+	if rsp.Msg != "Hello John" {
+		t.Fatalf("Got unexpected response %v", rsp.Msg)
+	}
+
+	expected := []string{"client", "first 1", "second 1"}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("Expected the interceptors to be called in order %v, got %v", expected, calls)
+	}
+}
+
+// TestGRPCServerDrain test the requests in flight finish before the server stops, up to the drain
+// timeout
+func TestGRPCServerDrain(t *testing.T) {
+	testDrain := func(t *testing.T, delay, timeout time.Duration) ([]string, error) {
+		r := rmemory.NewRegistry()
+		b := bmemory.NewBroker()
+		tr := tgrpc.NewTransport()
+		rtr := rtreg.NewRouter(router.Registry(r))
+
+		var mu sync.Mutex
+		var hooks []string
+		hook := func(name string) func() error {
+			return func() error {
+				mu.Lock()
+				hooks = append(hooks, name)
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		started := make(chan bool)
+		s := gsrv.NewServer(
+			server.Broker(b),
+			server.Name("foo"),
+			server.Registry(r),
+			server.Transport(tr),
+			server.WithDrainTimeout(timeout),
+			server.BeforeDrain(hook("before")),
+			server.AfterDrain(hook("after")),
+			server.InterceptUnary(func(ctx context.Context, req server.Request, rsp interface{}, h server.HandlerFunc) error {
+				close(started)
+				time.Sleep(delay)
+				hook("handler")()
+				return h(ctx, req, rsp)
+			}),
+		)
+
+		c := gcli.NewClient(
+			client.Router(rtr),
+			client.Broker(b),
+			client.Transport(tr),
+		)
+
+		pb.RegisterTestHandler(s, &testServer{})
+		if err := s.Start(); err != nil {
+			t.Fatalf("failed to start: %v", err)
+		}
+
+		errc := make(chan error, 1)
+		go func() {
+			rsp := pb.Response{}
+			req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"})
+			errc <- c.Call(context.TODO(), req, &rsp, client.WithRetries(0))
+		}()
+
+		<-started
+		if err := s.Stop(); err != nil {
+			t.Fatalf("failed to stop: %v", err)
+		}
+		hook("stopped")()
+
+		// the server is deregistered before draining
+		if services, _ := r.GetService("foo"); len(services) > 0 {
+			t.Fatal("Expected the server to be deregistered")
+		}
+
+		err := <-errc
+		mu.Lock()
+		defer mu.Unlock()
+		return hooks, err
+	}
+
+	t.Run("Drained", func(t *testing.T) {
+		hooks, err := testDrain(t, 100*time.Millisecond, time.Second)
+		if err != nil {
+			t.Fatalf("Expected the request in flight to finish, got %v", err)
+		}
+		if expected := "[before handler after stopped]"; fmt.Sprint(hooks) != expected {
+			t.Fatalf("Expected %v, got %v", expected, hooks)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		hooks, err := testDrain(t, time.Second, 50*time.Millisecond)
+		if err == nil {
+			t.Fatal("Expected the request in flight to be stopped")
+		}
+		if expected := "[before after stopped]"; fmt.Sprint(hooks[:3]) != expected {
+			t.Fatalf("Expected %v, got %v", expected, hooks)
+		}
+	})
+}
+
+func TestGRPCServerHealth(t *testing.T) {
+	r := rmemory.NewRegistry()
+	b := bmemory.NewBroker()
+	tr := tgrpc.NewTransport()
+
+	s := gsrv.NewServer(
+		server.Broker(b),
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tr),
+		server.WithDrainTimeout(50*time.Millisecond),
+		server.HealthCheck("Test.Call", func(ctx context.Context) error {
+			return fmt.Errorf("not ready")
+		}),
+	)
+
+	pb.RegisterTestHandler(s, &testServer{})
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+	hc := healthpb.NewHealthClient(cc)
+
+	testData := map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":          healthpb.HealthCheckResponse_SERVING,
+		"foo":       healthpb.HealthCheckResponse_SERVING,
+		"Test":      healthpb.HealthCheckResponse_SERVING,
+		"Test.Call": healthpb.HealthCheckResponse_NOT_SERVING,
+	}
+	for service, expected := range testData {
+		rsp, err := hc.Check(context.TODO(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("failed to check %q: %v", service, err)
+		}
+		if rsp.Status != expected {
+			t.Fatalf("Expected %q to be %v, got %v", service, expected, rsp.Status)
+		}
+	}
+
+	if _, err := hc.Check(context.TODO(), &healthpb.HealthCheckRequest{Service: "Unknown"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected an unknown service to be not found, got %v", err)
+	}
+
+	// the watch reports the server stopped serving once it's stopped
+	stream, err := hc.Watch(context.TODO(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	if rsp, err := stream.Recv(); err != nil || rsp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Expected the server to be serving, got %v %v", rsp, err)
+	}
+	go s.Stop()
+	if rsp, err := stream.Recv(); err != nil || rsp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Expected the server to stop serving, got %v %v", rsp, err)
+	}
+}
+
+func TestGRPCServerUnix(t *testing.T) {
+	r := rmemory.NewRegistry()
+	b := bmemory.NewBroker()
+	tr := tgrpc.NewTransport()
+	rtr := rtreg.NewRouter(router.Registry(r))
+
+	addr := "unix://" + filepath.Join(t.TempDir(), "micro.sock")
+	s := gsrv.NewServer(
+		server.Broker(b),
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tr),
+		server.Address(addr),
+	)
+
+	c := gcli.NewClient(
+		client.Router(rtr),
+		client.Broker(b),
+		client.Transport(tr),
+	)
+
+	pb.RegisterTestHandler(s, &testServer{})
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	// the socket is registered as it's dialled
+	services, err := r.GetService("foo")
+	if err != nil || len(services) == 0 {
+		t.Fatalf("failed to get service: %v # %d", err, len(services))
+	}
+	if services[0].Nodes[0].Address != addr {
+		t.Fatalf("Expected the node address %v, got %v", addr, services[0].Nodes[0].Address)
+	}
+
+	rsp := pb.Response{}
+	req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"})
+	if err := c.Call(context.TODO(), req, &rsp); err != nil {
+		t.Fatalf("error calling server: %v", err)
+	}
+	if rsp.Msg != "Hello John" {
+		t.Fatalf("Got unexpected response %v", rsp.Msg)
+	}
+}
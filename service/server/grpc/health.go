@@ -0,0 +1,164 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// HealthWatchInterval is how often the health checks are run again for a watch, sending the
+	// status if it changed
+	HealthWatchInterval = time.Second * 5
+)
+
+// healthServer implements the grpc health checking protocol, grpc.health.v1.Health. The server,
+// identified by an empty service or the name of the server, its handlers and their endpoints are
+// serving while the server is started, and their register and health checks pass.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	server *grpcServer
+
+	sync.RWMutex
+	serving bool
+	// watchers are notified when the server starts or stops serving
+	watchers map[chan struct{}]bool
+}
+
+func newHealthServer(g *grpcServer) *healthServer {
+	return &healthServer{
+		server:   g,
+		watchers: make(map[chan struct{}]bool),
+	}
+}
+
+// setServing sets whether the server is serving, notifying the watchers
+func (h *healthServer) setServing(serving bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.serving == serving {
+		return
+	}
+	h.serving = serving
+
+	for ch := range h.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// checks returns the checks of the service, false if it's unknown
+func (h *healthServer) checks(service string) ([]func(context.Context) error, bool) {
+	opts := h.server.Options()
+	checks := append([]func(context.Context) error{opts.RegisterCheck}, opts.HealthChecks[""]...)
+	if len(service) == 0 || service == opts.Name {
+		return checks, true
+	}
+
+	h.server.RLock()
+	defer h.server.RUnlock()
+
+	// the service is a handler
+	if _, ok := h.server.handlers[service]; ok {
+		return append(checks, opts.HealthChecks[service]...), true
+	}
+
+	// or an endpoint of a handler
+	name := strings.SplitN(service, ".", 2)[0]
+	hdlr, ok := h.server.handlers[name]
+	if !ok {
+		return nil, false
+	}
+	for _, ep := range hdlr.Endpoints() {
+		if ep.Name == service {
+			checks = append(checks, opts.HealthChecks[name]...)
+			return append(checks, opts.HealthChecks[service]...), true
+		}
+	}
+
+	return nil, false
+}
+
+// status returns the serving status of the service, or an error if it's unknown
+func (h *healthServer) status(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	checks, ok := h.checks(service)
+	if !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Errorf(codes.NotFound, "unknown service %v", service)
+	}
+
+	h.RLock()
+	serving := h.serving
+	h.RUnlock()
+	if !serving {
+		return healthpb.HealthCheckResponse_NOT_SERVING, nil
+	}
+
+	for _, fn := range checks {
+		if fn == nil {
+			continue
+		}
+		if err := fn(ctx); err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Server health check of %q failed: %v", service, err)
+			}
+			return healthpb.HealthCheckResponse_NOT_SERVING, nil
+		}
+	}
+
+	return healthpb.HealthCheckResponse_SERVING, nil
+}
+
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, err := h.status(ctx, req.Service)
+	if err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	update := make(chan struct{}, 1)
+
+	h.Lock()
+	h.watchers[update] = true
+	h.Unlock()
+
+	defer func() {
+		h.Lock()
+		delete(h.watchers, update)
+		h.Unlock()
+	}()
+
+	t := time.NewTicker(HealthWatchInterval)
+	defer t.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+
+	for {
+		// an unknown service is watched in case it becomes known rather than failing the call
+		st, _ := h.status(stream.Context(), req.Service)
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			last = st
+		}
+
+		select {
+		case <-update:
+		case <-t.C:
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		}
+	}
+}
@@ -130,6 +130,7 @@ func newOptions(opt ...server.Option) server.Options {
 		Version:          server.DefaultVersion,
 		RegisterInterval: server.DefaultRegisterInterval,
 		RegisterTTL:      server.DefaultRegisterTTL,
+		DrainTimeout:     server.DefaultDrainTimeout,
 	}
 
 	for _, o := range opt {
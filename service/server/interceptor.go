@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+)
+
+// StreamHandler handles a stream. It's what the stream interceptors call to continue the call.
+type StreamHandler func(ctx context.Context, req Request, stream Stream) error
+
+// UnaryInterceptor intercepts a unary call to a handler. It's passed the context, which holds the
+// metadata of the request, and calls the handler to continue the call, or returns an error to
+// fail it without calling the handler.
+type UnaryInterceptor func(ctx context.Context, req Request, rsp interface{}, handler HandlerFunc) error
+
+// StreamInterceptor intercepts a streaming call to a handler. It's passed the context, which holds
+// the metadata of the request, and calls the handler to continue the call, optionally with a
+// wrapped stream, or returns an error to fail it without calling the handler.
+type StreamInterceptor func(ctx context.Context, req Request, stream Stream, handler StreamHandler) error
+
+// ChainUnaryInterceptors returns the handler wrapped by the interceptors, which are called in
+// order with the first being the outermost
+func ChainUnaryInterceptors(h HandlerFunc, interceptors ...UnaryInterceptor) HandlerFunc {
+	for i := len(interceptors); i > 0; i-- {
+		interceptor, next := interceptors[i-1], h
+		h = func(ctx context.Context, req Request, rsp interface{}) error {
+			return interceptor(ctx, req, rsp, next)
+		}
+	}
+	return h
+}
+
+// ChainStreamInterceptors returns the stream handler wrapped by the interceptors, which are called
+// in order with the first being the outermost
+func ChainStreamInterceptors(h StreamHandler, interceptors ...StreamInterceptor) StreamHandler {
+	for i := len(interceptors); i > 0; i-- {
+		interceptor, next := interceptors[i-1], h
+		h = func(ctx context.Context, req Request, stream Stream) error {
+			return interceptor(ctx, req, stream, next)
+		}
+	}
+	return h
+}
+
+// ChainStreamHandler returns the handler of a streaming call wrapped by the stream interceptors,
+// which are called in order with the first being the outermost. The stream the handler is
+// called with must be a Stream.
+func ChainStreamHandler(fn HandlerFunc, interceptors ...StreamInterceptor) HandlerFunc {
+	if len(interceptors) == 0 {
+		return fn
+	}
+	h := ChainStreamInterceptors(func(ctx context.Context, req Request, stream Stream) error {
+		return fn(ctx, req, stream)
+	}, interceptors...)
+	return func(ctx context.Context, req Request, stream interface{}) error {
+		return h(ctx, req, stream.(Stream))
+	}
+}
@@ -30,6 +30,7 @@ func newOptions(opt ...server.Option) server.Options {
 		Metadata:         map[string]string{},
 		RegisterInterval: server.DefaultRegisterInterval,
 		RegisterTTL:      server.DefaultRegisterTTL,
+		DrainTimeout:     server.DefaultDrainTimeout,
 	}
 
 	for _, o := range opt {
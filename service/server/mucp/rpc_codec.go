@@ -23,6 +23,7 @@ import (
 	"github.com/micro/micro/v3/service/network/transport"
 	"github.com/micro/micro/v3/util/codec"
 	raw "github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/micro/micro/v3/util/codec/grpc"
 	"github.com/micro/micro/v3/util/codec/json"
 	"github.com/micro/micro/v3/util/codec/jsonrpc"
@@ -58,6 +59,7 @@ var (
 		"application/grpc":         grpc.NewCodec,
 		"application/grpc+json":    grpc.NewCodec,
 		"application/grpc+proto":   grpc.NewCodec,
+		"application/cbor":         cbor.NewCodec,
 		"application/json":         json.NewCodec,
 		"application/json-rpc":     jsonrpc.NewCodec,
 		"application/protobuf":     proto.NewCodec,
@@ -90,6 +90,9 @@ type router struct {
 	hdlrWrappers []server.HandlerWrapper
 	// subscriber wrappers
 	subWrappers []server.SubscriberWrapper
+	// handler interceptors
+	unaryInterceptors  []server.UnaryInterceptor
+	streamInterceptors []server.StreamInterceptor
 
 	su          sync.RWMutex
 	subscribers map[string][]*subscriber
@@ -248,7 +251,8 @@ func (s *service) call(ctx context.Context, router *router, sending *sync.Mutex,
 			return nil
 		}
 
-		// wrap the handler
+		// intercept and wrap the handler
+		fn = server.ChainUnaryInterceptors(fn, router.unaryInterceptors...)
 		for i := len(router.hdlrWrappers); i > 0; i-- {
 			fn = router.hdlrWrappers[i-1](fn)
 		}
@@ -286,7 +290,8 @@ func (s *service) call(ctx context.Context, router *router, sending *sync.Mutex,
 		}
 	}
 
-	// wrap the handler
+	// intercept and wrap the handler
+	fn = server.ChainStreamHandler(fn, router.streamInterceptors...)
 	for i := len(router.hdlrWrappers); i > 0; i-- {
 		fn = router.hdlrWrappers[i-1](fn)
 	}
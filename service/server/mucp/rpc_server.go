@@ -30,6 +30,7 @@ import (
 
 	"github.com/micro/micro/v3/service/broker"
 	"github.com/micro/micro/v3/service/context/metadata"
+	merrors "github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/network/transport"
 	"github.com/micro/micro/v3/service/registry"
@@ -58,6 +59,13 @@ type rpcServer struct {
 	subscriber broker.Subscriber
 	// graceful exit
 	wg *sync.WaitGroup
+	// the base context of the requests and the connections served, the context is cancelled and
+	// the connections closed if the requests haven't finished by the drain timeout
+	ctx    context.Context
+	cancel context.CancelFunc
+	conns  map[transport.Socket]bool
+	// marks the server as draining, the requests on the connections still open are rejected
+	draining bool
 
 	rsvc *registry.Service
 }
@@ -82,6 +90,14 @@ func newServer(opts ...server.Option) server.Server {
 	router := newRpcRouter()
 	router.hdlrWrappers = options.HdlrWrappers
 	router.subWrappers = options.SubWrappers
+	router.unaryInterceptors = options.UnaryInterceptors
+	router.streamInterceptors = options.StreamInterceptors
+
+	// track the requests in flight to drain them when stopping
+	wg := wait(options.Context)
+	if wg == nil {
+		wg = new(sync.WaitGroup)
+	}
 
 	return &rpcServer{
 		opts:        options,
@@ -89,7 +105,7 @@ func newServer(opts ...server.Option) server.Server {
 		handlers:    make(map[string]server.Handler),
 		subscribers: make(map[server.Subscriber][]broker.Subscriber),
 		exit:        make(chan chan error),
-		wg:          wait(options.Context),
+		wg:          wg,
 	}
 }
 
@@ -163,10 +179,18 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 	// streams are multiplexed on Micro-Stream or Micro-Id header
 	pool := socket.NewPool()
 
-	// get global waitgroup
+	// get global waitgroup and the base context of the requests, tracking the connection so it's
+	// closed if the server is stopped before its requests finish
 	s.Lock()
 	gg := s.wg
+	base := s.ctx
+	if s.conns != nil {
+		s.conns[sock] = true
+	}
 	s.Unlock()
+	if base == nil {
+		base = context.Background()
+	}
 
 	// waitgroup to wait for processing to finish
 	wg := &waitGroup{
@@ -182,6 +206,9 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 
 		// close underlying socket
 		sock.Close()
+		s.Lock()
+		delete(s.conns, sock)
+		s.Unlock()
 
 		// recover any panics
 		if r := recover(); r != nil {
@@ -294,7 +321,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		hdr["Remote"] = sock.Remote()
 
 		// create new context with the metadata
-		ctx := metadata.NewContext(context.Background(), hdr)
+		ctx := metadata.NewContext(base, hdr)
 
 		// set the timeout from the header if we have it
 		if len(to) > 0 {
@@ -339,6 +366,25 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		// check the protocol as well
 		protocol := rcodec.String()
 
+		// once the server is draining the new requests on the connections which are still open
+		// are rejected, so they're retried on another node rather than started during the drain
+		s.RLock()
+		draining := s.draining
+		s.RUnlock()
+		if draining {
+			rcodec.Write(&codec.Message{
+				Header: msg.Header,
+				Error:  merrors.ServiceUnavailable("go.micro.server", "server is shutting down").Error(),
+				Type:   codec.Error,
+			}, nil)
+			m := new(transport.Message)
+			if err := psock.Process(m); err == nil {
+				sock.Send(m)
+			}
+			pool.Release(psock)
+			continue
+		}
+
 		// internal request
 		request := &rpcRequest{
 			service:     getHeader("Micro-Service", msg.Header),
@@ -486,6 +532,8 @@ func (s *rpcServer) Init(opts ...server.Option) error {
 		r.hdlrWrappers = s.opts.HdlrWrappers
 		r.serviceMap = s.router.serviceMap
 		r.subWrappers = s.opts.SubWrappers
+		r.unaryInterceptors = s.opts.UnaryInterceptors
+		r.streamInterceptors = s.opts.StreamInterceptors
 		s.router = r
 	}
 
@@ -585,7 +633,10 @@ func (s *rpcServer) Register() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if mnet.IsUnix(advt) {
+		// a unix socket is registered as it's dialled
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -756,7 +807,10 @@ func (s *rpcServer) Deregister() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if mnet.IsUnix(advt) {
+		// a unix socket is registered as it's dialled
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -848,6 +902,9 @@ func (s *rpcServer) Start() error {
 	s.Lock()
 	addr := s.opts.Address
 	s.opts.Address = ts.Addr()
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.conns = make(map[transport.Socket]bool)
+	s.draining = false
 	s.Unlock()
 
 	bname := config.Broker.String()
@@ -972,15 +1029,16 @@ func (s *rpcServer) Start() error {
 
 		s.Lock()
 		swg := s.wg
+		s.draining = true
 		s.Unlock()
 
-		// wait for requests to finish
-		if swg != nil {
-			swg.Wait()
-		}
+		// close transport listener to stop accepting connections
+		err := ts.Close()
 
-		// close transport listener
-		ch <- ts.Close()
+		// wait for requests to finish, up to the drain timeout
+		server.Drain(s.Options(), swg.Wait, s.stop)
+
+		ch <- err
 
 		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
 			log.Infof("Broker [%s] Disconnected from %s", bname, config.Broker.Address())
@@ -1006,6 +1064,19 @@ func (s *rpcServer) Start() error {
 	return nil
 }
 
+// stop the requests in flight, cancelling their contexts and closing their connections
+func (s *rpcServer) stop() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+	for sock := range s.conns {
+		sock.Close()
+	}
+}
+
 func (s *rpcServer) Stop() error {
 	s.RLock()
 	if !s.started {
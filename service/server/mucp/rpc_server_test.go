@@ -0,0 +1,205 @@
+package mucp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bmemory "github.com/micro/micro/v3/service/broker/memory"
+	"github.com/micro/micro/v3/service/client"
+	cmucp "github.com/micro/micro/v3/service/client/mucp"
+	"github.com/micro/micro/v3/service/context/metadata"
+	merrors "github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/network/transport"
+	thttp "github.com/micro/micro/v3/service/network/transport/http"
+	rmemory "github.com/micro/micro/v3/service/registry/memory"
+	"github.com/micro/micro/v3/service/router"
+	rtreg "github.com/micro/micro/v3/service/router/registry"
+	"github.com/micro/micro/v3/service/server"
+	pb "github.com/micro/micro/v3/service/server/grpc/proto"
+	"github.com/micro/micro/v3/service/server/mucp"
+)
+
+type testHandler struct{}
+
+func (h *testHandler) Call(ctx context.Context, req *pb.Request, rsp *pb.Response) error {
+	rsp.Msg = "Hello " + req.Name
+	return nil
+}
+
+func (h *testHandler) CallPcre(ctx context.Context, req *pb.Request, rsp *pb.Response) error {
+	return nil
+}
+
+func (h *testHandler) CallPcreInvalid(ctx context.Context, req *pb.Request, rsp *pb.Response) error {
+	return nil
+}
+
+// TestRPCServerDrainTimeout tests the requests which haven't finished by the drain timeout are
+// stopped, with the contexts of their handlers cancelled
+func TestRPCServerDrainTimeout(t *testing.T) {
+	r := rmemory.NewRegistry()
+	b := bmemory.NewBroker()
+	tr := thttp.NewTransport()
+
+	started := make(chan bool)
+	cancelled := make(chan bool, 1)
+	s := mucp.NewServer(
+		server.Broker(b),
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tr),
+		server.WithDrainTimeout(50*time.Millisecond),
+		server.InterceptUnary(func(ctx context.Context, req server.Request, rsp interface{}, h server.HandlerFunc) error {
+			close(started)
+			// the handler outlives the drain timeout unless it's stopped
+			select {
+			case <-ctx.Done():
+				cancelled <- true
+			case <-time.After(5 * time.Second):
+			}
+			return h(ctx, req, rsp)
+		}),
+	)
+	pb.RegisterTestHandler(s, &testHandler{})
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	c := cmucp.NewClient(
+		client.Router(rtreg.NewRouter(router.Registry(r))),
+		client.Broker(b),
+		client.Transport(tr),
+	)
+	errc := make(chan error, 1)
+	go func() {
+		req := c.NewRequest("foo", "Test.Call", &pb.Request{Name: "John"})
+		errc <- c.Call(context.TODO(), req, &pb.Response{}, client.WithRetries(0), client.WithRequestTimeout(10*time.Second))
+	}()
+
+	<-started
+	start := time.Now()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Expected the server to stop after the drain timeout, took %v", d)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the context of the handler to be cancelled")
+	}
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("Expected the request in flight to be stopped")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the connection of the request to be closed")
+	}
+}
+
+// TestRPCServerDrainConn tests the new requests on a connection which is kept open are rejected
+// once the server is draining, while the request in flight finishes
+func TestRPCServerDrainConn(t *testing.T) {
+	tr := thttp.NewTransport()
+
+	started := make(chan bool)
+	release := make(chan bool)
+	draining := make(chan bool)
+	s := mucp.NewServer(
+		server.Broker(bmemory.NewBroker()),
+		server.Name("foo"),
+		server.Registry(rmemory.NewRegistry()),
+		server.Transport(tr),
+		server.WithDrainTimeout(5*time.Second),
+		server.BeforeDrain(func() error {
+			close(draining)
+			return nil
+		}),
+		server.InterceptUnary(func(ctx context.Context, req server.Request, rsp interface{}, h server.HandlerFunc) error {
+			if id, _ := metadata.Get(ctx, "Micro-Id"); id == "slow" {
+				close(started)
+				<-release
+			}
+			return h(ctx, req, rsp)
+		}),
+	)
+	pb.RegisterTestHandler(s, &testHandler{})
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	addr := s.Options().Address
+
+	// call sends a request on the connection and returns the error of the response
+	call := func(c transport.Client, id string) (string, error) {
+		err := c.Send(&transport.Message{
+			Header: map[string]string{
+				"Micro-Id":       id,
+				"Micro-Service":  "foo",
+				"Micro-Endpoint": "Test.Call",
+				"Micro-Method":   "Test.Call",
+				"Content-Type":   "application/json",
+			},
+			Body: []byte(`{"name":"John"}`),
+		})
+		if err != nil {
+			return "", err
+		}
+		var m transport.Message
+		if err := c.Recv(&m); err != nil {
+			return "", err
+		}
+		return m.Header["Micro-Error"], nil
+	}
+
+	slow, err := tr.Dial(addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer slow.Close()
+	conn, err := tr.Dial(addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	if rerr, err := call(conn, "1"); err != nil || len(rerr) > 0 {
+		t.Fatalf("Expected the request to be served, got %v %v", rerr, err)
+	}
+
+	slowc := make(chan string, 1)
+	go func() {
+		rerr, err := call(slow, "slow")
+		if err != nil {
+			rerr = err.Error()
+		}
+		slowc <- rerr
+	}()
+	<-started
+
+	stopc := make(chan error, 1)
+	go func() {
+		stopc <- s.Stop()
+	}()
+	<-draining
+
+	// the connection is still open but the request isn't served
+	rerr, err := call(conn, "2")
+	if err != nil {
+		t.Fatalf("Expected the request to be rejected, got %v", err)
+	}
+	if e := merrors.Parse(rerr); e.Code != 503 {
+		t.Fatalf("Expected the request to be rejected as unavailable, got %v", rerr)
+	}
+
+	close(release)
+	if rerr := <-slowc; len(rerr) > 0 {
+		t.Fatalf("Expected the request in flight to finish, got %v", rerr)
+	}
+	if err := <-stopc; err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+}
@@ -50,13 +50,30 @@ type Options struct {
 	HdlrWrappers []HandlerWrapper
 	SubWrappers  []SubscriberWrapper
 
+	// UnaryInterceptors intercept the unary calls to the handlers, in order
+	UnaryInterceptors []UnaryInterceptor
+	// StreamInterceptors intercept the streaming calls to the handlers, in order
+	StreamInterceptors []StreamInterceptor
+
 	// RegisterCheck runs a check function before registering the service
 	RegisterCheck func(context.Context) error
+	// HealthChecks are run by the health checks of the server keyed by what they check: the
+	// server as a whole if the key is empty, a handler, or an endpoint of a handler
+	HealthChecks map[string][]func(context.Context) error
 	// The register expiry time
 	RegisterTTL time.Duration
 	// The interval on which to register
 	RegisterInterval time.Duration
 
+	// DrainTimeout is how long the server waits for the requests in flight to finish when it's
+	// stopped, before stopping them
+	DrainTimeout time.Duration
+	// BeforeDrain funcs are run once the server has deregistered and stopped accepting requests,
+	// before it waits for the requests in flight
+	BeforeDrain []func() error
+	// AfterDrain funcs are run once the requests in flight have finished or been stopped
+	AfterDrain []func() error
+
 	// The router for requests
 	Router Router
 
@@ -74,6 +91,7 @@ func newOptions(opt ...Option) Options {
 		Metadata:         map[string]string{},
 		RegisterInterval: DefaultRegisterInterval,
 		RegisterTTL:      DefaultRegisterTTL,
+		DrainTimeout:     DefaultDrainTimeout,
 	}
 
 	for _, o := range opt {
@@ -279,6 +297,29 @@ func Wait(wg *sync.WaitGroup) Option {
 	}
 }
 
+// WithDrainTimeout sets how long the server waits for the requests in flight to finish when it's
+// stopped, before stopping them. A timeout of zero stops them straight away.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = d
+	}
+}
+
+// BeforeDrain adds a func run once the server has deregistered and stopped accepting requests,
+// before it waits for the requests in flight
+func BeforeDrain(fn func() error) Option {
+	return func(o *Options) {
+		o.BeforeDrain = append(o.BeforeDrain, fn)
+	}
+}
+
+// AfterDrain adds a func run once the requests in flight have finished or been stopped
+func AfterDrain(fn func() error) Option {
+	return func(o *Options) {
+		o.AfterDrain = append(o.AfterDrain, fn)
+	}
+}
+
 // Adds a handler Wrapper to a list of options passed into the server
 func WrapHandler(w HandlerWrapper) Option {
 	return func(o *Options) {
@@ -292,3 +333,31 @@ func WrapSubscriber(w SubscriberWrapper) Option {
 		o.SubWrappers = append(o.SubWrappers, w)
 	}
 }
+
+// HealthCheck adds a check run by the health checks of the server, failing them while it returns
+// an error. The name is a handler, such as "Greeter", or an endpoint of a handler, such as
+// "Greeter.Hello", to only fail their health checks, or empty to fail those of the whole server.
+func HealthCheck(name string, fn func(context.Context) error) Option {
+	return func(o *Options) {
+		if o.HealthChecks == nil {
+			o.HealthChecks = make(map[string][]func(context.Context) error)
+		}
+		o.HealthChecks[name] = append(o.HealthChecks[name], fn)
+	}
+}
+
+// InterceptUnary adds interceptors to the chain of the unary calls to the handlers. They're called
+// in the order they're added, inside of the handler wrappers.
+func InterceptUnary(i ...UnaryInterceptor) Option {
+	return func(o *Options) {
+		o.UnaryInterceptors = append(o.UnaryInterceptors, i...)
+	}
+}
+
+// InterceptStream adds interceptors to the chain of the streaming calls to the handlers. They're
+// called in the order they're added, inside of the handler wrappers.
+func InterceptStream(i ...StreamInterceptor) Option {
+	return func(o *Options) {
+		o.StreamInterceptors = append(o.StreamInterceptors, i...)
+	}
+}
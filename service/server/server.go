@@ -158,6 +158,7 @@ var (
 	DefaultRegisterCheck    = func(context.Context) error { return nil }
 	DefaultRegisterInterval = time.Second * 30
 	DefaultRegisterTTL      = time.Second * 90
+	DefaultDrainTimeout     = time.Second
 )
 
 // Register a handler
@@ -0,0 +1,306 @@
+package store
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+// backupVersion is the version of the archive format written by Backup
+const backupVersion = 1
+
+// backupBatch is the number of records Backup reads and Restore writes at a time
+const backupBatch = 100
+
+// backupManifest is the first file of a backup archive, it lists the tables whose files
+// follow it
+type backupManifest struct {
+	Version int            `json:"version"`
+	Created time.Time      `json:"created"`
+	Tables  []*backupTable `json:"tables"`
+}
+
+type backupTable struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	// Dir is the directory of the archive holding the files of records, each file holds
+	// up to backupBatch records so the table doesn't have to be held in memory
+	Dir string `json:"dir"`
+}
+
+// backupRecord is a line of a table file. The expiry is stored as a time rather than a
+// duration so records restored later expire when they would have done.
+type backupRecord struct {
+	Key       string                 `json:"key"`
+	Value     []byte                 `json:"value"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ExpiresAt *time.Time             `json:"expires_at,omitempty"`
+}
+
+// Backup writes the records of the default store to w, see BackupStore
+func Backup(ctx context.Context, w io.Writer, opts ...BackupOption) error {
+	return BackupStore(ctx, DefaultStore, w, opts...)
+}
+
+// Restore writes the records of a backup to the default store, see RestoreStore
+func Restore(ctx context.Context, r io.Reader, opts ...RestoreOption) error {
+	return RestoreStore(ctx, DefaultStore, r, opts...)
+}
+
+// BackupStore writes the records of the tables set with BackupTable, or of the database and
+// table of the store if there are none, to w as a gzipped tar archive. The archive doesn't
+// depend on the store it was taken from, so it can be restored to any other. The records are
+// read a page at a time, or with Iterate if the store implements Iterable.
+func BackupStore(ctx context.Context, s Store, w io.Writer, opts ...BackupOption) error {
+	var options BackupOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Tables) == 0 {
+		so := s.Options()
+		options.Tables = []TableName{{Database: so.Database, Table: so.Table}}
+	}
+
+	manifest := &backupManifest{Version: backupVersion, Created: time.Now()}
+	for i, t := range options.Tables {
+		manifest.Tables = append(manifest.Tables, &backupTable{
+			Database: t.Database,
+			Table:    t.Table,
+			Dir:      fmt.Sprintf("tables/%d", i),
+		})
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, "manifest.json", manifest.Created, b); err != nil {
+		return err
+	}
+	for _, t := range manifest.Tables {
+		if err := backupTableFiles(ctx, s, tw, t, manifest.Created); err != nil {
+			return fmt.Errorf("error reading %s/%s: %v", t.Database, t.Table, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// backupTableFiles writes the records of the table to the archive, a file per backupBatch records
+func backupTableFiles(ctx context.Context, s Store, tw *tar.Writer, t *backupTable, created time.Time) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	n, files := 0, 0
+
+	flush := func() error {
+		if n == 0 {
+			return nil
+		}
+		name := fmt.Sprintf("%s/%d.jsonl", t.Dir, files)
+		if err := writeTarFile(tw, name, created, buf.Bytes()); err != nil {
+			return err
+		}
+		buf.Reset()
+		n = 0
+		files++
+		return nil
+	}
+
+	err := readTable(ctx, s, t.Database, t.Table, func(r *Record) error {
+		br := &backupRecord{Key: r.Key, Value: r.Value, Metadata: r.Metadata}
+		if r.Expiry > 0 {
+			exp := time.Now().Add(r.Expiry)
+			br.ExpiresAt = &exp
+		}
+		if err := enc.Encode(br); err != nil {
+			return err
+		}
+		if n++; n == backupBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// readTable calls fn with each record of the table. The records are iterated over if the store
+// implements Iterable, otherwise they're read backupBatch at a time.
+func readTable(ctx context.Context, s Store, database, table string, fn func(*Record) error) error {
+	if i, ok := s.(Iterable); ok {
+		it, err := i.Iterate(ctx, "", ReadPrefix(), ReadFrom(database, table))
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+
+		for {
+			r, err := it.Next()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	for offset := uint(0); ; offset += backupBatch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		recs, err := s.Read("", ReadPrefix(), ReadFrom(database, table), ReadLimit(backupBatch), ReadOffset(offset))
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		if len(recs) < backupBatch {
+			return nil
+		}
+	}
+}
+
+func writeTarFile(tw *tar.Writer, name string, modTime time.Time, b []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
+
+// RestoreStore writes the records of a backup taken with BackupStore to the store, in the
+// databases and tables they were read from. Records which have expired since the backup was
+// taken are skipped, and existing records with the same keys are overwritten.
+func RestoreStore(ctx context.Context, s Store, r io.Reader, opts ...RestoreOption) error {
+	var options RestoreOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("error reading backup: %v", err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("error reading backup: %v", err)
+	}
+	if hdr.Name != "manifest.json" {
+		return fmt.Errorf("error reading backup: missing manifest")
+	}
+	var manifest backupManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return fmt.Errorf("error reading backup manifest: %v", err)
+	}
+	if manifest.Version != backupVersion {
+		return fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+
+	tables := make(map[string]*backupTable, len(manifest.Tables))
+	for _, t := range manifest.Tables {
+		tables[t.Dir] = t
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error reading backup: %v", err)
+		}
+
+		t, ok := tables[path.Dir(hdr.Name)]
+		if !ok || !options.restores(t.Database, t.Table) {
+			continue
+		}
+		if err := restoreTable(ctx, s, tr, t); err != nil {
+			return fmt.Errorf("error restoring %s/%s: %v", t.Database, t.Table, err)
+		}
+	}
+}
+
+func restoreTable(ctx context.Context, s Store, r io.Reader, t *backupTable) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	recs := make([]*Record, 0, backupBatch)
+
+	flush := func() error {
+		if len(recs) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writeMany(s, recs, WriteTo(t.Database, t.Table)); err != nil {
+			return err
+		}
+		recs = recs[:0]
+		return nil
+	}
+
+	for {
+		var br backupRecord
+		if err := dec.Decode(&br); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		rec := &Record{Key: br.Key, Value: br.Value, Metadata: br.Metadata}
+		if br.ExpiresAt != nil {
+			if rec.Expiry = time.Until(*br.ExpiresAt); rec.Expiry <= 0 {
+				continue
+			}
+		}
+
+		if recs = append(recs, rec); len(recs) == backupBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+// writeMany writes the records with WriteMany if the store implements BatchWriter
+func writeMany(s Store, recs []*Record, opts ...WriteOption) error {
+	if bw, ok := s.(BatchWriter); ok {
+		return bw.WriteMany(recs, opts...)
+	}
+	for _, r := range recs {
+		if err := s.Write(r, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,1002 @@
+// Package badger is a badger backed store
+package badger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/watch"
+)
+
+var (
+	// DefaultDatabase is the namespace that the badger store
+	// will use if no namespace is provided.
+	DefaultDatabase = "micro"
+	// DefaultTable when none is specified
+	DefaultTable = "micro"
+	// DefaultDir is the default directory for badger files
+	DefaultDir = filepath.Join(os.TempDir(), "micro", "badger")
+	// DefaultGCInterval is how often the value log is garbage collected
+	DefaultGCInterval = 5 * time.Minute
+)
+
+// NewStore returns a badger store
+func NewStore(opts ...store.Option) store.Store {
+	s := &badgerStore{
+		dbs:  make(map[string]*badger.DB),
+		exit: make(chan bool),
+	}
+	s.init(opts...)
+	go s.gcLoop()
+	return s
+}
+
+type badgerStore struct {
+	options store.Options
+
+	sync.Mutex
+	// open databases, badger only allows a directory to be opened once
+	dbs map[string]*badger.DB
+
+	exit chan bool
+	once sync.Once
+
+	watchers watch.Hub
+}
+
+// record stored by us
+type record struct {
+	Key       string
+	Value     []byte
+	Metadata  map[string]interface{}
+	ExpiresAt time.Time
+	Version   uint64
+}
+
+// tableKey returns the badger key for a key in a table. All the tables of a database
+// share a badger instance so keys are prefixed by the table they belong to.
+func tableKey(table, key string) []byte {
+	return []byte(table + "\x00" + key)
+}
+
+func (b *badgerStore) init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&b.options)
+	}
+
+	if b.options.Database == "" {
+		b.options.Database = DefaultDatabase
+	}
+
+	if b.options.Table == "" {
+		b.options.Table = DefaultTable
+	}
+
+	// close any open databases so they're reopened with the new options
+	b.Lock()
+	for k, db := range b.dbs {
+		db.Close()
+		delete(b.dbs, k)
+	}
+	b.Unlock()
+
+	return nil
+}
+
+// getDir returns the directory which should contain the files for a database
+func (b *badgerStore) getDir(db string) string {
+	var directory string
+	if b.options.Context != nil {
+		if d, ok := b.options.Context.Value(dirKey{}).(string); ok {
+			directory = d
+		}
+	}
+	if len(directory) == 0 {
+		directory = DefaultDir
+	}
+	return filepath.Join(directory, db)
+}
+
+// names returns the database and table to use, defaulting to those of the store
+func (b *badgerStore) names(database, table string) (string, string) {
+	if len(database) == 0 {
+		database = b.options.Database
+	}
+	if len(table) == 0 {
+		table = b.options.Table
+	}
+	return database, table
+}
+
+// Healthy opens the database of the store if it isn't already
+func (b *badgerStore) Healthy(ctx context.Context) error {
+	_, _, err := b.getDB(b.options.Database, b.options.Table)
+	return err
+}
+
+func (b *badgerStore) getDB(database, table string) (*badger.DB, string, error) {
+	database, table = b.names(database, table)
+
+	b.Lock()
+	defer b.Unlock()
+
+	if db, ok := b.dbs[database]; ok {
+		return db, table, nil
+	}
+
+	dir := b.getDir(database)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, "", err
+	}
+
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	if err != nil {
+		return nil, "", err
+	}
+
+	b.dbs[database] = db
+	return db, table, nil
+}
+
+// gcLoop periodically reclaims space in the value logs of the open databases
+func (b *badgerStore) gcLoop() {
+	t := time.NewTicker(DefaultGCInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.exit:
+			return
+		case <-t.C:
+		}
+
+		b.Lock()
+		for name, db := range b.dbs {
+			for {
+				if err := db.RunValueLogGC(0.5); err != nil {
+					if err != badger.ErrNoRewrite {
+						logger.Errorf("Error running badger value log GC for %s: %v", name, err)
+					}
+					break
+				}
+			}
+		}
+		b.Unlock()
+	}
+}
+
+func decode(v []byte) (*record, error) {
+	r := &record{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, err
+	}
+	// badger expires keys with second granularity so check the exact expiry too
+	if !r.ExpiresAt.IsZero() && r.ExpiresAt.Before(time.Now()) {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// list the keys matching the filters, after is the key of the cursor to continue from
+func (b *badgerStore) list(db *badger.DB, table string, order store.Order, limit, offset uint, prefix, suffix, after string, match *regexp.Regexp) ([]string, error) {
+	var keys []string
+
+	err := db.View(func(txn *badger.Txn) error {
+		tp := tableKey(table, "")
+		pfx := tableKey(table, prefix)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = order == store.OrderDesc
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// seek to the first key to read, in reverse that's the one following them. The
+		// key of the cursor and the one following the prefix are skipped.
+		var start, skip []byte
+		ak := tableKey(table, after)
+		if order == store.OrderDesc {
+			start = prefixEnd(pfx)
+			skip = start
+			if len(after) > 0 && (start == nil || bytes.Compare(ak, start) < 0) {
+				start, skip = ak, ak
+			}
+		} else {
+			start = pfx
+			if len(after) > 0 && bytes.Compare(ak, start) >= 0 {
+				start, skip = ak, ak
+			}
+		}
+
+		if start == nil {
+			it.Rewind()
+		} else {
+			it.Seek(start)
+			if skip != nil && it.Valid() && bytes.Equal(it.Item().Key(), skip) {
+				it.Next()
+			}
+		}
+
+		for ; it.ValidForPrefix(pfx); it.Next() {
+			item := it.Item()
+			k := bytes.TrimPrefix(item.Key(), tp)
+
+			if suffix != "" && !bytes.HasSuffix(k, []byte(suffix)) {
+				continue
+			}
+			if match != nil && !match.Match(k) {
+				continue
+			}
+
+			var r *record
+			if err := item.Value(func(v []byte) error {
+				var err error
+				r, err = decode(v)
+				return err
+			}); err != nil {
+				return err
+			}
+			if r == nil {
+				continue
+			}
+
+			if offset > 0 {
+				offset--
+				continue
+			}
+
+			keys = append(keys, string(k))
+			if limit > 0 && len(keys) == int(limit) {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// prefixEnd returns the first key after all those with the prefix, or nil if there isn't one
+func prefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+	return nil
+}
+
+func (b *badgerStore) get(db *badger.DB, table, k string) (*store.Record, error) {
+	var rec *store.Record
+
+	err := db.View(func(txn *badger.Txn) error {
+		var err error
+		rec, err = getRecord(txn, table, k)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// getRecord reads the record with key k from the table
+func getRecord(txn *badger.Txn, table, k string) (*store.Record, error) {
+	item, err := txn.Get(tableKey(table, k))
+	if err == badger.ErrKeyNotFound {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var r *record
+	if err := item.Value(func(v []byte) error {
+		r, err = decode(v)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, store.ErrNotFound
+	}
+
+	newRecord := &store.Record{
+		Key:      r.Key,
+		Value:    r.Value,
+		Metadata: make(map[string]interface{}),
+	}
+
+	for k, v := range r.Metadata {
+		newRecord.Metadata[k] = v
+	}
+
+	if !r.ExpiresAt.IsZero() {
+		newRecord.Expiry = time.Until(r.ExpiresAt)
+	}
+
+	newRecord.Version = r.Version
+
+	return newRecord, nil
+}
+
+func (b *badgerStore) set(db *badger.DB, database, table string, r *store.Record, opts store.WriteOptions) error {
+	var version uint64
+	var existed bool
+
+	err := db.Update(func(txn *badger.Txn) error {
+		var err error
+		version, existed, err = put(txn, table, r, opts)
+		return err
+	})
+	// another transaction changed the key while this one ran
+	for err == badger.ErrConflict {
+		err = db.Update(func(txn *badger.Txn) error {
+			var err error
+			version, existed, err = put(txn, table, r, opts)
+			return err
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	r.Version = version
+
+	if b.watchers.Watching() {
+		typ := store.EventCreate
+		if existed {
+			typ = store.EventUpdate
+		}
+		rec := *r
+		b.publish(database, table, typ, &rec)
+	}
+	return nil
+}
+
+// put writes the record to the table if the conditions of the write are met.
+// It returns the new version of the record and whether it replaced an existing one.
+func put(txn *badger.Txn, table string, r *store.Record, opts store.WriteOptions) (uint64, bool, error) {
+	cur, err := getRecord(txn, table, r.Key)
+	if err != nil && err != store.ErrNotFound {
+		return 0, false, err
+	}
+
+	// check the conditions of the write
+	if opts.IfNotExists && cur != nil {
+		return 0, false, store.ErrConflict
+	}
+	if opts.IfVersion > 0 && (cur == nil || cur.Version != opts.IfVersion) {
+		return 0, false, store.ErrConflict
+	}
+
+	version := uint64(1)
+	if cur != nil {
+		version = cur.Version + 1
+	}
+
+	e, err := newEntry(table, r, version)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := txn.SetEntry(e); err != nil {
+		return 0, false, err
+	}
+
+	return version, cur != nil, nil
+}
+
+// exists returns which of the keys are in the table
+func exists(db *badger.DB, table string, keys []string) ([]bool, error) {
+	found := make([]bool, len(keys))
+	err := db.View(func(txn *badger.Txn) error {
+		for i, k := range keys {
+			_, err := getRecord(txn, table, k)
+			if err != nil && err != store.ErrNotFound {
+				return err
+			}
+			found[i] = err == nil
+		}
+		return nil
+	})
+	return found, err
+}
+
+// publish an event for a change made to the database and table
+func (b *badgerStore) publish(database, table string, typ store.EventType, r *store.Record) {
+	database, table = b.names(database, table)
+	b.watchers.Publish(&store.Event{
+		Type:      typ,
+		Database:  database,
+		Table:     table,
+		Record:    r,
+		Timestamp: time.Now(),
+	})
+}
+
+// newEntry converts the record to a badger entry
+func newEntry(table string, r *store.Record, version uint64) (*badger.Entry, error) {
+	item := &record{
+		Key:      r.Key,
+		Value:    r.Value,
+		Metadata: make(map[string]interface{}),
+		Version:  version,
+	}
+
+	for k, v := range r.Metadata {
+		item.Metadata[k] = v
+	}
+
+	if r.Expiry != 0 {
+		item.ExpiresAt = time.Now().Add(r.Expiry)
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	e := badger.NewEntry(tableKey(table, r.Key), data)
+	if r.Expiry > 0 {
+		// badger has second granularity, round up so the key isn't removed early
+		e = e.WithTTL(time.Duration(math.Ceil(r.Expiry.Seconds())) * time.Second)
+	}
+
+	return e, nil
+}
+
+func (b *badgerStore) Close() error {
+	b.once.Do(func() {
+		close(b.exit)
+	})
+
+	b.Lock()
+	defer b.Unlock()
+
+	var err error
+	for k, db := range b.dbs {
+		if cerr := db.Close(); cerr != nil {
+			err = cerr
+		}
+		delete(b.dbs, k)
+	}
+	return err
+}
+
+func (b *badgerStore) Init(opts ...store.Option) error {
+	return b.init(opts...)
+}
+
+func (b *badgerStore) Delete(key string, opts ...store.DeleteOption) error {
+	if b.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOptions store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOptions)
+	}
+
+	db, table, err := b.getDB(deleteOptions.Database, deleteOptions.Table)
+	if err != nil {
+		return err
+	}
+
+	// Handle Prefix / suffix
+	if deleteOptions.Prefix || deleteOptions.Suffix {
+		prefix := ""
+		if deleteOptions.Prefix {
+			prefix = key
+		}
+		suffix := ""
+		if deleteOptions.Suffix {
+			suffix = key
+		}
+		keys, err := b.list(db, table, store.OrderAsc, 0, 0, prefix, suffix, "", nil)
+		if err != nil {
+			return err
+		}
+		return b.delete(db, deleteOptions.Database, table, keys...)
+	}
+
+	var found bool
+	err = db.Update(func(txn *badger.Txn) error {
+		if _, err := getRecord(txn, table, key); err == nil {
+			found = true
+		}
+		return txn.Delete(tableKey(table, key))
+	})
+	if err != nil {
+		return err
+	}
+
+	if found {
+		b.publish(deleteOptions.Database, table, store.EventDelete, &store.Record{Key: key})
+	}
+	return nil
+}
+
+// DeleteMany deletes the records using a badger write batch
+func (b *badgerStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if b.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOptions store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOptions)
+	}
+
+	db, table, err := b.getDB(deleteOptions.Database, deleteOptions.Table)
+	if err != nil {
+		return err
+	}
+
+	return b.delete(db, deleteOptions.Database, table, keys...)
+}
+
+// delete removes the keys from the table using a write batch
+func (b *badgerStore) delete(db *badger.DB, database, table string, keys ...string) error {
+	var found []bool
+	if b.watchers.Watching() {
+		var err error
+		if found, err = exists(db, table, keys); err != nil {
+			return err
+		}
+	}
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, k := range keys {
+		if err := wb.Delete(tableKey(table, k)); err != nil {
+			return err
+		}
+	}
+
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+
+	for i, ok := range found {
+		if ok {
+			b.publish(database, table, store.EventDelete, &store.Record{Key: keys[i]})
+		}
+	}
+	return nil
+}
+
+func (b *badgerStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var readOpts store.ReadOptions
+	for _, o := range opts {
+		o(&readOpts)
+	}
+
+	// earlier revisions of records aren't kept
+	if readOpts.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	db, table, err := b.getDB(readOpts.Database, readOpts.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *regexp.Regexp
+	if readOpts.Match != nil {
+		if match, err = readOpts.Match.Compile(); err != nil {
+			return nil, err
+		}
+		// reads with a pattern return many records, with the key as their prefix by default
+		if !readOpts.Suffix {
+			readOpts.Prefix = true
+		}
+	}
+
+	var after string
+	if readOpts.Cursor != nil {
+		if after, err = store.DecodeCursor(*readOpts.Cursor); err != nil {
+			return nil, err
+		}
+		if readOpts.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
+	var keys []string
+	var results []*store.Record
+
+	// Handle Prefix / suffix
+	if readOpts.Prefix || readOpts.Suffix {
+		prefix := ""
+		if readOpts.Prefix {
+			prefix = key
+		}
+		suffix := ""
+		if readOpts.Suffix {
+			suffix = key
+		}
+		if len(readOpts.Where) > 0 || readOpts.OrderBy == store.OrderByExpiry {
+			// the records are filtered and sorted before the limit and offset are applied
+			if results, err = b.where(db, table, readOpts, prefix, suffix, after, match); err != nil {
+				return nil, err
+			}
+		} else {
+			// list the keys
+			keys, err = b.list(db, table, readOpts.Order, readOpts.Limit, readOpts.Offset, prefix, suffix, after, match)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		keys = []string{key}
+	}
+
+	for _, k := range keys {
+		r, err := b.get(db, table, k)
+		if err != nil {
+			return results, err
+		}
+		if !r.Match(readOpts.Where) {
+			return results, store.ErrNotFound
+		}
+		results = append(results, r)
+	}
+
+	if readOpts.Cursor != nil {
+		var last string
+		if len(results) > 0 {
+			last = results[len(results)-1].Key
+		}
+		*readOpts.Cursor = store.NextCursor(readOpts.Limit, len(results), last)
+	}
+
+	return results, nil
+}
+
+// where returns the records matching the key filters and metadata values of the read
+func (b *badgerStore) where(db *badger.DB, table string, readOpts store.ReadOptions, prefix, suffix, after string, match *regexp.Regexp) ([]*store.Record, error) {
+	keys, err := b.list(db, table, readOpts.Order, 0, 0, prefix, suffix, after, match)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*store.Record
+
+	err = db.View(func(txn *badger.Txn) error {
+		for _, k := range keys {
+			r, err := getRecord(txn, table, k)
+			if err == store.ErrNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if r.Match(readOpts.Where) {
+				results = append(results, r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if readOpts.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, readOpts.Order)
+	}
+
+	if readOpts.Offset > 0 {
+		// offset is greater than the records we have
+		if int(readOpts.Offset) >= len(results) {
+			return nil, nil
+		}
+		results = results[readOpts.Offset:]
+	}
+
+	if readOpts.Limit > 0 && int(readOpts.Limit) < len(results) {
+		results = results[:readOpts.Limit]
+	}
+
+	return results, nil
+}
+
+func (b *badgerStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if b.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	db, table, err := b.getDB(writeOpts.Database, writeOpts.Table)
+	if err != nil {
+		return err
+	}
+
+	return b.set(db, writeOpts.Database, table, r, writeOpts)
+}
+
+// WriteMany writes the records in as few badger transactions as possible. The conditions of
+// the write are checked against each record and a conditional write is made in a single
+// transaction, so none of the records are written if one of them isn't met.
+func (b *badgerStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if b.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	db, table, err := b.getDB(writeOpts.Database, writeOpts.Table)
+	if err != nil {
+		return err
+	}
+
+	conditional := writeOpts.IfVersion > 0 || writeOpts.IfNotExists
+
+	versions := make([]uint64, len(recs))
+	found := make([]bool, len(recs))
+
+	for i := 0; i < len(recs); {
+		// write the records until the transaction is full, then commit and start another
+		n := 0
+		err := db.Update(func(txn *badger.Txn) error {
+			for _, r := range recs[i:] {
+				version, existed, err := put(txn, table, r, writeOpts)
+				if err == badger.ErrTxnTooBig && n > 0 && !conditional {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				versions[i+n] = version
+				found[i+n] = existed
+				n++
+			}
+			return nil
+		})
+		if err == badger.ErrConflict {
+			// another transaction changed one of the keys, try again
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		i += n
+	}
+
+	watching := b.watchers.Watching()
+
+	for i, r := range recs {
+		r.Version = versions[i]
+		if !watching {
+			continue
+		}
+		typ := store.EventCreate
+		if found[i] {
+			typ = store.EventUpdate
+		}
+		rec := *r
+		b.publish(writeOpts.Database, table, typ, &rec)
+	}
+	return nil
+}
+
+func (b *badgerStore) Options() store.Options {
+	return b.options
+}
+
+func (b *badgerStore) List(opts ...store.ListOption) ([]string, error) {
+	var listOptions store.ListOptions
+	for _, o := range opts {
+		o(&listOptions)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(listOptions.Delimiter) > 0 {
+		return store.ListDelimited(b, listOptions)
+	}
+
+	db, table, err := b.getDB(listOptions.Database, listOptions.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *regexp.Regexp
+	if listOptions.Match != nil {
+		if match, err = listOptions.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	var after string
+	if listOptions.Cursor != nil {
+		if after, err = store.DecodeCursor(*listOptions.Cursor); err != nil {
+			return nil, err
+		}
+		if listOptions.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
+	if listOptions.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := b.where(db, table, store.ReadOptions{
+			Limit:   listOptions.Limit,
+			Offset:  listOptions.Offset,
+			Order:   listOptions.Order,
+			OrderBy: listOptions.OrderBy,
+		}, listOptions.Prefix, listOptions.Suffix, "", match)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(recs))
+		for _, r := range recs {
+			keys = append(keys, r.Key)
+		}
+		return keys, nil
+	}
+
+	keys, err := b.list(db, table, listOptions.Order, listOptions.Limit, listOptions.Offset, listOptions.Prefix, listOptions.Suffix, after, match)
+	if err != nil {
+		return nil, err
+	}
+
+	if listOptions.Cursor != nil {
+		var last string
+		if len(keys) > 0 {
+			last = keys[len(keys)-1]
+		}
+		*listOptions.Cursor = store.NextCursor(listOptions.Limit, len(keys), last)
+	}
+
+	return keys, nil
+}
+
+// Txn runs fn in a badger read-write transaction
+func (b *badgerStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	if b.options.ReadOnly {
+		fn = store.ReadOnlyTxn(fn)
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	db, table, err := b.getDB(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+
+	var btx *badgerTx
+	err = db.Update(func(txn *badger.Txn) error {
+		btx = &badgerTx{txn: txn, table: table}
+		if err := fn(btx); err != nil {
+			return err
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range btx.events {
+		b.publish(options.Database, table, ev.Type, ev.Record)
+	}
+	return nil
+}
+
+type badgerTx struct {
+	txn   *badger.Txn
+	table string
+	// the changes to publish once the transaction is committed
+	events []*store.Event
+}
+
+func (t *badgerTx) Read(key string) (*store.Record, error) {
+	return getRecord(t.txn, t.table, key)
+}
+
+func (t *badgerTx) Write(r *store.Record) error {
+	version, existed, err := put(t.txn, t.table, r, store.WriteOptions{})
+	if err != nil {
+		return err
+	}
+	typ := store.EventCreate
+	if existed {
+		typ = store.EventUpdate
+	}
+	rec := *r
+	rec.Version = version
+	t.events = append(t.events, &store.Event{Type: typ, Record: &rec})
+	return nil
+}
+
+func (t *badgerTx) Delete(key string) error {
+	if _, err := getRecord(t.txn, t.table, key); err == nil {
+		t.events = append(t.events, &store.Event{Type: store.EventDelete, Record: &store.Record{Key: key}})
+	}
+	return t.txn.Delete(tableKey(t.table, key))
+}
+
+// Watch the records in the store for changes made by this process
+// Touch sets the expiry of the record, the record is read and written in
+// a single transaction so a concurrent write isn't lost
+func (b *badgerStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if b.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	db, table, err := b.getDB(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+
+	var r *store.Record
+	touch := func(txn *badger.Txn) error {
+		cur, err := getRecord(txn, table, key)
+		if err != nil {
+			return err
+		}
+		cur.Expiry = expiry
+		if cur.Version, _, err = put(txn, table, cur, store.WriteOptions{}); err != nil {
+			return err
+		}
+		r = cur
+		return nil
+	}
+
+	err = db.Update(touch)
+	// another transaction changed the key while this one ran
+	for err == badger.ErrConflict {
+		err = db.Update(touch)
+	}
+	if err != nil {
+		return err
+	}
+
+	if b.watchers.Watching() {
+		b.publish(options.Database, options.Table, store.EventUpdate, r)
+	}
+	return nil
+}
+
+func (b *badgerStore) Watch(ctx context.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	options.Database, options.Table = b.names(options.Database, options.Table)
+	return b.watchers.Watch(ctx, key, options), nil
+}
+
+func (b *badgerStore) String() string {
+	return "badger"
+}
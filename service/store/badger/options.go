@@ -0,0 +1,20 @@
+package badger
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type dirKey struct{}
+
+// WithDir sets the directory to store the files in
+func WithDir(dir string) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.WithValue(context.Background(), dirKey{}, dir)
+		} else {
+			o.Context = context.WithValue(o.Context, dirKey{}, dir)
+		}
+	}
+}
@@ -17,6 +17,7 @@ package store
 import (
 	"errors"
 	"io"
+	"time"
 )
 
 var (
@@ -26,6 +27,8 @@ var (
 
 // BlobStore is an interface for reading / writing blobs
 type BlobStore interface {
+	// Read returns a reader of the blob. Stores which stream the blob rather than reading it
+	// into memory return an io.ReadCloser, which should be closed once it's been read.
 	Read(key string, opts ...BlobOption) (io.Reader, error)
 	Write(key string, blob io.Reader, opts ...BlobOption) error
 	Delete(key string, opts ...BlobOption) error
@@ -33,12 +36,69 @@ type BlobStore interface {
 	List(opts ...BlobListOption) ([]string, error)
 }
 
+// Presigner is implemented by blob stores which can sign urls to read / write a blob directly,
+// so clients can transfer large blobs without them being proxied through the service
+type Presigner interface {
+	// PresignGet returns a url the blob can be downloaded from until the ttl expires
+	PresignGet(key string, ttl time.Duration, opts ...BlobOption) (string, error)
+	// PresignPut returns a url the blob can be uploaded to until the ttl expires
+	PresignPut(key string, ttl time.Duration, opts ...BlobOption) (string, error)
+}
+
+// PresignGet returns a url the blob can be downloaded from until the ttl expires. If the
+// blob store doesn't implement Presigner ErrNotSupported is returned.
+func PresignGet(key string, ttl time.Duration, opts ...BlobOption) (string, error) {
+	p, ok := DefaultBlobStore.(Presigner)
+	if !ok {
+		return "", ErrNotSupported
+	}
+	return p.PresignGet(key, ttl, opts...)
+}
+
+// PresignPut returns a url the blob can be uploaded to until the ttl expires. If the blob
+// store doesn't implement Presigner ErrNotSupported is returned.
+func PresignPut(key string, ttl time.Duration, opts ...BlobOption) (string, error) {
+	p, ok := DefaultBlobStore.(Presigner)
+	if !ok {
+		return "", ErrNotSupported
+	}
+	return p.PresignPut(key, ttl, opts...)
+}
+
+// BlobInfo is a blob and its attributes
+type BlobInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	Metadata    map[string]string
+	// Modified is when the blob was last written, it's zero if the store doesn't know
+	Modified time.Time
+}
+
+// BlobInfoLister is implemented by blob stores which can list blobs with their attributes
+type BlobInfoLister interface {
+	// ListInfo returns the blobs that match, or an empty list with no error if none matched.
+	ListInfo(opts ...BlobListOption) ([]*BlobInfo, error)
+}
+
+// ListInfo returns the blobs that match with their attributes. If the blob store doesn't
+// implement BlobInfoLister ErrNotSupported is returned.
+func ListInfo(opts ...BlobListOption) ([]*BlobInfo, error) {
+	l, ok := DefaultBlobStore.(BlobInfoLister)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return l.ListInfo(opts...)
+}
+
 // BlobOptions contains options to use when interacting with the store
 type BlobOptions struct {
 	// Namespace to  from
 	Namespace   string
 	Public      bool
 	ContentType string
+	// Metadata is written with the blob, stores which don't support it ignore it
+	Metadata map[string]string
 }
 
 // BlobOption sets one or more BlobOptions
@@ -65,6 +125,13 @@ func BlobContentType(contentType string) BlobOption {
 	}
 }
 
+// BlobMetadata sets the Metadata option
+func BlobMetadata(md map[string]string) BlobOption {
+	return func(o *BlobOptions) {
+		o.Metadata = md
+	}
+}
+
 type BlobListOptions struct {
 	Namespace string
 	Prefix    string
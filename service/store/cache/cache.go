@@ -15,25 +15,48 @@
 package cache
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
 	"github.com/micro/micro/v3/service/store"
 	"github.com/micro/micro/v3/service/store/memory"
+	"golang.org/x/sync/singleflight"
 )
 
 // cache store is a store with caching to reduce IO where applicable.
 // A memory store is used to cache reads from the given backing store.
 // Reads are read through, writes are write-through
 type cache struct {
+	// first so the counters are 64 bit aligned for atomic operations
+	counters counters
+
 	m       store.Store // the memory store
 	b       store.Store // the backing store, could be file, cockroach etc
 	options store.Options
+	// tracks the records in memory, evicting them once its limits are reached
+	lru *lru
+	// deduplicates concurrent reads from the backing store
+	reads singleflight.Group
+}
+
+// writable keeps memory writable when the store is read only, the backing store rejects
+// the changes and memory still caches the records read from it
+func writable(o *store.Options) {
+	o.ReadOnly = false
 }
 
 // NewStore returns a new cache store
 func NewStore(store store.Store, opts ...store.Option) store.Store {
 	cf := &cache{
-		m: memory.NewStore(opts...),
-		b: store,
+		// the backing store sets the versions of the records
+		m:   memory.NewStore(append(opts, memory.KeepVersions(), writable)...),
+		b:   store,
+		lru: newLRU(0, 0, 0),
 	}
+	cf.init(opts...)
 	return cf
 
 }
@@ -42,6 +65,77 @@ func (c *cache) init(opts ...store.Option) error {
 	for _, o := range opts {
 		o(&c.options)
 	}
+	if c.options.Context == nil {
+		return nil
+	}
+
+	maxEntries, _ := c.options.Context.Value(maxEntriesKey{}).(int)
+	maxBytes, _ := c.options.Context.Value(maxBytesKey{}).(int64)
+	ttl, _ := c.options.Context.Value(ttlKey{}).(time.Duration)
+	// keep tracking the records already in memory if the limits haven't changed
+	if c.lru.maxEntries != maxEntries || c.lru.maxBytes != maxBytes || c.lru.ttl != ttl {
+		c.lru = newLRU(maxEntries, maxBytes, ttl)
+	}
+	return nil
+}
+
+// names returns the database and table memory uses for them
+func (c *cache) names(database, table string) (string, string) {
+	if len(database) == 0 {
+		database = c.m.Options().Database
+	}
+	if len(table) == 0 {
+		table = c.m.Options().Table
+	}
+	return database, table
+}
+
+// cached returns true if the records read from memory are still to be used, they
+// aren't if they've been cached for longer than the TTL
+func (c *cache) cached(database, table string, recs []*store.Record) bool {
+	database, table = c.names(database, table)
+	for _, r := range recs {
+		if !c.lru.get(entryKey{database, table, r.Key}) {
+			return false
+		}
+	}
+	return true
+}
+
+// put writes the records to memory, evicting the least recently used if it's full
+func (c *cache) put(database, table string, recs ...*store.Record) error {
+	for _, r := range recs {
+		if err := c.m.Write(r, store.WriteTo(database, table)); err != nil {
+			return err
+		}
+		db, tbl := c.names(database, table)
+		for _, k := range c.lru.add(entryKey{db, tbl, r.Key}, int64(len(r.Key)+len(r.Value))) {
+			if err := c.m.Delete(k.key, store.DeleteFrom(k.database, k.table)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// drop removes the records deleted with the options from memory
+func (c *cache) drop(key string, opts ...store.DeleteOption) error {
+	if err := c.m.Delete(key, opts...); err != nil {
+		return err
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, table := c.names(options.Database, options.Table)
+	if !options.Prefix && !options.Suffix {
+		c.lru.remove(entryKey{database, table, key})
+		return nil
+	}
+	c.lru.removeMatching(database, table, func(k string) bool {
+		return (!options.Prefix || strings.HasPrefix(k, key)) && (!options.Suffix || strings.HasSuffix(k, key))
+	})
 	return nil
 }
 
@@ -50,7 +144,7 @@ func (c *cache) Init(opts ...store.Option) error {
 	if err := c.init(opts...); err != nil {
 		return err
 	}
-	if err := c.m.Init(opts...); err != nil {
+	if err := c.m.Init(append(opts, memory.KeepVersions(), writable)...); err != nil {
 		return err
 	}
 	return c.b.Init(opts...)
@@ -63,69 +157,358 @@ func (c *cache) Options() store.Options {
 
 // Read takes a single key name and optional ReadOptions. It returns matching []*Record or an error.
 func (c *cache) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
-	recs, err := c.m.Read(key, opts...)
-	if err != nil && err != store.ErrNotFound {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// only the latest revisions of records are cached
+	if options.Version > 0 {
+		return c.readBackend(key, opts...)
+	}
+
+	// memory may only hold some of the records matching a prefix, suffix or pattern, so only
+	// the record of a key is read from it. Pages are read from the backing store too.
+	exact := !options.Prefix && !options.Suffix && options.Match == nil
+	if exact && options.Cursor == nil {
+		recs, err := c.m.Read(key, opts...)
+		if err != nil && err != store.ErrNotFound {
+			return nil, err
+		}
+		if len(recs) > 0 && c.cached(options.Database, options.Table, recs) {
+			atomic.AddUint64(&c.counters.hits, 1)
+			return recs, nil
+		}
+		atomic.AddUint64(&c.counters.misses, 1)
+	}
+
+	// the cursor is set to the next page for each read so pages aren't shared
+	if options.Cursor != nil {
+		return c.readThrough(key, options, opts)
+	}
+
+	v, err, shared := c.reads.Do(c.flight(key, options), func() (interface{}, error) {
+		return c.readThrough(key, options, opts)
+	})
+	if err != nil {
 		return nil, err
 	}
-	if len(recs) > 0 {
-		return recs, nil
+	recs := v.([]*store.Record)
+	if shared {
+		recs = copyRecords(recs)
+	}
+	return recs, nil
+}
+
+// readThrough reads the records from the backing store and writes them to memory
+func (c *cache) readThrough(key string, options store.ReadOptions, opts []store.ReadOption) ([]*store.Record, error) {
+	recs, err := c.readBackend(key, opts...)
+	if err != nil {
+		return nil, err
 	}
-	recs, err = c.b.Read(key, opts...)
-	if err == nil {
-		for _, rec := range recs {
-			if err := c.m.Write(rec); err != nil {
-				return nil, err
+	if err := c.put(options.Database, options.Table, recs...); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// flight returns the key concurrent reads with the same options share a read from the
+// backing store with
+func (c *cache) flight(key string, options store.ReadOptions) string {
+	database, table := c.names(options.Database, options.Table)
+	var match string
+	if options.Match != nil {
+		match = fmt.Sprintf("%t:%s", options.Match.Regexp, options.Match.Expr)
+	}
+	return fmt.Sprintf("%q/%q/%q/%t/%t/%d/%d/%s/%s/%v/%q", database, table, key, options.Prefix,
+		options.Suffix, options.Limit, options.Offset, options.Order, options.OrderBy, options.Where, match)
+}
+
+// copyRecords returns copies of records shared between reads, so one can't change another's
+func copyRecords(recs []*store.Record) []*store.Record {
+	out := make([]*store.Record, len(recs))
+	for i, r := range recs {
+		rec := *r
+		rec.Value = append([]byte(nil), r.Value...)
+		if r.Metadata != nil {
+			rec.Metadata = make(map[string]interface{}, len(r.Metadata))
+			for k, v := range r.Metadata {
+				rec.Metadata[k] = v
 			}
 		}
+		out[i] = &rec
 	}
-	return recs, err
+	return out
 }
 
 // Write() writes a record to the store, and returns an error if the record was not written.
-// If the write succeeds in writing to memory but fails to write through to file, you'll receive an error
-// but the value may still reside in memory so appropriate action should be taken.
+// The record is written to the backing store first, which checks the conditions of the write
+// and sets the version, and then to memory.
 func (c *cache) Write(r *store.Record, opts ...store.WriteOption) error {
-	if err := c.m.Write(r, opts...); err != nil {
+	if err := c.b.Write(r, opts...); err != nil {
 		return err
 	}
-	return c.b.Write(r, opts...)
+	options := writeOptions(opts)
+	return c.put(options.Database, options.Table, r)
+}
+
+// WriteMany writes the records to the backing store, in a single operation if it
+// supports it, and then to memory.
+func (c *cache) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if bw, ok := c.b.(store.BatchWriter); ok {
+		if err := bw.WriteMany(recs, opts...); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range recs {
+			if err := c.b.Write(r, opts...); err != nil {
+				return err
+			}
+		}
+	}
+	options := writeOptions(opts)
+	return c.put(options.Database, options.Table, recs...)
+}
+
+// writeOptions returns the options of a write, only the database and table are used
+// for memory as the conditions have already been checked by the backing store
+func writeOptions(opts []store.WriteOption) store.WriteOptions {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
 }
 
 // Delete removes the record with the corresponding key from the store.
 // If the delete succeeds in writing to memory but fails to write through to file, you'll receive an error
 // but the value may still reside in memory so appropriate action should be taken.
 func (c *cache) Delete(key string, opts ...store.DeleteOption) error {
-	if err := c.m.Delete(key, opts...); err != nil {
+	if err := c.drop(key, opts...); err != nil {
 		return err
 	}
 	return c.b.Delete(key, opts...)
 }
 
-// List returns any keys that match, or an empty list with no error if none matched.
+// DeleteMany removes the records from memory and then from the backing store, in a single
+// operation if the backing store supports it.
+func (c *cache) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	for _, k := range keys {
+		if err := c.drop(k, opts...); err != nil {
+			return err
+		}
+	}
+	if bd, ok := c.b.(store.BatchDeleter); ok {
+		return bd.DeleteMany(keys, opts...)
+	}
+	for _, k := range keys {
+		if err := c.b.Delete(k, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Txn runs fn in a transaction on the backing store, the keys it changed are removed
+// from memory once it has been committed so they're read through again. If the backing store doesn't support transactions
+// store.ErrNotSupported is returned.
+func (c *cache) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	t, ok := c.b.(store.Transactional)
+	if !ok {
+		return store.ErrNotSupported
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var cached *cacheTx
+	err := t.Txn(ctx, func(tx store.Tx) error {
+		cached = &cacheTx{Tx: tx}
+		return fn(cached)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range cached.keys {
+		if err := c.drop(key, store.DeleteFrom(options.Database, options.Table)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cacheTx records the keys changed in a transaction so they can be removed from memory
+type cacheTx struct {
+	store.Tx
+	keys []string
+}
+
+func (t *cacheTx) Write(r *store.Record) error {
+	if err := t.Tx.Write(r); err != nil {
+		return err
+	}
+	t.keys = append(t.keys, r.Key)
+	return nil
+}
+
+func (t *cacheTx) Delete(key string) error {
+	if err := t.Tx.Delete(key); err != nil {
+		return err
+	}
+	t.keys = append(t.keys, key)
+	return nil
+}
+
+// Watch the backing store for changes. If it doesn't support watches store.ErrNotSupported is returned.
+func (c *cache) Watch(ctx context.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	w, ok := c.b.(store.Watcher)
+	if !ok {
+		return nil, store.ErrNotSupported
+	}
+	return w.Watch(ctx, key, opts...)
+}
+
+// ListVersions returns the revisions of the key from the backing store. If it doesn't keep
+// them store.ErrNotSupported is returned.
+func (c *cache) ListVersions(key string, opts ...store.ListOption) ([]*store.Revision, error) {
+	v, ok := c.b.(store.Versioner)
+	if !ok {
+		return nil, store.ErrNotSupported
+	}
+	return v.ListVersions(key, opts...)
+}
+
+// Touch sets the expiry of the record in the backing store and removes it from memory so
+// it's read through again. If the backing store doesn't support it store.ErrNotSupported is returned.
+func (c *cache) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	t, ok := c.b.(store.Toucher)
+	if !ok {
+		return store.ErrNotSupported
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if err := t.Touch(ctx, key, expiry, opts...); err != nil {
+		return err
+	}
+	return c.drop(key, store.DeleteFrom(options.Database, options.Table))
+}
+
+// List returns the keys from the backing store, as memory may only hold some of them. The
+// records of the keys returned are read through if memory doesn't hold them, so use ListLimit
+// to only warm a page of a large table, or Warm to preload all of them in a single read.
 func (c *cache) List(opts ...store.ListOption) ([]string, error) {
-	keys, err := c.m.List(opts...)
-	if err != nil && err != store.ErrNotFound {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// grouped keys aren't the keys of records, so there's nothing to warm
+	if len(options.Delimiter) > 0 {
+		return c.b.List(opts...)
+	}
+
+	keys, err := c.b.List(opts...)
+	if err != nil {
 		return nil, err
 	}
-	if len(keys) > 0 {
-		return keys, nil
+
+	database, table := c.names(options.Database, options.Table)
+	for _, key := range keys {
+		if c.lru.get(entryKey{database, table, key}) {
+			continue
+		}
+		recs, err := c.readBackend(key, store.ReadFrom(options.Database, options.Table))
+		if err == store.ErrNotFound {
+			// deleted or expired since it was listed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if err := c.put(options.Database, options.Table, recs...); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Warm preloads memory with the records of the keys the options list, reading them from the
+// backing store in a single read. The limits of the cache still apply, so the least recently
+// used records are evicted if they don't all fit.
+func (c *cache) Warm(opts ...store.ListOption) error {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
 	}
-	keys, err = c.b.List(opts...)
-	if err == nil {
-		for _, key := range keys {
-			recs, err := c.b.Read(key)
-			if err != nil {
-				return nil, err
-			}
-			for _, r := range recs {
-				if err := c.m.Write(r); err != nil {
-					return nil, err
-				}
-			}
 
+	readOpts := []store.ReadOption{
+		store.ReadPrefix(),
+		store.ReadFrom(options.Database, options.Table),
+		store.ReadOrder(options.Order),
+	}
+	// the records are filtered by suffix once read so the page is taken after
+	if len(options.Suffix) == 0 {
+		readOpts = append(readOpts, store.ReadLimit(options.Limit), store.ReadOffset(options.Offset))
+	}
+	recs, err := c.readBackend(options.Prefix, readOpts...)
+	if err == store.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if len(options.Suffix) > 0 {
+		var matched []*store.Record
+		for _, r := range recs {
+			if strings.HasSuffix(r.Key, options.Suffix) {
+				matched = append(matched, r)
+			}
+		}
+		if options.Offset >= uint(len(matched)) {
+			return nil
 		}
+		matched = matched[options.Offset:]
+		if options.Limit > 0 && options.Limit < uint(len(matched)) {
+			matched = matched[:options.Limit]
+		}
+		recs = matched
+	}
+	return c.put(options.Database, options.Table, recs...)
+}
+
+// Warm preloads the memory of a cache store with the records of the keys the options list.
+// If the store isn't a cache store store.ErrNotSupported is returned.
+func Warm(s store.Store, opts ...store.ListOption) error {
+	c, ok := s.(*cache)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	return c.Warm(opts...)
+}
+
+// Compact rewrites the files of the backing store. If it doesn't support it
+// store.ErrNotSupported is returned.
+func (c *cache) Compact(ctx context.Context, opts ...store.CompactOption) error {
+	cp, ok := c.b.(store.Compactor)
+	if !ok {
+		return store.ErrNotSupported
 	}
-	return keys, err
+	return cp.Compact(ctx, opts...)
+}
+
+// Healthy checks the backing store if it supports it
+func (c *cache) Healthy(ctx context.Context) error {
+	if h, ok := c.b.(store.HealthChecker); ok {
+		return h.Healthy(ctx)
+	}
+	return nil
 }
 
 // Close the store and the underlying store
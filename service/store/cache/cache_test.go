@@ -17,7 +17,9 @@ package cache
 import (
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/micro/micro/v3/service/store"
 	"github.com/micro/micro/v3/service/store/file"
@@ -114,3 +116,175 @@ func TestList(t *testing.T) {
 	assert.Len(t, keys, 2)
 
 }
+
+func TestMaxEntries(t *testing.T) {
+	cf := NewStore(file.NewStore(), MaxEntries(2))
+	cfInt := cf.(*cache)
+	defer cleanup(file.DefaultDatabase, cf)
+
+	for _, k := range []string{"key1", "key2"} {
+		assert.NoError(t, cf.Write(&store.Record{Key: k, Value: []byte("foo")}))
+	}
+	// key1 becomes the most recently used so key2 is evicted
+	_, err := cf.Read("key1")
+	assert.NoError(t, err)
+	assert.NoError(t, cf.Write(&store.Record{Key: "key3", Value: []byte("foo")}))
+
+	_, err = cfInt.m.Read("key2")
+	assert.Equal(t, store.ErrNotFound, err, "Expected key2 to be evicted from memory")
+	for _, k := range []string{"key1", "key3"} {
+		_, err = cfInt.m.Read(k)
+		assert.NoError(t, err, "Expected %s to be held in memory", k)
+	}
+
+	// evicted records are still read from the backing store
+	recs, err := cf.Read("key2")
+	assert.NoError(t, err)
+	assert.Len(t, recs, 1)
+}
+
+func TestMaxEntriesPrefix(t *testing.T) {
+	cf := NewStore(file.NewStore(), MaxEntries(1))
+	defer cleanup(file.DefaultDatabase, cf)
+
+	for _, k := range []string{"key1", "key2", "key3"} {
+		assert.NoError(t, cf.Write(&store.Record{Key: k, Value: []byte("foo")}))
+	}
+
+	// memory only holds key3, the records evicted are still read
+	recs, err := cf.Read("key", store.ReadPrefix())
+	assert.NoError(t, err)
+	assert.Len(t, recs, 3)
+}
+
+func TestMaxBytes(t *testing.T) {
+	cf := NewStore(file.NewStore(), MaxBytes(10))
+	cfInt := cf.(*cache)
+	defer cleanup(file.DefaultDatabase, cf)
+
+	assert.NoError(t, cf.Write(&store.Record{Key: "key1", Value: []byte("foo")}))
+	assert.NoError(t, cf.Write(&store.Record{Key: "key2", Value: []byte("foo")}))
+	_, err := cfInt.m.Read("key1")
+	assert.Equal(t, store.ErrNotFound, err, "Expected key1 to be evicted from memory")
+
+	// records larger than the limit aren't held at all
+	assert.NoError(t, cf.Write(&store.Record{Key: "key3", Value: []byte("foobarbaz")}))
+	_, err = cfInt.m.Read("key3")
+	assert.Equal(t, store.ErrNotFound, err)
+	recs, err := cf.Read("key3")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foobarbaz"), recs[0].Value)
+}
+
+func TestTTL(t *testing.T) {
+	cf := NewStore(file.NewStore(), TTL(50*time.Millisecond))
+	cfInt := cf.(*cache)
+	defer cleanup(file.DefaultDatabase, cf)
+
+	assert.NoError(t, cf.Write(&store.Record{Key: "key1", Value: []byte("foo")}))
+	assert.NoError(t, cfInt.b.Write(&store.Record{Key: "key1", Value: []byte("bar")}))
+
+	recs, err := cf.Read("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), recs[0].Value, "Expected the record to be read from memory")
+
+	time.Sleep(100 * time.Millisecond)
+	recs, err = cf.Read("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), recs[0].Value, "Expected the record to be read from the backing store")
+}
+
+func TestStats(t *testing.T) {
+	cf := NewStore(file.NewStore())
+	defer cleanup(file.DefaultDatabase, cf)
+
+	assert.NoError(t, cf.Write(&store.Record{Key: "key1", Value: []byte("foo")}))
+	cf.(*cache).b.Write(&store.Record{Key: "key2", Value: []byte("foo")})
+
+	for _, k := range []string{"key1", "key2", "key2"} {
+		_, err := cf.Read(k)
+		assert.NoError(t, err)
+	}
+	_, err := cf.Read("missing")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	stats, err := ReadStats(cf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, 0.5, stats.HitRatio())
+	assert.Equal(t, uint64(2), stats.BackendReads)
+	assert.Equal(t, 2, stats.Entries)
+
+	_, err = ReadStats(file.NewStore())
+	assert.Equal(t, store.ErrNotSupported, err)
+}
+
+// slowStore delays reads so concurrent ones overlap
+type slowStore struct {
+	store.Store
+}
+
+func (s *slowStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	time.Sleep(50 * time.Millisecond)
+	return s.Store.Read(key, opts...)
+}
+
+func TestConcurrentReads(t *testing.T) {
+	b := file.NewStore()
+	cf := NewStore(&slowStore{b})
+	defer cleanup(file.DefaultDatabase, cf)
+	assert.NoError(t, b.Write(&store.Record{Key: "key1", Value: []byte("foo")}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recs, err := cf.Read("key1")
+			assert.NoError(t, err)
+			assert.Len(t, recs, 1)
+		}()
+	}
+	wg.Wait()
+
+	stats, err := ReadStats(cf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.BackendReads, "Expected the reads to share a single read of the backing store")
+}
+
+func TestListWarm(t *testing.T) {
+	cf := NewStore(file.NewStore())
+	cfInt := cf.(*cache)
+	defer cleanup(file.DefaultDatabase, cf)
+
+	for _, k := range []string{"key1", "key2", "key3", "other"} {
+		assert.NoError(t, cfInt.b.Write(&store.Record{Key: k, Value: []byte("foo")}))
+	}
+
+	// only the page listed is warmed
+	keys, err := cf.List(store.ListLimit(1), store.ListOffset(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key2"}, keys)
+	keys, err = cfInt.m.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key2"}, keys)
+
+	// reads of a prefix aren't answered with the records of the page warmed
+	recs, err := cf.Read("key", store.ReadPrefix())
+	assert.NoError(t, err)
+	assert.Len(t, recs, 3)
+
+	// keys which aren't in memory are still listed
+	keys, err = cf.List(store.ListPrefix("key"))
+	assert.NoError(t, err)
+	assert.Len(t, keys, 3)
+
+	assert.NoError(t, Warm(cf, store.ListSuffix("er")))
+	_, err = cfInt.m.Read("other")
+	assert.NoError(t, err, "Expected the record to be warmed")
+
+	stats := cfInt.Stats()
+	assert.Equal(t, 4, stats.Entries)
+	assert.Equal(t, store.ErrNotSupported, Warm(file.NewStore()))
+}
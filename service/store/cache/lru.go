@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entryKey identifies a record held in memory
+type entryKey struct {
+	database, table, key string
+}
+
+type entry struct {
+	k        entryKey
+	size     int64
+	cachedAt time.Time
+}
+
+// lru tracks the records held in memory so the least recently used can be evicted
+// once the limits are reached
+type lru struct {
+	sync.Mutex
+
+	maxEntries int
+	maxBytes   int64
+	ttl        time.Duration
+
+	bytes int64
+	// the most recently used entry is at the front
+	list  *list.List
+	items map[entryKey]*list.Element
+}
+
+func newLRU(maxEntries int, maxBytes int64, ttl time.Duration) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		list:       list.New(),
+		items:      make(map[entryKey]*list.Element),
+	}
+}
+
+// add the entry as the most recently used and return the entries to evict
+func (l *lru) add(k entryKey, size int64) []entryKey {
+	l.Lock()
+	defer l.Unlock()
+
+	if el, ok := l.items[k]; ok {
+		e := el.Value.(*entry)
+		l.bytes += size - e.size
+		e.size = size
+		e.cachedAt = time.Now()
+		l.list.MoveToFront(el)
+	} else {
+		l.items[k] = l.list.PushFront(&entry{k: k, size: size, cachedAt: time.Now()})
+		l.bytes += size
+	}
+
+	var evicted []entryKey
+	for l.full() {
+		e := l.removeElement(l.list.Back())
+		evicted = append(evicted, e.k)
+	}
+	return evicted
+}
+
+func (l *lru) full() bool {
+	return (l.maxEntries > 0 && l.list.Len() > l.maxEntries) || (l.maxBytes > 0 && l.bytes > l.maxBytes)
+}
+
+// get marks the entry as the most recently used. It returns false if the entry isn't
+// held or was cached longer ago than the ttl.
+func (l *lru) get(k entryKey) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	el, ok := l.items[k]
+	if !ok {
+		return false
+	}
+	if l.ttl > 0 && time.Since(el.Value.(*entry).cachedAt) > l.ttl {
+		return false
+	}
+	l.list.MoveToFront(el)
+	return true
+}
+
+// remove the entry
+func (l *lru) remove(k entryKey) {
+	l.Lock()
+	defer l.Unlock()
+
+	if el, ok := l.items[k]; ok {
+		l.removeElement(el)
+	}
+}
+
+// removeMatching removes the entries in the database and table whose keys match
+func (l *lru) removeMatching(database, table string, match func(key string) bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	for k, el := range l.items {
+		if k.database == database && k.table == table && match(k.key) {
+			l.removeElement(el)
+		}
+	}
+}
+
+func (l *lru) removeElement(el *list.Element) *entry {
+	e := l.list.Remove(el).(*entry)
+	delete(l.items, e.k)
+	l.bytes -= e.size
+	return e
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type maxEntriesKey struct{}
+type maxBytesKey struct{}
+type ttlKey struct{}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// MaxEntries limits the number of records held in memory, the least recently used
+// are evicted once it's reached
+func MaxEntries(n int) store.Option {
+	return setOption(maxEntriesKey{}, n)
+}
+
+// MaxBytes limits the total size of the keys and values held in memory, the least
+// recently used records are evicted once it's reached
+func MaxBytes(n int64) store.Option {
+	return setOption(maxBytesKey{}, n)
+}
+
+// TTL limits how long a record is held in memory, after which it's read from the
+// backing store again. The expiry of the record itself is unchanged.
+func TTL(d time.Duration) store.Option {
+	return setOption(ttlKey{}, d)
+}
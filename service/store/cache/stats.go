@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+// Stats of a cache store, used to tune the size of the cache
+type Stats struct {
+	// Hits is the number of reads served from memory
+	Hits uint64
+	// Misses is the number of reads which went to the backing store
+	Misses uint64
+	// Entries is the number of records held in memory
+	Entries int
+	// BackendReads is the number of reads made to the backing store, including
+	// those to warm memory when listing
+	BackendReads uint64
+	// BackendReadTime is the total time spent reading from the backing store
+	BackendReadTime time.Duration
+}
+
+// HitRatio returns the proportion of reads served from memory
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// BackendReadLatency returns the mean time a read from the backing store took
+func (s Stats) BackendReadLatency() time.Duration {
+	if s.BackendReads == 0 {
+		return 0
+	}
+	return s.BackendReadTime / time.Duration(s.BackendReads)
+}
+
+// counters of a cache store, updated atomically
+type counters struct {
+	hits, misses, backendReads uint64
+	backendReadTime            int64
+}
+
+// ReadStats returns the stats of a cache store. If the store isn't one store.ErrNotSupported
+// is returned.
+func ReadStats(s store.Store) (Stats, error) {
+	c, ok := s.(*cache)
+	if !ok {
+		return Stats{}, store.ErrNotSupported
+	}
+	return c.Stats(), nil
+}
+
+// Stats returns the stats of the cache since it was created
+func (c *cache) Stats() Stats {
+	c.lru.Lock()
+	entries := c.lru.list.Len()
+	c.lru.Unlock()
+
+	return Stats{
+		Hits:            atomic.LoadUint64(&c.counters.hits),
+		Misses:          atomic.LoadUint64(&c.counters.misses),
+		Entries:         entries,
+		BackendReads:    atomic.LoadUint64(&c.counters.backendReads),
+		BackendReadTime: time.Duration(atomic.LoadInt64(&c.counters.backendReadTime)),
+	}
+}
+
+// readBackend reads from the backing store, recording how long it took
+func (c *cache) readBackend(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	start := time.Now()
+	recs, err := c.b.Read(key, opts...)
+	atomic.AddUint64(&c.counters.backendReads, 1)
+	atomic.AddInt64(&c.counters.backendReadTime, int64(time.Since(start)))
+	return recs, err
+}
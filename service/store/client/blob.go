@@ -1,10 +1,10 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"io"
 	"net/http"
+	"time"
 
 	pb "github.com/micro/micro/v3/proto/store"
 	"github.com/micro/micro/v3/service/client"
@@ -12,7 +12,8 @@ import (
 	"github.com/micro/micro/v3/service/store"
 )
 
-const bufferSize = 1024
+// bufferSize is the size of the chunks blobs are streamed in
+const bufferSize = 64 * 1024
 
 // NewBlobStore returns a new store service implementation
 func NewBlobStore() store.BlobStore {
@@ -52,23 +53,44 @@ func (b *blob) Read(key string, opts ...store.BlobOption) (io.Reader, error) {
 		return nil, err
 	}
 
-	// create a buffer to store the bytes in
-	buf := bytes.NewBuffer(nil)
+	// errors are returned with the first message
+	res, err := stream.Recv()
+	if err == io.EOF {
+		stream.Close()
+		return &blobReader{stream: stream}, nil
+	} else if verr := errors.FromError(err); verr != nil && verr.Code == http.StatusNotFound {
+		stream.Close()
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		stream.Close()
+		return nil, err
+	}
 
-	// keep recieving bytes from the stream until it's closed by the server
-	for {
-		res, err := stream.Recv()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, err
-		}
+	// the rest of the blob is received as it's read
+	return &blobReader{stream: stream, buf: res.Blob}, nil
+}
+
+// blobReader reads a blob from the stream as it's received from the server
+type blobReader struct {
+	stream pb.BlobStore_ReadService
+	buf    []byte
+}
 
-		buf.Write(res.Blob)
+func (r *blobReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		res, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = res.Blob
 	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
 
-	// return the bytes
-	return buf, nil
+func (r *blobReader) Close() error {
+	return r.stream.Close()
 }
 
 func (b *blob) Write(key string, blob io.Reader, opts ...store.BlobOption) error {
@@ -98,25 +120,27 @@ func (b *blob) Write(key string, blob io.Reader, opts ...store.BlobOption) error
 	// read from the blob and stream it to the server
 	buffer := make([]byte, bufferSize)
 	for {
-		num, err := blob.Read(buffer)
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return err
+		num, rerr := blob.Read(buffer)
+		if num > 0 {
+			req := &pb.BlobWriteRequest{
+				Key: key,
+				Options: &pb.BlobOptions{
+					Namespace:   options.Namespace,
+					Public:      options.Public,
+					ContentType: options.ContentType,
+					Metadata:    options.Metadata,
+				},
+				Blob: buffer[:num],
+			}
+			if err := stream.Send(req); err != nil {
+				return err
+			}
 		}
 
-		req := &pb.BlobWriteRequest{
-			Key: key,
-			Options: &pb.BlobOptions{
-				Namespace:   options.Namespace,
-				Public:      options.Public,
-				ContentType: options.ContentType,
-			},
-			Blob: buffer[:num],
-		}
-
-		if err := stream.Send(req); err != nil {
-			return err
+		if rerr == io.EOF {
+			break
+		} else if rerr != nil {
+			return rerr
 		}
 	}
 
@@ -189,3 +213,85 @@ func (b *blob) List(opts ...store.BlobListOption) ([]string, error) {
 
 	return rsp.Keys, nil
 }
+
+func (b *blob) ListInfo(opts ...store.BlobListOption) ([]*store.BlobInfo, error) {
+	// parse the options
+	var options store.BlobListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// execute the rpc
+	rsp, err := b.cli().ListInfo(context.TODO(), &pb.BlobListRequest{
+		Options: &pb.BlobListOptions{
+			Namespace: options.Namespace,
+			Prefix:    options.Prefix,
+		},
+	}, client.WithAuthToken())
+
+	// handle the error
+	if verr := errors.FromError(err); verr != nil && verr.Code == http.StatusNotImplemented {
+		return nil, store.ErrNotSupported
+	} else if verr != nil {
+		return nil, verr
+	} else if err != nil {
+		return nil, err
+	}
+
+	blobs := make([]*store.BlobInfo, len(rsp.Blobs))
+	for i, b := range rsp.Blobs {
+		blobs[i] = &store.BlobInfo{
+			Key:         b.Key,
+			Size:        b.Size,
+			ContentType: b.ContentType,
+			Metadata:    b.Metadata,
+		}
+		if b.Modified > 0 {
+			blobs[i].Modified = time.Unix(b.Modified, 0)
+		}
+	}
+	return blobs, nil
+}
+
+func (b *blob) PresignGet(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return b.presign(http.MethodGet, key, ttl, opts...)
+}
+
+func (b *blob) PresignPut(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return b.presign(http.MethodPut, key, ttl, opts...)
+}
+
+// presign asks the server to sign a url for the method, which it does if its blob store supports it
+func (b *blob) presign(method, key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	// validate the key
+	if len(key) == 0 {
+		return "", store.ErrMissingKey
+	}
+
+	// parse the options
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// execute the rpc
+	rsp, err := b.cli().Presign(context.TODO(), &pb.BlobPresignRequest{
+		Key: key,
+		Options: &pb.BlobOptions{
+			Namespace: options.Namespace,
+		},
+		Method: method,
+		Ttl:    int64(ttl.Seconds()),
+	}, client.WithAuthToken())
+
+	// handle the error
+	if verr := errors.FromError(err); verr != nil && verr.Code == http.StatusNotImplemented {
+		return "", store.ErrNotSupported
+	} else if verr != nil {
+		return "", verr
+	} else if err != nil {
+		return "", err
+	}
+
+	return rsp.Url, nil
+}
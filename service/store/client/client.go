@@ -4,6 +4,7 @@ import (
 	goctx "context"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"time"
 
@@ -71,13 +72,23 @@ func (s *srv) List(opts ...store.ListOption) ([]string, error) {
 	}
 
 	listOpts := &pb.ListOptions{
-		Database: options.Database,
-		Table:    options.Table,
-		Prefix:   options.Prefix,
-		Suffix:   options.Suffix,
-		Limit:    uint64(options.Limit),
-		Offset:   uint64(options.Offset),
-		Order:    string(options.Order),
+		Database:  options.Database,
+		Table:     options.Table,
+		Prefix:    options.Prefix,
+		Suffix:    options.Suffix,
+		Limit:     uint64(options.Limit),
+		Offset:    uint64(options.Offset),
+		Order:     string(options.Order),
+		OrderBy:   string(options.OrderBy),
+		Delimiter: options.Delimiter,
+	}
+	if options.Match != nil {
+		listOpts.Match = options.Match.Expr
+		listOpts.MatchRegexp = options.Match.Regexp
+	}
+	if options.Cursor != nil {
+		listOpts.Paginate = true
+		listOpts.Cursor = *options.Cursor
 	}
 
 	stream, err := s.Client.List(s.Context(), &pb.ListRequest{Options: listOpts}, client.WithAddress(s.Nodes...), client.WithAuthToken())
@@ -89,6 +100,7 @@ func (s *srv) List(opts ...store.ListOption) ([]string, error) {
 	defer stream.Close()
 
 	var keys []string
+	var cursor string
 
 	for {
 		rsp, err := stream.Recv()
@@ -102,6 +114,13 @@ func (s *srv) List(opts ...store.ListOption) ([]string, error) {
 		for _, key := range rsp.Keys {
 			keys = append(keys, key)
 		}
+		if len(rsp.Cursor) > 0 {
+			cursor = rsp.Cursor
+		}
+	}
+
+	if options.Cursor != nil {
+		*options.Cursor = cursor
 	}
 
 	return keys, nil
@@ -118,15 +137,11 @@ func (s *srv) Read(key string, opts ...store.ReadOption) ([]*store.Record, error
 		o(&options)
 	}
 
-	readOpts := &pb.ReadOptions{
-		Database: options.Database,
-		Table:    options.Table,
-		Prefix:   options.Prefix,
-		Suffix:   options.Suffix,
-		Limit:    uint64(options.Limit),
-		Offset:   uint64(options.Offset),
-		Order:    string(options.Order),
+	// the service doesn't keep earlier revisions of records
+	if options.Version > 0 {
+		return nil, store.ErrNotSupported
 	}
+	readOpts := readOptions(options)
 
 	rsp, err := s.Client.Read(s.Context(), &pb.ReadRequest{
 		Key:     key,
@@ -138,32 +153,147 @@ func (s *srv) Read(key string, opts ...store.ReadOption) ([]*store.Record, error
 		return nil, err
 	}
 
+	if options.Cursor != nil {
+		*options.Cursor = rsp.Cursor
+	}
+
 	records := make([]*store.Record, 0, len(rsp.Records))
 
 	for _, val := range rsp.Records {
-		metadata := make(map[string]interface{})
+		records = append(records, deserializeRecord(val))
+	}
 
-		for k, v := range val.Metadata {
-			switch v.Type {
-			// TODO: parse all types
-			default:
-				metadata[k] = v
-			}
+	return records, nil
+}
+
+// Iterate streams the records of a read from the store service, which sends them in batches
+func (s *srv) Iterate(ctx goctx.Context, key string, opts ...store.ReadOption) (store.Iterator, error) {
+	options := store.ReadOptions{
+		Database: s.Database,
+		Table:    s.Table,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	// pass on the database and table metadata
+	md, _ := metadata.FromContext(s.Context())
+	ctx = metadata.MergeContext(ctx, md, false)
+
+	stream, err := s.Client.ReadStream(ctx, &pb.ReadRequest{
+		Key:     key,
+		Options: readOptions(options),
+	}, client.WithAddress(s.Nodes...), client.WithAuthToken())
+	if err != nil && errors.Equal(err, errors.NotFound("", "")) {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &iterator{stream: stream, cursor: options.Cursor}, nil
+}
+
+// readOptions returns the options of the read request
+func readOptions(options store.ReadOptions) *pb.ReadOptions {
+	readOpts := &pb.ReadOptions{
+		Database: options.Database,
+		Table:    options.Table,
+		Prefix:   options.Prefix,
+		Suffix:   options.Suffix,
+		Limit:    uint64(options.Limit),
+		Offset:   uint64(options.Offset),
+		Order:    string(options.Order),
+		OrderBy:  string(options.OrderBy),
+	}
+
+	if len(options.Where) > 0 {
+		readOpts.Where = make(map[string]string, len(options.Where))
+		for k, v := range options.Where {
+			readOpts.Where[k] = fmt.Sprint(v)
 		}
+	}
+	if options.Match != nil {
+		readOpts.Match = options.Match.Expr
+		readOpts.MatchRegexp = options.Match.Regexp
+	}
+	if options.Cursor != nil {
+		readOpts.Paginate = true
+		readOpts.Cursor = *options.Cursor
+	}
+	return readOpts
+}
 
-		records = append(records, &store.Record{
-			Key:      val.Key,
-			Value:    val.Value,
-			Expiry:   time.Duration(val.Expiry) * time.Second,
-			Metadata: metadata,
-		})
+func deserializeRecord(val *pb.Record) *store.Record {
+	metadata := make(map[string]interface{})
+
+	for k, v := range val.Metadata {
+		switch v.Type {
+		// TODO: parse all types
+		default:
+			metadata[k] = v
+		}
 	}
 
-	return records, nil
+	return &store.Record{
+		Key:      val.Key,
+		Value:    val.Value,
+		Expiry:   time.Duration(val.Expiry) * time.Second,
+		Metadata: metadata,
+		Version:  val.Version,
+	}
+}
+
+// iterator returns the records of a streamed read, receiving the next batch once the
+// records of the last have been returned
+type iterator struct {
+	stream pb.Store_ReadStreamService
+	// records of the batch not yet returned
+	records []*pb.Record
+	// set to the cursor of the last batch if the read used a cursor
+	cursor *string
+	err    error
+}
+
+func (i *iterator) Next() (*store.Record, error) {
+	for len(i.records) == 0 {
+		if i.err != nil {
+			return nil, i.err
+		}
+
+		rsp, err := i.stream.Recv()
+		if err == io.EOF {
+			i.err = io.EOF
+		} else if err != nil && errors.Equal(err, errors.NotFound("", "")) {
+			i.err = store.ErrNotFound
+		} else if err != nil {
+			i.err = err
+		} else {
+			i.records = rsp.Records
+			if i.cursor != nil {
+				*i.cursor = rsp.Cursor
+			}
+		}
+	}
+
+	r := i.records[0]
+	i.records = i.records[1:]
+	return deserializeRecord(r), nil
+}
+
+func (i *iterator) Close() error {
+	return i.stream.Close()
 }
 
 // Write a record
 func (s *srv) Write(record *store.Record, opts ...store.WriteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
 	options := store.WriteOptions{
 		Database: s.Database,
 		Table:    s.Table,
@@ -174,8 +304,10 @@ func (s *srv) Write(record *store.Record, opts ...store.WriteOption) error {
 	}
 
 	writeOpts := &pb.WriteOptions{
-		Database: options.Database,
-		Table:    options.Table,
+		Database:    options.Database,
+		Table:       options.Table,
+		IfVersion:   options.IfVersion,
+		IfNotExists: options.IfNotExists,
 	}
 
 	metadata := make(map[string]*pb.Field)
@@ -187,7 +319,7 @@ func (s *srv) Write(record *store.Record, opts ...store.WriteOption) error {
 		}
 	}
 
-	_, err := s.Client.Write(s.Context(), &pb.WriteRequest{
+	rsp, err := s.Client.Write(s.Context(), &pb.WriteRequest{
 		Record: &pb.Record{
 			Key:      record.Key,
 			Value:    record.Value,
@@ -197,13 +329,26 @@ func (s *srv) Write(record *store.Record, opts ...store.WriteOption) error {
 		Options: writeOpts}, client.WithAddress(s.Nodes...), client.WithAuthToken())
 	if err != nil && errors.Equal(err, errors.NotFound("", "")) {
 		return store.ErrNotFound
+	} else if err != nil && errors.Equal(err, errors.Conflict("", "")) {
+		return store.ErrConflict
+	} else if err != nil && errors.Equal(err, errors.NotImplemented("", "")) {
+		return store.ErrNotSupported
+	} else if err != nil && errors.FromError(err).Detail == store.ErrQuotaExceeded.Error() {
+		return store.ErrQuotaExceeded
+	} else if err != nil {
+		return err
 	}
 
-	return err
+	record.Version = rsp.Version
+	return nil
 }
 
 // Delete a record with key
 func (s *srv) Delete(key string, opts ...store.DeleteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
 	options := store.DeleteOptions{
 		Database: s.Database,
 		Table:    s.Table,
@@ -216,6 +361,8 @@ func (s *srv) Delete(key string, opts ...store.DeleteOption) error {
 	deleteOpts := &pb.DeleteOptions{
 		Database: options.Database,
 		Table:    options.Table,
+		Prefix:   options.Prefix,
+		Suffix:   options.Suffix,
 	}
 
 	_, err := s.Client.Delete(s.Context(), &pb.DeleteRequest{
@@ -229,6 +376,190 @@ func (s *srv) Delete(key string, opts ...store.DeleteOption) error {
 	return err
 }
 
+// DeleteMany deletes the records with keys in a single request
+func (s *srv) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	options := store.DeleteOptions{
+		Database: s.Database,
+		Table:    s.Table,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	deleteOpts := &pb.DeleteOptions{
+		Database: options.Database,
+		Table:    options.Table,
+		Prefix:   options.Prefix,
+		Suffix:   options.Suffix,
+	}
+
+	_, err := s.Client.Delete(s.Context(), &pb.DeleteRequest{
+		Keys:    keys,
+		Options: deleteOpts,
+	}, client.WithAddress(s.Nodes...), client.WithAuthToken())
+	if err != nil && errors.Equal(err, errors.NotFound("", "")) {
+		return store.ErrNotFound
+	}
+
+	return err
+}
+
+// Touch sets the expiry of the record, which is rounded up to whole seconds
+func (s *srv) Touch(ctx goctx.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	options := store.TouchOptions{
+		Database: s.Database,
+		Table:    s.Table,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	md, _ := metadata.FromContext(s.Context())
+	ctx = metadata.MergeContext(ctx, md, false)
+
+	_, err := s.Client.Touch(ctx, &pb.TouchRequest{
+		Key:    key,
+		Expiry: int64(math.Ceil(expiry.Seconds())),
+		Options: &pb.TouchOptions{
+			Database: options.Database,
+			Table:    options.Table,
+		},
+	}, client.WithAddress(s.Nodes...), client.WithAuthToken())
+	if err != nil && errors.Equal(err, errors.NotFound("", "")) {
+		return store.ErrNotFound
+	} else if err != nil && errors.Equal(err, errors.NotImplemented("", "")) {
+		return store.ErrNotSupported
+	}
+
+	return err
+}
+
+// Compact rewrites the files of the table in the store service
+func (s *srv) Compact(ctx goctx.Context, opts ...store.CompactOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	options := store.CompactOptions{
+		Database: s.Database,
+		Table:    s.Table,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	md, _ := metadata.FromContext(s.Context())
+	ctx = metadata.MergeContext(ctx, md, false)
+
+	_, err := s.Client.Compact(ctx, &pb.CompactRequest{
+		Options: &pb.CompactOptions{
+			Database: options.Database,
+			Table:    options.Table,
+		},
+	}, client.WithAddress(s.Nodes...), client.WithAuthToken())
+	if err != nil && errors.Equal(err, errors.NotImplemented("", "")) {
+		return store.ErrNotSupported
+	}
+
+	return err
+}
+
+// Watch streams the changes to records from the store service until ctx is done
+func (s *srv) Watch(ctx goctx.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	options := store.WatchOptions{
+		Database: s.Database,
+		Table:    s.Table,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	watchOpts := &pb.WatchOptions{
+		Database: options.Database,
+		Table:    options.Table,
+		Prefix:   options.Prefix,
+	}
+
+	// pass on the database and table metadata
+	md, _ := metadata.FromContext(s.Context())
+	ctx = metadata.MergeContext(ctx, md, false)
+
+	stream, err := s.Client.Watch(ctx, &pb.WatchRequest{
+		Key:     key,
+		Options: watchOpts,
+	}, client.WithAddress(s.Nodes...), client.WithAuthToken())
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *store.Event)
+	done := make(chan bool)
+
+	// close the stream once ctx is done to unblock Recv
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		stream.Close()
+	}()
+
+	go func() {
+		defer close(events)
+		defer close(done)
+
+		for {
+			rsp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			metadata := make(map[string]interface{})
+			for k, v := range rsp.Record.GetMetadata() {
+				metadata[k] = v
+			}
+
+			ev := &store.Event{
+				Type:     store.EventType(rsp.Type),
+				Database: rsp.Database,
+				Table:    rsp.Table,
+				Record: &store.Record{
+					Key:      rsp.Record.GetKey(),
+					Value:    rsp.Record.GetValue(),
+					Expiry:   time.Duration(rsp.Record.GetExpiry()) * time.Second,
+					Metadata: metadata,
+					Version:  rsp.Record.GetVersion(),
+				},
+				Timestamp: time.Unix(rsp.Timestamp, 0),
+			}
+
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
 func (s *srv) String() string {
 	return "service"
 }
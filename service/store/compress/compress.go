@@ -0,0 +1,395 @@
+// Package compress is a store which compresses the values of records before writing them to
+// another store. A header byte is prepended to each compressed value so it's decompressed
+// when read, whatever the algorithm it was written with.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/micro/micro/v3/service/store"
+)
+
+// The headers of values written by the store. The bytes are never valid in UTF-8 so
+// they can't start text or JSON, which lets values written to the backing store
+// before it was wrapped be read as they are.
+const (
+	// the value isn't compressed but starts with a header byte so one is added
+	headerNone byte = 0xf8 + iota
+	headerSnappy
+	headerGzip
+	headerZstd
+)
+
+var (
+	// zstd encoders and decoders are safe for concurrent use and expensive to create
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+type compressStore struct {
+	b       store.Store // the backing store
+	options store.Options
+}
+
+// NewStore returns a store which compresses the values written to the backing store
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	c := &compressStore{b: s}
+	c.init(opts...)
+	return c
+}
+
+func (c *compressStore) init(opts ...store.Option) {
+	for _, o := range opts {
+		o(&c.options)
+	}
+}
+
+// Init initialises the backing store
+func (c *compressStore) Init(opts ...store.Option) error {
+	c.init(opts...)
+	return c.b.Init(opts...)
+}
+
+// Options allows you to view the current options.
+func (c *compressStore) Options() store.Options {
+	return c.options
+}
+
+// compression returns the algorithm to write the value with
+func (c *compressStore) compression(opts []store.WriteOption) store.Compression {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Compress) > 0 || c.options.Context == nil {
+		return options.Compress
+	}
+	def, _ := c.options.Context.Value(defaultKey{}).(store.Compression)
+	return def
+}
+
+func zstdInit() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+// encode compresses the value with the algorithm and prepends its header. The value is
+// kept as it is if compressing doesn't make it smaller.
+func encode(v []byte, c store.Compression) ([]byte, error) {
+	var header byte
+	var b []byte
+
+	switch c {
+	case "":
+	case store.CompressSnappy:
+		header, b = headerSnappy, snappy.Encode(nil, v)
+	case store.CompressGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(v); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		header, b = headerGzip, buf.Bytes()
+	case store.CompressZstd:
+		if err := zstdInit(); err != nil {
+			return nil, err
+		}
+		header, b = headerZstd, zstdEncoder.EncodeAll(v, nil)
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", c)
+	}
+
+	if b != nil && len(b)+1 < len(v) {
+		return append([]byte{header}, b...), nil
+	}
+	if len(v) > 0 && v[0] >= headerNone && v[0] <= headerZstd {
+		return append([]byte{headerNone}, v...), nil
+	}
+	return v, nil
+}
+
+// decode returns the value a record was written with
+func decode(v []byte) ([]byte, error) {
+	if len(v) == 0 {
+		return v, nil
+	}
+
+	switch v[0] {
+	case headerNone:
+		return v[1:], nil
+	case headerSnappy:
+		return snappy.Decode(nil, v[1:])
+	case headerGzip:
+		r, err := gzip.NewReader(bytes.NewReader(v[1:]))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case headerZstd:
+		if err := zstdInit(); err != nil {
+			return nil, err
+		}
+		return zstdDecoder.DecodeAll(v[1:], nil)
+	}
+
+	// written without compression
+	return v, nil
+}
+
+// compress returns a copy of the record with its value compressed
+func compress(r *store.Record, c store.Compression) (*store.Record, error) {
+	v, err := encode(r.Value, c)
+	if err != nil {
+		return nil, err
+	}
+	rec := *r
+	rec.Value = v
+	return &rec, nil
+}
+
+// decompress the values of the records in place
+func decompress(recs ...*store.Record) error {
+	for _, r := range recs {
+		v, err := decode(r.Value)
+		if err != nil {
+			return fmt.Errorf("couldn't decompress record %s: %w", r.Key, err)
+		}
+		r.Value = v
+	}
+	return nil
+}
+
+// Read reads the records from the backing store and decompresses their values
+func (c *compressStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	recs, err := c.b.Read(key, opts...)
+	if err != nil {
+		return recs, err
+	}
+	if err := decompress(recs...); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// Write compresses the value of the record and writes it to the backing store
+func (c *compressStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	rec, err := compress(r, c.compression(opts))
+	if err != nil {
+		return err
+	}
+	if err := c.b.Write(rec, opts...); err != nil {
+		return err
+	}
+	r.Version = rec.Version
+	return nil
+}
+
+// WriteMany compresses the values of the records and writes them to the backing store
+func (c *compressStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	comp := c.compression(opts)
+	out := make([]*store.Record, len(recs))
+	for i, r := range recs {
+		rec, err := compress(r, comp)
+		if err != nil {
+			return err
+		}
+		out[i] = rec
+	}
+
+	if bw, ok := c.b.(store.BatchWriter); ok {
+		if err := bw.WriteMany(out, opts...); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range out {
+			if err := c.b.Write(r, opts...); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, r := range out {
+		recs[i].Version = r.Version
+	}
+	return nil
+}
+
+// Delete removes the record from the backing store
+func (c *compressStore) Delete(key string, opts ...store.DeleteOption) error {
+	return c.b.Delete(key, opts...)
+}
+
+// DeleteMany removes the records from the backing store
+func (c *compressStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if bd, ok := c.b.(store.BatchDeleter); ok {
+		return bd.DeleteMany(keys, opts...)
+	}
+	for _, k := range keys {
+		if err := c.b.Delete(k, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the keys from the backing store
+func (c *compressStore) List(opts ...store.ListOption) ([]string, error) {
+	return c.b.List(opts...)
+}
+
+// Txn runs fn in a transaction on the backing store, the records read and written through
+// it are decompressed and compressed. If the backing store doesn't support transactions
+// store.ErrNotSupported is returned.
+func (c *compressStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	t, ok := c.b.(store.Transactional)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	comp := c.compression(nil)
+	return t.Txn(ctx, func(tx store.Tx) error {
+		return fn(&compressTx{Tx: tx, compression: comp})
+	}, opts...)
+}
+
+// compressTx compresses the records written in a transaction
+type compressTx struct {
+	store.Tx
+	compression store.Compression
+}
+
+func (t *compressTx) Read(key string) (*store.Record, error) {
+	r, err := t.Tx.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := decompress(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (t *compressTx) Write(r *store.Record) error {
+	rec, err := compress(r, t.compression)
+	if err != nil {
+		return err
+	}
+	return t.Tx.Write(rec)
+}
+
+// Watch the backing store for changes, the values of the records are decompressed. If it
+// doesn't support watches store.ErrNotSupported is returned.
+func (c *compressStore) Watch(ctx context.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	w, ok := c.b.(store.Watcher)
+	if !ok {
+		return nil, store.ErrNotSupported
+	}
+	events, err := w.Watch(ctx, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *store.Event)
+	go func() {
+		defer close(ch)
+		for ev := range events {
+			if ev.Record != nil {
+				// events can't carry errors so values which can't be read are sent as they are
+				rec := *ev.Record
+				if v, err := decode(rec.Value); err == nil {
+					rec.Value = v
+				}
+				e := *ev
+				e.Record = &rec
+				ev = &e
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				// drain the backing channel until it's closed
+				for range events {
+				}
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// ListVersions returns the revisions of the key from the backing store with their values
+// decompressed. If it doesn't keep them store.ErrNotSupported is returned.
+func (c *compressStore) ListVersions(key string, opts ...store.ListOption) ([]*store.Revision, error) {
+	v, ok := c.b.(store.Versioner)
+	if !ok {
+		return nil, store.ErrNotSupported
+	}
+	revs, err := v.ListVersions(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range revs {
+		if r.Record == nil {
+			continue
+		}
+		if err := decompress(r.Record); err != nil {
+			return nil, err
+		}
+	}
+	return revs, nil
+}
+
+// Touch sets the expiry of the record in the backing store. If it doesn't support it
+// store.ErrNotSupported is returned.
+func (c *compressStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	t, ok := c.b.(store.Toucher)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	return t.Touch(ctx, key, expiry, opts...)
+}
+
+// Compact rewrites the files of the backing store. If it doesn't support it
+// store.ErrNotSupported is returned.
+func (c *compressStore) Compact(ctx context.Context, opts ...store.CompactOption) error {
+	cp, ok := c.b.(store.Compactor)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	return cp.Compact(ctx, opts...)
+}
+
+// Healthy checks the backing store if it supports it
+func (c *compressStore) Healthy(ctx context.Context) error {
+	if h, ok := c.b.(store.HealthChecker); ok {
+		return h.Healthy(ctx)
+	}
+	return nil
+}
+
+// Close the backing store
+func (c *compressStore) Close() error {
+	return c.b.Close()
+}
+
+// String returns the name of the implementation.
+func (c *compressStore) String() string {
+	return "compress"
+}
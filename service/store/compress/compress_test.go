@@ -0,0 +1,74 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompress(t *testing.T) {
+	m := memory.NewStore()
+	s := NewStore(m)
+	value := bytes.Repeat([]byte(`{"foo":"bar"}`), 100)
+
+	for _, c := range []store.Compression{store.CompressSnappy, store.CompressGzip, store.CompressZstd} {
+		key := string(c)
+		assert.NoError(t, s.Write(&store.Record{Key: key, Value: value}, store.WriteCompress(c)))
+
+		// the backing store has the compressed value
+		recs, err := m.Read(key)
+		assert.NoError(t, err)
+		assert.Less(t, len(recs[0].Value), len(value), "Expected %s to compress the value", c)
+
+		recs, err = s.Read(key)
+		assert.NoError(t, err)
+		assert.Equal(t, value, recs[0].Value)
+	}
+
+	assert.Error(t, s.Write(&store.Record{Key: "bad", Value: value}, store.WriteCompress("bad")))
+}
+
+func TestDefault(t *testing.T) {
+	m := memory.NewStore()
+	s := NewStore(m, Default(store.CompressGzip))
+	value := bytes.Repeat([]byte("foo"), 100)
+
+	assert.NoError(t, s.Write(&store.Record{Key: "foo", Value: value}))
+	recs, err := m.Read("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, headerGzip, recs[0].Value[0])
+
+	recs, err = s.Read("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, value, recs[0].Value)
+}
+
+func TestHeaders(t *testing.T) {
+	m := memory.NewStore()
+	s := NewStore(m, Default(store.CompressSnappy))
+
+	// values which don't compress are written as they are
+	assert.NoError(t, s.Write(&store.Record{Key: "small", Value: []byte("foo")}))
+	recs, err := m.Read("small")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), recs[0].Value)
+
+	// unless they start with a header
+	binary := []byte{headerZstd, 1, 2}
+	assert.NoError(t, s.Write(&store.Record{Key: "binary", Value: binary}))
+	recs, err = m.Read("binary")
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{headerNone}, binary...), recs[0].Value)
+	recs, err = s.Read("binary")
+	assert.NoError(t, err)
+	assert.Equal(t, binary, recs[0].Value)
+
+	// values written to the backing store directly are read as they are
+	assert.NoError(t, m.Write(&store.Record{Key: "plain", Value: []byte(`{"foo":"bar"}`)}))
+	recs, err = s.Read("plain")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"foo":"bar"}`), recs[0].Value)
+}
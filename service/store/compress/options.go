@@ -0,0 +1,21 @@
+package compress
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type defaultKey struct{}
+
+// Default compresses the values of all the records written to the store with the algorithm,
+// unless the write sets another with store.WriteCompress
+func Default(c store.Compression) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.WithValue(context.Background(), defaultKey{}, c)
+		} else {
+			o.Context = context.WithValue(o.Context, defaultKey{}, c)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Operation is an operation made on the backing store of a Decorator
+type Operation struct {
+	// Method of the store, e.g. WriteMany
+	Method string
+	// Database and Table of the options of the operation, empty for those of the backing store
+	Database string
+	Table    string
+	// Key of the operation, if it has one
+	Key string
+	// Records written by the operation
+	Records []*Record
+}
+
+// Decorator is a store making its operations on a backing store, for wrappers which trace or
+// measure them. Wrappers embed it and override Read, Write, Delete and List. The optional
+// interfaces of the backing store are forwarded to it, with the batch operations made a record
+// at a time if it doesn't batch them, and ErrNotSupported returned for the others it lacks.
+type Decorator struct {
+	// Store is the backing store
+	Store
+	// Context is what the optional operations which don't take a context are made with,
+	// context.Background if nil
+	Context context.Context
+	// Around is called when an optional operation is made on the backing store, the func
+	// returned is called with its result
+	Around func(ctx context.Context, op Operation) (context.Context, func(error))
+}
+
+// context of the operations which don't take one
+func (d *Decorator) context() context.Context {
+	if d.Context == nil {
+		return context.Background()
+	}
+	return d.Context
+}
+
+func (d *Decorator) around(ctx context.Context, op Operation) (context.Context, func(error)) {
+	if d.Around == nil {
+		return ctx, func(error) {}
+	}
+	return d.Around(ctx, op)
+}
+
+// WriteMany writes the records to the backing store, in a single operation if it supports it
+func (d *Decorator) WriteMany(recs []*Record, opts ...WriteOption) error {
+	var options WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := d.around(d.context(), Operation{Method: "WriteMany", Database: options.Database, Table: options.Table, Records: recs})
+	var err error
+	if bw, ok := d.Store.(BatchWriter); ok {
+		err = bw.WriteMany(recs, opts...)
+	} else {
+		for _, r := range recs {
+			if err = d.Store.Write(r, opts...); err != nil {
+				break
+			}
+		}
+	}
+	end(err)
+	return err
+}
+
+// DeleteMany removes the records from the backing store, in a single operation if it
+// supports it
+func (d *Decorator) DeleteMany(keys []string, opts ...DeleteOption) error {
+	var options DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := d.around(d.context(), Operation{Method: "DeleteMany", Database: options.Database, Table: options.Table})
+	var err error
+	if bd, ok := d.Store.(BatchDeleter); ok {
+		err = bd.DeleteMany(keys, opts...)
+	} else {
+		for _, k := range keys {
+			if err = d.Store.Delete(k, opts...); err != nil {
+				break
+			}
+		}
+	}
+	end(err)
+	return err
+}
+
+// Iterate returns an iterator over the records of the read from the backing store. If it
+// doesn't support iterating the records are read at once.
+func (d *Decorator) Iterate(ctx context.Context, key string, opts ...ReadOption) (Iterator, error) {
+	var options ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	newCtx, end := d.around(ctx, Operation{Method: "Iterate", Database: options.Database, Table: options.Table, Key: key})
+	if i, ok := d.Store.(Iterable); ok {
+		it, err := i.Iterate(newCtx, key, opts...)
+		end(err)
+		return it, err
+	}
+	recs, err := d.Store.Read(key, opts...)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator(recs), nil
+}
+
+// Txn runs fn in a transaction on the backing store
+func (d *Decorator) Txn(ctx context.Context, fn func(tx Tx) error, opts ...TxnOption) error {
+	tr, ok := d.Store.(Transactional)
+	if !ok {
+		return ErrNotSupported
+	}
+	var options TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	newCtx, end := d.around(ctx, Operation{Method: "Txn", Database: options.Database, Table: options.Table})
+	err := tr.Txn(newCtx, fn, opts...)
+	end(err)
+	return err
+}
+
+// Watch the backing store for changes
+func (d *Decorator) Watch(ctx context.Context, key string, opts ...WatchOption) (<-chan *Event, error) {
+	w, ok := d.Store.(Watcher)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return w.Watch(ctx, key, opts...)
+}
+
+// Touch sets the expiry of the record in the backing store
+func (d *Decorator) Touch(ctx context.Context, key string, expiry time.Duration, opts ...TouchOption) error {
+	to, ok := d.Store.(Toucher)
+	if !ok {
+		return ErrNotSupported
+	}
+	var options TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	newCtx, end := d.around(ctx, Operation{Method: "Touch", Database: options.Database, Table: options.Table, Key: key})
+	err := to.Touch(newCtx, key, expiry, opts...)
+	end(err)
+	return err
+}
+
+// Compact rewrites the files of the backing store
+func (d *Decorator) Compact(ctx context.Context, opts ...CompactOption) error {
+	c, ok := d.Store.(Compactor)
+	if !ok {
+		return ErrNotSupported
+	}
+	var options CompactOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	newCtx, end := d.around(ctx, Operation{Method: "Compact", Database: options.Database, Table: options.Table})
+	err := c.Compact(newCtx, opts...)
+	end(err)
+	return err
+}
+
+// ListVersions returns the revisions of the key from the backing store
+func (d *Decorator) ListVersions(key string, opts ...ListOption) ([]*Revision, error) {
+	v, ok := d.Store.(Versioner)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	var options ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, end := d.around(d.context(), Operation{Method: "ListVersions", Database: options.Database, Table: options.Table, Key: key})
+	revs, err := v.ListVersions(key, opts...)
+	end(err)
+	return revs, err
+}
+
+// Healthy checks the backing store if it supports it
+func (d *Decorator) Healthy(ctx context.Context) error {
+	if h, ok := d.Store.(HealthChecker); ok {
+		return h.Healthy(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"github.com/micro/micro/v3/service/events"
+)
+
+// ExpiredTopic is the topic of the events published when records expire, see ExpiryEvents
+const ExpiredTopic = "store.expired"
+
+// Expired is the message of the events published to ExpiredTopic
+type Expired struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Key      string `json:"key"`
+}
+
+// PublishExpired publishes an event for each of the keys to the stream set with ExpiryEvents,
+// if there is one. Stores call it once they've removed the expired records.
+func PublishExpired(o Options, database, table string, keys ...string) error {
+	if o.ExpiryEvents == nil {
+		return nil
+	}
+
+	md := map[string]string{"database": database, "table": table}
+	for _, k := range keys {
+		ev := &Expired{Database: database, Table: table, Key: k}
+		if err := o.ExpiryEvents.Publish(ExpiredTopic, ev, events.WithMetadata(md)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
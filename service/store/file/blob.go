@@ -16,11 +16,11 @@ package file
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/micro/micro/v3/service/store"
@@ -55,6 +55,17 @@ type blobStore struct {
 	dir string
 }
 
+// infoBucket has a bucket for each namespace with the attributes of its blobs, which blobs written
+// before the attributes were kept don't have
+var infoBucket = []byte("\x00info")
+
+// blobInfo is the attributes of a blob kept in the info bucket
+type blobInfo struct {
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Modified    time.Time         `json:"modified"`
+}
+
 func (b *blobStore) db() (*bolt.DB, error) {
 	dbPath := filepath.Join(b.dir, "blob.db")
 	return bolt.Open(dbPath, 0700, &bolt.Options{Timeout: 5 * time.Second})
@@ -148,7 +159,27 @@ func (b *blobStore) Write(key string, blob io.Reader, opts ...store.BlobOption)
 			return err
 		}
 
-		return bucket.Put([]byte(key), value)
+		if err := bucket.Put([]byte(key), value); err != nil {
+			return err
+		}
+
+		// write the attributes of the blob
+		info, err := tx.CreateBucketIfNotExists(infoBucket)
+		if err != nil {
+			return err
+		}
+		if info, err = info.CreateBucketIfNotExists([]byte(options.Namespace)); err != nil {
+			return err
+		}
+		attrs, err := json.Marshal(&blobInfo{
+			ContentType: options.ContentType,
+			Metadata:    options.Metadata,
+			Modified:    time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return info.Put([]byte(key), attrs)
 	})
 }
 
@@ -182,7 +213,15 @@ func (b *blobStore) Delete(key string, opts ...store.BlobOption) error {
 			return nil
 		}
 
-		return bucket.Delete([]byte(key))
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return err
+		}
+		if info := tx.Bucket(infoBucket); info != nil {
+			if info = info.Bucket([]byte(options.Namespace)); info != nil {
+				return info.Delete([]byte(key))
+			}
+		}
+		return nil
 	})
 }
 
@@ -209,19 +248,11 @@ func (b *blobStore) List(opts ...store.BlobListOption) ([]string, error) {
 		if bucket == nil {
 			return store.ErrNotFound
 		}
+		// the keys are sorted so the ones with the prefix follow the first
+		prefix := []byte(options.Prefix)
 		c := bucket.Cursor()
-		for {
-			k, _ := c.Next()
-			if k == nil {
-				break
-			}
-			kcopy := make([]byte, len(k))
-			copy(kcopy, k)
-			kstring := string(kcopy)
-			if len(options.Prefix) == 0 || strings.HasPrefix(kstring, options.Prefix) {
-				keys = append(keys, kstring)
-			}
-
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, string(k))
 		}
 		return nil
 	}
@@ -232,3 +263,59 @@ func (b *blobStore) List(opts ...store.BlobListOption) ([]string, error) {
 	// return the keys
 	return keys, nil
 }
+
+func (b *blobStore) ListInfo(opts ...store.BlobListOption) ([]*store.BlobInfo, error) {
+	var options store.BlobListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = "micro"
+	}
+	// open a connection to the database
+	db, err := b.db()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// execute the transaction
+	blobs := []*store.BlobInfo{}
+	readValue := func(tx *bolt.Tx) error {
+		// check for the namespaces bucket
+		bucket := tx.Bucket([]byte(options.Namespace))
+		if bucket == nil {
+			return nil
+		}
+		var info *bolt.Bucket
+		if i := tx.Bucket(infoBucket); i != nil {
+			info = i.Bucket([]byte(options.Namespace))
+		}
+
+		// the keys are sorted so the ones with the prefix follow the first
+		prefix := []byte(options.Prefix)
+		c := bucket.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			blob := &store.BlobInfo{Key: string(k), Size: int64(len(v))}
+			if info != nil {
+				if attrs := info.Get(k); attrs != nil {
+					var bi blobInfo
+					if err := json.Unmarshal(attrs, &bi); err != nil {
+						return err
+					}
+					blob.ContentType = bi.ContentType
+					blob.Metadata = bi.Metadata
+					blob.Modified = bi.Modified
+				}
+			}
+			blobs = append(blobs, blob)
+		}
+		return nil
+	}
+	if err := db.View(readValue); err != nil {
+		return nil, err
+	}
+
+	// return the blobs
+	return blobs, nil
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/micro/micro/v3/service/store"
 	"github.com/stretchr/testify/assert"
@@ -72,6 +73,42 @@ func TestBlobStore(t *testing.T) {
 		assert.Equal(t, string(bytes), "world", "Value should be world")
 	})
 
+	t.Run("ListPrefix", func(t *testing.T) {
+		err := blob.Write("help", bytes.NewBuffer([]byte("me")))
+		assert.Nilf(t, err, "Error should be nil")
+		defer blob.Delete("help")
+
+		keys, err := blob.List(store.BlobListPrefix("hel"))
+		assert.Nilf(t, err, "Error should be nil")
+		assert.Equal(t, []string{"hello", "help"}, keys, "Keys should be hello and help")
+
+		keys, err = blob.List(store.BlobListPrefix("hello"))
+		assert.Nilf(t, err, "Error should be nil")
+		assert.Equal(t, []string{"hello"}, keys, "Keys should be hello")
+	})
+
+	t.Run("ListInfo", func(t *testing.T) {
+		err := blob.Write("help", bytes.NewBuffer([]byte("me")),
+			store.BlobContentType("text/plain"), store.BlobMetadata(map[string]string{"foo": "bar"}))
+		assert.Nilf(t, err, "Error should be nil")
+		defer blob.Delete("help")
+
+		blobs, err := blob.(store.BlobInfoLister).ListInfo(store.BlobListPrefix("hel"))
+		assert.Nilf(t, err, "Error should be nil")
+		assert.Len(t, blobs, 2, "Blobs should be hello and help")
+		assert.Equal(t, "hello", blobs[0].Key)
+		assert.Equal(t, int64(len("world")), blobs[0].Size)
+		assert.Equal(t, "help", blobs[1].Key)
+		assert.Equal(t, int64(len("me")), blobs[1].Size)
+		assert.Equal(t, "text/plain", blobs[1].ContentType)
+		assert.Equal(t, map[string]string{"foo": "bar"}, blobs[1].Metadata)
+		assert.WithinDuration(t, time.Now(), blobs[1].Modified, time.Minute)
+
+		blobs, err = blob.(store.BlobInfoLister).ListInfo(store.BlobListNamespace("bar"))
+		assert.Nilf(t, err, "Error should be nil")
+		assert.Empty(t, blobs, "Blobs should be empty")
+	})
+
 	t.Run("DeleteIncorrectNamespace", func(t *testing.T) {
 		err := blob.Delete("hello", store.BlobNamespace("bar"))
 		assert.Nil(t, err, "Error should be nil")
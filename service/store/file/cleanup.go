@@ -0,0 +1,124 @@
+package file
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+// cleanupLoop periodically deletes the expired records, which are otherwise only
+// skipped when read so they'd keep using disk space
+func (m *fileStore) cleanupLoop() {
+	interval := DefaultCleanupInterval
+	if ctx := m.options.Context; ctx != nil {
+		if d, ok := ctx.Value(cleanupIntervalKey{}).(time.Duration); ok && d > 0 {
+			interval = d
+		}
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-m.exit:
+			return
+		case <-t.C:
+			if !m.options.ReadOnly {
+				m.deleteExpired()
+			}
+		}
+	}
+}
+
+// deleteExpired deletes the expired records from every table in the directory of the store
+func (m *fileStore) deleteExpired() {
+	paths, err := filepath.Glob(filepath.Join(m.getDir("*"), "*.db"))
+	if err != nil {
+		logger.Errorf("Error finding file store tables: %v", err)
+		return
+	}
+
+	for _, path := range paths {
+		database := filepath.Base(filepath.Dir(path))
+		table := strings.TrimSuffix(filepath.Base(path), ".db")
+
+		n, err := m.expire(database, table)
+		if err != nil {
+			logger.Errorf("Error deleting expired records from %s: %v", key(database, table), err)
+			continue
+		}
+		if n > 0 {
+			logger.Debugf("Deleted %d expired records from %s", n, key(database, table))
+		}
+	}
+}
+
+// expire deletes the expired records from the table, publishing an event for each
+func (m *fileStore) expire(database, table string) (int, error) {
+	db, err := m.getDB(database, table)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	// find the expired records first so the file is only locked for writing if there are any
+	var keys []string
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dataBucket))
+		if b == nil {
+			return nil
+		}
+		now := time.Now()
+		return b.ForEach(func(k, v []byte) error {
+			r := &record{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return err
+			}
+			if !r.ExpiresAt.IsZero() && r.ExpiresAt.Before(now) {
+				keys = append(keys, string(k))
+			}
+			return nil
+		})
+	})
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	var expired []string
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dataBucket))
+		now := time.Now()
+		for _, k := range keys {
+			// the record may have been written again since
+			r, err := loadRecord(b, k)
+			if err != nil {
+				return err
+			}
+			if r == nil || r.ExpiresAt.IsZero() || r.ExpiresAt.After(now) {
+				continue
+			}
+			if err := deleteRecord(b, k); err != nil {
+				return err
+			}
+			expired = append(expired, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, k := range expired {
+		m.publish(database, table, store.EventExpire, &store.Record{Key: k})
+	}
+	if err := store.PublishExpired(m.options, database, table, expired...); err != nil {
+		logger.Errorf("Error publishing expired records of %s: %v", key(database, table), err)
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,105 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestCleanup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cleanup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewStore(WithDir(dir), CleanupInterval(20*time.Millisecond))
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := s.(store.Watcher).Watch(ctx, "", store.WatchPrefix())
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Write(&store.Record{Key: "expires", Value: []byte("foo"), Expiry: 10 * time.Millisecond}))
+	assert.NoError(t, s.Write(&store.Record{Key: "kept", Value: []byte("foo")}))
+
+	var expired *store.Event
+	timeout := time.After(time.Second)
+	for expired == nil {
+		select {
+		case ev := <-events:
+			if ev.Type == store.EventExpire {
+				expired = ev
+			}
+		case <-timeout:
+			t.Fatal("Expected an expire event")
+		}
+	}
+	assert.Equal(t, "expires", expired.Record.Key)
+	assert.Equal(t, DefaultDatabase, expired.Database)
+	assert.Equal(t, DefaultTable, expired.Table)
+
+	// the record is removed from the file
+	db, err := s.(*fileStore).getDB("", "")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dataBucket))
+		assert.Nil(t, b.Get([]byte("expires")))
+		assert.NotNil(t, b.Get([]byte("kept")))
+		return nil
+	})
+}
+
+// testStream records the messages published to it
+type testStream struct {
+	sync.Mutex
+	published map[string][]interface{}
+}
+
+func (s *testStream) Publish(topic string, msg interface{}, opts ...events.PublishOption) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.published == nil {
+		s.published = make(map[string][]interface{})
+	}
+	s.published[topic] = append(s.published[topic], msg)
+	return nil
+}
+
+func (s *testStream) Consume(topic string, opts ...events.ConsumeOption) (<-chan events.Event, error) {
+	return nil, nil
+}
+
+func TestCleanupExpiryEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cleanup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	stream := &testStream{}
+	s := NewStore(WithDir(dir), store.ExpiryEvents(stream))
+	defer s.Close()
+
+	assert.NoError(t, s.Write(&store.Record{Key: "expires", Value: []byte("foo"), Expiry: time.Millisecond}))
+	assert.NoError(t, s.Write(&store.Record{Key: "kept", Value: []byte("foo")}))
+	time.Sleep(5 * time.Millisecond)
+
+	n, err := s.(*fileStore).expire(DefaultDatabase, DefaultTable)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stream.Lock()
+	defer stream.Unlock()
+	assert.Equal(t, []interface{}{&store.Expired{
+		Database: DefaultDatabase,
+		Table:    DefaultTable,
+		Key:      "expires",
+	}}, stream.published[store.ExpiredTopic])
+}
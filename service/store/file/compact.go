@@ -0,0 +1,147 @@
+package file
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+// compactTxSize is the size of the records copied to the new file in each transaction,
+// so large files don't have to be held in memory
+const compactTxSize = 64 << 20
+
+// Compact rewrites the file of the database and table without the free pages left by deleted
+// and expired records, which bolt reuses but never gives back to the file system. The file is
+// locked while it's copied so reads and writes wait for it, and fail if it takes longer than
+// their timeout to open the file.
+func (m *fileStore) Compact(ctx context.Context, opts ...store.CompactOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.CompactOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	db, err := m.getDB(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	path := db.Path()
+	tmpPath := path + ".compact"
+	os.Remove(tmpPath)
+
+	tmp, err := bolt.Open(tmpPath, 0700, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return err
+	}
+	if err := compact(ctx, tmp, db); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// replace the file while it's still locked, getDB opens it again if it was waiting
+	// for the lock on the old one
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// compact copies the buckets of src to dst
+func compact(ctx context.Context, dst, src *bolt.DB) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+	c := &compactor{ctx: ctx, db: dst, tx: tx}
+	defer func() {
+		c.tx.Rollback()
+	}()
+
+	err = src.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return c.copy([][]byte{name}, b)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return c.tx.Commit()
+}
+
+// compactor copies buckets to a new file, committing every compactTxSize bytes
+type compactor struct {
+	ctx  context.Context
+	db   *bolt.DB
+	tx   *bolt.Tx
+	size int
+}
+
+// bucket returns the bucket at the path in the new file, creating it if it doesn't exist
+func (c *compactor) bucket(path [][]byte) (*bolt.Bucket, error) {
+	b, err := c.tx.CreateBucketIfNotExists(path[0])
+	for _, name := range path[1:] {
+		if err != nil {
+			return nil, err
+		}
+		b, err = b.CreateBucketIfNotExists(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the keys are copied in order so the pages can be filled
+	b.FillPercent = 1.0
+	return b, nil
+}
+
+// copy the keys and nested buckets of the bucket at the path
+func (c *compactor) copy(path [][]byte, src *bolt.Bucket) error {
+	dst, err := c.bucket(path)
+	if err != nil {
+		return err
+	}
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		// a nil value is a nested bucket
+		if v == nil {
+			return c.copy(append(path[:len(path):len(path)], k), src.Bucket(k))
+		}
+
+		if c.size > compactTxSize {
+			if err := c.ctx.Err(); err != nil {
+				return err
+			}
+			if err := c.tx.Commit(); err != nil {
+				return err
+			}
+			tx, err := c.db.Begin(true)
+			if err != nil {
+				return err
+			}
+			c.tx, c.size = tx, 0
+		}
+
+		b, err := c.bucket(path)
+		if err != nil {
+			return err
+		}
+		c.size += len(k) + len(v)
+		return b.Put(k, v)
+	})
+}
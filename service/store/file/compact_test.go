@@ -0,0 +1,57 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compact")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewStore(WithDir(dir), store.Index("colour"))
+	defer s.Close()
+	path := filepath.Join(dir, DefaultDatabase, DefaultTable+".db")
+
+	var recs []*store.Record
+	for i := 0; i < 1000; i++ {
+		recs = append(recs, &store.Record{
+			Key:      fmt.Sprintf("key%04d", i),
+			Value:    bytes.Repeat([]byte("v"), 1024),
+			Metadata: map[string]interface{}{"colour": "red"},
+		})
+	}
+	assert.NoError(t, s.(store.BatchWriter).WriteMany(recs))
+	assert.NoError(t, s.Delete("key", store.DeletePrefix()))
+	assert.NoError(t, s.Write(&store.Record{Key: "kept", Value: []byte("foo"), Metadata: map[string]interface{}{"colour": "blue"}}))
+
+	before, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, s.(store.Compactor).Compact(context.Background()))
+	after, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size(), "Expected the file to shrink")
+
+	// the records and indexes are copied
+	got, err := s.Read("kept")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), got[0].Value)
+	got, err = s.Read("", store.ReadPrefix(), store.ReadWhere("colour", "blue"))
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	// and it's still writable
+	assert.NoError(t, s.Write(&store.Record{Key: "new", Value: []byte("bar")}))
+	keys, err := s.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kept", "new"}, keys)
+}
@@ -17,12 +17,18 @@ package file
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/watch"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -34,21 +40,36 @@ var (
 	DefaultTable = "micro"
 	// DefaultDir is the default directory for bbolt files
 	DefaultDir = filepath.Join(os.TempDir(), "micro", "store")
+	// DefaultCleanupInterval is how often expired records are deleted
+	DefaultCleanupInterval = time.Hour
 
 	// bucket used for data storage
 	dataBucket = "data"
+	// bucket holding a bucket for each indexed metadata field, which
+	// maps the values of the field to the keys of the records
+	indexBucket = "index"
 )
 
 // NewStore returns a file store
 func NewStore(opts ...store.Option) store.Store {
-	s := &fileStore{}
+	s := &fileStore{
+		exit: make(chan bool),
+	}
 	s.init(opts...)
+	go s.cleanupLoop()
 	return s
 }
 
 type fileStore struct {
 	options store.Options
 	dir     string
+	// syncs the files every interval if set
+	syncer *syncer
+
+	exit chan bool
+	once sync.Once
+
+	watchers watch.Hub
 }
 
 type fileHandle struct {
@@ -62,19 +83,53 @@ type record struct {
 	Value     []byte
 	Metadata  map[string]interface{}
 	ExpiresAt time.Time
+	Version   uint64
 }
 
 func key(database, table string) string {
 	return database + ":" + table
 }
 
-func (m *fileStore) delete(db *bolt.DB, key string) error {
-	return db.Update(func(tx *bolt.Tx) error {
+func (m *fileStore) delete(db *bolt.DB, database, table string, keys ...string) error {
+	var deleted []string
+
+	err := db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(dataBucket))
 		if b == nil {
 			return nil
 		}
-		return b.Delete([]byte(key))
+		watching := m.watchers.Watching()
+		for _, k := range keys {
+			if watching {
+				if _, err := getRecord(b, k); err == nil {
+					deleted = append(deleted, k)
+				}
+			}
+			if err := deleteRecord(b, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range deleted {
+		m.publish(database, table, store.EventDelete, &store.Record{Key: k})
+	}
+	return nil
+}
+
+// publish an event for a change made to the database and table
+func (m *fileStore) publish(database, table string, typ store.EventType, r *store.Record) {
+	database, table = m.names(database, table)
+	m.watchers.Publish(&store.Event{
+		Type:      typ,
+		Database:  database,
+		Table:     table,
+		Record:    r,
+		Timestamp: time.Now(),
 	})
 }
 
@@ -97,9 +152,36 @@ func (m *fileStore) init(opts ...store.Option) error {
 	// about the dir not existing in case this cannot create the path anyway
 	dir := m.getDir(m.options.Database)
 	os.MkdirAll(dir, 0700)
+
+	var interval time.Duration
+	if m.options.Context != nil {
+		interval, _ = m.options.Context.Value(syncIntervalKey{}).(time.Duration)
+	}
+	if m.syncer != nil && m.syncer.interval != interval {
+		m.syncer.stop()
+		m.syncer = nil
+	}
+	if m.syncer == nil && interval > 0 {
+		m.syncer = newSyncer(interval)
+	}
 	return nil
 }
 
+// boltOptions returns the options to open the files with
+func (m *fileStore) boltOptions() *bolt.Options {
+	opts := &bolt.Options{Timeout: 5 * time.Second}
+	if m.options.Context == nil {
+		return opts
+	}
+	opts.NoSync, _ = m.options.Context.Value(noSyncKey{}).(bool)
+	opts.NoGrowSync, _ = m.options.Context.Value(noGrowSyncKey{}).(bool)
+	// the files are synced periodically instead
+	if m.syncer != nil {
+		opts.NoSync = true
+	}
+	return opts
+}
+
 // getDir returns the directory which should contain the files for a databases
 func (m *fileStore) getDir(db string) string {
 	// get the directory option from the context
@@ -118,13 +200,28 @@ func (m *fileStore) getDir(db string) string {
 	return filepath.Join(directory, db)
 }
 
-func (f *fileStore) getDB(database, table string) (*bolt.DB, error) {
+// names returns the database and table to use, defaulting to those of the store
+func (f *fileStore) names(database, table string) (string, string) {
 	if len(database) == 0 {
 		database = f.options.Database
 	}
 	if len(table) == 0 {
 		table = f.options.Table
 	}
+	return database, table
+}
+
+// Healthy opens the file of the database and table of the store
+func (f *fileStore) Healthy(ctx context.Context) error {
+	db, err := f.getDB(f.options.Database, f.options.Table)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func (f *fileStore) getDB(database, table string) (*bolt.DB, error) {
+	database, table = f.names(database, table)
 
 	// create a directory /tmp/micro
 	dir := f.getDir(database)
@@ -137,10 +234,175 @@ func (f *fileStore) getDB(database, table string) (*bolt.DB, error) {
 
 	// create new db handle
 	// Bolt DB only allows one process to open the file R/W so make sure we're doing this under a lock
-	return bolt.Open(dbPath, 0700, &bolt.Options{Timeout: 5 * time.Second})
+	db, err := openDB(dbPath, f.boltOptions())
+	if err != nil {
+		return nil, err
+	}
+	if f.syncer != nil {
+		f.syncer.add(dbPath)
+	}
+
+	// read only stores filter the records by their metadata instead
+	if f.options.ReadOnly {
+		return db, nil
+	}
+	if err := f.index(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
 }
 
-func (m *fileStore) list(db *bolt.DB, order store.Order, limit, offset uint, prefix, suffix string) []string {
+// openDB opens the file once it's locked. Compacting replaces the file, so it's opened again
+// if that happened while waiting for the lock.
+func openDB(path string, opts *bolt.Options) (*bolt.DB, error) {
+	for {
+		before, statErr := os.Stat(path)
+
+		db, err := bolt.Open(path, 0700, opts)
+		if err != nil {
+			return nil, err
+		}
+		if statErr != nil {
+			return db, nil
+		}
+
+		after, err := os.Stat(path)
+		if err == nil && os.SameFile(before, after) {
+			return db, nil
+		}
+		db.Close()
+	}
+}
+
+// index builds the indexes declared in the options which don't exist in the file yet.
+// Once an index exists it's kept up to date by every write and delete.
+func (f *fileStore) index(db *bolt.DB) error {
+	if len(f.options.Indexes) == 0 {
+		return nil
+	}
+
+	var missing []string
+
+	db.View(func(tx *bolt.Tx) error {
+		idx := tx.Bucket([]byte(indexBucket))
+		for _, field := range f.options.Indexes {
+			if idx == nil || idx.Bucket([]byte(field)) == nil {
+				missing = append(missing, field)
+			}
+		}
+		return nil
+	})
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		idx, err := tx.CreateBucketIfNotExists([]byte(indexBucket))
+		if err != nil {
+			return err
+		}
+
+		b := tx.Bucket([]byte(dataBucket))
+
+		for _, field := range missing {
+			fb, err := idx.CreateBucketIfNotExists([]byte(field))
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				continue
+			}
+
+			// index the existing records
+			err = b.ForEach(func(k, v []byte) error {
+				r := &record{}
+				if err := json.Unmarshal(v, r); err != nil {
+					return err
+				}
+				if val, ok := r.Metadata[field]; ok {
+					return fb.Put(indexKey(val, r.Key), nil)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// indexKey returns the key of the index entry for a record with the value
+func indexKey(value interface{}, key string) []byte {
+	return []byte(fmt.Sprint(value) + "\x00" + key)
+}
+
+// reindex replaces the index entries of the old record with those of the new one,
+// either of which may be nil
+func reindex(tx *bolt.Tx, old, new *record) error {
+	idx := tx.Bucket([]byte(indexBucket))
+	if idx == nil {
+		return nil
+	}
+
+	return idx.ForEach(func(field, _ []byte) error {
+		fb := idx.Bucket(field)
+		if fb == nil {
+			return nil
+		}
+		if old != nil {
+			if val, ok := old.Metadata[string(field)]; ok {
+				if err := fb.Delete(indexKey(val, old.Key)); err != nil {
+					return err
+				}
+			}
+		}
+		if new != nil {
+			if val, ok := new.Metadata[string(field)]; ok {
+				if err := fb.Put(indexKey(val, new.Key), nil); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// lookup returns the keys of the records which have the value of an indexed field
+// in where, or false if none of the fields are indexed
+func lookup(tx *bolt.Tx, where map[string]interface{}) ([]string, bool) {
+	idx := tx.Bucket([]byte(indexBucket))
+	if idx == nil {
+		return nil, false
+	}
+
+	for field, val := range where {
+		fb := idx.Bucket([]byte(field))
+		if fb == nil {
+			continue
+		}
+
+		var keys []string
+
+		// the entries for a value are sorted by key
+		prefix := indexKey(val, "")
+		c := fb.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, string(k[len(prefix):]))
+		}
+
+		return keys, true
+	}
+
+	return nil, false
+}
+
+// list the keys matching the filters, after is the key of the cursor to continue from
+func (m *fileStore) list(db *bolt.DB, order store.Order, limit, offset uint, prefix, suffix, after string, match *regexp.Regexp) []string {
 	var keys []string
 
 	db.View(func(tx *bolt.Tx) error {
@@ -149,25 +411,19 @@ func (m *fileStore) list(db *bolt.DB, order store.Order, limit, offset uint, pre
 		if b == nil {
 			return nil
 		}
-		c := b.Cursor()
-		var k, v []byte
-		var cont func(k []byte) bool
 
-		if prefix != "" {
-			// for prefix we can speed up the search, not for suffix though :(
-			k, v = c.Seek([]byte(prefix))
-			cont = func(k []byte) bool {
-				return bytes.HasPrefix(k, []byte(prefix))
+		// for prefix we can speed up the search, not for suffix though :(
+		k, v, next := seek(b.Cursor(), order, []byte(prefix), []byte(after))
+
+		// get all the key/vals
+		for ; k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = next() {
+			if suffix != "" && !bytes.HasSuffix(k, []byte(suffix)) {
+				continue
 			}
-		} else {
-			k, v = c.First()
-			cont = func(k []byte) bool {
-				return true
+			if match != nil && !match.Match(k) {
+				continue
 			}
-		}
 
-		// get all the key/vals
-		for ; k != nil && cont(k); k, v = c.Next() {
 			storedRecord := &record{}
 
 			if err := json.Unmarshal(v, storedRecord); err != nil {
@@ -178,71 +434,187 @@ func (m *fileStore) list(db *bolt.DB, order store.Order, limit, offset uint, pre
 					continue
 				}
 			}
-			if suffix != "" && !bytes.HasSuffix(k, []byte(suffix)) {
+
+			if offset > 0 {
+				offset--
 				continue
 			}
 
 			keys = append(keys, string(k))
+
+			if limit > 0 && len(keys) == int(limit) {
+				break
+			}
 		}
 
 		return nil
 	})
 
-	// now check if we need do ordering
-	if order == store.OrderDesc {
-		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
-			keys[i], keys[j] = keys[j], keys[i]
+	return keys
+}
+
+// seek moves the cursor to the first key with the prefix in the order, which is after the
+// key of the page cursor if it's set. It returns the key and value with the function to
+// move to the next one.
+func seek(c *bolt.Cursor, order store.Order, prefix, after []byte) ([]byte, []byte, func() ([]byte, []byte)) {
+	if order != store.OrderDesc {
+		start := prefix
+		if bytes.Compare(after, start) > 0 {
+			start = after
+		}
+		k, v := c.Seek(start)
+		if len(after) > 0 && bytes.Equal(k, after) {
+			k, v = c.Next()
+		}
+		return k, v, c.Next
+	}
+
+	// find the key following those to read and step back from it
+	end := prefixEnd(prefix)
+	if len(after) > 0 && (end == nil || bytes.Compare(after, end) < 0) {
+		end = after
+	}
+	if end == nil {
+		k, v := c.Last()
+		return k, v, c.Prev
+	}
+	k, v := c.Seek(end)
+	if k == nil {
+		k, v = c.Last()
+	} else {
+		k, v = c.Prev()
+	}
+	return k, v, c.Prev
+}
+
+// prefixEnd returns the first key after all those with the prefix, or nil if there isn't one
+func prefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
 		}
 	}
+	return nil
+}
 
-	// build a new key set
-	var keyList []string
+// where returns the records matching the key filters and metadata values of the read
+func (m *fileStore) where(db *bolt.DB, readOpts store.ReadOptions, prefix, suffix, after string, match *regexp.Regexp) ([]*store.Record, error) {
+	var results []*store.Record
 
-	if offset > 0 {
-		// offset is greater than the keys we have
-		if int(offset) >= len(keys) {
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dataBucket))
+		// nothing to read
+		if b == nil {
 			return nil
 		}
 
-		// otherwise set the offset for the keys
-		keys = keys[offset:]
+		keys, ok := lookup(tx, readOpts.Where)
+		if !ok {
+			// no index to use so check every record with the prefix
+			c := b.Cursor()
+			for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+				keys = append(keys, string(k))
+			}
+		}
+
+		for _, k := range keys {
+			if !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, suffix) {
+				continue
+			}
+			if match != nil && !match.MatchString(k) {
+				continue
+			}
+			if after != "" && (readOpts.Order == store.OrderDesc && k >= after || readOpts.Order != store.OrderDesc && k <= after) {
+				continue
+			}
+			r, err := getRecord(b, k)
+			if err == store.ErrNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if r.Match(readOpts.Where) {
+				results = append(results, r)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if readOpts.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, readOpts.Order)
+	} else if readOpts.Order == store.OrderDesc {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
 	}
 
-	// check key limit
-	if v := int(limit); v == 0 || v > len(keys) {
-		limit = uint(len(keys))
+	if readOpts.Offset > 0 {
+		// offset is greater than the records we have
+		if int(readOpts.Offset) >= len(results) {
+			return nil, nil
+		}
+		results = results[readOpts.Offset:]
 	}
 
-	for i := 0; i < int(limit); i++ {
-		keyList = append(keyList, keys[i])
+	if readOpts.Limit > 0 && int(readOpts.Limit) < len(results) {
+		results = results[:readOpts.Limit]
 	}
 
-	return keyList
+	return results, nil
 }
 
 func (m *fileStore) get(db *bolt.DB, k string) (*store.Record, error) {
-	var value []byte
+	var rec *store.Record
 
-	db.View(func(tx *bolt.Tx) error {
+	err := db.View(func(tx *bolt.Tx) error {
 		// @todo this is still very experimental...
 		b := tx.Bucket([]byte(dataBucket))
 		if b == nil {
-			return nil
+			return store.ErrNotFound
 		}
 
-		value = b.Get([]byte(k))
-		return nil
+		var err error
+		rec, err = getRecord(b, k)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
 
+// loadRecord reads the record with key k from the bucket, even if it has expired.
+// It returns nil if there's no record.
+func loadRecord(b *bolt.Bucket, k string) (*record, error) {
+	value := b.Get([]byte(k))
 	if value == nil {
-		return nil, store.ErrNotFound
+		return nil, nil
 	}
 
-	storedRecord := &record{}
+	r := &record{}
+	if err := json.Unmarshal(value, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
 
-	if err := json.Unmarshal(value, storedRecord); err != nil {
+// getRecord reads the record with key k from the bucket
+func getRecord(b *bolt.Bucket, k string) (*store.Record, error) {
+	storedRecord, err := loadRecord(b, k)
+	if err != nil {
 		return nil, err
 	}
+	if storedRecord == nil {
+		return nil, store.ErrNotFound
+	}
 
 	newRecord := &store.Record{}
 	newRecord.Key = storedRecord.Key
@@ -260,16 +632,75 @@ func (m *fileStore) get(db *bolt.DB, k string) (*store.Record, error) {
 		newRecord.Expiry = time.Until(storedRecord.ExpiresAt)
 	}
 
+	newRecord.Version = storedRecord.Version
+
 	return newRecord, nil
 }
 
-func (m *fileStore) set(db *bolt.DB, r *store.Record) error {
+func (m *fileStore) set(db *bolt.DB, opts store.WriteOptions, recs ...*store.Record) error {
+	var events []store.EventType
+	var versions []uint64
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(dataBucket))
+		if err != nil {
+			return err
+		}
+
+		for _, r := range recs {
+			version, existed, err := putRecord(b, r, opts)
+			if err != nil {
+				return err
+			}
+			typ := store.EventCreate
+			if existed {
+				typ = store.EventUpdate
+			}
+			events = append(events, typ)
+			versions = append(versions, version)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, typ := range events {
+		recs[i].Version = versions[i]
+		r := *recs[i]
+		m.publish(opts.Database, opts.Table, typ, &r)
+	}
+	return nil
+}
+
+// putRecord writes the record to the bucket if the conditions of the write are met.
+// It returns the new version of the record and whether it replaced an existing one.
+func putRecord(b *bolt.Bucket, r *store.Record, opts store.WriteOptions) (uint64, bool, error) {
+	cur, err := getRecord(b, r.Key)
+	if err != nil && err != store.ErrNotFound {
+		return 0, false, err
+	}
+
+	// check the conditions of the write
+	if opts.IfNotExists && cur != nil {
+		return 0, false, store.ErrConflict
+	}
+	if opts.IfVersion > 0 && (cur == nil || cur.Version != opts.IfVersion) {
+		return 0, false, store.ErrConflict
+	}
+
 	// copy the incoming record and then
 	// convert the expiry in to a hard timestamp
 	item := &record{}
 	item.Key = r.Key
 	item.Value = r.Value
 	item.Metadata = make(map[string]interface{})
+	item.Version = 1
+
+	if cur != nil {
+		item.Version = cur.Version + 1
+	}
 
 	if r.Expiry != 0 {
 		item.ExpiresAt = time.Now().Add(r.Expiry)
@@ -282,21 +713,51 @@ func (m *fileStore) set(db *bolt.DB, r *store.Record) error {
 	// marshal the data
 	data, _ := json.Marshal(item)
 
-	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(dataBucket))
-		if b == nil {
-			var err error
-			b, err = tx.CreateBucketIfNotExists([]byte(dataBucket))
-			if err != nil {
-				return err
-			}
-		}
-		return b.Put([]byte(r.Key), data)
-	})
+	// the old record is indexed even if it has expired
+	old, err := loadRecord(b, r.Key)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if err := b.Put([]byte(r.Key), data); err != nil {
+		return 0, false, err
+	}
+
+	// index the metadata as it's stored rather than as it was written
+	stored := &record{}
+	if err := json.Unmarshal(data, stored); err != nil {
+		return 0, false, err
+	}
+	if err := reindex(b.Tx(), old, stored); err != nil {
+		return 0, false, err
+	}
+
+	return item.Version, cur != nil, nil
+}
+
+// deleteRecord removes the record with key k and its index entries from the bucket
+func deleteRecord(b *bolt.Bucket, k string) error {
+	old, err := loadRecord(b, k)
+	if err != nil {
+		return err
+	}
+	if err := b.Delete([]byte(k)); err != nil {
+		return err
+	}
+	return reindex(b.Tx(), old, nil)
 }
 
 func (f *fileStore) Close() error {
-	return nil
+	f.once.Do(func() {
+		close(f.exit)
+	})
+
+	if f.syncer == nil {
+		return nil
+	}
+	err := f.syncer.stop()
+	f.syncer = nil
+	return err
 }
 
 func (f *fileStore) Init(opts ...store.Option) error {
@@ -304,6 +765,43 @@ func (f *fileStore) Init(opts ...store.Option) error {
 }
 
 func (m *fileStore) Delete(key string, opts ...store.DeleteOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var deleteOptions store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOptions)
+	}
+
+	db, err := m.getDB(deleteOptions.Database, deleteOptions.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Handle Prefix / suffix
+	if deleteOptions.Prefix || deleteOptions.Suffix {
+		prefix := ""
+		if deleteOptions.Prefix {
+			prefix = key
+		}
+		suffix := ""
+		if deleteOptions.Suffix {
+			suffix = key
+		}
+		return m.delete(db, deleteOptions.Database, deleteOptions.Table, m.list(db, store.OrderAsc, 0, 0, prefix, suffix, "", nil)...)
+	}
+
+	return m.delete(db, deleteOptions.Database, deleteOptions.Table, key)
+}
+
+// DeleteMany deletes the records in a single transaction
+func (m *fileStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
 	var deleteOptions store.DeleteOptions
 	for _, o := range opts {
 		o(&deleteOptions)
@@ -315,7 +813,7 @@ func (m *fileStore) Delete(key string, opts ...store.DeleteOption) error {
 	}
 	defer db.Close()
 
-	return m.delete(db, key)
+	return m.delete(db, deleteOptions.Database, deleteOptions.Table, keys...)
 }
 
 func (m *fileStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
@@ -324,13 +822,40 @@ func (m *fileStore) Read(key string, opts ...store.ReadOption) ([]*store.Record,
 		o(&readOpts)
 	}
 
+	// earlier revisions of records aren't kept
+	if readOpts.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
 	db, err := m.getDB(readOpts.Database, readOpts.Table)
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
+	var match *regexp.Regexp
+	if readOpts.Match != nil {
+		if match, err = readOpts.Match.Compile(); err != nil {
+			return nil, err
+		}
+		// reads with a pattern return many records, with the key as their prefix by default
+		if !readOpts.Suffix {
+			readOpts.Prefix = true
+		}
+	}
+
+	var after string
+	if readOpts.Cursor != nil {
+		if after, err = store.DecodeCursor(*readOpts.Cursor); err != nil {
+			return nil, err
+		}
+		if readOpts.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
 	var keys []string
+	var results []*store.Record
 
 	// Handle Prefix / suffix
 	if readOpts.Prefix || readOpts.Suffix {
@@ -342,26 +867,46 @@ func (m *fileStore) Read(key string, opts ...store.ReadOption) ([]*store.Record,
 		if readOpts.Suffix {
 			suffix = key
 		}
-		// list the keys
-		keys = m.list(db, readOpts.Order, readOpts.Limit, readOpts.Offset, prefix, suffix)
+		if len(readOpts.Where) > 0 || readOpts.OrderBy == store.OrderByExpiry {
+			// the records are filtered and sorted before the limit and offset are applied
+			if results, err = m.where(db, readOpts, prefix, suffix, after, match); err != nil {
+				return nil, err
+			}
+		} else {
+			// list the keys
+			keys = m.list(db, readOpts.Order, readOpts.Limit, readOpts.Offset, prefix, suffix, after, match)
+		}
 	} else {
 		keys = []string{key}
 	}
 
-	var results []*store.Record
-
 	for _, k := range keys {
 		r, err := m.get(db, k)
 		if err != nil {
 			return results, err
 		}
+		if !r.Match(readOpts.Where) {
+			return results, store.ErrNotFound
+		}
 		results = append(results, r)
 	}
 
+	if readOpts.Cursor != nil {
+		var last string
+		if len(results) > 0 {
+			last = results[len(results)-1].Key
+		}
+		*readOpts.Cursor = store.NextCursor(readOpts.Limit, len(results), last)
+	}
+
 	return results, nil
 }
 
 func (m *fileStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
 	var writeOpts store.WriteOptions
 	for _, o := range opts {
 		o(&writeOpts)
@@ -385,10 +930,35 @@ func (m *fileStore) Write(r *store.Record, opts ...store.WriteOption) error {
 			newRecord.Metadata[k] = v
 		}
 
-		return m.set(db, &newRecord)
+		if err := m.set(db, writeOpts, &newRecord); err != nil {
+			return err
+		}
+		r.Version = newRecord.Version
+		return nil
 	}
 
-	return m.set(db, r)
+	return m.set(db, writeOpts, r)
+}
+
+// WriteMany writes the records in a single transaction. The conditions of the write are
+// checked against each record, and none are written if one of them isn't met.
+func (m *fileStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	db, err := m.getDB(writeOpts.Database, writeOpts.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return m.set(db, writeOpts, recs...)
 }
 
 func (m *fileStore) Options() store.Options {
@@ -402,17 +972,190 @@ func (m *fileStore) List(opts ...store.ListOption) ([]string, error) {
 		o(&listOptions)
 	}
 
+	// the keys are grouped once they've been listed
+	if len(listOptions.Delimiter) > 0 {
+		return store.ListDelimited(m, listOptions)
+	}
+
 	db, err := m.getDB(listOptions.Database, listOptions.Table)
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	allKeys := m.list(db, listOptions.Order, listOptions.Limit, listOptions.Offset, listOptions.Prefix, listOptions.Suffix)
+	var match *regexp.Regexp
+	if listOptions.Match != nil {
+		if match, err = listOptions.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	var after string
+	if listOptions.Cursor != nil {
+		if after, err = store.DecodeCursor(*listOptions.Cursor); err != nil {
+			return nil, err
+		}
+		if listOptions.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
+	if listOptions.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := m.where(db, store.ReadOptions{
+			Limit:   listOptions.Limit,
+			Offset:  listOptions.Offset,
+			Order:   listOptions.Order,
+			OrderBy: listOptions.OrderBy,
+		}, listOptions.Prefix, listOptions.Suffix, "", match)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(recs))
+		for _, r := range recs {
+			keys = append(keys, r.Key)
+		}
+		return keys, nil
+	}
+
+	allKeys := m.list(db, listOptions.Order, listOptions.Limit, listOptions.Offset, listOptions.Prefix, listOptions.Suffix, after, match)
+
+	if listOptions.Cursor != nil {
+		var last string
+		if len(allKeys) > 0 {
+			last = allKeys[len(allKeys)-1]
+		}
+		*listOptions.Cursor = store.NextCursor(listOptions.Limit, len(allKeys), last)
+	}
 
 	return allKeys, nil
 }
 
+// Txn runs fn in a bolt read-write transaction
+func (m *fileStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	if m.options.ReadOnly {
+		fn = store.ReadOnlyTxn(fn)
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	db, err := m.getDB(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var ftx *fileTx
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(dataBucket))
+		if err != nil {
+			return err
+		}
+		ftx = &fileTx{bucket: b}
+		if err := fn(ftx); err != nil {
+			return err
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range ftx.events {
+		m.publish(options.Database, options.Table, ev.Type, ev.Record)
+	}
+	return nil
+}
+
+type fileTx struct {
+	bucket *bolt.Bucket
+	// the changes to publish once the transaction is committed
+	events []*store.Event
+}
+
+func (t *fileTx) Read(key string) (*store.Record, error) {
+	return getRecord(t.bucket, key)
+}
+
+func (t *fileTx) Write(r *store.Record) error {
+	version, existed, err := putRecord(t.bucket, r, store.WriteOptions{})
+	if err != nil {
+		return err
+	}
+	typ := store.EventCreate
+	if existed {
+		typ = store.EventUpdate
+	}
+	rec := *r
+	rec.Version = version
+	t.events = append(t.events, &store.Event{Type: typ, Record: &rec})
+	return nil
+}
+
+func (t *fileTx) Delete(key string) error {
+	if _, err := getRecord(t.bucket, key); err == nil {
+		t.events = append(t.events, &store.Event{Type: store.EventDelete, Record: &store.Record{Key: key}})
+	}
+	return deleteRecord(t.bucket, key)
+}
+
+// Watch the records in the store for changes made by this process
+// Touch sets the expiry of the record, the record is read and written in
+// a single transaction so a concurrent write isn't lost
+func (m *fileStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	db, err := m.getDB(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var r *store.Record
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dataBucket))
+		if b == nil {
+			return store.ErrNotFound
+		}
+		cur, err := getRecord(b, key)
+		if err != nil {
+			return err
+		}
+		cur.Expiry = expiry
+		if cur.Version, _, err = putRecord(b, cur, store.WriteOptions{}); err != nil {
+			return err
+		}
+		r = cur
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.publish(options.Database, options.Table, store.EventUpdate, r)
+	return nil
+}
+
+func (m *fileStore) Watch(ctx context.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	options.Database, options.Table = m.names(options.Database, options.Table)
+	return m.watchers.Watch(ctx, key, options), nil
+}
+
 func (m *fileStore) String() string {
 	return "file"
 }
@@ -16,11 +16,16 @@ package file
 
 import (
 	"context"
+	"time"
 
 	"github.com/micro/micro/v3/service/store"
 )
 
 type dirKey struct{}
+type noSyncKey struct{}
+type noGrowSyncKey struct{}
+type syncIntervalKey struct{}
+type cleanupIntervalKey struct{}
 
 // WithDir sets the directory to store the files in
 func WithDir(dir string) store.Option {
@@ -32,3 +37,36 @@ func WithDir(dir string) store.Option {
 		}
 	}
 }
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// NoSync stops writes being synced to disk before they return. Writes are much faster
+// but the most recent may be lost if the machine crashes, though not if only the process does.
+func NoSync() store.Option {
+	return setOption(noSyncKey{}, true)
+}
+
+// NoGrowSync skips syncing the directory when a file grows. It's safe on file systems
+// which sync file sizes with the data, such as ext4, but not on others.
+func NoGrowSync() store.Option {
+	return setOption(noGrowSyncKey{}, true)
+}
+
+// SyncInterval syncs the files to disk every interval instead of on every write, so at
+// most the writes made in the interval are lost if the machine crashes. They're also
+// synced when the store is closed.
+func SyncInterval(d time.Duration) store.Option {
+	return setOption(syncIntervalKey{}, d)
+}
+
+// CleanupInterval sets how often expired records are deleted from the files
+func CleanupInterval(d time.Duration) store.Option {
+	return setOption(cleanupIntervalKey{}, d)
+}
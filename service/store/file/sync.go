@@ -0,0 +1,85 @@
+package file
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+)
+
+// syncer periodically syncs the files of the store to disk when writes don't sync them.
+// Handles are only held open for each operation, so it keeps the path of every file
+// opened and syncs them all, which is cheap for the ones which haven't been written.
+type syncer struct {
+	sync.Mutex
+	interval time.Duration
+	paths    map[string]bool
+	exit     chan bool
+	once     sync.Once
+}
+
+func newSyncer(interval time.Duration) *syncer {
+	s := &syncer{
+		interval: interval,
+		paths:    make(map[string]bool),
+		exit:     make(chan bool),
+	}
+	go s.run()
+	return s
+}
+
+// add the file to those synced
+func (s *syncer) add(path string) {
+	s.Lock()
+	s.paths[path] = true
+	s.Unlock()
+}
+
+func (s *syncer) run() {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.exit:
+			return
+		case <-t.C:
+		}
+		if err := s.sync(); err != nil {
+			logger.Errorf("Error syncing file store: %v", err)
+		}
+	}
+}
+
+// sync the files to disk, returning the last error
+func (s *syncer) sync() error {
+	s.Lock()
+	defer s.Unlock()
+
+	var err error
+	for path := range s.paths {
+		f, ferr := os.OpenFile(path, os.O_RDWR, 0)
+		if os.IsNotExist(ferr) {
+			// the database was removed
+			delete(s.paths, path)
+			continue
+		} else if ferr != nil {
+			err = ferr
+			continue
+		}
+		if serr := f.Sync(); serr != nil {
+			err = serr
+		}
+		f.Close()
+	}
+	return err
+}
+
+// stop syncing and sync the files a final time
+func (s *syncer) stop() error {
+	s.once.Do(func() {
+		close(s.exit)
+	})
+	return s.sync()
+}
@@ -0,0 +1,40 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewStore(WithDir(dir), NoGrowSync(), SyncInterval(10*time.Millisecond))
+	fs := s.(*fileStore)
+	assert.True(t, fs.boltOptions().NoSync, "Expected writes not to be synced")
+	assert.True(t, fs.boltOptions().NoGrowSync)
+
+	assert.NoError(t, s.Write(&store.Record{Key: "foo", Value: []byte("bar")}))
+	fs.syncer.Lock()
+	assert.True(t, fs.syncer.paths[filepath.Join(dir, DefaultDatabase, DefaultTable+".db")])
+	fs.syncer.Unlock()
+
+	// the synced files are forgotten once they're removed
+	assert.NoError(t, os.RemoveAll(filepath.Join(dir, DefaultDatabase)))
+	time.Sleep(50 * time.Millisecond)
+	fs.syncer.Lock()
+	assert.Len(t, fs.syncer.paths, 0)
+	fs.syncer.Unlock()
+
+	assert.NoError(t, s.Close())
+	assert.Nil(t, fs.syncer)
+	assert.False(t, fs.boltOptions().NoSync, "Expected writes to be synced once the store is closed")
+	assert.False(t, NewStore(WithDir(dir), NoSync()).(*fileStore).boltOptions().NoGrowSync)
+}
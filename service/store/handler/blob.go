@@ -1,9 +1,11 @@
 package handler
 
 import (
-	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	pb "github.com/micro/micro/v3/proto/store"
 	"github.com/micro/micro/v3/service/errors"
@@ -12,7 +14,8 @@ import (
 	"github.com/micro/micro/v3/util/namespace"
 )
 
-const bufferSize = 1024
+// bufferSize is the size of the chunks blobs are streamed in
+const bufferSize = 64 * 1024
 
 type BlobStore struct{}
 
@@ -38,19 +41,24 @@ func (b *BlobStore) Read(ctx context.Context, req *pb.BlobReadRequest, stream pb
 	} else if err != nil {
 		return errors.InternalServerError("store.Blob.Read", err.Error())
 	}
+	if c, ok := blob.(io.Closer); ok {
+		defer c.Close()
+	}
 
 	// read from the blob and stream it to the client
 	buffer := make([]byte, bufferSize)
 	for {
-		num, err := blob.Read(buffer)
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return err
+		num, rerr := blob.Read(buffer)
+		if num > 0 {
+			if err := stream.Send(&pb.BlobReadResponse{Blob: buffer[:num]}); err != nil {
+				return err
+			}
 		}
 
-		if err := stream.Send(&pb.BlobReadResponse{Blob: buffer[:num]}); err != nil {
-			return err
+		if rerr == io.EOF {
+			break
+		} else if rerr != nil {
+			return errors.InternalServerError("store.Blob.Read", rerr.Error())
 		}
 	}
 
@@ -59,48 +67,52 @@ func (b *BlobStore) Read(ctx context.Context, req *pb.BlobReadRequest, stream pb
 
 func (b *BlobStore) Write(ctx context.Context, stream pb.BlobStore_WriteStream) error {
 	// the key and options are passed on each message but we only need to extract them once
-	var buf *bytes.Buffer
-	var key string
-	var options *pb.BlobOptions
+	req, err := stream.Recv()
+	if err == io.EOF {
+		// ensure the blob was sent over the stream
+		return errors.BadRequest("store.Blob.Write", "No blob was sent")
+	} else if err != nil {
+		return errors.InternalServerError("store.Blob.Write", err.Error())
+	}
+	key := req.Key
+	options := req.Options
 
-	// recieve the blob from the client
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return errors.InternalServerError("store.Blob.Write", err.Error())
-		}
+	// parse the options
+	if options == nil {
+		options = &pb.BlobOptions{}
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = namespace.FromContext(ctx)
+	}
 
-		if buf == nil {
-			// first message recieved from the stream
-			buf = bytes.NewBuffer(req.Blob)
-			key = req.Key
-			options = req.Options
+	// authorize the request before the blob is received so we fail fast
+	if err := authns.AuthorizeAdmin(ctx, options.Namespace, "store.Blob.Write"); err != nil {
+		return err
+	}
 
-			// parse the options
-			if options == nil || len(options.Namespace) == 0 {
-				options.Namespace = namespace.FromContext(ctx)
+	// the blob is passed to the store as it's received rather than held in memory
+	pr, pw := io.Pipe()
+	go func(chunk []byte) {
+		for {
+			if _, err := pw.Write(chunk); err != nil {
+				// the store stopped reading the blob
+				return
 			}
-
-			// authorize the request. do this inside the loop so we fail fast
-			if err := authns.AuthorizeAdmin(ctx, options.Namespace, "store.Blob.Write"); err != nil {
-				return err
+			req, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			} else if err != nil {
+				pw.CloseWithError(err)
+				return
 			}
-
-		} else {
-			// subsequent message recieved from the stream
-			buf.Write(req.Blob)
+			chunk = req.Blob
 		}
-	}
-
-	// ensure the blob was sent over the stream
-	if buf == nil {
-		return errors.BadRequest("store.Blob.Write", "No blob was sent")
-	}
+	}(req.Blob)
 
 	// execute the request
-	err := store.DefaultBlobStore.Write(key, buf, store.BlobNamespace(options.Namespace), store.BlobPublic(options.Public), store.BlobContentType(options.ContentType))
+	err = store.DefaultBlobStore.Write(key, pr, store.BlobNamespace(options.Namespace), store.BlobPublic(options.Public), store.BlobContentType(options.ContentType), store.BlobMetadata(options.Metadata))
+	pr.Close()
 	if err == store.ErrMissingKey {
 		return errors.BadRequest("store.Blob.Write", "Missing key")
 	} else if err != nil {
@@ -163,3 +175,86 @@ func (b *BlobStore) List(ctx context.Context, req *pb.BlobListRequest, rsp *pb.B
 	return nil
 
 }
+
+func (b *BlobStore) ListInfo(ctx context.Context, req *pb.BlobListRequest, rsp *pb.BlobListInfoResponse) error {
+	// parse the options
+	if ns := req.GetOptions().GetNamespace(); len(ns) == 0 {
+		req.Options = &pb.BlobListOptions{
+			Namespace: namespace.FromContext(ctx),
+			Prefix:    req.GetOptions().GetPrefix(),
+		}
+	}
+
+	// authorize the request
+	if err := authns.AuthorizeAdmin(ctx, req.Options.Namespace, "store.Blob.ListInfo"); err != nil {
+		return err
+	}
+
+	// execute the request
+	blobs, err := store.ListInfo(
+		store.BlobListNamespace(req.GetOptions().GetNamespace()),
+		store.BlobListPrefix(req.GetOptions().GetPrefix()))
+	if err == store.ErrNotSupported {
+		return errors.NotImplemented("store.Blob.ListInfo", "listing blob attributes is not supported by the blob store")
+	} else if err != nil {
+		return errors.InternalServerError("store.Blob.ListInfo", err.Error())
+	}
+
+	rsp.Blobs = make([]*pb.BlobInfo, len(blobs))
+	for i, b := range blobs {
+		rsp.Blobs[i] = &pb.BlobInfo{
+			Key:         b.Key,
+			Size:        b.Size,
+			ContentType: b.ContentType,
+			Metadata:    b.Metadata,
+		}
+		if !b.Modified.IsZero() {
+			rsp.Blobs[i].Modified = b.Modified.Unix()
+		}
+	}
+
+	return nil
+}
+
+// defaultPresignTTL is how long presigned urls are valid for when no ttl is requested
+const defaultPresignTTL = 15 * time.Minute
+
+func (b *BlobStore) Presign(ctx context.Context, req *pb.BlobPresignRequest, rsp *pb.BlobPresignResponse) error {
+	// parse the options
+	if ns := req.GetOptions().GetNamespace(); len(ns) == 0 {
+		req.Options = &pb.BlobOptions{
+			Namespace: namespace.FromContext(ctx),
+		}
+	}
+	ttl := defaultPresignTTL
+	if req.Ttl > 0 {
+		ttl = time.Duration(req.Ttl) * time.Second
+	}
+
+	// authorize the request
+	if err := authns.AuthorizeAdmin(ctx, req.Options.Namespace, "store.Blob.Presign"); err != nil {
+		return err
+	}
+
+	// execute the request
+	var url string
+	var err error
+	switch strings.ToUpper(req.Method) {
+	case "", http.MethodGet:
+		url, err = store.PresignGet(req.Key, ttl, store.BlobNamespace(req.Options.Namespace))
+	case http.MethodPut:
+		url, err = store.PresignPut(req.Key, ttl, store.BlobNamespace(req.Options.Namespace))
+	default:
+		return errors.BadRequest("store.Blob.Presign", "Unsupported method %v", req.Method)
+	}
+	if err == store.ErrMissingKey {
+		return errors.BadRequest("store.Blob.Presign", "Missing key")
+	} else if err == store.ErrNotSupported {
+		return errors.NotImplemented("store.Blob.Presign", "presigned urls are not supported by the blob store")
+	} else if err != nil {
+		return errors.InternalServerError("store.Blob.Presign", err.Error())
+	}
+	rsp.Url = url
+
+	return nil
+}
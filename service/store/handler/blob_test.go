@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	pb "github.com/micro/micro/v3/proto/store"
+	"github.com/micro/micro/v3/service/auth"
+	"github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/file"
+	"github.com/stretchr/testify/assert"
+)
+
+// blobStream is the server side of the streams of the blob store
+type blobStream struct {
+	pb.BlobStore_WriteStream
+	ctx  context.Context
+	reqs []*pb.BlobWriteRequest
+	rsps []*pb.BlobReadResponse
+}
+
+func (s *blobStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *blobStream) Recv() (*pb.BlobWriteRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *blobStream) SendAndClose(*pb.BlobWriteResponse) error {
+	return nil
+}
+
+// Send copies the response, as it's serialized when it's sent and the buffer then reused
+func (s *blobStream) Send(rsp *pb.BlobReadResponse) error {
+	s.rsps = append(s.rsps, &pb.BlobReadResponse{Blob: append([]byte{}, rsp.Blob...)})
+	return nil
+}
+
+func (s *blobStream) Close() error {
+	return nil
+}
+
+func TestBlobStream(t *testing.T) {
+	defer func(b store.BlobStore) { store.DefaultBlobStore = b }(store.DefaultBlobStore)
+	var err error
+	store.DefaultBlobStore, err = file.NewBlobStore(file.WithDir(t.TempDir()))
+	assert.NoError(t, err)
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: defaultDatabase, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &BlobStore{}
+
+	// the blob is written as it's received
+	blob := bytes.Repeat([]byte("micro"), bufferSize)
+	ws := &blobStream{ctx: ctx}
+	for b := blob; len(b) > 0; {
+		n := bufferSize
+		if n > len(b) {
+			n = len(b)
+		}
+		ws.reqs = append(ws.reqs, &pb.BlobWriteRequest{Key: "blob", Blob: b[:n]})
+		b = b[n:]
+	}
+	assert.NoError(t, h.Write(ctx, ws))
+
+	r, err := store.DefaultBlobStore.Read("blob", store.BlobNamespace(defaultDatabase))
+	assert.NoError(t, err)
+	v, _ := ioutil.ReadAll(r)
+	assert.True(t, bytes.Equal(blob, v), "Expected the blob to be written")
+
+	// and read in chunks
+	rs := &blobStream{ctx: ctx}
+	assert.NoError(t, h.Read(ctx, &pb.BlobReadRequest{Key: "blob"}, rs))
+	var read []byte
+	for _, rsp := range rs.rsps {
+		assert.LessOrEqual(t, len(rsp.Blob), bufferSize)
+		read = append(read, rsp.Blob...)
+	}
+	assert.True(t, bytes.Equal(blob, read), "Expected the blob to be read")
+
+	// an empty stream has no blob
+	assert.Error(t, h.Write(ctx, &blobStream{ctx: ctx}))
+}
+
+// presignStore is a blob store which signs urls for its blobs
+type presignStore struct {
+	store.BlobStore
+}
+
+func (p *presignStore) PresignGet(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return p.url("GET", key, ttl, opts...), nil
+}
+
+func (p *presignStore) PresignPut(key string, ttl time.Duration, opts ...store.BlobOption) (string, error) {
+	return p.url("PUT", key, ttl, opts...), nil
+}
+
+func (p *presignStore) url(method, key string, ttl time.Duration, opts ...store.BlobOption) string {
+	var options store.BlobOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return fmt.Sprintf("https://blobs/%v/%v?method=%v&ttl=%v", options.Namespace, key, method, ttl)
+}
+
+func TestBlobPresign(t *testing.T) {
+	defer func(b store.BlobStore) { store.DefaultBlobStore = b }(store.DefaultBlobStore)
+	var err error
+	store.DefaultBlobStore, err = file.NewBlobStore(file.WithDir(t.TempDir()))
+	assert.NoError(t, err)
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: defaultDatabase, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &BlobStore{}
+
+	// the file store can't sign urls
+	err = h.Presign(ctx, &pb.BlobPresignRequest{Key: "blob"}, &pb.BlobPresignResponse{})
+	assert.Equal(t, int32(http.StatusNotImplemented), errors.FromError(err).Code)
+
+	store.DefaultBlobStore = &presignStore{store.DefaultBlobStore}
+
+	rsp := &pb.BlobPresignResponse{}
+	assert.NoError(t, h.Presign(ctx, &pb.BlobPresignRequest{Key: "blob", Options: &pb.BlobOptions{Namespace: defaultDatabase}}, rsp))
+	assert.Equal(t, "https://blobs/micro/blob?method=GET&ttl=15m0s", rsp.Url)
+
+	rsp = &pb.BlobPresignResponse{}
+	assert.NoError(t, h.Presign(ctx, &pb.BlobPresignRequest{Key: "blob", Options: &pb.BlobOptions{Namespace: defaultDatabase}, Method: "put", Ttl: 60}, rsp))
+	assert.Equal(t, "https://blobs/micro/blob?method=PUT&ttl=1m0s", rsp.Url)
+
+	err = h.Presign(ctx, &pb.BlobPresignRequest{Key: "blob", Method: "DELETE"}, &pb.BlobPresignResponse{})
+	assert.Equal(t, int32(http.StatusBadRequest), errors.FromError(err).Code)
+}
+
+func TestBlobListInfo(t *testing.T) {
+	defer func(b store.BlobStore) { store.DefaultBlobStore = b }(store.DefaultBlobStore)
+	var err error
+	store.DefaultBlobStore, err = file.NewBlobStore(file.WithDir(t.TempDir()))
+	assert.NoError(t, err)
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: defaultDatabase, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &BlobStore{}
+
+	// the attributes are passed to the store with the blob
+	opts := &pb.BlobOptions{Namespace: defaultDatabase, ContentType: "text/html", Metadata: map[string]string{"foo": "bar"}}
+	ws := &blobStream{ctx: ctx, reqs: []*pb.BlobWriteRequest{{Key: "index.html", Options: opts, Blob: []byte("<html>")}}}
+	assert.NoError(t, h.Write(ctx, ws))
+
+	rsp := &pb.BlobListInfoResponse{}
+	assert.NoError(t, h.ListInfo(ctx, &pb.BlobListRequest{Options: &pb.BlobListOptions{Namespace: defaultDatabase}}, rsp))
+	assert.Len(t, rsp.Blobs, 1)
+	if len(rsp.Blobs) == 1 {
+		b := rsp.Blobs[0]
+		assert.Equal(t, "index.html", b.Key)
+		assert.Equal(t, int64(len("<html>")), b.Size)
+		assert.Equal(t, "text/html", b.ContentType)
+		assert.Equal(t, map[string]string{"foo": "bar"}, b.Metadata)
+		assert.InDelta(t, time.Now().Unix(), b.Modified, 60)
+	}
+
+	// stores which can't list the attributes of blobs return an error
+	store.DefaultBlobStore = &presignStore{store.DefaultBlobStore}
+	err = h.ListInfo(ctx, &pb.BlobListRequest{}, &pb.BlobListInfoResponse{})
+	assert.Equal(t, int32(http.StatusNotImplemented), errors.FromError(err).Code)
+}
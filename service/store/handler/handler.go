@@ -12,6 +12,7 @@ import (
 	pb "github.com/micro/micro/v3/proto/store"
 	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/wrapper/trace"
 	"github.com/micro/micro/v3/util/auth/namespace"
 )
 
@@ -25,6 +26,12 @@ type Store struct {
 	// local Stores cache
 	sync.RWMutex
 	Stores map[string]bool
+
+	// Tenancy chooses the database of requests which don't set one, TenancyNone by default
+	Tenancy Tenancy
+
+	// quotas and usage of the databases
+	quotas quotas
 }
 
 // List all the keys in a table
@@ -33,8 +40,8 @@ func (h *Store) List(ctx context.Context, req *pb.ListRequest, stream pb.Store_L
 	if req.Options == nil {
 		req.Options = &pb.ListOptions{}
 	}
-	if len(req.Options.Database) == 0 {
-		req.Options.Database = defaultDatabase
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.List"); err != nil {
+		return err
 	}
 	if len(req.Options.Table) == 0 {
 		req.Options.Table = defaultTable
@@ -73,30 +80,55 @@ func (h *Store) List(ctx context.Context, req *pb.ListRequest, stream pb.Store_L
 		}
 		opts = append(opts, store.ListOrder(order))
 	}
+	if req.Options.OrderBy == string(store.OrderByExpiry) {
+		opts = append(opts, store.ListOrderBy(store.OrderByExpiry))
+	}
+	if len(req.Options.Delimiter) > 0 {
+		opts = append(opts, store.ListDelimiter(req.Options.Delimiter))
+	}
+	if req.Options.MatchRegexp {
+		opts = append(opts, store.ListMatchRegexp(req.Options.Match))
+	} else if len(req.Options.Match) > 0 {
+		opts = append(opts, store.ListMatch(req.Options.Match))
+	}
+	cursor := req.Options.Cursor
+	if req.Options.Paginate {
+		opts = append(opts, store.ListCursor(&cursor))
+	}
 
 	// list from the store
-	vals, err := store.DefaultStore.List(opts...)
+	vals, err := trace.WithContext(ctx, store.DefaultStore).List(opts...)
 	if err != nil && err == store.ErrNotFound {
 		return errors.NotFound("store.Store.List", err.Error())
+	} else if err == store.ErrInvalidCursor || err == store.ErrInvalidPattern {
+		return errors.BadRequest("store.Store.List", err.Error())
 	} else if err != nil {
 		return errors.InternalServerError("store.Store.List", err.Error())
 	}
 
-	// serialize the response
-	// TODO: batch sync
-	rsp := new(pb.ListResponse)
-	for _, val := range vals {
-		rsp.Keys = append(rsp.Keys, val)
-	}
+	// send the keys in batches, with the cursor in the last
+	for {
+		n := listBatch
+		if n > len(vals) {
+			n = len(vals)
+		}
+		rsp := &pb.ListResponse{Keys: vals[:n]}
+		vals = vals[n:]
+		if req.Options.Paginate && len(vals) == 0 {
+			rsp.Cursor = cursor
+		}
 
-	err = stream.Send(rsp)
-	if err == io.EOF {
-		return nil
-	}
-	if err != nil {
-		return errors.InternalServerError("store.Store.List", err.Error())
+		err = stream.Send(rsp)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.InternalServerError("store.Store.List", err.Error())
+		}
+		if len(vals) == 0 {
+			return nil
+		}
 	}
-	return nil
 }
 
 // Read records from the store
@@ -105,8 +137,8 @@ func (h *Store) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadRespo
 	if req.Options == nil {
 		req.Options = &pb.ReadOptions{}
 	}
-	if len(req.Options.Database) == 0 {
-		req.Options.Database = defaultDatabase
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.Read"); err != nil {
+		return err
 	}
 	if len(req.Options.Table) == 0 {
 		req.Options.Table = defaultTable
@@ -123,56 +155,87 @@ func (h *Store) Read(ctx context.Context, req *pb.ReadRequest, rsp *pb.ReadRespo
 	}
 
 	// setup the options
-	opts := []store.ReadOption{
-		store.ReadFrom(req.Options.Database, req.Options.Table),
-	}
-	if req.Options.Prefix {
-		opts = append(opts, store.ReadPrefix())
-	}
-	if req.Options.Suffix {
-		opts = append(opts, store.ReadSuffix())
-	}
+	opts := readOptions(req.Options)
 	if req.Options.Limit > 0 {
 		opts = append(opts, store.ReadLimit(uint(req.Options.Limit)))
 	}
 	if req.Options.Offset > 0 {
 		opts = append(opts, store.ReadOffset(uint(req.Options.Offset)))
 	}
-	if len(req.Options.Order) > 0 {
-		order := store.OrderAsc
-		if req.Options.Order == string(store.OrderDesc) {
-			order = store.OrderDesc
-		}
-		opts = append(opts, store.ReadOrder(order))
+	cursor := req.Options.Cursor
+	if req.Options.Paginate {
+		opts = append(opts, store.ReadCursor(&cursor))
 	}
 
 	// read from the database
-	vals, err := store.DefaultStore.Read(req.Key, opts...)
+	vals, err := trace.WithContext(ctx, store.DefaultStore).Read(req.Key, opts...)
 	if err != nil && err == store.ErrNotFound {
 		return errors.NotFound("store.Store.Read", err.Error())
+	} else if err == store.ErrInvalidCursor || err == store.ErrInvalidPattern {
+		return errors.BadRequest("store.Store.Read", err.Error())
 	} else if err != nil {
 		return errors.InternalServerError("store.Store.Read", err.Error())
 	}
 
 	// serialize the result
 	for _, val := range vals {
-		metadata := make(map[string]*pb.Field)
-		for k, v := range val.Metadata {
-			metadata[k] = &pb.Field{
-				Type:  reflect.TypeOf(v).String(),
-				Value: fmt.Sprintf("%v", v),
-			}
-		}
-		rsp.Records = append(rsp.Records, &pb.Record{
-			Key:      val.Key,
-			Value:    val.Value,
-			Expiry:   int64(val.Expiry.Seconds()),
-			Metadata: metadata,
-		})
+		rsp.Records = append(rsp.Records, serializeRecord(val))
+	}
+	if req.Options.Paginate {
+		rsp.Cursor = cursor
 	}
 	return nil
 }
 
+// readOptions returns the options of a read other than its limit, offset and cursor
+func readOptions(o *pb.ReadOptions) []store.ReadOption {
+	opts := []store.ReadOption{
+		store.ReadFrom(o.Database, o.Table),
+	}
+	if o.Prefix {
+		opts = append(opts, store.ReadPrefix())
+	}
+	if o.Suffix {
+		opts = append(opts, store.ReadSuffix())
+	}
+	if len(o.Order) > 0 {
+		order := store.OrderAsc
+		if o.Order == string(store.OrderDesc) {
+			order = store.OrderDesc
+		}
+		opts = append(opts, store.ReadOrder(order))
+	}
+	if o.OrderBy == string(store.OrderByExpiry) {
+		opts = append(opts, store.ReadOrderBy(store.OrderByExpiry))
+	}
+	for k, v := range o.Where {
+		opts = append(opts, store.ReadWhere(k, v))
+	}
+	if o.MatchRegexp {
+		opts = append(opts, store.ReadMatchRegexp(o.Match))
+	} else if len(o.Match) > 0 {
+		opts = append(opts, store.ReadMatch(o.Match))
+	}
+	return opts
+}
+
+func serializeRecord(r *store.Record) *pb.Record {
+	metadata := make(map[string]*pb.Field)
+	for k, v := range r.Metadata {
+		metadata[k] = &pb.Field{
+			Type:  reflect.TypeOf(v).String(),
+			Value: fmt.Sprintf("%v", v),
+		}
+	}
+	return &pb.Record{
+		Key:      r.Key,
+		Value:    r.Value,
+		Expiry:   int64(r.Expiry.Seconds()),
+		Metadata: metadata,
+		Version:  r.Version,
+	}
+}
+
 // Write to the store
 func (h *Store) Write(ctx context.Context, req *pb.WriteRequest, rsp *pb.WriteResponse) error {
 	// validate the request
@@ -184,8 +247,8 @@ func (h *Store) Write(ctx context.Context, req *pb.WriteRequest, rsp *pb.WriteRe
 	if req.Options == nil {
 		req.Options = &pb.WriteOptions{}
 	}
-	if len(req.Options.Database) == 0 {
-		req.Options.Database = defaultDatabase
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.Write"); err != nil {
+		return err
 	}
 	if len(req.Options.Table) == 0 {
 		req.Options.Table = defaultTable
@@ -205,6 +268,12 @@ func (h *Store) Write(ctx context.Context, req *pb.WriteRequest, rsp *pb.WriteRe
 	opts := []store.WriteOption{
 		store.WriteTo(req.Options.Database, req.Options.Table),
 	}
+	if req.Options.IfVersion > 0 {
+		opts = append(opts, store.WriteIfVersion(req.Options.IfVersion))
+	}
+	if req.Options.IfNotExists {
+		opts = append(opts, store.WriteIfNotExists())
+	}
 
 	// construct the record
 	metadata := make(map[string]interface{})
@@ -218,14 +287,32 @@ func (h *Store) Write(ctx context.Context, req *pb.WriteRequest, rsp *pb.WriteRe
 		Metadata: metadata,
 	}
 
+	// check the write is within the quota of the database
+	release, err := h.reserve(req.Options.Database, req.Options.Table, record)
+	if err == store.ErrQuotaExceeded {
+		return errors.Forbidden("store.Store.Write", err.Error())
+	} else if err != nil {
+		return errors.InternalServerError("store.Store.Write", err.Error())
+	}
+
 	// write to the store
-	err := store.DefaultStore.Write(record, opts...)
+	err = trace.WithContext(ctx, store.DefaultStore).Write(record, opts...)
+	if err != nil {
+		release()
+	}
 	if err != nil && err == store.ErrNotFound {
 		return errors.NotFound("store.Store.Write", err.Error())
+	} else if err == store.ErrConflict {
+		return errors.Conflict("store.Store.Write", err.Error())
+	} else if err == store.ErrReadOnly {
+		return errors.Forbidden("store.Store.Write", err.Error())
+	} else if err == store.ErrNotSupported {
+		return errors.NotImplemented("store.Store.Write", "conditional writes are not supported by the %s store", store.DefaultStore.String())
 	} else if err != nil {
 		return errors.InternalServerError("store.Store.Write", err.Error())
 	}
 
+	rsp.Version = record.Version
 	return nil
 }
 
@@ -234,8 +321,8 @@ func (h *Store) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.Delet
 	if req.Options == nil {
 		req.Options = &pb.DeleteOptions{}
 	}
-	if len(req.Options.Database) == 0 {
-		req.Options.Database = defaultDatabase
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.Delete"); err != nil {
+		return err
 	}
 	if len(req.Options.Table) == 0 {
 		req.Options.Table = defaultTable
@@ -255,10 +342,33 @@ func (h *Store) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.Delet
 	opts := []store.DeleteOption{
 		store.DeleteFrom(req.Options.Database, req.Options.Table),
 	}
+	if req.Options.Prefix {
+		opts = append(opts, store.DeletePrefix())
+	}
+	if req.Options.Suffix {
+		opts = append(opts, store.DeleteSuffix())
+	}
+
+	// the deleted records are counted again on the next write
+	defer h.forgetUsage(req.Options.Database)
+
+	// delete many keys in one go
+	if len(req.Keys) > 0 {
+		if err := store.DeleteMany(req.Keys, opts...); err == store.ErrNotFound {
+			return errors.NotFound("store.Store.Delete", err.Error())
+		} else if err == store.ErrReadOnly {
+			return errors.Forbidden("store.Store.Delete", err.Error())
+		} else if err != nil {
+			return errors.InternalServerError("store.Store.Delete", err.Error())
+		}
+		return nil
+	}
 
 	// delete from the store
-	if err := store.DefaultStore.Delete(req.Key, opts...); err == store.ErrNotFound {
+	if err := trace.WithContext(ctx, store.DefaultStore).Delete(req.Key, opts...); err == store.ErrNotFound {
 		return errors.NotFound("store.Store.Delete", err.Error())
+	} else if err == store.ErrReadOnly {
+		return errors.Forbidden("store.Store.Delete", err.Error())
 	} else if err != nil {
 		return errors.InternalServerError("store.Store.Delete", err.Error())
 	}
@@ -266,6 +376,146 @@ func (h *Store) Delete(ctx context.Context, req *pb.DeleteRequest, rsp *pb.Delet
 	return nil
 }
 
+// Watch streams the changes made to records
+func (h *Store) Watch(ctx context.Context, req *pb.WatchRequest, stream pb.Store_WatchStream) error {
+	// set defaults
+	if req.Options == nil {
+		req.Options = &pb.WatchOptions{}
+	}
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.Watch"); err != nil {
+		return err
+	}
+	if len(req.Options.Table) == 0 {
+		req.Options.Table = defaultTable
+	}
+
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, req.Options.Database, "store.Store.Watch"); err != nil {
+		return err
+	}
+
+	// setup the options
+	opts := []store.WatchOption{
+		store.WatchFrom(req.Options.Database, req.Options.Table),
+	}
+	if req.Options.Prefix {
+		opts = append(opts, store.WatchPrefix())
+	}
+
+	// stop watching once the stream has been closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := store.Watch(ctx, req.Key, opts...)
+	if err == store.ErrNotSupported {
+		return errors.BadRequest("store.Store.Watch", "watch is not supported by the %s store", store.DefaultStore.String())
+	} else if err != nil {
+		return errors.InternalServerError("store.Store.Watch", err.Error())
+	}
+
+	for ev := range events {
+		metadata := make(map[string]*pb.Field)
+		for k, v := range ev.Record.Metadata {
+			metadata[k] = &pb.Field{
+				Type:  reflect.TypeOf(v).String(),
+				Value: fmt.Sprintf("%v", v),
+			}
+		}
+
+		rsp := &pb.WatchResponse{
+			Type:     string(ev.Type),
+			Database: ev.Database,
+			Table:    ev.Table,
+			Record: &pb.Record{
+				Key:      ev.Record.Key,
+				Value:    ev.Record.Value,
+				Expiry:   int64(ev.Record.Expiry.Seconds()),
+				Metadata: metadata,
+				Version:  ev.Record.Version,
+			},
+			Timestamp: ev.Timestamp.Unix(),
+		}
+		if err := stream.Send(rsp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Touch sets the expiry of a record
+func (h *Store) Touch(ctx context.Context, req *pb.TouchRequest, rsp *pb.TouchResponse) error {
+	// set defaults
+	if req.Options == nil {
+		req.Options = &pb.TouchOptions{}
+	}
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.Touch"); err != nil {
+		return err
+	}
+	if len(req.Options.Table) == 0 {
+		req.Options.Table = defaultTable
+	}
+
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, req.Options.Database, "store.Store.Touch"); err != nil {
+		return err
+	}
+
+	// setup the store
+	if err := h.setupTable(req.Options.Database, req.Options.Table); err != nil {
+		return errors.InternalServerError("store.Store.Touch", err.Error())
+	}
+
+	expiry := time.Duration(req.Expiry) * time.Second
+	err := store.Touch(ctx, req.Key, expiry, store.TouchFrom(req.Options.Database, req.Options.Table))
+	if err == store.ErrNotFound {
+		return errors.NotFound("store.Store.Touch", err.Error())
+	} else if err == store.ErrReadOnly {
+		return errors.Forbidden("store.Store.Touch", err.Error())
+	} else if err == store.ErrNotSupported {
+		return errors.NotImplemented("store.Store.Touch", "touch is not supported by the %s store", store.DefaultStore.String())
+	} else if err != nil {
+		return errors.InternalServerError("store.Store.Touch", err.Error())
+	}
+
+	return nil
+}
+
+// Compact rewrites the files of a table to reclaim disk space
+func (h *Store) Compact(ctx context.Context, req *pb.CompactRequest, rsp *pb.CompactResponse) error {
+	// set defaults
+	if req.Options == nil {
+		req.Options = &pb.CompactOptions{}
+	}
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.Compact"); err != nil {
+		return err
+	}
+	if len(req.Options.Table) == 0 {
+		req.Options.Table = defaultTable
+	}
+
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, req.Options.Database, "store.Store.Compact"); err != nil {
+		return err
+	}
+
+	// setup the store
+	if err := h.setupTable(req.Options.Database, req.Options.Table); err != nil {
+		return errors.InternalServerError("store.Store.Compact", err.Error())
+	}
+
+	err := store.Compact(ctx, store.CompactFrom(req.Options.Database, req.Options.Table))
+	if err == store.ErrReadOnly {
+		return errors.Forbidden("store.Store.Compact", err.Error())
+	} else if err == store.ErrNotSupported {
+		return errors.NotImplemented("store.Store.Compact", "compact is not supported by the %s store", store.DefaultStore.String())
+	} else if err != nil {
+		return errors.InternalServerError("store.Store.Compact", err.Error())
+	}
+
+	return nil
+}
+
 // Databases lists all the databases
 func (h *Store) Databases(ctx context.Context, req *pb.DatabasesRequest, rsp *pb.DatabasesResponse) error {
 	// authorize the request
@@ -294,8 +544,8 @@ func (h *Store) Databases(ctx context.Context, req *pb.DatabasesRequest, rsp *pb
 // Tables returns all the tables in a database
 func (h *Store) Tables(ctx context.Context, req *pb.TablesRequest, rsp *pb.TablesResponse) error {
 	// set defaults
-	if len(req.Database) == 0 {
-		req.Database = defaultDatabase
+	if err := h.tenant(ctx, &req.Database, "store.Store.Tables"); err != nil {
+		return err
 	}
 
 	// authorize the request
@@ -334,6 +584,11 @@ func (h *Store) setupTable(database, table string) error {
 		return nil
 	}
 
+	// a read only store can't record new tables, and has all the ones it will have
+	if store.DefaultStore.Options().ReadOnly {
+		return nil
+	}
+
 	// record the new database in the internal store
 	opt := store.WriteTo(defaultDatabase, internalTable)
 	dbRecord := &store.Record{Key: "databases/" + database, Value: []byte{}}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/micro/micro/v3/proto/store"
+	"github.com/micro/micro/v3/service/config"
+	"github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/util/auth/namespace"
+)
+
+const (
+	// quotaTTL is how long a quota read from config is used before it's read again
+	quotaTTL = time.Minute
+	// usageTTL is how long the usage of a database is tracked before it's counted again,
+	// other instances of the service write to the same databases
+	usageTTL = 5 * time.Minute
+)
+
+// Quota limits the records of a database. Quotas are read from the "store.quotas.<database>"
+// config value, falling back to "store.quotas.default". Zero means there's no limit.
+type Quota struct {
+	MaxKeys  int64 `json:"max_keys"`
+	MaxBytes int64 `json:"max_bytes"`
+}
+
+func (q Quota) limited() bool {
+	return q.MaxKeys > 0 || q.MaxBytes > 0
+}
+
+type cachedQuota struct {
+	quota Quota
+	read  time.Time
+}
+
+// usage is the number of records of a database and the size of their keys and values
+type usage struct {
+	keys    int64
+	bytes   int64
+	counted time.Time
+}
+
+type quotas struct {
+	sync.Mutex
+	quotas map[string]cachedQuota
+	usage  map[string]*usage
+}
+
+// quota returns the quota of the database
+func (h *Store) quota(database string) Quota {
+	h.quotas.Lock()
+	defer h.quotas.Unlock()
+
+	if q, ok := h.quotas.quotas[database]; ok && time.Since(q.read) < quotaTTL {
+		return q.quota
+	}
+
+	q := readQuota(database)
+	if h.quotas.quotas == nil {
+		h.quotas.quotas = make(map[string]cachedQuota)
+	}
+	h.quotas.quotas[database] = cachedQuota{quota: q, read: time.Now()}
+	return q
+}
+
+func readQuota(database string) Quota {
+	var q Quota
+	if config.DefaultConfig == nil {
+		return q
+	}
+
+	for _, path := range []string{"store.quotas." + database, "store.quotas.default"} {
+		v, err := config.Get(path)
+		if err != nil || !v.Exists() {
+			continue
+		}
+		v.Scan(&q)
+		break
+	}
+	return q
+}
+
+// usage returns the usage of the database, counting it if it isn't tracked. The caller must
+// hold the quotas lock.
+func (h *Store) usage(database string) (*usage, error) {
+	if u, ok := h.quotas.usage[database]; ok && time.Since(u.counted) < usageTTL {
+		return u, nil
+	}
+
+	u, err := countUsage(database)
+	if err != nil {
+		return nil, err
+	}
+	if h.quotas.usage == nil {
+		h.quotas.usage = make(map[string]*usage)
+	}
+	h.quotas.usage[database] = u
+	return u, nil
+}
+
+// countUsage reads all the records of the tables of the database
+func countUsage(database string) (*usage, error) {
+	prefix := fmt.Sprintf("tables/%v/", database)
+	tables, err := store.DefaultStore.Read(prefix, store.ReadPrefix(), store.ReadFrom(defaultDatabase, internalTable))
+	if err != nil {
+		return nil, err
+	}
+
+	u := &usage{counted: time.Now()}
+	for _, t := range tables {
+		table := strings.TrimPrefix(t.Key, prefix)
+		recs, err := store.DefaultStore.Read("", store.ReadPrefix(), store.ReadFrom(database, table))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range recs {
+			u.keys++
+			u.bytes += recordSize(r)
+		}
+	}
+	return u, nil
+}
+
+func recordSize(r *store.Record) int64 {
+	return int64(len(r.Key) + len(r.Value))
+}
+
+// reserve checks the write of the record is within the quota of the database and adds it to
+// the usage. The returned func removes it again if the write then fails.
+func (h *Store) reserve(database, table string, r *store.Record) (func(), error) {
+	q := h.quota(database)
+	if !q.limited() {
+		return func() {}, nil
+	}
+
+	h.quotas.Lock()
+	defer h.quotas.Unlock()
+
+	u, err := h.usage(database)
+	if err != nil {
+		return nil, err
+	}
+
+	// a record being overwritten doesn't add a key, only the difference in size
+	keys, bytes := int64(1), recordSize(r)
+	recs, err := store.DefaultStore.Read(r.Key, store.ReadFrom(database, table))
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+	if len(recs) > 0 {
+		keys, bytes = 0, bytes-recordSize(recs[0])
+	}
+
+	if q.MaxKeys > 0 && keys > 0 && u.keys+keys > q.MaxKeys {
+		return nil, store.ErrQuotaExceeded
+	}
+	if q.MaxBytes > 0 && bytes > 0 && u.bytes+bytes > q.MaxBytes {
+		return nil, store.ErrQuotaExceeded
+	}
+
+	u.keys += keys
+	u.bytes += bytes
+	return func() {
+		h.quotas.Lock()
+		defer h.quotas.Unlock()
+		u.keys -= keys
+		u.bytes -= bytes
+	}, nil
+}
+
+// forgetUsage drops the usage of the database so it's counted again on the next write
+func (h *Store) forgetUsage(database string) {
+	h.quotas.Lock()
+	defer h.quotas.Unlock()
+	delete(h.quotas.usage, database)
+}
+
+// Usage returns the number and size of the records in a database along with its quota
+func (h *Store) Usage(ctx context.Context, req *pb.UsageRequest, rsp *pb.UsageResponse) error {
+	// set defaults
+	if err := h.tenant(ctx, &req.Database, "store.Store.Usage"); err != nil {
+		return err
+	}
+
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, req.Database, "store.Store.Usage"); err != nil {
+		return err
+	}
+
+	q := h.quota(req.Database)
+
+	h.quotas.Lock()
+	defer h.quotas.Unlock()
+
+	u, err := h.usage(req.Database)
+	if err != nil {
+		return errors.InternalServerError("store.Store.Usage", err.Error())
+	}
+
+	rsp.Keys = u.keys
+	rsp.Bytes = u.bytes
+	rsp.MaxKeys = q.MaxKeys
+	rsp.MaxBytes = q.MaxBytes
+	return nil
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	pb "github.com/micro/micro/v3/proto/store"
+	"github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/util/auth/namespace"
+)
+
+// Stats returns the number and size of the records in each table of a database, and when the
+// records which expire do so, so operators can see what's using space
+func (h *Store) Stats(ctx context.Context, req *pb.StatsRequest, rsp *pb.StatsResponse) error {
+	// set defaults
+	if err := h.tenant(ctx, &req.Database, "store.Store.Stats"); err != nil {
+		return err
+	}
+
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, req.Database, "store.Store.Stats"); err != nil {
+		return err
+	}
+
+	tables := []string{req.Table}
+	if len(req.Table) == 0 {
+		prefix := fmt.Sprintf("tables/%v/", req.Database)
+		recs, err := store.DefaultStore.Read(prefix, store.ReadPrefix(), store.ReadFrom(defaultDatabase, internalTable))
+		if err != nil {
+			return errors.InternalServerError("store.Store.Stats", err.Error())
+		}
+		tables = make([]string, len(recs))
+		for i, r := range recs {
+			tables[i] = strings.TrimPrefix(r.Key, prefix)
+		}
+	}
+
+	rsp.Tables = make([]*pb.TableStats, 0, len(tables))
+	for _, t := range tables {
+		s, err := tableStats(ctx, req.Database, t)
+		if err != nil {
+			return errors.InternalServerError("store.Store.Stats", err.Error())
+		}
+		rsp.Tables = append(rsp.Tables, s)
+	}
+	return nil
+}
+
+// tableStats iterates over the records of the table, so large tables aren't held in memory
+func tableStats(ctx context.Context, database, table string) (*pb.TableStats, error) {
+	it, err := store.Iterate(ctx, "", store.ReadPrefix(), store.ReadFrom(database, table))
+	if err == store.ErrNotFound {
+		return &pb.TableStats{Database: database, Table: table}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	s := &pb.TableStats{Database: database, Table: table}
+	now := time.Now()
+	for {
+		r, err := it.Next()
+		if err == io.EOF {
+			return s, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		s.Keys++
+		s.Bytes += recordSize(r)
+		if r.Expiry == 0 {
+			continue
+		}
+		expiry := now.Add(r.Expiry).Unix()
+		if s.OldestExpiry == 0 || expiry < s.OldestExpiry {
+			s.OldestExpiry = expiry
+		}
+		if expiry > s.NewestExpiry {
+			s.NewestExpiry = expiry
+		}
+	}
+}
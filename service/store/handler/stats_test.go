@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/micro/micro/v3/proto/store"
+	"github.com/micro/micro/v3/service/auth"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	defer func(s store.Store) { store.DefaultStore = s }(store.DefaultStore)
+	store.DefaultStore = memory.NewStore()
+
+	ctx := auth.ContextWithAccount(context.TODO(), &auth.Account{
+		ID: "admin", Issuer: defaultDatabase, Type: "user", Scopes: []string{"admin"},
+	})
+	h := &Store{Stores: make(map[string]bool)}
+
+	for _, table := range []string{"users", "sessions"} {
+		assert.NoError(t, h.setupTable("foo", table))
+	}
+	assert.NoError(t, store.DefaultStore.Write(&store.Record{Key: "alice", Value: []byte("1234")}, store.WriteTo("foo", "users")))
+	assert.NoError(t, store.DefaultStore.Write(&store.Record{Key: "bob", Value: []byte("56")}, store.WriteTo("foo", "users")))
+	assert.NoError(t, store.DefaultStore.Write(&store.Record{Key: "a", Expiry: time.Minute}, store.WriteTo("foo", "sessions")))
+	assert.NoError(t, store.DefaultStore.Write(&store.Record{Key: "b", Expiry: time.Hour}, store.WriteTo("foo", "sessions")))
+
+	rsp := &pb.StatsResponse{}
+	assert.NoError(t, h.Stats(ctx, &pb.StatsRequest{Database: "foo"}, rsp))
+	stats := make(map[string]*pb.TableStats)
+	for _, s := range rsp.Tables {
+		assert.Equal(t, "foo", s.Database)
+		stats[s.Table] = s
+	}
+	assert.Len(t, stats, 2)
+
+	users := stats["users"]
+	assert.Equal(t, int64(2), users.Keys)
+	assert.Equal(t, int64(len("alice1234bob56")), users.Bytes)
+	assert.Zero(t, users.OldestExpiry)
+	assert.Zero(t, users.NewestExpiry)
+
+	sessions := stats["sessions"]
+	assert.Equal(t, int64(2), sessions.Keys)
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), sessions.OldestExpiry, 5)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), sessions.NewestExpiry, 5)
+
+	rsp = &pb.StatsResponse{}
+	assert.NoError(t, h.Stats(ctx, &pb.StatsRequest{Database: "foo", Table: "users"}, rsp))
+	assert.Len(t, rsp.Tables, 1)
+	assert.Equal(t, int64(2), rsp.Tables[0].Keys)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"io"
+
+	pb "github.com/micro/micro/v3/proto/store"
+	"github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/wrapper/trace"
+	"github.com/micro/micro/v3/util/auth/namespace"
+)
+
+const (
+	// streamBatch is the number of records read from the store and sent in each message
+	// of a streamed read
+	streamBatch = 100
+	// listBatch is the number of keys sent in each message of a list
+	listBatch = 1000
+)
+
+// ReadStream streams the records of a read in batches. Prefix and suffix reads are read from
+// the store a page at a time, so large reads are never held in memory or sent in one message.
+// Each message has the cursor to continue the read from after its records.
+func (h *Store) ReadStream(ctx context.Context, req *pb.ReadRequest, stream pb.Store_ReadStreamStream) error {
+	// set defaults
+	if req.Options == nil {
+		req.Options = &pb.ReadOptions{}
+	}
+	if err := h.tenant(ctx, &req.Options.Database, "store.Store.ReadStream"); err != nil {
+		return err
+	}
+	if len(req.Options.Table) == 0 {
+		req.Options.Table = defaultTable
+	}
+
+	// authorize the request
+	if err := namespace.AuthorizeAdmin(ctx, req.Options.Database, "store.Store.ReadStream"); err != nil {
+		return err
+	}
+
+	// setup the store
+	if err := h.setupTable(req.Options.Database, req.Options.Table); err != nil {
+		return errors.InternalServerError("store.Store.ReadStream", err.Error())
+	}
+
+	send := func(recs []*store.Record, cursor string) error {
+		rsp := &pb.ReadResponse{Cursor: cursor}
+		for _, r := range recs {
+			rsp.Records = append(rsp.Records, serializeRecord(r))
+		}
+		if err := stream.Send(rsp); err != nil && err != io.EOF {
+			return errors.InternalServerError("store.Store.ReadStream", err.Error())
+		}
+		return nil
+	}
+
+	read := func(opts ...store.ReadOption) ([]*store.Record, error) {
+		vals, err := trace.WithContext(ctx, store.DefaultStore).Read(req.Key, append(readOptions(req.Options), opts...)...)
+		if err == store.ErrNotFound {
+			return nil, errors.NotFound("store.Store.ReadStream", err.Error())
+		} else if err == store.ErrInvalidCursor || err == store.ErrInvalidPattern {
+			return nil, errors.BadRequest("store.Store.ReadStream", err.Error())
+		} else if err != nil {
+			return nil, errors.InternalServerError("store.Store.ReadStream", err.Error())
+		}
+		return vals, nil
+	}
+
+	// a single key, or records sorted by expiry which can't be paged through, are read at
+	// once and sent in batches
+	if (!req.Options.Prefix && !req.Options.Suffix) || req.Options.OrderBy == string(store.OrderByExpiry) {
+		var opts []store.ReadOption
+		if req.Options.Limit > 0 {
+			opts = append(opts, store.ReadLimit(uint(req.Options.Limit)))
+		}
+		if req.Options.Offset > 0 {
+			opts = append(opts, store.ReadOffset(uint(req.Options.Offset)))
+		}
+		vals, err := read(opts...)
+		if err != nil {
+			return err
+		}
+		for len(vals) > 0 {
+			n := streamBatch
+			if n > len(vals) {
+				n = len(vals)
+			}
+			if err := send(vals[:n], ""); err != nil {
+				return err
+			}
+			vals = vals[n:]
+		}
+		return nil
+	}
+
+	cursor := req.Options.Cursor
+	offset := uint(req.Options.Offset)
+	remaining := uint(req.Options.Limit)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		limit := uint(streamBatch)
+		if req.Options.Limit > 0 && remaining < limit {
+			limit = remaining
+		}
+		opts := []store.ReadOption{store.ReadLimit(limit), store.ReadCursor(&cursor)}
+		// the offset only applies to the first page
+		if offset > 0 {
+			opts = append(opts, store.ReadOffset(offset))
+			offset = 0
+		}
+
+		vals, err := read(opts...)
+		if err != nil {
+			return err
+		}
+		if req.Options.Limit > 0 {
+			remaining -= uint(len(vals))
+		}
+		if err := send(vals, cursor); err != nil {
+			return err
+		}
+
+		if len(cursor) == 0 || (req.Options.Limit > 0 && remaining == 0) {
+			return nil
+		}
+	}
+}
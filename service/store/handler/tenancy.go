@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/auth"
+	"github.com/micro/micro/v3/service/errors"
+)
+
+// Tenancy is how the database of a request is chosen from the namespace of the account
+// making it, so tenants' records are kept apart without clients setting the database
+type Tenancy string
+
+const (
+	// TenancyNone uses the database of the request, or the default database if it's blank
+	TenancyNone = Tenancy("none")
+	// TenancyNamespace uses the database of the request, or the namespace of the account
+	// if it's blank
+	TenancyNamespace = Tenancy("namespace")
+	// TenancyStrict always uses the namespace of the account. Requests for another database
+	// are forbidden, including those of the admins of the default namespace.
+	TenancyStrict = Tenancy("strict")
+)
+
+// tenant sets the database of a request made with the context
+func (h *Store) tenant(ctx context.Context, database *string, method string) error {
+	acc, ok := auth.AccountFromContext(ctx)
+	if !ok || len(acc.Issuer) == 0 || h.Tenancy == TenancyNone || len(h.Tenancy) == 0 {
+		if len(*database) == 0 {
+			*database = defaultDatabase
+		}
+		return nil
+	}
+
+	if h.Tenancy == TenancyStrict && len(*database) > 0 && *database != acc.Issuer {
+		return errors.Forbidden(method, "access denied to database %s", *database)
+	}
+	if len(*database) == 0 {
+		*database = acc.Issuer
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/micro/v3/service/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	foo := auth.ContextWithAccount(context.TODO(), &auth.Account{ID: "foo", Issuer: "foo", Type: "user"})
+
+	tcs := []struct {
+		name     string
+		tenancy  Tenancy
+		ctx      context.Context
+		database string
+		expect   string
+		err      bool
+	}{
+		{name: "NoneBlank", tenancy: TenancyNone, ctx: foo, expect: defaultDatabase},
+		{name: "NoneSet", tenancy: TenancyNone, ctx: foo, database: "bar", expect: "bar"},
+		{name: "NamespaceBlank", tenancy: TenancyNamespace, ctx: foo, expect: "foo"},
+		{name: "NamespaceSet", tenancy: TenancyNamespace, ctx: foo, database: "bar", expect: "bar"},
+		{name: "NamespaceNoAccount", tenancy: TenancyNamespace, ctx: context.TODO(), expect: defaultDatabase},
+		{name: "StrictBlank", tenancy: TenancyStrict, ctx: foo, expect: "foo"},
+		{name: "StrictOwn", tenancy: TenancyStrict, ctx: foo, database: "foo", expect: "foo"},
+		{name: "StrictOther", tenancy: TenancyStrict, ctx: foo, database: "bar", err: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &Store{Tenancy: tc.tenancy}
+			db := tc.database
+			err := h.tenant(tc.ctx, &db, "store.Store.Read")
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, db)
+		})
+	}
+}
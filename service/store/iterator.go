@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"io"
+)
+
+// Iterator returns the records of a read one at a time, see Iterate
+type Iterator interface {
+	// Next returns the next record, or io.EOF once there are no more
+	Next() (*Record, error)
+	// Close stops the read
+	Close() error
+}
+
+// Iterable is implemented by stores which can return the records of a read as they're read,
+// so large reads don't have to be held in memory at once
+type Iterable interface {
+	// Iterate returns an iterator over the records the read would return. The read is
+	// stopped once ctx is done.
+	Iterate(ctx context.Context, key string, opts ...ReadOption) (Iterator, error)
+}
+
+// Iterate returns an iterator over the records of the read. If the store doesn't implement
+// Iterable the records are read at once.
+func Iterate(ctx context.Context, key string, opts ...ReadOption) (Iterator, error) {
+	if i, ok := DefaultStore.(Iterable); ok {
+		return i.Iterate(ctx, key, opts...)
+	}
+	recs, err := DefaultStore.Read(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator(recs), nil
+}
+
+// NewIterator returns an iterator over the records
+func NewIterator(recs []*Record) Iterator {
+	return &sliceIterator{recs: recs}
+}
+
+type sliceIterator struct {
+	recs []*Record
+}
+
+func (s *sliceIterator) Next() (*Record, error) {
+	if len(s.recs) == 0 {
+		return nil, io.EOF
+	}
+	r := s.recs[0]
+	s.recs = s.recs[1:]
+	return r, nil
+}
+
+func (s *sliceIterator) Close() error {
+	s.recs = nil
+	return nil
+}
@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+)
+
+// usage of a record held in the store
+type usage struct {
+	prefix, key string
+	size        int64
+
+	// used by the LRU policy
+	el *list.Element
+	// used by the LFU policy, the least recently used of those used least is evicted first
+	hits, used uint64
+	index      int
+}
+
+// limits tracks the records held in the store so they can be evicted, or writes rejected,
+// once its limits are reached
+type limits struct {
+	sync.Mutex
+
+	maxKeys  int
+	maxBytes int64
+	policy   EvictionPolicy
+
+	bytes int64
+	items map[string]*usage
+	// the most recently used record is at the front
+	recent *list.List
+	// the least frequently used record is at the top
+	frequent usageHeap
+	// incremented on every use for the LFU policy
+	clock uint64
+}
+
+func newLimits(maxKeys int, maxBytes int64, policy EvictionPolicy) *limits {
+	if len(policy) == 0 {
+		policy = EvictLRU
+	}
+	return &limits{
+		maxKeys:  maxKeys,
+		maxBytes: maxBytes,
+		policy:   policy,
+		items:    make(map[string]*usage),
+		recent:   list.New(),
+	}
+}
+
+func id(prefix, key string) string {
+	return prefix + "/" + key
+}
+
+// exceeds returns true if setting the record would go over the limits
+func (l *limits) exceeds(prefix, key string, size int64) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	keys, bytes := len(l.items), l.bytes+size
+	if u, ok := l.items[id(prefix, key)]; ok {
+		bytes -= u.size
+	} else {
+		keys++
+	}
+	return l.over(keys, bytes)
+}
+
+func (l *limits) over(keys int, bytes int64) bool {
+	return (l.maxKeys > 0 && keys > l.maxKeys) || (l.maxBytes > 0 && bytes > l.maxBytes)
+}
+
+// fits returns ErrFull if the records can't be set, either because one is larger than the
+// limit or they'd go over the limits and writes are rejected rather than evicting records.
+// The sizes of the records which are being deleted are negative.
+func (l *limits) fits(prefix string, sizes map[string]int64) error {
+	l.Lock()
+	defer l.Unlock()
+
+	keys, bytes := len(l.items), l.bytes
+	for key, size := range sizes {
+		if l.maxBytes > 0 && size > l.maxBytes {
+			return ErrFull
+		}
+		if u, ok := l.items[id(prefix, key)]; ok {
+			keys--
+			bytes -= u.size
+		}
+		if size >= 0 {
+			keys++
+			bytes += size
+		}
+	}
+	if l.policy == RejectWrites && l.over(keys, bytes) {
+		return ErrFull
+	}
+	return nil
+}
+
+// add the record as the most recently used and return those to evict. ErrFull is returned
+// if it can't be added and reject is true, otherwise it's added even if it goes over
+// the limits.
+func (l *limits) add(prefix, key string, size int64, reject bool) ([]*usage, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	u, ok := l.items[id(prefix, key)]
+	if reject {
+		keys, bytes := len(l.items)+1, l.bytes+size
+		if ok {
+			keys, bytes = keys-1, bytes-u.size
+		}
+		if l.maxBytes > 0 && size > l.maxBytes {
+			return nil, ErrFull
+		}
+		if l.policy == RejectWrites && l.over(keys, bytes) {
+			return nil, ErrFull
+		}
+	}
+
+	if ok {
+		l.bytes += size - u.size
+		u.size = size
+		l.use(u)
+	} else {
+		u = &usage{prefix: prefix, key: key, size: size}
+		l.items[id(prefix, key)] = u
+		l.bytes += size
+		switch l.policy {
+		case EvictLRU:
+			u.el = l.recent.PushFront(u)
+		case EvictLFU:
+			l.clock++
+			u.hits, u.used = 1, l.clock
+			heap.Push(&l.frequent, u)
+		}
+	}
+
+	if l.policy == RejectWrites {
+		return nil, nil
+	}
+
+	var evicted []*usage
+	for l.over(len(l.items), l.bytes) {
+		if len(l.items) == 1 {
+			// only the record being added is left
+			break
+		}
+		var victim *usage
+		if l.policy == EvictLFU {
+			victim = l.frequent.leastUsed(u)
+		} else {
+			victim = l.recent.Back().Value.(*usage)
+		}
+		l.removeUsage(victim)
+		evicted = append(evicted, victim)
+	}
+	return evicted, nil
+}
+
+// touch marks the record as used
+func (l *limits) touch(prefix, key string) {
+	l.Lock()
+	defer l.Unlock()
+
+	if u, ok := l.items[id(prefix, key)]; ok {
+		l.use(u)
+	}
+}
+
+func (l *limits) use(u *usage) {
+	switch l.policy {
+	case EvictLRU:
+		l.recent.MoveToFront(u.el)
+	case EvictLFU:
+		l.clock++
+		u.hits++
+		u.used = l.clock
+		heap.Fix(&l.frequent, u.index)
+	}
+}
+
+// remove the record once it's deleted or expired
+func (l *limits) remove(prefix, key string) {
+	l.Lock()
+	defer l.Unlock()
+
+	if u, ok := l.items[id(prefix, key)]; ok {
+		l.removeUsage(u)
+	}
+}
+
+func (l *limits) removeUsage(u *usage) {
+	delete(l.items, id(u.prefix, u.key))
+	l.bytes -= u.size
+	switch l.policy {
+	case EvictLRU:
+		l.recent.Remove(u.el)
+	case EvictLFU:
+		heap.Remove(&l.frequent, u.index)
+	}
+}
+
+// reset forgets all the records once the store is flushed
+func (l *limits) reset() {
+	l.Lock()
+	defer l.Unlock()
+
+	l.bytes = 0
+	l.items = make(map[string]*usage)
+	l.recent.Init()
+	l.frequent = nil
+}
+
+// usageHeap orders records by how frequently they're used
+type usageHeap []*usage
+
+func (h usageHeap) Len() int { return len(h) }
+
+func (h usageHeap) Less(i, j int) bool {
+	if h[i].hits != h[j].hits {
+		return h[i].hits < h[j].hits
+	}
+	return h[i].used < h[j].used
+}
+
+func (h usageHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+// leastUsed returns the least frequently used record other than u, which is new so would
+// otherwise always be used least. The next least used is one of the children of the top.
+func (h usageHeap) leastUsed(u *usage) *usage {
+	if h[0] != u {
+		return h[0]
+	}
+	if len(h) == 2 || h.Less(1, 2) {
+		return h[1]
+	}
+	return h[2]
+}
+
+func (h *usageHeap) Push(x interface{}) {
+	u := x.(*usage)
+	u.index = len(*h)
+	*h = append(*h, u)
+}
+
+func (h *usageHeap) Pop() interface{} {
+	old := *h
+	u := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return u
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func write(t *testing.T, s store.Store, keys ...string) {
+	for _, k := range keys {
+		assert.NoError(t, s.Write(&store.Record{Key: k, Value: []byte("foo")}))
+	}
+}
+
+func held(s store.Store) []string {
+	keys, _ := s.List()
+	return keys
+}
+
+func TestEvictLRU(t *testing.T) {
+	s := NewStore(MaxKeys(2))
+	write(t, s, "a", "b")
+	_, err := s.Read("a")
+	assert.NoError(t, err)
+	write(t, s, "c")
+	assert.Equal(t, []string{"a", "c"}, held(s))
+
+	// the limit is across tables
+	assert.NoError(t, s.Write(&store.Record{Key: "d", Value: []byte("foo")}, store.WriteTo("other", "other")))
+	assert.Equal(t, []string{"c"}, held(s))
+}
+
+func TestEvictLFU(t *testing.T) {
+	s := NewStore(MaxKeys(2), Eviction(EvictLFU))
+	write(t, s, "a", "b")
+	for i := 0; i < 3; i++ {
+		_, err := s.Read("b")
+		assert.NoError(t, err)
+	}
+	_, err := s.Read("a")
+	assert.NoError(t, err)
+	write(t, s, "c")
+	assert.Equal(t, []string{"b", "c"}, held(s))
+}
+
+func TestRejectWrites(t *testing.T) {
+	s := NewStore(MaxBytes(8), Eviction(RejectWrites))
+	write(t, s, "a", "b")
+	assert.Equal(t, ErrFull, s.Write(&store.Record{Key: "c", Value: []byte("foo")}))
+	// overwriting a record with one of the same size still fits
+	write(t, s, "a")
+	assert.Equal(t, []string{"a", "b"}, held(s))
+
+	// expired records are removed to make room
+	assert.NoError(t, s.Delete("b"))
+	assert.NoError(t, s.Write(&store.Record{Key: "b", Value: []byte("foo"), Expiry: time.Millisecond}))
+	time.Sleep(5 * time.Millisecond)
+	write(t, s, "c")
+	assert.Equal(t, []string{"a", "c"}, held(s))
+
+	// transactions are applied together or not at all
+	err := s.(store.Transactional).Txn(context.Background(), func(tx store.Tx) error {
+		assert.NoError(t, tx.Delete("a"))
+		assert.NoError(t, tx.Write(&store.Record{Key: "d", Value: []byte("foo")}))
+		return tx.Write(&store.Record{Key: "e", Value: []byte("foo")})
+	})
+	assert.Equal(t, ErrFull, err)
+	assert.Equal(t, []string{"a", "c"}, held(s))
+}
+
+func TestMaxBytes(t *testing.T) {
+	s := NewStore(MaxBytes(8))
+	assert.Equal(t, ErrFull, s.Write(&store.Record{Key: "a", Value: []byte("foobarbaz")}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := s.(store.Watcher).Watch(ctx, "a")
+	assert.NoError(t, err)
+
+	write(t, s, "a", "b", "c")
+	assert.Equal(t, []string{"b", "c"}, held(s))
+	for _, typ := range []store.EventType{store.EventCreate, store.EventDelete} {
+		select {
+		case ev := <-events:
+			assert.Equal(t, typ, ev.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a %s event", typ)
+		}
+	}
+
+	// lowering the limits evicts the records over them
+	assert.NoError(t, s.Init(MaxKeys(1)))
+	assert.Equal(t, []string{"c"}, held(s))
+}
@@ -16,17 +16,29 @@
 package memory
 
 import (
+	"context"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/store"
-	"github.com/patrickmn/go-cache"
+	"github.com/micro/micro/v3/service/store/watch"
 	"github.com/pkg/errors"
 )
 
+var (
+	// ErrFull is returned when a write would take the store over its limits and the
+	// RejectWrites policy is used
+	ErrFull = errors.New("memory store is full")
+
+	// cleanupInterval is how often the expired records are removed
+	cleanupInterval = 5 * time.Minute
+)
+
 // NewStore returns a memory store
 func NewStore(opts ...store.Option) store.Store {
 	s := &memoryStore{
@@ -34,11 +46,17 @@ func NewStore(opts ...store.Option) store.Store {
 			Database: "micro",
 			Table:    "micro",
 		},
-		stores: map[string]*cache.Cache{}, // cache.New(cache.NoExpiration, 5*time.Minute),
+		tables: make(map[string]*table),
+		exit:   make(chan bool),
 	}
 	for _, o := range opts {
 		o(&s.options)
 	}
+	s.configure()
+	if err := s.configureSnapshots(); err != nil {
+		logger.Errorf("Error restoring memory store snapshot: %v", err)
+	}
+	go s.cleanupLoop()
 	return s
 }
 
@@ -46,7 +64,21 @@ type memoryStore struct {
 	sync.RWMutex
 	options store.Options
 
-	stores map[string]*cache.Cache
+	tables map[string]*table
+
+	// held for reading by every operation and for writing while a transaction runs,
+	// so it can't be interleaved with other operations
+	mtx sync.RWMutex
+
+	watchers watch.Hub
+
+	// tracks the records held if the size of the store is limited, nil otherwise
+	limits *limits
+	// writes the records to a file if a snapshot path is set, nil otherwise
+	snapshots *snapshotter
+
+	exit chan bool
+	once sync.Once
 }
 
 type storeRecord struct {
@@ -54,6 +86,7 @@ type storeRecord struct {
 	value     []byte
 	metadata  map[string]interface{}
 	expiresAt time.Time
+	version   uint64
 }
 
 func (m *memoryStore) prefix(database, table string) string {
@@ -66,56 +99,166 @@ func (m *memoryStore) prefix(database, table string) string {
 	return filepath.Join(database, table)
 }
 
-func (m *memoryStore) getStore(prefix string) *cache.Cache {
+// configure the limits of the store from its options
+func (m *memoryStore) configure() {
+	var maxKeys int
+	var maxBytes int64
+	var policy EvictionPolicy
+	if ctx := m.options.Context; ctx != nil {
+		maxKeys, _ = ctx.Value(maxKeysKey{}).(int)
+		maxBytes, _ = ctx.Value(maxBytesKey{}).(int64)
+		policy, _ = ctx.Value(evictionKey{}).(EvictionPolicy)
+	}
+
+	if maxKeys == 0 && maxBytes == 0 {
+		m.limits = nil
+		return
+	}
+	l := newLimits(maxKeys, maxBytes, policy)
+	if old := m.limits; old != nil && old.maxKeys == l.maxKeys && old.maxBytes == l.maxBytes && old.policy == l.policy {
+		return
+	}
+
+	// track the records already held, evicting them if they're over the new limits
+	var evicted []*usage
+	for prefix, t := range m.getTables() {
+		t.each(func(key string, r *storeRecord) error {
+			e, _ := l.add(prefix, key, int64(len(key)+len(r.value)), false)
+			evicted = append(evicted, e...)
+			return nil
+		})
+	}
+	m.limits = l
+	for _, u := range evicted {
+		m.delete(u.prefix, u.key)
+	}
+}
+
+// cleanupLoop periodically removes the expired records, which are otherwise only
+// hidden from reads, emitting expiry events for them
+func (m *memoryStore) cleanupLoop() {
+	t := time.NewTicker(cleanupInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-m.exit:
+			return
+		case <-t.C:
+			m.deleteExpired()
+		}
+	}
+}
+
+// deleteExpired removes the expired records from all the tables
+func (m *memoryStore) deleteExpired() {
+	for prefix, t := range m.getTables() {
+		var expired []string
+		t.deleteExpired(func(key string) {
+			if m.limits != nil {
+				m.limits.remove(prefix, key)
+			}
+			m.publish(store.EventExpire, prefix, &store.Record{Key: key})
+			expired = append(expired, key)
+		})
+
+		// the stream may block so it's published to once the shards are unlocked
+		if len(expired) == 0 {
+			continue
+		}
+		if err := store.PublishExpired(m.options, filepath.Dir(prefix), filepath.Base(prefix), expired...); err != nil {
+			logger.Errorf("Error publishing expired records of %s: %v", prefix, err)
+		}
+	}
+}
+
+// getTables returns a copy of the tables so they can be iterated without holding the lock
+func (m *memoryStore) getTables() map[string]*table {
 	m.RLock()
-	store := m.stores[prefix]
+	defer m.RUnlock()
+
+	tables := make(map[string]*table, len(m.tables))
+	for prefix, t := range m.tables {
+		tables[prefix] = t
+	}
+	return tables
+}
+
+func (m *memoryStore) getTable(prefix string) *table {
+	m.RLock()
+	t := m.tables[prefix]
 	m.RUnlock()
-	if store == nil {
+	if t == nil {
 		m.Lock()
-		if m.stores[prefix] == nil {
-			m.stores[prefix] = cache.New(cache.NoExpiration, 5*time.Minute)
+		if m.tables[prefix] == nil {
+			m.tables[prefix] = newTable()
 		}
-		store = m.stores[prefix]
+		t = m.tables[prefix]
 		m.Unlock()
 	}
-	return store
+	return t
 }
 
 func (m *memoryStore) get(prefix, key string) (*store.Record, error) {
-	var storedRecord *storeRecord
-	r, found := m.getStore(prefix).Get(key)
-	if !found {
+	s := m.getTable(prefix).shard(key)
+	s.RLock()
+	defer s.RUnlock()
+
+	storedRecord := s.get(key, time.Now())
+	if storedRecord == nil {
 		return nil, store.ErrNotFound
 	}
-
-	storedRecord, ok := r.(*storeRecord)
-	if !ok {
-		return nil, errors.New("Retrieved a non *storeRecord from the cache")
+	if m.limits != nil {
+		m.limits.touch(prefix, key)
 	}
 
-	// Copy the record on the way out
+	return storedRecord.record(), nil
+}
+
+// record returns a copy of the stored record
+func (r *storeRecord) record() *store.Record {
 	newRecord := &store.Record{}
-	newRecord.Key = strings.TrimPrefix(storedRecord.key, prefix+"/")
-	newRecord.Value = make([]byte, len(storedRecord.value))
+	newRecord.Key = r.key
+	newRecord.Value = make([]byte, len(r.value))
 	newRecord.Metadata = make(map[string]interface{})
 
 	// copy the value into the new record
-	copy(newRecord.Value, storedRecord.value)
+	copy(newRecord.Value, r.value)
 
 	// check if we need to set the expiry
-	if !storedRecord.expiresAt.IsZero() {
-		newRecord.Expiry = time.Until(storedRecord.expiresAt)
+	if !r.expiresAt.IsZero() {
+		newRecord.Expiry = time.Until(r.expiresAt)
 	}
 
 	// copy in the metadata
-	for k, v := range storedRecord.metadata {
+	for k, v := range r.metadata {
 		newRecord.Metadata[k] = v
 	}
 
-	return newRecord, nil
+	newRecord.Version = r.version
+
+	return newRecord
+}
+
+// keepVersions returns true if the versions of records are set by the writer
+func (m *memoryStore) keepVersions() bool {
+	if m.options.Context == nil {
+		return false
+	}
+	keep, _ := m.options.Context.Value(keepVersionsKey{}).(bool)
+	return keep
 }
 
-func (m *memoryStore) set(prefix string, r *store.Record) {
+// set the record if the conditions of the write are met and return its new version.
+// If reject is true ErrFull is returned when the record doesn't fit within the limits
+// of the store.
+func (m *memoryStore) set(prefix string, r *store.Record, opts store.WriteOptions, reject bool) (uint64, error) {
+	size := int64(len(r.Key) + len(r.Value))
+	if m.limits != nil && m.limits.exceeds(prefix, r.Key, size) {
+		// make room for the record
+		m.deleteExpired()
+	}
+
 	// copy the incoming record and then
 	// convert the expiry in to a hard timestamp
 	i := &storeRecord{}
@@ -136,20 +279,90 @@ func (m *memoryStore) set(prefix string, r *store.Record) {
 		i.metadata[k] = v
 	}
 
-	m.getStore(prefix).Set(r.Key, i, r.Expiry)
+	s := m.getTable(prefix).shard(r.Key)
+	s.Lock()
+	old := s.get(r.Key, time.Now())
+
+	// check the conditions of the write
+	if (opts.IfNotExists && old != nil) || (opts.IfVersion > 0 && (old == nil || old.version != opts.IfVersion)) {
+		s.Unlock()
+		return 0, store.ErrConflict
+	}
+
+	var evicted []*usage
+	if m.limits != nil {
+		var err error
+		if evicted, err = m.limits.add(prefix, r.Key, size, reject); err != nil {
+			s.Unlock()
+			return 0, err
+		}
+	}
+
+	// set the version
+	switch {
+	case m.keepVersions():
+		i.version = r.Version
+	case old != nil:
+		i.version = old.version + 1
+	default:
+		i.version = 1
+	}
+
+	s.records[r.Key] = i
+
+	// published while the shard is locked so the events for a key are in order
+	if m.watchers.Watching() {
+		typ := store.EventCreate
+		if old != nil {
+			typ = store.EventUpdate
+		}
+		rec := copyRecord(r)
+		rec.Version = i.version
+		m.publish(typ, prefix, rec)
+	}
+	s.Unlock()
+
+	for _, u := range evicted {
+		m.delete(u.prefix, u.key)
+	}
+
+	return i.version, nil
 }
 
 func (m *memoryStore) delete(prefix, key string) {
-	m.getStore(prefix).Delete(key)
-}
+	s := m.getTable(prefix).shard(key)
+	s.Lock()
+	defer s.Unlock()
 
-func (m *memoryStore) list(prefix string, order store.Order, limit, offset uint, prefixFilter, suffixFilter string) []string {
-	// TODO: sort they keys
-	var allItems []string
+	r, found := s.records[key]
+	if !found {
+		return
+	}
+	delete(s.records, key)
+	if m.limits != nil {
+		m.limits.remove(prefix, key)
+	}
 
-	for k := range m.getStore(prefix).Items() {
-		allItems = append(allItems, k)
+	if r.expired(time.Now()) {
+		m.publish(store.EventExpire, prefix, &store.Record{Key: key})
+	} else {
+		m.publish(store.EventDelete, prefix, &store.Record{Key: key})
 	}
+}
+
+func (m *memoryStore) publish(typ store.EventType, prefix string, r *store.Record) {
+	m.watchers.Publish(&store.Event{
+		Type:      typ,
+		Database:  filepath.Dir(prefix),
+		Table:     filepath.Base(prefix),
+		Record:    r,
+		Timestamp: time.Now(),
+	})
+}
+
+// list the keys matching the filters, after is the key of the cursor to continue from
+func (m *memoryStore) list(prefix string, order store.Order, limit, offset uint, prefixFilter, suffixFilter, after string, match *regexp.Regexp) []string {
+	allItems := m.getTable(prefix).keys()
 
 	// sort in ascending order
 	if order == store.OrderDesc {
@@ -170,6 +383,12 @@ func (m *memoryStore) list(prefix string, order store.Order, limit, offset uint,
 		if suffixFilter != "" && !strings.HasSuffix(k, suffixFilter) {
 			continue
 		}
+		if after != "" && (order == store.OrderDesc && k >= after || order != store.OrderDesc && k <= after) {
+			continue
+		}
+		if match != nil && !match.MatchString(k) {
+			continue
+		}
 
 		keys = append(keys, k)
 	}
@@ -200,19 +419,32 @@ func (m *memoryStore) list(prefix string, order store.Order, limit, offset uint,
 }
 
 func (m *memoryStore) Close() error {
-	m.Lock()
-	defer m.Unlock()
-	for _, s := range m.stores {
-		s.Flush()
+	var err error
+	if s := m.snapshots; s != nil {
+		s.stop()
+		err = m.snapshot(s.path)
+		m.snapshots = nil
 	}
-	return nil
+
+	m.once.Do(func() {
+		close(m.exit)
+	})
+
+	for _, t := range m.getTables() {
+		t.flush()
+	}
+	if m.limits != nil {
+		m.limits.reset()
+	}
+	return err
 }
 
 func (m *memoryStore) Init(opts ...store.Option) error {
 	for _, o := range opts {
 		o(&m.options)
 	}
-	return nil
+	m.configure()
+	return m.configureSnapshots()
 }
 
 func (m *memoryStore) String() string {
@@ -220,6 +452,9 @@ func (m *memoryStore) String() string {
 }
 
 func (m *memoryStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
 	readOpts := store.ReadOptions{
 		Order: store.OrderAsc,
 	}
@@ -227,9 +462,39 @@ func (m *memoryStore) Read(key string, opts ...store.ReadOption) ([]*store.Recor
 		o(&readOpts)
 	}
 
+	// earlier revisions of records aren't kept
+	if readOpts.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
 	prefix := m.prefix(readOpts.Database, readOpts.Table)
 
+	var match *regexp.Regexp
+	if readOpts.Match != nil {
+		var err error
+		if match, err = readOpts.Match.Compile(); err != nil {
+			return nil, err
+		}
+		// reads with a pattern return many records, with the key as their prefix by default
+		if !readOpts.Suffix {
+			readOpts.Prefix = true
+		}
+	}
+
+	var after string
+	if readOpts.Cursor != nil {
+		var err error
+		if after, err = store.DecodeCursor(*readOpts.Cursor); err != nil {
+			return nil, err
+		}
+		if readOpts.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
 	var keys []string
+	var results []*store.Record
+
 	// Handle Prefix / suffix
 	if readOpts.Prefix || readOpts.Suffix {
 		prefixFilter := ""
@@ -240,25 +505,84 @@ func (m *memoryStore) Read(key string, opts ...store.ReadOption) ([]*store.Recor
 		if readOpts.Suffix {
 			suffixFilter = key
 		}
-		keys = m.list(prefix, readOpts.Order, readOpts.Limit, readOpts.Offset, prefixFilter, suffixFilter)
+		if len(readOpts.Where) > 0 || readOpts.OrderBy == store.OrderByExpiry {
+			// the records are filtered and sorted before the limit and offset are applied
+			var err error
+			if results, err = m.where(prefix, readOpts, prefixFilter, suffixFilter, after, match); err != nil {
+				return nil, err
+			}
+		} else {
+			keys = m.list(prefix, readOpts.Order, readOpts.Limit, readOpts.Offset, prefixFilter, suffixFilter, after, match)
+		}
 	} else {
 		keys = []string{key}
 	}
 
-	var results []*store.Record
-
 	for _, k := range keys {
 		r, err := m.get(prefix, k)
 		if err != nil {
 			return results, err
 		}
+		if !r.Match(readOpts.Where) {
+			return results, store.ErrNotFound
+		}
 		results = append(results, r)
 	}
 
+	if readOpts.Cursor != nil {
+		var last string
+		if len(results) > 0 {
+			last = results[len(results)-1].Key
+		}
+		*readOpts.Cursor = store.NextCursor(readOpts.Limit, len(results), last)
+	}
+
+	return results, nil
+}
+
+// where returns the records matching the key filters and metadata values of the read
+func (m *memoryStore) where(prefix string, readOpts store.ReadOptions, prefixFilter, suffixFilter, after string, match *regexp.Regexp) ([]*store.Record, error) {
+	var results []*store.Record
+
+	for _, k := range m.list(prefix, readOpts.Order, 0, 0, prefixFilter, suffixFilter, after, match) {
+		r, err := m.get(prefix, k)
+		if err == store.ErrNotFound {
+			// expired since it was listed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if r.Match(readOpts.Where) {
+			results = append(results, r)
+		}
+	}
+	if readOpts.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(results, readOpts.Order)
+	}
+
+	if readOpts.Offset > 0 {
+		// offset is greater than the records we have
+		if int(readOpts.Offset) >= len(results) {
+			return nil, nil
+		}
+		results = results[readOpts.Offset:]
+	}
+
+	if readOpts.Limit > 0 && int(readOpts.Limit) < len(results) {
+		results = results[:readOpts.Limit]
+	}
+
 	return results, nil
 }
 
 func (m *memoryStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
 	writeOpts := store.WriteOptions{}
 	for _, o := range opts {
 		o(&writeOpts)
@@ -266,36 +590,47 @@ func (m *memoryStore) Write(r *store.Record, opts ...store.WriteOption) error {
 
 	prefix := m.prefix(writeOpts.Database, writeOpts.Table)
 
-	if len(opts) > 0 {
-		// Copy the record before applying options, or the incoming record will be mutated
-		newRecord := store.Record{}
-		newRecord.Key = r.Key
-		newRecord.Value = make([]byte, len(r.Value))
-		newRecord.Metadata = make(map[string]interface{})
-		copy(newRecord.Value, r.Value)
-		newRecord.Expiry = r.Expiry
-
-		for k, v := range r.Metadata {
-			newRecord.Metadata[k] = v
-		}
-
-		m.set(prefix, &newRecord)
-		return nil
+	// the record is copied when it's set so the incoming record isn't mutated
+	v, err := m.set(prefix, r, writeOpts, true)
+	if err != nil {
+		return err
 	}
-
-	// set
-	m.set(prefix, r)
+	r.Version = v
 
 	return nil
 }
 
 func (m *memoryStore) Delete(key string, opts ...store.DeleteOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
 	deleteOptions := store.DeleteOptions{}
 	for _, o := range opts {
 		o(&deleteOptions)
 	}
 
 	prefix := m.prefix(deleteOptions.Database, deleteOptions.Table)
+
+	// Handle Prefix / suffix
+	if deleteOptions.Prefix || deleteOptions.Suffix {
+		prefixFilter := ""
+		if deleteOptions.Prefix {
+			prefixFilter = key
+		}
+		suffixFilter := ""
+		if deleteOptions.Suffix {
+			suffixFilter = key
+		}
+		for _, k := range m.list(prefix, store.OrderAsc, 0, 0, prefixFilter, suffixFilter, "", nil) {
+			m.delete(prefix, k)
+		}
+		return nil
+	}
+
 	m.delete(prefix, key)
 	return nil
 }
@@ -313,7 +648,211 @@ func (m *memoryStore) List(opts ...store.ListOption) ([]string, error) {
 		o(&listOptions)
 	}
 
+	// the keys are grouped once they've been listed
+	if len(listOptions.Delimiter) > 0 {
+		return store.ListDelimited(m, listOptions)
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	var after string
+	if listOptions.Cursor != nil {
+		var err error
+		if after, err = store.DecodeCursor(*listOptions.Cursor); err != nil {
+			return nil, err
+		}
+		if listOptions.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
 	prefix := m.prefix(listOptions.Database, listOptions.Table)
-	keys := m.list(prefix, listOptions.Order, listOptions.Limit, listOptions.Offset, listOptions.Prefix, listOptions.Suffix)
+
+	var match *regexp.Regexp
+	if listOptions.Match != nil {
+		var err error
+		if match, err = listOptions.Match.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	if listOptions.OrderBy == store.OrderByExpiry {
+		// the records are needed to sort the keys
+		recs, err := m.where(prefix, store.ReadOptions{
+			Limit:   listOptions.Limit,
+			Offset:  listOptions.Offset,
+			Order:   listOptions.Order,
+			OrderBy: listOptions.OrderBy,
+		}, listOptions.Prefix, listOptions.Suffix, "", match)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(recs))
+		for _, r := range recs {
+			keys = append(keys, r.Key)
+		}
+		return keys, nil
+	}
+	keys := m.list(prefix, listOptions.Order, listOptions.Limit, listOptions.Offset, listOptions.Prefix, listOptions.Suffix, after, match)
+
+	if listOptions.Cursor != nil {
+		var last string
+		if len(keys) > 0 {
+			last = keys[len(keys)-1]
+		}
+		*listOptions.Cursor = store.NextCursor(listOptions.Limit, len(keys), last)
+	}
+
 	return keys, nil
 }
+
+// Txn buffers the changes made by fn and applies them once it returns without error
+func (m *memoryStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	if m.options.ReadOnly {
+		fn = store.ReadOnlyTxn(fn)
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	tx := &memoryTx{
+		store:   m,
+		prefix:  m.prefix(options.Database, options.Table),
+		pending: make(map[string]*store.Record),
+	}
+
+	// nothing else can run during the transaction so the records it reads can't change
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// check all the changes fit so they're applied together
+	if m.limits != nil {
+		sizes := make(map[string]int64, len(tx.keys))
+		for _, k := range tx.keys {
+			sizes[k] = -1
+			if r := tx.pending[k]; r != nil {
+				sizes[k] = int64(len(r.Key) + len(r.Value))
+			}
+		}
+		if err := m.limits.fits(tx.prefix, sizes); err != nil {
+			m.deleteExpired()
+			if err := m.limits.fits(tx.prefix, sizes); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, k := range tx.keys {
+		if r := tx.pending[k]; r != nil {
+			m.set(tx.prefix, r, store.WriteOptions{}, false)
+		} else {
+			m.delete(tx.prefix, k)
+		}
+	}
+
+	return nil
+}
+
+type memoryTx struct {
+	store  *memoryStore
+	prefix string
+
+	// the changes to apply in the order they were made, a nil record is a delete
+	keys    []string
+	pending map[string]*store.Record
+}
+
+func (t *memoryTx) Read(key string) (*store.Record, error) {
+	if r, ok := t.pending[key]; ok {
+		if r == nil {
+			return nil, store.ErrNotFound
+		}
+		return copyRecord(r), nil
+	}
+	return t.store.get(t.prefix, key)
+}
+
+func (t *memoryTx) Write(r *store.Record) error {
+	t.change(r.Key, copyRecord(r))
+	return nil
+}
+
+func (t *memoryTx) Delete(key string) error {
+	t.change(key, nil)
+	return nil
+}
+
+func (t *memoryTx) change(key string, r *store.Record) {
+	if _, ok := t.pending[key]; !ok {
+		t.keys = append(t.keys, key)
+	}
+	t.pending[key] = r
+}
+
+func copyRecord(r *store.Record) *store.Record {
+	newRecord := &store.Record{
+		Key:      r.Key,
+		Value:    make([]byte, len(r.Value)),
+		Metadata: make(map[string]interface{}),
+		Expiry:   r.Expiry,
+		Version:  r.Version,
+	}
+	copy(newRecord.Value, r.Value)
+	for k, v := range r.Metadata {
+		newRecord.Metadata[k] = v
+	}
+	return newRecord
+}
+
+// Touch sets the expiry of the record without rewriting its value
+func (m *memoryStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if m.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := m.prefix(options.Database, options.Table)
+	for {
+		r, err := m.get(prefix, key)
+		if err != nil {
+			return err
+		}
+		r.Expiry = expiry
+		// retry if the record was written since it was read
+		_, err = m.set(prefix, r, store.WriteOptions{IfVersion: r.Version}, true)
+		if err != store.ErrConflict {
+			return err
+		}
+	}
+}
+
+// Watch the records in the store for changes. Expiry events are emitted when the
+// expired records are cleaned up, which happens every 5 minutes.
+func (m *memoryStore) Watch(ctx context.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := m.prefix(options.Database, options.Table)
+	options.Database, options.Table = filepath.Dir(prefix), filepath.Base(prefix)
+
+	return m.watchers.Watch(ctx, key, options), nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+const benchmarkKeys = 10000
+
+func benchmarkStore(b *testing.B) store.Store {
+	s := NewStore()
+	for i := 0; i < benchmarkKeys; i++ {
+		if err := s.Write(&store.Record{Key: strconv.Itoa(i), Value: []byte("foo")}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	return s
+}
+
+// benchmarkParallel runs reads and writes of random keys concurrently, writing one in every
+// writeEvery operations or none if it's zero
+func benchmarkParallel(b *testing.B, writeEvery int) {
+	s := benchmarkStore(b)
+	defer s.Close()
+
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for i := 0; pb.Next(); i++ {
+			key := strconv.Itoa(r.Intn(benchmarkKeys))
+			if writeEvery > 0 && i%writeEvery == 0 {
+				if err := s.Write(&store.Record{Key: key, Value: []byte("bar")}); err != nil {
+					b.Fatal(err)
+				}
+			} else if _, err := s.Read(key); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkRead(b *testing.B) {
+	benchmarkParallel(b, 0)
+}
+
+func BenchmarkWrite(b *testing.B) {
+	benchmarkParallel(b, 1)
+}
+
+func BenchmarkReadMostly(b *testing.B) {
+	benchmarkParallel(b, 10)
+}
+
+// expiredStream records the keys of the expiry events published to it
+type expiredStream struct {
+	keys []string
+}
+
+func (s *expiredStream) Publish(topic string, msg interface{}, opts ...events.PublishOption) error {
+	if topic == store.ExpiredTopic {
+		s.keys = append(s.keys, msg.(*store.Expired).Key)
+	}
+	return nil
+}
+
+func (s *expiredStream) Consume(topic string, opts ...events.ConsumeOption) (<-chan events.Event, error) {
+	return nil, nil
+}
+
+func TestExpiryEvents(t *testing.T) {
+	stream := &expiredStream{}
+	s := NewStore(store.ExpiryEvents(stream))
+	defer s.Close()
+
+	assert.NoError(t, s.Write(&store.Record{Key: "a", Value: []byte("foo"), Expiry: time.Millisecond}))
+	write(t, s, "b")
+	time.Sleep(5 * time.Millisecond)
+
+	s.(*memoryStore).deleteExpired()
+	assert.Equal(t, []string{"a"}, stream.keys)
+	assert.Equal(t, []string{"b"}, held(s))
+}
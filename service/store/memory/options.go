@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type keepVersionsKey struct{}
+type maxKeysKey struct{}
+type maxBytesKey struct{}
+type evictionKey struct{}
+type snapshotPathKey struct{}
+type snapshotIntervalKey struct{}
+
+// EvictionPolicy decides what happens when a write would take the store over its limits
+type EvictionPolicy string
+
+const (
+	// EvictLRU evicts the least recently used records, it's the default
+	EvictLRU EvictionPolicy = "lru"
+	// EvictLFU evicts the least frequently used records
+	EvictLFU EvictionPolicy = "lfu"
+	// RejectWrites keeps the records and returns ErrFull from the write instead
+	RejectWrites EvictionPolicy = "reject"
+)
+
+// KeepVersions stops the store from changing the versions of the records written to it,
+// so that it can cache another store which manages the versions
+func KeepVersions() store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.WithValue(context.Background(), keepVersionsKey{}, true)
+		} else {
+			o.Context = context.WithValue(o.Context, keepVersionsKey{}, true)
+		}
+	}
+}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// MaxKeys limits the number of records held across all the tables of the store
+func MaxKeys(n int) store.Option {
+	return setOption(maxKeysKey{}, n)
+}
+
+// MaxBytes limits the total size of the keys and values held across all the tables of
+// the store. Writes of records larger than the limit return ErrFull.
+func MaxBytes(n int64) store.Option {
+	return setOption(maxBytesKey{}, n)
+}
+
+// Eviction sets what happens when a write would take the store over its limits. Expired
+// records are always removed first. Evicted records are deleted, so watchers receive
+// delete events for them.
+func Eviction(p EvictionPolicy) store.Option {
+	return setOption(evictionKey{}, p)
+}
+
+// SnapshotPath writes the records in the store to the file periodically and when it's
+// closed, and loads them from it when the option is set, so they survive restarts
+func SnapshotPath(path string) store.Option {
+	return setOption(snapshotPathKey{}, path)
+}
+
+// SnapshotInterval sets how often the store is written to the file set by SnapshotPath
+func SnapshotInterval(d time.Duration) store.Option {
+	return setOption(snapshotIntervalKey{}, d)
+}
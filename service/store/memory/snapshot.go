@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+)
+
+// DefaultSnapshotInterval is how often the store is written to its snapshot file
+var DefaultSnapshotInterval = time.Minute
+
+// snapshotRecord is a record as it's written to the snapshot file
+type snapshotRecord struct {
+	Database  string
+	Table     string
+	Key       string
+	Value     []byte
+	Metadata  map[string]interface{}
+	ExpiresAt time.Time
+	Version   uint64
+}
+
+// snapshotter periodically writes the store to a file
+type snapshotter struct {
+	path     string
+	interval time.Duration
+	exit     chan bool
+	once     sync.Once
+}
+
+func (s *snapshotter) stop() {
+	s.once.Do(func() {
+		close(s.exit)
+	})
+}
+
+// configureSnapshots starts snapshotting the store if a path is set, loading the records
+// from the snapshot the first time it's set
+func (m *memoryStore) configureSnapshots() error {
+	var path string
+	interval := DefaultSnapshotInterval
+	if ctx := m.options.Context; ctx != nil {
+		path, _ = ctx.Value(snapshotPathKey{}).(string)
+		if d, ok := ctx.Value(snapshotIntervalKey{}).(time.Duration); ok && d > 0 {
+			interval = d
+		}
+	}
+
+	if s := m.snapshots; s != nil {
+		if s.path == path && s.interval == interval {
+			return nil
+		}
+		s.stop()
+		m.snapshots = nil
+	}
+	if len(path) == 0 {
+		return nil
+	}
+
+	if err := m.restore(path); err != nil {
+		return err
+	}
+	m.snapshots = &snapshotter{
+		path:     path,
+		interval: interval,
+		exit:     make(chan bool),
+	}
+	go m.snapshotLoop(m.snapshots)
+	return nil
+}
+
+func (m *memoryStore) snapshotLoop(s *snapshotter) {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.exit:
+			return
+		case <-t.C:
+		}
+		if err := m.snapshot(s.path); err != nil {
+			logger.Errorf("Error writing memory store snapshot to %s: %v", s.path, err)
+		}
+	}
+}
+
+// snapshot writes the records in the store to the file, replacing it once written so a
+// crash never leaves it partially written
+func (m *memoryStore) snapshot(path string) error {
+	// nothing can be written while the lock is held so the snapshot is consistent
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	for prefix, t := range m.getTables() {
+		err := t.each(func(key string, r *storeRecord) error {
+			return enc.Encode(&snapshotRecord{
+				Database:  filepath.Dir(prefix),
+				Table:     filepath.Base(prefix),
+				Key:       key,
+				Value:     r.value,
+				Metadata:  r.metadata,
+				ExpiresAt: r.expiresAt,
+				Version:   r.version,
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// restore the records from the snapshot file, if it exists
+func (m *memoryStore) restore(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var r snapshotRecord
+		if err := dec.Decode(&r); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !r.ExpiresAt.IsZero() && r.ExpiresAt.Before(time.Now()) {
+			continue
+		}
+
+		prefix := filepath.Join(r.Database, r.Table)
+		var evicted []*usage
+		if m.limits != nil {
+			evicted, _ = m.limits.add(prefix, r.Key, int64(len(r.Key)+len(r.Value)), false)
+		}
+		sh := m.getTable(prefix).shard(r.Key)
+		sh.Lock()
+		sh.records[r.Key] = &storeRecord{
+			key:       r.Key,
+			value:     r.Value,
+			metadata:  r.Metadata,
+			expiresAt: r.ExpiresAt,
+			version:   r.Version,
+		}
+		sh.Unlock()
+		for _, u := range evicted {
+			m.delete(u.prefix, u.key)
+		}
+	}
+}
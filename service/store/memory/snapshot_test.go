@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "memory.snapshot")
+
+	s := NewStore(SnapshotPath(path))
+	assert.NoError(t, s.Write(&store.Record{Key: "foo", Value: []byte("bar"), Metadata: map[string]interface{}{"a": "b"}}))
+	assert.NoError(t, s.Write(&store.Record{Key: "foo", Value: []byte("baz")}))
+	assert.NoError(t, s.Write(&store.Record{Key: "other", Value: []byte("bar"), Expiry: time.Hour}, store.WriteTo("db", "table")))
+	assert.NoError(t, s.Write(&store.Record{Key: "expires", Value: []byte("bar"), Expiry: 50 * time.Millisecond}))
+	assert.NoError(t, s.Close())
+
+	time.Sleep(100 * time.Millisecond)
+	s = NewStore(SnapshotPath(path))
+	defer s.Close()
+
+	recs, err := s.Read("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("baz"), recs[0].Value)
+	assert.Equal(t, uint64(2), recs[0].Version)
+
+	recs, err = s.Read("other", store.ReadFrom("db", "table"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), recs[0].Value)
+	assert.InDelta(t, time.Hour, recs[0].Expiry, float64(time.Second))
+
+	_, err = s.Read("expires")
+	assert.Equal(t, store.ErrNotFound, err)
+}
+
+func TestSnapshotInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "memory.snapshot")
+
+	s := NewStore(SnapshotPath(path), SnapshotInterval(10*time.Millisecond))
+	defer s.Close()
+	assert.NoError(t, s.Write(&store.Record{Key: "foo", Value: []byte("bar")}))
+	time.Sleep(50 * time.Millisecond)
+
+	// the snapshot is written without closing the store
+	restored := NewStore(SnapshotPath(path))
+	defer restored.Close()
+	recs, err := restored.Read("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), recs[0].Value)
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// shardCount is the number of shards the records of a table are split between
+const shardCount = 32
+
+// table holds the records of a table split between shards by the hash of their keys,
+// so operations on different keys rarely wait for each other
+type table struct {
+	shards [shardCount]shard
+}
+
+type shard struct {
+	sync.RWMutex
+	records map[string]*storeRecord
+}
+
+func newTable() *table {
+	t := &table{}
+	for i := range t.shards {
+		t.shards[i].records = make(map[string]*storeRecord)
+	}
+	return t
+}
+
+// shard returns the shard holding the key, chosen by its FNV-1a hash
+func (t *table) shard(key string) *shard {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return &t.shards[h%shardCount]
+}
+
+// get returns the record with the key unless it's expired. The shard must be locked.
+func (s *shard) get(key string, now time.Time) *storeRecord {
+	r := s.records[key]
+	if r == nil || r.expired(now) {
+		return nil
+	}
+	return r
+}
+
+// keys returns the keys of the records which haven't expired
+func (t *table) keys() []string {
+	now := time.Now()
+	var keys []string
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.RLock()
+		for k, r := range s.records {
+			if !r.expired(now) {
+				keys = append(keys, k)
+			}
+		}
+		s.RUnlock()
+	}
+	return keys
+}
+
+// each calls fn with the records which haven't expired, the shards are locked for reading
+func (t *table) each(fn func(key string, r *storeRecord) error) error {
+	now := time.Now()
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.RLock()
+		for k, r := range s.records {
+			if r.expired(now) {
+				continue
+			}
+			if err := fn(k, r); err != nil {
+				s.RUnlock()
+				return err
+			}
+		}
+		s.RUnlock()
+	}
+	return nil
+}
+
+// deleteExpired removes the expired records, calling fn with each while its shard is locked
+func (t *table) deleteExpired(fn func(key string)) {
+	now := time.Now()
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.Lock()
+		for k, r := range s.records {
+			if r.expired(now) {
+				delete(s.records, k)
+				fn(k)
+			}
+		}
+		s.Unlock()
+	}
+}
+
+// flush removes all the records
+func (t *table) flush() {
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.Lock()
+		s.records = make(map[string]*storeRecord)
+		s.Unlock()
+	}
+}
+
+func (r *storeRecord) expired(now time.Time) bool {
+	return !r.expiresAt.IsZero() && !r.expiresAt.After(now)
+}
@@ -14,7 +14,11 @@
 
 package store
 
-import "context"
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/events"
+)
 
 // Options contains configuration for the Store
 type Options struct {
@@ -26,6 +30,12 @@ type Options struct {
 	Database string
 	// Table is analagous to a table in database backends or a key prefix in KV backends
 	Table string
+	// Indexes are the metadata fields which are indexed, if supported
+	Indexes []string
+	// ReadOnly stores return ErrReadOnly for any change to their records
+	ReadOnly bool
+	// ExpiryEvents is the stream events are published to when records expire, if set
+	ExpiryEvents events.Stream
 	// Context should contain all implementation specific options, using context.WithValue.
 	Context context.Context
 }
@@ -59,6 +69,30 @@ func Table(t string) Option {
 	}
 }
 
+// Index the metadata fields so records can be read by them efficiently with ReadWhere.
+// Stores which don't maintain indexes still support ReadWhere by filtering the records.
+func Index(fields ...string) Option {
+	return func(o *Options) {
+		o.Indexes = append(o.Indexes, fields...)
+	}
+}
+
+// ReadOnly makes the store return ErrReadOnly for writes, deletes and any other change to
+// its records, for example to read a snapshot of production data without risk of changing it
+func ReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// ExpiryEvents publishes an event to ExpiredTopic on the stream when a record expires, so
+// services can clean up after it without polling. Supported by the memory and file stores.
+func ExpiryEvents(s events.Stream) Option {
+	return func(o *Options) {
+		o.ExpiryEvents = s
+	}
+}
+
 // WithContext sets the stores context, for any extra configuration
 func WithContext(c context.Context) Option {
 	return func(o *Options) {
@@ -79,6 +113,17 @@ type ReadOptions struct {
 	Offset uint
 	// Order of the data returned e.g asc or desc
 	Order Order
+	// OrderBy is the field the data is sorted by, the key by default
+	OrderBy OrderBy
+	// Where only returns the records with these metadata values
+	Where map[string]interface{}
+	// Cursor to continue the read from, which is set to the cursor for the next page
+	Cursor *string
+	// Match only returns the records whose keys match the pattern
+	Match *Pattern
+	// Version reads an earlier revision of the key from a store which keeps them, see
+	// ReadVersion
+	Version uint64
 }
 
 // ReadOption sets values in ReadOptions
@@ -99,6 +144,15 @@ func ReadOrder(o Order) ReadOption {
 	}
 }
 
+// ReadOrderBy sorts the records by the field, e.g. OrderByExpiry with OrderDesc returns the
+// records which expire last first. Cursors page by key, so can't be combined with
+// OrderByExpiry, and the store returns ErrNotSupported.
+func ReadOrderBy(o OrderBy) ReadOption {
+	return func(r *ReadOptions) {
+		r.OrderBy = o
+	}
+}
+
 // ReadPrefix returns all records that are prefixed with key
 func ReadPrefix() ReadOption {
 	return func(r *ReadOptions) {
@@ -113,6 +167,57 @@ func ReadSuffix() ReadOption {
 	}
 }
 
+// ReadWhere only returns the records whose metadata field has the value. Values are compared
+// in their string form, so a field written as 1 matches both 1 and "1".
+func ReadWhere(field string, value interface{}) ReadOption {
+	return func(r *ReadOptions) {
+		if r.Where == nil {
+			r.Where = make(map[string]interface{})
+		}
+		r.Where[field] = value
+	}
+}
+
+// ReadCursor pages through the records in key order. The read continues from cursor, which
+// is empty for the first page, and cursor is then set to the token for the next page, or to
+// empty if there are no more. Unlike an offset, the pages aren't shifted by writes made
+// between them. Any offset is applied after the cursor. Stores which can't page through
+// keys return ErrNotSupported.
+func ReadCursor(cursor *string) ReadOption {
+	return func(r *ReadOptions) {
+		r.Cursor = cursor
+	}
+}
+
+// ReadMatch only returns the records whose keys match the glob, e.g. "user:*:session". A *
+// matches any characters, a ? matches one, [a-z] and [!a-z] match classes of characters and a
+// backslash escapes the character after it. Reads with a pattern return many records like
+// ReadPrefix, with the key as a prefix of them unless ReadSuffix is set, so it may be empty.
+// Stores return ErrInvalidPattern if the glob isn't valid.
+func ReadMatch(glob string) ReadOption {
+	return func(r *ReadOptions) {
+		r.Match = &Pattern{Expr: glob}
+	}
+}
+
+// ReadMatchRegexp only returns the records whose keys match the RE2 regular expression, see
+// ReadMatch. The expression isn't anchored, so it matches any part of the key.
+func ReadMatchRegexp(expr string) ReadOption {
+	return func(r *ReadOptions) {
+		r.Match = &Pattern{Expr: expr, Regexp: true}
+	}
+}
+
+// ReadVersion reads the nth revision of the key, counting its writes from 1, from a store
+// which keeps them such as one wrapped with versioned.NewStore. ErrNotFound is returned if
+// the revision doesn't exist or is the key being deleted, and other stores return
+// ErrNotSupported.
+func ReadVersion(n uint64) ReadOption {
+	return func(r *ReadOptions) {
+		r.Version = n
+	}
+}
+
 // ReadLimit limits the number of responses to l
 func ReadLimit(l uint) ReadOption {
 	return func(r *ReadOptions) {
@@ -131,6 +236,12 @@ func ReadOffset(o uint) ReadOption {
 // If Expiry and TTL are set TTL takes precedence
 type WriteOptions struct {
 	Database, Table string
+	// IfVersion only writes the record if its current version matches
+	IfVersion uint64
+	// IfNotExists only writes the record if the key doesn't exist
+	IfNotExists bool
+	// Compress the value of the record with the algorithm
+	Compress Compression
 }
 
 // WriteOption sets values in WriteOptions
@@ -144,9 +255,38 @@ func WriteTo(database, table string) WriteOption {
 	}
 }
 
+// WriteIfVersion only writes the record if the version of the stored record is v, otherwise
+// ErrConflict is returned. It only applies to Write, and stores which don't support versions
+// return ErrNotSupported.
+func WriteIfVersion(v uint64) WriteOption {
+	return func(w *WriteOptions) {
+		w.IfVersion = v
+	}
+}
+
+// WriteIfNotExists only writes the record if the key doesn't exist, otherwise ErrConflict is
+// returned. It only applies to Write, and stores which don't support it return ErrNotSupported.
+func WriteIfNotExists() WriteOption {
+	return func(w *WriteOptions) {
+		w.IfNotExists = true
+	}
+}
+
+// WriteCompress compresses the value of the record with the algorithm. It's applied by
+// stores wrapped with compress.NewStore, which decompress the values they read.
+func WriteCompress(c Compression) WriteOption {
+	return func(w *WriteOptions) {
+		w.Compress = c
+	}
+}
+
 // DeleteOptions configures an individual Delete operation
 type DeleteOptions struct {
 	Database, Table string
+	// Prefix deletes all records that are prefixed with key
+	Prefix bool
+	// Suffix deletes all records that have the suffix key
+	Suffix bool
 }
 
 // DeleteOption sets values in DeleteOptions
@@ -160,6 +300,93 @@ func DeleteFrom(database, table string) DeleteOption {
 	}
 }
 
+// DeletePrefix deletes all records that are prefixed with key
+func DeletePrefix() DeleteOption {
+	return func(d *DeleteOptions) {
+		d.Prefix = true
+	}
+}
+
+// DeleteSuffix deletes all records that have the suffix key
+func DeleteSuffix() DeleteOption {
+	return func(d *DeleteOptions) {
+		d.Suffix = true
+	}
+}
+
+// TxnOptions configures a transaction
+type TxnOptions struct {
+	Database, Table string
+}
+
+// TxnOption sets values in TxnOptions
+type TxnOption func(t *TxnOptions)
+
+// TxnOn the database and table
+func TxnOn(database, table string) TxnOption {
+	return func(t *TxnOptions) {
+		t.Database = database
+		t.Table = table
+	}
+}
+
+// WatchOptions configures a Watch
+type WatchOptions struct {
+	Database, Table string
+	// Prefix watches all the records that are prefixed with key
+	Prefix bool
+}
+
+// WatchOption sets values in WatchOptions
+type WatchOption func(w *WatchOptions)
+
+// WatchFrom the database and table
+func WatchFrom(database, table string) WatchOption {
+	return func(w *WatchOptions) {
+		w.Database = database
+		w.Table = table
+	}
+}
+
+// WatchPrefix watches all the records that are prefixed with key
+func WatchPrefix() WatchOption {
+	return func(w *WatchOptions) {
+		w.Prefix = true
+	}
+}
+
+// TouchOptions configures a Touch
+type TouchOptions struct {
+	Database, Table string
+}
+
+// TouchOption sets values in TouchOptions
+type TouchOption func(t *TouchOptions)
+
+// TouchFrom the database and table
+func TouchFrom(database, table string) TouchOption {
+	return func(t *TouchOptions) {
+		t.Database = database
+		t.Table = table
+	}
+}
+
+// CompactOptions configures a Compact
+type CompactOptions struct {
+	Database, Table string
+}
+
+// CompactOption sets values in CompactOptions
+type CompactOption func(c *CompactOptions)
+
+// CompactFrom the database and table
+func CompactFrom(database, table string) CompactOption {
+	return func(c *CompactOptions) {
+		c.Database = database
+		c.Table = table
+	}
+}
+
 // ListOptions configures an individual List operation
 type ListOptions struct {
 	// List from the following
@@ -174,6 +401,14 @@ type ListOptions struct {
 	Offset uint
 	// Order to list the data set
 	Order Order
+	// OrderBy is the field the data set is sorted by, the key by default
+	OrderBy OrderBy
+	// Cursor to continue the list from, which is set to the cursor for the next page
+	Cursor *string
+	// Delimiter groups the keys which contain it after the prefix
+	Delimiter string
+	// Match only returns the keys which match the pattern
+	Match *Pattern
 }
 
 // ListOption sets values in ListOptions
@@ -194,6 +429,13 @@ func ListOrder(o Order) ListOption {
 	}
 }
 
+// ListOrderBy sorts the keys by the field of their records, see ReadOrderBy
+func ListOrderBy(o OrderBy) ListOption {
+	return func(l *ListOptions) {
+		l.OrderBy = o
+	}
+}
+
 // ListPrefix returns all keys that are prefixed with key
 func ListPrefix(p string) ListOption {
 	return func(l *ListOptions) {
@@ -208,6 +450,39 @@ func ListSuffix(s string) ListOption {
 	}
 }
 
+// ListCursor pages through the keys, see ReadCursor
+func ListCursor(cursor *string) ListOption {
+	return func(l *ListOptions) {
+		l.Cursor = cursor
+	}
+}
+
+// ListDelimiter groups the keys which contain the delimiter after the prefix, returning their
+// common prefix up to and including the delimiter once rather than every key. For example
+// ListPrefix("users/") with ListDelimiter("/") returns "users/a" and "users/b/" for the keys
+// users/a, users/b/1 and users/b/2, so the keys can be browsed like directories. The limit,
+// offset and cursor apply to the grouped keys. Delimiters can't be combined with OrderByExpiry.
+func ListDelimiter(d string) ListOption {
+	return func(l *ListOptions) {
+		l.Delimiter = d
+	}
+}
+
+// ListMatch only returns the keys which match the glob, see ReadMatch
+func ListMatch(glob string) ListOption {
+	return func(l *ListOptions) {
+		l.Match = &Pattern{Expr: glob}
+	}
+}
+
+// ListMatchRegexp only returns the keys which match the RE2 regular expression, see
+// ReadMatchRegexp
+func ListMatchRegexp(expr string) ListOption {
+	return func(l *ListOptions) {
+		l.Match = &Pattern{Expr: expr, Regexp: true}
+	}
+}
+
 // ListLimit limits the number of returned keys to l
 func ListLimit(l uint) ListOption {
 	return func(lo *ListOptions) {
@@ -221,3 +496,53 @@ func ListOffset(o uint) ListOption {
 		l.Offset = o
 	}
 }
+
+// TableName is a table of a database
+type TableName struct {
+	Database, Table string
+}
+
+// BackupOptions configures a Backup
+type BackupOptions struct {
+	// Tables to back up, the database and table of the store if empty
+	Tables []TableName
+}
+
+// BackupOption sets values in BackupOptions
+type BackupOption func(b *BackupOptions)
+
+// BackupTable adds the table of the database to the backup
+func BackupTable(database, table string) BackupOption {
+	return func(b *BackupOptions) {
+		b.Tables = append(b.Tables, TableName{Database: database, Table: table})
+	}
+}
+
+// RestoreOptions configures a Restore
+type RestoreOptions struct {
+	// Tables to restore, all the tables of the backup if empty
+	Tables []TableName
+}
+
+// RestoreOption sets values in RestoreOptions
+type RestoreOption func(r *RestoreOptions)
+
+// RestoreTable only restores the table of the database from the backup
+func RestoreTable(database, table string) RestoreOption {
+	return func(r *RestoreOptions) {
+		r.Tables = append(r.Tables, TableName{Database: database, Table: table})
+	}
+}
+
+// restores returns true if the table should be restored
+func (r RestoreOptions) restores(database, table string) bool {
+	if len(r.Tables) == 0 {
+		return true
+	}
+	for _, t := range r.Tables {
+		if t.Database == database && t.Table == table {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Pattern is a glob or regular expression keys are matched against, see ReadMatch
+type Pattern struct {
+	// Expr is the glob, or the RE2 expression if Regexp is true
+	Expr string
+	// Regexp is true if Expr is a regular expression
+	Regexp bool
+}
+
+// Compile returns the regular expression matching the same keys as the pattern, or
+// ErrInvalidPattern if it isn't valid
+func (p *Pattern) Compile() (*regexp.Regexp, error) {
+	expr, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+	// a glob's ? and * match newlines too
+	if !p.Regexp {
+		expr = "(?s)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, ErrInvalidPattern
+	}
+	return re, nil
+}
+
+// Expression returns the regular expression matching the same keys as the pattern. Globs are
+// translated to an anchored expression which only uses syntax common to RE2 and POSIX, so
+// stores can pass it on to databases which support regular expressions.
+func (p *Pattern) Expression() (string, error) {
+	if p.Regexp {
+		return p.Expr, nil
+	}
+	return globToRegexp(p.Expr)
+}
+
+// Like returns the SQL LIKE pattern, escaped with backslashes, matching the same keys as the
+// pattern. It returns false for regular expressions and globs with character classes, which
+// LIKE can't express.
+func (p *Pattern) Like() (string, bool) {
+	if p.Regexp {
+		return "", false
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(p.Expr); i++ {
+		switch c := p.Expr[i]; c {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '[':
+			return "", false
+		case '\\':
+			if i++; i == len(p.Expr) {
+				return "", false
+			}
+			b.WriteString(escapeLike(p.Expr[i : i+1]))
+		default:
+			b.WriteString(escapeLike(string(c)))
+		}
+	}
+	return b.String(), true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// globToRegexp translates a glob to an anchored regular expression. A * matches any
+// characters, a ? matches one, [abc], [a-z] and [!a-z] match a class of characters and
+// a backslash escapes the character after it.
+func globToRegexp(glob string) (string, error) {
+	var b strings.Builder
+	b.WriteString(`^`)
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", ErrInvalidPattern
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i++; i == len(glob) {
+				return "", ErrInvalidPattern
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString(`$`)
+	return b.String(), nil
+}
+
+// ListMatched lists the keys matching options.Match by listing all the keys with the prefix
+// and suffix and filtering them, then applies the cursor, offset and limit. Stores which can't
+// match keys themselves use it to implement ListMatch.
+func ListMatched(s Store, options ListOptions) ([]string, error) {
+	if options.Cursor != nil && options.OrderBy == OrderByExpiry {
+		return nil, ErrNotSupported
+	}
+
+	re, err := options.Match.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	var after string
+	if options.Cursor != nil {
+		if after, err = DecodeCursor(*options.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := s.List(
+		ListFrom(options.Database, options.Table),
+		ListPrefix(options.Prefix),
+		ListSuffix(options.Suffix),
+		ListOrder(options.Order),
+		ListOrderBy(options.OrderBy),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, k := range keys {
+		if !re.MatchString(k) {
+			continue
+		}
+		if len(after) > 0 {
+			if options.Order == OrderDesc && k >= after {
+				continue
+			} else if options.Order != OrderDesc && k <= after {
+				continue
+			}
+		}
+		matched = append(matched, k)
+	}
+
+	if options.Offset > uint(len(matched)) {
+		matched = nil
+	} else {
+		matched = matched[options.Offset:]
+	}
+	if options.Limit > 0 && uint(len(matched)) > options.Limit {
+		matched = matched[:options.Limit]
+	}
+
+	if options.Cursor != nil {
+		var last string
+		if len(matched) > 0 {
+			last = matched[len(matched)-1]
+		}
+		*options.Cursor = NextCursor(options.Limit, len(matched), last)
+	}
+	return matched, nil
+}
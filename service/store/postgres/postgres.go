@@ -0,0 +1,917 @@
+// Package postgres is a PostgreSQL backed store
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultDatabase is the schema that the postgres store
+	// will use if no database is provided.
+	DefaultDatabase = "micro"
+	// DefaultTable when none is specified
+	DefaultTable = "micro"
+	// DefaultNodes is the connection string used when none is provided
+	DefaultNodes = []string{"postgresql://postgres@localhost:5432/?sslmode=disable"}
+	// DefaultCleanupInterval is how often expired records are deleted
+	DefaultCleanupInterval = time.Hour
+
+	// ErrNoConnection is returned when the store has not connected to postgres
+	ErrNoConnection = errors.New("database connection not initialised")
+
+	// the number of rows written by each insert of WriteMany, postgres
+	// limits the number of parameters in a statement to 65535
+	batchSize = 1000
+
+	// database and table names may only contain letters, numbers and underscores
+	re = regexp.MustCompile("[^a-zA-Z0-9]+")
+	// escapes the special characters in a LIKE pattern
+	likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+)
+
+// NewStore returns a new micro Store backed by postgres
+func NewStore(opts ...store.Option) store.Store {
+	s := &sqlStore{
+		options: store.Options{
+			Database: DefaultDatabase,
+			Table:    DefaultTable,
+		},
+		tables: make(map[string]bool),
+		exit:   make(chan bool),
+	}
+
+	// best-effort configure the store
+	if err := s.Init(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	go s.cleanupLoop()
+
+	return s
+}
+
+type sqlStore struct {
+	options store.Options
+	db      *sql.DB
+
+	sync.RWMutex
+	// tables which have been created, keyed by quoted schema.table
+	tables map[string]bool
+
+	exit chan bool
+	once sync.Once
+}
+
+func (s *sqlStore) configure() error {
+	nodes := s.options.Nodes
+	if len(nodes) == 0 {
+		nodes = DefaultNodes
+	}
+
+	db, err := sql.Open("postgres", nodes[0])
+	if err != nil {
+		return err
+	}
+
+	if ctx := s.options.Context; ctx != nil {
+		if n, ok := ctx.Value(maxOpenConnsKey{}).(int); ok {
+			db.SetMaxOpenConns(n)
+		}
+		if n, ok := ctx.Value(maxIdleConnsKey{}).(int); ok {
+			db.SetMaxIdleConns(n)
+		}
+		if d, ok := ctx.Value(connMaxLifetimeKey{}).(time.Duration); ok {
+			db.SetConnMaxLifetime(d)
+		}
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return err
+	}
+
+	s.Lock()
+	if s.db != nil {
+		s.db.Close()
+	}
+	s.db = db
+	s.tables = make(map[string]bool)
+	s.Unlock()
+
+	// create the default table up front
+	_, err = s.table(s.options.Database, s.options.Table)
+	return err
+}
+
+// table returns the quoted schema.table name for the database and table,
+// creating them if they don't yet exist
+func (s *sqlStore) table(database, table string) (string, error) {
+	if len(database) == 0 {
+		database = s.options.Database
+	}
+	if len(table) == 0 {
+		table = s.options.Table
+	}
+
+	schema := pq.QuoteIdentifier(re.ReplaceAllString(database, "_"))
+	name := pq.QuoteIdentifier(re.ReplaceAllString(table, "_"))
+	qualified := schema + "." + name
+
+	s.RLock()
+	db, ok := s.db, s.tables[qualified]
+	s.RUnlock()
+
+	if db == nil {
+		return "", ErrNoConnection
+	}
+	if ok {
+		return qualified, nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.tables[qualified] {
+		return qualified, nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema)); err != nil {
+		return "", errors.Wrap(err, "Couldn't create schema")
+	}
+
+	if _, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		key text NOT NULL PRIMARY KEY,
+		value bytea,
+		metadata jsonb,
+		expiry timestamp with time zone,
+		version bigint NOT NULL DEFAULT 1
+	)`, qualified)); err != nil {
+		return "", errors.Wrap(err, "Couldn't create table")
+	}
+
+	// tables created before records were versioned need the column adding
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1", qualified)); err != nil {
+		return "", errors.Wrap(err, "Couldn't add version column")
+	}
+
+	// the primary key index can't be used for LIKE queries unless the
+	// database uses the C collation, so create one which always can
+	idx := pq.QuoteIdentifier(re.ReplaceAllString(database+"_"+table, "_") + "_key_pattern")
+	if _, err := db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (key text_pattern_ops)", idx, qualified)); err != nil {
+		return "", errors.Wrap(err, "Couldn't create key index")
+	}
+
+	idx = pq.QuoteIdentifier(re.ReplaceAllString(database+"_"+table, "_") + "_expiry")
+	if _, err := db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (expiry) WHERE expiry IS NOT NULL", idx, qualified)); err != nil {
+		return "", errors.Wrap(err, "Couldn't create expiry index")
+	}
+
+	// index the metadata fields so they can be used by ReadWhere
+	for _, field := range s.options.Indexes {
+		idx = pq.QuoteIdentifier(re.ReplaceAllString(database+"_"+table+"_"+field, "_") + "_metadata")
+		if _, err := db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s ((metadata->>%s))", idx, qualified, pq.QuoteLiteral(field))); err != nil {
+			return "", errors.Wrap(err, "Couldn't create metadata index")
+		}
+	}
+
+	s.tables[qualified] = true
+	return qualified, nil
+}
+
+func (s *sqlStore) conn() (*sql.DB, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.db == nil {
+		return nil, ErrNoConnection
+	}
+	return s.db, nil
+}
+
+// pattern returns a LIKE pattern which matches the prefix and suffix
+func pattern(prefix, suffix string) string {
+	return likeReplacer.Replace(prefix) + "%" + likeReplacer.Replace(suffix)
+}
+
+// orderBy returns the ORDER BY clause for the order, records which don't expire
+// have a NULL expiry so are sorted as if they expire last
+func orderBy(order store.Order, by store.OrderBy) string {
+	switch {
+	case by == store.OrderByExpiry && order == store.OrderDesc:
+		return "ORDER BY expiry DESC NULLS FIRST, key DESC"
+	case by == store.OrderByExpiry:
+		return "ORDER BY expiry ASC NULLS LAST, key ASC"
+	case order == store.OrderDesc:
+		return "ORDER BY key DESC"
+	}
+	return "ORDER BY key ASC"
+}
+
+// where returns the conditions on the metadata of the records to add to a WHERE clause,
+// with their arguments numbered from n. The fields are compared as text, which
+// matches the string form the store compares values in.
+func where(fields map[string]interface{}, n int) (string, []interface{}) {
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var conds strings.Builder
+	args := make([]interface{}, 0, len(names))
+
+	for i, k := range names {
+		// the field is a literal rather than an argument so the expression indexes can be used
+		fmt.Fprintf(&conds, " AND metadata->>%s = $%d", pq.QuoteLiteral(k), n+i)
+		args = append(args, fmt.Sprint(fields[k]))
+	}
+
+	return conds.String(), args
+}
+
+// after returns the condition to add to a WHERE clause to continue from the cursor,
+// with its argument numbered n. The keys are paged through rather than offset so
+// the primary key index can be used to find the start of the page.
+func after(cursor *string, order store.Order, n int) (string, []interface{}, error) {
+	if cursor == nil || len(*cursor) == 0 {
+		return "", nil, nil
+	}
+	key, err := store.DecodeCursor(*cursor)
+	if err != nil {
+		return "", nil, err
+	}
+	if order == store.OrderDesc {
+		return fmt.Sprintf(" AND key < $%d", n), []interface{}{key}, nil
+	}
+	return fmt.Sprintf(" AND key > $%d", n), []interface{}{key}, nil
+}
+
+// match returns the condition to add to a WHERE clause for the keys to match the pattern,
+// with its argument numbered n. Globs which can be are matched with LIKE, others with a
+// regular expression.
+func match(p *store.Pattern, n int) (string, []interface{}, error) {
+	if p == nil {
+		return "", nil, nil
+	}
+	if like, ok := p.Like(); ok {
+		return fmt.Sprintf(" AND key LIKE $%d", n), []interface{}{like}, nil
+	}
+	// check the pattern is valid before it's sent
+	if _, err := p.Compile(); err != nil {
+		return "", nil, err
+	}
+	expr, err := p.Expression()
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf(" AND key ~ $%d", n), []interface{}{expr}, nil
+}
+
+// limit returns the value to use for a LIMIT clause, NULL means no limit
+func limit(l uint) sql.NullInt64 {
+	if l == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(l), Valid: true}
+}
+
+func scanRecords(rows *sql.Rows) ([]*store.Record, error) {
+	defer rows.Close()
+
+	var records []*store.Record
+
+	for rows.Next() {
+		var expiry pq.NullTime
+		var metadata []byte
+		rec := &store.Record{}
+
+		if err := rows.Scan(&rec.Key, &rec.Value, &metadata, &expiry, &rec.Version); err != nil {
+			return nil, err
+		}
+
+		rec.Metadata = make(map[string]interface{})
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &rec.Metadata); err != nil {
+				return nil, err
+			}
+		}
+
+		if expiry.Valid {
+			rec.Expiry = time.Until(expiry.Time)
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, rows.Err()
+}
+
+func (s *sqlStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&s.options)
+	}
+	return s.configure()
+}
+
+func (s *sqlStore) Options() store.Options {
+	return s.options
+}
+
+func (s *sqlStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	options := store.ReadOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// earlier revisions of records aren't kept
+	if options.Version > 0 {
+		return nil, store.ErrNotSupported
+	}
+
+	// cursors page by key
+	if options.Cursor != nil && options.OrderBy == store.OrderByExpiry {
+		return nil, store.ErrNotSupported
+	}
+
+	// reads with a pattern return many records, with the key as their prefix by default
+	if options.Match != nil && !options.Suffix {
+		options.Prefix = true
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.Prefix && !options.Suffix {
+		conds, args := where(options.Where, 2)
+		q := fmt.Sprintf("SELECT key, value, metadata, expiry, version FROM %s WHERE key = $1 AND (expiry IS NULL OR expiry > now())%s", table, conds)
+		rows, err := db.Query(q, append([]interface{}{key}, args...)...)
+		if err != nil {
+			return nil, err
+		}
+		records, err := scanRecords(rows)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, store.ErrNotFound
+		}
+		if options.Cursor != nil {
+			*options.Cursor = ""
+		}
+		return records, nil
+	}
+
+	var prefix, suffix string
+	if options.Prefix {
+		prefix = key
+	}
+	if options.Suffix {
+		suffix = key
+	}
+
+	page, args, err := after(options.Cursor, options.Order, 4)
+	if err != nil {
+		return nil, err
+	}
+	matches, matchArgs, err := match(options.Match, 4+len(args))
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, matchArgs...)
+	conds, whereArgs := where(options.Where, 4+len(args))
+	args = append([]interface{}{pattern(prefix, suffix), limit(options.Limit), options.Offset}, append(args, whereArgs...)...)
+
+	q := fmt.Sprintf("SELECT key, value, metadata, expiry, version FROM %s WHERE key LIKE $1 AND (expiry IS NULL OR expiry > now())%s%s%s %s LIMIT $2 OFFSET $3", table, page, matches, conds, orderBy(options.Order, options.OrderBy))
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't read records")
+	}
+
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*store.Record{}
+	}
+
+	if options.Cursor != nil {
+		var last string
+		if len(records) > 0 {
+			last = records[len(records)-1].Key
+		}
+		*options.Cursor = store.NextCursor(options.Limit, len(records), last)
+	}
+
+	return records, nil
+}
+
+func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+
+	return write(db, table, r, options)
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// upsert is the conflict clause used when writing records, rows which have
+// expired but not yet been cleaned up are replaced rather than updated
+const upsert = `ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, metadata = EXCLUDED.metadata, expiry = EXCLUDED.expiry,
+	version = CASE WHEN t.expiry IS NOT NULL AND t.expiry <= now() THEN 1 ELSE t.version + 1 END`
+
+// write upserts the record into the table if the conditions of the write
+// are met, and sets the version of the record to the one written
+func write(ex execer, table string, r *store.Record, opts store.WriteOptions) error {
+	metadata, err := json.Marshal(r.Metadata)
+	if err != nil {
+		return err
+	}
+
+	var expiry pq.NullTime
+	if r.Expiry != 0 {
+		expiry = pq.NullTime{Time: time.Now().Add(r.Expiry), Valid: true}
+	}
+
+	var version uint64
+
+	switch {
+	case opts.IfVersion > 0:
+		q := fmt.Sprintf(`UPDATE %s SET value = $2, metadata = $3, expiry = $4, version = version + 1
+			WHERE key = $1 AND version = $5 AND (expiry IS NULL OR expiry > now()) RETURNING version`, table)
+		err = ex.QueryRow(q, r.Key, r.Value, metadata, expiry, opts.IfVersion).Scan(&version)
+	case opts.IfNotExists:
+		// only a row which has expired can be replaced
+		q := fmt.Sprintf(`INSERT INTO %s AS t (key, value, metadata, expiry, version) VALUES ($1, $2, $3, $4, 1)
+			%s WHERE t.expiry IS NOT NULL AND t.expiry <= now() RETURNING t.version`, table, upsert)
+		err = ex.QueryRow(q, r.Key, r.Value, metadata, expiry).Scan(&version)
+	default:
+		q := fmt.Sprintf(`INSERT INTO %s AS t (key, value, metadata, expiry, version) VALUES ($1, $2, $3, $4, 1)
+			%s RETURNING t.version`, table, upsert)
+		err = ex.QueryRow(q, r.Key, r.Value, metadata, expiry).Scan(&version)
+	}
+	if err == sql.ErrNoRows {
+		return store.ErrConflict
+	} else if err != nil {
+		return errors.Wrap(err, "Couldn't write record "+r.Key)
+	}
+
+	r.Version = version
+	return nil
+}
+
+// WriteMany writes the records using multi-row inserts in a single transaction. None of
+// the records are written if the conditions of the write aren't met by one of them.
+func (s *sqlStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+
+	// a row can't be updated twice by the same insert so only the last write of a key is kept
+	seen := make(map[string]int, len(recs))
+	var rows []*store.Record
+	for _, r := range recs {
+		if i, ok := seen[r.Key]; ok {
+			rows[i] = r
+			continue
+		}
+		seen[r.Key] = len(rows)
+		rows = append(rows, r)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the conditions are checked against each record, so they're written one at a time
+	if options.IfVersion > 0 || options.IfNotExists {
+		for _, r := range recs {
+			if err := write(tx, table, r, options); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}
+
+	versions := make(map[string]uint64, len(rows))
+
+	for len(rows) > 0 {
+		n := len(rows)
+		if n > batchSize {
+			n = batchSize
+		}
+
+		values := make([]string, 0, n)
+		args := make([]interface{}, 0, n*4)
+
+		for i, r := range rows[:n] {
+			metadata, err := json.Marshal(r.Metadata)
+			if err != nil {
+				return err
+			}
+			var expiry pq.NullTime
+			if r.Expiry != 0 {
+				expiry = pq.NullTime{Time: time.Now().Add(r.Expiry), Valid: true}
+			}
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, 1)", i*4+1, i*4+2, i*4+3, i*4+4))
+			args = append(args, r.Key, r.Value, metadata, expiry)
+		}
+
+		q := fmt.Sprintf(`INSERT INTO %s AS t (key, value, metadata, expiry, version) VALUES %s
+			%s RETURNING t.key, t.version`,
+			table, strings.Join(values, ", "), upsert)
+		res, err := tx.Query(q, args...)
+		if err != nil {
+			return errors.Wrap(err, "Couldn't write records")
+		}
+		for res.Next() {
+			var key string
+			var version uint64
+			if err := res.Scan(&key, &version); err != nil {
+				res.Close()
+				return err
+			}
+			versions[key] = version
+		}
+		res.Close()
+		if err := res.Err(); err != nil {
+			return errors.Wrap(err, "Couldn't write records")
+		}
+
+		rows = rows[n:]
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, r := range recs {
+		r.Version = versions[r.Key]
+	}
+	return nil
+}
+
+// Touch sets the expiry of the record with a single update
+func (s *sqlStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.TouchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+
+	var expiresAt pq.NullTime
+	if expiry != 0 {
+		expiresAt = pq.NullTime{Time: time.Now().Add(expiry), Valid: true}
+	}
+
+	q := fmt.Sprintf(`UPDATE %s SET expiry = $2, version = version + 1
+		WHERE key = $1 AND (expiry IS NULL OR expiry > now())`, table)
+	res, err := db.ExecContext(ctx, q, key, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "Couldn't touch record "+key)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqlStore) Delete(key string, opts ...store.DeleteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+
+	if options.Prefix || options.Suffix {
+		var prefix, suffix string
+		if options.Prefix {
+			prefix = key
+		}
+		if options.Suffix {
+			suffix = key
+		}
+		if _, err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE key LIKE $1", table), pattern(prefix, suffix)); err != nil {
+			return errors.Wrap(err, "Couldn't delete records")
+		}
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("DELETE FROM %s WHERE key = $1", table), key)
+	return err
+}
+
+// DeleteMany deletes the records with a single statement
+func (s *sqlStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if s.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE key = ANY($1)", table), pq.Array(keys)); err != nil {
+		return errors.Wrap(err, "Couldn't delete records")
+	}
+	return nil
+}
+
+func (s *sqlStore) List(opts ...store.ListOption) ([]string, error) {
+	options := store.ListOptions{
+		Order: store.OrderAsc,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(options.Delimiter) > 0 {
+		return store.ListDelimited(s, options)
+	}
+
+	// cursors page by key
+	if options.Cursor != nil && options.OrderBy == store.OrderByExpiry {
+		return nil, store.ErrNotSupported
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	page, args, err := after(options.Cursor, options.Order, 4)
+	if err != nil {
+		return nil, err
+	}
+	matches, matchArgs, err := match(options.Match, 4+len(args))
+	if err != nil {
+		return nil, err
+	}
+	args = append([]interface{}{pattern(options.Prefix, options.Suffix), limit(options.Limit), options.Offset}, append(args, matchArgs...)...)
+
+	q := fmt.Sprintf("SELECT key FROM %s WHERE key LIKE $1 AND (expiry IS NULL OR expiry > now())%s%s %s LIMIT $2 OFFSET $3", table, page, matches, orderBy(options.Order, options.OrderBy))
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't list records")
+	}
+	defer rows.Close()
+
+	var keys []string
+
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if options.Cursor != nil {
+		var last string
+		if len(keys) > 0 {
+			last = keys[len(keys)-1]
+		}
+		*options.Cursor = store.NextCursor(options.Limit, len(keys), last)
+	}
+
+	return keys, nil
+}
+
+// Txn runs fn in a postgres transaction. Records read in the transaction are locked until it ends.
+func (s *sqlStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	if s.options.ReadOnly {
+		fn = store.ReadOnlyTxn(fn)
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	table, err := s.table(options.Database, options.Table)
+	if err != nil {
+		return err
+	}
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&sqlTx{tx: tx, table: table}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type sqlTx struct {
+	tx    *sql.Tx
+	table string
+}
+
+func (t *sqlTx) Read(key string) (*store.Record, error) {
+	q := fmt.Sprintf("SELECT key, value, metadata, expiry, version FROM %s WHERE key = $1 AND (expiry IS NULL OR expiry > now()) FOR UPDATE", t.table)
+	rows, err := t.tx.Query(q, key)
+	if err != nil {
+		return nil, err
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return records[0], nil
+}
+
+func (t *sqlTx) Write(r *store.Record) error {
+	return write(t.tx, t.table, r, store.WriteOptions{})
+}
+
+func (t *sqlTx) Delete(key string) error {
+	_, err := t.tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE key = $1", t.table), key)
+	return err
+}
+
+func (s *sqlStore) Close() error {
+	s.once.Do(func() {
+		close(s.exit)
+	})
+
+	s.Lock()
+	defer s.Unlock()
+	if s.db != nil {
+		err := s.db.Close()
+		s.db = nil
+		return err
+	}
+	return nil
+}
+
+// Healthy pings the database
+func (s *sqlStore) Healthy(ctx context.Context) error {
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+func (s *sqlStore) String() string {
+	return "postgres"
+}
+
+// cleanupLoop periodically deletes expired records from the tables used by the store
+func (s *sqlStore) cleanupLoop() {
+	interval := DefaultCleanupInterval
+	if ctx := s.options.Context; ctx != nil {
+		if d, ok := ctx.Value(cleanupIntervalKey{}).(time.Duration); ok && d > 0 {
+			interval = d
+		}
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.exit:
+			return
+		case <-t.C:
+			s.deleteExpired()
+		}
+	}
+}
+
+func (s *sqlStore) deleteExpired() {
+	s.RLock()
+	db := s.db
+	tables := make([]string, 0, len(s.tables))
+	for t := range s.tables {
+		tables = append(tables, t)
+	}
+	s.RUnlock()
+
+	if db == nil {
+		return
+	}
+
+	for _, t := range tables {
+		res, err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE expiry < now()", t))
+		if err != nil {
+			logger.Errorf("Error deleting expired records from %s: %v", t, err)
+			continue
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			logger.Debugf("Deleted %d expired records from %s", n, t)
+		}
+	}
+}
@@ -3,8 +3,12 @@ package store
 import (
 	pb "github.com/micro/micro/v3/proto/store"
 	"github.com/micro/micro/v3/service"
+	"github.com/micro/micro/v3/service/events"
 	log "github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
 	"github.com/micro/micro/v3/service/store/handler"
+	"github.com/micro/micro/v3/service/store/wrapper/metrics"
+	"github.com/micro/micro/v3/service/store/wrapper/trace"
 	"github.com/urfave/cli/v2"
 )
 
@@ -15,6 +19,23 @@ var (
 	address = ":8002"
 )
 
+var (
+	// Flags specific to the store service
+	Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:    "tenancy",
+			EnvVars: []string{"MICRO_STORE_TENANCY"},
+			Usage:   "Choose the database of requests from the namespace of the account: none, namespace or strict",
+			Value:   string(handler.TenancyNone),
+		},
+		&cli.BoolFlag{
+			Name:    "enable_trace",
+			EnvVars: []string{"MICRO_STORE_ENABLE_TRACE"},
+			Usage:   "Start a span for each operation on the store",
+		},
+	}
+)
+
 // Run micro store
 func Run(ctx *cli.Context) error {
 	if len(ctx.String("server_name")) > 0 {
@@ -30,9 +51,28 @@ func Run(ctx *cli.Context) error {
 		service.Address(address),
 	)
 
+	// publish the expiry of records so services can act on it
+	if err := store.DefaultStore.Init(store.ExpiryEvents(events.DefaultStream)); err != nil {
+		log.Fatalf("Error configuring store: %v", err)
+	}
+
+	// report the operations on the store, and trace them if enabled
+	store.DefaultStore = metrics.NewStore(store.DefaultStore)
+	if ctx.Bool("enable_trace") {
+		store.DefaultStore = trace.NewStore(store.DefaultStore)
+	}
+
+	tenancy := handler.Tenancy(ctx.String("tenancy"))
+	switch tenancy {
+	case handler.TenancyNone, handler.TenancyNamespace, handler.TenancyStrict:
+	default:
+		log.Fatalf("Unknown tenancy %s", tenancy)
+	}
+
 	// the store handler
 	pb.RegisterStoreHandler(service.Server(), &handler.Store{
-		Stores: make(map[string]bool),
+		Stores:  make(map[string]bool),
+		Tenancy: tenancy,
 	})
 
 	// the blob store handler
@@ -17,8 +17,13 @@
 package store
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -30,6 +35,27 @@ const (
 	OrderDesc = Order("desc")
 )
 
+// OrderBy is the field records are sorted by
+type OrderBy string
+
+const (
+	// OrderByKey sorts the records by key, which is the default
+	OrderByKey = OrderBy("key")
+	// OrderByExpiry sorts the records by when they expire. Records which don't
+	// expire come after those which do, and records which expire together are
+	// sorted by key.
+	OrderByExpiry = OrderBy("expiry")
+)
+
+// Compression is an algorithm record values are compressed with, see WriteCompress
+type Compression string
+
+const (
+	CompressSnappy = Compression("snappy")
+	CompressGzip   = Compression("gzip")
+	CompressZstd   = Compression("zstd")
+)
+
 var (
 	// DefaultStore implementation
 	DefaultStore Store
@@ -37,6 +63,18 @@ var (
 	DefaultBlobStore BlobStore
 	// ErrNotFound is returned when a key doesn't exist
 	ErrNotFound = errors.New("not found")
+	// ErrNotSupported is returned when the store doesn't support an operation
+	ErrNotSupported = errors.New("not supported")
+	// ErrConflict is returned when a conditional write fails because the record has changed
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidCursor is returned when a cursor wasn't returned by the store
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrReadOnly is returned when changing the records of a store initialised with ReadOnly
+	ErrReadOnly = errors.New("store is read only")
+	// ErrQuotaExceeded is returned when a write would take a database over its quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrInvalidPattern is returned when a pattern set with ReadMatch or ListMatch isn't valid
+	ErrInvalidPattern = errors.New("invalid pattern")
 )
 
 // Store is a data storage interface
@@ -48,8 +86,10 @@ type Store interface {
 	// Read takes a single key name and optional ReadOptions. It returns matching []*Record or an error.
 	Read(key string, opts ...ReadOption) ([]*Record, error)
 	// Write() writes a record to the store, and returns an error if the record was not written.
+	// Stores which support versions set the Version of the record once it has been written.
 	Write(r *Record, opts ...WriteOption) error
-	// Delete removes the record with the corresponding key from the store.
+	// Delete removes the record with the corresponding key from the store. With the DeletePrefix
+	// or DeleteSuffix options all the records matching the key are removed.
 	Delete(key string, opts ...DeleteOption) error
 	// List returns any keys that match, or an empty list with no error if none matched.
 	List(opts ...ListOption) ([]string, error)
@@ -59,6 +99,131 @@ type Store interface {
 	String() string
 }
 
+// BatchWriter is implemented by stores which can write many records in a single operation
+type BatchWriter interface {
+	// WriteMany writes the records to the store, and returns an error if they were not written.
+	WriteMany(recs []*Record, opts ...WriteOption) error
+}
+
+// BatchDeleter is implemented by stores which can delete many records in a single operation
+type BatchDeleter interface {
+	// DeleteMany removes the records with the corresponding keys from the store.
+	DeleteMany(keys []string, opts ...DeleteOption) error
+}
+
+// Tx is a transaction on a single database and table. Changes made through it are only
+// visible to the rest of the store once the transaction has been committed.
+type Tx interface {
+	// Read a record, returns ErrNotFound if the key doesn't exist.
+	Read(key string) (*Record, error)
+	// Write a record as part of the transaction.
+	Write(r *Record) error
+	// Delete a record as part of the transaction.
+	Delete(key string) error
+}
+
+// Transactional is implemented by stores which can apply multi record changes atomically
+type Transactional interface {
+	// Txn runs fn in a transaction. If fn returns an error the transaction is rolled back,
+	// otherwise it is committed.
+	Txn(ctx context.Context, fn func(tx Tx) error, opts ...TxnOption) error
+}
+
+// Toucher is implemented by stores which can change when a record expires without rewriting it
+type Toucher interface {
+	// Touch sets the record with the key to expire after expiry, or to never expire if expiry
+	// is zero. The value and metadata are unchanged, and ErrNotFound is returned if the record
+	// doesn't exist. Stores which support versions count it as a write of the record.
+	Touch(ctx context.Context, key string, expiry time.Duration, opts ...TouchOption) error
+}
+
+// ReadOnlyTxn returns fn with the writes and deletes of its transaction returning ErrReadOnly,
+// stores use it to implement ReadOnly
+func ReadOnlyTxn(fn func(tx Tx) error) func(tx Tx) error {
+	return func(tx Tx) error {
+		return fn(&readOnlyTx{tx})
+	}
+}
+
+type readOnlyTx struct {
+	Tx
+}
+
+func (t *readOnlyTx) Write(r *Record) error {
+	return ErrReadOnly
+}
+
+func (t *readOnlyTx) Delete(key string) error {
+	return ErrReadOnly
+}
+
+// Compactor is implemented by stores whose files don't shrink when records are removed
+type Compactor interface {
+	// Compact rewrites the files of the database and table to give the space left by deleted
+	// and expired records back to the file system. The records are unchanged.
+	Compact(ctx context.Context, opts ...CompactOption) error
+}
+
+// Revision is a write of a key kept by a Versioner
+type Revision struct {
+	// Version numbers the writes of the key from 1, see ReadVersion
+	Version uint64
+	// Created is when the key was written
+	Created time.Time
+	// Deleted is true if the key was deleted rather than written
+	Deleted bool
+	// Record as it was written, nil if the key was deleted
+	Record *Record
+}
+
+// Versioner is implemented by stores which keep the earlier revisions of records, which are
+// read with ReadVersion
+type Versioner interface {
+	// ListVersions returns the revisions of the key kept by the store, oldest first unless
+	// ListOrder is OrderDesc. The database, table, limit and offset of the options are used.
+	ListVersions(key string, opts ...ListOption) ([]*Revision, error)
+}
+
+// HealthChecker is implemented by stores which depend on a database or files they may lose
+// access to
+type HealthChecker interface {
+	// Healthy returns an error if the store can't currently reach its backing storage
+	Healthy(ctx context.Context) error
+}
+
+// EventType is the type of change made to a record
+type EventType string
+
+const (
+	// EventCreate is emitted when a record is written with a new key
+	EventCreate = EventType("create")
+	// EventUpdate is emitted when an existing record is overwritten
+	EventUpdate = EventType("update")
+	// EventDelete is emitted when a record is deleted
+	EventDelete = EventType("delete")
+	// EventExpire is emitted when an expired record is removed from the store
+	EventExpire = EventType("expire")
+)
+
+// Event is a change made to a record in the store
+type Event struct {
+	// Type of change
+	Type EventType
+	// Database and table of the record
+	Database, Table string
+	// The record after a create or update. Only the key is set for deletes and expiries.
+	Record *Record
+	// Time the change was made
+	Timestamp time.Time
+}
+
+// Watcher is implemented by stores which can notify of changes to records
+type Watcher interface {
+	// Watch returns a channel of the changes made to the record with the key, or the records
+	// prefixed by it if the WatchPrefix option is set. The channel is closed once ctx is done.
+	Watch(ctx context.Context, key string, opts ...WatchOption) (<-chan *Event, error)
+}
+
 // Record is an item stored or retrieved from a Store
 type Record struct {
 	// The key to store the record
@@ -69,6 +234,9 @@ type Record struct {
 	Metadata map[string]interface{} `json:"metadata"`
 	// Time to expire a record: TODO: change to timestamp
 	Expiry time.Duration `json:"expiry,omitempty"`
+	// Version is changed by the store each time the record is written, it should only be
+	// compared for equality. It is zero if the store doesn't support versions.
+	Version uint64 `json:"version,omitempty"`
 }
 
 // NewRecord returns a record from key, val
@@ -95,6 +263,127 @@ func (r *Record) Decode(v interface{}) error {
 	return json.Unmarshal(r.Value, v)
 }
 
+// Match returns true if the metadata of the record has all the values in where, see ReadWhere
+func (r *Record) Match(where map[string]interface{}) bool {
+	for k, v := range where {
+		mv, ok := r.Metadata[k]
+		if !ok || fmt.Sprint(mv) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeCursor returns the cursor for the page after the key, stores use it to implement ReadCursor
+func EncodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// DecodeCursor returns the key the page of a cursor starts after, which is
+// empty for the first page
+func DecodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(key), nil
+}
+
+// NextCursor returns the cursor for the page after the one ending with the key. It's
+// empty if the page had fewer than limit items, as there can't be any more.
+func NextCursor(limit uint, n int, last string) string {
+	if limit == 0 || n < int(limit) {
+		return ""
+	}
+	return EncodeCursor(last)
+}
+
+// ListDelimited lists the keys of the store grouped by the delimiter of the options, stores
+// use it to implement ListDelimiter. All the keys matching the prefix and suffix are listed,
+// then the limit, offset and cursor are applied to the groups.
+func ListDelimited(s Store, options ListOptions) ([]string, error) {
+	if options.OrderBy == OrderByExpiry {
+		return nil, ErrNotSupported
+	}
+
+	var after string
+	if options.Cursor != nil {
+		var err error
+		if after, err = DecodeCursor(*options.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := s.List(
+		ListFrom(options.Database, options.Table),
+		ListPrefix(options.Prefix),
+		ListSuffix(options.Suffix),
+		ListOrder(options.Order),
+		func(l *ListOptions) { l.Match = options.Match },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// keys with the same common prefix are next to each other in either order, and the
+	// common prefixes sort in the same order as the keys
+	var groups []string
+	for _, k := range keys {
+		if i := strings.Index(k[len(options.Prefix):], options.Delimiter); i >= 0 {
+			k = k[:len(options.Prefix)+i+len(options.Delimiter)]
+		}
+		if len(groups) > 0 && groups[len(groups)-1] == k {
+			continue
+		}
+		if len(after) > 0 {
+			if options.Order == OrderDesc && k >= after {
+				continue
+			} else if options.Order != OrderDesc && k <= after {
+				continue
+			}
+		}
+		groups = append(groups, k)
+	}
+
+	if options.Offset > uint(len(groups)) {
+		groups = nil
+	} else {
+		groups = groups[options.Offset:]
+	}
+	if options.Limit > 0 && uint(len(groups)) > options.Limit {
+		groups = groups[:options.Limit]
+	}
+
+	if options.Cursor != nil {
+		var last string
+		if len(groups) > 0 {
+			last = groups[len(groups)-1]
+		}
+		*options.Cursor = NextCursor(options.Limit, len(groups), last)
+	}
+	return groups, nil
+}
+
+// SortByExpiry sorts the records by expiry in the order, stores which can't sort by
+// expiry themselves use it to implement OrderByExpiry
+func SortByExpiry(recs []*Record, order Order) {
+	sort.Slice(recs, func(i, j int) bool {
+		a, b := recs[i], recs[j]
+		if order == OrderDesc {
+			a, b = b, a
+		}
+		switch {
+		case a.Expiry == b.Expiry:
+			return a.Key < b.Key
+		case a.Expiry == 0:
+			return false
+		case b.Expiry == 0:
+			return true
+		}
+		return a.Expiry < b.Expiry
+	})
+}
+
 // Read records
 func Read(key string, opts ...ReadOption) ([]*Record, error) {
 	// execute the query
@@ -106,9 +395,92 @@ func Write(r *Record) error {
 	return DefaultStore.Write(r)
 }
 
+// WriteMany writes the records to the store. If the store doesn't implement
+// BatchWriter the records are written one at a time.
+func WriteMany(recs []*Record, opts ...WriteOption) error {
+	return writeMany(DefaultStore, recs, opts...)
+}
+
 // Delete removes the record with the corresponding key from the store.
-func Delete(key string) error {
-	return DefaultStore.Delete(key)
+func Delete(key string, opts ...DeleteOption) error {
+	return DefaultStore.Delete(key, opts...)
+}
+
+// DeleteMany removes the records with the corresponding keys from the store. If the
+// store doesn't implement BatchDeleter the records are deleted one at a time.
+func DeleteMany(keys []string, opts ...DeleteOption) error {
+	if bd, ok := DefaultStore.(BatchDeleter); ok {
+		return bd.DeleteMany(keys, opts...)
+	}
+	for _, k := range keys {
+		if err := DefaultStore.Delete(k, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Txn runs fn in a transaction. If the store doesn't implement Transactional
+// ErrNotSupported is returned.
+func Txn(ctx context.Context, fn func(tx Tx) error, opts ...TxnOption) error {
+	t, ok := DefaultStore.(Transactional)
+	if !ok {
+		return ErrNotSupported
+	}
+	return t.Txn(ctx, fn, opts...)
+}
+
+// Watch the record with the key for changes. If the store doesn't implement Watcher
+// ErrNotSupported is returned.
+func Watch(ctx context.Context, key string, opts ...WatchOption) (<-chan *Event, error) {
+	w, ok := DefaultStore.(Watcher)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return w.Watch(ctx, key, opts...)
+}
+
+// Touch sets the record with the key to expire after expiry. If the store doesn't
+// implement Toucher ErrNotSupported is returned.
+func Touch(ctx context.Context, key string, expiry time.Duration, opts ...TouchOption) error {
+	t, ok := DefaultStore.(Toucher)
+	if !ok {
+		return ErrNotSupported
+	}
+	return t.Touch(ctx, key, expiry, opts...)
+}
+
+// Compact rewrites the files of the database and table to reclaim disk space. If the store
+// doesn't implement Compactor ErrNotSupported is returned.
+func Compact(ctx context.Context, opts ...CompactOption) error {
+	c, ok := DefaultStore.(Compactor)
+	if !ok {
+		return ErrNotSupported
+	}
+	return c.Compact(ctx, opts...)
+}
+
+// ListVersions returns the revisions of the key kept by the default store. If the store
+// doesn't implement Versioner ErrNotSupported is returned.
+func ListVersions(key string, opts ...ListOption) ([]*Revision, error) {
+	v, ok := DefaultStore.(Versioner)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return v.ListVersions(key, opts...)
+}
+
+// Healthy returns an error if the default store can't reach its backing storage. Stores which
+// don't implement HealthChecker are always healthy.
+func Healthy(ctx context.Context) error {
+	if DefaultStore == nil {
+		return nil
+	}
+	h, ok := DefaultStore.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	return h.Healthy(ctx)
 }
 
 // List returns any keys that match, or an empty list with no error if none matched.
@@ -0,0 +1,795 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Original source: github.com/micro/go-micro/v3/store/test/store_test.go
+
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/kr/pretty"
+	"github.com/micro/micro/v3/service/store"
+)
+
+func readTests(s store.Store, t *testing.T) {
+	// Test Table, Suffix and WriteOptions
+	if err := s.Write(&store.Record{
+		Key:    "foofoobarbar",
+		Value:  []byte("something"),
+		Expiry: time.Millisecond * 100,
+	}); err != nil {
+		t.Error(err)
+	}
+	if err := s.Write(&store.Record{
+		Key:    "foofoo",
+		Value:  []byte("something"),
+		Expiry: time.Millisecond * 100,
+	}); err != nil {
+		t.Error(err)
+	}
+	if err := s.Write(&store.Record{
+		Key:    "barbar",
+		Value:  []byte("something"),
+		Expiry: time.Millisecond * 100,
+	}); err != nil {
+		t.Error(err)
+	}
+
+	if results, err := s.Read("foo", store.ReadPrefix(), store.ReadSuffix()); err != nil {
+		t.Error(err)
+	} else {
+		if len(results) != 1 {
+			t.Errorf("Expected 1 results, got %d: %# v", len(results), spew.Sdump(results))
+		}
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	if results, err := s.List(); err != nil {
+		t.Fatalf("List failed: %s", err)
+	} else {
+		if len(results) != 0 {
+			t.Fatalf("Expiry options were not effective, results :%v", spew.Sdump(results))
+		}
+	}
+
+	// write the following records
+	for i := 0; i < 10; i++ {
+		s.Write(&store.Record{
+			Key:   fmt.Sprintf("a%d", i),
+			Value: []byte{},
+		})
+	}
+
+	// read back a few records
+	if results, err := s.Read("a", store.ReadLimit(5), store.ReadPrefix()); err != nil {
+		t.Error(err)
+	} else {
+		if len(results) != 5 {
+			t.Fatal("Expected 5 results, got ", len(results))
+		}
+		if !strings.HasPrefix(results[0].Key, "a") {
+			t.Fatalf("Expected a prefix, got %s", results[0].Key)
+		}
+	}
+
+	// read the rest back
+	if results, err := s.Read("a", store.ReadLimit(30), store.ReadOffset(5), store.ReadPrefix()); err != nil {
+		t.Fatal(err)
+	} else {
+		if len(results) != 5 {
+			t.Fatal("Expected 5 results, got ", len(results))
+		}
+	}
+}
+
+func listTests(s store.Store, t *testing.T) {
+	for i := 0; i < 10; i++ {
+		s.Write(&store.Record{Key: fmt.Sprintf("List%d", i), Value: []byte("bar")})
+	}
+
+	recs, err := s.List(store.ListPrefix("List"))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(recs) != 10 {
+		t.Fatalf("Expected 10 records, received %d", len(recs))
+	}
+
+	recs, err = s.List(store.ListPrefix("List"), store.ListLimit(5))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(recs) != 5 {
+		t.Fatalf("Expected 5 records, received %d", len(recs))
+	}
+
+	recs, err = s.List(store.ListPrefix("List"), store.ListOffset(6))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("Expected 4 records, received %d %+v", len(recs), recs)
+	}
+
+	recs, err = s.List(store.ListPrefix("List"), store.ListOffset(6), store.ListLimit(2))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("Expected 2 records, received %d %+v", len(recs), recs)
+	}
+
+	for i := 0; i < 10; i++ {
+		s.Write(&store.Record{Key: fmt.Sprintf("ListOffset%d", i), Value: []byte("bar")})
+	}
+
+	recs, err = s.List(store.ListPrefix("ListOffset"), store.ListOffset(6))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("Expected 4 records, received %d %+v", len(recs), recs)
+	}
+
+}
+
+func batchTests(s store.Store, t *testing.T) {
+	bw, ok := s.(store.BatchWriter)
+	if !ok {
+		return
+	}
+
+	var recs []*store.Record
+	for i := 0; i < 10; i++ {
+		recs = append(recs, &store.Record{Key: fmt.Sprintf("Batch%d", i), Value: []byte("bar")})
+	}
+	// the last write of a key wins
+	recs = append(recs, &store.Record{Key: "Batch0", Value: []byte("baz")})
+
+	if err := bw.WriteMany(recs); err != nil {
+		t.Fatalf("Error writing records %s", err)
+	}
+
+	keys, err := s.List(store.ListPrefix("Batch"))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(keys) != 10 {
+		t.Fatalf("Expected 10 records, received %d", len(keys))
+	}
+
+	r, err := s.Read("Batch0")
+	if err != nil {
+		t.Fatalf("Error reading record %s", err)
+	}
+	if string(r[0].Value) != "baz" {
+		t.Fatalf("Expected baz, got %s", r[0].Value)
+	}
+
+	// the conditions of the write are checked against each record
+	err = bw.WriteMany([]*store.Record{
+		{Key: "BatchNew", Value: []byte("bar")},
+		{Key: "Batch1", Value: []byte("baz")},
+	}, store.WriteIfNotExists())
+	if err == store.ErrNotSupported {
+		return
+	} else if err != store.ErrConflict {
+		t.Fatalf("Expected conflict writing existing record, got %v", err)
+	}
+	if r, err := s.Read("Batch1"); err != nil || string(r[0].Value) != "bar" {
+		t.Fatalf("Expected Batch1 not to be overwritten, got %v %v", r, err)
+	}
+
+	if stale := recs[2].Version; stale > 0 {
+		if err := bw.WriteMany([]*store.Record{{Key: "Batch2", Value: []byte("baz")}}, store.WriteIfVersion(stale+1)); err != store.ErrConflict {
+			t.Fatalf("Expected conflict writing stale version, got %v", err)
+		}
+		if err := bw.WriteMany([]*store.Record{{Key: "Batch2", Value: []byte("baz")}}, store.WriteIfVersion(stale)); err != nil {
+			t.Fatalf("Error writing records %s", err)
+		}
+	}
+}
+
+func deleteTests(s store.Store, t *testing.T) {
+	for _, k := range []string{"DelA1", "DelA2", "DelB1", "DelB2", "DelC1"} {
+		if err := s.Write(&store.Record{Key: k, Value: []byte("bar")}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+
+	if err := s.Delete("DelA", store.DeletePrefix()); err != nil {
+		t.Fatalf("Error deleting records %s", err)
+	}
+	keys, err := s.List(store.ListPrefix("Del"))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 records, received %d %v", len(keys), keys)
+	}
+
+	if err := s.Delete("2", store.DeleteSuffix()); err != nil {
+		t.Fatalf("Error deleting records %s", err)
+	}
+	if _, err := s.Read("DelB2"); err != store.ErrNotFound {
+		t.Fatalf("Expected DelB2 to be deleted, got %v", err)
+	}
+
+	if bd, ok := s.(store.BatchDeleter); ok {
+		if err := bd.DeleteMany([]string{"DelB1", "DelC1"}); err != nil {
+			t.Fatalf("Error deleting records %s", err)
+		}
+	} else {
+		if err := s.Delete("Del", store.DeletePrefix()); err != nil {
+			t.Fatalf("Error deleting records %s", err)
+		}
+	}
+
+	keys, err = s.List(store.ListPrefix("Del"))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected 0 records, received %d %v", len(keys), keys)
+	}
+}
+
+func txnTests(s store.Store, t *testing.T) {
+	ts, ok := s.(store.Transactional)
+	if !ok {
+		return
+	}
+
+	if err := s.Write(&store.Record{Key: "TxnA", Value: []byte("bar")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+
+	// move TxnA to TxnB
+	err := ts.Txn(context.TODO(), func(tx store.Tx) error {
+		r, err := tx.Read("TxnA")
+		if err != nil {
+			return err
+		}
+		if err := tx.Write(&store.Record{Key: "TxnB", Value: r.Value}); err != nil {
+			return err
+		}
+		return tx.Delete("TxnA")
+	})
+	if err != nil {
+		t.Fatalf("Error running transaction %s", err)
+	}
+	if _, err := s.Read("TxnA"); err != store.ErrNotFound {
+		t.Fatalf("Expected TxnA to be deleted, got %v", err)
+	}
+	r, err := s.Read("TxnB")
+	if err != nil {
+		t.Fatalf("Error reading record %s", err)
+	}
+	if string(r[0].Value) != "bar" {
+		t.Fatalf("Expected bar, got %s", r[0].Value)
+	}
+
+	// a failed transaction is rolled back
+	errTxn := errors.New("rollback")
+	err = ts.Txn(context.TODO(), func(tx store.Tx) error {
+		if err := tx.Write(&store.Record{Key: "TxnC", Value: []byte("bar")}); err != nil {
+			return err
+		}
+		if err := tx.Delete("TxnB"); err != nil {
+			return err
+		}
+		return errTxn
+	})
+	if err != errTxn {
+		t.Fatalf("Expected %s, got %v", errTxn, err)
+	}
+	if _, err := s.Read("TxnC"); err != store.ErrNotFound {
+		t.Fatalf("Expected TxnC not to be written, got %v", err)
+	}
+	if _, err := s.Read("TxnB"); err != nil {
+		t.Fatalf("Expected TxnB not to be deleted, got %v", err)
+	}
+}
+
+func watchTests(s store.Store, t *testing.T) {
+	w, ok := s.(store.Watcher)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	events, err := w.Watch(ctx, "Watch", store.WatchPrefix())
+	if err != nil {
+		t.Fatalf("Error watching %s", err)
+	}
+
+	if err := s.Write(&store.Record{Key: "WatchA", Value: []byte("foo")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if err := s.Write(&store.Record{Key: "WatchA", Value: []byte("bar")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	// not watched
+	if err := s.Write(&store.Record{Key: "OtherA", Value: []byte("bar")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if err := s.Delete("WatchA"); err != nil {
+		t.Fatalf("Error deleting record %s", err)
+	}
+
+	for _, exp := range []store.EventType{store.EventCreate, store.EventUpdate, store.EventDelete} {
+		select {
+		case ev := <-events:
+			if ev.Type != exp {
+				t.Fatalf("Expected %s event, got %s", exp, ev.Type)
+			}
+			if ev.Record.Key != "WatchA" {
+				t.Fatalf("Expected event for WatchA, got %s", ev.Record.Key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s event", exp)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func casTests(s store.Store, t *testing.T) {
+	r := &store.Record{Key: "CasA", Value: []byte("foo")}
+	if err := s.Write(r, store.WriteIfNotExists()); err == store.ErrNotSupported {
+		t.Skip("Conditional writes aren't supported")
+	} else if err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if r.Version == 0 {
+		// versions aren't supported
+		return
+	}
+
+	recs, err := s.Read("CasA")
+	if err != nil {
+		t.Fatalf("Error reading record %s", err)
+	}
+	if recs[0].Version != r.Version {
+		t.Fatalf("Expected version %d, got %d", r.Version, recs[0].Version)
+	}
+
+	if err := s.Write(&store.Record{Key: "CasA", Value: []byte("bar")}, store.WriteIfNotExists()); err != store.ErrConflict {
+		t.Fatalf("Expected conflict writing existing record, got %v", err)
+	}
+
+	stale := r.Version
+	r.Value = []byte("bar")
+	if err := s.Write(r, store.WriteIfVersion(stale)); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if r.Version == stale {
+		t.Fatalf("Expected version to change from %d", stale)
+	}
+
+	if err := s.Write(&store.Record{Key: "CasA", Value: []byte("baz")}, store.WriteIfVersion(stale)); err != store.ErrConflict {
+		t.Fatalf("Expected conflict writing stale version, got %v", err)
+	}
+	if err := s.Write(&store.Record{Key: "CasB", Value: []byte("baz")}, store.WriteIfVersion(stale)); err != store.ErrConflict {
+		t.Fatalf("Expected conflict writing missing record, got %v", err)
+	}
+
+	recs, err = s.Read("CasA")
+	if err != nil {
+		t.Fatalf("Error reading record %s", err)
+	}
+	if string(recs[0].Value) != "bar" || recs[0].Version != r.Version {
+		t.Fatalf("Expected bar at version %d, got %s at %d", r.Version, recs[0].Value, recs[0].Version)
+	}
+}
+
+func whereTests(s store.Store, t *testing.T) {
+	recs := []*store.Record{
+		{Key: "WhereA", Value: []byte("a"), Metadata: map[string]interface{}{"status": "active", "rank": 1}},
+		{Key: "WhereB", Value: []byte("b"), Metadata: map[string]interface{}{"status": "inactive", "rank": 2}},
+		{Key: "WhereC", Value: []byte("c"), Metadata: map[string]interface{}{"status": "active", "rank": 3}},
+		{Key: "WhereD", Value: []byte("d"), Metadata: map[string]interface{}{"status": "active", "rank": 4}, Expiry: 50 * time.Millisecond},
+		{Key: "OtherE", Value: []byte("e"), Metadata: map[string]interface{}{"status": "active"}},
+	}
+	for _, r := range recs {
+		if err := s.Write(r); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	keys := func(recs []*store.Record) string {
+		var k []string
+		for _, r := range recs {
+			k = append(k, r.Key)
+		}
+		return strings.Join(k, ",")
+	}
+
+	got, err := s.Read("Where", store.ReadPrefix(), store.ReadWhere("status", "active"))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if k := keys(got); k != "WhereA,WhereC" {
+		t.Fatalf("Expected WhereA,WhereC, got %s", k)
+	}
+
+	// the limit and offset apply to the matching records
+	got, err = s.Read("Where", store.ReadPrefix(), store.ReadWhere("status", "active"), store.ReadOrder(store.OrderDesc), store.ReadLimit(1))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if k := keys(got); k != "WhereC" {
+		t.Fatalf("Expected WhereC, got %s", k)
+	}
+	got, err = s.Read("Where", store.ReadPrefix(), store.ReadWhere("status", "active"), store.ReadOffset(1))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if k := keys(got); k != "WhereC" {
+		t.Fatalf("Expected WhereC, got %s", k)
+	}
+
+	// values are compared as strings and all the fields must match
+	got, err = s.Read("Where", store.ReadPrefix(), store.ReadWhere("status", "active"), store.ReadWhere("rank", "3"))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if k := keys(got); k != "WhereC" {
+		t.Fatalf("Expected WhereC, got %s", k)
+	}
+
+	if _, err := s.Read("WhereB", store.ReadWhere("status", "active")); err != store.ErrNotFound {
+		t.Fatalf("Expected WhereB not to match, got %v", err)
+	}
+	if got, err := s.Read("WhereB", store.ReadWhere("status", "inactive")); err != nil || len(got) != 1 {
+		t.Fatalf("Expected WhereB to match, got %v %v", got, err)
+	}
+}
+
+func cursorTests(s store.Store, t *testing.T) {
+	for _, k := range []string{"CursorA", "CursorB", "CursorC", "CursorD", "CursorE"} {
+		if err := s.Write(&store.Record{Key: k, Value: []byte(k)}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+
+	// page through the records two at a time, a write between the pages
+	// doesn't shift the next page
+	var cursor string
+	var pages []string
+	for i := 0; i < 5; i++ {
+		recs, err := s.Read("Cursor", store.ReadPrefix(), store.ReadLimit(2), store.ReadCursor(&cursor))
+		if err == store.ErrNotSupported && i == 0 {
+			t.Skip("Cursors aren't supported")
+		} else if err != nil {
+			t.Fatalf("Error reading records %s", err)
+		}
+		var page []string
+		for _, r := range recs {
+			page = append(page, r.Key)
+		}
+		pages = append(pages, strings.Join(page, ","))
+		if i == 0 {
+			if err := s.Write(&store.Record{Key: "Cursor0", Value: []byte("0")}); err != nil {
+				t.Fatalf("Error writing record %s", err)
+			}
+		}
+		if cursor == "" {
+			break
+		}
+	}
+	if p := strings.Join(pages, "|"); p != "CursorA,CursorB|CursorC,CursorD|CursorE" {
+		t.Fatalf("Expected CursorA,CursorB|CursorC,CursorD|CursorE, got %s", p)
+	}
+
+	cursor = ""
+	pages = nil
+	for i := 0; i < 5; i++ {
+		keys, err := s.List(store.ListPrefix("Cursor"), store.ListOrder(store.OrderDesc), store.ListLimit(4), store.ListCursor(&cursor))
+		if err != nil {
+			t.Fatalf("Error listing keys %s", err)
+		}
+		pages = append(pages, strings.Join(keys, ","))
+		if cursor == "" {
+			break
+		}
+	}
+	if p := strings.Join(pages, "|"); p != "CursorE,CursorD,CursorC,CursorB|CursorA,Cursor0" {
+		t.Fatalf("Expected CursorE,CursorD,CursorC,CursorB|CursorA,Cursor0, got %s", p)
+	}
+
+	cursor = "not a cursor!"
+	if _, err := s.List(store.ListPrefix("Cursor"), store.ListCursor(&cursor)); err != store.ErrInvalidCursor {
+		t.Fatalf("Expected %v, got %v", store.ErrInvalidCursor, err)
+	}
+}
+
+func orderByTests(s store.Store, t *testing.T) {
+	recs := []*store.Record{
+		{Key: "OrderA", Value: []byte("a"), Expiry: 30 * time.Second},
+		{Key: "OrderB", Value: []byte("b"), Expiry: 10 * time.Second},
+		{Key: "OrderC", Value: []byte("c")},
+		{Key: "OrderD", Value: []byte("d"), Expiry: 20 * time.Second},
+	}
+	for _, r := range recs {
+		if err := s.Write(r); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+
+	keys := func(recs []*store.Record) string {
+		var k []string
+		for _, r := range recs {
+			k = append(k, r.Key)
+		}
+		return strings.Join(k, ",")
+	}
+
+	// records which don't expire are sorted last
+	got, err := s.Read("Order", store.ReadPrefix(), store.ReadOrderBy(store.OrderByExpiry))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if k := keys(got); k != "OrderB,OrderD,OrderA,OrderC" {
+		t.Fatalf("Expected OrderB,OrderD,OrderA,OrderC, got %s", k)
+	}
+
+	// the limit and offset apply after sorting
+	got, err = s.Read("Order", store.ReadPrefix(), store.ReadOrderBy(store.OrderByExpiry), store.ReadOrder(store.OrderDesc), store.ReadLimit(2))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if k := keys(got); k != "OrderC,OrderA" {
+		t.Fatalf("Expected OrderC,OrderA, got %s", k)
+	}
+
+	list, err := s.List(store.ListPrefix("Order"), store.ListOrderBy(store.OrderByExpiry), store.ListOffset(1), store.ListLimit(2))
+	if err != nil {
+		t.Fatalf("Error listing keys %s", err)
+	}
+	if k := strings.Join(list, ","); k != "OrderD,OrderA" {
+		t.Fatalf("Expected OrderD,OrderA, got %s", k)
+	}
+
+	var cursor string
+	if _, err := s.List(store.ListPrefix("Order"), store.ListOrderBy(store.OrderByExpiry), store.ListCursor(&cursor)); err != store.ErrNotSupported {
+		t.Fatalf("Expected %v, got %v", store.ErrNotSupported, err)
+	}
+}
+
+func touchTests(s store.Store, t *testing.T) {
+	ts, ok := s.(store.Toucher)
+	if !ok {
+		return
+	}
+
+	r := &store.Record{Key: "TouchA", Value: []byte("foo"), Metadata: map[string]interface{}{"bar": "baz"}, Expiry: 100 * time.Millisecond}
+	if err := s.Write(r); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+
+	// the record outlives its original expiry and is unchanged
+	if err := ts.Touch(context.TODO(), "TouchA", time.Minute); err != nil {
+		t.Fatalf("Error touching record %s", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	recs, err := s.Read("TouchA")
+	if err != nil {
+		t.Fatalf("Error reading touched record %s", err)
+	}
+	if string(recs[0].Value) != "foo" || recs[0].Metadata["bar"] != "baz" {
+		t.Fatalf("Expected the record to be unchanged, got %s %v", recs[0].Value, recs[0].Metadata)
+	}
+	if recs[0].Expiry < 50*time.Second {
+		t.Fatalf("Expected an expiry of about a minute, got %v", recs[0].Expiry)
+	}
+	if r.Version > 0 && recs[0].Version <= r.Version {
+		t.Fatalf("Expected the version to change from %d, got %d", r.Version, recs[0].Version)
+	}
+
+	// an expiry of zero removes it
+	if err := ts.Touch(context.TODO(), "TouchA", 0); err != nil {
+		t.Fatalf("Error touching record %s", err)
+	}
+	if recs, err := s.Read("TouchA"); err != nil || recs[0].Expiry != 0 {
+		t.Fatalf("Expected the record not to expire, got %v %v", recs, err)
+	}
+
+	if err := ts.Touch(context.TODO(), "TouchA", 50*time.Millisecond); err != nil {
+		t.Fatalf("Error touching record %s", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := s.Read("TouchA"); err != store.ErrNotFound {
+		t.Fatalf("Expected the record to expire, got %v", err)
+	}
+
+	if err := ts.Touch(context.TODO(), "TouchMissing", time.Minute); err != store.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", store.ErrNotFound, err)
+	}
+}
+
+func expiryTests(s store.Store, t *testing.T) {
+	// Read and Write an expiring Record
+	if err := s.Write(&store.Record{
+		Key:    "Hello",
+		Value:  []byte("World"),
+		Expiry: time.Millisecond * 150,
+	}); err != nil {
+		t.Error(err)
+	}
+
+	if r, err := s.Read("Hello"); err != nil {
+		t.Fatal(err)
+	} else {
+		if len(r) != 1 {
+			t.Error("Read returned multiple records")
+		}
+		if r[0].Key != "Hello" {
+			t.Errorf("Expected %s, got %s", "Hello", r[0].Key)
+		}
+		if string(r[0].Value) != "World" {
+			t.Errorf("Expected %s, got %s", "World", r[0].Value)
+		}
+	}
+
+	// wait for expiry
+	time.Sleep(time.Millisecond * 200)
+
+	if _, err := s.Read("Hello"); err != store.ErrNotFound {
+		t.Errorf("Expected %# v, got %# v", store.ErrNotFound, err)
+	}
+
+	// exercise the records expiry
+	s.Write(&store.Record{Key: "aaa", Value: []byte("bbb"), Expiry: 1 * time.Second})
+	s.Write(&store.Record{Key: "aaaa", Value: []byte("bbb"), Expiry: 1 * time.Second})
+	s.Write(&store.Record{Key: "aaaaa", Value: []byte("bbb"), Expiry: 1 * time.Second})
+	results, err := s.Read("a", store.ReadPrefix())
+	if err != nil {
+		t.Error(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Results should have returned 3 records, returned %d", len(results))
+	}
+	time.Sleep(1 * time.Second)
+	results, err = s.Read("a", store.ReadPrefix())
+	if err != nil {
+		t.Error(err)
+	}
+	if len(results) != 0 {
+		t.Fatal("Results should have returned 0 records")
+	}
+}
+
+func suffixPrefixExpiryTests(s store.Store, t *testing.T) {
+	// Write 3 records with various expiry and get with Prefix
+	records := []*store.Record{
+		&store.Record{
+			Key:   "foo",
+			Value: []byte("foofoo"),
+		},
+		&store.Record{
+			Key:    "foobar",
+			Value:  []byte("foobarfoobar"),
+			Expiry: 1 * time.Second,
+		},
+	}
+
+	for _, r := range records {
+		if err := s.Write(r); err != nil {
+			t.Errorf("Couldn't write k: %s, v: %# v (%s)", r.Key, pretty.Formatter(r.Value), err)
+		}
+	}
+
+	if results, err := s.Read("foo", store.ReadPrefix()); err != nil {
+		t.Errorf("Couldn't read all \"foo\" keys, got %#v (%s)", spew.Sdump(results), err)
+	} else {
+		if len(results) != 2 {
+			t.Errorf("Expected 2 items, got %d", len(results))
+		}
+	}
+
+	// wait for the expiry
+	time.Sleep(1 * time.Second)
+
+	if results, err := s.Read("foo", store.ReadPrefix()); err != nil {
+		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
+	} else if len(results) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(results))
+	}
+
+	if err := s.Delete("foo"); err != nil {
+		t.Errorf("Delete failed (%v)", err)
+	}
+
+	if results, err := s.Read("foo"); err != store.ErrNotFound {
+		t.Errorf("Expected read failure read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
+	} else {
+		if len(results) != 0 {
+			t.Errorf("Expected 0 items, got %d (%# v)", len(results), spew.Sdump(results))
+		}
+	}
+
+	// Write 3 records with various expiry and get with Suffix
+	records = []*store.Record{
+		&store.Record{
+			Key:   "foo",
+			Value: []byte("foofoo"),
+		},
+		&store.Record{
+			Key:   "barfoo",
+			Value: []byte("barfoobarfoo"),
+
+			Expiry: time.Second * 1,
+		},
+		&store.Record{
+			Key:    "bazbarfoo",
+			Value:  []byte("bazbarfoobazbarfoo"),
+			Expiry: 2 * time.Second,
+		},
+	}
+	for _, r := range records {
+		if err := s.Write(r); err != nil {
+			t.Errorf("Couldn't write k: %s, v: %# v (%s)", r.Key, pretty.Formatter(r.Value), err)
+		}
+	}
+	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
+		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
+	} else {
+		if len(results) != 3 {
+			t.Errorf("Expected 3 items, got %d", len(results))
+			//t.Logf("Table test: %v\n", spew.Sdump(results))
+		}
+
+	}
+	time.Sleep(time.Second * 1)
+	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
+		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
+	} else {
+		if len(results) != 2 {
+			t.Errorf("Expected 2 items, got %d", len(results))
+			//t.Logf("Table test: %v\n", spew.Sdump(results))
+		}
+
+	}
+	time.Sleep(time.Second * 1)
+	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
+		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
+	} else {
+		if len(results) != 1 {
+			t.Errorf("Expected 1 item, got %d", len(results))
+			//	t.Logf("Table test: %# v\n", spew.Sdump(results))
+		}
+	}
+	if err := s.Delete("foo"); err != nil {
+		t.Errorf("Delete failed (%v)", err)
+	}
+	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
+		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
+	} else {
+		if len(results) != 0 {
+			t.Errorf("Expected 0 items, got %d (%# v)", len(results), spew.Sdump(results))
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Package storetest tests implementations of the store interface behave as the rest of micro
+// expects. Store plugins run the conformance tests from their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Conformance(t, func() store.Store {
+//			return mystore.NewStore(store.Nodes(addr))
+//		})
+//	}
+//
+// Features the store returns store.ErrNotSupported for, such as cursors or transactions, are
+// skipped.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+// concurrency is the number of goroutines using the store at once in the concurrency tests
+const concurrency = 10
+
+// Conformance runs all the tests against stores returned by newStore, which must return a
+// new store each time it's called. The stores are initialised with the databases and tables
+// of the tests, and the records written are deleted once they've finished.
+func Conformance(t *testing.T, newStore func() store.Store) {
+	tables := []struct {
+		name string
+		opts []store.Option
+	}{
+		{name: "Default"},
+		{name: "Table", opts: []store.Option{store.Table("conformance")}},
+		{name: "Database", opts: []store.Option{store.Database("conformance")}},
+		{name: "DatabaseTable", opts: []store.Option{store.Database("conformance"), store.Table("conformance")}},
+	}
+	for _, tc := range tables {
+		t.Run(tc.name, func(t *testing.T) {
+			s := setup(t, newStore, tc.opts...)
+			Run(t, s)
+		})
+	}
+
+	t.Run("ReInit", func(t *testing.T) {
+		s := setup(t, newStore, store.Table("conformance"))
+		if err := s.Init(store.Table("conformance_reinit")); err != nil {
+			t.Fatalf("Error initialising store %s", err)
+		}
+		if s.Options().Table != "conformance_reinit" {
+			t.Fatal("Init didn't reinitialise the store")
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		paginationTests(setup(t, newStore), t)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		concurrencyTests(setup(t, newStore), t)
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		h, ok := setup(t, newStore).(store.HealthChecker)
+		if !ok {
+			t.Skip("Health checks aren't supported")
+		}
+		if err := h.Healthy(context.TODO()); err != nil {
+			t.Fatalf("Expected the store to be healthy, got %s", err)
+		}
+	})
+}
+
+// setup returns a new store initialised with the options, which is emptied and closed when
+// the test finishes
+func setup(t *testing.T, newStore func() store.Store, opts ...store.Option) store.Store {
+	s := newStore()
+	if err := s.Init(opts...); err != nil {
+		t.Fatalf("Error initialising store %s", err)
+	}
+	t.Cleanup(func() {
+		o := s.Options()
+		keys, _ := s.List()
+		for _, k := range keys {
+			s.Delete(k, store.DeleteFrom(o.Database, o.Table))
+		}
+		s.Close()
+	})
+	return s
+}
+
+// Run runs the tests against the store as it's been initialised. The records written aren't
+// deleted, so the tables should be empty when it's called.
+func Run(t *testing.T, s store.Store) {
+	if len(os.Getenv("IN_TRAVIS_CI")) == 0 {
+		t.Logf("Options %s %v\n", s.String(), s.Options())
+	}
+
+	tests := []struct {
+		name string
+		fn   func(store.Store, *testing.T)
+	}{
+		{"Expiry", expiryTests},
+		{"SuffixPrefixExpiry", suffixPrefixExpiryTests},
+		{"Read", readTests},
+		{"List", listTests},
+		{"Batch", batchTests},
+		{"Delete", deleteTests},
+		{"Txn", txnTests},
+		{"Watch", watchTests},
+		{"CAS", casTests},
+		{"Where", whereTests},
+		{"Cursor", cursorTests},
+		{"OrderBy", orderByTests},
+		{"Touch", touchTests},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(s, t)
+		})
+	}
+}
+
+// Where runs the tests of reads filtered by metadata against the store
+func Where(t *testing.T, s store.Store) {
+	whereTests(s, t)
+}
+
+func paginationTests(s store.Store, t *testing.T) {
+	for i := 0; i < 25; i++ {
+		k := fmt.Sprintf("Page%02d", i)
+		if err := s.Write(&store.Record{Key: k, Value: []byte(k)}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+
+	// every key is read once whatever the size of the pages
+	for _, limit := range []uint{1, 7, 25, 30} {
+		var keys []string
+		for offset := uint(0); offset < 30; offset += limit {
+			page, err := s.List(store.ListPrefix("Page"), store.ListLimit(limit), store.ListOffset(offset))
+			if err != nil {
+				t.Fatalf("Error listing keys %s", err)
+			}
+			if uint(len(page)) > limit {
+				t.Fatalf("Expected at most %d keys, got %d", limit, len(page))
+			}
+			keys = append(keys, page...)
+		}
+		if len(keys) != 25 {
+			t.Fatalf("Expected 25 keys with a limit of %d, got %d", limit, len(keys))
+		}
+		for i, k := range keys {
+			if exp := fmt.Sprintf("Page%02d", i); k != exp {
+				t.Fatalf("Expected %s with a limit of %d, got %s", exp, limit, k)
+			}
+		}
+	}
+
+	recs, err := s.Read("Page", store.ReadPrefix(), store.ReadLimit(5), store.ReadOffset(20))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if len(recs) != 5 || recs[0].Key != "Page20" || recs[4].Key != "Page24" {
+		t.Fatalf("Expected Page20 to Page24, got %v", recs)
+	}
+
+	recs, err = s.Read("Page", store.ReadPrefix(), store.ReadLimit(5), store.ReadOffset(30))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if len(recs) != 0 {
+		t.Fatalf("Expected no records past the end, got %v", recs)
+	}
+}
+
+func concurrencyTests(s store.Store, t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				// each goroutine writes its own keys and one they all share
+				k := fmt.Sprintf("Concurrent%d-%d", i, j)
+				if err := s.Write(&store.Record{Key: k, Value: []byte(k)}); err != nil {
+					errs <- err
+					return
+				}
+				if err := s.Write(&store.Record{Key: "ConcurrentShared", Value: []byte(k)}); err != nil {
+					errs <- err
+					return
+				}
+				recs, err := s.Read(k)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if string(recs[0].Value) != k {
+					errs <- fmt.Errorf("expected %s to have its value, got %s", k, recs[0].Value)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("Error using the store concurrently %s", err)
+	}
+
+	keys, err := s.List(store.ListPrefix("Concurrent"))
+	if err != nil {
+		t.Fatalf("Error listing keys %s", err)
+	}
+	if exp := concurrency*10 + 1; len(keys) != exp {
+		t.Fatalf("Expected %d keys, got %d", exp, len(keys))
+	}
+}
@@ -0,0 +1,14 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+)
+
+func TestConformance(t *testing.T) {
+	Conformance(t, func() store.Store {
+		return memory.NewStore()
+	})
+}
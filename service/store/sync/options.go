@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+// Consistency is how many of the stores a change is made to before it returns
+type Consistency int
+
+const (
+	// WriteAll makes changes to all the stores before returning, the default
+	WriteAll Consistency = iota
+	// WriteQuorum returns once the change has been made to a majority of the stores,
+	// including the primary
+	WriteQuorum
+	// WriteAsync returns once the change has been made to the primary, the replicas
+	// are changed in the background in the order the changes were made
+	WriteAsync
+)
+
+type consistencyKey struct{}
+type queueSizeKey struct{}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// WithConsistency sets how many of the stores a change is made to before it returns
+func WithConsistency(c Consistency) store.Option {
+	return setOption(consistencyKey{}, c)
+}
+
+// QueueSize is the number of changes queued for each replica with WriteAsync, changes
+// block once it's full. It defaults to 1024.
+func QueueSize(n int) store.Option {
+	return setOption(queueSizeKey{}, n)
+}
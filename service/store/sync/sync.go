@@ -0,0 +1,477 @@
+// Package sync is a store which replicates the changes made to a primary store to other
+// stores, for example to migrate the records of a file store to cockroach without downtime.
+// The primary checks the conditions of writes and sets the versions of records, reads are
+// made from it and only fail over to the replicas if it errors.
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+)
+
+// defaultQueueSize is the number of changes queued for each replica with WriteAsync
+const defaultQueueSize = 1024
+
+// change is made to the primary store and then replicated to the others
+type change func(s store.Store) error
+
+type syncStore struct {
+	primary  store.Store
+	replicas []store.Store
+	options  store.Options
+
+	sync.RWMutex
+	consistency Consistency
+	// changes waiting to be made to each replica with WriteAsync
+	queues []chan change
+	wg     sync.WaitGroup
+}
+
+// NewStore returns a store which makes changes to the primary and then to the replicas
+func NewStore(primary store.Store, replicas []store.Store, opts ...store.Option) store.Store {
+	s := &syncStore{
+		primary:  primary,
+		replicas: replicas,
+	}
+	s.init(opts...)
+	return s
+}
+
+func (s *syncStore) init(opts ...store.Option) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, o := range opts {
+		o(&s.options)
+	}
+	if s.options.Context == nil {
+		return
+	}
+	if c, ok := s.options.Context.Value(consistencyKey{}).(Consistency); ok {
+		s.consistency = c
+	}
+	if s.consistency != WriteAsync || s.queues != nil {
+		return
+	}
+
+	size, _ := s.options.Context.Value(queueSizeKey{}).(int)
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	s.queues = make([]chan change, len(s.replicas))
+	for i, r := range s.replicas {
+		s.queues[i] = make(chan change, size)
+		s.wg.Add(1)
+		go s.replicateAsync(r, s.queues[i])
+	}
+}
+
+// replicateAsync makes the queued changes to the replica until the queue is closed
+func (s *syncStore) replicateAsync(r store.Store, queue <-chan change) {
+	defer s.wg.Done()
+	for fn := range queue {
+		if err := fn(r); err != nil {
+			logger.Errorf("Error replicating a change to the %s store: %v", r.String(), err)
+		}
+	}
+}
+
+// replicate makes the change to the replicas once it's been made to the primary
+func (s *syncStore) replicate(fn change) error {
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.replicas) == 0 {
+		return nil
+	}
+	if s.consistency == WriteAsync && s.queues != nil {
+		for _, q := range s.queues {
+			q <- fn
+		}
+		return nil
+	}
+
+	errs := make(chan error, len(s.replicas))
+	for _, r := range s.replicas {
+		go func(r store.Store) {
+			errs <- fn(r)
+		}(r)
+	}
+
+	// a quorum is a majority of all the stores, one of which is the primary
+	need := len(s.replicas)
+	if s.consistency == WriteQuorum {
+		need = (len(s.replicas) + 1) / 2
+	}
+
+	var done, failed int
+	var firstErr error
+	for done < need {
+		err := <-errs
+		if err == nil {
+			done++
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if failed++; len(s.replicas)-failed < need {
+			return fmt.Errorf("replicated to %d of %d stores: %v", done+1, len(s.replicas)+1, firstErr)
+		}
+	}
+	return nil
+}
+
+// failover returns true if the error from a read means the next store should be tried
+func failover(err error) bool {
+	switch err {
+	case nil, store.ErrNotFound, store.ErrNotSupported, store.ErrInvalidCursor:
+		return false
+	}
+	return true
+}
+
+// Init initialises the primary and replica stores
+func (s *syncStore) Init(opts ...store.Option) error {
+	s.init(opts...)
+	if err := s.primary.Init(opts...); err != nil {
+		return err
+	}
+	for _, r := range s.replicas {
+		if err := r.Init(opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options allows you to view the current options.
+func (s *syncStore) Options() store.Options {
+	return s.options
+}
+
+// Read from the primary, or the first replica which doesn't error if the primary does
+func (s *syncStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	recs, err := s.primary.Read(key, opts...)
+	if !failover(err) {
+		return recs, err
+	}
+	for _, r := range s.replicas {
+		if recs, rerr := r.Read(key, opts...); !failover(rerr) {
+			return recs, rerr
+		}
+	}
+	return nil, err
+}
+
+// List from the primary, or the first replica which doesn't error if the primary does
+func (s *syncStore) List(opts ...store.ListOption) ([]string, error) {
+	keys, err := s.primary.List(opts...)
+	if !failover(err) {
+		return keys, err
+	}
+	for _, r := range s.replicas {
+		if keys, rerr := r.List(opts...); !failover(rerr) {
+			return keys, rerr
+		}
+	}
+	return nil, err
+}
+
+// replicaWriteOptions returns the options to write to the replicas with. The conditions
+// have been checked by the primary, and the versions of the replicas differ from it.
+func replicaWriteOptions(opts []store.WriteOption) []store.WriteOption {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	ropts := []store.WriteOption{store.WriteTo(options.Database, options.Table)}
+	if len(options.Compress) > 0 {
+		ropts = append(ropts, store.WriteCompress(options.Compress))
+	}
+	return ropts
+}
+
+// copyRecord returns a copy of the record for the replicas, so their versions don't
+// change the one written and it can't be changed before they're written
+func copyRecord(r *store.Record) *store.Record {
+	rec := *r
+	rec.Value = append([]byte(nil), r.Value...)
+	if r.Metadata != nil {
+		rec.Metadata = make(map[string]interface{}, len(r.Metadata))
+		for k, v := range r.Metadata {
+			rec.Metadata[k] = v
+		}
+	}
+	return &rec
+}
+
+// Write the record to the primary, which sets its version, and then to the replicas
+func (s *syncStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if err := s.primary.Write(r, opts...); err != nil {
+		return err
+	}
+	rec := copyRecord(r)
+	ropts := replicaWriteOptions(opts)
+	return s.replicate(func(rs store.Store) error {
+		return rs.Write(copyRecord(rec), ropts...)
+	})
+}
+
+// WriteMany writes the records to the primary and then to the replicas, in a single
+// operation for the stores which support it
+func (s *syncStore) WriteMany(recs []*store.Record, opts ...store.WriteOption) error {
+	if err := writeMany(s.primary, recs, opts...); err != nil {
+		return err
+	}
+	copies := make([]*store.Record, len(recs))
+	for i, r := range recs {
+		copies[i] = copyRecord(r)
+	}
+	ropts := replicaWriteOptions(opts)
+	return s.replicate(func(rs store.Store) error {
+		batch := make([]*store.Record, len(copies))
+		for i, r := range copies {
+			batch[i] = copyRecord(r)
+		}
+		return writeMany(rs, batch, ropts...)
+	})
+}
+
+func writeMany(s store.Store, recs []*store.Record, opts ...store.WriteOption) error {
+	if bw, ok := s.(store.BatchWriter); ok {
+		return bw.WriteMany(recs, opts...)
+	}
+	for _, r := range recs {
+		if err := s.Write(r, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete the records from the primary and then from the replicas, the records not
+// being found in a replica isn't an error
+func (s *syncStore) Delete(key string, opts ...store.DeleteOption) error {
+	if err := s.primary.Delete(key, opts...); err != nil {
+		return err
+	}
+	return s.replicate(func(rs store.Store) error {
+		if err := rs.Delete(key, opts...); err != store.ErrNotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteMany removes the records from the primary and then from the replicas, in a
+// single operation for the stores which support it
+func (s *syncStore) DeleteMany(keys []string, opts ...store.DeleteOption) error {
+	if err := deleteMany(s.primary, keys, opts...); err != nil {
+		return err
+	}
+	keys = append([]string(nil), keys...)
+	return s.replicate(func(rs store.Store) error {
+		if err := deleteMany(rs, keys, opts...); err != store.ErrNotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+func deleteMany(s store.Store, keys []string, opts ...store.DeleteOption) error {
+	if bd, ok := s.(store.BatchDeleter); ok {
+		return bd.DeleteMany(keys, opts...)
+	}
+	for _, k := range keys {
+		if err := s.Delete(k, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Txn runs fn in a transaction on the primary. Once it has been committed the changes it
+// made are replicated, in a transaction for the replicas which support them. If the primary
+// doesn't support transactions store.ErrNotSupported is returned.
+func (s *syncStore) Txn(ctx context.Context, fn func(tx store.Tx) error, opts ...store.TxnOption) error {
+	t, ok := s.primary.(store.Transactional)
+	if !ok {
+		return store.ErrNotSupported
+	}
+
+	var options store.TxnOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var rtx *syncTx
+	err := t.Txn(ctx, func(tx store.Tx) error {
+		rtx = &syncTx{Tx: tx}
+		return fn(rtx)
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	if len(rtx.changes) == 0 {
+		return nil
+	}
+
+	changes := rtx.changes
+	return s.replicate(func(rs store.Store) error {
+		apply := func(tx store.Tx) error {
+			for _, c := range changes {
+				if err := c(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if rt, ok := rs.(store.Transactional); ok {
+			return rt.Txn(context.Background(), apply, opts...)
+		}
+		return apply(&storeTx{s: rs, options: options})
+	})
+}
+
+// syncTx records the changes made in a transaction so they can be replicated
+type syncTx struct {
+	store.Tx
+	changes []func(tx store.Tx) error
+}
+
+func (t *syncTx) Write(r *store.Record) error {
+	if err := t.Tx.Write(r); err != nil {
+		return err
+	}
+	rec := copyRecord(r)
+	t.changes = append(t.changes, func(tx store.Tx) error {
+		return tx.Write(copyRecord(rec))
+	})
+	return nil
+}
+
+func (t *syncTx) Delete(key string) error {
+	if err := t.Tx.Delete(key); err != nil {
+		return err
+	}
+	t.changes = append(t.changes, func(tx store.Tx) error {
+		if err := tx.Delete(key); err != store.ErrNotFound {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+// storeTx makes the changes of a transaction to a replica which doesn't support them one at a time
+type storeTx struct {
+	s       store.Store
+	options store.TxnOptions
+}
+
+func (t *storeTx) Read(key string) (*store.Record, error) {
+	recs, err := t.s.Read(key, store.ReadFrom(t.options.Database, t.options.Table))
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return recs[0], nil
+}
+
+func (t *storeTx) Write(r *store.Record) error {
+	return t.s.Write(r, store.WriteTo(t.options.Database, t.options.Table))
+}
+
+func (t *storeTx) Delete(key string) error {
+	return t.s.Delete(key, store.DeleteFrom(t.options.Database, t.options.Table))
+}
+
+// Watch the primary for changes. If it doesn't support watches store.ErrNotSupported is returned.
+func (s *syncStore) Watch(ctx context.Context, key string, opts ...store.WatchOption) (<-chan *store.Event, error) {
+	w, ok := s.primary.(store.Watcher)
+	if !ok {
+		return nil, store.ErrNotSupported
+	}
+	return w.Watch(ctx, key, opts...)
+}
+
+// Touch sets the expiry of the record in the primary and then in the replicas. If the
+// primary doesn't support it store.ErrNotSupported is returned, replicas which don't
+// fail the change.
+func (s *syncStore) Touch(ctx context.Context, key string, expiry time.Duration, opts ...store.TouchOption) error {
+	t, ok := s.primary.(store.Toucher)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	if err := t.Touch(ctx, key, expiry, opts...); err != nil {
+		return err
+	}
+	return s.replicate(func(rs store.Store) error {
+		rt, ok := rs.(store.Toucher)
+		if !ok {
+			return store.ErrNotSupported
+		}
+		return rt.Touch(context.Background(), key, expiry, opts...)
+	})
+}
+
+// Compact the stores which support it, if none do store.ErrNotSupported is returned
+func (s *syncStore) Compact(ctx context.Context, opts ...store.CompactOption) error {
+	err := store.ErrNotSupported
+	for _, st := range append([]store.Store{s.primary}, s.replicas...) {
+		c, ok := st.(store.Compactor)
+		if !ok {
+			continue
+		}
+		if err = c.Compact(ctx, opts...); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Healthy checks the primary and replicas which support it
+func (s *syncStore) Healthy(ctx context.Context) error {
+	for _, st := range append([]store.Store{s.primary}, s.replicas...) {
+		h, ok := st.(store.HealthChecker)
+		if !ok {
+			continue
+		}
+		if err := h.Healthy(ctx); err != nil {
+			return fmt.Errorf("%s store: %w", st.String(), err)
+		}
+	}
+	return nil
+}
+
+// Close waits for the queued changes to be made to the replicas and closes the stores
+func (s *syncStore) Close() error {
+	s.Lock()
+	for _, q := range s.queues {
+		close(q)
+	}
+	s.queues = nil
+	s.Unlock()
+	s.wg.Wait()
+
+	err := s.primary.Close()
+	for _, r := range s.replicas {
+		if rerr := r.Close(); err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// String returns the name of the implementation.
+func (s *syncStore) String() string {
+	return "sync"
+}
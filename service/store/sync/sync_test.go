@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+)
+
+// failStore is a store whose reads and writes fail
+type failStore struct {
+	store.Store
+}
+
+var errFail = errors.New("fail")
+
+func (f *failStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	return nil, errFail
+}
+
+func (f *failStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	return errFail
+}
+
+func (f *failStore) Delete(key string, opts ...store.DeleteOption) error {
+	return errFail
+}
+
+func TestWriteAll(t *testing.T) {
+	primary, replica := memory.NewStore(), memory.NewStore()
+	s := NewStore(primary, []store.Store{replica})
+	defer s.Close()
+
+	rec := &store.Record{Key: "foo", Value: []byte("bar")}
+	if err := s.Write(rec); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if rec.Version == 0 {
+		t.Errorf("Expected the version to be set by the primary")
+	}
+	if recs, err := replica.Read("foo"); err != nil || string(recs[0].Value) != "bar" {
+		t.Fatalf("Expected the record to be replicated, got %v %v", recs, err)
+	}
+
+	// the conditions of writes are only checked by the primary
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("baz")}, store.WriteIfNotExists()); err != store.ErrConflict {
+		t.Fatalf("Expected a conflict, got %v", err)
+	}
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("baz")}, store.WriteIfVersion(rec.Version)); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if recs, _ := replica.Read("foo"); string(recs[0].Value) != "baz" {
+		t.Fatalf("Expected the conditional write to be replicated, got %v", recs)
+	}
+
+	if err := s.Delete("foo"); err != nil {
+		t.Fatalf("Error deleting record %s", err)
+	}
+	if _, err := replica.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("Expected the delete to be replicated, got %v", err)
+	}
+
+	// all the replicas must be written to
+	s = NewStore(memory.NewStore(), []store.Store{&failStore{memory.NewStore()}})
+	if err := s.Write(&store.Record{Key: "foo"}); err == nil {
+		t.Fatalf("Expected an error writing to a failing replica")
+	}
+}
+
+func TestWriteQuorum(t *testing.T) {
+	replica := memory.NewStore()
+	s := NewStore(memory.NewStore(), []store.Store{replica, &failStore{memory.NewStore()}}, WithConsistency(WriteQuorum))
+	defer s.Close()
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatalf("Expected 2 of 3 stores to be a quorum, got %v", err)
+	}
+
+	s = NewStore(memory.NewStore(), []store.Store{&failStore{replica}, &failStore{replica}}, WithConsistency(WriteQuorum))
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err == nil {
+		t.Fatalf("Expected 1 of 3 stores not to be a quorum")
+	}
+}
+
+func TestWriteAsync(t *testing.T) {
+	replica := memory.NewStore()
+	s := NewStore(memory.NewStore(), []store.Store{replica}, WithConsistency(WriteAsync))
+	defer s.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		if err := s.Write(&store.Record{Key: k}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+	if err := s.Delete("b"); err != nil {
+		t.Fatalf("Error deleting record %s", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		keys, _ := replica.List()
+		if len(keys) == 2 && keys[0] == "a" && keys[1] == "c" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the changes to be replicated, got %v", keys)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReadFailover(t *testing.T) {
+	replica := memory.NewStore()
+	if err := replica.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+
+	s := NewStore(&failStore{memory.NewStore()}, []store.Store{replica})
+	defer s.Close()
+
+	recs, err := s.Read("foo")
+	if err != nil || len(recs) != 1 || string(recs[0].Value) != "bar" {
+		t.Fatalf("Expected the read to fail over to the replica, got %v %v", recs, err)
+	}
+
+	// records which aren't found in the primary aren't read from the replicas
+	s = NewStore(memory.NewStore(), []store.Store{replica})
+	if _, err := s.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/file"
+	"github.com/micro/micro/v3/service/store/memory"
+)
+
+func TestBackupRestore(t *testing.T) {
+	from := memory.NewStore()
+	defer from.Close()
+	to := file.NewStore(store.Database("backupdb"))
+	defer fileStoreCleanup("backupdb", to)
+
+	recs := []*store.Record{
+		{Key: "a", Value: []byte("foo"), Metadata: map[string]interface{}{"status": "active"}},
+		{Key: "b", Value: []byte("bar"), Expiry: time.Hour},
+		{Key: "c", Value: []byte("baz"), Expiry: time.Millisecond * 50},
+	}
+	for _, r := range recs {
+		if err := from.Write(r, store.WriteTo("backupdb", "users")); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+	if err := from.Write(&store.Record{Key: "d", Value: []byte("qux")}, store.WriteTo("backupdb", "posts")); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+
+	var buf bytes.Buffer
+	err := store.BackupStore(context.TODO(), from, &buf,
+		store.BackupTable("backupdb", "users"),
+		store.BackupTable("backupdb", "posts"),
+	)
+	if err != nil {
+		t.Fatalf("Error backing up the store %s", err)
+	}
+
+	// c expires before the backup is restored
+	time.Sleep(time.Millisecond * 100)
+
+	if err := store.RestoreStore(context.TODO(), to, &buf); err != nil {
+		t.Fatalf("Error restoring the store %s", err)
+	}
+
+	got, err := to.Read("", store.ReadPrefix(), store.ReadFrom("backupdb", "users"))
+	if err != nil {
+		t.Fatalf("Error reading records %s", err)
+	}
+	if len(got) != 2 || got[0].Key != "a" || got[1].Key != "b" {
+		t.Fatalf("Expected a and b, got %v", got)
+	}
+	if string(got[0].Value) != "foo" || got[0].Metadata["status"] != "active" {
+		t.Errorf("Expected the value and metadata of a to be restored, got %v", got[0])
+	}
+	if got[1].Expiry <= 0 || got[1].Expiry > time.Hour {
+		t.Errorf("Expected b to expire within the hour, got %v", got[1].Expiry)
+	}
+
+	got, err = to.Read("d", store.ReadFrom("backupdb", "posts"))
+	if err != nil || len(got) != 1 || string(got[0].Value) != "qux" {
+		t.Fatalf("Expected d to be restored, got %v %v", got, err)
+	}
+}
+
+func TestRestoreTable(t *testing.T) {
+	from := memory.NewStore(store.Database("backupdb"), store.Table("users"))
+	defer from.Close()
+	to := memory.NewStore()
+	defer to.Close()
+
+	if err := from.Write(&store.Record{Key: "a", Value: []byte("foo")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := store.BackupStore(context.TODO(), from, &buf); err != nil {
+		t.Fatalf("Error backing up the store %s", err)
+	}
+	if err := store.RestoreStore(context.TODO(), to, &buf, store.RestoreTable("backupdb", "posts")); err != nil {
+		t.Fatalf("Error restoring the store %s", err)
+	}
+
+	if _, err := to.Read("a", store.ReadFrom("backupdb", "users")); err != store.ErrNotFound {
+		t.Fatalf("Expected the users table not to be restored, got %v", err)
+	}
+}
+
+func TestBackupPages(t *testing.T) {
+	from := memory.NewStore()
+	defer from.Close()
+	to := memory.NewStore()
+	defer to.Close()
+
+	for i := 0; i < 250; i++ {
+		if err := from.Write(&store.Record{Key: fmt.Sprintf("%03d", i), Value: []byte("foo")}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := store.BackupStore(context.TODO(), from, &buf); err != nil {
+		t.Fatalf("Error backing up the store %s", err)
+	}
+
+	// the records are written a page at a time after the manifest
+	gr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Error reading backup %s", err)
+	}
+	tr := tar.NewReader(gr)
+	var files []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Error reading backup %s", err)
+		}
+		files = append(files, hdr.Name)
+	}
+	if f := strings.Join(files, ","); f != "manifest.json,tables/0/0.jsonl,tables/0/1.jsonl,tables/0/2.jsonl" {
+		t.Fatalf("Expected the manifest and three pages, got %s", f)
+	}
+
+	if err := store.RestoreStore(context.TODO(), to, &buf); err != nil {
+		t.Fatalf("Error restoring the store %s", err)
+	}
+	keys, err := to.List()
+	if err != nil {
+		t.Fatalf("Error listing keys %s", err)
+	}
+	if len(keys) != 250 {
+		t.Fatalf("Expected 250 keys to be restored, got %d", len(keys))
+	}
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+)
+
+func TestIterate(t *testing.T) {
+	defer func(s store.Store) { store.DefaultStore = s }(store.DefaultStore)
+	store.DefaultStore = memory.NewStore()
+	defer store.DefaultStore.Close()
+
+	for _, k := range []string{"a1", "a2", "a3", "b1"} {
+		if err := store.Write(&store.Record{Key: k, Value: []byte("foo")}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+
+	it, err := store.Iterate(context.TODO(), "a", store.ReadPrefix())
+	if err != nil {
+		t.Fatalf("Error iterating %s", err)
+	}
+	defer it.Close()
+
+	var keys []string
+	for {
+		r, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Error reading the next record %s", err)
+		}
+		keys = append(keys, r.Key)
+	}
+	if len(keys) != 3 || keys[0] != "a1" || keys[2] != "a3" {
+		t.Fatalf("Expected a1, a2 and a3, got %v", keys)
+	}
+
+	if _, err := store.Iterate(context.TODO(), "missing"); err != store.ErrNotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
@@ -17,19 +17,24 @@
 package test
 
 import (
-	"fmt"
+	"context"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
-	"github.com/kr/pretty"
 	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/badger"
 	"github.com/micro/micro/v3/service/store/cache"
+	"github.com/micro/micro/v3/service/store/compress"
 	"github.com/micro/micro/v3/service/store/file"
 	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/micro/micro/v3/service/store/postgres"
+	"github.com/micro/micro/v3/service/store/storetest"
+	"github.com/micro/micro/v3/service/store/sync"
+	"github.com/micro/micro/v3/service/store/versioned"
+	"github.com/micro/micro/v3/service/store/wrapper/metrics"
+	"github.com/micro/micro/v3/service/store/wrapper/trace"
 )
 
 func fileStoreCleanup(db string, s store.Store) {
@@ -38,7 +43,13 @@ func fileStoreCleanup(db string, s store.Store) {
 	os.RemoveAll(dir)
 }
 
-func cockroachStoreCleanup(db string, s store.Store) {
+func badgerCleanup(db string, s store.Store) {
+	s.Close()
+	dir := filepath.Join(badger.DefaultDir, db+"/")
+	os.RemoveAll(dir)
+}
+
+func postgresCleanup(db string, s store.Store) {
 	keys, _ := s.List()
 	for _, k := range keys {
 		s.Delete(k)
@@ -54,13 +65,27 @@ func cacheCleanup(db string, s store.Store) {
 	s.Close()
 }
 
+type testCase struct {
+	name    string
+	s       store.Store
+	cleanup func(db string, s store.Store)
+}
+
+// withPostgres adds the postgres store to the test cases when
+// MICRO_STORE_POSTGRES_ADDRESS points at a running database
+func withPostgres(tcs []testCase, opts ...store.Option) []testCase {
+	addr := os.Getenv("MICRO_STORE_POSTGRES_ADDRESS")
+	if len(addr) == 0 {
+		return tcs
+	}
+	opts = append(opts, store.Nodes(addr))
+	return append(tcs, testCase{name: "postgres", s: postgres.NewStore(opts...), cleanup: postgresCleanup})
+}
+
 func TestStoreReInit(t *testing.T) {
-	tcs := []struct {
-		name    string
-		s       store.Store
-		cleanup func(db string, s store.Store)
-	}{
+	tcs := []testCase{
 		{name: "file", s: file.NewStore(store.Table("aaa")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Table("aaa")), cleanup: badgerCleanup},
 		{name: "memory", s: memory.NewStore(store.Table("aaa")), cleanup: memoryCleanup},
 		{name: "cache", s: cache.NewStore(memory.NewStore(store.Table("aaa"))), cleanup: cacheCleanup},
 	}
@@ -76,373 +101,288 @@ func TestStoreReInit(t *testing.T) {
 }
 
 func TestStoreBasic(t *testing.T) {
-	tcs := []struct {
-		name    string
-		s       store.Store
-		cleanup func(db string, s store.Store)
-	}{
+	tcs := []testCase{
 		{name: "file", s: file.NewStore(), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(), cleanup: badgerCleanup},
 		{name: "memory", s: memory.NewStore(), cleanup: memoryCleanup},
 		{name: "cache", s: cache.NewStore(memory.NewStore()), cleanup: cacheCleanup},
+		{name: "compress", s: compress.NewStore(file.NewStore(), compress.Default(store.CompressZstd)), cleanup: fileStoreCleanup},
+		{name: "sync", s: sync.NewStore(memory.NewStore(), []store.Store{memory.NewStore()}), cleanup: memoryCleanup},
+		{name: "versioned", s: versioned.NewStore(memory.NewStore()), cleanup: memoryCleanup},
+		{name: "metrics", s: metrics.NewStore(memory.NewStore()), cleanup: memoryCleanup},
+		{name: "trace", s: trace.NewStore(memory.NewStore()), cleanup: memoryCleanup},
 	}
+	tcs = withPostgres(tcs)
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
 			defer tc.cleanup(file.DefaultDatabase, tc.s)
-			runStoreTest(tc.s, t)
+			storetest.Run(t, tc.s)
 		})
 	}
 
 }
 
-func TestStoreTable(t *testing.T) {
-	tcs := []struct {
-		name    string
-		s       store.Store
-		cleanup func(db string, s store.Store)
-	}{
-		{name: "file", s: file.NewStore(store.Table("testTable")), cleanup: fileStoreCleanup},
-		{name: "memory", s: memory.NewStore(store.Table("testTable")), cleanup: memoryCleanup},
-		{name: "cache", s: cache.NewStore(memory.NewStore(store.Table("testTable"))), cleanup: cacheCleanup},
+func TestStoreIndex(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(), cleanup: fileStoreCleanup},
 	}
+	tcs = withPostgres(tcs)
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
 			defer tc.cleanup(file.DefaultDatabase, tc.s)
-			runStoreTest(tc.s, t)
-		})
-	}
-}
 
-func TestStoreDatabase(t *testing.T) {
-	tcs := []struct {
-		name    string
-		s       store.Store
-		cleanup func(db string, s store.Store)
-	}{
-		{name: "file", s: file.NewStore(store.Database("testdb")), cleanup: fileStoreCleanup},
-		{name: "memory", s: memory.NewStore(store.Database("testdb")), cleanup: memoryCleanup},
-		{name: "cache", s: cache.NewStore(memory.NewStore(store.Database("testdb"))), cleanup: cacheCleanup},
-	}
-	for _, tc := range tcs {
-		t.Run(tc.name, func(t *testing.T) {
-			defer tc.cleanup("testdb", tc.s)
-			runStoreTest(tc.s, t)
+			// records written before the index is declared are indexed too
+			if err := tc.s.Write(&store.Record{Key: "IndexA", Metadata: map[string]interface{}{"status": "old"}}); err != nil {
+				t.Fatalf("Error writing record %s", err)
+			}
+			if err := tc.s.Init(store.Index("status")); err != nil {
+				t.Fatalf("Error initialising store %s", err)
+			}
+			recs, err := tc.s.Read("Index", store.ReadPrefix(), store.ReadWhere("status", "old"))
+			if err != nil {
+				t.Fatalf("Error reading records %s", err)
+			}
+			if len(recs) != 1 || recs[0].Key != "IndexA" {
+				t.Fatalf("Expected IndexA, got %v", recs)
+			}
+
+			// the index is updated when the record changes
+			if err := tc.s.Write(&store.Record{Key: "IndexA", Metadata: map[string]interface{}{"status": "new"}}); err != nil {
+				t.Fatalf("Error writing record %s", err)
+			}
+			if recs, err := tc.s.Read("Index", store.ReadPrefix(), store.ReadWhere("status", "old")); err != nil || len(recs) != 0 {
+				t.Fatalf("Expected no records, got %v %v", recs, err)
+			}
+
+			storetest.Where(t, tc.s)
 		})
 	}
 }
 
-func TestStoreDatabaseTable(t *testing.T) {
-	tcs := []struct {
-		name    string
-		s       store.Store
-		cleanup func(db string, s store.Store)
-	}{
-		{name: "file", s: file.NewStore(store.Database("testdb"), store.Table("testTable")), cleanup: fileStoreCleanup},
-		{name: "memory", s: memory.NewStore(store.Database("testdb"), store.Table("testTable")), cleanup: memoryCleanup},
-		{name: "cache", s: cache.NewStore(memory.NewStore(store.Database("testdb"), store.Table("testTable"))), cleanup: cacheCleanup},
+func TestStoreReadOnly(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(store.Table("readonly")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Table("readonly")), cleanup: badgerCleanup},
+		{name: "memory", s: memory.NewStore(store.Table("readonly")), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore(store.Table("readonly"))), cleanup: cacheCleanup},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			defer tc.cleanup("testdb", tc.s)
-			runStoreTest(tc.s, t)
-		})
-	}
-}
+			defer tc.cleanup(file.DefaultDatabase, tc.s)
 
-func runStoreTest(s store.Store, t *testing.T) {
-	if len(os.Getenv("IN_TRAVIS_CI")) == 0 {
-		t.Logf("Options %s %v\n", s.String(), s.Options())
-	}
+			if err := tc.s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+				t.Fatalf("Error writing record %s", err)
+			}
+			if err := tc.s.Init(store.ReadOnly()); err != nil {
+				t.Fatalf("Error initialising store %s", err)
+			}
 
-	expiryTests(s, t)
-	suffixPrefixExpiryTests(s, t)
-	readTests(s, t)
-	listTests(s, t)
+			if err := tc.s.Write(&store.Record{Key: "foo", Value: []byte("baz")}); err != store.ErrReadOnly {
+				t.Errorf("Expected write to return ErrReadOnly, got %v", err)
+			}
+			if err := tc.s.Delete("foo"); err != store.ErrReadOnly {
+				t.Errorf("Expected delete to return ErrReadOnly, got %v", err)
+			}
+			if to, ok := tc.s.(store.Toucher); ok {
+				if err := to.Touch(context.TODO(), "foo", time.Hour); err != store.ErrReadOnly {
+					t.Errorf("Expected touch to return ErrReadOnly, got %v", err)
+				}
+			}
+			if tr, ok := tc.s.(store.Transactional); ok {
+				err := tr.Txn(context.TODO(), func(tx store.Tx) error {
+					if _, err := tx.Read("foo"); err != nil {
+						return err
+					}
+					return tx.Write(&store.Record{Key: "foo"})
+				})
+				if err != store.ErrReadOnly {
+					t.Errorf("Expected txn write to return ErrReadOnly, got %v", err)
+				}
+			}
 
+			recs, err := tc.s.Read("foo")
+			if err != nil || len(recs) != 1 || string(recs[0].Value) != "bar" {
+				t.Fatalf("Expected the record to be unchanged, got %v %v", recs, err)
+			}
+		})
+	}
 }
 
-func readTests(s store.Store, t *testing.T) {
-	// Test Table, Suffix and WriteOptions
-	if err := s.Write(&store.Record{
-		Key:    "foofoobarbar",
-		Value:  []byte("something"),
-		Expiry: time.Millisecond * 100,
-	}); err != nil {
-		t.Error(err)
-	}
-	if err := s.Write(&store.Record{
-		Key:    "foofoo",
-		Value:  []byte("something"),
-		Expiry: time.Millisecond * 100,
-	}); err != nil {
-		t.Error(err)
-	}
-	if err := s.Write(&store.Record{
-		Key:    "barbar",
-		Value:  []byte("something"),
-		Expiry: time.Millisecond * 100,
-	}); err != nil {
-		t.Error(err)
+func TestStoreListDelimiter(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(store.Table("delimiter")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Table("delimiter")), cleanup: badgerCleanup},
+		{name: "memory", s: memory.NewStore(store.Table("delimiter")), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore(store.Table("delimiter"))), cleanup: cacheCleanup},
 	}
+	tcs = withPostgres(tcs, store.Table("delimiter"))
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup(file.DefaultDatabase, tc.s)
 
-	if results, err := s.Read("foo", store.ReadPrefix(), store.ReadSuffix()); err != nil {
-		t.Error(err)
-	} else {
-		if len(results) != 1 {
-			t.Errorf("Expected 1 results, got %d: %# v", len(results), spew.Sdump(results))
-		}
-	}
+			for _, k := range []string{"users/a", "users/b/1", "users/b/2", "users/c/1", "users/c/d/1", "posts/1"} {
+				if err := tc.s.Write(&store.Record{Key: k, Value: []byte("foo")}); err != nil {
+					t.Fatalf("Error writing record %s", err)
+				}
+			}
 
-	time.Sleep(time.Millisecond * 100)
+			keys, err := tc.s.List(store.ListPrefix("users/"), store.ListDelimiter("/"))
+			if err != nil {
+				t.Fatalf("Error listing keys %s", err)
+			}
+			if exp := []string{"users/a", "users/b/", "users/c/"}; !equalKeys(keys, exp) {
+				t.Fatalf("Expected %v, got %v", exp, keys)
+			}
 
-	if results, err := s.List(); err != nil {
-		t.Fatalf("List failed: %s", err)
-	} else {
-		if len(results) != 0 {
-			t.Fatalf("Expiry options were not effective, results :%v", spew.Sdump(results))
-		}
-	}
+			keys, err = tc.s.List(store.ListPrefix("users/c/"), store.ListDelimiter("/"))
+			if err != nil {
+				t.Fatalf("Error listing keys %s", err)
+			}
+			if exp := []string{"users/c/1", "users/c/d/"}; !equalKeys(keys, exp) {
+				t.Fatalf("Expected %v, got %v", exp, keys)
+			}
 
-	// write the following records
-	for i := 0; i < 10; i++ {
-		s.Write(&store.Record{
-			Key:   fmt.Sprintf("a%d", i),
-			Value: []byte{},
+			// the groups are paged through
+			var cursor string
+			var pages [][]string
+			for {
+				keys, err := tc.s.List(store.ListDelimiter("/"), store.ListLimit(1), store.ListCursor(&cursor))
+				if err != nil {
+					t.Fatalf("Error listing keys %s", err)
+				}
+				pages = append(pages, keys)
+				if len(cursor) == 0 {
+					break
+				}
+			}
+			if len(pages) != 3 || !equalKeys(pages[0], []string{"posts/"}) || !equalKeys(pages[1], []string{"users/"}) || len(pages[2]) != 0 {
+				t.Fatalf("Expected pages posts/ and users/, got %v", pages)
+			}
 		})
 	}
-
-	// read back a few records
-	if results, err := s.Read("a", store.ReadLimit(5), store.ReadPrefix()); err != nil {
-		t.Error(err)
-	} else {
-		if len(results) != 5 {
-			t.Fatal("Expected 5 results, got ", len(results))
-		}
-		if !strings.HasPrefix(results[0].Key, "a") {
-			t.Fatalf("Expected a prefix, got %s", results[0].Key)
-		}
-	}
-
-	// read the rest back
-	if results, err := s.Read("a", store.ReadLimit(30), store.ReadOffset(5), store.ReadPrefix()); err != nil {
-		t.Fatal(err)
-	} else {
-		if len(results) != 5 {
-			t.Fatal("Expected 5 results, got ", len(results))
-		}
-	}
 }
 
-func listTests(s store.Store, t *testing.T) {
-	for i := 0; i < 10; i++ {
-		s.Write(&store.Record{Key: fmt.Sprintf("List%d", i), Value: []byte("bar")})
+func TestStoreMatch(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(store.Table("match")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Table("match")), cleanup: badgerCleanup},
+		{name: "memory", s: memory.NewStore(store.Table("match")), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore(store.Table("match"))), cleanup: cacheCleanup},
 	}
+	tcs = withPostgres(tcs, store.Table("match"))
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup(file.DefaultDatabase, tc.s)
 
-	recs, err := s.List(store.ListPrefix("List"))
-	if err != nil {
-		t.Fatalf("Error listing records %s", err)
-	}
-	if len(recs) != 10 {
-		t.Fatalf("Expected 10 records, received %d", len(recs))
-	}
+			for _, k := range []string{"user:1:session", "user:1:profile", "user:22:session", "users:1:session", "user_1:session"} {
+				if err := tc.s.Write(&store.Record{Key: k, Value: []byte("foo")}); err != nil {
+					t.Fatalf("Error writing record %s", err)
+				}
+			}
 
-	recs, err = s.List(store.ListPrefix("List"), store.ListLimit(5))
-	if err != nil {
-		t.Fatalf("Error listing records %s", err)
-	}
-	if len(recs) != 5 {
-		t.Fatalf("Expected 5 records, received %d", len(recs))
-	}
+			recs, err := tc.s.Read("", store.ReadMatch("user:*:session"))
+			if err != nil {
+				t.Fatalf("Error reading records %s", err)
+			}
+			keys := make([]string, 0, len(recs))
+			for _, r := range recs {
+				keys = append(keys, r.Key)
+			}
+			if exp := []string{"user:1:session", "user:22:session"}; !equalKeys(keys, exp) {
+				t.Fatalf("Expected %v, got %v", exp, keys)
+			}
 
-	recs, err = s.List(store.ListPrefix("List"), store.ListOffset(6))
-	if err != nil {
-		t.Fatalf("Error listing records %s", err)
-	}
-	if len(recs) != 4 {
-		t.Fatalf("Expected 4 records, received %d %+v", len(recs), recs)
-	}
+			// the key is a prefix of the keys matched
+			recs, err = tc.s.Read("user:2", store.ReadMatch("*:session"))
+			if err != nil {
+				t.Fatalf("Error reading records %s", err)
+			}
+			if len(recs) != 1 || recs[0].Key != "user:22:session" {
+				t.Fatalf("Expected user:22:session, got %v", recs)
+			}
 
-	recs, err = s.List(store.ListPrefix("List"), store.ListOffset(6), store.ListLimit(2))
-	if err != nil {
-		t.Fatalf("Error listing records %s", err)
-	}
-	if len(recs) != 2 {
-		t.Fatalf("Expected 2 records, received %d %+v", len(recs), recs)
-	}
+			keys, err = tc.s.List(store.ListMatch("user?1:*"))
+			if err != nil {
+				t.Fatalf("Error listing keys %s", err)
+			}
+			if exp := []string{"user:1:profile", "user:1:session", "user_1:session"}; !equalKeys(keys, exp) {
+				t.Fatalf("Expected %v, got %v", exp, keys)
+			}
 
-	for i := 0; i < 10; i++ {
-		s.Write(&store.Record{Key: fmt.Sprintf("ListOffset%d", i), Value: []byte("bar")})
-	}
+			keys, err = tc.s.List(store.ListMatchRegexp(`^users?:\d:session$`), store.ListLimit(1), store.ListOffset(1))
+			if err != nil {
+				t.Fatalf("Error listing keys %s", err)
+			}
+			if exp := []string{"users:1:session"}; !equalKeys(keys, exp) {
+				t.Fatalf("Expected %v, got %v", exp, keys)
+			}
 
-	recs, err = s.List(store.ListPrefix("ListOffset"), store.ListOffset(6))
-	if err != nil {
-		t.Fatalf("Error listing records %s", err)
-	}
-	if len(recs) != 4 {
-		t.Fatalf("Expected 4 records, received %d %+v", len(recs), recs)
+			if _, err := tc.s.List(store.ListMatchRegexp("user(")); err != store.ErrInvalidPattern {
+				t.Fatalf("Expected ErrInvalidPattern, got %v", err)
+			}
+			if _, err := tc.s.Read("", store.ReadMatch("user[")); err != store.ErrInvalidPattern {
+				t.Fatalf("Expected ErrInvalidPattern, got %v", err)
+			}
+		})
 	}
-
 }
 
-func expiryTests(s store.Store, t *testing.T) {
-	// Read and Write an expiring Record
-	if err := s.Write(&store.Record{
-		Key:    "Hello",
-		Value:  []byte("World"),
-		Expiry: time.Millisecond * 150,
-	}); err != nil {
-		t.Error(err)
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
 	}
-
-	if r, err := s.Read("Hello"); err != nil {
-		t.Fatal(err)
-	} else {
-		if len(r) != 1 {
-			t.Error("Read returned multiple records")
-		}
-		if r[0].Key != "Hello" {
-			t.Errorf("Expected %s, got %s", "Hello", r[0].Key)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
-		if string(r[0].Value) != "World" {
-			t.Errorf("Expected %s, got %s", "World", r[0].Value)
-		}
-	}
-
-	// wait for expiry
-	time.Sleep(time.Millisecond * 200)
-
-	if _, err := s.Read("Hello"); err != store.ErrNotFound {
-		t.Errorf("Expected %# v, got %# v", store.ErrNotFound, err)
-	}
-
-	// exercise the records expiry
-	s.Write(&store.Record{Key: "aaa", Value: []byte("bbb"), Expiry: 1 * time.Second})
-	s.Write(&store.Record{Key: "aaaa", Value: []byte("bbb"), Expiry: 1 * time.Second})
-	s.Write(&store.Record{Key: "aaaaa", Value: []byte("bbb"), Expiry: 1 * time.Second})
-	results, err := s.Read("a", store.ReadPrefix())
-	if err != nil {
-		t.Error(err)
-	}
-	if len(results) != 3 {
-		t.Fatalf("Results should have returned 3 records, returned %d", len(results))
-	}
-	time.Sleep(1 * time.Second)
-	results, err = s.Read("a", store.ReadPrefix())
-	if err != nil {
-		t.Error(err)
-	}
-	if len(results) != 0 {
-		t.Fatal("Results should have returned 0 records")
 	}
+	return true
 }
 
-func suffixPrefixExpiryTests(s store.Store, t *testing.T) {
-	// Write 3 records with various expiry and get with Prefix
-	records := []*store.Record{
-		&store.Record{
-			Key:   "foo",
-			Value: []byte("foofoo"),
-		},
-		&store.Record{
-			Key:    "foobar",
-			Value:  []byte("foobarfoobar"),
-			Expiry: 1 * time.Second,
-		},
-	}
-
-	for _, r := range records {
-		if err := s.Write(r); err != nil {
-			t.Errorf("Couldn't write k: %s, v: %# v (%s)", r.Key, pretty.Formatter(r.Value), err)
-		}
-	}
-
-	if results, err := s.Read("foo", store.ReadPrefix()); err != nil {
-		t.Errorf("Couldn't read all \"foo\" keys, got %#v (%s)", spew.Sdump(results), err)
-	} else {
-		if len(results) != 2 {
-			t.Errorf("Expected 2 items, got %d", len(results))
-		}
-	}
-
-	// wait for the expiry
-	time.Sleep(1 * time.Second)
-
-	if results, err := s.Read("foo", store.ReadPrefix()); err != nil {
-		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
-	} else if len(results) != 1 {
-		t.Errorf("Expected 1 item, got %d", len(results))
-	}
-
-	if err := s.Delete("foo"); err != nil {
-		t.Errorf("Delete failed (%v)", err)
+func TestStoreTable(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(store.Table("testTable")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Table("testTable")), cleanup: badgerCleanup},
+		{name: "memory", s: memory.NewStore(store.Table("testTable")), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore(store.Table("testTable"))), cleanup: cacheCleanup},
 	}
-
-	if results, err := s.Read("foo"); err != store.ErrNotFound {
-		t.Errorf("Expected read failure read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
-	} else {
-		if len(results) != 0 {
-			t.Errorf("Expected 0 items, got %d (%# v)", len(results), spew.Sdump(results))
-		}
+	tcs = withPostgres(tcs, store.Table("testTable"))
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup(file.DefaultDatabase, tc.s)
+			storetest.Run(t, tc.s)
+		})
 	}
+}
 
-	// Write 3 records with various expiry and get with Suffix
-	records = []*store.Record{
-		&store.Record{
-			Key:   "foo",
-			Value: []byte("foofoo"),
-		},
-		&store.Record{
-			Key:   "barfoo",
-			Value: []byte("barfoobarfoo"),
-
-			Expiry: time.Second * 1,
-		},
-		&store.Record{
-			Key:    "bazbarfoo",
-			Value:  []byte("bazbarfoobazbarfoo"),
-			Expiry: 2 * time.Second,
-		},
-	}
-	for _, r := range records {
-		if err := s.Write(r); err != nil {
-			t.Errorf("Couldn't write k: %s, v: %# v (%s)", r.Key, pretty.Formatter(r.Value), err)
-		}
+func TestStoreDatabase(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(store.Database("testdb")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Database("testdb")), cleanup: badgerCleanup},
+		{name: "memory", s: memory.NewStore(store.Database("testdb")), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore(store.Database("testdb"))), cleanup: cacheCleanup},
 	}
-	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
-		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
-	} else {
-		if len(results) != 3 {
-			t.Errorf("Expected 3 items, got %d", len(results))
-			//t.Logf("Table test: %v\n", spew.Sdump(results))
-		}
-
+	tcs = withPostgres(tcs, store.Database("testdb"))
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup("testdb", tc.s)
+			storetest.Run(t, tc.s)
+		})
 	}
-	time.Sleep(time.Second * 1)
-	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
-		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
-	} else {
-		if len(results) != 2 {
-			t.Errorf("Expected 2 items, got %d", len(results))
-			//t.Logf("Table test: %v\n", spew.Sdump(results))
-		}
+}
 
+func TestStoreDatabaseTable(t *testing.T) {
+	tcs := []testCase{
+		{name: "file", s: file.NewStore(store.Database("testdb"), store.Table("testTable")), cleanup: fileStoreCleanup},
+		{name: "badger", s: badger.NewStore(store.Database("testdb"), store.Table("testTable")), cleanup: badgerCleanup},
+		{name: "memory", s: memory.NewStore(store.Database("testdb"), store.Table("testTable")), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore(store.Database("testdb"), store.Table("testTable"))), cleanup: cacheCleanup},
 	}
-	time.Sleep(time.Second * 1)
-	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
-		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
-	} else {
-		if len(results) != 1 {
-			t.Errorf("Expected 1 item, got %d", len(results))
-			//	t.Logf("Table test: %# v\n", spew.Sdump(results))
-		}
-	}
-	if err := s.Delete("foo"); err != nil {
-		t.Errorf("Delete failed (%v)", err)
-	}
-	if results, err := s.Read("foo", store.ReadSuffix()); err != nil {
-		t.Errorf("Couldn't read all \"foo\" keys, got %# v (%s)", spew.Sdump(results), err)
-	} else {
-		if len(results) != 0 {
-			t.Errorf("Expected 0 items, got %d (%# v)", len(results), spew.Sdump(results))
-		}
+	tcs = withPostgres(tcs, store.Database("testdb"), store.Table("testTable"))
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup("testdb", tc.s)
+			storetest.Run(t, tc.s)
+		})
 	}
 }
@@ -0,0 +1,32 @@
+package tiered
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type warmTTLKey struct{}
+type intervalKey struct{}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// WarmTTL is how long a record stays in the warm tier after it was last written, or read
+// from the cold tier, before it's moved to the cold tier. It defaults to a day.
+func WarmTTL(d time.Duration) store.Option {
+	return setOption(warmTTLKey{}, d)
+}
+
+// Interval sets how often the records which have been in the warm tier for longer than
+// the WarmTTL are moved to the cold tier. It defaults to a minute.
+func Interval(d time.Duration) store.Option {
+	return setOption(intervalKey{}, d)
+}
@@ -0,0 +1,668 @@
+// Package tiered is a store which keeps records in tiers by how recently they were used.
+// Hot records are held in memory, warm records in a store such as the file store, and
+// cold records in a blob store. Records are moved to the cold tier once they haven't been
+// written for the WarmTTL, and back to the warm and hot tiers when they're read.
+package tiered
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/cache"
+)
+
+const (
+	defaultWarmTTL  = 24 * time.Hour
+	defaultInterval = time.Minute
+	// writtenTable is the table of the warm store holding when each record was last written
+	writtenTable = "tiered"
+)
+
+type tiered struct {
+	hot  store.Store // the cache store holding the hot records in memory
+	warm store.Store
+	cold store.BlobStore
+
+	options  store.Options
+	warmTTL  time.Duration
+	interval time.Duration
+
+	// changes take a read lock, moving a record to the cold tier takes the write lock
+	sync.RWMutex
+
+	// guards the loop moving records to the cold tier
+	loop sync.Mutex
+	exit chan bool
+	done chan bool
+}
+
+// written is the record of the writtenTable for a record of the warm tier
+type written struct {
+	Database string    `json:"database"`
+	Table    string    `json:"table"`
+	Key      string    `json:"key"`
+	Time     time.Time `json:"time"`
+}
+
+// coldRecord is a record of the cold tier, the expiry is stored as a time
+type coldRecord struct {
+	Value     []byte                 `json:"value"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ExpiresAt *time.Time             `json:"expires_at,omitempty"`
+}
+
+// NewStore returns a tiered store. The hot records are cached in memory in front of the
+// warm store, which is configured with the options of the cache store such as cache.TTL
+// and cache.MaxEntries. Records are moved from the warm store to the cold blob store.
+func NewStore(warm store.Store, cold store.BlobStore, opts ...store.Option) store.Store {
+	t := &tiered{
+		hot:  cache.NewStore(warm, opts...),
+		warm: warm,
+		cold: cold,
+	}
+	t.init(opts...)
+	return t
+}
+
+func (t *tiered) init(opts ...store.Option) {
+	for _, o := range opts {
+		o(&t.options)
+	}
+
+	t.warmTTL, t.interval = defaultWarmTTL, defaultInterval
+	if t.options.Context != nil {
+		if d, ok := t.options.Context.Value(warmTTLKey{}).(time.Duration); ok && d > 0 {
+			t.warmTTL = d
+		}
+		if d, ok := t.options.Context.Value(intervalKey{}).(time.Duration); ok && d > 0 {
+			t.interval = d
+		}
+	}
+
+	// restart the loop so it uses the interval
+	t.loop.Lock()
+	defer t.loop.Unlock()
+	t.stop()
+	t.exit = make(chan bool)
+	t.done = make(chan bool)
+	go t.run(t.interval, t.exit, t.done)
+}
+
+func (t *tiered) stop() {
+	if t.exit == nil {
+		return
+	}
+	close(t.exit)
+	<-t.done
+	t.exit, t.done = nil, nil
+}
+
+func (t *tiered) run(interval time.Duration, exit, done chan bool) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+			if t.options.ReadOnly {
+				continue
+			}
+			if err := t.Demote(context.Background()); err != nil {
+				logger.Errorf("Error moving records to the cold tier: %v", err)
+			}
+		}
+	}
+}
+
+// names returns the database and table the warm store uses for them
+func (t *tiered) names(database, table string) (string, string) {
+	if len(database) == 0 {
+		database = t.warm.Options().Database
+	}
+	if len(table) == 0 {
+		table = t.warm.Options().Table
+	}
+	return database, table
+}
+
+// coldKey returns the key of a record in the cold tier, the namespace is the database
+func coldKey(table, key string) string {
+	return table + "/" + key
+}
+
+func writtenKey(database, table, key string) string {
+	return url.PathEscape(database) + "/" + url.PathEscape(table) + "/" + key
+}
+
+// touch records that the record was written to the warm tier now
+func (t *tiered) touch(database, table, key string) error {
+	database, table = t.names(database, table)
+	b, err := json.Marshal(&written{Database: database, Table: table, Key: key, Time: time.Now()})
+	if err != nil {
+		return err
+	}
+	rec := &store.Record{Key: writtenKey(database, table, key), Value: b}
+	return t.warm.Write(rec, store.WriteTo("", writtenTable))
+}
+
+// Init initialises the tiers
+func (t *tiered) Init(opts ...store.Option) error {
+	t.init(opts...)
+	return t.hot.Init(opts...)
+}
+
+// Options allows you to view the current options.
+func (t *tiered) Options() store.Options {
+	return t.options
+}
+
+// Read a record from the hot or warm tier, or from the cold tier in which case it's moved
+// back to the others. Records read by prefix or suffix are read from the warm and cold tiers
+// and aren't moved.
+func (t *tiered) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	options := store.ReadOptions{Order: store.OrderAsc}
+	for _, o := range opts {
+		o(&options)
+	}
+	// reads with a pattern return many records, with the key as their prefix by default
+	if options.Match != nil && !options.Suffix {
+		options.Prefix = true
+	}
+	if options.Prefix || options.Suffix {
+		return t.readMany(key, options)
+	}
+
+	recs, err := t.hot.Read(key, opts...)
+	if err != store.ErrNotFound {
+		return recs, err
+	}
+
+	rec, err := t.promote(options.Database, options.Table, key)
+	if err != nil {
+		return nil, err
+	}
+	if !rec.Match(options.Where) {
+		return nil, store.ErrNotFound
+	}
+	return []*store.Record{rec}, nil
+}
+
+// promote moves the record from the cold tier to the warm and hot tiers
+func (t *tiered) promote(database, table, key string) (*store.Record, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	database, table = t.names(database, table)
+	rec, err := t.readCold(database, table, key)
+	if err != nil {
+		return nil, err
+	}
+	// read only stores read cold records where they are
+	if t.options.ReadOnly {
+		return rec, nil
+	}
+
+	if err := t.hot.Write(rec, store.WriteTo(database, table)); err != nil {
+		return nil, err
+	}
+	if err := t.touch(database, table, key); err != nil {
+		return nil, err
+	}
+	if err := t.cold.Delete(coldKey(table, key), store.BlobNamespace(database)); err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// readCold reads the record from the cold tier, deleting it if it has expired
+func (t *tiered) readCold(database, table, key string) (*store.Record, error) {
+	r, err := t.cold.Read(coldKey(table, key), store.BlobNamespace(database))
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	var cr coldRecord
+	if err := json.NewDecoder(r).Decode(&cr); err != nil {
+		return nil, err
+	}
+	rec := &store.Record{Key: key, Value: cr.Value, Metadata: cr.Metadata}
+	if cr.ExpiresAt != nil {
+		if rec.Expiry = time.Until(*cr.ExpiresAt); rec.Expiry <= 0 {
+			t.cold.Delete(coldKey(table, key), store.BlobNamespace(database))
+			return nil, store.ErrNotFound
+		}
+	}
+	return rec, nil
+}
+
+// coldKeys returns the keys of the records of the table in the cold tier matching the filters
+func (t *tiered) coldKeys(database, table, prefix, suffix string) ([]string, error) {
+	keys, err := t.cold.List(store.BlobListNamespace(database), store.BlobListPrefix(coldKey(table, prefix)))
+	if err == store.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, k := range keys {
+		k = strings.TrimPrefix(k, coldKey(table, ""))
+		if strings.HasSuffix(k, suffix) {
+			matched = append(matched, k)
+		}
+	}
+	return matched, nil
+}
+
+// readMany reads the records matching the key from the warm and cold tiers
+func (t *tiered) readMany(key string, options store.ReadOptions) ([]*store.Record, error) {
+	var after string
+	if options.Cursor != nil {
+		var err error
+		if after, err = store.DecodeCursor(*options.Cursor); err != nil {
+			return nil, err
+		}
+		if options.OrderBy == store.OrderByExpiry {
+			return nil, store.ErrNotSupported
+		}
+	}
+
+	var prefix, suffix string
+	readOpts := []store.ReadOption{store.ReadFrom(options.Database, options.Table)}
+	if options.Prefix {
+		prefix = key
+		readOpts = append(readOpts, store.ReadPrefix())
+	}
+	if options.Suffix {
+		suffix = key
+		readOpts = append(readOpts, store.ReadSuffix())
+	}
+	for k, v := range options.Where {
+		readOpts = append(readOpts, store.ReadWhere(k, v))
+	}
+	match, err := compile(options.Match)
+	if err != nil {
+		return nil, err
+	}
+	if options.Match != nil {
+		readOpts = append(readOpts, func(r *store.ReadOptions) { r.Match = options.Match })
+	}
+
+	recs, err := t.warm.Read(key, readOpts...)
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	// the warm tier has the latest version of records in both
+	warm := make(map[string]bool, len(recs))
+	for _, r := range recs {
+		warm[r.Key] = true
+	}
+
+	database, table := t.names(options.Database, options.Table)
+	keys, err := t.coldKeys(database, table, prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if warm[k] || (match != nil && !match.MatchString(k)) {
+			continue
+		}
+		r, err := t.readCold(database, table, k)
+		if err == store.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if r.Match(options.Where) {
+			recs = append(recs, r)
+		}
+	}
+
+	if options.OrderBy == store.OrderByExpiry {
+		store.SortByExpiry(recs, options.Order)
+	} else {
+		sort.Slice(recs, func(i, j int) bool {
+			if options.Order == store.OrderDesc {
+				return recs[i].Key > recs[j].Key
+			}
+			return recs[i].Key < recs[j].Key
+		})
+	}
+
+	if len(after) > 0 {
+		var page []*store.Record
+		for _, r := range recs {
+			if options.Order == store.OrderDesc && r.Key < after || options.Order != store.OrderDesc && r.Key > after {
+				page = append(page, r)
+			}
+		}
+		recs = page
+	}
+	recs = paginate(recs, options.Offset, options.Limit)
+
+	if options.Cursor != nil {
+		var last string
+		if len(recs) > 0 {
+			last = recs[len(recs)-1].Key
+		}
+		*options.Cursor = store.NextCursor(options.Limit, len(recs), last)
+	}
+	return recs, nil
+}
+
+// compile returns the regular expression for the pattern, or nil if there isn't one
+func compile(p *store.Pattern) (*regexp.Regexp, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return p.Compile()
+}
+
+func paginate(recs []*store.Record, offset, limit uint) []*store.Record {
+	if int(offset) >= len(recs) {
+		return nil
+	}
+	recs = recs[offset:]
+	if limit > 0 && int(limit) < len(recs) {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+// Write the record to the hot and warm tiers
+func (t *tiered) Write(r *store.Record, opts ...store.WriteOption) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	if err := t.hot.Write(r, opts...); err != nil {
+		return err
+	}
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return t.touch(options.Database, options.Table, r.Key)
+}
+
+// Delete removes the records from all the tiers
+func (t *tiered) Delete(key string, opts ...store.DeleteOption) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, table := t.names(options.Database, options.Table)
+
+	if err := t.hot.Delete(key, opts...); err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	var keys []string
+	if options.Prefix || options.Suffix {
+		var prefix, suffix string
+		if options.Prefix {
+			prefix = key
+		}
+		if options.Suffix {
+			suffix = key
+		}
+		var err error
+		if keys, err = t.coldKeys(database, table, prefix, suffix); err != nil {
+			return err
+		}
+	} else {
+		keys = []string{key}
+	}
+
+	for _, k := range keys {
+		err := t.cold.Delete(coldKey(table, k), store.BlobNamespace(database))
+		if err != nil && err != store.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// List the keys of the records in the warm and cold tiers
+func (t *tiered) List(opts ...store.ListOption) ([]string, error) {
+	options := store.ListOptions{Order: store.OrderAsc}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the keys are grouped once they've been listed
+	if len(options.Delimiter) > 0 {
+		return store.ListDelimited(t, options)
+	}
+
+	// the records are read to sort them by expiry
+	if options.OrderBy == store.OrderByExpiry {
+		readOpts := store.ReadOptions{
+			Database: options.Database,
+			Table:    options.Table,
+			Prefix:   true,
+			Limit:    options.Limit,
+			Offset:   options.Offset,
+			Order:    options.Order,
+			OrderBy:  options.OrderBy,
+			Cursor:   options.Cursor,
+			Match:    options.Match,
+		}
+		recs, err := t.readMany(options.Prefix, readOpts)
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		for _, r := range recs {
+			if strings.HasSuffix(r.Key, options.Suffix) {
+				keys = append(keys, r.Key)
+			}
+		}
+		return keys, nil
+	}
+
+	var after string
+	if options.Cursor != nil {
+		var err error
+		if after, err = store.DecodeCursor(*options.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	match, err := compile(options.Match)
+	if err != nil {
+		return nil, err
+	}
+
+	listOpts := []store.ListOption{
+		store.ListFrom(options.Database, options.Table),
+		store.ListPrefix(options.Prefix),
+		store.ListSuffix(options.Suffix),
+		func(l *store.ListOptions) { l.Match = options.Match },
+	}
+	keys, err := t.warm.List(listOpts...)
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		seen[k] = true
+	}
+	database, table := t.names(options.Database, options.Table)
+	cold, err := t.coldKeys(database, table, options.Prefix, options.Suffix)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range cold {
+		if !seen[k] && (match == nil || match.MatchString(k)) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if options.Order == store.OrderDesc {
+			return keys[i] > keys[j]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var page []string
+	for _, k := range keys {
+		if len(after) > 0 && (options.Order == store.OrderDesc && k >= after || options.Order != store.OrderDesc && k <= after) {
+			continue
+		}
+		page = append(page, k)
+	}
+	if int(options.Offset) >= len(page) {
+		page = nil
+	} else {
+		page = page[options.Offset:]
+	}
+	if options.Limit > 0 && int(options.Limit) < len(page) {
+		page = page[:options.Limit]
+	}
+
+	if options.Cursor != nil {
+		var last string
+		if len(page) > 0 {
+			last = page[len(page)-1]
+		}
+		*options.Cursor = store.NextCursor(options.Limit, len(page), last)
+	}
+	return page, nil
+}
+
+// Demote moves the records which haven't been written for the WarmTTL from the warm tier
+// to the cold tier. It's run in the background at the interval set with Interval.
+func (t *tiered) Demote(ctx context.Context) error {
+	if t.options.ReadOnly {
+		return store.ErrReadOnly
+	}
+
+	recs, err := t.warm.Read("", store.ReadPrefix(), store.ReadFrom("", writtenTable))
+	if err == store.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, r := range recs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var w written
+		if err := json.Unmarshal(r.Value, &w); err != nil {
+			return err
+		}
+		if time.Since(w.Time) < t.warmTTL {
+			continue
+		}
+		if err := t.demote(w.Database, w.Table, w.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Demote moves the records of a tiered store which haven't been written for the WarmTTL
+// to the cold tier. If the store isn't a tiered store store.ErrNotSupported is returned.
+func Demote(ctx context.Context, s store.Store) error {
+	t, ok := s.(*tiered)
+	if !ok {
+		return store.ErrNotSupported
+	}
+	return t.Demote(ctx)
+}
+
+// demote moves the record to the cold tier unless it has been written since it was listed
+func (t *tiered) demote(database, table, key string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	wopt := store.ReadFrom("", writtenTable)
+	wrecs, err := t.warm.Read(writtenKey(database, table, key), wopt)
+	if err == store.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var w written
+	if err := json.Unmarshal(wrecs[0].Value, &w); err != nil {
+		return err
+	}
+	if time.Since(w.Time) < t.warmTTL {
+		return nil
+	}
+
+	recs, err := t.warm.Read(key, store.ReadFrom(database, table))
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	// records which have expired or been deleted are only removed from the written table
+	if len(recs) > 0 {
+		r := recs[0]
+		cr := &coldRecord{Value: r.Value, Metadata: r.Metadata}
+		if r.Expiry > 0 {
+			exp := time.Now().Add(r.Expiry)
+			cr.ExpiresAt = &exp
+		}
+		b, err := json.Marshal(cr)
+		if err != nil {
+			return err
+		}
+		if err := t.cold.Write(coldKey(table, key), bytes.NewReader(b), store.BlobNamespace(database)); err != nil {
+			return err
+		}
+		if err := t.hot.Delete(key, store.DeleteFrom(database, table)); err != nil && err != store.ErrNotFound {
+			return err
+		}
+	}
+
+	if err := t.warm.Delete(wrecs[0].Key, store.DeleteFrom("", writtenTable)); err != nil && err != store.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// Healthy checks the warm tier if it supports it
+func (t *tiered) Healthy(ctx context.Context) error {
+	if h, ok := t.warm.(store.HealthChecker); ok {
+		return h.Healthy(ctx)
+	}
+	return nil
+}
+
+// Close stops moving records to the cold tier and closes the hot and warm tiers
+func (t *tiered) Close() error {
+	t.loop.Lock()
+	t.stop()
+	t.loop.Unlock()
+	return t.hot.Close()
+}
+
+// String returns the name of the implementation.
+func (t *tiered) String() string {
+	return "tiered"
+}
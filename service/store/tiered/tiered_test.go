@@ -0,0 +1,109 @@
+package tiered
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/file"
+	"github.com/micro/micro/v3/service/store/memory"
+)
+
+func newTestStore(t *testing.T) (store.Store, store.Store, store.BlobStore) {
+	dir, err := ioutil.TempDir("", "tiered")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	cold, err := file.NewBlobStore(file.WithDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	warm := memory.NewStore()
+	s := NewStore(warm, cold, WarmTTL(time.Millisecond*50), Interval(time.Hour))
+	t.Cleanup(func() { s.Close() })
+	return s, warm, cold
+}
+
+func TestDemotePromote(t *testing.T) {
+	s, warm, _ := newTestStore(t)
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar"), Expiry: time.Hour}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+	if err := s.Write(&store.Record{Key: "baz", Value: []byte("qux")}); err != nil {
+		t.Fatalf("Error writing record %s", err)
+	}
+
+	// records which haven't been written for the ttl are moved
+	if err := Demote(context.TODO(), s); err != nil {
+		t.Fatalf("Error demoting records %s", err)
+	}
+	if _, err := warm.Read("foo"); err != nil {
+		t.Fatalf("Expected foo to still be warm, got %v", err)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if err := Demote(context.TODO(), s); err != nil {
+		t.Fatalf("Error demoting records %s", err)
+	}
+	if _, err := warm.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("Expected foo to be cold, got %v", err)
+	}
+
+	// cold records are still listed and read by prefix
+	keys, err := s.List()
+	if err != nil || len(keys) != 2 || keys[0] != "baz" || keys[1] != "foo" {
+		t.Fatalf("Expected baz and foo, got %v %v", keys, err)
+	}
+	recs, err := s.Read("f", store.ReadPrefix())
+	if err != nil || len(recs) != 1 || string(recs[0].Value) != "bar" {
+		t.Fatalf("Expected foo, got %v %v", recs, err)
+	}
+
+	// reading a record moves it back to the warm tier
+	recs, err = s.Read("foo")
+	if err != nil || len(recs) != 1 || string(recs[0].Value) != "bar" {
+		t.Fatalf("Expected foo, got %v %v", recs, err)
+	}
+	if recs[0].Expiry <= 0 || recs[0].Expiry > time.Hour {
+		t.Errorf("Expected foo to expire within the hour, got %v", recs[0].Expiry)
+	}
+	if _, err := warm.Read("foo"); err != nil {
+		t.Fatalf("Expected foo to be warm, got %v", err)
+	}
+}
+
+func TestDeleteCold(t *testing.T) {
+	s, _, cold := newTestStore(t)
+
+	for _, k := range []string{"a1", "a2", "b1"} {
+		if err := s.Write(&store.Record{Key: k}); err != nil {
+			t.Fatalf("Error writing record %s", err)
+		}
+	}
+	time.Sleep(time.Millisecond * 100)
+	if err := Demote(context.TODO(), s); err != nil {
+		t.Fatalf("Error demoting records %s", err)
+	}
+
+	if err := s.Delete("a", store.DeletePrefix()); err != nil {
+		t.Fatalf("Error deleting records %s", err)
+	}
+	keys, err := s.List()
+	if err != nil || len(keys) != 1 || keys[0] != "b1" {
+		t.Fatalf("Expected b1, got %v %v", keys, err)
+	}
+	if err := s.Delete("b1"); err != nil {
+		t.Fatalf("Error deleting record %s", err)
+	}
+	if _, err := s.Read("b1"); err != store.ErrNotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+	if keys, _ := cold.List(store.BlobListNamespace(s.Options().Database)); len(keys) != 0 {
+		t.Fatalf("Expected the cold tier to be empty, got %v", keys)
+	}
+}
@@ -0,0 +1,32 @@
+package versioned
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+type retainKey struct{}
+type retainForKey struct{}
+
+// Retain keeps the latest n revisions of each key, older ones are removed when the key is
+// written or deleted. All the revisions are kept by default.
+func Retain(n int) store.Option {
+	return setOption(retainKey{}, n)
+}
+
+// RetainFor keeps the revisions of each key written within d, older ones are removed when the
+// key is written or deleted. The latest revision is always kept.
+func RetainFor(d time.Duration) store.Option {
+	return setOption(retainForKey{}, d)
+}
+
+func setOption(k, v interface{}) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
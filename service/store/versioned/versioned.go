@@ -0,0 +1,376 @@
+// Package versioned is a store which keeps the earlier revisions of the records written to
+// another store, so changes to them can be audited. Writes and deletes append a revision,
+// which are read with store.ReadVersion and store.ListVersions. The revisions of a table are
+// kept in a table of the backing store with the same name and a "_versions" suffix.
+package versioned
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+const (
+	// tableSuffix is added to the name of a table for the table its revisions are kept in
+	tableSuffix = "_versions"
+	// separator comes between the key and the version in the keys of revisions
+	separator = "#"
+	// versionDigits is the width versions are padded to so their keys sort in order
+	versionDigits = 20
+)
+
+// revision is the value of a revision in the backing store
+type revision struct {
+	Value    []byte                 `json:"value,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Created  time.Time              `json:"created"`
+	Deleted  bool                   `json:"deleted,omitempty"`
+}
+
+type versionedStore struct {
+	b       store.Store // the backing store
+	options store.Options
+
+	// versions are numbered by the store, so changes are made one at a time
+	sync.Mutex
+}
+
+// NewStore returns a store which keeps the revisions of the records written to the backing
+// store. The versions are numbered by the store, so a table should only be written through
+// one of them.
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	v := &versionedStore{b: s}
+	v.init(opts...)
+	return v
+}
+
+func (v *versionedStore) init(opts ...store.Option) {
+	for _, o := range opts {
+		o(&v.options)
+	}
+}
+
+// Init initialises the backing store
+func (v *versionedStore) Init(opts ...store.Option) error {
+	v.init(opts...)
+	return v.b.Init(opts...)
+}
+
+// Options allows you to view the current options.
+func (v *versionedStore) Options() store.Options {
+	return v.options
+}
+
+// retention returns the number of revisions to keep and how long to keep them for
+func (v *versionedStore) retention() (int, time.Duration) {
+	if v.options.Context == nil {
+		return 0, 0
+	}
+	n, _ := v.options.Context.Value(retainKey{}).(int)
+	d, _ := v.options.Context.Value(retainForKey{}).(time.Duration)
+	return n, d
+}
+
+// names returns the database and table of the records, and the table of their revisions
+func (v *versionedStore) names(database, table string) (string, string, string) {
+	if len(database) == 0 {
+		database = v.b.Options().Database
+	}
+	if len(table) == 0 {
+		table = v.b.Options().Table
+	}
+	return database, table, table + tableSuffix
+}
+
+func revisionKey(key string, version uint64) string {
+	return fmt.Sprintf("%s%s%0*d", key, separator, versionDigits, version)
+}
+
+// parseVersion returns the version of the revision of the key, false if it's the revision
+// of another key which has the key as its prefix
+func parseVersion(key, revKey string) (uint64, bool) {
+	s := strings.TrimPrefix(revKey, key+separator)
+	if len(s) != versionDigits || len(s) == len(revKey) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// latest returns the version of the last revision of the key, zero if there are none
+func (v *versionedStore) latest(database, vtable, key string) (uint64, error) {
+	keys, err := v.b.List(store.ListFrom(database, vtable), store.ListPrefix(key+separator))
+	if err != nil {
+		return 0, err
+	}
+	var last uint64
+	for _, k := range keys {
+		if n, ok := parseVersion(key, k); ok && n > last {
+			last = n
+		}
+	}
+	return last, nil
+}
+
+// revisions reads all the revisions of the key, oldest first
+func (v *versionedStore) revisions(database, vtable, key string) ([]*store.Revision, error) {
+	recs, err := v.b.Read(key+separator, store.ReadPrefix(), store.ReadFrom(database, vtable))
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	revs := []*store.Revision{}
+	for _, r := range recs {
+		n, ok := parseVersion(key, r.Key)
+		if !ok {
+			continue
+		}
+		var rev revision
+		if err := json.Unmarshal(r.Value, &rev); err != nil {
+			return nil, fmt.Errorf("couldn't read revision %d of %s: %w", n, key, err)
+		}
+		revs = append(revs, toRevision(key, n, &rev))
+	}
+	return revs, nil
+}
+
+func toRevision(key string, version uint64, rev *revision) *store.Revision {
+	r := &store.Revision{Version: version, Created: rev.Created, Deleted: rev.Deleted}
+	if !rev.Deleted {
+		r.Record = &store.Record{Key: key, Value: rev.Value, Metadata: rev.Metadata}
+	}
+	return r
+}
+
+// add appends a revision to each of the keys and returns a func which removes them again if
+// the change they record then fails. The caller must hold the lock.
+func (v *versionedStore) add(database, vtable string, revs map[string]*revision) (func(), error) {
+	var added []string
+	undo := func() {
+		for _, k := range added {
+			v.b.Delete(k, store.DeleteFrom(database, vtable))
+		}
+	}
+
+	for key, rev := range revs {
+		last, err := v.latest(database, vtable, key)
+		if err != nil {
+			undo()
+			return nil, err
+		}
+		b, err := json.Marshal(rev)
+		if err != nil {
+			undo()
+			return nil, err
+		}
+		k := revisionKey(key, last+1)
+		if err := v.b.Write(&store.Record{Key: k, Value: b}, store.WriteTo(database, vtable)); err != nil {
+			undo()
+			return nil, err
+		}
+		added = append(added, k)
+	}
+	return undo, nil
+}
+
+// prune removes the revisions of the key outside of the retention limits. The caller must
+// hold the lock.
+func (v *versionedStore) prune(database, vtable, key string) error {
+	n, d := v.retention()
+	if n <= 0 && d <= 0 {
+		return nil
+	}
+
+	revs, err := v.revisions(database, vtable, key)
+	if err != nil {
+		return err
+	}
+
+	for i, r := range revs {
+		// the latest revision is always kept
+		if i == len(revs)-1 {
+			break
+		}
+		if (n > 0 && len(revs)-i > n) || (d > 0 && time.Since(r.Created) > d) {
+			if err := v.b.Delete(revisionKey(key, r.Version), store.DeleteFrom(database, vtable)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Read reads the records from the backing store, or the revision of the key set with
+// store.ReadVersion
+func (v *versionedStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.Version == 0 {
+		return v.b.Read(key, opts...)
+	}
+
+	// revisions are read one key at a time
+	if options.Prefix || options.Suffix || options.Match != nil {
+		return nil, store.ErrNotSupported
+	}
+
+	database, _, vtable := v.names(options.Database, options.Table)
+	recs, err := v.b.Read(revisionKey(key, options.Version), store.ReadFrom(database, vtable))
+	if err != nil {
+		return nil, err
+	}
+
+	var rev revision
+	if err := json.Unmarshal(recs[0].Value, &rev); err != nil {
+		return nil, fmt.Errorf("couldn't read revision %d of %s: %w", options.Version, key, err)
+	}
+	if rev.Deleted {
+		return nil, store.ErrNotFound
+	}
+	r := toRevision(key, options.Version, &rev).Record
+	if !r.Match(options.Where) {
+		return nil, store.ErrNotFound
+	}
+	return []*store.Record{r}, nil
+}
+
+// ListVersions returns the revisions of the key, oldest first unless the order is
+// store.OrderDesc
+func (v *versionedStore) ListVersions(key string, opts ...store.ListOption) ([]*store.Revision, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	database, _, vtable := v.names(options.Database, options.Table)
+	revs, err := v.revisions(database, vtable, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Order == store.OrderDesc {
+		for i, j := 0, len(revs)-1; i < j; i, j = i+1, j-1 {
+			revs[i], revs[j] = revs[j], revs[i]
+		}
+	}
+	if options.Offset > uint(len(revs)) {
+		revs = revs[:0]
+	} else {
+		revs = revs[options.Offset:]
+	}
+	if options.Limit > 0 && uint(len(revs)) > options.Limit {
+		revs = revs[:options.Limit]
+	}
+	return revs, nil
+}
+
+// Write appends a revision of the record and writes it to the backing store
+func (v *versionedStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, _, vtable := v.names(options.Database, options.Table)
+
+	v.Lock()
+	defer v.Unlock()
+
+	undo, err := v.add(database, vtable, map[string]*revision{
+		r.Key: {Value: r.Value, Metadata: r.Metadata, Created: time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if err := v.b.Write(r, opts...); err != nil {
+		undo()
+		return err
+	}
+	return v.prune(database, vtable, r.Key)
+}
+
+// Delete appends a revision recording the deletion of each of the records and removes them
+// from the backing store
+func (v *versionedStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, table, vtable := v.names(options.Database, options.Table)
+
+	v.Lock()
+	defer v.Unlock()
+
+	// find the keys which will be deleted
+	var keys []string
+	if options.Prefix || options.Suffix {
+		lo := []store.ListOption{store.ListFrom(database, table)}
+		if options.Prefix {
+			lo = append(lo, store.ListPrefix(key))
+		}
+		if options.Suffix {
+			lo = append(lo, store.ListSuffix(key))
+		}
+		var err error
+		if keys, err = v.b.List(lo...); err != nil {
+			return err
+		}
+	} else if _, err := v.b.Read(key, store.ReadFrom(database, table)); err == nil {
+		keys = []string{key}
+	} else if err != store.ErrNotFound {
+		return err
+	}
+
+	revs := make(map[string]*revision, len(keys))
+	for _, k := range keys {
+		revs[k] = &revision{Created: time.Now(), Deleted: true}
+	}
+	undo, err := v.add(database, vtable, revs)
+	if err != nil {
+		return err
+	}
+	if err := v.b.Delete(key, opts...); err != nil {
+		undo()
+		return err
+	}
+
+	for _, k := range keys {
+		if err := v.prune(database, vtable, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the keys from the backing store
+func (v *versionedStore) List(opts ...store.ListOption) ([]string, error) {
+	return v.b.List(opts...)
+}
+
+// Healthy checks the backing store if it supports it
+func (v *versionedStore) Healthy(ctx context.Context) error {
+	if h, ok := v.b.(store.HealthChecker); ok {
+		return h.Healthy(ctx)
+	}
+	return nil
+}
+
+// Close the backing store
+func (v *versionedStore) Close() error {
+	return v.b.Close()
+}
+
+// String returns the name of the implementation.
+func (v *versionedStore) String() string {
+	return "versioned"
+}
@@ -0,0 +1,95 @@
+package versioned
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersions(t *testing.T) {
+	s := NewStore(memory.NewStore())
+
+	for _, v := range []string{"one", "two", "three"} {
+		assert.NoError(t, s.Write(&store.Record{Key: "config", Value: []byte(v)}))
+	}
+	// revisions of keys with the key as their prefix aren't listed
+	assert.NoError(t, s.Write(&store.Record{Key: "config#1", Value: []byte("other")}))
+
+	recs, err := s.Read("config")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("three"), recs[0].Value)
+
+	recs, err = s.Read("config", store.ReadVersion(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("one"), recs[0].Value)
+
+	_, err = s.Read("config", store.ReadVersion(4))
+	assert.Equal(t, store.ErrNotFound, err)
+
+	revs, err := s.(store.Versioner).ListVersions("config")
+	assert.NoError(t, err)
+	assert.Len(t, revs, 3)
+	for i, r := range revs {
+		assert.Equal(t, uint64(i+1), r.Version)
+		assert.False(t, r.Created.IsZero())
+	}
+
+	revs, err = s.(store.Versioner).ListVersions("config", store.ListOrder(store.OrderDesc), store.ListLimit(1))
+	assert.NoError(t, err)
+	assert.Len(t, revs, 1)
+	assert.Equal(t, []byte("three"), revs[0].Record.Value)
+
+	// deletes are kept as a revision
+	assert.NoError(t, s.Delete("config"))
+	_, err = s.Read("config")
+	assert.Equal(t, store.ErrNotFound, err)
+	_, err = s.Read("config", store.ReadVersion(4))
+	assert.Equal(t, store.ErrNotFound, err)
+
+	revs, err = s.(store.Versioner).ListVersions("config")
+	assert.NoError(t, err)
+	assert.Len(t, revs, 4)
+	assert.True(t, revs[3].Deleted)
+	assert.Nil(t, revs[3].Record)
+
+	// the versions continue once the key is written again
+	assert.NoError(t, s.Write(&store.Record{Key: "config", Value: []byte("four")}))
+	recs, err = s.Read("config", store.ReadVersion(5))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("four"), recs[0].Value)
+}
+
+func TestRetain(t *testing.T) {
+	s := NewStore(memory.NewStore(), Retain(2))
+	for _, v := range []string{"one", "two", "three"} {
+		assert.NoError(t, s.Write(&store.Record{Key: "config", Value: []byte(v)}))
+	}
+
+	revs, err := s.(store.Versioner).ListVersions("config")
+	assert.NoError(t, err)
+	assert.Len(t, revs, 2)
+	assert.Equal(t, uint64(2), revs[0].Version)
+
+	_, err = s.Read("config", store.ReadVersion(1))
+	assert.Equal(t, store.ErrNotFound, err)
+}
+
+func TestRetainFor(t *testing.T) {
+	s := NewStore(memory.NewStore(), RetainFor(50*time.Millisecond))
+	assert.NoError(t, s.Write(&store.Record{Key: "config", Value: []byte("one")}))
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, s.Write(&store.Record{Key: "config", Value: []byte("two")}))
+
+	revs, err := s.(store.Versioner).ListVersions("config")
+	assert.NoError(t, err)
+	assert.Len(t, revs, 1)
+	assert.Equal(t, uint64(2), revs[0].Version)
+}
+
+func TestNotSupported(t *testing.T) {
+	_, err := memory.NewStore().Read("config", store.ReadVersion(1))
+	assert.Equal(t, store.ErrNotSupported, err)
+}
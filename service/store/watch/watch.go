@@ -0,0 +1,128 @@
+// Package watch fans out the changes made to a store to its watchers
+package watch
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/micro/micro/v3/service/store"
+)
+
+// Hub delivers the events published by a store to the watchers interested in them.
+// The zero value is ready to use.
+type Hub struct {
+	sync.RWMutex
+	watchers map[*watcher]bool
+}
+
+type watcher struct {
+	key      string
+	prefix   bool
+	database string
+	table    string
+
+	sync.Mutex
+	// events waiting to be sent to the watcher
+	queue  []*store.Event
+	notify chan bool
+}
+
+func (w *watcher) match(ev *store.Event) bool {
+	if ev.Database != w.database || ev.Table != w.table {
+		return false
+	}
+	if w.prefix {
+		return strings.HasPrefix(ev.Record.Key, w.key)
+	}
+	return ev.Record.Key == w.key
+}
+
+func (w *watcher) push(ev *store.Event) {
+	w.Lock()
+	w.queue = append(w.queue, ev)
+	w.Unlock()
+
+	select {
+	case w.notify <- true:
+	default:
+	}
+}
+
+// run sends the queued events to the channel until ctx is done
+func (w *watcher) run(ctx context.Context, events chan<- *store.Event) {
+	defer close(events)
+
+	for {
+		w.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.Unlock()
+
+		for _, ev := range queue {
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-w.notify:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Watch returns a channel of the events for the key in the database and table, which
+// should have been defaulted by the store. The channel is closed once ctx is done.
+func (h *Hub) Watch(ctx context.Context, key string, opts store.WatchOptions) <-chan *store.Event {
+	w := &watcher{
+		key:      key,
+		prefix:   opts.Prefix,
+		database: opts.Database,
+		table:    opts.Table,
+		notify:   make(chan bool, 1),
+	}
+
+	h.Lock()
+	if h.watchers == nil {
+		h.watchers = make(map[*watcher]bool)
+	}
+	h.watchers[w] = true
+	h.Unlock()
+
+	events := make(chan *store.Event)
+
+	go func() {
+		w.run(ctx, events)
+
+		h.Lock()
+		delete(h.watchers, w)
+		h.Unlock()
+	}()
+
+	return events
+}
+
+// Publish queues the event for the matching watchers, it never blocks
+// so it's safe to call while the store holds its locks.
+func (h *Hub) Publish(ev *store.Event) {
+	h.RLock()
+	defer h.RUnlock()
+
+	for w := range h.watchers {
+		if w.match(ev) {
+			w.push(ev)
+		}
+	}
+}
+
+// Watching returns true if there are any watchers, so stores can
+// skip the work of building events when nobody is listening.
+func (h *Hub) Watching() bool {
+	h.RLock()
+	defer h.RUnlock()
+	return len(h.watchers) > 0
+}
@@ -0,0 +1,150 @@
+// Package metrics is a store which reports the operations made on another store to a metrics
+// reporter. Each operation is timed and counted, tagged with the method, the backing store and
+// its result, and the bytes read and written are counted.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/micro/v3/service/metrics"
+	"github.com/micro/micro/v3/service/store"
+)
+
+const (
+	// timingID is the id of the duration of each operation
+	timingID = "store.operation"
+	// countID is the id of the number of operations
+	countID = "store.operations"
+	// bytesID is the id of the size of the keys and values read and written
+	bytesID = "store.bytes"
+)
+
+// The results operations are tagged with
+const (
+	resultSuccess  = "success"
+	resultNotFound = "not_found"
+	resultFailure  = "failure"
+)
+
+type metricsStore struct {
+	// forwards the optional interfaces of the backing store, reporting their operations
+	store.Decorator
+
+	b       store.Store // the backing store
+	options store.Options
+}
+
+// NewStore returns a store which reports the operations on the backing store, to the
+// reporter set with the Reporter option or metrics.DefaultMetricsReporter
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	m := &metricsStore{b: s}
+	m.Decorator = store.Decorator{Store: s, Around: m.around}
+	m.init(opts...)
+	return m
+}
+
+func (m *metricsStore) init(opts ...store.Option) {
+	for _, o := range opts {
+		o(&m.options)
+	}
+}
+
+// Init initialises the backing store
+func (m *metricsStore) Init(opts ...store.Option) error {
+	m.init(opts...)
+	return m.b.Init(opts...)
+}
+
+// Options allows you to view the current options.
+func (m *metricsStore) Options() store.Options {
+	return m.options
+}
+
+// reporter returns the reporter to report to, nil if there's none
+func (m *metricsStore) reporter() metrics.Reporter {
+	if m.options.Context != nil {
+		if r, ok := m.options.Context.Value(reporterKey{}).(metrics.Reporter); ok {
+			return r
+		}
+	}
+	return metrics.DefaultMetricsReporter
+}
+
+// report the operation which started at the time and moved the number of bytes
+func (m *metricsStore) report(method string, started time.Time, bytes int, err error) {
+	r := m.reporter()
+	if r == nil {
+		return
+	}
+
+	result := resultSuccess
+	if err == store.ErrNotFound {
+		result = resultNotFound
+	} else if err != nil {
+		result = resultFailure
+	}
+	tags := metrics.Tags{
+		"method":  method,
+		"backend": m.b.String(),
+		"result":  result,
+	}
+
+	r.Timing(timingID, time.Since(started), tags)
+	r.Count(countID, 1, tags)
+	if bytes > 0 {
+		r.Count(bytesID, int64(bytes), tags)
+	}
+}
+
+// around reports the optional operations made by the decorator
+func (m *metricsStore) around(ctx context.Context, op store.Operation) (context.Context, func(error)) {
+	started := time.Now()
+	return ctx, func(err error) {
+		m.report(op.Method, started, size(op.Records...), err)
+	}
+}
+
+func size(recs ...*store.Record) int {
+	var n int
+	for _, r := range recs {
+		n += len(r.Key) + len(r.Value)
+	}
+	return n
+}
+
+// Read reads the records from the backing store
+func (m *metricsStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	started := time.Now()
+	recs, err := m.b.Read(key, opts...)
+	m.report("Read", started, size(recs...), err)
+	return recs, err
+}
+
+// Write writes the record to the backing store
+func (m *metricsStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	started := time.Now()
+	err := m.b.Write(r, opts...)
+	m.report("Write", started, size(r), err)
+	return err
+}
+
+// Delete removes the record from the backing store
+func (m *metricsStore) Delete(key string, opts ...store.DeleteOption) error {
+	started := time.Now()
+	err := m.b.Delete(key, opts...)
+	m.report("Delete", started, 0, err)
+	return err
+}
+
+// List returns the keys from the backing store
+func (m *metricsStore) List(opts ...store.ListOption) ([]string, error) {
+	started := time.Now()
+	keys, err := m.b.List(opts...)
+	var n int
+	for _, k := range keys {
+		n += len(k)
+	}
+	m.report("List", started, n, err)
+	return keys, err
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/metrics"
+	"github.com/micro/micro/v3/service/store"
+	"github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+type metric struct {
+	id    string
+	value int64
+	tags  metrics.Tags
+}
+
+type testReporter struct {
+	sync.Mutex
+	counts  []metric
+	timings []metric
+}
+
+func (r *testReporter) Count(id string, value int64, tags metrics.Tags) error {
+	r.Lock()
+	defer r.Unlock()
+	r.counts = append(r.counts, metric{id, value, tags})
+	return nil
+}
+
+func (r *testReporter) Gauge(id string, value float64, tags metrics.Tags) error {
+	return nil
+}
+
+func (r *testReporter) Timing(id string, value time.Duration, tags metrics.Tags) error {
+	r.Lock()
+	defer r.Unlock()
+	r.timings = append(r.timings, metric{id, int64(value), tags})
+	return nil
+}
+
+func TestMetrics(t *testing.T) {
+	r := new(testReporter)
+	s := NewStore(memory.NewStore(), Reporter(r))
+
+	assert.NoError(t, s.Write(&store.Record{Key: "foo", Value: []byte("bar")}))
+	_, err := s.Read("foo")
+	assert.NoError(t, err)
+	_, err = s.Read("missing")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	assert.Len(t, r.timings, 3)
+	assert.Equal(t, metrics.Tags{"method": "Write", "backend": "memory", "result": "success"}, r.timings[0].tags)
+	assert.Equal(t, "not_found", r.timings[2].tags["result"])
+
+	// the operations and the bytes read and written are counted
+	var ops, bytes int64
+	for _, c := range r.counts {
+		switch c.id {
+		case countID:
+			ops += c.value
+		case bytesID:
+			bytes += c.value
+		}
+	}
+	assert.Equal(t, int64(3), ops)
+	assert.Equal(t, int64(12), bytes)
+
+	// the optional interfaces of the backing store are kept
+	_, ok := s.(store.Transactional)
+	assert.True(t, ok)
+	assert.Equal(t, "memory", s.String())
+}
+
+func TestMetricsDecorator(t *testing.T) {
+	r := new(testReporter)
+	s := NewStore(memory.NewStore(), Reporter(r))
+
+	// the optional operations are reported by the decorator
+	recs := []*store.Record{{Key: "foo", Value: []byte("bar")}, {Key: "baz", Value: []byte("qux")}}
+	assert.NoError(t, s.(store.BatchWriter).WriteMany(recs))
+
+	assert.Len(t, r.timings, 1)
+	assert.Equal(t, "WriteMany", r.timings[0].tags["method"])
+	for _, c := range r.counts {
+		if c.id == bytesID {
+			assert.Equal(t, int64(12), c.value)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/metrics"
+	"github.com/micro/micro/v3/service/store"
+)
+
+type reporterKey struct{}
+
+// Reporter sets the reporter the operations are reported to, metrics.DefaultMetricsReporter
+// is used by default
+func Reporter(r metrics.Reporter) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.WithValue(context.Background(), reporterKey{}, r)
+		} else {
+			o.Context = context.WithValue(o.Context, reporterKey{}, r)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/debug/trace"
+	"github.com/micro/micro/v3/service/store"
+)
+
+type tracerKey struct{}
+
+// Tracer sets the tracer the spans are started with, debug.DefaultTracer is used by default
+func Tracer(t trace.Tracer) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.WithValue(context.Background(), tracerKey{}, t)
+		} else {
+			o.Context = context.WithValue(o.Context, tracerKey{}, t)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package trace is a store which starts a span for each operation made on another store, so
+// the time spent in the store shows up in the traces of requests. The spans have the
+// database, table and key of the operation in their metadata.
+package trace
+
+import (
+	"context"
+
+	"github.com/micro/micro/v3/service/debug"
+	"github.com/micro/micro/v3/service/debug/trace"
+	"github.com/micro/micro/v3/service/store"
+)
+
+type traceStore struct {
+	// forwards the optional interfaces of the backing store, tracing their operations. The
+	// context of the decorator is that of the request the operations are made for.
+	store.Decorator
+
+	b       store.Store // the backing store
+	options store.Options
+}
+
+// NewStore returns a store which traces the operations on the backing store with the tracer
+// set with the Tracer option, or debug.DefaultTracer. Operations which don't take a context
+// start new traces, use WithContext to make them part of the trace of a request.
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	t := &traceStore{b: s}
+	t.Decorator = store.Decorator{Store: s, Context: context.Background(), Around: t.around}
+	t.init(opts...)
+	return t
+}
+
+// WithContext returns the store with the operations which don't take a context traced as part
+// of the request the context belongs to. Stores not returned by NewStore are returned as
+// they are.
+func WithContext(ctx context.Context, s store.Store) store.Store {
+	t, ok := s.(*traceStore)
+	if !ok {
+		return s
+	}
+	tc := *t
+	tc.Decorator.Context = ctx
+	return &tc
+}
+
+func (t *traceStore) init(opts ...store.Option) {
+	for _, o := range opts {
+		o(&t.options)
+	}
+}
+
+// Init initialises the backing store
+func (t *traceStore) Init(opts ...store.Option) error {
+	t.init(opts...)
+	return t.b.Init(opts...)
+}
+
+// Options allows you to view the current options.
+func (t *traceStore) Options() store.Options {
+	return t.options
+}
+
+func (t *traceStore) tracer() trace.Tracer {
+	if t.options.Context != nil {
+		if tr, ok := t.options.Context.Value(tracerKey{}).(trace.Tracer); ok {
+			return tr
+		}
+	}
+	return debug.DefaultTracer
+}
+
+// around traces the optional operations made by the decorator
+func (t *traceStore) around(ctx context.Context, op store.Operation) (context.Context, func(error)) {
+	return t.start(ctx, op.Method, op.Database, op.Table, op.Key)
+}
+
+// start a span for the operation, the returned func finishes it
+func (t *traceStore) start(ctx context.Context, method, database, table, key string) (context.Context, func(error)) {
+	tr := t.tracer()
+	newCtx, s := tr.Start(ctx, "store."+method)
+	if s == nil {
+		return ctx, func(error) {}
+	}
+	s.Type = trace.SpanTypeRequestOutbound
+
+	// the database and table default to those of the backing store
+	o := t.b.Options()
+	if len(database) == 0 {
+		database = o.Database
+	}
+	if len(table) == 0 {
+		table = o.Table
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+	s.Metadata["backend"] = t.b.String()
+	s.Metadata["database"] = database
+	s.Metadata["table"] = table
+	if len(key) > 0 {
+		s.Metadata["key"] = key
+	}
+
+	return newCtx, func(err error) {
+		if err != nil && err != store.ErrNotFound {
+			s.Metadata["error"] = err.Error()
+		}
+		tr.Finish(s)
+	}
+}
+
+// Read reads the records from the backing store
+func (t *traceStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, finish := t.start(t.Decorator.Context, "Read", options.Database, options.Table, key)
+	recs, err := t.b.Read(key, opts...)
+	finish(err)
+	return recs, err
+}
+
+// Write writes the record to the backing store
+func (t *traceStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, finish := t.start(t.Decorator.Context, "Write", options.Database, options.Table, r.Key)
+	err := t.b.Write(r, opts...)
+	finish(err)
+	return err
+}
+
+// Delete removes the record from the backing store
+func (t *traceStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, finish := t.start(t.Decorator.Context, "Delete", options.Database, options.Table, key)
+	err := t.b.Delete(key, opts...)
+	finish(err)
+	return err
+}
+
+// List returns the keys from the backing store, the prefix listed is the key of the span
+func (t *traceStore) List(opts ...store.ListOption) ([]string, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	_, finish := t.start(t.Decorator.Context, "List", options.Database, options.Table, options.Prefix)
+	keys, err := t.b.List(opts...)
+	finish(err)
+	return keys, err
+}
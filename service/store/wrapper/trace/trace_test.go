@@ -0,0 +1,44 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/micro/v3/service/debug/trace"
+	"github.com/micro/micro/v3/service/debug/trace/memory"
+	"github.com/micro/micro/v3/service/store"
+	mstore "github.com/micro/micro/v3/service/store/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	tr := memory.NewTracer()
+	s := NewStore(mstore.NewStore(store.Database("db"), store.Table("table")), Tracer(tr))
+
+	// the operations are part of the trace of the request
+	ctx, span := tr.Start(context.TODO(), "request")
+	assert.NoError(t, WithContext(ctx, s).Write(&store.Record{Key: "foo", Value: []byte("bar")}))
+	_, err := WithContext(ctx, s).Read("missing")
+	assert.Equal(t, store.ErrNotFound, err)
+	tr.Finish(span)
+
+	spans, err := tr.Read(trace.ReadTrace(span.Trace))
+	assert.NoError(t, err)
+	assert.Len(t, spans, 3)
+
+	write := spans[0]
+	assert.Equal(t, "store.Write", write.Name)
+	assert.Equal(t, span.Id, write.Parent)
+	assert.Equal(t, "db", write.Metadata["database"])
+	assert.Equal(t, "table", write.Metadata["table"])
+	assert.Equal(t, "foo", write.Metadata["key"])
+
+	// records which aren't found aren't errors
+	read := spans[1]
+	assert.Equal(t, "store.Read", read.Name)
+	assert.Empty(t, read.Metadata["error"])
+
+	// other stores are returned as they are
+	m := mstore.NewStore()
+	assert.Equal(t, m, WithContext(ctx, m))
+}
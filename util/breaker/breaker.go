@@ -0,0 +1,275 @@
+// Package breaker is a circuit breaker. The calls made through the breaker are counted and once
+// too many of them fail or are slow the breaker opens, failing the calls without making them, then
+// after a time a few probe calls are let through to find out if the breaker can close again.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	merrors "github.com/micro/micro/v3/service/errors"
+)
+
+// ErrOpen is returned when a call isn't allowed because the breaker is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State of the breaker
+type State int
+
+const (
+	// Closed breakers allow every call
+	Closed State = iota
+	// Open breakers fail the calls without making them
+	Open
+	// HalfOpen breakers allow a few probe calls to find out if they can close
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Options of the breaker
+type Options struct {
+	// FailureThreshold is the share of the calls in the window which have to fail to open the
+	// breaker
+	FailureThreshold float64
+	// SlowCallDuration is how long a call takes to be counted as slow, slow calls aren't counted
+	// if it's zero
+	SlowCallDuration time.Duration
+	// SlowCallThreshold is the share of the calls in the window which have to be slow to open the
+	// breaker
+	SlowCallThreshold float64
+	// MinRequests is the number of calls in the window before the breaker can open
+	MinRequests int
+	// Window is the period the calls are counted over
+	Window time.Duration
+	// OpenTime is how long the breaker stays open before it allows the probe calls
+	OpenTime time.Duration
+	// HalfOpenRequests is the number of probe calls which have to succeed to close the breaker
+	HalfOpenRequests int
+	// OnStateChange are called when the state of the breaker changes
+	OnStateChange []func(from, to State)
+}
+
+// Option sets an option of the breaker
+type Option func(o *Options)
+
+// FailureThreshold sets the share of the calls in the window which have to fail to open the
+// breaker, by default 0.5
+func FailureThreshold(t float64) Option {
+	return func(o *Options) {
+		o.FailureThreshold = t
+	}
+}
+
+// SlowCallDuration sets how long a call takes to be counted as slow, by default slow calls aren't
+// counted
+func SlowCallDuration(d time.Duration) Option {
+	return func(o *Options) {
+		o.SlowCallDuration = d
+	}
+}
+
+// SlowCallThreshold sets the share of the calls in the window which have to be slow to open the
+// breaker, by default 0.5
+func SlowCallThreshold(t float64) Option {
+	return func(o *Options) {
+		o.SlowCallThreshold = t
+	}
+}
+
+// MinRequests sets the number of calls in the window before the breaker can open, by default 20
+func MinRequests(n int) Option {
+	return func(o *Options) {
+		o.MinRequests = n
+	}
+}
+
+// Window sets the period the calls are counted over, by default 10 seconds
+func Window(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// OpenTime sets how long the breaker stays open before it allows the probe calls, by default 30
+// seconds
+func OpenTime(d time.Duration) Option {
+	return func(o *Options) {
+		o.OpenTime = d
+	}
+}
+
+// HalfOpenRequests sets the number of probe calls which have to succeed to close the breaker, by
+// default 5
+func HalfOpenRequests(n int) Option {
+	return func(o *Options) {
+		o.HalfOpenRequests = n
+	}
+}
+
+// OnStateChange adds a func called when the state of the breaker changes. It's called while the
+// breaker is locked so it mustn't make calls through the breaker.
+func OnStateChange(fn func(from, to State)) Option {
+	return func(o *Options) {
+		o.OnStateChange = append(o.OnStateChange, fn)
+	}
+}
+
+// Breaker is a circuit breaker
+type Breaker struct {
+	options Options
+
+	sync.Mutex
+	state State
+	// generation is incremented when the state changes so calls allowed before are ignored
+	generation int
+	// the start of the window and the calls counted in it
+	start    time.Time
+	requests int
+	failures int
+	slow     int
+	// the time the breaker opened
+	opened time.Time
+	// the probe calls in flight and those which succeeded
+	probes    int
+	successes int
+}
+
+// New returns a closed breaker
+func New(opts ...Option) *Breaker {
+	options := Options{
+		FailureThreshold:  0.5,
+		SlowCallThreshold: 0.5,
+		MinRequests:       20,
+		Window:            10 * time.Second,
+		OpenTime:          30 * time.Second,
+		HalfOpenRequests:  5,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Breaker{options: options}
+}
+
+// State returns the state of the breaker
+func (b *Breaker) State() State {
+	b.Lock()
+	defer b.Unlock()
+	return b.currentState(time.Now())
+}
+
+// Allow returns ErrOpen if the call isn't allowed, otherwise a func which has to be called with
+// the error of the call and how long it took once it's done
+func (b *Breaker) Allow() (func(err error, d time.Duration), error) {
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.currentState(time.Now()) {
+	case Open:
+		return nil, ErrOpen
+	case HalfOpen:
+		if b.probes+b.successes >= b.options.HalfOpenRequests {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	return func(err error, d time.Duration) {
+		b.record(generation, err, d, time.Now())
+	}, nil
+}
+
+// currentState returns the state, moving an open breaker to half open once its time is up
+func (b *Breaker) currentState(now time.Time) State {
+	if b.state == Open && now.Sub(b.opened) >= b.options.OpenTime {
+		b.setState(HalfOpen, now)
+	}
+	return b.state
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.start = now
+	b.requests, b.failures, b.slow = 0, 0, 0
+	b.probes, b.successes = 0, 0
+	if state == Open {
+		b.opened = now
+	}
+	for _, fn := range b.options.OnStateChange {
+		fn(from, state)
+	}
+}
+
+func (b *Breaker) record(generation int, err error, d time.Duration, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	// the state changed since the call was allowed
+	if generation != b.generation {
+		return
+	}
+
+	bad := Failed(err) || (b.options.SlowCallDuration > 0 && d >= b.options.SlowCallDuration)
+
+	switch b.state {
+	case HalfOpen:
+		b.probes--
+		if bad {
+			b.setState(Open, now)
+			return
+		}
+		if b.successes++; b.successes >= b.options.HalfOpenRequests {
+			b.setState(Closed, now)
+		}
+	case Closed:
+		// start a new window
+		if now.Sub(b.start) >= b.options.Window {
+			b.start = now
+			b.requests, b.failures, b.slow = 0, 0, 0
+		}
+
+		b.requests++
+		if Failed(err) {
+			b.failures++
+		}
+		if b.options.SlowCallDuration > 0 && d >= b.options.SlowCallDuration {
+			b.slow++
+		}
+
+		if b.requests < b.options.MinRequests {
+			return
+		}
+		requests := float64(b.requests)
+		if float64(b.failures)/requests >= b.options.FailureThreshold ||
+			(b.options.SlowCallDuration > 0 && float64(b.slow)/requests >= b.options.SlowCallThreshold) {
+			b.setState(Open, now)
+		}
+	}
+}
+
+// Failed returns true if the error is a failure of the service rather than of the request, e.g. a
+// connection error, timeout or internal server error. Errors without a code, such as those of the
+// calls cancelled by the caller or once a hedged call has been answered, aren't failures.
+func Failed(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := merrors.FromError(err).Code
+	return code == 408 || code >= 500
+}
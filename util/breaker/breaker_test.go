@@ -0,0 +1,113 @@
+package breaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	merrors "github.com/micro/micro/v3/service/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	refused := merrors.InternalServerError("go.micro.client", "connection error: connection refused")
+
+	// makes the calls through the breaker, returning the number which were allowed
+	call := func(b *Breaker, n int, err error, d time.Duration) int {
+		var allowed int
+		for i := 0; i < n; i++ {
+			done, aerr := b.Allow()
+			if aerr != nil {
+				assert.Equal(t, ErrOpen, aerr)
+				continue
+			}
+			allowed++
+			done(err, d)
+		}
+		return allowed
+	}
+
+	t.Run("Failures", func(t *testing.T) {
+		// errors of the request don't count as failures
+		b := New(MinRequests(4))
+		call(b, 10, merrors.NotFound("test", "not found"), 0)
+		call(b, 10, merrors.TooManyRequests("test", "limit reached"), 0)
+		assert.Equal(t, Closed, b.State())
+
+		// nor do the calls cancelled
+		b = New(MinRequests(4))
+		call(b, 10, context.Canceled, 0)
+		assert.Equal(t, Closed, b.State())
+
+		// not enough calls fail to open the breaker
+		var changes []State
+		b = New(MinRequests(4), OnStateChange(func(from, to State) {
+			changes = append(changes, to)
+		}))
+		call(b, 3, nil, 0)
+		call(b, 2, refused, 0)
+		assert.Equal(t, Closed, b.State())
+
+		// then enough of them do
+		call(b, 1, merrors.InternalServerError("test", "error"), 0)
+		assert.Equal(t, Open, b.State())
+		assert.Equal(t, 0, call(b, 10, nil, 0), "Calls shouldn't be allowed while open")
+		assert.Equal(t, []State{Open}, changes)
+	})
+
+	t.Run("SlowCalls", func(t *testing.T) {
+		b := New(MinRequests(4), SlowCallDuration(time.Second))
+		call(b, 4, nil, 100*time.Millisecond)
+		assert.Equal(t, Closed, b.State())
+		call(b, 4, nil, 2*time.Second)
+		assert.Equal(t, Open, b.State())
+
+		// slow calls aren't counted by default
+		b = New(MinRequests(4))
+		call(b, 10, nil, time.Hour)
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("HalfOpen", func(t *testing.T) {
+		var changes []State
+		b := New(MinRequests(2), OpenTime(10*time.Millisecond), HalfOpenRequests(2), OnStateChange(func(from, to State) {
+			changes = append(changes, to)
+		}))
+		call(b, 2, refused, 0)
+		assert.Equal(t, Open, b.State())
+
+		// a failed probe opens the breaker again
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, HalfOpen, b.State())
+		assert.Equal(t, 1, call(b, 1, refused, 0))
+		assert.Equal(t, Open, b.State())
+
+		// only as many probes as need to succeed are allowed at once
+		time.Sleep(20 * time.Millisecond)
+		done1, err := b.Allow()
+		assert.Nil(t, err)
+		done2, err := b.Allow()
+		assert.Nil(t, err)
+		_, err = b.Allow()
+		assert.Equal(t, ErrOpen, err)
+
+		// the breaker closes once they succeed
+		done1(nil, 0)
+		assert.Equal(t, HalfOpen, b.State())
+		done2(nil, 0)
+		assert.Equal(t, Closed, b.State())
+		assert.Equal(t, []State{Open, HalfOpen, Open, HalfOpen, Closed}, changes)
+	})
+
+	t.Run("Generation", func(t *testing.T) {
+		b := New(MinRequests(2))
+		done, err := b.Allow()
+		assert.Nil(t, err)
+		call(b, 2, refused, 0)
+		assert.Equal(t, Open, b.State())
+
+		// the result of a call allowed before the breaker opened is ignored
+		done(nil, 0)
+		assert.Equal(t, Open, b.State())
+	})
+}
@@ -0,0 +1,65 @@
+// Package cbor provides a cbor (RFC 8949) codec. Messages are streamed as a cbor sequence, one
+// data item after another, and proto messages are encoded with the names of their json mapping.
+package cbor
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/micro/v3/util/codec"
+)
+
+type Codec struct {
+	Conn    io.ReadWriteCloser
+	Encoder *cbor.Encoder
+	Decoder *cbor.Decoder
+}
+
+func (c *Codec) ReadHeader(m *codec.Message, t codec.MessageType) error {
+	return nil
+}
+
+func (c *Codec) ReadBody(b interface{}) error {
+	if b == nil {
+		return nil
+	}
+	if pb, ok := b.(proto.Message); ok {
+		var raw cbor.RawMessage
+		if err := c.Decoder.Decode(&raw); err != nil {
+			return err
+		}
+		return Marshaler{}.Unmarshal(raw, pb)
+	}
+	return c.Decoder.Decode(b)
+}
+
+func (c *Codec) Write(m *codec.Message, b interface{}) error {
+	if b == nil {
+		return nil
+	}
+	if pb, ok := b.(proto.Message); ok {
+		raw, err := Marshaler{}.Marshal(pb)
+		if err != nil {
+			return err
+		}
+		return c.Encoder.Encode(cbor.RawMessage(raw))
+	}
+	return c.Encoder.Encode(b)
+}
+
+func (c *Codec) Close() error {
+	return c.Conn.Close()
+}
+
+func (c *Codec) String() string {
+	return "cbor"
+}
+
+func NewCodec(c io.ReadWriteCloser) codec.Codec {
+	return &Codec{
+		Conn:    c,
+		Decoder: decMode.NewDecoder(c),
+		Encoder: encMode.NewEncoder(c),
+	}
+}
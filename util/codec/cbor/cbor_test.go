@@ -0,0 +1,60 @@
+package cbor
+
+import (
+	"bytes"
+	"testing"
+
+	pberr "github.com/micro/micro/v3/proto/errors"
+	"github.com/micro/micro/v3/util/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type buffer struct {
+	*bytes.Buffer
+}
+
+func (b *buffer) Close() error {
+	return nil
+}
+
+func TestMarshaler(t *testing.T) {
+	// proto messages are encoded with their json mapping
+	b, err := Marshaler{}.Marshal(&pberr.Error{Id: "foo", Code: 500})
+	assert.Nil(t, err)
+	j, err := ToJSON(b)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id":"foo","code":500}`, string(j))
+
+	var e pberr.Error
+	assert.Nil(t, Marshaler{}.Unmarshal(b, &e))
+	assert.Equal(t, "foo", e.Id)
+	assert.Equal(t, int32(500), e.Code)
+
+	// numbers converted from json stay integers
+	b, err = FromJSON([]byte(`{"count":3,"ratio":0.5}`))
+	assert.Nil(t, err)
+	var v map[string]interface{}
+	assert.Nil(t, Marshaler{}.Unmarshal(b, &v))
+	assert.Equal(t, uint64(3), v["count"])
+	assert.Equal(t, 0.5, v["ratio"])
+}
+
+func TestCodecStream(t *testing.T) {
+	buf := &buffer{new(bytes.Buffer)}
+	c := NewCodec(buf)
+
+	// the messages are written as a sequence of data items
+	for _, id := range []string{"foo", "bar"} {
+		assert.Nil(t, c.Write(&codec.Message{}, &pberr.Error{Id: id}))
+	}
+	assert.Nil(t, c.Write(&codec.Message{}, map[string]string{"id": "baz"}))
+
+	for _, id := range []string{"foo", "bar"} {
+		var e pberr.Error
+		assert.Nil(t, c.ReadBody(&e))
+		assert.Equal(t, id, e.Id)
+	}
+	var m map[string]string
+	assert.Nil(t, c.ReadBody(&m))
+	assert.Equal(t, "baz", m["id"])
+}
@@ -0,0 +1,97 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/oxtoacart/bpool"
+)
+
+var jsonpbMarshaler = &jsonpb.Marshaler{}
+
+// create buffer pool with 16 instances each preallocated with 256 bytes
+var bufferPool = bpool.NewSizedBufferPool(16, 256)
+
+var (
+	encMode, _ = cbor.EncOptions{}.EncMode()
+	// maps are decoded with string keys so they can be converted to json
+	decMode, _ = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+)
+
+type Marshaler struct{}
+
+func (c Marshaler) Marshal(v interface{}) ([]byte, error) {
+	if pb, ok := v.(proto.Message); ok {
+		buf := bufferPool.Get()
+		defer bufferPool.Put(buf)
+		if err := jsonpbMarshaler.Marshal(buf, pb); err != nil {
+			return nil, err
+		}
+		return FromJSON(buf.Bytes())
+	}
+	return encMode.Marshal(v)
+}
+
+func (c Marshaler) Unmarshal(d []byte, v interface{}) error {
+	if pb, ok := v.(proto.Message); ok {
+		b, err := ToJSON(d)
+		if err != nil {
+			return err
+		}
+		return jsonpb.Unmarshal(bytes.NewReader(b), pb)
+	}
+	return decMode.Unmarshal(d, v)
+}
+
+func (c Marshaler) String() string {
+	return "cbor"
+}
+
+// FromJSON converts json to cbor, encoding the numbers as integers where they are
+func FromJSON(d []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(d))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return encMode.Marshal(fromJSON(v))
+}
+
+// fromJSON replaces the json numbers of a decoded value by integers or floats
+func fromJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = fromJSON(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = fromJSON(e)
+		}
+	}
+	return v
+}
+
+// ToJSON converts cbor to json. Byte strings are encoded as base64 strings, as in the json
+// mapping of proto, and maps must have string keys.
+func ToJSON(d []byte) ([]byte, error) {
+	var v interface{}
+	if err := decMode.Unmarshal(d, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/micro/micro/v3/util/codec"
 	"github.com/micro/micro/v3/util/codec/bytes"
+	"github.com/micro/micro/v3/util/codec/cbor"
 	"github.com/micro/micro/v3/util/codec/grpc"
 	"github.com/micro/micro/v3/util/codec/json"
 	"github.com/micro/micro/v3/util/codec/jsonrpc"
@@ -45,6 +46,7 @@ func (rwc *testRWC) Close() error {
 func getCodecs(c io.ReadWriteCloser) map[string]codec.Codec {
 	return map[string]codec.Codec{
 		"bytes":    bytes.NewCodec(c),
+		"cbor":     cbor.NewCodec(c),
 		"grpc":     grpc.NewCodec(c),
 		"json":     json.NewCodec(c),
 		"jsonrpc":  jsonrpc.NewCodec(c),
@@ -0,0 +1,217 @@
+// Package limiter limits the requests served concurrently. The requests over the limit wait in a
+// bounded queue, and the requests which don't fit in the queue are rejected so an overloaded
+// service sheds load rather than slowing down every request. The limit can adapt to the latency
+// of the requests: it's increased while the latency stays close to the lowest seen and decreased
+// once the latency grows, a sign the requests are queueing up within the service.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrLimitExceeded is returned when a request is rejected because the limit is reached and the
+// queue is full, or the request timed out in the queue
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// Options of the limiter
+type Options struct {
+	// Limit is the number of requests served concurrently, the initial limit if it's adaptive
+	Limit int
+	// QueueSize is the number of requests which wait for the limit before they're rejected
+	QueueSize int
+	// QueueTimeout is how long a request waits in the queue before it's rejected, until its
+	// context is done if it's zero
+	QueueTimeout time.Duration
+	// Adaptive adapts the limit to the latency of the requests
+	Adaptive bool
+	// MinLimit is the lowest the adaptive limit goes
+	MinLimit int
+	// MaxLimit is the highest the adaptive limit goes
+	MaxLimit int
+}
+
+// Option sets an option of the limiter
+type Option func(o *Options)
+
+// Limit sets the number of requests served concurrently, by default 100
+func Limit(n int) Option {
+	return func(o *Options) {
+		o.Limit = n
+	}
+}
+
+// QueueSize sets the number of requests which wait for the limit before they're rejected, by
+// default none
+func QueueSize(n int) Option {
+	return func(o *Options) {
+		o.QueueSize = n
+	}
+}
+
+// QueueTimeout sets how long a request waits in the queue before it's rejected, by default until
+// its context is done
+func QueueTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.QueueTimeout = d
+	}
+}
+
+// Adaptive adapts the limit to the latency of the requests, between the min and max limits
+func Adaptive(min, max int) Option {
+	return func(o *Options) {
+		o.Adaptive = true
+		o.MinLimit = min
+		o.MaxLimit = max
+	}
+}
+
+const (
+	// minRTTSamples is the number of samples after which the lowest latency is measured again, so
+	// the limit adapts to a change in the latency of the service when it isn't loaded
+	minRTTSamples = 1000
+)
+
+// Limiter limits the requests served concurrently
+type Limiter struct {
+	options Options
+
+	sync.Mutex
+	limit    float64
+	inflight int
+	// the requests waiting for the limit in order
+	queue []chan struct{}
+	// the lowest latency seen and the samples since it was reset
+	minRTT  time.Duration
+	samples int
+}
+
+// New returns a limiter
+func New(opts ...Option) *Limiter {
+	options := Options{
+		Limit:    100,
+		MinLimit: 1,
+		MaxLimit: 1000,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.Limit < 1 {
+		options.Limit = 1
+	}
+	if options.MinLimit < 1 {
+		options.MinLimit = 1
+	}
+
+	return &Limiter{
+		options: options,
+		limit:   float64(options.Limit),
+	}
+}
+
+// Limit returns the current limit
+func (l *Limiter) Limit() int {
+	l.Lock()
+	defer l.Unlock()
+	return int(l.limit)
+}
+
+// Acquire waits for the request to be allowed, returning ErrLimitExceeded if it's rejected,
+// otherwise a func which has to be called once the request is done
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	l.Lock()
+	if l.inflight < int(l.limit) && len(l.queue) == 0 {
+		l.inflight++
+		l.Unlock()
+		return l.release(time.Now()), nil
+	}
+	if len(l.queue) >= l.options.QueueSize {
+		l.Unlock()
+		return nil, ErrLimitExceeded
+	}
+	ready := make(chan struct{})
+	l.queue = append(l.queue, ready)
+	l.Unlock()
+
+	var timeout <-chan time.Time
+	if l.options.QueueTimeout > 0 {
+		t := time.NewTimer(l.options.QueueTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	var err error
+	select {
+	case <-ready:
+		return l.release(time.Now()), nil
+	case <-timeout:
+		err = ErrLimitExceeded
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// leave the queue, unless the request was allowed in the meantime
+	l.Lock()
+	defer l.Unlock()
+	for i, r := range l.queue {
+		if r == ready {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			return nil, err
+		}
+	}
+	return l.release(time.Now()), nil
+}
+
+// release returns the func which ends the request started at the time
+func (l *Limiter) release(start time.Time) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			rtt := time.Since(start)
+
+			l.Lock()
+			defer l.Unlock()
+			l.inflight--
+			if l.options.Adaptive {
+				l.record(rtt)
+			}
+
+			// allow the requests queued up to the limit
+			for len(l.queue) > 0 && l.inflight < int(l.limit) {
+				l.inflight++
+				close(l.queue[0])
+				l.queue = l.queue[1:]
+			}
+		})
+	}
+}
+
+// record adapts the limit to the latency of a request. The number of requests queued within the
+// service is estimated from how much higher the latency is than the lowest seen. The limit is
+// increased while few are queued and decreased once too many are.
+func (l *Limiter) record(rtt time.Duration) {
+	if rtt <= 0 {
+		rtt = 1
+	}
+	l.samples++
+	if l.minRTT == 0 || rtt < l.minRTT || l.samples >= minRTTSamples {
+		l.minRTT = rtt
+		l.samples = 0
+	}
+
+	queued := l.limit * (1 - float64(l.minRTT)/float64(rtt))
+	alpha := math.Max(3, 3*math.Log10(l.limit))
+	beta := math.Max(6, 6*math.Log10(l.limit))
+
+	switch {
+	case queued < alpha && float64(l.inflight+1)*2 >= l.limit:
+		// only increase the limit while it's being used
+		l.limit++
+	case queued > beta:
+		l.limit--
+	}
+	l.limit = math.Max(float64(l.options.MinLimit), math.Min(float64(l.options.MaxLimit), l.limit))
+}
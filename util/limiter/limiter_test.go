@@ -0,0 +1,96 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("Limit", func(t *testing.T) {
+		l := New(Limit(2))
+		done1, err := l.Acquire(context.TODO())
+		assert.Nil(t, err)
+		_, err = l.Acquire(context.TODO())
+		assert.Nil(t, err)
+
+		// requests over the limit are rejected without a queue
+		_, err = l.Acquire(context.TODO())
+		assert.Equal(t, ErrLimitExceeded, err)
+
+		// releasing twice only frees one request
+		done1()
+		done1()
+		_, err = l.Acquire(context.TODO())
+		assert.Nil(t, err)
+		_, err = l.Acquire(context.TODO())
+		assert.Equal(t, ErrLimitExceeded, err)
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		l := New(Limit(1), QueueSize(2))
+		done, err := l.Acquire(context.TODO())
+		assert.Nil(t, err)
+
+		// the queued requests are allowed in order
+		order := make(chan int, 2)
+		for i := 1; i <= 2; i++ {
+			go func(i int) {
+				done, err := l.Acquire(context.TODO())
+				if err != nil {
+					order <- 0
+					return
+				}
+				order <- i
+				done()
+			}(i)
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// and those which don't fit in the queue are rejected
+		_, err = l.Acquire(context.TODO())
+		assert.Equal(t, ErrLimitExceeded, err)
+
+		done()
+		assert.Equal(t, 1, <-order)
+		assert.Equal(t, 2, <-order)
+	})
+
+	t.Run("QueueTimeout", func(t *testing.T) {
+		l := New(Limit(1), QueueSize(1), QueueTimeout(10*time.Millisecond))
+		done, err := l.Acquire(context.TODO())
+		assert.Nil(t, err)
+		_, err = l.Acquire(context.TODO())
+		assert.Equal(t, ErrLimitExceeded, err)
+
+		// the request which timed out left the queue
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		_, err = l.Acquire(ctx)
+		assert.Equal(t, context.Canceled, err)
+
+		done()
+		_, err = l.Acquire(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Adaptive", func(t *testing.T) {
+		l := New(Limit(20), Adaptive(10, 30))
+
+		// the limit grows while the latency stays low and it's used
+		l.inflight = 19
+		for i := 0; i < 20; i++ {
+			l.record(10 * time.Millisecond)
+		}
+		assert.Equal(t, 30, l.Limit())
+
+		// and shrinks once the latency grows
+		l.inflight = 0
+		for i := 0; i < 50; i++ {
+			l.record(100 * time.Millisecond)
+		}
+		assert.Equal(t, 10, l.Limit())
+	})
+}
@@ -18,12 +18,59 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"strconv"
 	"strings"
 )
 
+// UnixPrefix prefixes the addresses of unix sockets, such as unix:///var/run/micro.sock
+const UnixPrefix = "unix://"
+
+// IsUnix returns whether the address is of a unix socket
+func IsUnix(addr string) bool {
+	return strings.HasPrefix(addr, UnixPrefix)
+}
+
+// Network returns the network and address to listen on or dial for the address: the path of a
+// unix socket if the address is prefixed by unix://, otherwise the tcp address
+func Network(addr string) (string, string) {
+	if IsUnix(addr) {
+		return "unix", strings.TrimPrefix(addr, UnixPrefix)
+	}
+	return "tcp", addr
+}
+
+// Addr returns the address of a listener, prefixing the path of a unix socket by unix:// so it
+// can be dialled
+func Addr(l net.Listener) string {
+	if l.Addr().Network() == "unix" {
+		return UnixPrefix + l.Addr().String()
+	}
+	return l.Addr().String()
+}
+
+// removeStaleSocket removes the file of a unix socket which isn't listened on, left over by a
+// process which didn't close its listener
+func removeStaleSocket(path string) {
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(path)
+}
+
 // HostPort format addr and port suitable for dial
 func HostPort(addr string, port interface{}) string {
+	// a unix socket is dialled by its address
+	if IsUnix(addr) {
+		return addr
+	}
+
 	host := addr
 	if strings.Count(addr, ":") > 0 {
 		host = fmt.Sprintf("[%s]", addr)
@@ -40,7 +87,15 @@ func HostPort(addr string, port interface{}) string {
 
 // Listen takes addr:portmin-portmax and binds to the first available port
 // Example: Listen("localhost:5000-6000", fn)
+//
+// The address of a unix socket, such as unix:///var/run/micro.sock, is passed to fn as is, which
+// should listen on the network and address returned by Network.
 func Listen(addr string, fn func(string) (net.Listener, error)) (net.Listener, error) {
+	if IsUnix(addr) {
+		_, path := Network(addr)
+		removeStaleSocket(path)
+		return fn(addr)
+	}
 
 	if strings.Count(addr, ":") == 1 && strings.Count(addr, "-") == 0 {
 		return fn(addr)
@@ -16,6 +16,7 @@ package net
 
 import (
 	"net"
+	"path/filepath"
 	"testing"
 )
 
@@ -38,3 +39,33 @@ func TestListen(t *testing.T) {
 	// Expect addr DO NOT has extra ":" at the end!
 
 }
+
+func TestListenUnix(t *testing.T) {
+	fn := func(addr string) (net.Listener, error) {
+		return net.Listen(Network(addr))
+	}
+
+	addr := UnixPrefix + filepath.Join(t.TempDir(), "micro.sock")
+	l, err := Listen(addr, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Addr(l) != addr {
+		t.Fatalf("Expected the address %v, got %v", addr, Addr(l))
+	}
+
+	// the socket left over by a listener which wasn't closed is removed
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	l, err = Listen(addr, fn)
+	if err != nil {
+		t.Fatalf("Expected the stale socket to be removed, got %v", err)
+	}
+	defer l.Close()
+
+	// but not one which is listened on
+	if _, err := Listen(addr, fn); err == nil {
+		t.Fatal("Expected the socket in use not to be removed")
+	}
+}
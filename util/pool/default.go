@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/micro/micro/v3/service/network/transport"
+	mls "github.com/micro/micro/v3/util/tls"
 )
 
 type pool struct {
@@ -36,6 +37,7 @@ type pool struct {
 type poolConn struct {
 	transport.Client
 	id      string
+	key     string
 	created time.Time
 }
 
@@ -74,15 +76,26 @@ func (p *poolConn) Created() time.Time {
 }
 
 func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
+	var options transport.DialOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// conns secured by their own tls config are only reused by the dials with an equal config
+	key := addr
+	if options.TLSConfig != nil {
+		key = addr + "#" + mls.ConfigKey(options.TLSConfig)
+	}
+
 	p.Lock()
-	conns := p.conns[addr]
+	conns := p.conns[key]
 
 	// while we have conns check age and then return one
 	// otherwise we'll create a new conn
 	for len(conns) > 0 {
 		conn := conns[len(conns)-1]
 		conns = conns[:len(conns)-1]
-		p.conns[addr] = conns
+		p.conns[key] = conns
 
 		// if conn is old kill it and move on
 		if d := time.Since(conn.Created()); d > p.ttl {
@@ -106,6 +119,7 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 	return &poolConn{
 		Client:  c,
 		id:      uuid.New().String(),
+		key:     key,
 		created: time.Now(),
 	}, nil
 }
@@ -117,13 +131,14 @@ func (p *pool) Release(conn Conn, err error) error {
 	}
 
 	// otherwise put it back for reuse
+	pc := conn.(*poolConn)
 	p.Lock()
-	conns := p.conns[conn.Remote()]
+	conns := p.conns[pc.key]
 	if len(conns) >= p.size {
 		p.Unlock()
-		return conn.(*poolConn).Client.Close()
+		return pc.Client.Close()
 	}
-	p.conns[conn.Remote()] = append(conns, conn.(*poolConn))
+	p.conns[pc.key] = append(conns, pc)
 	p.Unlock()
 
 	return nil
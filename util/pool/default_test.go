@@ -17,6 +17,7 @@
 package pool
 
 import (
+	"crypto/tls"
 	"testing"
 	"time"
 
@@ -103,3 +104,62 @@ func TestClientPool(t *testing.T) {
 	testPool(t, 0, time.Minute)
 	testPool(t, 2, time.Minute)
 }
+
+func TestPoolTLSConfig(t *testing.T) {
+	tr := memory.NewTransport()
+	p := newPool(Options{TTL: time.Minute, Size: 2, Transport: tr})
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(s transport.Socket) {})
+
+	config := &tls.Config{}
+
+	// a conn secured by a tls config of its own isn't reused by the other dials
+	c, err := p.Get(l.Addr(), transport.WithTLSConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Release(c, nil)
+
+	c2, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2.Id() == c.Id() {
+		t.Fatal("conn secured by a tls config reused without it")
+	}
+	p.Release(c2, nil)
+
+	c3, err := p.Get(l.Addr(), transport.WithTLSConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3.Id() != c.Id() {
+		t.Fatal("conn secured by the tls config not reused")
+	}
+	p.Release(c3, nil)
+
+	// a config built for each dial is equal to the first
+	c4, err := p.Get(l.Addr(), transport.WithTLSConfig(&tls.Config{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c4.Id() != c.Id() {
+		t.Fatal("conn secured by an equal tls config not reused")
+	}
+	p.Release(c4, nil)
+
+	c5, err := p.Get(l.Addr(), transport.WithTLSConfig(&tls.Config{ServerName: "foo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c5.Id() == c.Id() {
+		t.Fatal("conn secured by a tls config reused by another config")
+	}
+	p.Release(c5, nil)
+}
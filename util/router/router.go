@@ -27,7 +27,22 @@ type apiRouter struct {
 }
 
 func (r *apiRouter) Lookup(service string, opts ...router.LookupOption) ([]router.Route, error) {
-	return r.routes, nil
+	options := router.NewLookup(opts...)
+	if options.Version == "*" {
+		return r.routes, nil
+	}
+
+	// only return the routes to the version requested
+	var routes []router.Route
+	for _, route := range r.routes {
+		if route.Version == options.Version {
+			routes = append(routes, route)
+		}
+	}
+	if len(routes) == 0 {
+		return nil, router.ErrRouteNotFound
+	}
+	return routes, nil
 }
 
 func (r *apiRouter) String() string {
@@ -40,7 +55,7 @@ func New(srvs []*registry.Service) router.Router {
 
 	for _, srv := range srvs {
 		for _, n := range srv.Nodes {
-			routes = append(routes, router.Route{Address: n.Address, Metadata: n.Metadata})
+			routes = append(routes, router.Route{Address: n.Address, Metadata: n.Metadata, Version: srv.Version})
 		}
 	}
 
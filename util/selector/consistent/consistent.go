@@ -0,0 +1,211 @@
+// Package consistent is a selector which selects the route for a key using consistent hashing
+// with bounded loads, so the requests with the same key are sent to the same route while it isn't
+// overloaded, and only the keys of the routes added or removed move to other routes
+package consistent
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/micro/micro/v3/util/selector"
+)
+
+const (
+	// replicas is the number of points of each route on the ring, spreading the keys evenly
+	replicas = 100
+	// loadFactor bounds the in flight requests of a route relative to the average, once a route
+	// reaches it the keys on it are sent to the next route on the ring
+	loadFactor = 1.25
+	// maxRings is the number of rings kept, one for each of the sets of routes selected from
+	// most recently, so a client calling several services doesn't rebuild them on each call
+	maxRings = 16
+)
+
+// NewSelector returns an initialised consistent hashing selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	return &consistent{rings: make(map[string]*ring), load: make(map[string]int)}
+}
+
+type consistent struct {
+	sync.Mutex
+	// the rings of the sets of routes selected from most recently, by their id, and their ids
+	// from the least recently used
+	rings map[string]*ring
+	used  []string
+	// the number of requests in flight to each route, from it being selected until the result
+	// is recorded
+	load map[string]int
+}
+
+// ring of the hashes of the points of the routes, in order
+type ring struct {
+	id     string
+	hashes []uint32
+	routes map[uint32]string
+}
+
+func hash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func newRing(id string, routes []string) *ring {
+	r := &ring{id: id, routes: make(map[uint32]string, len(routes)*replicas)}
+	for _, route := range routes {
+		for i := 0; i < replicas; i++ {
+			h := hash(route + "#" + strconv.Itoa(i))
+			if _, ok := r.routes[h]; ok {
+				continue
+			}
+			r.routes[h] = route
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// walk the routes on the ring from the key onwards, each route once
+func (r *ring) walk(key string, fn func(route string) bool) {
+	h := hash(key)
+	start := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+
+	seen := make(map[string]bool)
+	for i := 0; i < len(r.hashes); i++ {
+		route := r.routes[r.hashes[(start+i)%len(r.hashes)]]
+		if seen[route] {
+			continue
+		}
+		seen[route] = true
+		if !fn(route) {
+			return
+		}
+	}
+}
+
+func (c *consistent) Select(routes []string, opts ...selector.SelectOption) (selector.Next, error) {
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	options := selector.NewSelectOptions(opts...)
+
+	// without a key there's nothing to be consistent with so the routes are round robined from a
+	// random one
+	if len(options.Key) == 0 {
+		i := rand.Intn(len(routes))
+		return func() string {
+			route := routes[i%len(routes)]
+			i++
+			return route
+		}, nil
+	}
+
+	sorted := make([]string, len(routes))
+	copy(sorted, routes)
+	sort.Strings(sorted)
+	id := strings.Join(sorted, ",")
+
+	c.Lock()
+	r := c.get(id, sorted)
+	c.Unlock()
+
+	// each call to next returns a route not returned before, so retries go to another route
+	tried := make(map[string]bool)
+
+	return func() string {
+		c.Lock()
+		defer c.Unlock()
+
+		// the most requests in flight a route can have, relative to the average
+		var total int
+		for _, route := range sorted {
+			total += c.load[route]
+		}
+		limit := int(loadFactor*float64(total+1)/float64(len(sorted))) + 1
+
+		var next, fallback string
+		r.walk(options.Key, func(route string) bool {
+			if tried[route] {
+				return true
+			}
+			if len(fallback) == 0 {
+				fallback = route
+			}
+			if c.load[route] < limit {
+				next = route
+				return false
+			}
+			return true
+		})
+		if len(next) == 0 {
+			next = fallback
+		}
+		// once all the routes have been tried start again
+		if len(next) == 0 {
+			tried = make(map[string]bool)
+			r.walk(options.Key, func(route string) bool {
+				next = route
+				return false
+			})
+		}
+
+		tried[next] = true
+		c.load[next]++
+		return next
+	}, nil
+}
+
+// get the ring of the routes, building it if it isn't kept and evicting the least recently used
+// once there are too many
+func (c *consistent) get(id string, routes []string) *ring {
+	for i, u := range c.used {
+		if u == id {
+			c.used = append(append(c.used[:i:i], c.used[i+1:]...), id)
+			break
+		}
+	}
+	if r, ok := c.rings[id]; ok {
+		return r
+	}
+
+	if len(c.used) >= maxRings {
+		delete(c.rings, c.used[0])
+		c.used = c.used[1:]
+	}
+	r := newRing(id, routes)
+	c.rings[id] = r
+	c.used = append(c.used, id)
+	return r
+}
+
+func (c *consistent) Record(addr string, err error, opts ...selector.RecordOption) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.load[addr] > 1 {
+		c.load[addr]--
+	} else {
+		delete(c.load, addr)
+	}
+	return nil
+}
+
+func (c *consistent) Reset() error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.rings = make(map[string]*ring)
+	c.used = nil
+	c.load = make(map[string]int)
+	return nil
+}
+
+func (c *consistent) String() string {
+	return "consistent"
+}
@@ -0,0 +1,118 @@
+package consistent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro/micro/v3/util/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistent(t *testing.T) {
+	selector.Tests(t, NewSelector())
+
+	routes := []string{"127.0.0.1:8000", "127.0.0.1:8001", "127.0.0.1:8002", "127.0.0.1:8003"}
+
+	// selects the route for each key, recording the result so the load doesn't build up
+	selectKeys := func(sel selector.Selector, routes []string) map[string]string {
+		selected := map[string]string{}
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("user-%d", i)
+			next, err := sel.Select(routes, selector.Key(key))
+			assert.Nil(t, err, "Error should be nil")
+			selected[key] = next()
+			sel.Record(selected[key], nil)
+		}
+		return selected
+	}
+
+	t.Run("SameKey", func(t *testing.T) {
+		sel := NewSelector()
+		first := selectKeys(sel, routes)
+		assert.Equal(t, first, selectKeys(sel, routes), "Expected the keys to select the same routes")
+
+		// the order of the routes doesn't matter
+		reversed := []string{routes[3], routes[2], routes[1], routes[0]}
+		assert.Equal(t, first, selectKeys(NewSelector(), reversed), "Expected the keys to select the same routes")
+
+		counts := map[string]int{}
+		for _, route := range first {
+			counts[route]++
+		}
+		for _, route := range routes {
+			assert.True(t, counts[route] > 100, "Expected the keys to be spread across the routes, got %v", counts)
+		}
+	})
+
+	t.Run("TopologyChange", func(t *testing.T) {
+		sel := NewSelector()
+		before := selectKeys(sel, routes)
+		after := selectKeys(sel, routes[:3])
+
+		// only the keys of the removed route move
+		for key, route := range before {
+			if route != routes[3] {
+				assert.Equal(t, route, after[key], "Expected the key %v to stay on its route", key)
+			}
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		next, err := NewSelector().Select(routes, selector.Key("user-1"))
+		assert.Nil(t, err, "Error should be nil")
+
+		seen := map[string]bool{}
+		for range routes {
+			seen[next()] = true
+		}
+		assert.Equal(t, len(routes), len(seen), "Expected each retry to select another route")
+	})
+
+	t.Run("BoundedLoad", func(t *testing.T) {
+		sel := NewSelector()
+
+		// the requests for a hot key spill over to the other routes while they're in flight
+		counts := map[string]int{}
+		for i := 0; i < 100; i++ {
+			next, err := sel.Select(routes, selector.Key("user-1"))
+			assert.Nil(t, err, "Error should be nil")
+			counts[next()]++
+		}
+		for route, count := range counts {
+			assert.True(t, count <= 32, "Expected at most 32 requests in flight to %v, got %v", route, count)
+		}
+
+		// once the requests complete the key goes back to its route
+		for route, count := range counts {
+			for i := 0; i < count; i++ {
+				sel.Record(route, nil)
+			}
+		}
+		first := selectKeys(NewSelector(), routes)["user-1"]
+		next, _ := sel.Select(routes, selector.Key("user-1"))
+		assert.Equal(t, first, next(), "Expected the key to select its route")
+	})
+
+	t.Run("Services", func(t *testing.T) {
+		sel := NewSelector().(*consistent)
+		other := []string{"127.0.0.1:9000", "127.0.0.1:9001"}
+
+		// the rings of the services called in turn are kept rather than rebuilt
+		for i := 0; i < 2; i++ {
+			selectKeys(sel, routes)
+			selectKeys(sel, other)
+		}
+		assert.Len(t, sel.rings, 2)
+		r := sel.rings["127.0.0.1:8000,127.0.0.1:8001,127.0.0.1:8002,127.0.0.1:8003"]
+		selectKeys(sel, other)
+		selectKeys(sel, routes)
+		assert.True(t, r == sel.rings["127.0.0.1:8000,127.0.0.1:8001,127.0.0.1:8002,127.0.0.1:8003"], "Expected the ring to be reused")
+
+		// the least recently used rings are evicted
+		for i := 0; i < maxRings; i++ {
+			sel.Select([]string{fmt.Sprintf("10.0.0.%d:8080", i)}, selector.Key("user-1"))
+		}
+		assert.Len(t, sel.rings, maxRings)
+		assert.NotContains(t, sel.rings, "127.0.0.1:9000,127.0.0.1:9001")
+	})
+}
@@ -0,0 +1,141 @@
+// Package leastload is a selector which selects the least loaded route, scoring each route by its
+// requests in flight and the moving average of its latency, so more requests are sent to the
+// routes which are faster and less busy
+package leastload
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/util/selector"
+)
+
+// decay is the weight of the latest latency in the moving average of the latency of a route
+const decay = 0.2
+
+// NewSelector returns an initialised least load selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	return &leastload{stats: make(map[string]*stats)}
+}
+
+type leastload struct {
+	sync.Mutex
+	stats map[string]*stats
+}
+
+// stats of a route
+type stats struct {
+	// the number of requests in flight, from the route being selected until the result is
+	// recorded
+	inflight int
+	// the moving average of the latency
+	latency time.Duration
+}
+
+// score of the route, the lower the score the less loaded the route. The routes without a
+// latency yet are scored by the average latency so they're tried.
+func (s *stats) score(avg time.Duration) float64 {
+	latency := s.latency
+	if latency == 0 {
+		latency = avg
+	}
+	return float64(s.inflight+1) * float64(latency+1)
+}
+
+func (l *leastload) get(route string) *stats {
+	s, ok := l.stats[route]
+	if !ok {
+		s = &stats{}
+		l.stats[route] = s
+	}
+	return s
+}
+
+func (l *leastload) Select(routes []string, opts ...selector.SelectOption) (selector.Next, error) {
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	// each call to next returns a route not returned before, so retries go to another route
+	tried := make(map[string]bool)
+
+	return func() string {
+		l.Lock()
+		defer l.Unlock()
+
+		if len(tried) >= len(routes) {
+			tried = make(map[string]bool)
+		}
+
+		// the average latency of the routes with one
+		var total time.Duration
+		var count int
+		for _, r := range routes {
+			if s, ok := l.stats[r]; ok && s.latency > 0 {
+				total += s.latency
+				count++
+			}
+		}
+		var avg time.Duration
+		if count > 0 {
+			avg = total / time.Duration(count)
+		}
+
+		// select the route with the lowest score, starting at a random route so the ties are
+		// spread across the routes
+		var best string
+		var bestScore float64
+		start := rand.Intn(len(routes))
+		for i := range routes {
+			r := routes[(start+i)%len(routes)]
+			if tried[r] {
+				continue
+			}
+			if score := l.get(r).score(avg); len(best) == 0 || score < bestScore {
+				best, bestScore = r, score
+			}
+		}
+
+		tried[best] = true
+		l.get(best).inflight++
+		return best
+	}, nil
+}
+
+// Record the end of a request to the route, updating its latency with the time since the request
+// was started. Results recorded without the start of the request only release the route.
+func (l *leastload) Record(addr string, err error, opts ...selector.RecordOption) error {
+	options := selector.NewRecordOptions(opts...)
+
+	l.Lock()
+	defer l.Unlock()
+
+	s, ok := l.stats[addr]
+	if !ok || s.inflight == 0 {
+		return nil
+	}
+
+	s.inflight--
+	if options.Started.IsZero() {
+		return nil
+	}
+	latency := time.Since(options.Started)
+	if s.latency == 0 {
+		s.latency = latency
+	} else {
+		s.latency = time.Duration(decay*float64(latency) + (1-decay)*float64(s.latency))
+	}
+	return nil
+}
+
+func (l *leastload) Reset() error {
+	l.Lock()
+	defer l.Unlock()
+	l.stats = make(map[string]*stats)
+	return nil
+}
+
+func (l *leastload) String() string {
+	return "leastload"
+}
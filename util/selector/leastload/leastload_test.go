@@ -0,0 +1,83 @@
+package leastload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/util/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeastLoad(t *testing.T) {
+	selector.Tests(t, NewSelector())
+
+	r1 := "127.0.0.1:8000"
+	r2 := "127.0.0.1:8001"
+	routes := []string{r1, r2}
+
+	t.Run("Inflight", func(t *testing.T) {
+		sel := NewSelector()
+
+		// the requests in flight are spread across the routes
+		counts := map[string]int{}
+		for i := 0; i < 10; i++ {
+			next, err := sel.Select(routes)
+			assert.Nil(t, err, "Error should be nil")
+			counts[next()]++
+		}
+		assert.Equal(t, map[string]int{r1: 5, r2: 5}, counts, "Expected the requests to be spread across the routes")
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		sel := NewSelector()
+
+		// r1 responds slowly and r2 quickly
+		for _, r := range []string{r1, r2} {
+			next, _ := sel.Select([]string{r})
+			next()
+			start := time.Now()
+			if r == r1 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			sel.Record(r, nil, selector.Started(start))
+		}
+
+		counts := map[string]int{}
+		for i := 0; i < 10; i++ {
+			next, err := sel.Select(routes)
+			assert.Nil(t, err, "Error should be nil")
+			counts[next()]++
+		}
+		assert.True(t, counts[r2] > counts[r1], "Expected more requests to be sent to the faster route, got %v", counts)
+	})
+
+	t.Run("Overlapping", func(t *testing.T) {
+		sel := NewSelector().(*leastload)
+
+		// a slow request to the route is overtaken by a fast one, each measured from its start
+		for i := 0; i < 2; i++ {
+			next, _ := sel.Select([]string{r1})
+			next()
+		}
+		slow := time.Now().Add(-time.Second)
+		sel.Record(r1, nil, selector.Started(time.Now()))
+		assert.True(t, sel.stats[r1].latency < 100*time.Millisecond, "Expected the latency of the fast request, got %v", sel.stats[r1].latency)
+		sel.Record(r1, nil, selector.Started(slow))
+		assert.True(t, sel.stats[r1].latency >= 200*time.Millisecond, "Expected the latency of the slow request to be averaged in, got %v", sel.stats[r1].latency)
+		assert.Equal(t, 0, sel.stats[r1].inflight)
+
+		// the routes released without a start aren't measured
+		next, _ := sel.Select([]string{r1})
+		next()
+		latency := sel.stats[r1].latency
+		sel.Record(r1, nil)
+		assert.Equal(t, latency, sel.stats[r1].latency)
+		assert.Equal(t, 0, sel.stats[r1].inflight)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		next, err := NewSelector().Select(routes)
+		assert.Nil(t, err, "Error should be nil")
+		assert.NotEqual(t, next(), next(), "Expected the retry to select another route")
+	})
+}
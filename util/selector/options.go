@@ -14,6 +14,8 @@
 
 package selector
 
+import "time"
+
 // Options used to configure a selector
 type Options struct{}
 
@@ -21,7 +23,14 @@ type Options struct{}
 type Option func(*Options)
 
 // SelectOptions used to configure selection
-type SelectOptions struct{}
+type SelectOptions struct {
+	// Weights of the routes keyed by address, used by the selectors which balance the routes by
+	// their weights
+	Weights map[string]int
+	// Key the route is selected by, used by the selectors which select the same route for the
+	// same key
+	Key string
+}
 
 // SelectOption updates the select options
 type SelectOption func(*SelectOptions)
@@ -35,3 +44,47 @@ func NewSelectOptions(opts ...SelectOption) SelectOptions {
 
 	return options
 }
+
+// Weights sets the weights of the routes keyed by address. The routes without a weight have the
+// default weight of 1.
+func Weights(w map[string]int) SelectOption {
+	return func(o *SelectOptions) {
+		o.Weights = w
+	}
+}
+
+// Key sets the key the route is selected by, e.g. the id of the user a request is for, so the
+// requests with the same key are sent to the same route
+func Key(k string) SelectOption {
+	return func(o *SelectOptions) {
+		o.Key = k
+	}
+}
+
+// RecordOptions used to configure the recording of a result
+type RecordOptions struct {
+	// Started is when the request to the route was started, used by the selectors which measure
+	// the latency of the routes
+	Started time.Time
+}
+
+// RecordOption updates the record options
+type RecordOption func(*RecordOptions)
+
+// NewRecordOptions parses record options
+func NewRecordOptions(opts ...RecordOption) RecordOptions {
+	var options RecordOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// Started sets when the request to the route was started. Results recorded without it, e.g. of
+// the routes selected which weren't sent a request, release the route without being measured.
+func Started(t time.Time) RecordOption {
+	return func(o *RecordOptions) {
+		o.Started = t
+	}
+}
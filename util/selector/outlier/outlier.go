@@ -0,0 +1,208 @@
+// Package outlier wraps a selector with outlier detection. The errors of each route are counted
+// and a route which fails too many of its requests is ejected, so it isn't selected for a time
+// which grows each time it's ejected again, then its share of the requests is ramped back up.
+package outlier
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/util/breaker"
+	"github.com/micro/micro/v3/util/selector"
+)
+
+// Options of the outlier detection
+type Options struct {
+	// Threshold is the share of the requests to a route which have to fail for it to be ejected
+	Threshold float64
+	// MinRequests is the number of requests to a route in the window before it can be ejected
+	MinRequests int
+	// Window is the period the requests to a route are counted over
+	Window time.Duration
+	// EjectionTime is how long a route is ejected for the first time, doubling each time it's
+	// ejected again up to MaxEjectionTime
+	EjectionTime time.Duration
+	// MaxEjectionTime is the longest a route is ejected for
+	MaxEjectionTime time.Duration
+	// RampTime is how long it takes for the share of the requests of a route to ramp back up
+	// once it's no longer ejected
+	RampTime time.Duration
+}
+
+// Option sets an option of the outlier detection
+type Option func(o *Options)
+
+// Threshold sets the share of the requests to a route which have to fail for it to be ejected,
+// by default 0.5
+func Threshold(t float64) Option {
+	return func(o *Options) {
+		o.Threshold = t
+	}
+}
+
+// MinRequests sets the number of requests to a route in the window before it can be ejected, by
+// default 10
+func MinRequests(n int) Option {
+	return func(o *Options) {
+		o.MinRequests = n
+	}
+}
+
+// Window sets the period the requests to a route are counted over, by default 10 seconds
+func Window(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// EjectionTime sets how long a route is ejected for the first time, by default 30 seconds
+func EjectionTime(d time.Duration) Option {
+	return func(o *Options) {
+		o.EjectionTime = d
+	}
+}
+
+// MaxEjectionTime sets the longest a route is ejected for, by default 5 minutes
+func MaxEjectionTime(d time.Duration) Option {
+	return func(o *Options) {
+		o.MaxEjectionTime = d
+	}
+}
+
+// RampTime sets how long it takes for the share of the requests of a route to ramp back up once
+// it's no longer ejected, by default 30 seconds
+func RampTime(d time.Duration) Option {
+	return func(o *Options) {
+		o.RampTime = d
+	}
+}
+
+// NewSelector returns the selector wrapped with outlier detection
+func NewSelector(s selector.Selector, opts ...Option) selector.Selector {
+	options := Options{
+		Threshold:       0.5,
+		MinRequests:     10,
+		Window:          10 * time.Second,
+		EjectionTime:    30 * time.Second,
+		MaxEjectionTime: 5 * time.Minute,
+		RampTime:        30 * time.Second,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &outlier{
+		Selector: s,
+		options:  options,
+		stats:    make(map[string]*stats),
+	}
+}
+
+type outlier struct {
+	selector.Selector
+	options Options
+
+	sync.Mutex
+	stats map[string]*stats
+}
+
+// stats of a route
+type stats struct {
+	// the start of the window and the requests and errors counted in it
+	start    time.Time
+	requests int
+	errors   int
+	// the number of times the route was ejected without a healthy window since
+	ejections int
+	// the time the route is ejected until
+	until time.Time
+}
+
+// share of the requests the route is sent at the time, 0 while it's ejected, ramping up to 1
+func (o *outlier) share(s *stats, now time.Time) float64 {
+	if now.Before(s.until) {
+		return 0
+	}
+	if o.options.RampTime <= 0 {
+		return 1
+	}
+	if ramp := now.Sub(s.until); ramp < o.options.RampTime {
+		return float64(ramp) / float64(o.options.RampTime)
+	}
+	return 1
+}
+
+func (o *outlier) Select(routes []string, opts ...selector.SelectOption) (selector.Next, error) {
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	// skip the ejected routes and those ramping up by their share of the requests
+	now := time.Now()
+	o.Lock()
+	available := make([]string, 0, len(routes))
+	for _, r := range routes {
+		if s, ok := o.stats[r]; ok && rand.Float64() >= o.share(s, now) {
+			continue
+		}
+		available = append(available, r)
+	}
+	o.Unlock()
+
+	// fail open if all the routes are outliers
+	if len(available) == 0 {
+		available = routes
+	}
+
+	return o.Selector.Select(available, opts...)
+}
+
+func (o *outlier) Record(addr string, err error, opts ...selector.RecordOption) error {
+	o.record(addr, err, time.Now())
+	return o.Selector.Record(addr, err, opts...)
+}
+
+func (o *outlier) record(addr string, err error, now time.Time) {
+	o.Lock()
+	defer o.Unlock()
+
+	s, ok := o.stats[addr]
+	if !ok {
+		s = &stats{start: now}
+		o.stats[addr] = s
+	}
+
+	// start a new window, forgetting an ejection once a route has a window of requests without
+	// errors
+	if now.Sub(s.start) > o.options.Window {
+		if s.requests > 0 && s.errors == 0 && s.ejections > 0 {
+			s.ejections--
+		}
+		s.start, s.requests, s.errors = now, 0, 0
+	}
+
+	s.requests++
+	if breaker.Failed(err) {
+		s.errors++
+	}
+	if s.requests < o.options.MinRequests || float64(s.errors) < o.options.Threshold*float64(s.requests) {
+		return
+	}
+
+	// eject the route, for longer each time it's ejected again
+	ejection := o.options.EjectionTime << uint(s.ejections)
+	if ejection > o.options.MaxEjectionTime || ejection <= 0 {
+		ejection = o.options.MaxEjectionTime
+	}
+	s.ejections++
+	s.until = now.Add(ejection)
+	s.start, s.requests, s.errors = now, 0, 0
+}
+
+func (o *outlier) Reset() error {
+	o.Lock()
+	o.stats = make(map[string]*stats)
+	o.Unlock()
+	return o.Selector.Reset()
+}
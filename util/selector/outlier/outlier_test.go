@@ -0,0 +1,122 @@
+package outlier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	merrors "github.com/micro/micro/v3/service/errors"
+	"github.com/micro/micro/v3/util/selector"
+	"github.com/micro/micro/v3/util/selector/roundrobin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutlier(t *testing.T) {
+	selector.Tests(t, NewSelector(roundrobin.NewSelector()))
+
+	r1 := "127.0.0.1:8000"
+	r2 := "127.0.0.1:8001"
+	routes := []string{r1, r2}
+
+	// counts the routes selected
+	count := func(sel selector.Selector, n int) map[string]int {
+		counts := map[string]int{}
+		for i := 0; i < n; i++ {
+			next, err := sel.Select(routes)
+			assert.Nil(t, err, "Error should be nil")
+			counts[next()]++
+		}
+		return counts
+	}
+
+	newSelector := func() *outlier {
+		return NewSelector(roundrobin.NewSelector(),
+			MinRequests(4),
+			EjectionTime(time.Minute),
+			RampTime(time.Minute),
+		).(*outlier)
+	}
+	refused := merrors.InternalServerError("go.micro.client", "connection error: connection refused")
+
+	t.Run("Eject", func(t *testing.T) {
+		sel := newSelector()
+
+		// errors of the requests rather than the route don't count
+		for i := 0; i < 4; i++ {
+			sel.Record(r1, merrors.NotFound("foo", "not found"))
+		}
+		assert.Equal(t, 2, len(count(sel, 10)), "Expected both routes to be selected")
+
+		for i := 0; i < 4; i++ {
+			sel.Record(r1, refused)
+		}
+		assert.Equal(t, map[string]int{r2: 10}, count(sel, 10), "Expected the failing route to be ejected")
+
+		// nor do the calls cancelled
+		sel = newSelector()
+		for i := 0; i < 4; i++ {
+			sel.Record(r1, context.Canceled)
+		}
+		assert.Equal(t, 2, len(count(sel, 10)), "Expected both routes to be selected")
+
+		// nor the requests rejected by the limit of the service
+		sel = newSelector()
+		for i := 0; i < 4; i++ {
+			sel.Record(r1, merrors.TooManyRequests("foo", "limit reached"))
+		}
+		assert.Equal(t, 2, len(count(sel, 10)), "Expected both routes to be selected")
+	})
+
+	t.Run("Ramp", func(t *testing.T) {
+		sel := newSelector()
+		now := time.Now()
+		for i := 0; i < 4; i++ {
+			sel.record(r1, refused, now)
+		}
+
+		// half way through the ramp the route is sent around half its share of the requests
+		sel.stats[r1].until = now.Add(-30 * time.Second)
+		counts := count(sel, 1000)
+		assert.True(t, counts[r1] > 100 && counts[r1] < 400, "Expected the route to be ramping up, got %v", counts)
+
+		// once ramped up the route is sent its share of the requests
+		sel.stats[r1].until = now.Add(-time.Minute)
+		counts = count(sel, 1000)
+		assert.True(t, counts[r1] > 400 && counts[r1] < 600, "Expected the route to have ramped up, got %v", counts)
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		sel := newSelector()
+		now := time.Now()
+		for i := 0; i < 4; i++ {
+			sel.record(r1, refused, now)
+		}
+		assert.Equal(t, now.Add(time.Minute), sel.stats[r1].until)
+
+		// the route is ejected for longer when it fails again
+		now = now.Add(2 * time.Minute)
+		for i := 0; i < 4; i++ {
+			sel.record(r1, refused, now)
+		}
+		assert.Equal(t, now.Add(2*time.Minute), sel.stats[r1].until)
+
+		// until it has a window without errors
+		now = now.Add(3 * time.Minute)
+		sel.record(r1, nil, now)
+		now = now.Add(time.Minute)
+		for i := 0; i < 4; i++ {
+			sel.record(r1, refused, now)
+		}
+		assert.Equal(t, now.Add(2*time.Minute), sel.stats[r1].until)
+	})
+
+	t.Run("FailOpen", func(t *testing.T) {
+		sel := newSelector()
+		for _, r := range routes {
+			for i := 0; i < 4; i++ {
+				sel.Record(r, refused)
+			}
+		}
+		assert.Equal(t, 2, len(count(sel, 10)), "Expected the routes to be selected when all are ejected")
+	})
+}
@@ -40,7 +40,7 @@ func (r *random) Select(routes []string, opts ...selector.SelectOption) (selecto
 	}, nil
 }
 
-func (r *random) Record(addr string, err error) error {
+func (r *random) Record(addr string, err error, opts ...selector.RecordOption) error {
 	return nil
 }
 
@@ -42,7 +42,7 @@ func (r *roundrobin) Select(routes []string, opts ...selector.SelectOption) (sel
 	}, nil
 }
 
-func (r *roundrobin) Record(addr string, err error) error { return nil }
+func (r *roundrobin) Record(addr string, err error, opts ...selector.RecordOption) error { return nil }
 
 func (r *roundrobin) Reset() error { return nil }
 
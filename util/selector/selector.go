@@ -29,7 +29,7 @@ type Selector interface {
 	// Select a route from the pool using the strategy
 	Select([]string, ...SelectOption) (Next, error)
 	// Record the error returned from a route to inform future selection
-	Record(string, error) error
+	Record(string, error, ...RecordOption) error
 	// Reset the selector
 	Reset() error
 	// String returns the name of the selector
@@ -0,0 +1,113 @@
+// Package weighted is a selector which balances the routes by their weights using smooth weighted
+// round robin, so a route with twice the weight of another is selected twice as often and the
+// selections of each route are spread out rather than in bursts
+package weighted
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/util/selector"
+)
+
+// pruneInterval is how long the current weight of a route is kept once it's no longer selected
+// from, so the weights of the routes which have gone are forgotten
+const pruneInterval = time.Minute
+
+// NewSelector returns an initialised weighted round robin selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	return &weighted{current: make(map[string]int), used: make(map[string]time.Time)}
+}
+
+type weighted struct {
+	sync.Mutex
+	// the current weight of each route, kept between selections so the routes are balanced
+	// across calls rather than within a single one
+	current map[string]int
+	// the last time each route was selected from, and the last time the routes were pruned
+	used   map[string]time.Time
+	pruned time.Time
+}
+
+// prune forgets the current weights of the routes which haven't been selected from for the
+// prune interval
+func (w *weighted) prune(now time.Time) {
+	if now.Sub(w.pruned) < pruneInterval {
+		return
+	}
+	for r, t := range w.used {
+		if now.Sub(t) > pruneInterval {
+			delete(w.current, r)
+			delete(w.used, r)
+		}
+	}
+	w.pruned = now
+}
+
+func (w *weighted) Select(routes []string, opts ...selector.SelectOption) (selector.Next, error) {
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	options := selector.NewSelectOptions(opts...)
+
+	// routes without a weight have the default weight, routes with a weight of 0 are only
+	// selected if all the routes have a weight of 0
+	weights := make([]int, len(routes))
+	var total int
+	for i, r := range routes {
+		weight, ok := options.Weights[r]
+		if !ok {
+			weight = 1
+		} else if weight < 0 {
+			weight = 0
+		}
+		weights[i] = weight
+		total += weight
+	}
+	if total == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+		total = len(weights)
+	}
+
+	return func() string {
+		w.Lock()
+		defer w.Unlock()
+
+		now := time.Now()
+		w.prune(now)
+
+		// increase the current weight of each route by its weight, then select the route with
+		// the highest current weight and decrease it by the total
+		best := -1
+		for i, r := range routes {
+			if weights[i] == 0 {
+				continue
+			}
+			w.used[r] = now
+			w.current[r] += weights[i]
+			if best < 0 || w.current[r] > w.current[routes[best]] {
+				best = i
+			}
+		}
+		w.current[routes[best]] -= total
+
+		return routes[best]
+	}, nil
+}
+
+func (w *weighted) Record(addr string, err error, opts ...selector.RecordOption) error { return nil }
+
+func (w *weighted) Reset() error {
+	w.Lock()
+	defer w.Unlock()
+	w.current = make(map[string]int)
+	w.used = make(map[string]time.Time)
+	return nil
+}
+
+func (w *weighted) String() string {
+	return "weighted"
+}
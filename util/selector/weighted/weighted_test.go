@@ -0,0 +1,70 @@
+package weighted
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/util/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeighted(t *testing.T) {
+	selector.Tests(t, NewSelector())
+
+	r1 := "127.0.0.1:8000"
+	r2 := "127.0.0.1:8001"
+	r3 := "127.0.0.1:8002"
+	routes := []string{r1, r2, r3}
+
+	count := func(sel selector.Selector, n int, opts ...selector.SelectOption) map[string]int {
+		counts := map[string]int{}
+		for i := 0; i < n; i++ {
+			// a route is selected per call, so the weights must hold across the calls
+			next, err := sel.Select(routes, opts...)
+			assert.Nil(t, err, "Error should be nil")
+			counts[next()]++
+		}
+		return counts
+	}
+
+	t.Run("Weights", func(t *testing.T) {
+		counts := count(NewSelector(), 60, selector.Weights(map[string]int{r1: 3, r2: 2}))
+		assert.Equal(t, map[string]int{r1: 30, r2: 20, r3: 10}, counts, "Expected the routes to be selected by their weights")
+	})
+
+	t.Run("Spread", func(t *testing.T) {
+		next, err := NewSelector().Select([]string{r1, r2}, selector.Weights(map[string]int{r1: 2}))
+		assert.Nil(t, err, "Error should be nil")
+		assert.Equal(t, []string{r1, r2, r1, r1, r2, r1}, []string{next(), next(), next(), next(), next(), next()})
+	})
+
+	t.Run("NoWeight", func(t *testing.T) {
+		counts := count(NewSelector(), 30, selector.Weights(map[string]int{r1: 0}))
+		assert.Equal(t, map[string]int{r2: 15, r3: 15}, counts, "Expected the route without a weight not to be selected")
+
+		counts = count(NewSelector(), 30, selector.Weights(map[string]int{r1: 0, r2: 0, r3: -1}))
+		assert.Equal(t, map[string]int{r1: 10, r2: 10, r3: 10}, counts, "Expected the routes to be selected equally")
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		sel := NewSelector().(*weighted)
+		count(sel, 3)
+		assert.Len(t, sel.current, 3)
+
+		// the routes which haven't been selected from for the prune interval are forgotten
+		past := time.Now().Add(-2 * pruneInterval)
+		sel.used[r1], sel.used[r2], sel.pruned = past, past, past
+		next, err := sel.Select([]string{r3})
+		assert.Nil(t, err, "Error should be nil")
+		next()
+		assert.Equal(t, []string{r3}, keys(sel.current))
+	})
+}
+
+func keys(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -19,10 +19,15 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"math/big"
 	"net"
 	"time"
@@ -86,3 +91,68 @@ func Certificate(host ...string) (tls.Certificate, error) {
 
 	return tls.X509KeyPair(certOut.Bytes(), keyOut.Bytes())
 }
+
+// ClientOptions are the files and settings of the tls config of a client
+type ClientOptions struct {
+	// CAFile is the bundle of CAs the server is verified by, the CAs of the system if empty
+	CAFile string
+	// CertFile and KeyFile are the certificate the client presents for mutual tls
+	CertFile string
+	KeyFile  string
+	// ServerName is the name the certificate of the server is verified for, and sent by SNI,
+	// the host dialled if empty
+	ServerName string
+	// InsecureSkipVerify skips the verification of the server
+	InsecureSkipVerify bool
+}
+
+// ClientConfig returns the tls config of a client from the options
+func ClientConfig(opts ClientOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if len(opts.CAFile) > 0 {
+		b, err := ioutil.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New("no certificates found in " + opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if len(opts.CertFile) > 0 || len(opts.KeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// ConfigKey returns the key of the connections secured by the config, so they can be reused by
+// the dials with an equal config rather than only the same one. Configs are equal if they have
+// the same server name, certificates and settings, and the same pool of root CAs as its
+// certificates can't be listed. Configs with callbacks are only equal to themselves.
+func ConfigKey(c *tls.Config) string {
+	if c.GetClientCertificate != nil || c.VerifyPeerCertificate != nil || c.VerifyConnection != nil {
+		return fmt.Sprintf("%p", c)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%q %t %p %d %d %v %v", c.ServerName, c.InsecureSkipVerify, c.RootCAs,
+		c.MinVersion, c.MaxVersion, c.CipherSuites, c.NextProtos)
+	for _, cert := range c.Certificates {
+		for _, der := range cert.Certificate {
+			fmt.Fprintf(h, " %d:", len(der))
+			h.Write(der)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
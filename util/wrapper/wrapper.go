@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/micro/micro/v3/service/auth"
@@ -17,7 +18,9 @@ import (
 	"github.com/micro/micro/v3/service/metrics"
 	"github.com/micro/micro/v3/service/server"
 	inauth "github.com/micro/micro/v3/util/auth"
+	"github.com/micro/micro/v3/util/breaker"
 	"github.com/micro/micro/v3/util/cache"
+	"github.com/micro/micro/v3/util/limiter"
 )
 
 type authWrapper struct {
@@ -281,6 +284,118 @@ func CacheClient(c client.Client) client.Client {
 	}
 }
 
+type breakerWrapper struct {
+	client.Client
+	opts []breaker.Option
+
+	sync.Mutex
+	breakers map[string]*breaker.Breaker
+}
+
+// breaker returns the breaker of the endpoint, creating it on the first call
+func (b *breakerWrapper) breaker(req client.Request) *breaker.Breaker {
+	key := req.Service() + "." + req.Endpoint()
+
+	b.Lock()
+	defer b.Unlock()
+	if br, ok := b.breakers[key]; ok {
+		return br
+	}
+
+	tags := metrics.Tags{
+		"service":  req.Service(),
+		"endpoint": req.Endpoint(),
+	}
+	opts := append([]breaker.Option{}, b.opts...)
+	opts = append(opts, breaker.OnStateChange(func(from, to breaker.State) {
+		logger.Debugf("Circuit breaker for %v %v changed from %v to %v", req.Service(), req.Endpoint(), from, to)
+		if metrics.DefaultMetricsReporter != nil {
+			metrics.Gauge("client.breaker.state", float64(to), tags)
+		}
+	}))
+	br := breaker.New(opts...)
+	b.breakers[key] = br
+	return br
+}
+
+// allow returns the func to record the result of the call with, or an error if the breaker of the
+// endpoint is open
+func (b *breakerWrapper) allow(req client.Request) (func(error, time.Duration), error) {
+	done, err := b.breaker(req).Allow()
+	if err == nil {
+		return done, nil
+	}
+
+	if metrics.DefaultMetricsReporter != nil {
+		metrics.Count("client.breaker.rejected", 1, metrics.Tags{
+			"service":  req.Service(),
+			"endpoint": req.Endpoint(),
+		})
+	}
+	return nil, errors.ServiceUnavailable("go.micro.client", "circuit breaker open for %v %v", req.Service(), req.Endpoint())
+}
+
+// Call executes the request unless the breaker of the endpoint is open
+func (b *breakerWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	done, err := b.allow(req)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = b.Client.Call(ctx, req, rsp, opts...)
+	done(err, time.Since(start))
+	return err
+}
+
+// Stream opens the stream unless the breaker of the endpoint is open
+func (b *breakerWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	done, err := b.allow(req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	stream, err := b.Client.Stream(ctx, req, opts...)
+	done(err, time.Since(start))
+	return stream, err
+}
+
+// BreakerClient wraps requests with a circuit breaker for each endpoint of each service, failing
+// the calls to an endpoint which fails or is slow too often until it recovers
+func BreakerClient(c client.Client, opts ...breaker.Option) client.Client {
+	return &breakerWrapper{
+		Client:   c,
+		opts:     opts,
+		breakers: make(map[string]*breaker.Breaker),
+	}
+}
+
+// LimitHandler wraps a server handler to limit the requests served concurrently, rejecting those
+// over the limit once the queue of the limiter is full. The requests are rejected with a 429 so
+// they aren't retried, or counted as failures of the service by breakers and outlier detection.
+func LimitHandler(l *limiter.Limiter) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			// don't limit debug calls so an overloaded service can still be inspected
+			if strings.HasPrefix(req.Endpoint(), "Debug.") {
+				return h(ctx, req, rsp)
+			}
+
+			done, err := l.Acquire(ctx)
+			if err != nil {
+				if metrics.DefaultMetricsReporter != nil {
+					metrics.Count("server.limiter.rejected", 1, metrics.Tags{"method": req.Method()})
+				}
+				return errors.TooManyRequests(req.Service(), "%v: %v", req.Endpoint(), err)
+			}
+			defer done()
+
+			return h(ctx, req, rsp)
+		}
+	}
+}
+
 // MetricsHandler wraps a server handler to instrument calls
 func MetricsHandler() server.HandlerWrapper {
 	// return a handler wrapper
@@ -9,6 +9,7 @@ import (
 	"github.com/micro/micro/v3/service/errors"
 	"github.com/micro/micro/v3/service/server"
 	"github.com/micro/micro/v3/util/codec"
+	"github.com/micro/micro/v3/util/limiter"
 
 	. "github.com/onsi/gomega"
 )
@@ -169,3 +170,20 @@ func TestAuthWrapper(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	l := limiter.New(limiter.Limit(1))
+	done, err := l.Acquire(context.TODO())
+	g.Expect(err).To(BeNil())
+	defer done()
+
+	h := LimitHandler(l)(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		return nil
+	})
+
+	// the requests over the limit are rejected as too many rather than the service unavailable
+	err = h(context.TODO(), dummyReq{}, nil)
+	g.Expect(errors.FromError(err).Code).To(Equal(int32(429)))
+}